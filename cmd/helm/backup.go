@@ -0,0 +1,203 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/golang/protobuf/proto"
+	"github.com/spf13/cobra"
+
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/release"
+	"k8s.io/helm/pkg/proto/hapi/services"
+	"k8s.io/helm/pkg/releaseutil"
+)
+
+const backupDesc = `
+This command writes every revision of every release that Tiller stores,
+whatever the state of the release, to a single archive. 'helm restore' loads
+the archive into a Tiller, which may use a different storage driver.
+
+The archive records a SHA-256 checksum of each revision, which 'helm restore'
+checks before anything is restored.
+`
+
+// backupManifestName is the name of the file that describes the contents of
+// a backup.
+const backupManifestName = "backup.yaml"
+
+// backupMaxRevisions is the most revisions of one release that a backup
+// asks Tiller for.
+const backupMaxRevisions = 1 << 20
+
+// backupManifest describes the contents of a backup.
+type backupManifest struct {
+	APIVersion string          `json:"apiVersion"`
+	Created    string          `json:"created"`
+	Releases   []backupRelease `json:"releases"`
+}
+
+type backupRelease struct {
+	Name      string           `json:"name"`
+	Namespace string           `json:"namespace"`
+	Revisions []backupRevision `json:"revisions"`
+}
+
+type backupRevision struct {
+	Revision int32  `json:"revision"`
+	Status   string `json:"status"`
+	SHA256   string `json:"sha256"`
+}
+
+type backupCmd struct {
+	file   string
+	out    io.Writer
+	client helm.Interface
+}
+
+func newBackupCmd(client helm.Interface, out io.Writer) *cobra.Command {
+	b := &backupCmd{out: out, client: client}
+
+	cmd := &cobra.Command{
+		Use:               "backup [flags]",
+		Short:             "write all release records to an archive",
+		Long:              backupDesc,
+		PersistentPreRunE: setupConnection,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if b.client == nil {
+				b.client = helm.NewClient(helm.Host(tillerHost), helm.UserToken(userToken), helm.Impersonate(asUser, asGroups), helm.RequestID(requestID))
+			}
+			return b.run()
+		},
+	}
+
+	cmd.Flags().StringVarP(&b.file, "file", "f", "", "file to write the backup to. Defaults to helm-backup-TIMESTAMP.tgz")
+
+	return cmd
+}
+
+func (b *backupCmd) run() error {
+	names, err := b.releaseNames()
+	if err != nil {
+		return prettyError(err)
+	}
+
+	now := time.Now().UTC()
+	m := &backupManifest{APIVersion: "v1", Created: now.Format(time.RFC3339)}
+	files := []archiveFile{{name: backupManifestName}}
+	count := 0
+	for _, name := range names {
+		res, err := b.client.ReleaseHistory(name, helm.WithMaxHistory(backupMaxRevisions))
+		if err != nil {
+			return prettyError(err)
+		}
+		rels := append([]*release.Release{}, res.Releases...)
+		if len(rels) == 0 {
+			continue
+		}
+		releaseutil.SortByRevision(rels)
+
+		br := backupRelease{Name: name, Namespace: rels[len(rels)-1].Namespace}
+		for _, r := range rels {
+			data, err := proto.Marshal(r)
+			if err != nil {
+				return err
+			}
+			files = append(files, archiveFile{revisionFile(name, r.Version), data})
+			br.Revisions = append(br.Revisions, backupRevision{
+				Revision: r.Version,
+				Status:   r.Info.Status.Code.String(),
+				SHA256:   checksum(data),
+			})
+		}
+		m.Releases = append(m.Releases, br)
+		count += len(rels)
+	}
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return err
+	}
+	files[0].data = data
+
+	dest := b.file
+	if dest == "" {
+		dest = "helm-backup-" + now.Format("20060102150405") + ".tgz"
+	}
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if err := writeArchive(f, files); err != nil {
+		f.Close()
+		os.Remove(dest)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(b.out, "Backed up %d revisions of %d releases to %s\n", count, len(m.Releases), dest)
+	return nil
+}
+
+// releaseNames returns the names of all releases, in any state, sorted.
+func (b *backupCmd) releaseNames() ([]string, error) {
+	codes := []release.Status_Code{}
+	for c := range release.Status_Code_name {
+		codes = append(codes, release.Status_Code(c))
+	}
+
+	seen := map[string]bool{}
+	names := []string{}
+	offset := ""
+	for {
+		res, err := b.client.ListReleases(
+			helm.ReleaseListStatuses(codes),
+			helm.ReleaseListSort(int32(services.ListSort_NAME)),
+			helm.ReleaseListOffset(offset),
+		)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range res.Releases {
+			if !seen[r.Name] {
+				seen[r.Name] = true
+				names = append(names, r.Name)
+			}
+		}
+		// A page starts with the release it was asked to start at.
+		if res.Next == "" || res.Next == offset {
+			break
+		}
+		offset = res.Next
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// checksum returns the hex-encoded SHA-256 checksum of data.
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
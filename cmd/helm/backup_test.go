@@ -0,0 +1,133 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/release"
+	rls "k8s.io/helm/pkg/proto/hapi/services"
+)
+
+// fakeBackupClient keeps the history of several releases.
+type fakeBackupClient struct {
+	fakeReleaseClient
+	history  map[string][]*release.Release
+	imported []string
+	existing string
+}
+
+func (c *fakeBackupClient) ReleaseHistory(name string, opts ...helm.HistoryOption) (*rls.GetHistoryResponse, error) {
+	return &rls.GetHistoryResponse{Releases: c.history[name]}, nil
+}
+
+func (c *fakeBackupClient) ImportRelease(revisions []*release.Release, opts ...helm.ImportOption) (*rls.ImportReleaseResponse, error) {
+	name := revisions[0].Name
+	if name == c.existing {
+		return nil, errors.New("rpc error: code = 2 desc = release \"" + name + "\" already exists")
+	}
+	c.imported = append(c.imported, name)
+	return &rls.ImportReleaseResponse{Release: revisions[len(revisions)-1]}, nil
+}
+
+func TestBackupRestore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-backup-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	bird2 := releaseMock(&releaseOptions{name: "angry-bird", version: 2})
+	bird1 := releaseMock(&releaseOptions{name: "angry-bird", version: 1, statusCode: release.Status_SUPERSEDED})
+	panda := releaseMock(&releaseOptions{name: "happy-panda", statusCode: release.Status_DELETED})
+	c := &fakeBackupClient{
+		fakeReleaseClient: fakeReleaseClient{rels: []*release.Release{bird1, bird2, panda}},
+		history: map[string][]*release.Release{
+			"angry-bird":  {bird2, bird1},
+			"happy-panda": {panda},
+		},
+	}
+
+	file := filepath.Join(dir, "backup.tgz")
+	var buf bytes.Buffer
+	cmd := newBackupCmd(c, &buf)
+	cmd.ParseFlags([]string{"--file", file})
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatal(err)
+	}
+	if expect := "Backed up 3 revisions of 2 releases to " + file + "\n"; buf.String() != expect {
+		t.Errorf("expected %q, got %q", expect, buf.String())
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, rels, err := readBackup(f)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Releases) != 2 || m.Releases[0].Name != "angry-bird" || m.Releases[1].Revisions[0].Status != "DELETED" {
+		t.Errorf("unexpected manifest %v", m)
+	}
+	if len(rels[0]) != 2 || rels[0][0].Version != 1 || rels[0][1].Version != 2 {
+		t.Errorf("expected revisions 1 and 2 of angry-bird, oldest first, got %v", rels[0])
+	}
+
+	buf.Reset()
+	restore := &fakeBackupClient{existing: "angry-bird"}
+	cmd = newRestoreCmd(restore, &buf)
+	if err := cmd.RunE(cmd, []string{file}); err != nil {
+		t.Fatal(err)
+	}
+	if expect := "Skipped angry-bird, which already exists\nRestored 1 revisions of happy-panda\n"; buf.String() != expect {
+		t.Errorf("expected %q, got %q", expect, buf.String())
+	}
+	if strings.Join(restore.imported, ",") != "happy-panda" {
+		t.Errorf("expected happy-panda to be imported, got %v", restore.imported)
+	}
+}
+
+func TestReadBackupChecksums(t *testing.T) {
+	manifest := `apiVersion: v1
+releases:
+- name: angry-bird
+  revisions:
+  - revision: 1
+    sha256: 0000000000000000000000000000000000000000000000000000000000000000
+`
+	var buf bytes.Buffer
+	files := []archiveFile{
+		{backupManifestName, []byte(manifest)},
+		{revisionFile("angry-bird", 1), []byte("tampered")},
+	}
+	if err := writeArchive(&buf, files); err != nil {
+		t.Fatal(err)
+	}
+	_, _, err := readBackup(&buf)
+	if expect := `revision 1 of "angry-bird" does not match its checksum`; err == nil || err.Error() != expect {
+		t.Errorf("expected %q, got %v", expect, err)
+	}
+}
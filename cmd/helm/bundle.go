@@ -0,0 +1,84 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const bundleDesc = `
+This command moves charts and their images into disconnected environments.
+
+'helm bundle export' writes a chart, its dependencies, their provenance files
+and, optionally, the container images the chart runs into a single archive.
+Copy the archive across the air gap, and load it with 'helm bundle import'.
+`
+
+// bundleManifestName is the name of the file that describes a bundle's
+// contents.
+const bundleManifestName = "bundle.yaml"
+
+// bundleImagesDir is the directory of a bundle holding images, in OCI image
+// layout format.
+const bundleImagesDir = "images"
+
+// skopeoCommand is the binary used to copy container images.
+var skopeoCommand = "skopeo"
+
+// bundleManifest describes the contents of a bundle.
+type bundleManifest struct {
+	APIVersion string `json:"apiVersion"`
+	// Chart is the archive of the exported chart.
+	Chart string `json:"chart"`
+	// Charts lists the archives of the chart and all of its dependencies,
+	// stored under charts/.
+	Charts []string `json:"charts"`
+	// Images lists the container images stored under images/.
+	Images []string `json:"images,omitempty"`
+}
+
+func newBundleCmd(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bundle [FLAGS] export|import [ARGS]",
+		Short: "export and import charts for air-gapped installs",
+		Long:  bundleDesc,
+	}
+
+	cmd.AddCommand(newBundleExportCmd(out))
+	cmd.AddCommand(newBundleImportCmd(out))
+
+	return cmd
+}
+
+// copyImage copies a container image between two skopeo transports, for
+// example "docker://nginx:1.11" and "oci:/tmp/images:nginx:1.11".
+func copyImage(src, dest string) error {
+	path, err := exec.LookPath(skopeoCommand)
+	if err != nil {
+		return fmt.Errorf("copying images requires %q, which could not be found", skopeoCommand)
+	}
+	out, err := exec.Command(path, "copy", src, dest).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to copy %s to %s: %s", src, dest, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,300 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+
+	"k8s.io/helm/cmd/helm/downloader"
+	"k8s.io/helm/cmd/helm/helmpath"
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/releaseutil"
+)
+
+const bundleExportDesc = `
+This command writes a chart and everything needed to install it into a single
+archive, NAME-VERSION.bundle.tgz.
+
+The chart may be a chart reference (as in 'stable/mariadb'), a URL, a chart
+archive or a chart directory. The bundle holds the chart archive, an archive
+of each of its dependencies, and any provenance files.
+
+With '--images', the container images the rendered chart runs are also stored,
+in OCI image layout format. This requires 'skopeo' and access to the image
+registries.
+`
+
+type bundleExportCmd struct {
+	chartRef string
+	version  string
+	verify   bool
+	keyring  string
+	destdir  string
+	images   bool
+	out      io.Writer
+}
+
+func newBundleExportCmd(out io.Writer) *cobra.Command {
+	exp := &bundleExportCmd{out: out}
+
+	cmd := &cobra.Command{
+		Use:   "export [flags] CHART",
+		Short: "write a chart, its dependencies and images to a bundle",
+		Long:  bundleExportDesc,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkArgsLength(len(args), "chart"); err != nil {
+				return err
+			}
+			exp.chartRef = args[0]
+			return exp.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&exp.version, "version", "", "version of the chart. Without this, the latest version is exported")
+	f.BoolVar(&exp.verify, "verify", false, "verify the chart against its signature before exporting it")
+	f.StringVar(&exp.keyring, "keyring", defaultKeyring(), "keyring containing public keys")
+	f.StringVarP(&exp.destdir, "destination", "d", ".", "location to write the bundle to")
+	f.BoolVar(&exp.images, "images", false, "include the container images the chart runs")
+
+	return cmd
+}
+
+func (e *bundleExportCmd) run() error {
+	dir, err := ioutil.TempDir("", "helm-bundle-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	chartsDir := filepath.Join(dir, "charts")
+	if err := os.Mkdir(chartsDir, 0755); err != nil {
+		return err
+	}
+
+	archive, err := e.fetch(chartsDir)
+	if err != nil {
+		return err
+	}
+	ch, err := chartutil.Load(archive)
+	if err != nil {
+		return err
+	}
+	if err := checkDependencies(ch); err != nil {
+		return err
+	}
+
+	m := &bundleManifest{
+		APIVersion: "v1",
+		Chart:      filepath.Base(archive),
+		Charts:     []string{filepath.Base(archive)},
+	}
+
+	// Dependencies are packaged inside of the chart, but are also stored on
+	// their own so that they can be added to a repository on import.
+	var saveDeps func(*chart.Chart) error
+	saveDeps = func(c *chart.Chart) error {
+		for _, dep := range c.Dependencies {
+			name, err := chartutil.Save(dep, chartsDir)
+			if err != nil {
+				return err
+			}
+			m.Charts = append(m.Charts, filepath.Base(name))
+			if err := saveDeps(dep); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := saveDeps(ch); err != nil {
+		return err
+	}
+
+	if e.images {
+		manifest, err := renderChart(archive, "", "")
+		if err != nil {
+			return err
+		}
+		refs, err := releaseutil.ManifestImages(manifest)
+		if err != nil {
+			return err
+		}
+		layout := filepath.Join(dir, bundleImagesDir)
+		for _, img := range uniqueImages(refs) {
+			fmt.Fprintf(e.out, "Copying image %s\n", img)
+			if err := copyImage("docker://"+img, "oci:"+layout+":"+img); err != nil {
+				return err
+			}
+			m.Images = append(m.Images, img)
+		}
+	}
+
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, bundleManifestName), data, 0644); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.bundle.tgz", ch.Metadata.Name, ch.Metadata.Version)
+	dest := filepath.Join(e.destdir, name)
+	if err := writeBundle(dest, dir); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.out, "Exported %s to %s\n", e.chartRef, dest)
+	return nil
+}
+
+// fetch puts the chart archive, and its provenance file if there is one, in
+// dir and returns the path to the archive.
+func (e *bundleExportCmd) fetch(dir string) (string, error) {
+	if fi, err := os.Stat(e.chartRef); err == nil {
+		if fi.IsDir() {
+			if e.verify {
+				return "", fmt.Errorf("cannot verify a directory")
+			}
+			ch, err := chartutil.LoadDir(e.chartRef)
+			if err != nil {
+				return "", err
+			}
+			return chartutil.Save(ch, dir)
+		}
+		if e.verify {
+			if _, err := downloader.VerifyChart(e.chartRef, e.keyring); err != nil {
+				return "", err
+			}
+		}
+		dest := filepath.Join(dir, filepath.Base(e.chartRef))
+		if err := copyArchive(e.chartRef, dest); err != nil {
+			return "", err
+		}
+		if _, err := os.Stat(e.chartRef + ".prov"); err == nil {
+			if err := copyArchive(e.chartRef+".prov", dest+".prov"); err != nil {
+				return "", err
+			}
+		}
+		return dest, nil
+	}
+
+	c := downloader.ChartDownloader{
+		HelmHome: helmpath.Home(homePath()),
+		Out:      e.out,
+		Keyring:  e.keyring,
+		Verify:   downloader.VerifyLater,
+	}
+	if e.verify {
+		c.Verify = downloader.VerifyAlways
+	}
+	saved, _, err := c.DownloadTo(e.chartRef, e.version, dir)
+	return saved, err
+}
+
+// checkDependencies returns an error if a dependency listed in the chart's
+// requirements.yaml is not packaged with it.
+func checkDependencies(ch *chart.Chart) error {
+	reqs, err := chartutil.LoadRequirements(ch)
+	if err == chartutil.ErrRequirementsNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	have := map[string]bool{}
+	for _, dep := range ch.Dependencies {
+		have[dep.Metadata.Name] = true
+	}
+	for _, r := range reqs.Dependencies {
+		if !have[r.Name] {
+			return fmt.Errorf("dependency %q of %s is missing; run 'helm dependency build' first", r.Name, ch.Metadata.Name)
+		}
+	}
+	return nil
+}
+
+// writeBundle writes the contents of dir to a gzipped tar archive at dest.
+func writeBundle(dest, dir string) error {
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	zw := gzip.NewWriter(f)
+	tw := tar.NewWriter(zw)
+	err = filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil || path == dir {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		hdr, err := tar.FileInfoHeader(fi, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = filepath.ToSlash(rel)
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			return nil
+		}
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer in.Close()
+		_, err = io.Copy(tw, in)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func copyArchive(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+
+	"k8s.io/helm/cmd/helm/helmpath"
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/repo"
+)
+
+const bundleImportDesc = `
+This command loads a bundle written by 'helm bundle export'.
+
+The charts in the bundle, along with their provenance files, are added to the
+local chart repository ($HELM_HOME/repository/local), from which they can be
+installed, served with 'helm serve', or used to build dependencies.
+
+If the bundle holds images, '--registry' pushes them to the given registry,
+keeping their repository paths but replacing their original registry. This
+requires 'skopeo'.
+`
+
+type bundleImportCmd struct {
+	bundle   string
+	registry string
+	out      io.Writer
+	home     helmpath.Home
+}
+
+func newBundleImportCmd(out io.Writer) *cobra.Command {
+	imp := &bundleImportCmd{out: out}
+
+	cmd := &cobra.Command{
+		Use:   "import [flags] BUNDLE",
+		Short: "load the charts and images of a bundle",
+		Long:  bundleImportDesc,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkArgsLength(len(args), "bundle"); err != nil {
+				return err
+			}
+			imp.bundle = args[0]
+			imp.home = helmpath.Home(homePath())
+			return imp.run()
+		},
+	}
+
+	cmd.Flags().StringVar(&imp.registry, "registry", "", "registry to push the images of the bundle to, e.g. registry.example.com:5000")
+
+	return cmd
+}
+
+func (i *bundleImportCmd) run() error {
+	dir, err := ioutil.TempDir("", "helm-bundle-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := chartutil.ExpandFile(dir, i.bundle); err != nil {
+		return fmt.Errorf("could not read bundle %s: %s", i.bundle, err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, bundleManifestName))
+	if err != nil {
+		return fmt.Errorf("%s is not a bundle: %s", i.bundle, err)
+	}
+	m := &bundleManifest{}
+	if err := yaml.Unmarshal(data, m); err != nil {
+		return fmt.Errorf("could not parse %s: %s", bundleManifestName, err)
+	}
+
+	lr := i.home.LocalRepository()
+	for _, name := range m.Charts {
+		if name != filepath.Base(name) {
+			return fmt.Errorf("invalid chart name %q in bundle", name)
+		}
+		src := filepath.Join(dir, "charts", name)
+		ch, err := chartutil.Load(src)
+		if err != nil {
+			return err
+		}
+		dest := filepath.Join(lr, name)
+		if err := copyArchive(src, dest); err != nil {
+			return err
+		}
+		if _, err := os.Stat(src + ".prov"); err == nil {
+			if err := copyArchive(src+".prov", dest+".prov"); err != nil {
+				return err
+			}
+		}
+		if err := repo.Reindex(ch, filepath.Join(lr, "index.yaml")); err != nil {
+			return err
+		}
+		fmt.Fprintf(i.out, "Imported chart %s\n", name)
+	}
+
+	if len(m.Images) == 0 {
+		return nil
+	}
+	if i.registry == "" {
+		fmt.Fprintf(i.out, "Skipped %d images. Use --registry to push them to a registry\n", len(m.Images))
+		return nil
+	}
+	layout := filepath.Join(dir, bundleImagesDir)
+	for _, img := range m.Images {
+		dest := i.registry + "/" + imageRepository(img)
+		if err := copyImage("oci:"+layout+":"+img, "docker://"+dest); err != nil {
+			return err
+		}
+		fmt.Fprintf(i.out, "Pushed image %s to %s\n", img, dest)
+	}
+	return nil
+}
+
+// imageRepository returns an image reference without its registry host, so
+// "quay.io/coreos/etcd:v3.0" becomes "coreos/etcd:v3.0".
+func imageRepository(ref string) string {
+	i := strings.Index(ref, "/")
+	if i < 0 {
+		return ref
+	}
+	host := ref[:i]
+	if strings.ContainsAny(host, ".:") || host == "localhost" {
+		return ref[i+1:]
+	}
+	return ref
+}
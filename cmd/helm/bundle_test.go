@@ -0,0 +1,140 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/helm/cmd/helm/helmpath"
+	"k8s.io/helm/pkg/repo"
+)
+
+func TestBundleExportImport(t *testing.T) {
+	hh, err := tempHelmHome(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := homePath()
+	helmHome = hh
+	defer func() {
+		helmHome = old
+		os.RemoveAll(hh)
+	}()
+
+	// A stand-in for skopeo that logs its arguments.
+	log := filepath.Join(hh, "skopeo.log")
+	fake := filepath.Join(hh, "skopeo")
+	if err := ioutil.WriteFile(fake, []byte("#!/bin/sh\necho \"$@\" >> "+log+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer func(cmd string) { skopeoCommand = cmd }(skopeoCommand)
+	skopeoCommand = fake
+
+	outdir := filepath.Join(hh, "out")
+	os.Mkdir(outdir, 0755)
+
+	tests := []struct {
+		chart  string
+		flags  []string
+		bundle string
+		charts []string
+	}{
+		{
+			chart:  "testdata/testcharts/signtest-0.1.0.tgz",
+			bundle: "signtest-0.1.0.bundle.tgz",
+			charts: []string{"signtest-0.1.0.tgz", "signtest-0.1.0.tgz.prov"},
+		},
+		{
+			chart:  "testdata/testcharts/reqtest",
+			bundle: "reqtest-0.1.0.bundle.tgz",
+			charts: []string{"reqtest-0.1.0.tgz", "reqsubchart-0.1.0.tgz", "reqsubchart2-0.2.0.tgz"},
+		},
+		{
+			chart:  "testdata/testcharts/alpine",
+			flags:  []string{"--images"},
+			bundle: "alpine-0.1.0.bundle.tgz",
+			charts: []string{"alpine-0.1.0.tgz"},
+		},
+	}
+
+	for _, tt := range tests {
+		buf := bytes.NewBuffer(nil)
+		cmd := newBundleExportCmd(buf)
+		cmd.ParseFlags(append(tt.flags, "-d", outdir))
+		if err := cmd.RunE(cmd, []string{tt.chart}); err != nil {
+			t.Errorf("export %s: %s", tt.chart, err)
+			continue
+		}
+
+		bundle := filepath.Join(outdir, tt.bundle)
+		cmd = newBundleImportCmd(buf)
+		cmd.ParseFlags([]string{"--registry", "registry.example.com"})
+		if err := cmd.RunE(cmd, []string{bundle}); err != nil {
+			t.Errorf("import %s: %s", bundle, err)
+			continue
+		}
+
+		lr := helmpath.Home(hh).LocalRepository()
+		for _, name := range tt.charts {
+			if _, err := os.Stat(filepath.Join(lr, name)); err != nil {
+				t.Errorf("%s: expected %s in the local repository", tt.bundle, name)
+			}
+		}
+		idx, err := repo.LoadIndexFile(filepath.Join(lr, "index.yaml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !idx.Has(strings.TrimSuffix(tt.bundle, "-0.1.0.bundle.tgz"), "0.1.0") {
+			t.Errorf("%s: expected the chart to be indexed", tt.bundle)
+		}
+	}
+
+	data, err := ioutil.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 image copies, got %q", calls)
+	}
+	if !strings.HasPrefix(calls[0], "copy docker://alpine:3.3 oci:") {
+		t.Errorf("unexpected export copy %q", calls[0])
+	}
+	if !strings.HasSuffix(calls[1], ":alpine:3.3 docker://registry.example.com/alpine:3.3") {
+		t.Errorf("unexpected import copy %q", calls[1])
+	}
+}
+
+func TestImageRepository(t *testing.T) {
+	tests := map[string]string{
+		"nginx:1.11":               "nginx:1.11",
+		"bitnami/mariadb":          "bitnami/mariadb",
+		"quay.io/coreos/etcd:v3.0": "coreos/etcd:v3.0",
+		"localhost:5000/nginx":     "nginx",
+		"localhost/nginx":          "nginx",
+	}
+	for ref, expect := range tests {
+		if got := imageRepository(ref); got != expect {
+			t.Errorf("%s: expected %q, got %q", ref, expect, got)
+		}
+	}
+}
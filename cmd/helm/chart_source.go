@@ -0,0 +1,59 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"k8s.io/helm/cmd/helm/downloader"
+	"k8s.io/helm/cmd/helm/helmpath"
+	"k8s.io/helm/pkg/proto/hapi/services"
+)
+
+// chartSource describes where the chart at chartPath, located from ref and
+// version, came from, for Tiller to check against its chart rules.
+//
+// Only packaged charts have a source. The provenance file is sent along if it
+// is next to the archive, as it is after a verified download. The archive
+// itself is only needed to check the provenance, so it is left out when there
+// is none, rather than sending a second copy of the chart to Tiller.
+func chartSource(ref, version, chartPath string) (*services.ChartSource, error) {
+	fi, err := os.Stat(chartPath)
+	if err != nil || fi.IsDir() {
+		return nil, nil
+	}
+	src := &services.ChartSource{ArchiveName: filepath.Base(chartPath)}
+
+	if prov, err := ioutil.ReadFile(chartPath + ".prov"); err == nil {
+		src.Provenance = prov
+		if src.Archive, err = ioutil.ReadFile(chartPath); err != nil {
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if _, err := os.Stat(ref); os.IsNotExist(err) {
+		dl := downloader.ChartDownloader{HelmHome: helmpath.Home(homePath())}
+		if u, err := dl.ResolveChartVersion(ref, version); err == nil {
+			src.Url = u.String()
+		}
+	}
+	return src, nil
+}
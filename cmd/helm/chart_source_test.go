@@ -0,0 +1,50 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+)
+
+func TestChartSource(t *testing.T) {
+	path := "testdata/testcharts/signtest-0.1.0.tgz"
+	src, err := chartSource(path, "", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src.ArchiveName != "signtest-0.1.0.tgz" {
+		t.Errorf("unexpected archive name %q", src.ArchiveName)
+	}
+	if len(src.Archive) == 0 || len(src.Provenance) == 0 {
+		t.Error("expected the archive and its provenance")
+	}
+	if src.Url != "" {
+		t.Errorf("expected no URL for a local archive, got %q", src.Url)
+	}
+
+	path = "testdata/testcharts/reqtest-0.1.0.tgz"
+	if src, err = chartSource(path, "", path); err != nil {
+		t.Fatal(err)
+	}
+	if len(src.Archive) != 0 {
+		t.Error("expected no archive to be sent without a provenance file")
+	}
+
+	if src, err := chartSource("testdata/testcharts/alpine", "", "testdata/testcharts/alpine"); err != nil || src != nil {
+		t.Errorf("expected no source for a chart directory, got %v, %v", src, err)
+	}
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/releaseutil"
+)
+
+const checkDeprecationsDesc = `
+This command reports the resources of a chart or a release that use Kubernetes
+API versions deprecated or removed in a target version of Kubernetes, so that
+they can be updated before the cluster is upgraded.
+
+The argument is treated as a chart if it is a path to a chart or contains a
+'/' (as in 'stable/mariadb' or a chart URL), and as the name of a release
+otherwise. Charts are rendered locally for the target version, like
+'helm template --kube-version' does; for a release, the manifest and hooks
+stored by Tiller are checked.
+
+	$ helm check-deprecations --kube-version 1.16 ./web
+	KIND      	NAME	API VERSION       	STATUS                      	REPLACEMENT         	SOURCE
+	Deployment	web 	extensions/v1beta1	removed in 1.16             	apps/v1             	web/templates/deployment.yaml
+	Ingress   	web 	extensions/v1beta1	deprecated (removed in 1.22)	networking.k8s.io/v1	web/templates/ingress.yaml
+	Error: 1 resource uses API versions removed in Kubernetes v1.16.0
+
+The command exits with an error if any resource uses an API version that the
+target version no longer serves. With '--strict', resources that use API
+versions that are only deprecated fail the check too.
+`
+
+type checkDeprecationsCmd struct {
+	target      string
+	kubeVersion string
+	version     string
+	revision    int32
+	valuesFile  string
+	values      string
+	strict      bool
+	output      string
+	out         io.Writer
+	client      helm.Interface
+}
+
+func newCheckDeprecationsCmd(client helm.Interface, out io.Writer) *cobra.Command {
+	c := &checkDeprecationsCmd{
+		out:    out,
+		client: client,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "check-deprecations [flags] CHART|RELEASE",
+		Short: "find deprecated Kubernetes API versions in a chart or release",
+		Long:  checkDeprecationsDesc,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkArgsLength(len(args), "chart or release name"); err != nil {
+				return err
+			}
+			c.target = args[0]
+			if !isChart(c.target) && c.client == nil {
+				if err := setupConnection(cmd, args); err != nil {
+					return err
+				}
+				c.client = ensureHelmClient(c.client)
+			}
+			return c.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&c.kubeVersion, "kube-version", "", "Kubernetes version to check against (required)")
+	f.StringVar(&c.version, "version", "", "version of the chart to render. Without this, the latest version is used")
+	f.Int32Var(&c.revision, "revision", 0, "revision of the release to check")
+	f.StringVarP(&c.valuesFile, "values", "f", "", "values file to render the chart with")
+	f.StringVar(&c.values, "set", "", "set values to render the chart with. Separate values with commas: key1=val1,key2=val2")
+	f.BoolVar(&c.strict, "strict", false, "fail on API versions that are deprecated but still served")
+	addOutputFlag(f, &c.output, "o", "table")
+
+	return cmd
+}
+
+func (c *checkDeprecationsCmd) run() error {
+	format, err := parseOutputFormat(c.output, "table")
+	if err != nil {
+		return err
+	}
+	if c.kubeVersion == "" {
+		return errors.New("--kube-version is required")
+	}
+	kv, err := parseKubeVersion(c.kubeVersion)
+	if err != nil {
+		return err
+	}
+	minor, _ := strconv.Atoi(kv.Minor)
+
+	manifest, err := c.manifest(minor)
+	if err != nil {
+		return err
+	}
+	res, err := releaseutil.ManifestDeprecations(manifest, minor)
+	if err != nil {
+		return err
+	}
+
+	if !format.human() {
+		if err := format.write(c.out, res); err != nil {
+			return err
+		}
+	} else if len(res) == 0 {
+		fmt.Fprintf(c.out, "No deprecated API versions found for Kubernetes %s\n", kv.GitVersion)
+	} else {
+		fmt.Fprintln(c.out, formatDeprecations(res))
+	}
+
+	failed := 0
+	for _, r := range res {
+		if r.Status == releaseutil.APIRemoved || c.strict {
+			failed++
+		}
+	}
+	switch {
+	case failed == 0:
+		return nil
+	case c.strict:
+		return fmt.Errorf("%d of %d resources use API versions deprecated in Kubernetes %s", failed, len(res), kv.GitVersion)
+	case failed == 1:
+		return fmt.Errorf("1 resource uses API versions removed in Kubernetes %s", kv.GitVersion)
+	}
+	return fmt.Errorf("%d resources use API versions removed in Kubernetes %s", failed, kv.GitVersion)
+}
+
+// manifest returns the manifests to check: the chart rendered for the given
+// minor release of Kubernetes 1, or the manifest and hooks of the release.
+func (c *checkDeprecationsCmd) manifest(minor int) (string, error) {
+	if isChart(c.target) {
+		cp, err := locateChartPath(c.target, c.version, false, "")
+		if err != nil {
+			return "", err
+		}
+		t := &templateCmd{
+			chartPath:   cp,
+			name:        "RELEASE-NAME",
+			namespace:   "default",
+			valuesFile:  c.valuesFile,
+			values:      c.values,
+			apiVersions: chartutil.KubeAPIVersions(minor),
+			kubeVersion: c.kubeVersion,
+		}
+		return t.render()
+	}
+
+	res, err := c.client.ReleaseContent(c.target, helm.ContentReleaseVersion(c.revision))
+	if err != nil {
+		return "", prettyError(err)
+	}
+	manifest := res.Release.Manifest
+	for _, h := range res.Release.Hooks {
+		manifest += fmt.Sprintf("\n---\n# Source: %s\n%s\n", h.Path, h.Manifest)
+	}
+	return manifest, nil
+}
+
+func formatDeprecations(res []releaseutil.DeprecatedResource) string {
+	tbl := uitable.New()
+	tbl.MaxColWidth = 60
+	tbl.AddRow("KIND", "NAME", "API VERSION", "STATUS", "REPLACEMENT", "SOURCE")
+	for _, r := range res {
+		status := "removed in " + r.RemovedIn
+		if r.Status == releaseutil.APIDeprecated {
+			status = "deprecated (removed in " + r.RemovedIn + ")"
+		}
+		replacement := r.ReplacedBy
+		if replacement == "" {
+			replacement = "none"
+		}
+		tbl.AddRow(r.Kind, r.Name, r.APIVersion, status, replacement, r.Source)
+	}
+	return tbl.String()
+}
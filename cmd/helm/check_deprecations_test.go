@@ -0,0 +1,109 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+func deprecationsReleaseMock() *release.Release {
+	r := releaseMock(&releaseOptions{name: "juno"})
+	r.Manifest = `---
+# Source: web/templates/deployment.yaml
+apiVersion: extensions/v1beta1
+kind: Deployment
+metadata:
+  name: web
+`
+	r.Hooks = []*release.Hook{{
+		Name: "migrate",
+		Kind: "Job",
+		Path: "web/templates/migrate.yaml",
+		Manifest: `apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+`,
+	}, {
+		Name: "backup",
+		Kind: "CronJob",
+		Path: "web/templates/backup.yaml",
+		Manifest: `apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: backup
+`,
+	}}
+	return r
+}
+
+func TestCheckDeprecationsCmd(t *testing.T) {
+	tests := []releaseCase{
+		{
+			name:     "chart rendered for an old version",
+			args:     []string{"testdata/testcharts/apis"},
+			flags:    []string{"--kube-version", "1.8"},
+			expected: "^No deprecated API versions found for Kubernetes v1.8.0\n$",
+		},
+		{
+			name:     "chart with a deprecated version",
+			args:     []string{"testdata/testcharts/apis"},
+			flags:    []string{"--kube-version", "1.16"},
+			expected: `Ingress\s+RELEASE-NAME-web\s+extensions/v1beta1\s+deprecated \(removed in 1.22\)\s+networking.k8s.io/v1\s+apis/templates/ingress.yaml`,
+		},
+		{
+			name:     "chart with a deprecated version in strict mode",
+			args:     []string{"testdata/testcharts/apis"},
+			flags:    []string{"--kube-version", "1.16", "--strict"},
+			expected: "Ingress",
+			err:      true,
+		},
+		{
+			name:     "release with a removed version",
+			args:     []string{"juno"},
+			flags:    []string{"--kube-version", "1.16"},
+			expected: `Deployment\s+web\s+extensions/v1beta1\s+removed in 1.16\s+apps/v1\s+web/templates/deployment.yaml`,
+			resp:     deprecationsReleaseMock(),
+			err:      true,
+		},
+		{
+			name:     "release hooks as JSON",
+			args:     []string{"juno"},
+			flags:    []string{"--kube-version", "1.25", "--output", "json"},
+			expected: `"kind": "CronJob",\s+"name": "backup",\s+"apiVersion": "batch/v1beta1",\s+"source": "web/templates/backup.yaml",\s+"status": "removed"`,
+			resp:     deprecationsReleaseMock(),
+			err:      true,
+		},
+		{
+			name: "without a Kubernetes version",
+			args: []string{"testdata/testcharts/apis"},
+			err:  true,
+		},
+		{
+			name: "without args",
+			err:  true,
+		},
+	}
+	runReleaseCases(t, tests, func(c *fakeReleaseClient, out io.Writer) *cobra.Command {
+		return newCheckDeprecationsCmd(c, out)
+	})
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+
+	"k8s.io/helm/cmd/helm/helmpath"
+)
+
+const (
+	contextEnvVar         = "HELM_CONTEXT"
+	tillerNamespaceEnvVar = "TILLER_NAMESPACE"
+)
+
+var (
+	// contextName is the named context of the config file to use.
+	contextName string
+
+	// contextNamespace is the namespace releases are installed into by
+	// default, as set by the context.
+	contextNamespace string
+)
+
+// helmConfig is the client config file, config.yaml in $HELM_HOME.
+type helmConfig struct {
+	// CurrentContext is the context used when none is asked for.
+	CurrentContext string `json:"current-context"`
+	// Contexts are the named contexts.
+	Contexts map[string]helmContext `json:"contexts"`
+}
+
+// helmContext holds the defaults of the global flags for one environment,
+// usually a cluster.
+type helmContext struct {
+	Host            string `json:"host"`
+	KubeContext     string `json:"kube-context"`
+	TillerNamespace string `json:"tiller-namespace"`
+	Namespace       string `json:"namespace"`
+}
+
+func loadHelmConfig(filename string) (*helmConfig, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	c := &helmConfig{}
+	if err := yaml.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %s", filename, err)
+	}
+	return c, nil
+}
+
+// applyContext fills in the global settings that were not given by a flag or
+// an environment variable from the selected context of the config file.
+//
+// The context is the one named by --context or $HELM_CONTEXT, or else the
+// current context of the file. Without a config file, nothing changes. A nil
+// cmd has no flags set.
+func applyContext(cmd *cobra.Command) error {
+	filename := helmpath.Home(homePath()).ConfigFile()
+	cfg, err := loadHelmConfig(filename)
+	if os.IsNotExist(err) {
+		if contextName != "" {
+			return fmt.Errorf("context %q is not defined: %s does not exist", contextName, filename)
+		}
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	name := contextName
+	if name == "" {
+		name = cfg.CurrentContext
+	}
+	if name == "" {
+		return nil
+	}
+	ctx, ok := cfg.Contexts[name]
+	if !ok {
+		return fmt.Errorf("context %q is not defined in %s (defined: %v)", name, filename, contextNames(cfg))
+	}
+
+	unset := func(flag, env string) bool {
+		if cmd != nil {
+			if f := cmd.Flag(flag); f != nil && f.Changed {
+				return false
+			}
+		}
+		return env == "" || os.Getenv(env) == ""
+	}
+	if ctx.Host != "" && unset("host", hostEnvVar) {
+		tillerHost = ctx.Host
+	}
+	if ctx.KubeContext != "" && unset("kube-context", "") {
+		kubeContext = ctx.KubeContext
+	}
+	if ctx.TillerNamespace != "" && unset("tiller-namespace", tillerNamespaceEnvVar) {
+		tillerNamespace = ctx.TillerNamespace
+	}
+	contextNamespace = ctx.Namespace
+	return nil
+}
+
+func contextNames(cfg *helmConfig) []string {
+	names := []string{}
+	for n := range cfg.Contexts {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
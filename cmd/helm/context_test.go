@@ -0,0 +1,129 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/helm/cmd/helm/helmpath"
+	"k8s.io/helm/pkg/repo"
+)
+
+const testHelmConfig = `
+current-context: staging
+contexts:
+  staging:
+    host: tiller.staging:44134
+    kube-context: staging-admin
+    tiller-namespace: helm
+    namespace: apps
+  prod:
+    kube-context: prod-admin
+`
+
+func TestApplyContext(t *testing.T) {
+	hh, err := ioutil.TempDir("", "helm-context-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldHome, oldHost, oldKube, oldTNS, oldName, oldNS := helmHome, tillerHost, kubeContext, tillerNamespace, contextName, contextNamespace
+	defer func() {
+		os.RemoveAll(hh)
+		helmHome, tillerHost, kubeContext, tillerNamespace, contextName, contextNamespace = oldHome, oldHost, oldKube, oldTNS, oldName, oldNS
+	}()
+	if err := ioutil.WriteFile(helmpath.Home(hh).ConfigFile(), []byte(testHelmConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Creating the command resets the settings to their defaults, including the
+	// retry policy that the other tests expect to make a single attempt.
+	defer func(p repo.RetryPolicy) { repo.DefaultRetryPolicy = p }(repo.DefaultRetryPolicy)
+	cmd := func(args ...string) *cobra.Command {
+		c := newRootCmd(ioutil.Discard)
+		contextNamespace = ""
+		if err := c.ParseFlags(append([]string{"--home", hh}, args...)); err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	// The current context fills in everything.
+	if err := applyContext(cmd()); err != nil {
+		t.Fatal(err)
+	}
+	if tillerHost != "tiller.staging:44134" || kubeContext != "staging-admin" || tillerNamespace != "helm" || contextNamespace != "apps" {
+		t.Errorf("unexpected settings: host=%q kube-context=%q tiller-namespace=%q namespace=%q", tillerHost, kubeContext, tillerNamespace, contextNamespace)
+	}
+
+	// Flags win over the context.
+	if err := applyContext(cmd("--kube-context", "mine", "--host", "localhost:1")); err != nil {
+		t.Fatal(err)
+	}
+	if tillerHost != "localhost:1" || kubeContext != "mine" || tillerNamespace != "helm" {
+		t.Errorf("expected flags to win, got host=%q kube-context=%q tiller-namespace=%q", tillerHost, kubeContext, tillerNamespace)
+	}
+
+	// --context picks another context.
+	if err := applyContext(cmd("--context", "prod")); err != nil {
+		t.Fatal(err)
+	}
+	if tillerHost != "" || kubeContext != "prod-admin" || tillerNamespace != "kube-system" || contextNamespace != "" {
+		t.Errorf("unexpected settings for prod: host=%q kube-context=%q tiller-namespace=%q namespace=%q", tillerHost, kubeContext, tillerNamespace, contextNamespace)
+	}
+
+	// helm init sets up its connection without a command.
+	tillerHost, kubeContext, tillerNamespace, contextName = "", "", "kube-system", ""
+	if err := applyContext(nil); err != nil {
+		t.Fatal(err)
+	}
+	if tillerHost != "tiller.staging:44134" || kubeContext != "staging-admin" || tillerNamespace != "helm" {
+		t.Errorf("unexpected settings without a command: host=%q kube-context=%q tiller-namespace=%q", tillerHost, kubeContext, tillerNamespace)
+	}
+
+	err = applyContext(cmd("--context", "qa"))
+	if err == nil || !strings.Contains(err.Error(), `context "qa" is not defined`) || !strings.Contains(err.Error(), "[prod staging]") {
+		t.Errorf("expected an error for an unknown context, got %v", err)
+	}
+}
+
+func TestApplyContextWithoutConfig(t *testing.T) {
+	hh, err := ioutil.TempDir("", "helm-context-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldHome, oldName := helmHome, contextName
+	defer func() {
+		os.RemoveAll(hh)
+		helmHome, contextName = oldHome, oldName
+	}()
+
+	defer func(p repo.RetryPolicy) { repo.DefaultRetryPolicy = p }(repo.DefaultRetryPolicy)
+	cmd := newRootCmd(ioutil.Discard)
+	helmHome, contextName = hh, ""
+	if err := applyContext(cmd); err != nil {
+		t.Errorf("expected no error without a config file, got %s", err)
+	}
+	contextName = "prod"
+	if err := applyContext(cmd); err == nil {
+		t.Error("expected an error for a context without a config file")
+	}
+}
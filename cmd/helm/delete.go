@@ -18,6 +18,7 @@ package main
 
 import (
 	"errors"
+	"fmt"
 	"io"
 
 	"github.com/spf13/cobra"
@@ -31,13 +32,29 @@ It removes all of the resources associated with the last release of the chart.
 
 Use the '--dry-run' flag to see which releases will be deleted without actually
 deleting them.
+
+'--cascade' sets what happens to the objects Kubernetes created for the
+resources of the release, such as the Pods of a Deployment: 'background'
+deletes them after the resources, 'foreground' before them, and 'orphan'
+leaves them running. With '--wait', the resources are deleted kind by kind,
+in the reverse of the order they were installed in, and the command only
+returns once everything is gone.
+
+Resources annotated with 'helm.sh/resource-policy: keep' are not deleted,
+unless '--include-kept' is set. The command lists the resources it kept.
 `
 
 type deleteCmd struct {
-	name         string
-	dryRun       bool
-	disableHooks bool
-	purge        bool
+	name          string
+	dryRun        bool
+	disableHooks  bool
+	purge         bool
+	overrideLock  string
+	overrideGates string
+	cascade       string
+	wait          bool
+	timeout       int64
+	includeKept   bool
 
 	out    io.Writer
 	client helm.Interface
@@ -76,6 +93,12 @@ func newDeleteCmd(c helm.Interface, out io.Writer) *cobra.Command {
 	f.BoolVar(&del.dryRun, "dry-run", false, "simulate a delete")
 	f.BoolVar(&del.disableHooks, "no-hooks", false, "prevent hooks from running during deletion")
 	f.BoolVar(&del.purge, "purge", false, "remove the release from the store and make its name free for later use")
+	f.StringVar(&del.overrideLock, "override-lock", "", "delete the release even if it is locked, giving the reason for the change")
+	f.StringVar(&del.overrideGates, "override-gates", "", "delete the release even if the deployment windows or gates of Tiller do not allow it, giving the reason for the change")
+	f.StringVar(&del.cascade, "cascade", "", "propagation policy of the deletion: background, foreground or orphan")
+	f.BoolVar(&del.wait, "wait", false, "delete the resources kind by kind, waiting until each kind is gone from the cluster")
+	f.Int64Var(&del.timeout, "timeout", 300, "time in seconds to wait with --wait")
+	f.BoolVar(&del.includeKept, "include-kept", false, "also delete the resources annotated with helm.sh/resource-policy: keep")
 
 	return cmd
 }
@@ -85,7 +108,18 @@ func (d *deleteCmd) run() error {
 		helm.DeleteDryRun(d.dryRun),
 		helm.DeleteDisableHooks(d.disableHooks),
 		helm.DeletePurge(d.purge),
+		helm.DeleteOverrideLock(d.overrideLock),
+		helm.DeleteOverrideGates(d.overrideGates),
+		helm.DeleteCascade(d.cascade),
+		helm.DeleteWait(d.wait, d.timeout),
+		helm.DeleteIncludeKept(d.includeKept),
+	}
+	res, err := d.client.DeleteRelease(d.name, opts...)
+	if res != nil && len(res.Kept) > 0 {
+		fmt.Fprintf(d.out, "These resources of %s were kept because of their resource policy:\n", d.name)
+		for _, k := range res.Kept {
+			fmt.Fprintf(d.out, "  %s\n", k)
+		}
 	}
-	_, err := d.client.DeleteRelease(d.name, opts...)
 	return prettyError(err)
 }
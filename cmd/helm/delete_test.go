@@ -47,6 +47,20 @@ func TestDelete(t *testing.T) {
 			expected: "",
 			resp:     releaseMock(&releaseOptions{name: "aeneas"}),
 		},
+		{
+			name:     "delete in the foreground and wait",
+			args:     []string{"aeneas"},
+			flags:    []string{"--purge", "--cascade", "foreground", "--wait", "--timeout", "60"},
+			expected: "",
+			resp:     releaseMock(&releaseOptions{name: "aeneas"}),
+		},
+		{
+			name:     "delete kept resources",
+			args:     []string{"aeneas"},
+			flags:    []string{"--include-kept"},
+			expected: "",
+			resp:     releaseMock(&releaseOptions{name: "aeneas"}),
+		},
 		{
 			name: "delete without release",
 			args: []string{},
@@ -205,8 +205,8 @@ func (l *dependencyListCmd) printMissing(reqs *chartutil.Requirements, out io.Wr
 		if err != nil {
 			fmt.Fprintf(l.out, "Warning: %s\n", err)
 		}
-		// Skip anything that is not a directory and not a tgz file.
-		if !fi.IsDir() && filepath.Ext(f) != ".tgz" {
+		// Skip anything that is not a directory and not a chart archive.
+		if !fi.IsDir() && !chartutil.IsArchive(f) {
 			continue
 		}
 		c, err := chartutil.Load(f)
@@ -35,6 +35,8 @@ This command can generate documentation for Helm in the following formats:
 It can also generate bash autocompletions.
 
 	$ helm docs markdown -dir mydocs/
+
+To document the values of a chart instead, use 'helm docs values'.
 `
 
 type docsCmd struct {
@@ -58,6 +60,8 @@ func newDocsCmd(out io.Writer, topCmd *cobra.Command) *cobra.Command {
 		},
 	}
 
+	cmd.AddCommand(newDocsValuesCmd(out))
+
 	f := cmd.Flags()
 	f.StringVar(&dc.dest, "dir", "./", "directory to which documentation is written")
 	f.StringVar(&dc.docTypeString, "type", "markdown", "the type of documentation to generate (markdown, man, bash)")
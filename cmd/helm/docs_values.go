@@ -0,0 +1,137 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/helm/pkg/chartutil"
+)
+
+const docsValuesDesc = `
+This command prints a table of the values of a chart: the path, type and
+default of each value, and its description.
+
+Descriptions are taken from the comments directly above each key in
+values.yaml. If the chart has a values.schema.json, its types and descriptions
+take precedence, and values that only the schema defines are listed too.
+
+The default output is a Markdown table, ready to paste into a chart's README:
+
+	$ helm docs values ./mychart >> mychart/README.md
+`
+
+type docsValuesCmd struct {
+	out     io.Writer
+	chart   string
+	version string
+	output  string
+}
+
+// docsValue is a value in the machine readable output of 'helm docs values'.
+type docsValue struct {
+	Path        string      `json:"path"`
+	Type        string      `json:"type"`
+	Default     interface{} `json:"default"`
+	Description string      `json:"description,omitempty"`
+}
+
+func newDocsValuesCmd(out io.Writer) *cobra.Command {
+	dv := &docsValuesCmd{out: out}
+
+	cmd := &cobra.Command{
+		Use:   "values [flags] CHART",
+		Short: "generate a table of the values of a chart",
+		Long:  docsValuesDesc,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkArgsLength(len(args), "chart name"); err != nil {
+				return err
+			}
+			cp, err := locateChartPath(args[0], dv.version, false, "")
+			if err != nil {
+				return err
+			}
+			dv.chart = cp
+			return dv.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&dv.version, "version", "", "version of the chart. By default, the newest version is used")
+	addOutputFlag(f, &dv.output, "o", "markdown")
+	return cmd
+}
+
+func (d *docsValuesCmd) run() error {
+	format, err := parseOutputFormat(d.output, "markdown")
+	if err != nil {
+		return err
+	}
+
+	ch, err := chartutil.Load(d.chart)
+	if err != nil {
+		return err
+	}
+	var values, schema []byte
+	if ch.Values != nil {
+		values = []byte(ch.Values.Raw)
+	}
+	for _, f := range ch.Files {
+		if f.TypeUrl == chartutil.SchemaFile {
+			schema = f.Value
+		}
+	}
+
+	docs, err := chartutil.DocumentValues(values, schema)
+	if err != nil {
+		return fmt.Errorf("cannot document the values of %s: %s", ch.Metadata.Name, err)
+	}
+
+	if !format.human() {
+		out := []docsValue{}
+		for _, v := range docs {
+			out = append(out, docsValue{
+				Path:        v.Path,
+				Type:        v.Type,
+				Default:     v.Default,
+				Description: v.Description,
+			})
+		}
+		return format.write(d.out, out)
+	}
+
+	fmt.Fprintln(d.out, "| Key | Type | Default | Description |")
+	fmt.Fprintln(d.out, "|-----|------|---------|-------------|")
+	for _, v := range docs {
+		def, err := json.Marshal(v.Default)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(d.out, "| `%s` | %s | `%s` | %s |\n", v.Path, markdownCell(v.Type), markdownCell(string(def)), markdownCell(v.Description))
+	}
+	return nil
+}
+
+// markdownCell escapes the characters that would end a Markdown table cell.
+func markdownCell(s string) string {
+	return strings.Replace(s, "|", `\|`, -1)
+}
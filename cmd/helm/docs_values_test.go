@@ -0,0 +1,54 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestDocsValues(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	cmd := newDocsValuesCmd(buf)
+	if err := cmd.RunE(cmd, []string{"testdata/testcharts/prompts"}); err != nil {
+		t.Fatal(err)
+	}
+	expect := "| Key | Type | Default | Description |\n" +
+		"|-----|------|---------|-------------|\n" +
+		"| `adminPassword` | string | `\"\"` | Admin password |\n" +
+		"| `db.replicas` | int | `1` | Number of replicas |\n" +
+		"| `db.storageClass` | string\\|null | `null` | Storage class of the volumes |\n" +
+		"| `size` | string | `\"small\"` | Size of the cluster: small, medium or large |\n"
+	if got := buf.String(); got != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, got)
+	}
+
+	buf.Reset()
+	cmd = newDocsValuesCmd(buf)
+	cmd.ParseFlags([]string{"-o", "json"})
+	if err := cmd.RunE(cmd, []string{"testdata/testcharts/alpine"}); err != nil {
+		t.Fatal(err)
+	}
+	var got []docsValue
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("%s: %s", err, buf.String())
+	}
+	if len(got) != 1 || got[0].Path != "Name" || got[0].Default != "my-alpine" || got[0].Description != "The pod name" {
+		t.Errorf("expected the values of alpine, got %s", buf.String())
+	}
+}
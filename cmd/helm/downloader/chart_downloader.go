@@ -21,13 +21,14 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
-	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"k8s.io/helm/cmd/helm/helmpath"
+	"k8s.io/helm/pkg/chartref"
+	"k8s.io/helm/pkg/chartutil"
 	"k8s.io/helm/pkg/provenance"
 	"k8s.io/helm/pkg/repo"
 )
@@ -62,6 +63,11 @@ type ChartDownloader struct {
 	Keyring string
 	// HelmHome is the $HELM_HOME.
 	HelmHome helmpath.Home
+	// Progress, if set, is where a progress bar is drawn while the chart
+	// archive downloads. It should be a terminal.
+	Progress io.Writer
+	// LimitRate, if positive, caps downloads at that many bytes per second.
+	LimitRate int64
 }
 
 // DownloadTo retrieves a chart. Depending on the settings, it may also download a provenance file.
@@ -73,52 +79,123 @@ type ChartDownloader struct {
 //
 // For VerifyNever and VerifyIfPossible, the Verification may be empty.
 //
+// A chart from a repository with a verification policy (the Verify field of
+// its entry in the repositories file) is verified as that policy requires,
+// and a repository's keyring replaces Keyring.
+//
+// The archive is verified in memory as it downloads, and is only saved once
+// it has passed, so a chart that fails verification never reaches dest.
+//
 // Returns a string path to the location where the file was downloaded and a verification
 // (if provenance was verified), or an error if something bad happened.
 func (c *ChartDownloader) DownloadTo(ref, version, dest string) (string, *provenance.Verification, error) {
-	// resolve URL
-	u, err := c.ResolveChartVersion(ref, version)
+	d, err := c.download(ref, version)
 	if err != nil {
-		return "", nil, err
+		return "", d.ver, err
+	}
+
+	destfile := filepath.Join(dest, d.name)
+	if err := ioutil.WriteFile(destfile, d.data.Bytes(), 0655); err != nil {
+		return destfile, nil, err
+	}
+	if d.prov != nil {
+		if err := ioutil.WriteFile(destfile+".prov", d.prov.Bytes(), 0655); err != nil {
+			return destfile, nil, err
+		}
 	}
-	data, err := download(u.String())
+	return destfile, d.ver, nil
+}
+
+// Download retrieves a chart into memory, verifying it as DownloadTo does
+// while it downloads. Nothing is written to disk.
+//
+// VerifyLater is not supported, as there is nowhere to keep the provenance
+// file for later.
+func (c *ChartDownloader) Download(ref, version string) (*bytes.Buffer, *provenance.Verification, error) {
+	if c.Verify == VerifyLater {
+		return nil, nil, errors.New("charts downloaded into memory cannot be verified later")
+	}
+	d, err := c.download(ref, version)
 	if err != nil {
-		return "", nil, err
+		return nil, d.ver, err
 	}
+	return d.data, d.ver, nil
+}
 
-	name := filepath.Base(u.Path)
-	destfile := filepath.Join(dest, name)
-	if err := ioutil.WriteFile(destfile, data.Bytes(), 0655); err != nil {
-		return destfile, nil, err
+// downloaded is a chart archive, and its provenance file if there is one,
+// downloaded into memory.
+type downloaded struct {
+	name string
+	data *bytes.Buffer
+	prov *bytes.Buffer
+	ver  *provenance.Verification
+}
+
+func (c *ChartDownloader) download(ref, version string) (*downloaded, error) {
+	d := &downloaded{ver: &provenance.Verification{}}
+
+	// resolve URL
+	u, err := c.ResolveChartVersion(ref, version)
+	if err != nil {
+		return d, err
 	}
+	d.name = filepath.Base(u.Path)
 
-	// If provenance is requested, verify it.
-	ver := &provenance.Verification{}
-	if c.Verify > VerifyNever {
+	re := c.sourceRepo(ref, u.String())
+	strategy, keyring, err := c.verification(re)
+	if err != nil {
+		return d, err
+	}
 
-		body, err := download(u.String() + ".prov")
+	// If provenance is requested, fetch it first, so that the archive can be
+	// verified as it arrives.
+	if strategy > VerifyNever {
+		d.prov, err = downloadWith(u.String()+".prov", nil, "", c.LimitRate)
 		if err != nil {
-			if c.Verify == VerifyAlways {
-				return destfile, ver, fmt.Errorf("Failed to fetch provenance %q", u.String()+".prov")
+			if strategy == VerifyAlways {
+				return d, fmt.Errorf("Failed to fetch provenance %q", u.String()+".prov")
 			}
 			fmt.Fprintf(c.Out, "WARNING: Verification not found for %s: %s\n", ref, err)
-			return destfile, ver, nil
+			d.prov = nil
+			strategy = VerifyNever
 		}
-		provfile := destfile + ".prov"
-		if err := ioutil.WriteFile(provfile, body.Bytes(), 0655); err != nil {
-			return destfile, nil, err
+	}
+
+	var verifier *provenance.Verifier
+	if strategy == VerifyAlways || strategy == VerifyIfPossible {
+		if !isTar(d.name) {
+			return d, errors.New("chart must be a tgz file")
+		}
+		sig, err := provenance.NewFromKeyring(keyring, "")
+		if err != nil {
+			return d, fmt.Errorf("failed to load keyring: %s", err)
 		}
+		// Fail always in this case, since it means the verification step
+		// failed.
+		verifier, err = sig.NewVerifier(d.name, d.prov.Bytes())
+		if err != nil {
+			return d, err
+		}
+	}
 
-		if c.Verify != VerifyLater {
-			ver, err = VerifyChart(destfile, c.Keyring)
-			if err != nil {
-				// Fail always in this case, since it means the verification step
-				// failed.
-				return destfile, ver, err
-			}
+	d.data = bytes.NewBuffer(nil)
+	var w io.Writer = d.data
+	if verifier != nil {
+		w = io.MultiWriter(d.data, verifier)
+	}
+	if err := fetch(w, u.String(), c.Progress, d.name, c.LimitRate); err != nil {
+		return d, err
+	}
+
+	if verifier != nil {
+		if d.ver, err = verifier.Verify(); err != nil {
+			return d, err
+		}
+		if err := c.checkTrust(re, u, d.data.Bytes(), d.ver); err != nil {
+			return d, err
 		}
 	}
-	return destfile, ver, nil
+	return d, nil
 }
 
 // ResolveChartVersion resolves a chart reference to a URL.
@@ -133,55 +210,189 @@ func (c *ChartDownloader) DownloadTo(ref, version, dest string) (string, *proven
 //		* If version is empty, this will return the URL for the latest version
 // 		* If no version can be found, an error is returned
 func (c *ChartDownloader) ResolveChartVersion(ref, version string) (*url.URL, error) {
-	// See if it's already a full URL.
-	// FIXME: Why do we use url.ParseRequestURI instead of url.Parse?
-	u, err := url.ParseRequestURI(ref)
-	if err == nil {
-		// If it has a scheme and host and path, it's a full URL
-		if u.IsAbs() && len(u.Host) > 0 && len(u.Path) > 0 {
-			return u, nil
+	r, err := chartref.ParseRemote(ref)
+	if err != nil {
+		if e, ok := err.(*chartref.Error); ok && e.Suggestion == "" {
+			e.Suggestion = c.Suggest("", strings.TrimSpace(ref))
 		}
-		return u, fmt.Errorf("invalid chart url format: %s", ref)
+		return nil, err
 	}
-
-	r, err := repo.LoadRepositoriesFile(c.HelmHome.RepositoryFile())
-	if err != nil {
-		return u, err
+	if err := chartref.ValidateVersion(version); err != nil {
+		return nil, err
+	}
+	switch r.Kind {
+	case chartref.URL:
+		return url.Parse(r.Location)
+	case chartref.OCI:
+		return nil, fmt.Errorf("charts cannot be downloaded from OCI registries: index the registry with 'helm repo index --from-registry' and add the index as a repository")
+	case chartref.Path:
+		return nil, fmt.Errorf("invalid chart url format: %s", ref)
 	}
 
-	// See if it's of the form: repo/path_to_chart
-	p := strings.SplitN(ref, "/", 2)
-	if len(p) < 2 {
-		return u, fmt.Errorf("invalid chart url format: %s", ref)
+	rf, err := repo.LoadRepositoriesFile(c.HelmHome.RepositoryFile())
+	if err != nil {
+		return nil, err
 	}
 
-	repoName := p[0]
-	chartName := p[1]
-	rf, err := findRepoEntry(repoName, r.Repositories)
+	re, err := findRepoEntry(r.Repo, rf.Repositories)
 	if err != nil {
-		return u, err
+		if s := c.Suggest(r.Repo, r.Name); s != "" {
+			return nil, fmt.Errorf("%s. Did you mean %q?", err, s)
+		}
+		return nil, err
 	}
-	if rf.URL == "" {
-		return u, fmt.Errorf("no URL found for repository %q", repoName)
+	if re.URL == "" {
+		return nil, fmt.Errorf("no URL found for repository %q", r.Repo)
 	}
 
 	// Next, we need to load the index, and actually look up the chart.
-	i, err := repo.LoadIndexFile(c.HelmHome.CacheIndex(repoName))
+	i, err := repo.LoadCachedIndexFile(c.HelmHome.CacheIndex(r.Repo))
 	if err != nil {
-		return u, fmt.Errorf("no cached repo found. (try 'helm repo update'). %s", err)
+		return nil, fmt.Errorf("no cached repo found. (try 'helm repo update'). %s", err)
 	}
 
-	cv, err := i.Get(chartName, version)
+	cv, err := i.Get(r.Name, version)
 	if err != nil {
-		return u, fmt.Errorf("chart %q not found in %s index. (try 'helm repo update'). %s", chartName, repoName, err)
+		msg := fmt.Sprintf("chart %q not found in %s index. (try 'helm repo update'). %s", r.Name, r.Repo, err)
+		if _, ok := i.Entries[r.Name]; !ok {
+			if s := c.Suggest(r.Repo, r.Name); s != "" {
+				msg += fmt.Sprintf(". Did you mean %q?", s)
+			}
+		}
+		return nil, errors.New(msg)
 	}
 
 	if len(cv.URLs) == 0 {
-		return u, fmt.Errorf("chart %q has no downloadable URLs", ref)
+		return nil, fmt.Errorf("chart %q has no downloadable URLs", ref)
 	}
 	return url.Parse(cv.URLs[0])
 }
 
+// Suggest returns the REPO/NAME reference of the chart that a reference to
+// the chart name in the repository repoName was most likely meant to be, or
+// "" if there is none. The other charts of the repository are considered
+// first, and then, or if repoName is empty or not known, the charts of every
+// repository.
+func (c *ChartDownloader) Suggest(repoName, name string) string {
+	if c.HelmHome == "" {
+		return ""
+	}
+	rf, err := repo.LoadRepositoriesFile(c.HelmHome.RepositoryFile())
+	if err != nil {
+		return ""
+	}
+	names := func(re *repo.Entry) []string {
+		i, err := repo.LoadCachedIndexFile(c.HelmHome.CacheIndex(re.Name))
+		if err != nil {
+			return nil
+		}
+		res := make([]string, 0, len(i.Entries))
+		for n := range i.Entries {
+			res = append(res, n)
+		}
+		return res
+	}
+
+	if re, err := findRepoEntry(repoName, rf.Repositories); err == nil {
+		if s := chartref.Suggest(name, names(re)); s != "" {
+			return re.Name + "/" + s
+		}
+	}
+	// The first repository with a chart of the suggested name wins.
+	refs := map[string]string{}
+	all := []string{}
+	for _, re := range rf.Repositories {
+		for _, n := range names(re) {
+			if _, ok := refs[n]; !ok {
+				refs[n] = re.Name + "/" + n
+				all = append(all, n)
+			}
+		}
+	}
+	if s := chartref.Suggest(name, all); s != "" {
+		return refs[s]
+	}
+	return ""
+}
+
+// verification returns how to verify a chart from the repository re, which
+// may be nil: with the strategy the downloader is set to, made stricter or
+// looser by the verification policy of the repository, and with the keyring
+// of the repository, if it has one.
+func (c *ChartDownloader) verification(re *repo.Entry) (VerificationStrategy, string, error) {
+	if re == nil {
+		return c.Verify, c.Keyring, nil
+	}
+	keyring := c.Keyring
+	if re.Keyring != "" {
+		keyring = re.Keyring
+	}
+	switch re.Verify {
+	case "":
+		return c.Verify, keyring, nil
+	case repo.VerifyAlways:
+		return VerifyAlways, keyring, nil
+	case repo.VerifyIfPresent:
+		if c.Verify == VerifyAlways {
+			return VerifyAlways, keyring, nil
+		}
+		return VerifyIfPossible, keyring, nil
+	case repo.VerifyNever:
+		// An explicit request to verify still applies.
+		if c.Verify == VerifyIfPossible {
+			return VerifyNever, keyring, nil
+		}
+		return c.Verify, keyring, nil
+	}
+	return c.Verify, keyring, fmt.Errorf("repository %q has an invalid verify policy %q: must be one of always, ifpresent or never", re.Name, re.Verify)
+}
+
+// checkTrust checks a verified chart archive, from the repository re (which
+// may be nil), against the trust policy in HelmHome, if there is one.
+func (c *ChartDownloader) checkTrust(re *repo.Entry, u *url.URL, archive []byte, ver *provenance.Verification) error {
+	if c.HelmHome == "" {
+		return nil
+	}
+	policy, err := LoadTrustPolicy(c.HelmHome.TrustPolicyFile())
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	ch, err := chartutil.LoadArchive(bytes.NewReader(archive))
+	if err != nil {
+		return err
+	}
+	repoName := ""
+	if re != nil {
+		repoName = re.Name
+	}
+	return policy.Check(repoName, u.String(), ch.Metadata.Name, ver)
+}
+
+// sourceRepo returns the repository a chart reference resolving to href comes
+// from: the repository named by a repo/chart reference, or the one whose URL a
+// chart URL is under. It returns nil if there is none.
+func (c *ChartDownloader) sourceRepo(ref, href string) *repo.Entry {
+	if c.HelmHome == "" {
+		return nil
+	}
+	rf, err := repo.LoadRepositoriesFile(c.HelmHome.RepositoryFile())
+	if err != nil {
+		return nil
+	}
+	if _, err := url.ParseRequestURI(ref); err != nil {
+		re, _ := findRepoEntry(strings.SplitN(ref, "/", 2)[0], rf.Repositories)
+		return re
+	}
+	for _, re := range rf.Repositories {
+		if re.URL != "" && strings.HasPrefix(href, strings.TrimSuffix(re.URL, "/")+"/") {
+			return re
+		}
+	}
+	return nil
+}
+
 func findRepoEntry(name string, repos []*repo.Entry) (*repo.Entry, error) {
 	for _, re := range repos {
 		if re.Name == name {
@@ -219,19 +430,37 @@ func VerifyChart(path string, keyring string) (*provenance.Verification, error)
 
 // download performs a simple HTTP Get and returns the body.
 func download(href string) (*bytes.Buffer, error) {
+	return downloadWith(href, nil, "", 0)
+}
+
+// downloadWith is download, drawing a progress bar labeled name on progress
+// if it is not nil, and reading no faster than rate bytes per second if rate
+// is positive.
+func downloadWith(href string, progress io.Writer, name string, rate int64) (*bytes.Buffer, error) {
 	buf := bytes.NewBuffer(nil)
+	return buf, fetch(buf, href, progress, name, rate)
+}
 
-	resp, err := http.Get(href)
+// fetch is downloadWith, writing the body to w as it arrives.
+func fetch(w io.Writer, href string, progress io.Writer, name string, rate int64) error {
+	resp, err := repo.Get(href)
 	if err != nil {
-		return buf, err
+		return err
 	}
+	defer resp.Body.Close()
 	if resp.StatusCode != 200 {
-		return buf, fmt.Errorf("Failed to fetch %s : %s", href, resp.Status)
+		return fmt.Errorf("Failed to fetch %s : %s", href, resp.Status)
 	}
 
-	_, err = io.Copy(buf, resp.Body)
-	resp.Body.Close()
-	return buf, err
+	var body io.Reader = resp.Body
+	if rate > 0 {
+		body = newRateLimitedReader(body, rate)
+	}
+	if progress != nil {
+		body = newProgressReader(body, progress, name, resp.ContentLength)
+	}
+	_, err = io.Copy(w, body)
+	return err
 }
 
 // isTar tests whether the given file is a tar file.
@@ -239,5 +468,5 @@ func download(href string) (*bytes.Buffer, error) {
 // Currently, this simply checks extension, since a subsequent function will
 // untar the file and validate its binary format.
 func isTar(filename string) bool {
-	return strings.ToLower(filepath.Ext(filename)) == ".tgz"
+	return chartutil.IsArchive(filename)
 }
@@ -16,15 +16,18 @@ limitations under the License.
 package downloader
 
 import (
+	"bytes"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"k8s.io/helm/cmd/helm/helmpath"
+	"k8s.io/helm/pkg/repo"
 	"k8s.io/helm/pkg/repo/repotest"
 )
 
@@ -63,6 +66,35 @@ func TestResolveChartRef(t *testing.T) {
 	}
 }
 
+func TestResolveChartRefSuggestions(t *testing.T) {
+	tests := []struct {
+		ref, expect string
+	}{
+		{ref: "testing/alpin", expect: `Did you mean "testing/alpine"?`},
+		{ref: "testing/maria", expect: `Did you mean "kubernetes-charts/mariadb"?`},
+		{ref: "testng/alpine", expect: `no repo named "testng". Did you mean "testing/alpine"?`},
+		{ref: "mariadb", expect: `Did you mean "kubernetes-charts/mariadb"?`},
+		{ref: "testing:alpine", expect: `Did you mean "testing/alpine"?`},
+		{ref: "oci://registry.example.com/project/alpine", expect: "OCI registries"},
+		{ref: "testing/alpine/extra", expect: "more than one '/'"},
+	}
+
+	c := ChartDownloader{
+		HelmHome: helmpath.Home("testdata/helmhome"),
+		Out:      os.Stderr,
+	}
+	for _, tt := range tests {
+		_, err := c.ResolveChartVersion(tt.ref, "")
+		if err == nil || !strings.Contains(err.Error(), tt.expect) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.ref, tt.expect, err)
+		}
+	}
+
+	if _, err := c.ResolveChartVersion("testing/alpine", ">= 1.0 <"); err == nil {
+		t.Error("expected an invalid version to fail")
+	}
+}
+
 func TestVerifyChart(t *testing.T) {
 	v, err := VerifyChart("testdata/signtest-0.1.0.tgz", "testdata/helm-test-key.pub")
 	if err != nil {
@@ -97,6 +129,7 @@ func TestIsTar(t *testing.T) {
 		"foo.tgz":           true,
 		"foo/bar/baz.tgz":   true,
 		"foo-1.2.3.4.5.tgz": true,
+		"foo-1.2.3.tzst":    true,
 		"foo.tar.gz":        false, // for our purposes
 		"foo.tgz.1":         false,
 		"footgz":            false,
@@ -197,3 +230,155 @@ func TestDownloadTo_VerifyLater(t *testing.T) {
 		return
 	}
 }
+
+func TestDownloadIntoMemory(t *testing.T) {
+	hh, err := ioutil.TempDir("", "helm-downloadto-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(hh)
+
+	srv := repotest.NewServer(hh)
+	defer srv.Stop()
+	if _, err := srv.CopyCharts("testdata/*.tgz*"); err != nil {
+		t.Fatal(err)
+	}
+	expect, err := ioutil.ReadFile("testdata/signtest-0.1.0.tgz")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := ChartDownloader{
+		HelmHome: helmpath.Home("testdata/helmhome"),
+		Out:      ioutil.Discard,
+		Verify:   VerifyAlways,
+		Keyring:  "testdata/helm-test-key.pub",
+	}
+	data, v, err := c.Download(srv.URL()+"/signtest-0.1.0.tgz", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data.Bytes(), expect) {
+		t.Error("expected the downloaded archive to match the chart")
+	}
+	if v.FileHash == "" {
+		t.Error("File hash was empty, but verification is required.")
+	}
+
+	c.Verify = VerifyLater
+	if _, _, err := c.Download(srv.URL()+"/signtest-0.1.0.tgz", ""); err == nil {
+		t.Error("expected VerifyLater to fail for a download into memory")
+	}
+}
+
+func TestDownloadTampered(t *testing.T) {
+	hh, err := ioutil.TempDir("", "helm-downloadto-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(hh)
+
+	dest := filepath.Join(hh, "dest")
+	os.MkdirAll(dest, 0755)
+
+	srv := repotest.NewServer(hh)
+	defer srv.Stop()
+	if _, err := srv.CopyCharts("testdata/*.tgz*"); err != nil {
+		t.Fatal(err)
+	}
+	// Change the archive, but not its provenance file.
+	data, err := ioutil.ReadFile("testdata/signtest-0.1.0.tgz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = append(data, 0)
+	if err := ioutil.WriteFile(filepath.Join(srv.Root(), "signtest-0.1.0.tgz"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := ChartDownloader{
+		HelmHome: helmpath.Home("testdata/helmhome"),
+		Out:      ioutil.Discard,
+		Verify:   VerifyAlways,
+		Keyring:  "testdata/helm-test-key.pub",
+	}
+	if _, _, err := c.DownloadTo(srv.URL()+"/signtest-0.1.0.tgz", "", dest); err == nil || !strings.Contains(err.Error(), "sum does not match") {
+		t.Errorf("expected a tampered chart to fail verification, got %v", err)
+	}
+	if files, _ := ioutil.ReadDir(dest); len(files) != 0 {
+		t.Errorf("expected a tampered chart not to be saved, found %d files", len(files))
+	}
+	if _, _, err := c.Download(srv.URL()+"/signtest-0.1.0.tgz", ""); err == nil {
+		t.Error("expected a tampered chart to fail verification in memory")
+	}
+}
+
+func TestDownloadToRepoVerifyPolicy(t *testing.T) {
+	hh, err := ioutil.TempDir("", "helm-downloadto-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(hh)
+
+	dest := filepath.Join(hh, "dest")
+	os.MkdirAll(dest, 0755)
+
+	srv := repotest.NewServer(hh)
+	defer srv.Stop()
+	if _, err := srv.CopyCharts("testdata/*.tgz*"); err != nil {
+		t.Fatal(err)
+	}
+	// A chart without a provenance file.
+	data, err := ioutil.ReadFile("testdata/signtest-0.1.0.tgz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(srv.Root(), "unsigned-0.1.0.tgz"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		policy   string
+		strategy VerificationStrategy
+		chart    string
+		verified bool
+		fail     string
+	}{
+		{policy: repo.VerifyAlways, chart: "signtest-0.1.0.tgz", verified: true},
+		{policy: repo.VerifyAlways, chart: "unsigned-0.1.0.tgz", fail: "Failed to fetch provenance"},
+		{policy: repo.VerifyIfPresent, chart: "signtest-0.1.0.tgz", verified: true},
+		{policy: repo.VerifyIfPresent, chart: "unsigned-0.1.0.tgz"},
+		{policy: repo.VerifyNever, strategy: VerifyIfPossible, chart: "signtest-0.1.0.tgz"},
+		{policy: repo.VerifyNever, strategy: VerifyAlways, chart: "signtest-0.1.0.tgz", verified: true},
+		{policy: "sometimes", chart: "signtest-0.1.0.tgz", fail: "invalid verify policy"},
+	}
+	for _, tt := range tests {
+		rf := repo.NewRepoFile()
+		rf.Add(&repo.Entry{Name: "test", URL: srv.URL(), Verify: tt.policy, Keyring: "testdata/helm-test-key.pub"})
+		if err := rf.WriteFile(helmpath.Home(hh).RepositoryFile(), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		// The keyring of the repository is used rather than the downloader's.
+		c := ChartDownloader{
+			HelmHome: helmpath.Home(hh),
+			Out:      ioutil.Discard,
+			Verify:   tt.strategy,
+			Keyring:  "testdata/no-such-keyring.gpg",
+		}
+		_, v, err := c.DownloadTo(srv.URL()+"/"+tt.chart, "", dest)
+		if tt.fail != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.fail) {
+				t.Errorf("%s %s: expected an error containing %q, got %v", tt.policy, tt.chart, tt.fail, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s %s: %s", tt.policy, tt.chart, err)
+			continue
+		}
+		if verified := v.FileHash != ""; verified != tt.verified {
+			t.Errorf("%s %s: expected verified=%t, got %t", tt.policy, tt.chart, tt.verified, verified)
+		}
+	}
+}
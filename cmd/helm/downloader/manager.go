@@ -32,6 +32,7 @@ import (
 
 	"k8s.io/helm/cmd/helm/helmpath"
 	"k8s.io/helm/cmd/helm/resolver"
+	"k8s.io/helm/pkg/chartref"
 	"k8s.io/helm/pkg/chartutil"
 	"k8s.io/helm/pkg/proto/hapi/chart"
 	"k8s.io/helm/pkg/repo"
@@ -115,6 +116,9 @@ func (m *Manager) Update() error {
 		}
 		return err
 	}
+	if err := validateDependencies(req.Dependencies); err != nil {
+		return err
+	}
 
 	// Check that all of the repos we're dependent on actually exist and
 	// the repo index names.
@@ -210,6 +214,27 @@ func (m *Manager) downloadAll(deps []*chartutil.Dependency) error {
 	return nil
 }
 
+// validateDependencies checks the names, versions and repositories of the
+// dependencies in requirements.yaml, so that a mistake fails before anything
+// is downloaded.
+func validateDependencies(deps []*chartutil.Dependency) error {
+	for _, d := range deps {
+		if err := chartref.ValidateName(d.Name); err != nil {
+			return fmt.Errorf("requirements.yaml: %s", err)
+		}
+		if err := chartref.ValidateVersion(d.Version); err != nil {
+			return fmt.Errorf("requirements.yaml: dependency %q has an %s", d.Name, err)
+		}
+		if d.Repository == "" {
+			continue
+		}
+		if err := chartref.ValidateRepoURL(d.Repository); err != nil {
+			return fmt.Errorf("requirements.yaml: dependency %q has an %s", d.Name, err)
+		}
+	}
+	return nil
+}
+
 // hasAllRepos ensures that all of the referenced deps are in the local repo cache.
 func (m *Manager) hasAllRepos(deps []*chartutil.Dependency) error {
 	rf, err := repo.LoadRepositoriesFile(m.HelmHome.RepositoryFile())
@@ -294,14 +319,14 @@ func (m *Manager) parallelRepoUpdate(repos []*repo.Entry) {
 	var wg sync.WaitGroup
 	for _, re := range repos {
 		wg.Add(1)
-		go func(n, u string) {
-			if err := repo.DownloadIndexFile(n, u, m.HelmHome.CacheIndex(n)); err != nil {
-				fmt.Fprintf(out, "...Unable to get an update from the %q chart repository (%s):\n\t%s\n", n, u, err)
+		go func(re *repo.Entry) {
+			if err := re.DownloadIndex(m.HelmHome.CacheIndex(re.Name)); err != nil {
+				fmt.Fprintf(out, "...Unable to get an update from the %q chart repository (%s):\n\t%s\n", re.Name, re.URL, err)
 			} else {
-				fmt.Fprintf(out, "...Successfully got an update from the %q chart repository\n", n)
+				fmt.Fprintf(out, "...Successfully got an update from the %q chart repository\n", re.Name)
 			}
 			wg.Done()
-		}(re.Name, re.URL)
+		}(re)
 	}
 	wg.Wait()
 	fmt.Fprintln(out, "Update Complete. ⎈Happy Helming!⎈")
@@ -434,7 +459,7 @@ func (m *Manager) loadChartRepositories() (map[string]*repo.ChartRepository, err
 	for _, re := range rf.Repositories {
 		lname := re.Name
 		cacheindex := m.HelmHome.CacheIndex(lname)
-		index, err := repo.LoadIndexFile(cacheindex)
+		index, err := repo.LoadCachedIndexFile(cacheindex)
 		if err != nil {
 			return indices, err
 		}
@@ -0,0 +1,140 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package downloader
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/repo"
+)
+
+// ErrNoPartialRead is returned by LoadFiles for a chart that can only be read
+// by downloading all of it.
+var ErrNoPartialRead = errors.New("chart cannot be read in part")
+
+// tailSize is how much of the end of an archive is fetched first. It holds
+// the index of the archive, and all of it for most charts.
+const tailSize = 64 << 10
+
+// LoadFiles loads only the named files of the chart that ref refers to, such
+// as Chart.yaml and values.yaml, without downloading the rest of it.
+//
+// This works for a chart packaged as a tzst archive that does not need to be
+// verified. For other charts it returns ErrNoPartialRead, and the chart has to
+// be downloaded with DownloadTo instead. If the server does not support
+// range requests, the whole archive is fetched, but not written anywhere.
+func (c *ChartDownloader) LoadFiles(ref, version string, names ...string) (*chart.Chart, error) {
+	u, err := c.ResolveChartVersion(ref, version)
+	if err != nil {
+		return nil, err
+	}
+	if strings.ToLower(path.Ext(u.Path)) != chartutil.FormatTzst.Ext() {
+		return nil, ErrNoPartialRead
+	}
+	strategy, _, err := c.verification(c.sourceRepo(ref, u.String()))
+	if err != nil {
+		return nil, err
+	}
+	if strategy != VerifyNever {
+		return nil, ErrNoPartialRead
+	}
+
+	r, err := newRangeReader(u.String())
+	if err != nil {
+		return nil, err
+	}
+	return chartutil.LoadArchiveFiles(r, r.size, names...)
+}
+
+// rangeReader reads a remote file with HTTP range requests.
+type rangeReader struct {
+	href string
+	size int64
+	// tail is the end of the file, fetched when the reader is created, and
+	// tailOff is where in the file it starts.
+	tail    []byte
+	tailOff int64
+}
+
+func newRangeReader(href string) (*rangeReader, error) {
+	req, err := http.NewRequest("GET", href, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=-%d", tailSize))
+	resp, err := repo.DefaultRetryPolicy.Do(repo.Client(), req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		// The server ignored the range and sent all of the file.
+		data, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		return &rangeReader{href: href, size: int64(len(data)), tail: data}, nil
+	case http.StatusPartialContent:
+		var start, end, size int64
+		cr := resp.Header.Get("Content-Range")
+		if _, err := fmt.Sscanf(cr, "bytes %d-%d/%d", &start, &end, &size); err != nil || start < 0 || end < start || end >= size {
+			return nil, fmt.Errorf("Failed to fetch %s : invalid Content-Range %q", href, cr)
+		}
+		data := make([]byte, end-start+1)
+		if _, err := io.ReadFull(resp.Body, data); err != nil {
+			return nil, err
+		}
+		return &rangeReader{href: href, size: size, tail: data, tailOff: start}, nil
+	}
+	return nil, fmt.Errorf("Failed to fetch %s : %s", href, resp.Status)
+}
+
+// ReadAt implements io.ReaderAt. Reads from the tail are served from memory,
+// and any other read is a request of its own.
+func (r *rangeReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 || off+int64(len(p)) > r.size {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if off >= r.tailOff {
+		return copy(p, r.tail[off-r.tailOff:]), nil
+	}
+
+	req, err := http.NewRequest("GET", r.href, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", off, off+int64(len(p))-1))
+	resp, err := repo.DefaultRetryPolicy.Do(repo.Client(), req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent {
+		return 0, fmt.Errorf("Failed to fetch %s : %s", r.href, resp.Status)
+	}
+	return io.ReadFull(resp.Body, p)
+}
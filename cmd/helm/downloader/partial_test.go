@@ -0,0 +1,106 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package downloader
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+)
+
+// countingWriter counts the bytes of the response bodies a server writes.
+type countingWriter struct {
+	http.ResponseWriter
+	n *int
+}
+
+func (w countingWriter) Write(b []byte) (int, error) {
+	*w.n += len(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func TestLoadFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-partial-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// A template that does not compress, so that not downloading it shows.
+	big := make([]byte, 1<<20)
+	rand.New(rand.NewSource(1)).Read(big)
+	c := &chart.Chart{
+		Metadata:  &chart.Metadata{Name: "ahab", Version: "1.2.3"},
+		Values:    &chart.Config{Raw: "ship: Pequod"},
+		Templates: []*chart.Template{{Name: "templates/whale.yaml", Data: big}},
+	}
+	if _, err := chartutil.SaveArchive(c, dir, chartutil.FormatTzst); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chartutil.Save(c, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	served := 0
+	fs := http.FileServer(http.Dir(dir))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fs.ServeHTTP(countingWriter{w, &served}, r)
+	}))
+	defer srv.Close()
+
+	dl := &ChartDownloader{}
+	c2, err := dl.LoadFiles(srv.URL+"/ahab-1.2.3.tzst", "", chartutil.ChartfileName, chartutil.ValuesfileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c2.Metadata.Name != "ahab" || c2.Values.Raw != "ship: Pequod" {
+		t.Errorf("Unexpected chart %v", c2)
+	}
+	if served > len(big)/10 {
+		t.Errorf("Expected a fraction of the archive to be downloaded, got %d bytes", served)
+	}
+
+	if _, err := dl.LoadFiles(srv.URL+"/ahab-1.2.3.tgz", ""); err != ErrNoPartialRead {
+		t.Errorf("Expected ErrNoPartialRead for a tgz archive, got %v", err)
+	}
+	// Servers that ignore ranges send the whole archive.
+	whole := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := ioutil.ReadFile(filepath.Join(dir, "ahab-1.2.3.tzst"))
+		if err != nil {
+			t.Error(err)
+		}
+		w.Write(data)
+	}))
+	defer whole.Close()
+	if c2, err = dl.LoadFiles(whole.URL+"/ahab-1.2.3.tzst", "", chartutil.ChartfileName); err != nil {
+		t.Fatal(err)
+	} else if c2.Metadata.Name != "ahab" {
+		t.Errorf("Unexpected chart %v", c2)
+	}
+
+	dl.Verify = VerifyAlways
+	if _, err := dl.LoadFiles(srv.URL+"/ahab-1.2.3.tzst", ""); err != ErrNoPartialRead {
+		t.Errorf("Expected ErrNoPartialRead when verifying, got %v", err)
+	}
+}
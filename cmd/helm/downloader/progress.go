@@ -0,0 +1,136 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package downloader
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// progressInterval is how often the progress bar is redrawn.
+const progressInterval = 200 * time.Millisecond
+
+// progressWidth is the width of the bar itself, in characters.
+const progressWidth = 30
+
+// progressReader draws a progress bar on out as the underlying reader is read.
+//
+// The bar is redrawn in place with a carriage return, so out should be a
+// terminal.
+type progressReader struct {
+	r     io.Reader
+	out   io.Writer
+	name  string
+	total int64 // -1 if unknown
+
+	read  int64
+	start time.Time
+	drawn time.Time
+	now   func() time.Time
+}
+
+func newProgressReader(r io.Reader, out io.Writer, name string, total int64) *progressReader {
+	return &progressReader{r: r, out: out, name: name, total: total, now: time.Now}
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	if p.start.IsZero() {
+		p.start = p.now()
+	}
+	n, err := p.r.Read(b)
+	p.read += int64(n)
+	if now := p.now(); now.Sub(p.drawn) >= progressInterval || err != nil {
+		p.drawn = now
+		fmt.Fprintf(p.out, "\r%s", p.line(now.Sub(p.start)))
+	}
+	if err == io.EOF {
+		fmt.Fprintln(p.out)
+	}
+	return n, err
+}
+
+// line renders the progress line after elapsed time.
+func (p *progressReader) line(elapsed time.Duration) string {
+	if p.total <= 0 {
+		return fmt.Sprintf("%s %s", p.name, formatBytes(p.read))
+	}
+	pct := p.read * 100 / p.total
+	if pct > 100 {
+		pct = 100
+	}
+	filled := int(pct) * progressWidth / 100
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", progressWidth-filled)
+
+	eta := "--"
+	if p.read > 0 && p.read < p.total {
+		left := time.Duration(float64(elapsed) * float64(p.total-p.read) / float64(p.read))
+		eta = (left / time.Second * time.Second).String()
+	} else if p.read >= p.total {
+		eta = "0s"
+	}
+	return fmt.Sprintf("%s [%s] %3d%% %s/%s ETA %s", p.name, bar, pct, formatBytes(p.read), formatBytes(p.total), eta)
+}
+
+// formatBytes formats n bytes with a binary unit, e.g. "1.5MiB".
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// rateLimitedReader reads from the underlying reader at no more than rate
+// bytes per second, averaged over the whole read.
+type rateLimitedReader struct {
+	r    io.Reader
+	rate int64
+
+	read  int64
+	start time.Time
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+func newRateLimitedReader(r io.Reader, rate int64) *rateLimitedReader {
+	return &rateLimitedReader{r: r, rate: rate, now: time.Now, sleep: time.Sleep}
+}
+
+func (l *rateLimitedReader) Read(b []byte) (int, error) {
+	if l.start.IsZero() {
+		l.start = l.now()
+	}
+	// Never read more than a tenth of a second's worth at a time, so that the
+	// transfer is smooth rather than bursty.
+	if max := l.rate / 10; max > 0 && int64(len(b)) > max {
+		b = b[:max]
+	}
+	n, err := l.r.Read(b)
+	l.read += int64(n)
+	due := time.Duration(float64(l.read) / float64(l.rate) * float64(time.Second))
+	if wait := due - l.now().Sub(l.start); wait > 0 {
+		l.sleep(wait)
+	}
+	return n, err
+}
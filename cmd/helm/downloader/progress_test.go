@@ -0,0 +1,108 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package downloader
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeClock is a clock that only moves when told to.
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time        { return c.t }
+func (c *fakeClock) sleep(d time.Duration) { c.t = c.t.Add(d) }
+
+func TestProgressReader(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	p := newProgressReader(strings.NewReader(strings.Repeat("x", 4096)), out, "foo-0.1.0.tgz", 4096)
+	p.now = clock.now
+
+	b := make([]byte, 1024)
+	if _, err := p.Read(b); err != nil {
+		t.Fatal(err)
+	}
+	clock.sleep(time.Second)
+	if got, expect := p.line(time.Second), "foo-0.1.0.tgz [=======                       ]  25% 1.0KiB/4.0KiB ETA 3s"; got != expect {
+		t.Errorf("expected %q, got %q", expect, got)
+	}
+
+	if _, err := ioutil.ReadAll(p); err != nil {
+		t.Fatal(err)
+	}
+	s := out.String()
+	if !strings.Contains(s, "100% 4.0KiB/4.0KiB ETA 0s") || !strings.HasSuffix(s, "\n") {
+		t.Errorf("expected a finished progress bar, got %q", s)
+	}
+}
+
+func TestProgressReaderUnknownSize(t *testing.T) {
+	p := newProgressReader(strings.NewReader("abc"), ioutil.Discard, "foo", -1)
+	ioutil.ReadAll(p)
+	if got, expect := p.line(time.Second), "foo 3B"; got != expect {
+		t.Errorf("expected %q, got %q", expect, got)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{
+		0:                  "0B",
+		1023:               "1023B",
+		1024:               "1.0KiB",
+		1536:               "1.5KiB",
+		5 * 1024 * 1024:    "5.0MiB",
+		3 << 30:            "3.0GiB",
+		1024*1024*1024 - 1: "1024.0MiB",
+	}
+	for n, expect := range tests {
+		if got := formatBytes(n); got != expect {
+			t.Errorf("%d: expected %q, got %q", n, expect, got)
+		}
+	}
+}
+
+func TestRateLimitedReader(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	l := newRateLimitedReader(strings.NewReader(strings.Repeat("x", 10000)), 1000)
+	l.now, l.sleep = clock.now, clock.sleep
+
+	b := make([]byte, 4096)
+	n, err := l.Read(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 100 {
+		t.Errorf("expected reads to be capped at 100 bytes, got %d", n)
+	}
+
+	data, err := ioutil.ReadAll(l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data)+n != 10000 {
+		t.Errorf("expected 10000 bytes, got %d", len(data)+n)
+	}
+	if elapsed := clock.t.Sub(time.Unix(0, 0)); elapsed != 10*time.Second {
+		t.Errorf("expected 10000 bytes at 1000B/s to take 10s, took %s", elapsed)
+	}
+}
@@ -0,0 +1,231 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package downloader
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+
+	"k8s.io/helm/pkg/provenance"
+)
+
+// TrustPolicy restricts which keys may sign the charts of a source.
+//
+// A verified chart is checked against the first rule that matches its source.
+// Its signature must then be by one of the keys of that rule. A chart that no
+// rule matches only needs a valid signature by a key of the keyring; a
+// catch-all rule, with repository "*", requires every source to be listed.
+type TrustPolicy struct {
+	Rules []TrustRule `json:"rules"`
+}
+
+// TrustRule lists the keys trusted to sign the charts of a source. A rule
+// matches the charts that match all of its fields; fields left empty match
+// any chart.
+type TrustRule struct {
+	// Repository is a glob matched against the name of the repository the
+	// chart comes from, e.g. "stable". A chart referenced by URL comes from
+	// the repository whose URL it is under, if any.
+	Repository string `json:"repository,omitempty"`
+	// URL is a glob matched against the URL the chart is downloaded from,
+	// e.g. "https://charts.example.com/*".
+	URL string `json:"url,omitempty"`
+	// Chart is a glob matched against the chart name.
+	Chart string `json:"chart,omitempty"`
+	// Keys are the fingerprints, or 16 digit key IDs, of the keys trusted to
+	// sign the matching charts. Spaces and case are ignored.
+	Keys []string `json:"keys"`
+}
+
+// LoadTrustPolicy reads a trust policy from a YAML file.
+func LoadTrustPolicy(filename string) (*TrustPolicy, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	p := &TrustPolicy{}
+	if err := yaml.Unmarshal(b, p); err != nil {
+		return nil, fmt.Errorf("cannot parse trust policy %s: %s", filename, err)
+	}
+	for i, r := range p.Rules {
+		for _, g := range []string{r.Repository, r.URL, r.Chart} {
+			if _, err := path.Match(g, ""); err != nil {
+				return nil, fmt.Errorf("trust policy %s: rule %d: invalid pattern %q: %s", filename, i+1, g, err)
+			}
+		}
+		for _, k := range r.Keys {
+			if n := len(normalizeKey(k)); n != 16 && n != 40 {
+				return nil, fmt.Errorf("trust policy %s: rule %d: %q is neither a fingerprint nor a 16 digit key ID", filename, i+1, k)
+			}
+		}
+	}
+	return p, nil
+}
+
+// WriteFile writes the policy to filename as YAML.
+func (p *TrustPolicy) WriteFile(filename string) error {
+	b, err := yaml.Marshal(p)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, b, 0644)
+}
+
+// Trust adds the key with the given fingerprint to the rule with exactly the
+// given repository, URL and chart patterns, creating the rule if there is
+// none. A new rule goes before the first catch-all rule, which matches every
+// chart and would hide it.
+func (p *TrustPolicy) Trust(repository, url, chart, fingerprint string) {
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		if r.Repository != repository || r.URL != url || r.Chart != chart {
+			continue
+		}
+		for _, k := range r.Keys {
+			if sameKey(fingerprint, k) {
+				return
+			}
+		}
+		r.Keys = append(r.Keys, fingerprint)
+		return
+	}
+
+	rule := TrustRule{Repository: repository, URL: url, Chart: chart, Keys: []string{fingerprint}}
+	at := len(p.Rules)
+	if !rule.catchAll() {
+		for i, r := range p.Rules {
+			if r.catchAll() {
+				at = i
+				break
+			}
+		}
+	}
+	p.Rules = append(p.Rules, TrustRule{})
+	copy(p.Rules[at+1:], p.Rules[at:])
+	p.Rules[at] = rule
+}
+
+// TrustedFor returns the rules that list the key with the given fingerprint.
+func (p *TrustPolicy) TrustedFor(fingerprint string) []TrustRule {
+	rules := []TrustRule{}
+	for _, r := range p.Rules {
+		for _, k := range r.Keys {
+			if sameKey(fingerprint, k) {
+				rules = append(rules, r)
+				break
+			}
+		}
+	}
+	return rules
+}
+
+// catchAll reports whether the rule matches every chart.
+func (r TrustRule) catchAll() bool {
+	return (r.Repository == "" || r.Repository == "*") && (r.URL == "" || r.URL == "*") && (r.Chart == "" || r.Chart == "*")
+}
+
+// String describes the charts the rule matches.
+func (r TrustRule) String() string {
+	parts := []string{}
+	if r.Repository != "" {
+		parts = append(parts, "repository "+r.Repository)
+	}
+	if r.URL != "" {
+		parts = append(parts, "url "+r.URL)
+	}
+	if r.Chart != "" {
+		parts = append(parts, "chart "+r.Chart)
+	}
+	if len(parts) == 0 {
+		return "any chart"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Check returns an error unless the chart, from the named repository (which
+// may be empty) and URL, is signed by a key the policy trusts for it.
+func (p *TrustPolicy) Check(repoName, url, chartName string, ver *provenance.Verification) error {
+	r := p.rule(repoName, url, chartName)
+	if r == nil {
+		return nil
+	}
+	fp := fmt.Sprintf("%X", ver.SignedBy.PrimaryKey.Fingerprint)
+	for _, k := range r.Keys {
+		if sameKey(fp, k) {
+			return nil
+		}
+	}
+	return fmt.Errorf("chart %q is signed by %s (%s), which the trust policy does not trust for %s", chartName, signerName(ver), fp, sourceName(repoName, url))
+}
+
+func (p *TrustPolicy) rule(repoName, url, chartName string) *TrustRule {
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		if r.Repository != "" {
+			if ok, _ := path.Match(r.Repository, repoName); !ok || repoName == "" {
+				continue
+			}
+		}
+		if r.URL != "" {
+			if ok, _ := path.Match(r.URL, url); !ok {
+				continue
+			}
+		}
+		if r.Chart != "" {
+			if ok, _ := path.Match(r.Chart, chartName); !ok {
+				continue
+			}
+		}
+		return r
+	}
+	return nil
+}
+
+// sameKey reports whether the key ID or fingerprint k names the key with
+// the given fingerprint.
+func sameKey(fingerprint, k string) bool {
+	return strings.HasSuffix(normalizeKey(fingerprint), normalizeKey(k))
+}
+
+func normalizeKey(k string) string {
+	k = strings.ToUpper(strings.Replace(k, " ", "", -1))
+	return strings.TrimPrefix(k, "0X")
+}
+
+func signerName(ver *provenance.Verification) string {
+	ids := []string{}
+	for id := range ver.SignedBy.Identities {
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return "an unnamed key"
+	}
+	sort.Strings(ids)
+	return ids[0]
+}
+
+func sourceName(repoName, url string) string {
+	if repoName != "" {
+		return fmt.Sprintf("repository %q", repoName)
+	}
+	return url
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package downloader
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/helm/cmd/helm/helmpath"
+	"k8s.io/helm/pkg/repo/repotest"
+)
+
+// testKeyFingerprint is the fingerprint of testdata/helm-test-key.pub.
+const testKeyFingerprint = "5E61 5389 B53C A37F 0EE6 0BD3 843B BF98 1FC1 8762"
+
+func writeTrustPolicy(t *testing.T, dir, policy string) string {
+	name := filepath.Join(dir, "trust-policy.yaml")
+	if err := ioutil.WriteFile(name, []byte(policy), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+func TestTrustPolicyCheck(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-trust-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	p, err := LoadTrustPolicy(writeTrustPolicy(t, dir, `
+rules:
+  - repository: stable
+    keys: ["`+testKeyFingerprint+`"]
+  - repository: incubator
+    chart: "sign*"
+    keys: ["843bbf981fc18762"]
+  - url: "https://charts.example.com/*"
+    keys: ["0000000000000000000000000000000000000000"]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ver, err := VerifyChart("testdata/signtest-0.1.0.tgz", "testdata/helm-test-key.pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		repo, url, chart string
+		fail             bool
+	}{
+		{repo: "stable", url: "https://kubernetes-charts.storage.googleapis.com/signtest-0.1.0.tgz", chart: "signtest"},
+		{repo: "incubator", chart: "signtest"},
+		// The incubator rule does not match, and no other rule does.
+		{repo: "incubator", chart: "other"},
+		{url: "https://charts.example.com/signtest-0.1.0.tgz", chart: "signtest", fail: true},
+		{url: "https://other.example.com/signtest-0.1.0.tgz", chart: "signtest"},
+	}
+	for _, tt := range tests {
+		err := p.Check(tt.repo, tt.url, tt.chart, ver)
+		if tt.fail {
+			if err == nil {
+				t.Errorf("%s %s %s: expected the chart to be rejected", tt.repo, tt.url, tt.chart)
+			} else if !strings.Contains(err.Error(), "helm-testing@helm.sh") {
+				t.Errorf("expected the error to name the signer, got %q", err)
+			}
+		} else if err != nil {
+			t.Errorf("%s %s %s: %s", tt.repo, tt.url, tt.chart, err)
+		}
+	}
+}
+
+func TestTrustPolicyTrust(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-trust-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	p := &TrustPolicy{Rules: []TrustRule{
+		{Repository: "stable", Keys: []string{"843BBF981FC18762"}},
+		{Repository: "*", Keys: []string{"0000000000000000"}},
+	}}
+	fp := strings.Replace(testKeyFingerprint, " ", "", -1)
+	p.Trust("stable", "", "", fp)
+	p.Trust("incubator", "", "sign*", fp)
+	p.Trust("incubator", "", "sign*", fp)
+
+	if len(p.Rules) != 3 {
+		t.Fatalf("expected 3 rules, got %v", p.Rules)
+	}
+	// The key ID already trusted for stable is the same key.
+	if len(p.Rules[0].Keys) != 1 {
+		t.Errorf("expected the key not to be added twice, got %v", p.Rules[0].Keys)
+	}
+	if r := p.Rules[1]; r.Repository != "incubator" || r.Chart != "sign*" || len(r.Keys) != 1 {
+		t.Errorf("expected the new rule before the catch-all rule, got %v", p.Rules)
+	}
+	if got := len(p.TrustedFor(fp)); got != 2 {
+		t.Errorf("expected the key to be trusted by 2 rules, got %d", got)
+	}
+
+	name := filepath.Join(dir, "trust-policy.yaml")
+	if err := p.WriteFile(name); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadTrustPolicy(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Rules) != 3 || loaded.Rules[1].String() != "repository incubator, chart sign*" {
+		t.Errorf("unexpected rules after a round trip: %v", loaded.Rules)
+	}
+}
+
+func TestLoadTrustPolicyInvalid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-trust-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := map[string]string{
+		"rules:\n  - chart: \"[\"\n    keys: [843BBF981FC18762]\n": "invalid pattern",
+		"rules:\n  - keys: [1FC18762]\n":                           "neither a fingerprint nor a 16 digit key ID",
+		"rules: {}\n":                                              "cannot parse trust policy",
+	}
+	for policy, expect := range tests {
+		_, err := LoadTrustPolicy(writeTrustPolicy(t, dir, policy))
+		if err == nil || !strings.Contains(err.Error(), expect) {
+			t.Errorf("expected an error containing %q, got %v", expect, err)
+		}
+	}
+}
+
+func TestDownloadToTrustPolicy(t *testing.T) {
+	hh, err := ioutil.TempDir("", "helm-downloadto-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(hh)
+
+	dest := filepath.Join(hh, "dest")
+	os.MkdirAll(dest, 0755)
+
+	srv := repotest.NewServer(hh)
+	defer srv.Stop()
+	if _, err := srv.CopyCharts("testdata/*.tgz*"); err != nil {
+		t.Fatal(err)
+	}
+
+	c := ChartDownloader{
+		HelmHome: helmpath.Home(hh),
+		Out:      ioutil.Discard,
+		Verify:   VerifyAlways,
+		Keyring:  "testdata/helm-test-key.pub",
+	}
+
+	writeTrustPolicy(t, hh, "rules:\n  - url: \""+srv.URL()+"/*\"\n    keys: [\"0000000000000000\"]\n")
+	if _, _, err := c.DownloadTo(srv.URL()+"/signtest-0.1.0.tgz", "", dest); err == nil || !strings.Contains(err.Error(), "does not trust") {
+		t.Errorf("expected the trust policy to reject the chart, got %v", err)
+	}
+
+	writeTrustPolicy(t, hh, "rules:\n  - url: \""+srv.URL()+"/*\"\n    keys: [\""+testKeyFingerprint+"\"]\n")
+	if _, _, err := c.DownloadTo(srv.URL()+"/signtest-0.1.0.tgz", "", dest); err != nil {
+		t.Errorf("expected the trust policy to accept the chart, got %s", err)
+	}
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"k8s.io/helm/pkg/helm"
+	rls "k8s.io/helm/pkg/proto/hapi/services"
+)
+
+const driftHelp = `
+This command compares the resources of a release, as Tiller stored them at
+install or upgrade time, with the live objects in the cluster, and reports
+the fields that were added, removed or changed since, for example by
+'kubectl edit' or 'kubectl scale'.
+
+	$ helm drift happy-panda
+	KIND      	NAME               	NAMESPACE	STATUS
+	Deployment	happy-panda-mariadb	default  	changed
+	Service   	happy-panda-mariadb	default  	in sync
+	Secret    	happy-panda-mariadb	default  	missing
+
+	Deployment happy-panda-mariadb:
+	  ~ spec.replicas: 1 -> 3
+	  + metadata.labels.hotfix: "true"
+	Error: 2 of 3 resources of release "happy-panda" have drifted
+
+Fields that the API server maintains, such as the status and resource
+version, are ignored, as are fields that the release leaves out and that the
+API server sets to their default values.
+
+Fields that are managed outside of Helm on purpose, such as the replica count
+of a Deployment scaled by a HorizontalPodAutoscaler, can be ignored with
+'--fields-to-ignore', or with the 'helm.sh/ignore-fields' annotation on the
+resource. Both take a comma separated list of field paths, and '--fields-to-ignore'
+may prefix a path with KIND: or KIND/NAME: to only ignore it for some resources.
+'[*]' matches any item of a list:
+
+	$ helm drift happy-panda --fields-to-ignore Deployment:spec.replicas,spec.template.spec.containers[*].image
+
+Use 'helm sync' to revert the changes.
+
+The command exits with an error if any resource has drifted, so that it can
+be used in scripts. Use '--revision' to compare an older revision of the
+release with the cluster.
+`
+
+type driftCmd struct {
+	release  string
+	revision int32
+	ignore   []string
+	output   string
+	out      io.Writer
+	client   helm.Interface
+}
+
+// driftResource is a resource in the machine readable output of 'helm drift'.
+type driftResource struct {
+	APIVersion string       `json:"apiVersion"`
+	Kind       string       `json:"kind"`
+	Name       string       `json:"name"`
+	Namespace  string       `json:"namespace"`
+	Status     string       `json:"status"`
+	Fields     []driftField `json:"fields,omitempty"`
+}
+
+// driftField is a field that differs in the machine readable output of
+// 'helm drift'.
+type driftField struct {
+	Path   string `json:"path"`
+	Change string `json:"change"`
+	Stored string `json:"stored,omitempty"`
+	Live   string `json:"live,omitempty"`
+}
+
+func newDriftCmd(client helm.Interface, out io.Writer) *cobra.Command {
+	d := &driftCmd{out: out, client: client}
+
+	cmd := &cobra.Command{
+		Use:               "drift [flags] RELEASE_NAME",
+		Short:             "compare the resources of a release with the cluster",
+		Long:              driftHelp,
+		PersistentPreRunE: setupConnection,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkArgsLength(len(args), "release name"); err != nil {
+				return err
+			}
+			d.release = args[0]
+			if d.client == nil {
+				d.client = helm.NewClient(helm.Host(tillerHost), helm.UserToken(userToken), helm.Impersonate(asUser, asGroups), helm.RequestID(requestID))
+			}
+			return d.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.Int32Var(&d.revision, "revision", 0, "revision of the release to compare with the cluster")
+	f.StringSliceVar(&d.ignore, "fields-to-ignore", []string{}, "fields managed outside of Helm, as [KIND[/NAME]:]PATH. Can be repeated or separated with commas")
+	addOutputFlag(f, &d.output, "o", "table")
+
+	return cmd
+}
+
+func (d *driftCmd) run() error {
+	format, err := parseOutputFormat(d.output, "table")
+	if err != nil {
+		return err
+	}
+
+	res, err := d.client.ReleaseDrift(d.release, helm.DriftReleaseVersion(d.revision), helm.DriftIgnoreFields(d.ignore))
+	if err != nil {
+		return prettyError(err)
+	}
+
+	if !format.human() {
+		if err := format.write(d.out, driftOutput(res.Resources)); err != nil {
+			return err
+		}
+	} else if len(res.Resources) == 0 {
+		fmt.Fprintf(d.out, "Release %q has no resources\n", d.release)
+	} else {
+		fmt.Fprint(d.out, formatDrift(res.Resources))
+	}
+
+	drifted := 0
+	for _, r := range res.Resources {
+		if r.Status != "in sync" {
+			drifted++
+		}
+	}
+	if drifted > 0 {
+		return fmt.Errorf("%d of %d resources of release %q have drifted", drifted, len(res.Resources), d.release)
+	}
+	return nil
+}
+
+func driftOutput(resources []*rls.ResourceDrift) []driftResource {
+	res := []driftResource{}
+	for _, r := range resources {
+		dr := driftResource{
+			APIVersion: r.ApiVersion,
+			Kind:       r.Kind,
+			Name:       r.Name,
+			Namespace:  r.Namespace,
+			Status:     r.Status,
+		}
+		for _, f := range r.Fields {
+			dr.Fields = append(dr.Fields, driftField{Path: f.Path, Change: f.Change, Stored: f.Stored, Live: f.Live})
+		}
+		res = append(res, dr)
+	}
+	return res
+}
+
+// driftMarks prefix the fields of a resource by how they changed.
+var driftMarks = map[string]string{"added": "+", "removed": "-", "changed": "~"}
+
+func formatDrift(resources []*rls.ResourceDrift) string {
+	tbl := uitable.New()
+	tbl.MaxColWidth = 60
+	tbl.AddRow("KIND", "NAME", "NAMESPACE", "STATUS")
+	for _, r := range resources {
+		tbl.AddRow(r.Kind, r.Name, r.Namespace, r.Status)
+	}
+
+	b := bytes.NewBufferString(tbl.String() + "\n")
+	for _, r := range resources {
+		if len(r.Fields) == 0 {
+			continue
+		}
+		fmt.Fprintf(b, "\n%s %s:\n", r.Kind, r.Name)
+		for _, f := range r.Fields {
+			switch f.Change {
+			case "added":
+				fmt.Fprintf(b, "  %s %s: %s\n", driftMarks[f.Change], f.Path, f.Live)
+			case "removed":
+				fmt.Fprintf(b, "  %s %s: %s\n", driftMarks[f.Change], f.Path, f.Stored)
+			default:
+				fmt.Fprintf(b, "  %s %s: %s -> %s\n", driftMarks[f.Change], f.Path, f.Stored, f.Live)
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"k8s.io/helm/pkg/helm"
+	rls "k8s.io/helm/pkg/proto/hapi/services"
+)
+
+type fakeDriftClient struct {
+	fakeReleaseClient
+	resources []*rls.ResourceDrift
+}
+
+func (c *fakeDriftClient) ReleaseDrift(name string, opts ...helm.DriftOption) (*rls.GetReleaseDriftResponse, error) {
+	return &rls.GetReleaseDriftResponse{Resources: c.resources}, nil
+}
+
+func TestDrift(t *testing.T) {
+	drifted := []*rls.ResourceDrift{
+		{ApiVersion: "extensions/v1beta1", Kind: "Deployment", Name: "web", Namespace: "default", Status: "changed", Fields: []*rls.FieldDrift{
+			{Path: "metadata.labels.hotfix", Change: "added", Live: `"true"`},
+			{Path: "spec.replicas", Change: "changed", Stored: "1", Live: "3"},
+		}},
+		{ApiVersion: "v1", Kind: "Service", Name: "web", Namespace: "default", Status: "in sync"},
+	}
+
+	tests := []struct {
+		name      string
+		flags     []string
+		resources []*rls.ResourceDrift
+		expected  []string
+		err       string
+	}{
+		{
+			name:      "in sync",
+			resources: drifted[1:],
+			expected:  []string{"Service\tweb \tdefault  \tin sync"},
+		},
+		{
+			name:      "drifted",
+			resources: drifted,
+			expected: []string{
+				"Deployment\tweb \tdefault  \tchanged",
+				"Deployment web:\n  + metadata.labels.hotfix: \"true\"\n  ~ spec.replicas: 1 -> 3\n",
+			},
+			err: `1 of 2 resources of release "web" have drifted`,
+		},
+		{
+			name:      "json",
+			flags:     []string{"-o", "json"},
+			resources: drifted,
+			expected:  []string{`"path": "spec.replicas",`, `"live": "3"`},
+			err:       `1 of 2 resources of release "web" have drifted`,
+		},
+	}
+
+	for _, tt := range tests {
+		out := bytes.NewBuffer(nil)
+		cmd := newDriftCmd(&fakeDriftClient{resources: tt.resources}, out)
+		cmd.ParseFlags(tt.flags)
+		err := cmd.RunE(cmd, []string{"web"})
+		switch {
+		case tt.err == "" && err != nil:
+			t.Errorf("%s: unexpected error: %s", tt.name, err)
+		case tt.err != "" && (err == nil || err.Error() != tt.err):
+			t.Errorf("%s: expected error %q, got %v", tt.name, tt.err, err)
+		}
+		for _, e := range tt.expected {
+			if !strings.Contains(out.String(), e) {
+				t.Errorf("%s: expected output to contain %q, got\n%s", tt.name, e, out.String())
+			}
+		}
+	}
+}
+
+type fakeSyncClient struct {
+	fakeReleaseClient
+	resources []*rls.ResourceDrift
+}
+
+func (c *fakeSyncClient) SyncRelease(name string, opts ...helm.SyncOption) (*rls.SyncReleaseResponse, error) {
+	return &rls.SyncReleaseResponse{Resources: c.resources}, nil
+}
+
+func TestSync(t *testing.T) {
+	reverted := []*rls.ResourceDrift{
+		{Kind: "Deployment", Name: "web", Namespace: "default", Status: "changed", Fields: []*rls.FieldDrift{
+			{Path: "spec.replicas", Change: "changed", Stored: "1", Live: "3"},
+		}},
+	}
+
+	out := bytes.NewBuffer(nil)
+	cmd := newSyncCmd(&fakeSyncClient{resources: reverted}, out)
+	cmd.ParseFlags([]string{"--fields-to-ignore", "metadata.labels"})
+	if err := cmd.RunE(cmd, []string{"web"}); err != nil {
+		t.Fatal(err)
+	}
+	expected := "\nDeployment web:\n  ~ spec.replicas: 1 -> 3\nReverted 1 resource of release \"web\"\n"
+	if !strings.HasSuffix(out.String(), expected) {
+		t.Errorf("expected output to end with %q, got %q", expected, out.String())
+	}
+
+	out.Reset()
+	cmd = newSyncCmd(&fakeSyncClient{}, out)
+	if err := cmd.RunE(cmd, []string{"web"}); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "Release \"web\" is in sync\n" {
+		t.Errorf("unexpected output %q", out.String())
+	}
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+// dryRunValue is the value of a --dry-run flag. It takes "client" (or
+// "true", which is what a bare --dry-run means) for a dry run rendered by
+// Tiller only, "server" to also have the Kubernetes API validate the
+// resources, and "none" (or "false") for a real run.
+type dryRunValue struct {
+	dryRun *bool
+	server *bool
+}
+
+func (v dryRunValue) Set(s string) error {
+	switch s {
+	case "true", "client":
+		*v.dryRun, *v.server = true, false
+	case "server":
+		*v.dryRun, *v.server = true, true
+	case "false", "none":
+		*v.dryRun, *v.server = false, false
+	default:
+		return fmt.Errorf("must be one of \"none\", \"client\" or \"server\", not %q", s)
+	}
+	return nil
+}
+
+func (v dryRunValue) String() string {
+	switch {
+	case *v.server:
+		return "server"
+	case *v.dryRun:
+		return "client"
+	}
+	return "none"
+}
+
+func (v dryRunValue) Type() string {
+	return "string"
+}
+
+// addDryRunFlag adds a --dry-run flag to f, which sets dryRun for any dry
+// run, and server as well for a server-side one.
+func addDryRunFlag(f *pflag.FlagSet, dryRun, server *bool, usage string) {
+	f.Var(dryRunValue{dryRun: dryRun, server: server}, "dry-run", usage)
+	f.Lookup("dry-run").NoOptDefVal = "true"
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestDryRunFlag(t *testing.T) {
+	tests := []struct {
+		args   []string
+		dryRun bool
+		server bool
+		err    bool
+	}{
+		{args: []string{}},
+		{args: []string{"--dry-run"}, dryRun: true},
+		{args: []string{"--dry-run=true"}, dryRun: true},
+		{args: []string{"--dry-run=client"}, dryRun: true},
+		{args: []string{"--dry-run=server"}, dryRun: true, server: true},
+		{args: []string{"--dry-run=server", "--dry-run=none"}},
+		{args: []string{"--dry-run=false"}},
+		{args: []string{"--dry-run=cluster"}, err: true},
+	}
+
+	for _, tt := range tests {
+		var dryRun, server bool
+		f := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		addDryRunFlag(f, &dryRun, &server, "")
+		err := f.Parse(tt.args)
+		if (err != nil) != tt.err {
+			t.Errorf("%v: expected error %v, got %v", tt.args, tt.err, err)
+			continue
+		}
+		if dryRun != tt.dryRun || server != tt.server {
+			t.Errorf("%v: expected dry run %v and server %v, got %v and %v", tt.args, tt.dryRun, tt.server, dryRun, server)
+		}
+	}
+}
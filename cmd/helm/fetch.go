@@ -17,13 +17,20 @@ limitations under the License.
 package main
 
 import (
+	"bytes"
+	"compress/gzip"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh/terminal"
 	"k8s.io/helm/cmd/helm/downloader"
 	"k8s.io/helm/cmd/helm/helmpath"
 	"k8s.io/helm/pkg/chartutil"
@@ -37,11 +44,32 @@ also be used to perform cryptographic verification of a chart without installing
 the chart.
 
 There are options for unpacking the chart after download. This will create a
-directory for the chart and uncomparess into that directory.
+directory for the chart and uncomparess into that directory. Archive entries
+that would be written outside of that directory, including links that point
+outside of it, cause the fetch to fail. '--insecure-untar' turns this check
+off, and should only be used for charts from a trusted source.
 
 If the --verify flag is specified, the requested chart MUST have a provenance
 file, and MUST pass the verification process. Failure in any part of this will
-result in an error, and the chart will not be saved locally.
+result in an error, and the chart will not be saved locally. If
+$HELM_HOME/trust-policy.yaml exists, the chart must also be signed by a key
+it trusts for the chart's repository.
+
+To write the chart archive to standard output instead of a file, use
+'--stdout' (or '-O -'). Combined with '--untar', the uncompressed tar stream
+is written instead. Nothing is written to disk either way:
+
+	$ helm fetch stable/nginx --stdout | tar -tz
+
+With '--verify', the chart is verified in memory as it downloads, so nothing
+is written to standard output unless it passes. '--prov' cannot be used with
+'--stdout'.
+
+When messages go to a terminal, a progress bar shows how much of the chart has
+been downloaded. '--quiet' turns it off. '--limit-rate' caps the download speed
+in bytes per second, with an optional K, M or G suffix (powers of 1024):
+
+	$ helm fetch stable/nginx --limit-rate 200K
 `
 
 type fetchCmd struct {
@@ -50,6 +78,14 @@ type fetchCmd struct {
 	chartRef string
 	destdir  string
 	version  string
+	output   string
+	stdout   bool
+
+	quiet     bool
+	limitRate string
+
+	noDeprecated  bool
+	insecureUntar bool
 
 	verify      bool
 	verifyLater bool
@@ -69,6 +105,13 @@ func newFetchCmd(out io.Writer) *cobra.Command {
 			if len(args) == 0 {
 				return fmt.Errorf("This command needs at least one argument, url or repo/name of the chart.")
 			}
+			switch fch.output {
+			case "":
+			case "-":
+				fch.stdout = true
+			default:
+				return fmt.Errorf("unsupported --output %q: only '-' (stdout) is supported", fch.output)
+			}
 			for i := 0; i < len(args); i++ {
 				fch.chartRef = args[i]
 				if err := fch.run(); err != nil {
@@ -82,35 +125,59 @@ func newFetchCmd(out io.Writer) *cobra.Command {
 	f := cmd.Flags()
 	f.BoolVar(&fch.untar, "untar", false, "if set to true, will untar the chart after downloading it")
 	f.StringVar(&fch.untardir, "untardir", ".", "if untar is specified, this flag specifies the name of the directory into which the chart is expanded")
+	f.BoolVar(&fch.insecureUntar, "insecure-untar", false, "if untar is specified, allow the chart to write files and links outside of the untar directory")
 	f.BoolVar(&fch.verify, "verify", false, "verify the package against its signature")
 	f.BoolVar(&fch.verifyLater, "prov", false, "fetch the provenance file, but don't perform verification")
 	f.StringVar(&fch.version, "version", "", "specific version of a chart. Without this, the latest version is fetched")
 	f.StringVar(&fch.keyring, "keyring", defaultKeyring(), "keyring containing public keys")
 	f.StringVarP(&fch.destdir, "destination", "d", ".", "location to write the chart. If this and tardir are specified, tardir is appended to this")
+	f.StringVarP(&fch.output, "output", "O", "", "where to write the chart instead of the destination directory. Only '-' (stdout) is supported")
+	f.BoolVar(&fch.noDeprecated, "no-deprecated", false, "refuse to fetch deprecated charts")
+	f.BoolVar(&fch.stdout, "stdout", false, "write the chart to stdout instead of the destination directory. Same as '-O -'")
+	f.BoolVarP(&fch.quiet, "quiet", "q", false, "do not show a progress bar")
+	f.StringVar(&fch.limitRate, "limit-rate", "", "maximum download speed in bytes per second, e.g. 500K or 2M")
 
 	return cmd
 }
 
 func (f *fetchCmd) run() error {
 	pname := f.chartRef
+
+	// When the chart itself goes to stdout, messages go to stderr so that
+	// they do not corrupt the stream.
+	msgs := f.out
+	if f.stdout {
+		msgs = os.Stderr
+	}
 	c := downloader.ChartDownloader{
 		HelmHome: helmpath.Home(homePath()),
-		Out:      f.out,
+		Out:      msgs,
 		Keyring:  f.keyring,
 		Verify:   downloader.VerifyNever,
 	}
 
+	rate, err := parseByteRate(f.limitRate)
+	if err != nil {
+		return err
+	}
+	c.LimitRate = rate
+	if !f.quiet && isTerminal(msgs) {
+		c.Progress = msgs
+	}
+
 	if f.verify {
 		c.Verify = downloader.VerifyAlways
 	} else if f.verifyLater {
 		c.Verify = downloader.VerifyLater
 	}
 
-	// If untar is set, we fetch to a tempdir, then untar and copy after
-	// verification.
+	if f.stdout {
+		return f.stream(&c, msgs)
+	}
+
+	// If untar is set, we fetch to a tempdir, then untar after verification.
 	dest := f.destdir
 	if f.untar {
-		var err error
 		dest, err = ioutil.TempDir("", "helm-")
 		if err != nil {
 			return fmt.Errorf("Failed to untar: %s", err)
@@ -124,7 +191,15 @@ func (f *fetchCmd) run() error {
 	}
 
 	if f.verify {
-		fmt.Fprintf(f.out, "Verification: %v", v)
+		fmt.Fprintf(msgs, "Verification: %v", v)
+	}
+
+	if err := checkDeprecated(msgs, saved, f.noDeprecated); err != nil {
+		if dest == f.destdir {
+			os.Remove(saved)
+			os.Remove(saved + ".prov")
+		}
+		return err
 	}
 
 	// After verification, untar the chart into the requested directory.
@@ -142,12 +217,97 @@ func (f *fetchCmd) run() error {
 			return fmt.Errorf("Failed to untar: %s is not a directory", ud)
 		}
 
+		if f.insecureUntar {
+			return chartutil.ExpandFileInsecure(ud, saved)
+		}
 		return chartutil.ExpandFile(ud, saved)
 	}
 	return nil
 }
 
+// stream downloads the chart into memory and writes it to the output,
+// decompressing it to a plain tar stream if untar is set. With --verify,
+// nothing is written unless the chart passes.
+func (f *fetchCmd) stream(c *downloader.ChartDownloader, msgs io.Writer) error {
+	if f.verifyLater {
+		return errors.New("--prov cannot be used with --stdout, as there is nowhere to write the provenance file")
+	}
+	data, v, err := c.Download(f.chartRef, f.version)
+	if err != nil {
+		return err
+	}
+
+	if f.verify {
+		fmt.Fprintf(msgs, "Verification: %v", v)
+	}
+
+	if ch, err := chartutil.LoadArchive(bytes.NewReader(data.Bytes())); err == nil {
+		if err := checkDeprecatedMetadata(msgs, ch.Metadata, f.noDeprecated); err != nil {
+			return err
+		}
+	}
+
+	var r io.Reader = data
+	if f.untar {
+		gz, err := gzip.NewReader(data)
+		if err != nil {
+			return fmt.Errorf("Failed to untar: %s", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	_, err = io.Copy(f.out, r)
+	return err
+}
+
+// parseByteRate parses a --limit-rate value: a number of bytes, optionally
+// followed by K, M or G. An empty value means no limit.
+func parseByteRate(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	mult := int64(1)
+	switch strings.ToUpper(s[len(s)-1:]) {
+	case "K":
+		mult = 1 << 10
+	case "M":
+		mult = 1 << 20
+	case "G":
+		mult = 1 << 30
+	}
+	num := s
+	if mult > 1 {
+		num = s[:len(s)-1]
+	}
+	n, err := strconv.ParseInt(num, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid --limit-rate %q: expected a positive number of bytes, optionally followed by K, M or G", s)
+	}
+	return n * mult, nil
+}
+
+// isTerminal reports whether w is a terminal.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && terminal.IsTerminal(int(f.Fd()))
+}
+
 // defaultKeyring returns the expanded path to the default keyring.
+//
+// That is the keyring managed by 'helm keys', once a key has been imported
+// into it. Otherwise, like gpg, this honors $GNUPGHOME, and looks in ~/.gnupg,
+// or in %APPDATA%\gnupg on Windows.
 func defaultKeyring() string {
-	return os.ExpandEnv("$HOME/.gnupg/pubring.gpg")
+	kr := helmpath.Home(homePath()).Keyring()
+	if _, err := os.Stat(kr); err == nil {
+		return kr
+	}
+	dir := os.Getenv("GNUPGHOME")
+	if dir == "" && runtime.GOOS == "windows" && os.Getenv("APPDATA") != "" {
+		dir = filepath.Join(os.Getenv("APPDATA"), "gnupg")
+	}
+	if dir == "" {
+		dir = filepath.Join(helmpath.UserHomeDir(), ".gnupg")
+	}
+	return filepath.Join(dir, "pubring.gpg")
 }
@@ -17,7 +17,11 @@ limitations under the License.
 package main
 
 import (
+	"archive/tar"
 	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
@@ -98,6 +102,19 @@ func TestFetchCmd(t *testing.T) {
 			expectFile: "./signtest",
 			expectDir:  true,
 		},
+		{
+			name:       "Fetch with a rate limit",
+			chart:      "test/signtest",
+			flags:      []string{"--limit-rate", "10M", "--quiet"},
+			expectFile: "./signtest-0.1.0.tgz",
+		},
+		{
+			name:       "Fail fetch with an invalid rate limit",
+			chart:      "test/signtest",
+			flags:      []string{"--limit-rate", "fast"},
+			failExpect: "invalid --limit-rate",
+			fail:       true,
+		},
 	}
 
 	srv := repotest.NewServer(hh)
@@ -137,3 +154,112 @@ func TestFetchCmd(t *testing.T) {
 		}
 	}
 }
+
+func TestFetchCmdStdout(t *testing.T) {
+	hh, err := tempHelmHome(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := homePath()
+	helmHome = hh
+	defer func() {
+		helmHome = old
+		os.RemoveAll(hh)
+	}()
+
+	srv := repotest.NewServer(hh)
+	defer srv.Stop()
+
+	if _, err := srv.CopyCharts("testdata/testcharts/*.tgz*"); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.LinkIndices(); err != nil {
+		t.Fatal(err)
+	}
+
+	outdir := filepath.Join(hh, "testout")
+	os.Mkdir(outdir, 0755)
+
+	tests := []struct {
+		name  string
+		flags []string
+		untar bool
+		fail  bool
+	}{
+		{name: "--stdout", flags: []string{"--stdout"}},
+		{name: "-O -", flags: []string{"-O", "-"}},
+		{name: "--stdout --untar", flags: []string{"--stdout", "--untar"}, untar: true},
+		{name: "--stdout --verify", flags: []string{"--stdout", "--verify", "--keyring", "testdata/helm-test-key.pub"}},
+		{name: "--stdout --prov", flags: []string{"--stdout", "--prov"}, fail: true},
+		{name: "-O file", flags: []string{"-O", "chart.tgz"}, fail: true},
+	}
+
+	for _, tt := range tests {
+		buf := bytes.NewBuffer(nil)
+		cmd := newFetchCmd(buf)
+		cmd.ParseFlags(append(tt.flags, "-d", outdir))
+		err := cmd.RunE(cmd, []string{"test/signtest"})
+		if tt.fail {
+			if err == nil {
+				t.Errorf("%s: expected an error", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+			continue
+		}
+
+		var r io.Reader = buf
+		if !tt.untar {
+			if r, err = gzip.NewReader(buf); err != nil {
+				t.Errorf("%s: expected a gzipped archive: %s", tt.name, err)
+				continue
+			}
+		}
+		hdr, err := tar.NewReader(r).Next()
+		if err != nil {
+			t.Errorf("%s: expected a tar stream: %s", tt.name, err)
+			continue
+		}
+		if filepath.Dir(hdr.Name) != "signtest" {
+			t.Errorf("%s: unexpected entry %q", tt.name, hdr.Name)
+		}
+	}
+
+	if files, _ := ioutil.ReadDir(outdir); len(files) != 0 {
+		t.Errorf("expected nothing to be written to %s, found %d files", outdir, len(files))
+	}
+}
+
+func TestParseByteRate(t *testing.T) {
+	tests := []struct {
+		in     string
+		expect int64
+		fail   bool
+	}{
+		{in: "", expect: 0},
+		{in: "500", expect: 500},
+		{in: "500K", expect: 500 * 1024},
+		{in: "2m", expect: 2 * 1024 * 1024},
+		{in: "1G", expect: 1024 * 1024 * 1024},
+		{in: "K", fail: true},
+		{in: "0", fail: true},
+		{in: "-5K", fail: true},
+		{in: "1.5M", fail: true},
+	}
+	for _, tt := range tests {
+		got, err := parseByteRate(tt.in)
+		if tt.fail {
+			if err == nil {
+				t.Errorf("%q: expected an error", tt.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %s", tt.in, err)
+		} else if got != tt.expect {
+			t.Errorf("%q: expected %d, got %d", tt.in, tt.expect, got)
+		}
+	}
+}
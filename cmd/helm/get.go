@@ -19,6 +19,7 @@ package main
 import (
 	"errors"
 	"io"
+	"strings"
 	"text/template"
 	"time"
 
@@ -26,6 +27,7 @@ import (
 
 	"k8s.io/helm/pkg/chartutil"
 	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/release"
 	"k8s.io/helm/pkg/timeconv"
 )
 
@@ -49,6 +51,28 @@ type getCmd struct {
 	out     io.Writer
 	client  helm.Interface
 	version int32
+	output  string
+}
+
+// getResult is the machine readable output of 'helm get'.
+type getResult struct {
+	Name           string                 `json:"name"`
+	Namespace      string                 `json:"namespace"`
+	Revision       int32                  `json:"revision"`
+	Released       string                 `json:"released"`
+	Chart          string                 `json:"chart"`
+	Values         map[string]interface{} `json:"values"`
+	ComputedValues map[string]interface{} `json:"computedValues"`
+	Hooks          []getHook              `json:"hooks"`
+	Manifest       string                 `json:"manifest"`
+}
+
+type getHook struct {
+	Name     string   `json:"name"`
+	Kind     string   `json:"kind"`
+	Path     string   `json:"path"`
+	Events   []string `json:"events"`
+	Manifest string   `json:"manifest"`
 }
 
 func newGetCmd(client helm.Interface, out io.Writer) *cobra.Command {
@@ -68,17 +92,19 @@ func newGetCmd(client helm.Interface, out io.Writer) *cobra.Command {
 			}
 			get.release = args[0]
 			if get.client == nil {
-				get.client = helm.NewClient(helm.Host(tillerHost))
+				get.client = helm.NewClient(helm.Host(tillerHost), helm.UserToken(userToken), helm.Impersonate(asUser, asGroups), helm.RequestID(requestID))
 			}
 			return get.run()
 		},
 	}
 
 	cmd.Flags().Int32Var(&get.version, "revision", 0, "get the named release with revision")
+	addOutputFlag(cmd.Flags(), &get.output, "o", "table")
 
 	cmd.AddCommand(newGetValuesCmd(nil, out))
 	cmd.AddCommand(newGetManifestCmd(nil, out))
 	cmd.AddCommand(newGetHooksCmd(nil, out))
+	cmd.AddCommand(newGetNotesCmd(nil, out))
 
 	return cmd
 }
@@ -102,6 +128,11 @@ MANIFEST:
 
 // getCmd is the command that implements 'helm get'
 func (g *getCmd) run() error {
+	format, err := parseOutputFormat(g.output, "table")
+	if err != nil {
+		return err
+	}
+
 	res, err := g.client.ReleaseContent(g.release, helm.ContentReleaseVersion(g.version))
 	if err != nil {
 		return prettyError(err)
@@ -111,6 +142,13 @@ func (g *getCmd) run() error {
 	if err != nil {
 		return err
 	}
+	if !format.human() {
+		out, err := getOutput(res.Release, cfg)
+		if err != nil {
+			return err
+		}
+		return format.write(g.out, out)
+	}
 	cfgStr, err := cfg.YAML()
 	if err != nil {
 		return err
@@ -124,6 +162,41 @@ func (g *getCmd) run() error {
 	return tpl(getTemplate, data, g.out)
 }
 
+func getOutput(r *release.Release, computed chartutil.Values) (getResult, error) {
+	res := getResult{
+		Name:           r.Name,
+		Namespace:      r.Namespace,
+		Revision:       r.Version,
+		Released:       outputTime(r.Info.LastDeployed),
+		Chart:          formatChartname(r.Chart),
+		Values:         map[string]interface{}{},
+		ComputedValues: computed,
+		Hooks:          []getHook{},
+		Manifest:       r.Manifest,
+	}
+	if r.Config != nil {
+		vals, err := chartutil.ReadValues([]byte(r.Config.Raw))
+		if err != nil {
+			return res, err
+		}
+		res.Values = vals
+	}
+	for _, h := range r.Hooks {
+		events := []string{}
+		for _, e := range h.Events {
+			events = append(events, strings.ToLower(strings.Replace(e.String(), "_", "-", -1)))
+		}
+		res.Hooks = append(res.Hooks, getHook{
+			Name:     h.Name,
+			Kind:     h.Kind,
+			Path:     h.Path,
+			Events:   events,
+			Manifest: h.Manifest,
+		})
+	}
+	return res, nil
+}
+
 func tpl(t string, vals map[string]interface{}, out io.Writer) error {
 	tt, err := template.New("_").Parse(t)
 	if err != nil {
@@ -136,5 +209,5 @@ func ensureHelmClient(h helm.Interface) helm.Interface {
 	if h != nil {
 		return h
 	}
-	return helm.NewClient(helm.Host(tillerHost))
+	return helm.NewClient(helm.Host(tillerHost), helm.UserToken(userToken), helm.Impersonate(asUser, asGroups), helm.RequestID(requestID))
 }
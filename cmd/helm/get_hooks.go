@@ -19,16 +19,29 @@ package main
 import (
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/release"
+	"k8s.io/helm/pkg/timeconv"
 )
 
 const getHooksHelp = `
 This command downloads hooks for a given release.
 
 Hooks are formatted in YAML and separated by the YAML '---\n' separator.
+Hooks that have run are preceded by a report of their last run, as YAML
+comments: the event it ran for, when it started and completed, whether it
+succeeded, and where to find its logs.
+
+Use '--filter' to only show some of the hooks. 'event=EVENT' keeps the hooks
+that fire on EVENT (e.g. 'pre-upgrade'), and 'status=STATUS' those whose last
+run is in STATUS: 'succeeded', 'failed', 'running', or 'unknown' for hooks that
+never ran. To look into a failed upgrade:
+
+	$ helm get hooks --filter event=pre-upgrade,status=failed RELEASE_NAME
 `
 
 type getHooksCmd struct {
@@ -36,6 +49,7 @@ type getHooksCmd struct {
 	out     io.Writer
 	client  helm.Interface
 	version int32
+	filters []string
 }
 
 func newGetHooksCmd(client helm.Interface, out io.Writer) *cobra.Command {
@@ -57,10 +71,16 @@ func newGetHooksCmd(client helm.Interface, out io.Writer) *cobra.Command {
 		},
 	}
 	cmd.Flags().Int32Var(&ghc.version, "revision", 0, "get the named release with revision")
+	cmd.Flags().StringSliceVar(&ghc.filters, "filter", []string{}, "only show hooks matching these event=EVENT or status=STATUS filters")
 	return cmd
 }
 
 func (g *getHooksCmd) run() error {
+	match, err := hookFilter(g.filters)
+	if err != nil {
+		return err
+	}
+
 	res, err := g.client.ReleaseContent(g.release, helm.ContentReleaseVersion(g.version))
 	if err != nil {
 		fmt.Fprintln(g.out, g.release)
@@ -68,7 +88,92 @@ func (g *getHooksCmd) run() error {
 	}
 
 	for _, hook := range res.Release.Hooks {
-		fmt.Fprintf(g.out, "---\n# %s\n%s", hook.Name, hook.Manifest)
+		if !match(hook) {
+			continue
+		}
+		fmt.Fprintf(g.out, "---\n# %s\n%s%s", hook.Name, hookReport(hook.LastExecution), hook.Manifest)
 	}
 	return nil
 }
+
+// hookFilter returns a function reporting whether a hook matches all of the
+// given event=EVENT and status=STATUS filters.
+func hookFilter(filters []string) (func(*release.Hook) bool, error) {
+	var events []release.Hook_Event
+	var phases []release.HookExecution_Phase
+	for _, f := range filters {
+		parts := strings.SplitN(f, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid filter %q: expected event=EVENT or status=STATUS", f)
+		}
+		value := strings.ToUpper(strings.Replace(parts[1], "-", "_", -1))
+		switch parts[0] {
+		case "event":
+			e, ok := release.Hook_Event_value[value]
+			if !ok || e == int32(release.Hook_UNKNOWN) {
+				return nil, fmt.Errorf("unknown hook event %q", parts[1])
+			}
+			events = append(events, release.Hook_Event(e))
+		case "status":
+			p, ok := release.HookExecution_Phase_value[value]
+			if !ok {
+				return nil, fmt.Errorf("unknown hook status %q", parts[1])
+			}
+			phases = append(phases, release.HookExecution_Phase(p))
+		default:
+			return nil, fmt.Errorf("invalid filter %q: expected event=EVENT or status=STATUS", f)
+		}
+	}
+
+	return func(h *release.Hook) bool {
+		if len(events) > 0 && !hasEvent(h, events) {
+			return false
+		}
+		if len(phases) > 0 {
+			phase := release.HookExecution_UNKNOWN
+			if h.LastExecution != nil {
+				phase = h.LastExecution.Phase
+			}
+			for _, p := range phases {
+				if p == phase {
+					return true
+				}
+			}
+			return false
+		}
+		return true
+	}, nil
+}
+
+func hasEvent(h *release.Hook, events []release.Hook_Event) bool {
+	for _, e := range h.Events {
+		for _, want := range events {
+			if e == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hookReport formats the last run of a hook as YAML comments.
+func hookReport(e *release.HookExecution) string {
+	if e == nil {
+		return ""
+	}
+	event := strings.ToLower(strings.Replace(e.Event.String(), "_", "-", -1))
+	r := fmt.Sprintf("# Last run: %s, %s\n", event, strings.ToLower(e.Phase.String()))
+	if e.StartedAt != nil {
+		r += fmt.Sprintf("# Started: %s\n", timeconv.String(e.StartedAt))
+	}
+	if e.CompletedAt != nil {
+		r += fmt.Sprintf("# Completed: %s\n", timeconv.String(e.CompletedAt))
+	}
+	if e.Message != "" {
+		r += fmt.Sprintf("# Message: %s\n", strings.Replace(e.Message, "\n", "\n#   ", -1))
+	}
+	if e.Logs != "" {
+		r += fmt.Sprintf("# Logs: %s\n", e.Logs)
+	}
+	return r
+}
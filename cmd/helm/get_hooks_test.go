@@ -20,9 +20,42 @@ import (
 	"io"
 	"testing"
 
+	"github.com/golang/protobuf/ptypes/timestamp"
 	"github.com/spf13/cobra"
+
+	"k8s.io/helm/pkg/proto/hapi/release"
 )
 
+// hookReportMock returns a release with a failed pre-upgrade hook and a
+// post-upgrade hook that never ran.
+func hookReportMock() *release.Release {
+	date := timestamp.Timestamp{Seconds: 242085845, Nanos: 0}
+	rel := releaseMock(&releaseOptions{name: "aeneas", version: 2, statusCode: release.Status_FAILED})
+	rel.Hooks = []*release.Hook{
+		{
+			Name:     "migrate",
+			Kind:     "Job",
+			Manifest: "kind: Job\n",
+			Events:   []release.Hook_Event{release.Hook_PRE_UPGRADE},
+			LastExecution: &release.HookExecution{
+				Event:       release.Hook_PRE_UPGRADE,
+				StartedAt:   &date,
+				CompletedAt: &date,
+				Phase:       release.HookExecution_FAILED,
+				Message:     "job failed: BackoffLimitExceeded",
+				Logs:        "kubectl logs --namespace default job/migrate",
+			},
+		},
+		{
+			Name:     "notify",
+			Kind:     "Job",
+			Manifest: "kind: Job\n",
+			Events:   []release.Hook_Event{release.Hook_POST_UPGRADE},
+		},
+	}
+	return rel
+}
+
 func TestGetHooks(t *testing.T) {
 	tests := []releaseCase{
 		{
@@ -31,6 +64,33 @@ func TestGetHooks(t *testing.T) {
 			expected: mockHookTemplate,
 			resp:     releaseMock(&releaseOptions{name: "aeneas"}),
 		},
+		{
+			name:     "get hooks with reports",
+			args:     []string{"aeneas"},
+			expected: `(?s)# migrate\n# Last run: pre-upgrade, failed\n# Started: .*\n# Message: job failed: BackoffLimitExceeded\n# Logs: kubectl logs --namespace default job/migrate\nkind: Job\n---\n# notify\nkind: Job\n$`,
+			resp:     hookReportMock(),
+		},
+		{
+			name:     "filter hooks by event and status",
+			args:     []string{"aeneas"},
+			flags:    []string{"--filter", "event=pre-upgrade,status=failed"},
+			expected: `^---\n# migrate\n# Last run: pre-upgrade, failed\n(# .*\n)*kind: Job\n$`,
+			resp:     hookReportMock(),
+		},
+		{
+			name:     "filter hooks that never ran",
+			args:     []string{"aeneas"},
+			flags:    []string{"--filter", "status=unknown"},
+			expected: `^---\n# notify\nkind: Job\n$`,
+			resp:     hookReportMock(),
+		},
+		{
+			name:  "get hooks with a bad filter",
+			args:  []string{"aeneas"},
+			flags: []string{"--filter", "event=sometime"},
+			resp:  hookReportMock(),
+			err:   true,
+		},
 		{
 			name: "get hooks without args",
 			args: []string{},
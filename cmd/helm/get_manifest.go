@@ -19,10 +19,16 @@ package main
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/releaseutil"
 )
 
 var getManifestHelp = `
@@ -31,6 +37,17 @@ This command fetches the generated manifest for a given release.
 A manifest is a YAML-encoded representation of the Kubernetes resources that
 were generated from this release's chart(s). If a chart is dependent on other
 charts, those resources will also be included in the manifest.
+
+'--filter' selects resources by kind, name or the template they were rendered
+from. Names and templates may be globs, and kinds are compared ignoring case:
+
+	$ helm get manifest my-release --filter kind=Deployment,name=web
+
+'--output-dir' writes the resources to files instead, one for each template,
+laid out like the chart's templates, so that they can be compared with the
+chart's source:
+
+	$ helm get manifest my-release --output-dir ./applied
 `
 
 type getManifestCmd struct {
@@ -38,6 +55,8 @@ type getManifestCmd struct {
 	out     io.Writer
 	client  helm.Interface
 	version int32
+	filter  string
+	outDir  string
 }
 
 func newGetManifestCmd(client helm.Interface, out io.Writer) *cobra.Command {
@@ -55,13 +74,16 @@ func newGetManifestCmd(client helm.Interface, out io.Writer) *cobra.Command {
 			}
 			get.release = args[0]
 			if get.client == nil {
-				get.client = helm.NewClient(helm.Host(tillerHost))
+				get.client = helm.NewClient(helm.Host(tillerHost), helm.UserToken(userToken), helm.Impersonate(asUser, asGroups), helm.RequestID(requestID))
 			}
 			return get.run()
 		},
 	}
 
-	cmd.Flags().Int32Var(&get.version, "revision", 0, "get the named release with revision")
+	f := cmd.Flags()
+	f.Int32Var(&get.version, "revision", 0, "get the named release with revision")
+	f.StringVar(&get.filter, "filter", "", "only get the resources matching kind=KIND,name=NAME,source=TEMPLATE (any of them)")
+	f.StringVar(&get.outDir, "output-dir", "", "write the resources to files in this directory, one for each template")
 	return cmd
 }
 
@@ -71,6 +93,128 @@ func (g *getManifestCmd) run() error {
 	if err != nil {
 		return prettyError(err)
 	}
-	fmt.Fprintln(g.out, res.Release.Manifest)
+	if g.filter == "" && g.outDir == "" {
+		fmt.Fprintln(g.out, res.Release.Manifest)
+		return nil
+	}
+
+	filter, err := parseManifestFilter(g.filter)
+	if err != nil {
+		return err
+	}
+	docs, err := releaseutil.SplitManifest(res.Release.Manifest)
+	if err != nil {
+		return err
+	}
+	selected := []releaseutil.Document{}
+	for _, d := range docs {
+		if filter.matches(d) {
+			selected = append(selected, d)
+		}
+	}
+	if len(selected) == 0 {
+		return fmt.Errorf("no resources of release %q match %q", g.release, g.filter)
+	}
+
+	if g.outDir != "" {
+		return writeManifestFiles(g.out, g.outDir, selected)
+	}
+	for i, d := range selected {
+		if i > 0 {
+			fmt.Fprintln(g.out, "---")
+		}
+		fmt.Fprint(g.out, d.Content)
+	}
+	return nil
+}
+
+// manifestFilter selects the resources of a manifest. Empty fields match any
+// resource.
+type manifestFilter struct {
+	kind   string
+	name   string
+	source string
+}
+
+func parseManifestFilter(s string) (manifestFilter, error) {
+	f := manifestFilter{}
+	if s == "" {
+		return f, nil
+	}
+	for _, kv := range strings.Split(s, ",") {
+		parts := strings.SplitN(kv, "=", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			return f, fmt.Errorf("invalid --filter %q: expected kind=KIND,name=NAME,source=TEMPLATE", s)
+		}
+		switch parts[0] {
+		case "kind":
+			f.kind = parts[1]
+		case "name":
+			f.name = parts[1]
+		case "source":
+			f.source = parts[1]
+		default:
+			return f, fmt.Errorf("invalid --filter %q: unknown key %q, expected kind, name or source", s, parts[0])
+		}
+		if _, err := path.Match(parts[1], ""); err != nil {
+			return f, fmt.Errorf("invalid --filter %q: %s", s, err)
+		}
+	}
+	return f, nil
+}
+
+func (f manifestFilter) matches(d releaseutil.Document) bool {
+	if f.kind != "" && !strings.EqualFold(f.kind, d.Kind) {
+		return false
+	}
+	if f.name != "" {
+		if ok, _ := path.Match(f.name, d.Name); !ok {
+			return false
+		}
+	}
+	if f.source != "" {
+		// Allow the chart name to be left off, as with --include.
+		ok, _ := path.Match(f.source, d.Source)
+		if i := strings.Index(d.Source, "/"); !ok && i >= 0 {
+			ok, _ = path.Match(f.source, d.Source[i+1:])
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// writeManifestFiles writes documents to files under dir named after their
+// templates, or after their kind and name if their template is not known.
+// Documents of the same template go to the same file, in order.
+func writeManifestFiles(out io.Writer, dir string, docs []releaseutil.Document) error {
+	names := []string{}
+	files := map[string][]string{}
+	for _, d := range docs {
+		name := d.Source
+		if name == "" {
+			name = strings.ToLower(d.Kind) + "-" + d.Name + ".yaml"
+		}
+		name = filepath.Clean(filepath.FromSlash(name))
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("refusing to write %s outside of %s", d.Source, dir)
+		}
+		if _, ok := files[name]; !ok {
+			names = append(names, name)
+		}
+		files[name] = append(files[name], d.Content)
+	}
+
+	for _, name := range names {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(p, []byte(strings.Join(files[name], "---\n")), 0644); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "wrote %s\n", p)
+	}
 	return nil
 }
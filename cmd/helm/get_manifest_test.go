@@ -17,10 +17,17 @@ limitations under the License.
 package main
 
 import (
+	"bytes"
 	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
+
+	"k8s.io/helm/pkg/proto/hapi/release"
 )
 
 func TestGetManifest(t *testing.T) {
@@ -31,6 +38,27 @@ func TestGetManifest(t *testing.T) {
 			expected: mockManifest,
 			resp:     releaseMock(&releaseOptions{name: "juno"}),
 		},
+		{
+			name:     "get manifest filtered by kind",
+			args:     []string{"juno"},
+			flags:    []string{"--filter", "kind=secret,name=fix*"},
+			expected: "^" + mockManifest + "$",
+			resp:     releaseMock(&releaseOptions{name: "juno"}),
+		},
+		{
+			name:  "get manifest with no matching resources",
+			args:  []string{"juno"},
+			flags: []string{"--filter", "kind=Deployment"},
+			resp:  releaseMock(&releaseOptions{name: "juno"}),
+			err:   true,
+		},
+		{
+			name:  "get manifest with an invalid filter",
+			args:  []string{"juno"},
+			flags: []string{"--filter", "label=web"},
+			resp:  releaseMock(&releaseOptions{name: "juno"}),
+			err:   true,
+		},
 		{
 			name: "get manifest without args",
 			args: []string{},
@@ -41,3 +69,68 @@ func TestGetManifest(t *testing.T) {
 		return newGetManifestCmd(c, out)
 	})
 }
+
+func TestGetManifestOutputDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-get-manifest-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	rel := releaseMock(&releaseOptions{name: "juno"})
+	rel.Manifest = `---
+# Source: juno/templates/web.yaml
+kind: Deployment
+metadata:
+  name: web
+---
+# Source: juno/templates/web.yaml
+kind: Service
+metadata:
+  name: web
+---
+kind: Secret
+metadata:
+  name: fixture
+---
+# Source: ../../etc/passwd
+kind: Secret
+metadata:
+  name: evil
+`
+	c := &fakeReleaseClient{rels: []*release.Release{rel}}
+	out := bytes.NewBuffer(nil)
+	cmd := newGetManifestCmd(c, out)
+	cmd.ParseFlags([]string{"--output-dir", dir, "--filter", "name=web"})
+	if err := cmd.RunE(cmd, []string{"juno"}); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "juno", "templates", "web.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "# Source: juno/templates/web.yaml\nkind: Deployment\nmetadata:\n  name: web\n---\n# Source: juno/templates/web.yaml\nkind: Service\nmetadata:\n  name: web\n"
+	if string(b) != expected {
+		t.Errorf("expected %q, got %q", expected, b)
+	}
+	if !strings.Contains(out.String(), "wrote "+filepath.Join(dir, "juno", "templates", "web.yaml")) {
+		t.Errorf("unexpected output %q", out.String())
+	}
+
+	out.Reset()
+	cmd = newGetManifestCmd(c, out)
+	cmd.ParseFlags([]string{"--output-dir", dir, "--filter", "kind=secret,name=fixture"})
+	if err := cmd.RunE(cmd, []string{"juno"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "secret-fixture.yaml")); err != nil {
+		t.Error(err)
+	}
+
+	cmd = newGetManifestCmd(c, out)
+	cmd.ParseFlags([]string{"--output-dir", dir, "--filter", "name=evil"})
+	if err := cmd.RunE(cmd, []string{"juno"}); err == nil {
+		t.Error("expected an error writing outside of the output directory")
+	}
+}
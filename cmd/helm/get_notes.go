@@ -0,0 +1,78 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/helm/pkg/helm"
+)
+
+var getNotesHelp = `
+This command shows the notes for a given release.
+
+Notes are rendered from the chart's NOTES.txt when the release is installed,
+upgraded or rolled back. Use '--revision' to show the notes of an earlier
+revision.
+`
+
+type getNotesCmd struct {
+	release string
+	out     io.Writer
+	client  helm.Interface
+	version int32
+}
+
+func newGetNotesCmd(client helm.Interface, out io.Writer) *cobra.Command {
+	get := &getNotesCmd{
+		out:    out,
+		client: client,
+	}
+	cmd := &cobra.Command{
+		Use:   "notes [flags] RELEASE_NAME",
+		Short: "show the notes for a named release",
+		Long:  getNotesHelp,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return errReleaseRequired
+			}
+			get.release = args[0]
+			if get.client == nil {
+				get.client = helm.NewClient(helm.Host(tillerHost), helm.UserToken(userToken), helm.Impersonate(asUser, asGroups), helm.RequestID(requestID))
+			}
+			return get.run()
+		},
+	}
+
+	cmd.Flags().Int32Var(&get.version, "revision", 0, "get the notes of the named release with revision")
+	return cmd
+}
+
+// getNotes implements 'helm get notes'
+func (g *getNotesCmd) run() error {
+	res, err := g.client.ReleaseContent(g.release, helm.ContentReleaseVersion(g.version))
+	if err != nil {
+		return prettyError(err)
+	}
+	if s := res.Release.Info.Status; s != nil && s.Notes != "" {
+		fmt.Fprintln(g.out, s.Notes)
+	}
+	return nil
+}
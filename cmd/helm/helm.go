@@ -23,7 +23,10 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"google.golang.org/grpc"
@@ -32,20 +35,43 @@ import (
 	"k8s.io/kubernetes/pkg/client/unversioned"
 
 	"k8s.io/helm/cmd/helm/helmpath"
+	"k8s.io/helm/pkg/helm"
 	"k8s.io/helm/pkg/kube"
+	"k8s.io/helm/pkg/provenance"
+	"k8s.io/helm/pkg/repo"
 )
 
 const (
 	localRepoIndexFilePath = "index.yaml"
 	homeEnvVar             = "HELM_HOME"
 	hostEnvVar             = "HELM_HOST"
-	tillerNamespace        = "kube-system"
+	retriesEnvVar          = "HELM_RETRIES"
+	retryBackoffEnvVar     = "HELM_RETRY_BACKOFF"
+	networkTimeoutEnvVar   = "HELM_NETWORK_TIMEOUT"
+	maxConnsEnvVar         = "HELM_MAX_CONNECTIONS_PER_HOST"
+	minHashBitsEnvVar      = "HELM_MIN_HASH_BITS"
+	sendTokenEnvVar        = "HELM_SEND_TOKEN"
 )
 
 var (
-	helmHome    string
-	tillerHost  string
-	kubeContext string
+	helmHome        string
+	tillerHost      string
+	tillerNamespace string
+	kubeContext     string
+
+	// userToken is the bearer token of the kube context, sent to Tiller so
+	// that a Tiller impersonating its users knows who is calling. It is only
+	// read from the kube context if sendToken is set.
+	userToken string
+	sendToken bool
+
+	// asUser and asGroups are the identity Tiller acts on the cluster as.
+	asUser   string
+	asGroups []string
+
+	// requestID identifies the calls the command makes to Tiller in Tiller's
+	// log. It is printed if the command fails.
+	requestID string
 )
 
 // flagDebug is a signal that the user wants additional output.
@@ -68,31 +94,90 @@ Common actions from this point include:
 - helm list:      list releases of charts
 
 Environment:
-  $HELM_HOME      set an alternative location for Helm files. By default, these are stored in ~/.helm
-  $HELM_HOST      set an alternative Tiller host. The format is host:port
-  $KUBECONFIG     set an alternate Kubernetes configuration file (default "~/.kube/config")
+  $HELM_HOME        set an alternative location for Helm files. By default, these are stored in ~/.helm
+                    if it exists, and in the XDG base directories ($XDG_CONFIG_HOME/helm, etc.) otherwise
+  $HELM_CONFIG_HOME set an alternative location for the repositories file
+  $HELM_CACHE_HOME  set an alternative location for downloaded repository indexes
+  $HELM_DATA_HOME   set an alternative location for the local repository, plugins and starters
+  $HELM_HOST        set an alternative Tiller host. The format is host:port
+  $HELM_CONTEXT     set the context of $HELM_HOME/config.yaml to use
+  $TILLER_NAMESPACE set the namespace Tiller runs in (default "kube-system")
+  $HELM_SEARCH_ENDPOINT set a remote search API that 'helm search' queries too
+  $HELM_RETRIES     set the default of --retries
+  $HELM_RETRY_BACKOFF set the default of --retry-backoff
+  $HELM_NETWORK_TIMEOUT set the default of --network-timeout
+  $HELM_MAX_CONNECTIONS_PER_HOST set the default of --max-connections-per-host
+  $HELM_MIN_HASH_BITS set the default of --min-hash-bits
+  $HELM_SEND_TOKEN  set the default of --send-token
+  $KUBECONFIG       set an alternate Kubernetes configuration file (default "~/.kube/config")
 `
 
+// envInt returns the integer in the environment variable name, or def if it
+// is unset or not an integer.
+func envInt(name string, def int) int {
+	if n, err := strconv.Atoi(os.Getenv(name)); err == nil {
+		return n
+	}
+	return def
+}
+
+// envBool returns the boolean in the environment variable name, such as
+// "true" or "1", or def if it is unset or not a boolean.
+func envBool(name string, def bool) bool {
+	if b, err := strconv.ParseBool(os.Getenv(name)); err == nil {
+		return b
+	}
+	return def
+}
+
+// envDuration returns the duration in the environment variable name, such as
+// "30s", or def if it is unset or not a duration.
+func envDuration(name string, def time.Duration) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(name)); err == nil {
+		return d
+	}
+	return def
+}
+
 func newRootCmd(out io.Writer) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:          "helm",
 		Short:        "The Helm package manager for Kubernetes.",
 		Long:         globalUsage,
 		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return applyContext(cmd)
+		},
 		PersistentPostRun: func(cmd *cobra.Command, args []string) {
 			teardown()
 		},
 	}
 	home := os.Getenv(homeEnvVar)
 	if home == "" {
-		home = "$HOME/.helm"
+		home = defaultHelmHome()
 	}
 	thost := os.Getenv(hostEnvVar)
+	tns := os.Getenv(tillerNamespaceEnvVar)
+	if tns == "" {
+		tns = "kube-system"
+	}
 	p := cmd.PersistentFlags()
 	p.StringVar(&helmHome, "home", home, "location of your Helm config. Overrides $HELM_HOME")
 	p.StringVar(&tillerHost, "host", thost, "address of tiller. Overrides $HELM_HOST")
 	p.StringVar(&kubeContext, "kube-context", "", "name of the kubeconfig context to use")
+	p.StringVar(&tillerNamespace, "tiller-namespace", tns, "namespace of Tiller. Overrides $TILLER_NAMESPACE")
+	p.StringVar(&contextName, "context", os.Getenv(contextEnvVar), "name of the context of $HELM_HOME/config.yaml to use. Overrides $HELM_CONTEXT")
 	p.BoolVar(&flagDebug, "debug", false, "enable verbose output")
+	p.StringVar(&asUser, "as", "", "username Tiller impersonates for the Kubernetes operations of a release")
+	p.StringSliceVar(&asGroups, "as-group", []string{}, "group Tiller impersonates for the Kubernetes operations of a release, can be repeated")
+	p.BoolVar(&sendToken, "send-token", envBool(sendTokenEnvVar, false), "send the bearer token of the kube context to a Tiller that acts as the calling user. Overrides $HELM_SEND_TOKEN")
+	p.IntVar(&repo.DefaultRetryPolicy.Retries, "retries", envInt(retriesEnvVar, 3), "number of times to retry a failed index, chart or registry download. Overrides $HELM_RETRIES")
+	p.DurationVar(&repo.DefaultRetryPolicy.Backoff, "retry-backoff", envDuration(retryBackoffEnvVar, time.Second), "wait before the first retry, doubled for each retry after it. Overrides $HELM_RETRY_BACKOFF")
+	p.DurationVar(&repo.DefaultRetryPolicy.Timeout, "network-timeout", envDuration(networkTimeoutEnvVar, 0), "time limit for each attempt of a download, 0 for none. Overrides $HELM_NETWORK_TIMEOUT")
+	p.IntVar(&repo.DefaultClientOptions.MaxConnsPerHost, "max-connections-per-host", envInt(maxConnsEnvVar, 0), "limit on the connections open to one repository or registry host at a time, 0 for none. Overrides $HELM_MAX_CONNECTIONS_PER_HOST")
+	p.IntVar(&provenance.MinHashBits, "min-hash-bits", envInt(minHashBitsEnvVar, 256), "reject charts whose provenance records their sum with a hash of fewer bits. Overrides $HELM_MIN_HASH_BITS")
+	p.StringVar(&cpuProfile, "cpuprofile", "", "write a CPU profile of the command to this file")
+	p.StringVar(&memProfile, "memprofile", "", "write a memory profile to this file when the command finishes")
 
 	// Tell gRPC not to log to console.
 	grpclog.SetLogger(log.New(ioutil.Discard, "", log.LstdFlags))
@@ -101,25 +186,42 @@ func newRootCmd(out io.Writer) *cobra.Command {
 	rup.Deprecated = "use 'helm repo update'\n"
 
 	cmd.AddCommand(
+		newBackupCmd(nil, out),
+		newBundleCmd(out),
+		newCheckDeprecationsCmd(nil, out),
 		newCreateCmd(out),
 		newDeleteCmd(nil, out),
 		newDependencyCmd(out),
+		newDriftCmd(nil, out),
 		newFetchCmd(out),
 		newGetCmd(nil, out),
 		newHomeCmd(out),
 		newHistoryCmd(nil, out),
+		newImagesCmd(nil, out),
 		newInitCmd(out),
 		newInspectCmd(nil, out),
 		newInstallCmd(nil, out),
+		newKeysCmd(out),
 		newLintCmd(out),
 		newListCmd(nil, out),
+		newMirrorCmd(out),
+		newOutdatedCmd(nil, out),
 		newPackageCmd(nil, out),
+		newPromoteCmd(nil, out),
+		newReleaseCmd(nil, out),
 		newRepoCmd(out),
+		newRestoreCmd(nil, out),
 		newRollbackCmd(nil, out),
 		newSearchCmd(out),
 		newServeCmd(out),
+		newStarterCmd(out),
 		newStatusCmd(nil, out),
+		newSyncCmd(nil, out),
+		newTemplateCmd(out),
+		newTillerCmd(nil, out),
+		newTestTemplatesCmd(out),
 		newUpgradeCmd(nil, out),
+		newValuesCmd(nil, out),
 		newVerifyCmd(out),
 		newVersionCmd(nil, out),
 
@@ -138,12 +240,25 @@ func newRootCmd(out io.Writer) *cobra.Command {
 
 func main() {
 	cmd := newRootCmd(os.Stdout)
-	if err := cmd.Execute(); err != nil {
+	cobra.OnInitialize(startProfiling)
+	err := cmd.Execute()
+	stopProfiling(os.Stderr)
+	if err != nil {
+		if requestID != "" {
+			fmt.Fprintf(os.Stderr, "Request ID: %s (search Tiller's log for it)\n", requestID)
+		}
 		os.Exit(1)
 	}
 }
 
 func setupConnection(c *cobra.Command, args []string) error {
+	if err := applyContext(c); err != nil {
+		return err
+	}
+	if asUser == "" && len(asGroups) > 0 {
+		return errors.New("--as-group requires --as")
+	}
+
 	if tillerHost == "" {
 		tunnel, err := newTillerPortForwarder(tillerNamespace, kubeContext)
 		if err != nil {
@@ -156,9 +271,18 @@ func setupConnection(c *cobra.Command, args []string) error {
 		}
 	}
 
+	if sendToken {
+		if config, err := kube.GetConfig(kubeContext).ClientConfig(); err == nil {
+			userToken = config.BearerToken
+		}
+	}
+
+	requestID = helm.NewRequestID()
+
 	// Set up the gRPC config.
 	if flagDebug {
 		fmt.Printf("SERVER: %q\n", tillerHost)
+		fmt.Printf("REQUEST ID: %s\n", requestID)
 	}
 	// Plugin support.
 	return nil
@@ -197,6 +321,21 @@ func homePath() string {
 	return os.ExpandEnv(helmHome)
 }
 
+// defaultHelmHome returns ~/.helm if it exists, so that existing setups keep
+// working until they are migrated with 'helm home migrate', and the XDG
+// configuration directory otherwise.
+func defaultHelmHome() string {
+	if _, err := os.Stat(legacyHelmHome()); err == nil {
+		return legacyHelmHome()
+	}
+	return helmpath.XDGConfigHome()
+}
+
+// legacyHelmHome returns the single-directory HELM_HOME of earlier releases.
+func legacyHelmHome() string {
+	return filepath.Join(helmpath.UserHomeDir(), ".helm")
+}
+
 // getKubeClient is a convenience method for creating kubernetes config and client
 // for a given kubeconfig context
 func getKubeClient(context string) (*restclient.Config, *unversioned.Client, error) {
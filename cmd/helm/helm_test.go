@@ -135,6 +135,12 @@ func (c *fakeReleaseClient) InstallRelease(chStr, ns string, opts ...helm.Instal
 	}, nil
 }
 
+func (c *fakeReleaseClient) InstallReleaseFromChart(chart *chart.Chart, ns string, opts ...helm.InstallOption) (*rls.InstallReleaseResponse, error) {
+	return &rls.InstallReleaseResponse{
+		Release: c.rels[0],
+	}, nil
+}
+
 func (c *fakeReleaseClient) DeleteRelease(rlsName string, opts ...helm.DeleteOption) (*rls.UninstallReleaseResponse, error) {
 	return nil, nil
 }
@@ -162,6 +168,10 @@ func (c *fakeReleaseClient) UpdateRelease(rlsName string, chStr string, opts ...
 	return nil, nil
 }
 
+func (c *fakeReleaseClient) UpdateReleaseFromChart(rlsName string, chart *chart.Chart, opts ...helm.UpdateOption) (*rls.UpdateReleaseResponse, error) {
+	return nil, nil
+}
+
 func (c *fakeReleaseClient) RollbackRelease(rlsName string, opts ...helm.RollbackOption) (*rls.RollbackReleaseResponse, error) {
 	return nil, nil
 }
@@ -179,6 +189,35 @@ func (c *fakeReleaseClient) ReleaseHistory(rlsName string, opts ...helm.HistoryO
 	return &rls.GetHistoryResponse{Releases: c.rels}, c.err
 }
 
+func (c *fakeReleaseClient) ImportRelease(revisions []*release.Release, opts ...helm.ImportOption) (*rls.ImportReleaseResponse, error) {
+	c.rels = revisions
+	return &rls.ImportReleaseResponse{Release: revisions[len(revisions)-1]}, c.err
+}
+
+func (c *fakeReleaseClient) ReleaseDrift(rlsName string, opts ...helm.DriftOption) (*rls.GetReleaseDriftResponse, error) {
+	return &rls.GetReleaseDriftResponse{}, c.err
+}
+
+func (c *fakeReleaseClient) SyncRelease(rlsName string, opts ...helm.SyncOption) (*rls.SyncReleaseResponse, error) {
+	return &rls.SyncReleaseResponse{}, c.err
+}
+
+func (c *fakeReleaseClient) SetReleaseValues(rlsName string, rawVals []byte, opts ...helm.SetValuesOption) (*rls.SetReleaseValuesResponse, error) {
+	return &rls.SetReleaseValuesResponse{Release: c.rels[0]}, c.err
+}
+
+func (c *fakeReleaseClient) MaintainStorage(opts ...helm.MaintainOption) (*rls.MaintainStorageResponse, error) {
+	return &rls.MaintainStorageResponse{}, c.err
+}
+
+func (c *fakeReleaseClient) SetReleaseLock(rlsName string, opts ...helm.LockOption) (*rls.SetReleaseLockResponse, error) {
+	return &rls.SetReleaseLockResponse{Release: c.rels[0]}, c.err
+}
+
+func (c *fakeReleaseClient) PromoteRelease(rlsName string, opts ...helm.PromoteOption) (*rls.PromoteReleaseResponse, error) {
+	return &rls.PromoteReleaseResponse{Release: c.rels[0]}, c.err
+}
+
 func (c *fakeReleaseClient) Option(opt ...helm.Option) helm.Interface {
 	return c
 }
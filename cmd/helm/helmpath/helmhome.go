@@ -17,12 +17,29 @@ package helmpath
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 )
 
+// Environment variables that move one component of a Home elsewhere.
+const (
+	CacheHomeEnvVar  = "HELM_CACHE_HOME"
+	ConfigHomeEnvVar = "HELM_CONFIG_HOME"
+	DataHomeEnvVar   = "HELM_DATA_HOME"
+)
+
 // Home describes the location of a CLI configuration.
 //
 // This helper builds paths relative to a Helm Home directory.
+//
+// The files in a Home fall into three components: configuration (the
+// repositories file), cache (downloaded repository indexes) and data (the
+// local repository, plugins and starters). By default all of them live in the
+// Home directory itself. A Home at XDGConfigHome instead keeps its cache and
+// data in XDGCacheHome and XDGDataHome, and $HELM_CONFIG_HOME,
+// $HELM_CACHE_HOME and $HELM_DATA_HOME override the directory of a component
+// in either layout. Paths within a component directory are the same in every
+// layout.
 type Home string
 
 // String returns Home as a string.
@@ -32,30 +49,85 @@ func (h Home) String() string {
 	return string(h)
 }
 
+// ConfigHome returns the directory holding the configuration component.
+func (h Home) ConfigHome() string {
+	if d := os.Getenv(ConfigHomeEnvVar); d != "" {
+		return d
+	}
+	return string(h)
+}
+
+// CacheHome returns the directory holding the cache component.
+func (h Home) CacheHome() string {
+	if d := os.Getenv(CacheHomeEnvVar); d != "" {
+		return d
+	}
+	if h.isXDG() {
+		return XDGCacheHome()
+	}
+	return string(h)
+}
+
+// DataHome returns the directory holding the data component.
+func (h Home) DataHome() string {
+	if d := os.Getenv(DataHomeEnvVar); d != "" {
+		return d
+	}
+	if h.isXDG() {
+		return XDGDataHome()
+	}
+	return string(h)
+}
+
+func (h Home) isXDG() bool {
+	return filepath.Clean(string(h)) == XDGConfigHome()
+}
+
 // Repository returns the path to the local repository.
 func (h Home) Repository() string {
-	return filepath.Join(string(h), "repository")
+	return filepath.Join(h.ConfigHome(), "repository")
 }
 
 // RepositoryFile returns the path to the repositories.yaml file.
 func (h Home) RepositoryFile() string {
-	return filepath.Join(string(h), "repository/repositories.yaml")
+	return filepath.Join(h.ConfigHome(), "repository/repositories.yaml")
+}
+
+// ConfigFile returns the path to the config.yaml file.
+func (h Home) ConfigFile() string {
+	return filepath.Join(h.ConfigHome(), "config.yaml")
+}
+
+// TrustPolicyFile returns the path to the trust-policy.yaml file.
+func (h Home) TrustPolicyFile() string {
+	return filepath.Join(h.ConfigHome(), "trust-policy.yaml")
+}
+
+// Keyring returns the path to the keyring managed by 'helm keys'.
+func (h Home) Keyring() string {
+	return filepath.Join(h.ConfigHome(), "keyring.gpg")
 }
 
 // Cache returns the path to the local cache.
 func (h Home) Cache() string {
-	return filepath.Join(string(h), "repository/cache")
+	return filepath.Join(h.CacheHome(), "repository/cache")
 }
 
 // CacheIndex returns the path to an index for the given named repository.
 func (h Home) CacheIndex(name string) string {
 	target := fmt.Sprintf("repository/cache/%s-index.yaml", name)
-	return filepath.Join(string(h), target)
+	return filepath.Join(h.CacheHome(), target)
 }
 
 // Starters returns the path to the Helm starter packs.
 func (h Home) Starters() string {
-	return filepath.Join(string(h), "starters")
+	return filepath.Join(h.DataHome(), "starters")
+}
+
+// StartersFile returns the path to the starters.yaml file, which records
+// where installed starters came from.
+func (h Home) StartersFile() string {
+	return filepath.Join(h.Starters(), "starters.yaml")
 }
 
 // LocalRepository returns the location to the local repo.
@@ -64,11 +136,11 @@ func (h Home) Starters() string {
 //
 // If additional path elements are passed, they are appended to the returned path.
 func (h Home) LocalRepository(paths ...string) string {
-	frag := append([]string{string(h), "repository/local"}, paths...)
+	frag := append([]string{h.DataHome(), "repository/local"}, paths...)
 	return filepath.Join(frag...)
 }
 
 // Plugins returns the path to the plugins directory.
 func (h Home) Plugins() string {
-	return filepath.Join(string(h), "plugins")
+	return filepath.Join(h.DataHome(), "plugins")
 }
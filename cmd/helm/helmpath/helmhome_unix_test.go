@@ -17,6 +17,7 @@
 package helmpath
 
 import (
+	"os"
 	"runtime"
 	"testing"
 )
@@ -33,8 +34,49 @@ func TestHelmHome(t *testing.T) {
 	isEq(t, hh.String(), "/r")
 	isEq(t, hh.Repository(), "/r/repository")
 	isEq(t, hh.RepositoryFile(), "/r/repository/repositories.yaml")
+	isEq(t, hh.ConfigFile(), "/r/config.yaml")
+	isEq(t, hh.TrustPolicyFile(), "/r/trust-policy.yaml")
+	isEq(t, hh.Keyring(), "/r/keyring.gpg")
 	isEq(t, hh.LocalRepository(), "/r/repository/local")
 	isEq(t, hh.Cache(), "/r/repository/cache")
 	isEq(t, hh.CacheIndex("t"), "/r/repository/cache/t-index.yaml")
 	isEq(t, hh.Starters(), "/r/starters")
+	isEq(t, hh.StartersFile(), "/r/starters/starters.yaml")
+}
+
+func TestHelmHomeXDG(t *testing.T) {
+	for _, v := range []string{"XDG_CONFIG_HOME", "XDG_CACHE_HOME", "XDG_DATA_HOME", CacheHomeEnvVar, ConfigHomeEnvVar, DataHomeEnvVar} {
+		defer os.Setenv(v, os.Getenv(v))
+		os.Unsetenv(v)
+	}
+	os.Setenv("XDG_CONFIG_HOME", "/c")
+	os.Setenv("XDG_CACHE_HOME", "/k")
+	os.Setenv("XDG_DATA_HOME", "/d")
+
+	isEq := func(t *testing.T, a, b string) {
+		if a != b {
+			t.Errorf("Expected %q, got %q", b, a)
+		}
+	}
+
+	hh := Home(XDGConfigHome())
+	isEq(t, hh.String(), "/c/helm")
+	isEq(t, hh.RepositoryFile(), "/c/helm/repository/repositories.yaml")
+	isEq(t, hh.CacheIndex("t"), "/k/helm/repository/cache/t-index.yaml")
+	isEq(t, hh.LocalRepository(), "/d/helm/repository/local")
+	isEq(t, hh.Plugins(), "/d/helm/plugins")
+	isEq(t, hh.Starters(), "/d/helm/starters")
+
+	// Any other home keeps everything in one directory...
+	hh = Home("/r")
+	isEq(t, hh.Cache(), "/r/repository/cache")
+	isEq(t, hh.Plugins(), "/r/plugins")
+
+	// ...unless a component is overridden.
+	os.Setenv(CacheHomeEnvVar, "/tmp/cache")
+	os.Setenv(DataHomeEnvVar, "/data")
+	isEq(t, hh.Cache(), "/tmp/cache/repository/cache")
+	isEq(t, hh.Plugins(), "/data/plugins")
+	isEq(t, hh.RepositoryFile(), "/r/repository/repositories.yaml")
+	isEq(t, Home(XDGConfigHome()).Cache(), "/tmp/cache/repository/cache")
 }
@@ -30,8 +30,12 @@ func TestHelmHome(t *testing.T) {
 	isEq(t, hh.String(), "r:\\")
 	isEq(t, hh.Repository(), "r:\\repository")
 	isEq(t, hh.RepositoryFile(), "r:\\repository\\repositories.yaml")
+	isEq(t, hh.ConfigFile(), "r:\\config.yaml")
+	isEq(t, hh.TrustPolicyFile(), "r:\\trust-policy.yaml")
+	isEq(t, hh.Keyring(), "r:\\keyring.gpg")
 	isEq(t, hh.LocalRepository(), "r:\\repository\\local")
 	isEq(t, hh.Cache(), "r:\\repository\\cache")
 	isEq(t, hh.CacheIndex("t"), "r:\\repository\\cache\\t-index.yaml")
 	isEq(t, hh.Starters(), "r:\\starters")
+	isEq(t, hh.StartersFile(), "r:\\starters\\starters.yaml")
 }
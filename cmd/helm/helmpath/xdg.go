@@ -0,0 +1,57 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helmpath
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// XDGConfigHome returns the Helm directory under $XDG_CONFIG_HOME, which
+// defaults to ~/.config (%APPDATA% on Windows).
+func XDGConfigHome() string {
+	return xdgDir("XDG_CONFIG_HOME")
+}
+
+// XDGCacheHome returns the Helm directory under $XDG_CACHE_HOME, which
+// defaults to ~/.cache (%LOCALAPPDATA% on Windows).
+func XDGCacheHome() string {
+	return xdgDir("XDG_CACHE_HOME")
+}
+
+// XDGDataHome returns the Helm directory under $XDG_DATA_HOME, which
+// defaults to ~/.local/share (%APPDATA% on Windows).
+func XDGDataHome() string {
+	return xdgDir("XDG_DATA_HOME")
+}
+
+// UserHomeDir returns the home directory of the current user: $HOME, or
+// %USERPROFILE% where $HOME is not set, as is usual on Windows.
+func UserHomeDir() string {
+	if h := os.Getenv("HOME"); h != "" {
+		return h
+	}
+	return os.Getenv("USERPROFILE")
+}
+
+func xdgDir(envVar string) string {
+	base := os.Getenv(envVar)
+	if base == "" {
+		base = xdgDefault(envVar)
+	}
+	return filepath.Join(base, "helm")
+}
@@ -0,0 +1,30 @@
+// Copyright 2016 The Kubernetes Authors All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build windows
+
+package helmpath
+
+import "os"
+
+// xdgDefault returns the Windows equivalent of the base directory the XDG
+// specification defaults envVar to: roaming application data for
+// configuration and data, and local application data for caches.
+func xdgDefault(envVar string) string {
+	if envVar == "XDG_CACHE_HOME" {
+		if d := os.Getenv("LOCALAPPDATA"); d != "" {
+			return d
+		}
+	}
+	return os.Getenv("APPDATA")
+}
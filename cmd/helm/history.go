@@ -19,6 +19,8 @@ package main
 import (
 	"fmt"
 	"io"
+	"sort"
+	"strings"
 
 	"github.com/gosuri/uitable"
 	"github.com/spf13/cobra"
@@ -26,6 +28,8 @@ import (
 	"k8s.io/helm/pkg/helm"
 	"k8s.io/helm/pkg/proto/hapi/chart"
 	"k8s.io/helm/pkg/proto/hapi/release"
+	"k8s.io/helm/pkg/provenance"
+	"k8s.io/helm/pkg/releaseutil"
 	"k8s.io/helm/pkg/timeconv"
 )
 
@@ -43,13 +47,34 @@ The historical release set is printed as a formatted table, e.g:
     2           Mon Oct 3 10:15:13 2016     SUPERSEDED      alpine-0.1.0
     3           Mon Oct 3 10:15:13 2016     SUPERSEDED      alpine-0.1.0
     4           Mon Oct 3 10:15:13 2016     DEPLOYED        alpine-0.1.0
+
+If Tiller signs the release records it stores, '--verify' checks the
+signature of each revision against the keyring, and that no revision was
+removed from the middle of the history. It fails if any cannot be verified.
 `
 
 type historyCmd struct {
-	max   int32
-	rls   string
-	out   io.Writer
-	helmc helm.Interface
+	max     int32
+	rls     string
+	output  string
+	verify  bool
+	keyring string
+	out     io.Writer
+	helmc   helm.Interface
+}
+
+// historyRevision is a revision in the machine readable output of
+// 'helm history'.
+type historyRevision struct {
+	Revision int32  `json:"revision"`
+	Updated  string `json:"updated"`
+	Status   string `json:"status"`
+	Chart    string `json:"chart"`
+
+	Canary    string            `json:"canary,omitempty"`
+	BlueGreen string            `json:"blueGreen,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	Signature string            `json:"signature,omitempty"`
 }
 
 func newHistoryCmd(c helm.Interface, w io.Writer) *cobra.Command {
@@ -66,19 +91,28 @@ func newHistoryCmd(c helm.Interface, w io.Writer) *cobra.Command {
 			case len(args) == 0:
 				return errReleaseRequired
 			case his.helmc == nil:
-				his.helmc = helm.NewClient(helm.Host(tillerHost))
+				his.helmc = helm.NewClient(helm.Host(tillerHost), helm.UserToken(userToken), helm.Impersonate(asUser, asGroups), helm.RequestID(requestID))
 			}
 			his.rls = args[0]
 			return his.run()
 		},
 	}
 
-	cmd.Flags().Int32Var(&his.max, "max", 256, "maximum number of revision to include in history")
+	f := cmd.Flags()
+	f.Int32Var(&his.max, "max", 256, "maximum number of revision to include in history")
+	f.BoolVar(&his.verify, "verify", false, "verify the signatures of the release records")
+	f.StringVar(&his.keyring, "keyring", defaultKeyring(), "keyring containing the public keys release records are signed with")
+	addOutputFlag(f, &his.output, "o", "table")
 
 	return cmd
 }
 
 func (cmd *historyCmd) run() error {
+	format, err := parseOutputFormat(cmd.output, "table")
+	if err != nil {
+		return err
+	}
+
 	opts := []helm.HistoryOption{
 		helm.WithMaxHistory(cmd.max),
 	}
@@ -87,29 +121,119 @@ func (cmd *historyCmd) run() error {
 	if err != nil {
 		return prettyError(err)
 	}
-	if len(r.Releases) == 0 {
-		return nil
+
+	var sigs map[int32]string
+	var failures []string
+	if cmd.verify {
+		signer, err := provenance.NewFromKeyring(cmd.keyring, "")
+		if err != nil {
+			return err
+		}
+		sigs = map[int32]string{}
+		for _, c := range releaseutil.VerifyHistory(signer, r.Releases) {
+			if c.Err != nil {
+				sigs[c.Revision] = c.Err.Error()
+				failures = append(failures, fmt.Sprintf("revision %d: %s", c.Revision, c.Err))
+			} else {
+				sigs[c.Revision] = "verified"
+			}
+		}
+	}
+
+	if !format.human() {
+		if err := format.write(cmd.out, historyOutput(r.Releases, sigs)); err != nil {
+			return err
+		}
+	} else if len(r.Releases) > 0 {
+		fmt.Fprintln(cmd.out, formatHistory(r.Releases, sigs))
 	}
 
-	fmt.Fprintln(cmd.out, formatHistory(r.Releases))
+	if len(failures) > 0 {
+		return fmt.Errorf("%d of %d revisions failed verification:\n  %s", len(failures), len(r.Releases), strings.Join(failures, "\n  "))
+	}
 	return nil
 }
 
-func formatHistory(rls []*release.Release) string {
+// historyOutput lists the revisions in rls oldest first, the same order as
+// the table. sigs holds the outcome of verifying each revision, if verified.
+func historyOutput(rls []*release.Release, sigs map[int32]string) []historyRevision {
+	res := []historyRevision{}
+	for i := len(rls) - 1; i >= 0; i-- {
+		r := rls[i]
+		rev := historyRevision{
+			Revision:  r.Version,
+			Updated:   outputTime(r.Info.LastDeployed),
+			Status:    r.Info.Status.Code.String(),
+			Chart:     formatChartname(r.Chart),
+			Metadata:  r.Info.Metadata,
+			Signature: sigs[r.Version],
+		}
+		if r.Info.Canary != nil {
+			rev.Canary = r.Info.Canary.Phase.String()
+		}
+		if r.Info.BlueGreen != nil {
+			rev.BlueGreen = r.Info.BlueGreen.Phase.String()
+		}
+		res = append(res, rev)
+	}
+	return res
+}
+
+func formatHistory(rls []*release.Release, sigs map[int32]string) string {
+	// The METADATA column is only shown once some revision has any.
+	withMetadata := false
+	for _, r := range rls {
+		if len(r.Info.Metadata) > 0 {
+			withMetadata = true
+		}
+	}
+
 	tbl := uitable.New()
 	tbl.MaxColWidth = 30
-	tbl.AddRow("REVISION", "UPDATED", "STATUS", "CHART")
+	header := []interface{}{"REVISION", "UPDATED", "STATUS", "CHART"}
+	if withMetadata {
+		header = append(header, "METADATA")
+	}
+	if sigs != nil {
+		header = append(header, "SIGNATURE")
+	}
+	tbl.AddRow(header...)
 	for i := len(rls) - 1; i >= 0; i-- {
 		r := rls[i]
 		c := formatChartname(r.Chart)
 		t := timeconv.String(r.Info.LastDeployed)
 		s := r.Info.Status.Code.String()
+		// e.g. "FAILED (canary rolled back)"
+		switch {
+		case r.Info.Canary != nil:
+			s += " (canary " + formatPhase(r.Info.Canary.Phase.String()) + ")"
+		case r.Info.BlueGreen != nil:
+			s += " (blue/green " + formatPhase(r.Info.BlueGreen.Phase.String()) + ")"
+		}
 		v := r.Version
-		tbl.AddRow(v, t, s, c)
+		row := []interface{}{v, t, s, c}
+		if withMetadata {
+			row = append(row, strings.Join(formatMetadata(r.Info.Metadata), ","))
+		}
+		if sigs != nil {
+			row = append(row, sigs[v])
+		}
+		tbl.AddRow(row...)
 	}
 	return tbl.String()
 }
 
+// formatMetadata returns the key=value pairs of a revision's metadata, sorted
+// by key.
+func formatMetadata(md map[string]string) []string {
+	res := []string{}
+	for k, v := range md {
+		res = append(res, k+"="+v)
+	}
+	sort.Strings(res)
+	return res
+}
+
 func formatChartname(c *chart.Chart) string {
 	if c == nil || c.Metadata == nil {
 		// This is an edge case that has happened in prod, though we don't
@@ -118,3 +242,8 @@ func formatChartname(c *chart.Chart) string {
 	}
 	return fmt.Sprintf("%s-%s", c.Metadata.Name, c.Metadata.Version)
 }
+
+// formatPhase turns the name of a phase, such as "ROLLED_BACK", into words.
+func formatPhase(phase string) string {
+	return strings.ToLower(strings.Replace(phase, "_", " ", -1))
+}
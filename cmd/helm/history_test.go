@@ -19,9 +19,12 @@ package main
 import (
 	"bytes"
 	"regexp"
+	"strings"
 	"testing"
 
 	rpb "k8s.io/helm/pkg/proto/hapi/release"
+	"k8s.io/helm/pkg/provenance"
+	"k8s.io/helm/pkg/releaseutil"
 )
 
 func TestHistoryCmd(t *testing.T) {
@@ -33,6 +36,16 @@ func TestHistoryCmd(t *testing.T) {
 		})
 	}
 
+	withMetadata := func(r *rpb.Release, md map[string]string) *rpb.Release {
+		r.Info.Metadata = md
+		return r
+	}
+
+	withCanary := func(r *rpb.Release, phase rpb.Canary_Phase) *rpb.Release {
+		r.Info.Canary = &rpb.Canary{Phase: phase, Percent: 20, Deployments: []string{"web-canary"}}
+		return r
+	}
+
 	tests := []struct {
 		cmds string
 		desc string
@@ -62,6 +75,36 @@ func TestHistoryCmd(t *testing.T) {
 			},
 			xout: "REVISION\tUPDATED                 \tSTATUS    \tCHART           \n3       \t(.*)\tSUPERSEDED\tfoo-0.1.0-beta.1\n4       \t(.*)\tDEPLOYED  \tfoo-0.1.0-beta.1\n",
 		},
+		{
+			cmds: "helm history RELEASE_NAME",
+			desc: "get history with metadata",
+			args: []string{"angry-bird"},
+			resp: []*rpb.Release{
+				withMetadata(mk("angry-bird", 2, rpb.Status_DEPLOYED), map[string]string{"commit": "abc123", "by": "ci"}),
+				mk("angry-bird", 1, rpb.Status_SUPERSEDED),
+			},
+			xout: "REVISION\tUPDATED                 \tSTATUS    \tCHART           \tMETADATA           \n1       \t(.*)\tSUPERSEDED\tfoo-0.1.0-beta.1\t                   \n2       \t(.*)\tDEPLOYED  \tfoo-0.1.0-beta.1\tby=ci,commit=abc123\n",
+		},
+		{
+			cmds: "helm history RELEASE_NAME",
+			desc: "get history with a canary",
+			args: []string{"angry-bird"},
+			resp: []*rpb.Release{
+				withCanary(mk("angry-bird", 2, rpb.Status_FAILED), rpb.Canary_ROLLED_BACK),
+				mk("angry-bird", 1, rpb.Status_DEPLOYED),
+			},
+			xout: "REVISION\tUPDATED                 \tSTATUS                     \tCHART           \n1       \t(.*)\tDEPLOYED                   \tfoo-0.1.0-beta.1\n2       \t(.*)\tFAILED \\(canary rolled back\\)\tfoo-0.1.0-beta.1\n",
+		},
+		{
+			cmds: "helm history -o go-template=TEMPLATE RELEASE_NAME",
+			desc: "get history with a template",
+			args: []string{"-o", "go-template={{range .}}{{.revision}}={{.status}} {{end}}", "angry-bird"},
+			resp: []*rpb.Release{
+				mk("angry-bird", 2, rpb.Status_DEPLOYED),
+				mk("angry-bird", 1, rpb.Status_SUPERSEDED),
+			},
+			xout: "^1=SUPERSEDED 2=DEPLOYED $",
+		},
 	}
 
 	var buf bytes.Buffer
@@ -80,3 +123,43 @@ func TestHistoryCmd(t *testing.T) {
 		buf.Reset()
 	}
 }
+
+func TestHistoryVerify(t *testing.T) {
+	signer, err := provenance.NewFromFiles("testdata/helm-test-key.secret", "testdata/helm-test-key.pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	history := func() []*rpb.Release {
+		var rls []*rpb.Release
+		var prev *rpb.Release
+		for v := int32(1); v <= 2; v++ {
+			r := releaseMock(&releaseOptions{name: "angry-bird", version: v})
+			if err := releaseutil.Sign(signer, r, prev); err != nil {
+				t.Fatal(err)
+			}
+			rls = append([]*rpb.Release{r}, rls...)
+			prev = r
+		}
+		return rls
+	}
+
+	var buf bytes.Buffer
+	cmd := newHistoryCmd(&fakeReleaseClient{rels: history()}, &buf)
+	cmd.ParseFlags([]string{"--verify", "--keyring", "testdata/helm-test-key.pub"})
+	if err := cmd.RunE(cmd, []string{"angry-bird"}); err != nil {
+		t.Fatal(err)
+	}
+	if expect := regexp.MustCompile(`SIGNATURE\s*\n1 .*verified\s*\n2 .*verified\s*\n$`); !expect.Match(buf.Bytes()) {
+		t.Errorf("expected both revisions to be verified, got\n%s", buf.String())
+	}
+
+	tampered := history()
+	tampered[0].Manifest += "\nkind: Secret"
+	buf.Reset()
+	cmd = newHistoryCmd(&fakeReleaseClient{rels: tampered}, &buf)
+	cmd.ParseFlags([]string{"--verify", "--keyring", "testdata/helm-test-key.pub"})
+	err = cmd.RunE(cmd, []string{"angry-bird"})
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 revisions failed verification:\n  revision 2: signature does not match the record") {
+		t.Errorf("expected revision 2 to fail verification, got %v", err)
+	}
+}
@@ -21,22 +21,36 @@ import (
 	"io"
 
 	"github.com/spf13/cobra"
+
+	"k8s.io/helm/cmd/helm/helmpath"
 )
 
 var longHomeHelp = `
 This command displays the location of HELM_HOME. This is where
 any helm configuration files live.
+
+If $HELM_CONFIG_HOME, $HELM_CACHE_HOME or $HELM_DATA_HOME move parts of
+HELM_HOME elsewhere, or HELM_HOME uses the XDG base directory layout, the
+'--all' flag displays the location of each part.
 `
 
 func newHomeCmd(out io.Writer) *cobra.Command {
+	var all bool
 	cmd := &cobra.Command{
 		Use:   "home",
 		Short: "displays the location of HELM_HOME",
 		Long:  longHomeHelp,
 		Run: func(cmd *cobra.Command, args []string) {
-			fmt.Fprintf(out, homePath()+"\n")
+			if !all {
+				fmt.Fprintf(out, homePath()+"\n")
+				return
+			}
+			h := helmpath.Home(homePath())
+			fmt.Fprintf(out, "config: %s\ncache:  %s\ndata:   %s\n", h.ConfigHome(), h.CacheHome(), h.DataHome())
 		},
 	}
+	cmd.Flags().BoolVar(&all, "all", false, "display the location of the config, cache and data directories")
+	cmd.AddCommand(newHomeMigrateCmd(out))
 
 	return cmd
 }
@@ -0,0 +1,176 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/helm/cmd/helm/helmpath"
+)
+
+const homeMigrateDesc = `
+This command moves a single-directory HELM_HOME (by default ~/.helm) into the
+XDG base directories: the repositories file into $XDG_CONFIG_HOME/helm,
+repository indexes into $XDG_CACHE_HOME/helm, and the local repository,
+plugins and starters into $XDG_DATA_HOME/helm. $HELM_CONFIG_HOME,
+$HELM_CACHE_HOME and $HELM_DATA_HOME, if set, are used instead.
+
+Nothing is moved if any destination already exists. Once the old directory has
+been emptied it is removed, and helm uses the new locations by default.
+`
+
+// homeComponent is a path within a Home, and the component it belongs to.
+type homeComponent struct {
+	rel string
+	dir func(helmpath.Home) string
+}
+
+var homeComponents = []homeComponent{
+	{"repository/repositories.yaml", helmpath.Home.ConfigHome},
+	{"repository/cache", helmpath.Home.CacheHome},
+	{"repository/local", helmpath.Home.DataHome},
+	{"plugins", helmpath.Home.DataHome},
+	{"starters", helmpath.Home.DataHome},
+}
+
+type homeMigrateCmd struct {
+	from string
+	to   helmpath.Home
+	out  io.Writer
+}
+
+func newHomeMigrateCmd(out io.Writer) *cobra.Command {
+	m := &homeMigrateCmd{out: out}
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "move HELM_HOME into the XDG base directories",
+		Long:  homeMigrateDesc,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			m.from = os.ExpandEnv(m.from)
+			m.to = helmpath.Home(helmpath.XDGConfigHome())
+			return m.run()
+		},
+	}
+	cmd.Flags().StringVar(&m.from, "from", legacyHelmHome(), "the HELM_HOME to migrate")
+	return cmd
+}
+
+func (m *homeMigrateCmd) run() error {
+	if fi, err := os.Stat(m.from); err != nil || !fi.IsDir() {
+		return fmt.Errorf("%s is not a directory", m.from)
+	}
+
+	type move struct{ src, dst string }
+	var moves []move
+	for _, c := range homeComponents {
+		src := filepath.Join(m.from, c.rel)
+		dst := filepath.Join(c.dir(m.to), c.rel)
+		if _, err := os.Lstat(src); os.IsNotExist(err) {
+			continue
+		}
+		if filepath.Clean(src) == filepath.Clean(dst) {
+			continue
+		}
+		if _, err := os.Lstat(dst); err == nil {
+			return fmt.Errorf("%s already exists. Remove it, or merge it by hand", dst)
+		}
+		moves = append(moves, move{src, dst})
+	}
+
+	for _, mv := range moves {
+		if err := moveFile(mv.src, mv.dst); err != nil {
+			return fmt.Errorf("could not move %s to %s: %s", mv.src, mv.dst, err)
+		}
+		fmt.Fprintf(m.out, "Moved %s to %s\n", mv.src, mv.dst)
+	}
+
+	// The cached index of the local repository is a link to the local
+	// repository's index, both of which may just have moved.
+	localIndex := m.to.CacheIndex("local")
+	if _, err := os.Readlink(localIndex); err == nil {
+		os.Remove(localIndex)
+		if err := os.Symlink(m.to.LocalRepository(localRepoIndexFilePath), localIndex); err != nil {
+			return err
+		}
+	}
+
+	for _, d := range []string{filepath.Join(m.from, "repository"), m.from} {
+		if empty, _ := isEmptyDir(d); empty {
+			os.Remove(d)
+		}
+	}
+	if _, err := os.Stat(m.from); err == nil {
+		fmt.Fprintf(m.out, "%s still has other files in it, and was left in place. Set $HELM_HOME to %s, or remove %s, to use the new locations.\n", m.from, m.to, m.from)
+	}
+	return nil
+}
+
+// moveFile renames src to dst, copying it if they are on different file systems.
+func moveFile(src, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+	if err := copyTree(src, dst); err != nil {
+		os.RemoveAll(dst)
+		return err
+	}
+	return os.RemoveAll(src)
+}
+
+func copyTree(src, dst string) error {
+	return filepath.Walk(src, func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		switch {
+		case fi.IsDir():
+			return os.MkdirAll(target, fi.Mode().Perm())
+		case fi.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		default:
+			b, err := ioutil.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			return ioutil.WriteFile(target, b, fi.Mode().Perm())
+		}
+	})
+}
+
+func isEmptyDir(dir string) (bool, error) {
+	files, err := ioutil.ReadDir(dir)
+	return err == nil && len(files) == 0, err
+}
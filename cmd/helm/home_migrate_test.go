@@ -0,0 +1,77 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/helm/cmd/helm/helmpath"
+)
+
+func TestHomeMigrate(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "helm-migrate-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	for _, v := range []string{"XDG_CONFIG_HOME", "XDG_CACHE_HOME", "XDG_DATA_HOME", helmpath.CacheHomeEnvVar, helmpath.ConfigHomeEnvVar, helmpath.DataHomeEnvVar} {
+		defer os.Setenv(v, os.Getenv(v))
+		os.Unsetenv(v)
+	}
+	os.Setenv("XDG_CONFIG_HOME", filepath.Join(tmp, "config"))
+	os.Setenv("XDG_CACHE_HOME", filepath.Join(tmp, "cache"))
+	os.Setenv("XDG_DATA_HOME", filepath.Join(tmp, "data"))
+
+	legacy := helmpath.Home(filepath.Join(tmp, "legacy"))
+	if err := ensureHome(legacy, ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(legacy.CacheIndex("stable"), []byte("stable"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	to := helmpath.Home(helmpath.XDGConfigHome())
+	m := &homeMigrateCmd{from: legacy.String(), to: to, out: bytes.NewBuffer(nil)}
+	if err := m.run(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range []string{to.RepositoryFile(), to.CacheIndex("stable"), to.LocalRepository("index.yaml"), to.Plugins(), to.Starters()} {
+		if _, err := os.Stat(p); err != nil {
+			t.Errorf("expected %s to exist: %s", p, err)
+		}
+	}
+	if link, err := os.Readlink(to.CacheIndex("local")); err != nil || link != to.LocalRepository("index.yaml") {
+		t.Errorf("expected the local index link to point to %s, got %q (%v)", to.LocalRepository("index.yaml"), link, err)
+	}
+	if _, err := os.Stat(legacy.String()); !os.IsNotExist(err) {
+		t.Errorf("expected %s to have been removed", legacy)
+	}
+
+	// A second migration must not overwrite anything.
+	if err := ensureHome(legacy, ioutil.Discard); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.run(); err == nil {
+		t.Error("expected migrating onto existing directories to fail")
+	}
+}
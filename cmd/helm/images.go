@@ -0,0 +1,171 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/releaseutil"
+)
+
+const imagesDesc = `
+This command lists the container images a chart or a release runs.
+
+The argument is treated as a chart if it is a path to a chart or contains a
+'/' (as in 'stable/mariadb' or a chart URL), and as the name of a release
+otherwise. Charts are rendered locally, like 'helm template' does; for a
+release, the manifest stored by Tiller is used.
+
+Images are collected from the containers and init containers of Pods and of
+the pod templates of Deployments, DaemonSets, StatefulSets, Jobs, CronJobs and
+other workloads.
+
+By default, each image is printed once, sorted. With '--output json', every
+reference is listed with the resource and container that uses it:
+
+	$ helm images stable/mariadb
+	bitnami/mariadb:10.1.19-r0
+`
+
+type imagesCmd struct {
+	target     string
+	version    string
+	revision   int32
+	valuesFile string
+	values     string
+	output     string
+	out        io.Writer
+	client     helm.Interface
+}
+
+func newImagesCmd(client helm.Interface, out io.Writer) *cobra.Command {
+	img := &imagesCmd{
+		out:    out,
+		client: client,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "images [flags] CHART|RELEASE",
+		Short: "list the container images of a chart or release",
+		Long:  imagesDesc,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkArgsLength(len(args), "chart or release name"); err != nil {
+				return err
+			}
+			img.target = args[0]
+			if !isChart(img.target) && img.client == nil {
+				if err := setupConnection(cmd, args); err != nil {
+					return err
+				}
+				img.client = ensureHelmClient(img.client)
+			}
+			return img.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&img.version, "version", "", "version of the chart to render. Without this, the latest version is used")
+	f.Int32Var(&img.revision, "revision", 0, "revision of the release to list images for")
+	f.StringVarP(&img.valuesFile, "values", "f", "", "values file to render the chart with")
+	f.StringVar(&img.values, "set", "", "set values to render the chart with. Separate values with commas: key1=val1,key2=val2")
+	addOutputFlag(f, &img.output, "o", "list")
+
+	return cmd
+}
+
+// isChart reports whether target names a chart rather than a release: a
+// chart directory or archive, or a chart reference such as "stable/mysql".
+func isChart(target string) bool {
+	if _, err := os.Stat(target); err == nil {
+		return true
+	}
+	return strings.Contains(target, "/")
+}
+
+func (i *imagesCmd) run() error {
+	format, err := parseOutputFormat(i.output, "list")
+	if err != nil {
+		return err
+	}
+
+	var manifest string
+	if isChart(i.target) {
+		cp, err := locateChartPath(i.target, i.version, false, "")
+		if err != nil {
+			return err
+		}
+		if manifest, err = renderChart(cp, i.valuesFile, i.values); err != nil {
+			return err
+		}
+	} else {
+		res, err := i.client.ReleaseContent(i.target, helm.ContentReleaseVersion(i.revision))
+		if err != nil {
+			return prettyError(err)
+		}
+		manifest = res.Release.Manifest
+	}
+
+	refs, err := releaseutil.ManifestImages(manifest)
+	if err != nil {
+		return err
+	}
+
+	if !format.human() {
+		return format.write(i.out, refs)
+	}
+
+	for _, img := range uniqueImages(refs) {
+		fmt.Fprintln(i.out, img)
+	}
+	return nil
+}
+
+// renderChart renders the chart at chartPath with the given values, the way
+// 'helm template' does with its defaults.
+func renderChart(chartPath, valuesFile, values string) (string, error) {
+	t := &templateCmd{
+		chartPath:   chartPath,
+		name:        "RELEASE-NAME",
+		namespace:   "default",
+		valuesFile:  valuesFile,
+		values:      values,
+		kubeVersion: chartutil.DefaultKubeVersion.GitVersion,
+	}
+	return t.render()
+}
+
+// uniqueImages returns the images of refs, sorted and without duplicates.
+func uniqueImages(refs []releaseutil.ImageRef) []string {
+	seen := map[string]bool{}
+	images := []string{}
+	for _, r := range refs {
+		if !seen[r.Image] {
+			seen[r.Image] = true
+			images = append(images, r.Image)
+		}
+	}
+	sort.Strings(images)
+	return images
+}
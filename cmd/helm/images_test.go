@@ -0,0 +1,80 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+func imagesReleaseMock() *release.Release {
+	r := releaseMock(&releaseOptions{name: "juno"})
+	r.Manifest = `apiVersion: extensions/v1beta1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.11
+      - name: proxy
+        image: envoy:1.0
+`
+	return r
+}
+
+func TestImagesCmd(t *testing.T) {
+	tests := []releaseCase{
+		{
+			name:     "images of a chart",
+			args:     []string{"testdata/testcharts/alpine"},
+			expected: "^alpine:3.3\n$",
+		},
+		{
+			name:     "images of a release",
+			args:     []string{"juno"},
+			expected: "^envoy:1.0\nnginx:1.11\n$",
+			resp:     imagesReleaseMock(),
+		},
+		{
+			name:     "images of a release as JSON",
+			args:     []string{"juno"},
+			flags:    []string{"--output", "json"},
+			expected: `"image": "envoy:1.0",\s+"kind": "Deployment",\s+"name": "web",\s+"container": "proxy"`,
+			resp:     imagesReleaseMock(),
+		},
+		{
+			name:  "images with an unknown output format",
+			args:  []string{"testdata/testcharts/alpine"},
+			flags: []string{"--output", "xml"},
+			err:   true,
+		},
+		{
+			name: "images without args",
+			err:  true,
+		},
+	}
+	runReleaseCases(t, tests, func(c *fakeReleaseClient, out io.Writer) *cobra.Command {
+		return newImagesCmd(c, out)
+	})
+}
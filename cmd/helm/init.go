@@ -21,6 +21,8 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	kerrors "k8s.io/kubernetes/pkg/api/errors"
@@ -28,6 +30,7 @@ import (
 
 	"k8s.io/helm/cmd/helm/helmpath"
 	"k8s.io/helm/cmd/helm/installer"
+	"k8s.io/helm/pkg/helm"
 	"k8s.io/helm/pkg/repo"
 )
 
@@ -48,6 +51,14 @@ frequently working on the latest code, the flag '--canary-image' will install
 the latest pre-release version of Tiller (e.g. the HEAD commit in the GitHub
 repository on the master branch).
 
+To upgrade an installed Tiller to the version of the client (or to the images
+'--tiller-image' and '--canary-image' pick), use '--upgrade'. A Tiller that runs
+the canary image stays on the canary image, unless '--tiller-image' is given.
+'helm init --upgrade' waits for the new Tiller to roll out and answer requests,
+for at most '--timeout' seconds. It refuses to move Tiller to an older version,
+whose release storage may not read records written by the newer one, unless
+'--force-downgrade' is set.
+
 To dump a manifest containing the Tiller deployment YAML, combine the
 '--dry-run' and '--debug' flags.
 `
@@ -61,14 +72,23 @@ const (
 	localRepositoryURL = "http://127.0.0.1:8879/charts"
 )
 
+// tillerPollInterval is how often 'helm init --upgrade' checks whether the
+// upgraded Tiller answers requests.
+var tillerPollInterval = 2 * time.Second
+
 type initCmd struct {
-	image      string
-	clientOnly bool
-	canary     bool
-	dryRun     bool
-	out        io.Writer
-	home       helmpath.Home
-	kubeClient unversioned.DeploymentsNamespacer
+	image          string
+	clientOnly     bool
+	canary         bool
+	dryRun         bool
+	upgrade        bool
+	forceDowngrade bool
+	timeout        int64
+	out            io.Writer
+	home           helmpath.Home
+	kubeClient     unversioned.DeploymentsNamespacer
+	client         helm.Interface
+	cmd            *cobra.Command
 }
 
 func newInitCmd(out io.Writer) *cobra.Command {
@@ -85,6 +105,7 @@ func newInitCmd(out io.Writer) *cobra.Command {
 				return errors.New("This command does not accept arguments")
 			}
 			i.home = helmpath.Home(homePath())
+			i.cmd = cmd
 			return i.run()
 		},
 	}
@@ -94,6 +115,9 @@ func newInitCmd(out io.Writer) *cobra.Command {
 	f.BoolVar(&i.canary, "canary-image", false, "use the canary tiller image")
 	f.BoolVarP(&i.clientOnly, "client-only", "c", false, "if set does not install tiller")
 	f.BoolVar(&i.dryRun, "dry-run", false, "do not install local or remote")
+	f.BoolVar(&i.upgrade, "upgrade", false, "upgrade Tiller if it is already installed")
+	f.BoolVar(&i.forceDowngrade, "force-downgrade", false, "allow --upgrade to move Tiller to an older version")
+	f.Int64Var(&i.timeout, "timeout", 300, "time in seconds to wait for an upgraded Tiller to become ready")
 
 	return cmd
 }
@@ -128,7 +152,11 @@ func (i *initCmd) run() error {
 			if !kerrors.IsAlreadyExists(err) {
 				return fmt.Errorf("error installing: %s", err)
 			}
-			fmt.Fprintln(i.out, "Warning: Tiller is already installed in the cluster. (Use --client-only to suppress this message.)")
+			if !i.upgrade {
+				fmt.Fprintln(i.out, "Warning: Tiller is already installed in the cluster. (Use --client-only to suppress this message.)")
+			} else if err := i.upgradeTiller(); err != nil {
+				return err
+			}
 		} else {
 			fmt.Fprintln(i.out, "\nTiller (the helm server side component) has been installed into your Kubernetes Cluster.")
 		}
@@ -139,6 +167,60 @@ func (i *initCmd) run() error {
 	return nil
 }
 
+// upgradeTiller upgrades the installed Tiller, then waits for the new one to
+// roll out and answer requests.
+func (i *initCmd) upgradeTiller() error {
+	opts := installer.UpgradeOptions{Image: i.image, Canary: i.canary, ForceDowngrade: i.forceDowngrade}
+	from, to, err := installer.Upgrade(i.kubeClient, tillerNamespace, opts)
+	if err != nil {
+		return fmt.Errorf("error upgrading: %s", err)
+	}
+	if from == to {
+		fmt.Fprintf(i.out, "Tiller is already running %s.\n", to)
+		return nil
+	}
+	fmt.Fprintf(i.out, "Upgrading Tiller from %s to %s...\n", from, to)
+
+	timeout := time.Duration(i.timeout) * time.Second
+	deadline := time.Now().Add(timeout)
+	if err := installer.WaitForRollout(i.kubeClient, tillerNamespace, timeout); err != nil {
+		return fmt.Errorf("error upgrading: %s", err)
+	}
+	if err := i.waitForTiller(installer.ImageVersion(to), deadline); err != nil {
+		return fmt.Errorf("error upgrading: %s", err)
+	}
+	fmt.Fprintln(i.out, "\nTiller (the helm server side component) has been upgraded to the current version.")
+	return nil
+}
+
+// waitForTiller waits until Tiller answers requests, and, if want is set,
+// reports version want.
+func (i *initCmd) waitForTiller(want string, deadline time.Time) error {
+	if i.client == nil {
+		if err := setupConnection(i.cmd, nil); err != nil {
+			return err
+		}
+		i.client = ensureHelmClient(nil)
+	}
+	for {
+		resp, err := i.client.GetVersion()
+		if err == nil {
+			got := ""
+			if resp.Version != nil {
+				got = strings.SplitN(resp.Version.SemVer, "+", 2)[0]
+			}
+			if want == "" || got == want {
+				return nil
+			}
+			err = fmt.Errorf("Tiller reports version %s, expected %s", got, want)
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("Tiller did not become ready: %s", err)
+		}
+		time.Sleep(tillerPollInterval)
+	}
+}
+
 // ensureHome checks to see if $HELM_HOME exists
 //
 // If $HELM_HOME does not exist, this function will create it.
@@ -158,17 +240,25 @@ func ensureHome(home helmpath.Home, out io.Writer) error {
 	repoFile := home.RepositoryFile()
 	if fi, err := os.Stat(repoFile); err != nil {
 		fmt.Fprintf(out, "Creating %s \n", repoFile)
-		r := repo.NewRepoFile()
-		r.Add(&repo.Entry{
-			Name:  stableRepository,
-			URL:   stableRepositoryURL,
-			Cache: "stable-index.yaml",
-		}, &repo.Entry{
-			Name:  localRepository,
-			URL:   localRepositoryURL,
-			Cache: "local-index.yaml",
+		err := repo.UpdateRepositoriesFile(repoFile, func(r *repo.RepoFile) error {
+			// Another helm may have created the file since it was checked.
+			if !r.Has(stableRepository) {
+				r.Add(&repo.Entry{
+					Name:  stableRepository,
+					URL:   stableRepositoryURL,
+					Cache: "stable-index.yaml",
+				})
+			}
+			if !r.Has(localRepository) {
+				r.Add(&repo.Entry{
+					Name:  localRepository,
+					URL:   localRepositoryURL,
+					Cache: "local-index.yaml",
+				})
+			}
+			return nil
 		})
-		if err := r.WriteFile(repoFile, 0644); err != nil {
+		if err != nil {
 			return err
 		}
 		cif := home.CacheIndex(stableRepository)
@@ -178,9 +268,11 @@ func ensureHome(home helmpath.Home, out io.Writer) error {
 	} else if fi.IsDir() {
 		return fmt.Errorf("%s must be a file, not a directory", repoFile)
 	}
-	if r, err := repo.LoadRepositoriesFile(repoFile); err == repo.ErrRepoOutOfDate {
+	if _, err := repo.LoadRepositoriesFile(repoFile); err == repo.ErrRepoOutOfDate {
 		fmt.Fprintln(out, "Updating repository file format...")
-		if err := r.WriteFile(repoFile, 0644); err != nil {
+		// Writing the file back saves it in the current format.
+		err := repo.UpdateRepositoriesFile(repoFile, func(*repo.RepoFile) error { return nil })
+		if err != nil {
 			return err
 		}
 	}
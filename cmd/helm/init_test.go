@@ -22,15 +22,18 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ghodss/yaml"
 
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/api/errors"
+	"k8s.io/kubernetes/pkg/apis/extensions"
 	"k8s.io/kubernetes/pkg/client/unversioned/testclient"
 	"k8s.io/kubernetes/pkg/runtime"
 
 	"k8s.io/helm/cmd/helm/helmpath"
+	"k8s.io/helm/pkg/version"
 )
 
 func TestInitCmd(t *testing.T) {
@@ -170,3 +173,64 @@ func TestEnsureHome(t *testing.T) {
 		t.Errorf("%s should not be a directory", fi)
 	}
 }
+
+func TestInitCmd_upgrade(t *testing.T) {
+	home, err := ioutil.TempDir("", "helm_home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(home)
+
+	running := &extensions.Deployment{
+		ObjectMeta: api.ObjectMeta{Name: "tiller-deploy", Generation: 1},
+		Spec: extensions.DeploymentSpec{
+			Replicas: 1,
+			Template: api.PodTemplateSpec{
+				Spec: api.PodSpec{Containers: []api.Container{{Name: "tiller", Image: "gcr.io/kubernetes-helm/tiller:v1.0.0"}}},
+			},
+		},
+		Status: extensions.DeploymentStatus{ObservedGeneration: 1, Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1},
+	}
+
+	var buf bytes.Buffer
+	fake := testclient.Fake{}
+	fake.AddReactor("create", "deployments", func(action testclient.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.NewAlreadyExists(api.Resource("deployments"), "tiller-deploy")
+	})
+	fake.AddReactor("get", "deployments", func(action testclient.Action) (bool, runtime.Object, error) {
+		return true, running, nil
+	})
+	fake.AddReactor("update", "deployments", func(action testclient.Action) (bool, runtime.Object, error) {
+		running = action.(testclient.UpdateAction).GetObject().(*extensions.Deployment)
+		return true, running, nil
+	})
+
+	cmd := &initCmd{
+		out:        &buf,
+		home:       helmpath.Home(home),
+		kubeClient: fake.Extensions(),
+		client:     &fakeVersionClient{semVer: version.Version},
+		upgrade:    true,
+		timeout:    1,
+	}
+	if err := cmd.run(); err != nil {
+		t.Fatal(err)
+	}
+	if got := running.Spec.Template.Spec.Containers[0].Image; got != "gcr.io/kubernetes-helm/tiller:"+version.Version {
+		t.Errorf("Expected Tiller to be upgraded, got image %s", got)
+	}
+	expected := "Tiller (the helm server side component) has been upgraded to the current version."
+	if !strings.Contains(buf.String(), expected) {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+
+	// An upgraded Tiller that reports the wrong version fails the upgrade.
+	defer func(d time.Duration) { tillerPollInterval = d }(tillerPollInterval)
+	tillerPollInterval = time.Millisecond
+	running.Spec.Template.Spec.Containers[0].Image = "gcr.io/kubernetes-helm/tiller:v1.0.0"
+	cmd.client = &fakeVersionClient{semVer: "v1.0.0"}
+	cmd.timeout = 0
+	if err := cmd.run(); err == nil || !strings.Contains(err.Error(), "expected "+version.Version) {
+		t.Errorf("Expected a version mismatch, got %v", err)
+	}
+}
@@ -19,12 +19,16 @@ package main
 import (
 	"fmt"
 	"io"
+	"os"
 
 	"github.com/ghodss/yaml"
 	"github.com/spf13/cobra"
 
+	"k8s.io/helm/cmd/helm/downloader"
+	"k8s.io/helm/cmd/helm/helmpath"
 	"k8s.io/helm/pkg/chartutil"
 	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/chart"
 )
 
 const inspectDesc = `
@@ -32,6 +36,9 @@ This command inspects a chart and displays information. It takes a chart referen
 ('stable/drupal'), a full path to a directory or packaged chart, or a URL.
 
 Inspect prints the contents of the Chart.yaml file and the values.yaml file.
+
+Of a chart in a repository that is packaged as a tzst archive, only those two
+files are downloaded, unless the chart has to be verified.
 `
 
 const inspectValuesDesc = `
@@ -46,6 +53,7 @@ of the Charts.yaml file
 
 type inspectCmd struct {
 	chartpath string
+	chart     *chart.Chart
 	output    string
 	verify    bool
 	keyring   string
@@ -75,11 +83,9 @@ func newInspectCmd(c helm.Interface, out io.Writer) *cobra.Command {
 			if err := checkArgsLength(len(args), "chart name"); err != nil {
 				return err
 			}
-			cp, err := locateChartPath(args[0], insp.version, insp.verify, insp.keyring)
-			if err != nil {
+			if err := insp.locate(args[0]); err != nil {
 				return err
 			}
-			insp.chartpath = cp
 			return insp.run()
 		},
 	}
@@ -93,11 +99,9 @@ func newInspectCmd(c helm.Interface, out io.Writer) *cobra.Command {
 			if err := checkArgsLength(len(args), "chart name"); err != nil {
 				return err
 			}
-			cp, err := locateChartPath(args[0], insp.version, insp.verify, insp.keyring)
-			if err != nil {
+			if err := insp.locate(args[0]); err != nil {
 				return err
 			}
-			insp.chartpath = cp
 			return insp.run()
 		},
 	}
@@ -111,11 +115,9 @@ func newInspectCmd(c helm.Interface, out io.Writer) *cobra.Command {
 			if err := checkArgsLength(len(args), "chart name"); err != nil {
 				return err
 			}
-			cp, err := locateChartPath(args[0], insp.version, insp.verify, insp.keyring)
-			if err != nil {
+			if err := insp.locate(args[0]); err != nil {
 				return err
 			}
-			insp.chartpath = cp
 			return insp.run()
 		},
 	}
@@ -145,11 +147,37 @@ func newInspectCmd(c helm.Interface, out io.Writer) *cobra.Command {
 	return inspectCommand
 }
 
-func (i *inspectCmd) run() error {
-	chrt, err := chartutil.Load(i.chartpath)
+// locate finds the chart to inspect. Chart.yaml and values.yaml are read
+// straight out of a remote tzst archive, while other charts are located, and
+// fetched if need be, as for an install.
+func (i *inspectCmd) locate(name string) error {
+	if _, err := os.Stat(name); os.IsNotExist(err) && !i.verify {
+		dl := downloader.ChartDownloader{
+			HelmHome: helmpath.Home(homePath()),
+			Out:      i.out,
+			Keyring:  i.keyring,
+		}
+		if c, err := dl.LoadFiles(name, i.version, chartutil.ChartfileName, chartutil.ValuesfileName); err == nil {
+			i.chart = c
+			return nil
+		}
+	}
+	cp, err := locateChartPath(name, i.version, i.verify, i.keyring)
 	if err != nil {
 		return err
 	}
+	i.chartpath = cp
+	return nil
+}
+
+func (i *inspectCmd) run() error {
+	chrt := i.chart
+	if chrt == nil {
+		var err error
+		if chrt, err = chartutil.Load(i.chartpath); err != nil {
+			return err
+		}
+	}
 	cf, err := yaml.Marshal(chrt.Metadata)
 	if err != nil {
 		return err
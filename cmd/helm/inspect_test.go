@@ -19,8 +19,12 @@ package main
 import (
 	"bytes"
 	"io/ioutil"
+	"os"
 	"strings"
 	"testing"
+
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/repo/repotest"
 )
 
 func TestInspect(t *testing.T) {
@@ -75,3 +79,47 @@ func TestInspect(t *testing.T) {
 	}
 
 }
+
+func TestInspectTzst(t *testing.T) {
+	oldhome := helmHome
+	hh, err := tempHelmHome(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	helmHome = hh
+	defer func() {
+		os.RemoveAll(hh)
+		helmHome = oldhome
+	}()
+
+	srv := repotest.NewServer(hh)
+	defer srv.Stop()
+	ch, err := chartutil.Load("testdata/testcharts/alpine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := chartutil.SaveArchive(ch, srv.Root(), chartutil.FormatTzst); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.CreateIndex(); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.LinkIndices(); err != nil {
+		t.Fatal(err)
+	}
+
+	b := bytes.NewBuffer(nil)
+	insp := &inspectCmd{output: valuesOnly, out: b}
+	if err := insp.locate("test/alpine"); err != nil {
+		t.Fatal(err)
+	}
+	if insp.chartpath != "" {
+		t.Errorf("expected the chart not to be downloaded, got %s", insp.chartpath)
+	}
+	if err := insp.run(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(b.String()) != strings.TrimSpace(ch.Values.Raw) {
+		t.Errorf("expected the values of alpine, got %q", b.String())
+	}
+}
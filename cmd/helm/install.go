@@ -35,9 +35,13 @@ import (
 	"k8s.io/helm/cmd/helm/downloader"
 	"k8s.io/helm/cmd/helm/helmpath"
 	"k8s.io/helm/cmd/helm/strvals"
+	"k8s.io/helm/pkg/chartref"
+	"k8s.io/helm/pkg/chartutil"
 	"k8s.io/helm/pkg/helm"
 	"k8s.io/helm/pkg/kube"
+	"k8s.io/helm/pkg/proto/hapi/chart"
 	"k8s.io/helm/pkg/proto/hapi/release"
+	"k8s.io/helm/pkg/proto/hapi/services"
 )
 
 const installDesc = `
@@ -57,17 +61,60 @@ or
 
 To check the generated manifests of a release without installing the chart,
 the '--debug' and '--dry-run' flags can be combined. This will still require a
-round-trip to the Tiller server.
+round-trip to the Tiller server. With '--dry-run=server', Tiller also sends the
+resources to the Kubernetes API as a dry run, so that they are checked by its
+validation and admission webhooks, without anything being created.
+
+With '--interactive', install asks for the values that the chart's values.yaml
+marks with '# @prompt' comments, unless they are already set with '--values' or
+'--set'. Answers are validated, and pressing enter accepts the default.
+
+To deploy only part of a chart, use '--include' and '--exclude'. Each takes
+template path globs (e.g. 'templates/*-svc.yaml') or Kind/name selectors (e.g.
+'Deployment/web'). '--show-only' renders the selected templates and prints them
+without installing anything, which is handy for debugging a single template.
+
+	$ helm install --show-only templates/deployment.yaml ./redis
 
 If --verify is set, the chart MUST have a provenance file, and the provenenace
 fall MUST pass all verification steps.
 
+A chart directory must have the charts listed in its requirements.yaml in its
+charts/ directory. '--dep-up' runs 'helm dependency build' first if any are
+missing, so they are downloaded at the versions in requirements.lock.
+
 There are four different ways you can express the chart you want to install:
 
 1. By chart reference: helm install stable/mariadb
 2. By path to a packaged chart: helm install ./nginx-1.2.3.tgz
 3. By path to an unpacked chart directory: helm install ./nginx
 4. By absolute URL: helm install https://example.com/charts/nginx-1.2.3.tgz
+5. By a packaged chart read from stdin: helm package -d - ./nginx | helm install -
+
+A chart read from stdin cannot be used with '--verify', which needs its
+provenance file, or with '--interactive', which reads answers from stdin.
+
+BATCHES
+
+'--batch-file' installs, or upgrades if they exist, all of the releases listed
+in a file, each after the releases it needs. The other flags of the command,
+such as '--wait' or '--dry-run', apply to every release. An entry with several
+namespaces becomes one release in each, and its name and values may use
+{{ .Namespace }} to tell them apart:
+
+	releases:
+	  - name: db-{{ .Namespace }}
+	    chart: stable/mariadb
+	    namespaces: [dev, staging]
+	    values: values/db-{{ .Namespace }}.yaml
+	  - name: web-{{ .Namespace }}
+	    chart: ./web
+	    namespaces: [dev, staging]
+	    set: image.tag=1.4.2
+	    needs: [db-{{ .Namespace }}]
+
+A release that fails does not stop the batch, but the releases that need it are
+skipped. A report of every release is printed at the end.
 
 CHART REFERENCES
 
@@ -84,25 +131,54 @@ charts in a repository, use 'helm search'.
 `
 
 type installCmd struct {
-	name         string
-	namespace    string
-	valuesFile   string
-	chartPath    string
-	dryRun       bool
-	disableHooks bool
-	replace      bool
-	verify       bool
-	keyring      string
-	out          io.Writer
-	client       helm.Interface
-	values       string
-	nameTemplate string
-	version      string
+	name          string
+	namespace     string
+	valuesFile    string
+	chartPath     string
+	chart         *chart.Chart // read from stdin, if chartPath is "-"
+	source        *services.ChartSource
+	dryRun        bool
+	serverDryRun  bool
+	disableHooks  bool
+	replace       bool
+	contexts      kubeContexts
+	valuesOverlay string // merged over valuesFile, for one of contexts
+	valuesFrom    valuesFrom
+	verify        bool
+	keyring       string
+	out           io.Writer
+	client        helm.Interface
+	values        string
+	jsonValues    string
+	nameTemplate  string
+	version       string
+	serverSide    bool
+	include       []string
+	exclude       []string
+	showOnly      []string
+	versionCheck  bool
+	noDecrypt     bool
+	noDeprecated  bool
+	subNotes      bool
+	profile       string
+	remoteValues  remoteValues
+	progress      bool
+	interactive   bool
+	wait          bool
+	waitForJobs   bool
+	timeout       int64
+	metadata      []string
+	overrideGates string
+	skipPreflight bool
+	batchFile     string
+	depUp         bool
+	in            io.Reader
 }
 
 func newInstallCmd(c helm.Interface, out io.Writer) *cobra.Command {
 	inst := &installCmd{
 		out:    out,
+		in:     os.Stdin,
 		client: c,
 	}
 
@@ -110,37 +186,93 @@ func newInstallCmd(c helm.Interface, out io.Writer) *cobra.Command {
 		Use:               "install [CHART]",
 		Short:             "install a chart archive",
 		Long:              installDesc,
-		PersistentPreRunE: setupConnection,
+		PersistentPreRunE: inst.contexts.setupConnection,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if inst.batchFile != "" {
+				if len(args) > 0 || inst.name != "" || inst.nameTemplate != "" || inst.valuesFile != "" || inst.values != "" || len(inst.valuesFrom.sources) > 0 ||
+					inst.jsonValues != "" || inst.version != "" || len(inst.showOnly) > 0 || inst.interactive || inst.contexts.enabled() {
+					return errBatchConflict
+				}
+				inst.client = ensureHelmClient(inst.client)
+				return inst.runBatch()
+			}
 			if err := checkArgsLength(len(args), "chart name"); err != nil {
 				return err
 			}
+			if args[0] == "-" {
+				if err := inst.readChart(); err != nil {
+					return err
+				}
+				return inst.runAll()
+			}
 			cp, err := locateChartPath(args[0], inst.version, inst.verify, inst.keyring)
 			if err != nil {
 				return err
 			}
 			inst.chartPath = cp
-			inst.client = ensureHelmClient(inst.client)
-			return inst.run()
+			if inst.source, err = chartSource(args[0], inst.version, cp); err != nil {
+				return err
+			}
+			return inst.runAll()
 		},
 	}
 
 	f := cmd.Flags()
-	f.StringVarP(&inst.valuesFile, "values", "f", "", "specify values in a YAML file")
+	f.StringVarP(&inst.valuesFile, "values", "f", "", "specify values in a YAML file or a URL (append #sha256=DIGEST to pin its content)")
 	f.StringVarP(&inst.name, "name", "n", "", "release name. If unspecified, it will autogenerate one for you")
 	f.StringVar(&inst.namespace, "namespace", "", "namespace to install the release into")
-	f.BoolVar(&inst.dryRun, "dry-run", false, "simulate an install")
+	addDryRunFlag(f, &inst.dryRun, &inst.serverDryRun, "simulate an install. With --dry-run=server, the Kubernetes API also validates the release's resources")
 	f.BoolVar(&inst.disableHooks, "no-hooks", false, "prevent hooks from running during install")
 	f.BoolVar(&inst.replace, "replace", false, "re-use the given name, even if that name is already used. This is unsafe in production")
 	f.StringVar(&inst.values, "set", "", "set values on the command line. Separate values with commas: key1=val1,key2=val2")
+	f.StringVar(&inst.jsonValues, "set-json", "", "set JSON values on the command line. Separate values with commas: key1=JSON1,key2=JSON2")
 	f.StringVar(&inst.nameTemplate, "name-template", "", "specify template used to name the release")
 	f.BoolVar(&inst.verify, "verify", false, "verify the package before installing it")
 	f.StringVar(&inst.keyring, "keyring", defaultKeyring(), "location of public keys used for verification")
 	f.StringVar(&inst.version, "version", "", "specify the exact chart version to install. If this is not specified, the latest version is installed")
+	f.BoolVar(&inst.serverSide, "server-side", false, "create resources using server-side apply instead of client-side create")
+	f.StringSliceVar(&inst.include, "include", []string{}, "only install templates matching these path globs or Kind/name selectors")
+	f.StringSliceVar(&inst.exclude, "exclude", []string{}, "skip templates matching these path globs or Kind/name selectors")
+	f.BoolVar(&inst.versionCheck, "force-version-check", true, "refuse to install if the chart's kubeVersion or tillerVersion constraints are not met")
+	f.BoolVar(&inst.skipPreflight, "skip-preflight", false, "do not run the pre-flight checks of the chart")
+	f.BoolVar(&inst.noDeprecated, "no-deprecated", false, "refuse to install deprecated charts")
+	f.BoolVar(&inst.subNotes, "render-subchart-notes", false, "also render and show the notes of subcharts")
+	f.StringVar(&inst.profile, "profile", "", "merge the named profile of the values file over its other values")
+	inst.remoteValues.addFlags(f)
+	f.BoolVar(&inst.noDecrypt, "no-decrypt", false, "do not decrypt SOPS-encrypted values files")
+	f.BoolVar(&inst.progress, "progress", false, "print each change to the release's resources as it is made")
+	f.StringSliceVar(&inst.showOnly, "show-only", []string{}, "only render and print templates matching these path globs or Kind/name selectors. Implies --dry-run")
+	f.BoolVar(&inst.interactive, "interactive", false, "prompt for the values the chart asks for that are not already set")
+	f.BoolVar(&inst.wait, "wait", false, "wait until the release's Pods, Deployments, PersistentVolumeClaims and Services are ready before marking the release as successful")
+	f.BoolVar(&inst.waitForJobs, "wait-for-jobs", false, "also wait for the release's Jobs to complete. Implies --wait")
+	f.Int64Var(&inst.timeout, "timeout", 300, "time in seconds to wait with --wait")
+	f.StringSliceVar(&inst.metadata, "metadata", []string{}, "record metadata on the release, such as the commit being deployed: key1=val1,key2=val2")
+	f.StringVar(&inst.overrideGates, "override-gates", "", "install the release even if the deployment windows or gates of Tiller do not allow it, giving the reason for the change")
+	f.StringVar(&inst.batchFile, "batch-file", "", "install or upgrade the releases listed in this file, in dependency order")
+	f.BoolVar(&inst.depUp, "dep-up", false, "run 'helm dependency build' first if dependencies of a chart directory are missing from charts/")
+	inst.valuesFrom.addFlags(f)
+	inst.contexts.addFlags(f)
 
 	return cmd
 }
 
+// runAll runs the install, against each of the --kube-contexts if there are
+// any.
+func (i *installCmd) runAll() error {
+	if !i.contexts.enabled() {
+		i.client = ensureHelmClient(i.client)
+		return i.run()
+	}
+	if i.interactive {
+		return errors.New("--interactive cannot be used with --kube-contexts")
+	}
+	return i.contexts.run(i.out, func(client helm.Interface, valuesOverlay string) error {
+		ic := *i
+		ic.client, ic.valuesOverlay = client, valuesOverlay
+		return ic.run()
+	})
+}
+
 func (i *installCmd) run() error {
 	if flagDebug {
 		fmt.Fprintf(i.out, "CHART PATH: %s\n", i.chartPath)
@@ -150,11 +282,33 @@ func (i *installCmd) run() error {
 		i.namespace = defaultNamespace()
 	}
 
+	if i.chart != nil {
+		if err := checkDeprecatedMetadata(i.out, i.chart.Metadata, i.noDeprecated); err != nil {
+			return err
+		}
+	} else if err := checkDeprecated(i.out, i.chartPath, i.noDeprecated); err != nil {
+		return err
+	}
+
+	if err := i.ensureDependencies(); err != nil {
+		return err
+	}
+
 	rawVals, err := i.vals()
 	if err != nil {
 		return err
 	}
 
+	metadata, err := parseMetadata(i.metadata)
+	if err != nil {
+		return err
+	}
+
+	if len(i.showOnly) > 0 {
+		i.dryRun = true
+		i.include = append(i.include, i.showOnly...)
+	}
+
 	// If template is specified, try to run the template.
 	if i.nameTemplate != "" {
 		i.name, err = generateName(i.nameTemplate)
@@ -165,14 +319,32 @@ func (i *installCmd) run() error {
 		fmt.Printf("FINAL NAME: %s\n", i.name)
 	}
 
-	res, err := i.client.InstallRelease(
-		i.chartPath,
-		i.namespace,
+	opts := []helm.InstallOption{
 		helm.ValueOverrides(rawVals),
 		helm.ReleaseName(i.name),
 		helm.InstallDryRun(i.dryRun),
+		helm.InstallServerDryRun(i.serverDryRun),
 		helm.InstallReuseName(i.replace),
-		helm.InstallDisableHooks(i.disableHooks))
+		helm.InstallDisableHooks(i.disableHooks),
+		helm.InstallServerSideApply(i.serverSide),
+		helm.InstallTemplateFilter(i.include, i.exclude),
+		helm.InstallDisableVersionCheck(!i.versionCheck),
+		helm.InstallSkipPreflight(i.skipPreflight),
+		helm.InstallSubchartNotes(i.subNotes),
+		helm.InstallChartSource(i.source),
+		helm.InstallWait(i.wait, i.waitForJobs, i.timeout),
+		helm.InstallMetadata(metadata),
+		helm.InstallOverrideGates(i.overrideGates),
+	}
+	if i.progress {
+		opts = append(opts, helm.InstallProgress(printProgress(i.out)))
+	}
+	var res *services.InstallReleaseResponse
+	if i.chart != nil {
+		res, err = i.client.InstallReleaseFromChart(i.chart, i.namespace, opts...)
+	} else {
+		res, err = i.client.InstallRelease(i.chartPath, i.namespace, opts...)
+	}
 	if err != nil {
 		return prettyError(err)
 	}
@@ -181,6 +353,10 @@ func (i *installCmd) run() error {
 	if rel == nil {
 		return nil
 	}
+	if len(i.showOnly) > 0 {
+		printTemplates(i.out, rel)
+		return nil
+	}
 	i.printRelease(rel)
 
 	// If this is a dry run, we can't display status.
@@ -197,12 +373,20 @@ func (i *installCmd) run() error {
 	return nil
 }
 
+// printProgress returns a function that prints resource changes to out as
+// they are streamed from Tiller.
+func printProgress(out io.Writer) func(*services.ResourceEvent) {
+	return func(e *services.ResourceEvent) {
+		fmt.Fprintf(out, "%s %s/%s\n", e.Action, strings.ToLower(e.Kind), e.Name)
+	}
+}
+
 func (i *installCmd) vals() ([]byte, error) {
 	base := map[string]interface{}{}
 
 	// User specified a values file via -f/--values
 	if i.valuesFile != "" {
-		bytes, err := ioutil.ReadFile(i.valuesFile)
+		bytes, err := readValuesFile(i.valuesFile, i.remoteValues, !i.noDecrypt)
 		if err != nil {
 			return []byte{}, err
 		}
@@ -212,13 +396,51 @@ func (i *installCmd) vals() ([]byte, error) {
 		}
 	}
 
+	if err := applyProfile(base, i.profile); err != nil {
+		return []byte{}, err
+	}
+
+	if err := mergeValuesFile(base, i.valuesOverlay, i.remoteValues, !i.noDecrypt); err != nil {
+		return []byte{}, err
+	}
+
+	if err := i.valuesFrom.merge(base, i.namespace); err != nil {
+		return []byte{}, err
+	}
+
+	if err := strvals.ParseJSONInto(i.jsonValues, base); err != nil {
+		return []byte{}, fmt.Errorf("failed parsing --set-json data: %s", err)
+	}
+
 	if err := strvals.ParseInto(i.values, base); err != nil {
 		return []byte{}, fmt.Errorf("failed parsing --set data: %s", err)
 	}
 
+	if i.interactive {
+		if err := promptValues(i.in, i.out, i.chartPath, base); err != nil {
+			return []byte{}, err
+		}
+	}
+
 	return yaml.Marshal(base)
 }
 
+// parseMetadata parses the key=value pairs of --metadata.
+func parseMetadata(pairs []string) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	md := map[string]string{}
+	for _, p := range pairs {
+		kv := strings.SplitN(p, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("invalid metadata %q: expected key=value", p)
+		}
+		md[kv[0]] = kv[1]
+	}
+	return md, nil
+}
+
 // printRelease prints info about a release if the flagDebug is true.
 func (i *installCmd) printRelease(rel *release.Release) {
 	if rel == nil {
@@ -235,6 +457,14 @@ func (i *installCmd) printRelease(rel *release.Release) {
 	}
 }
 
+// printTemplates prints the rendered hooks and manifests of a release.
+func printTemplates(out io.Writer, rel *release.Release) {
+	for _, h := range rel.Hooks {
+		fmt.Fprintf(out, "---\n# Source: %s\n%s\n", h.Path, h.Manifest)
+	}
+	fmt.Fprintln(out, strings.TrimPrefix(rel.Manifest, "\n"))
+}
+
 // locateChartPath looks for a chart directory in known places, and returns either the full path or an error.
 //
 // This does not ensure that the chart is well-formed; only that the requested filename exists.
@@ -268,7 +498,8 @@ func locateChartPath(name, version string, verify bool, keyring string) (string,
 		return name, fmt.Errorf("path %q not found", name)
 	}
 
-	crepo := filepath.Join(helmpath.Home(homePath()).Repository(), name)
+	// Charts of the local repository, such as local/foo, are in the data home.
+	crepo := filepath.Join(helmpath.Home(homePath()).DataHome(), "repository", name)
 	if _, err := os.Stat(crepo); err == nil {
 		return filepath.Abs(crepo)
 	}
@@ -282,6 +513,20 @@ func locateChartPath(name, version string, verify bool, keyring string) (string,
 		dl.Verify = downloader.VerifyAlways
 	}
 
+	ref, err := chartref.ParseRemote(name)
+	if err != nil {
+		if e, ok := err.(*chartref.Error); ok && e.Suggestion == "" {
+			e.Suggestion = dl.Suggest("", name)
+		}
+		return name, err
+	}
+	if ref.Kind == chartref.Path {
+		return name, fmt.Errorf("path %q not found", name)
+	}
+	if err := chartref.ValidateVersion(version); err != nil {
+		return name, err
+	}
+
 	filename, _, err := dl.DownloadTo(name, version, ".")
 	if err == nil {
 		lname, err := filepath.Abs(filename)
@@ -294,9 +539,118 @@ func locateChartPath(name, version string, verify bool, keyring string) (string,
 		return filename, err
 	}
 
+	if ref.Kind == chartref.Repo {
+		if s := dl.Suggest(ref.Repo, ref.Name); s != "" && s != ref.String() {
+			return filename, fmt.Errorf("file %q not found. Did you mean %q?", name, s)
+		}
+	}
 	return filename, fmt.Errorf("file %q not found", name)
 }
 
+// readChart loads the chart archive piped to stdin.
+func (i *installCmd) readChart() error {
+	if i.verify {
+		return errors.New("--verify cannot be used with a chart read from stdin")
+	}
+	if i.interactive {
+		return errors.New("--interactive cannot be used with a chart read from stdin")
+	}
+	if i.valuesFile == "-" {
+		return errors.New("the chart and the values file cannot both be read from stdin")
+	}
+	ch, err := readStdinChart(i.in)
+	if err != nil {
+		return err
+	}
+	i.chartPath, i.chart = "-", ch
+	return nil
+}
+
+// readStdinChart reads a chart archive, in either archive format, from in.
+func readStdinChart(in io.Reader) (*chart.Chart, error) {
+	data, err := ioutil.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New("no chart archive was given on stdin")
+	}
+	ch, err := chartutil.LoadArchive(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the chart archive on stdin: %s", err)
+	}
+	return ch, nil
+}
+
+// checkDeprecated warns if the chart at chartPath is deprecated, or returns an
+// error if fail is set.
+func checkDeprecated(out io.Writer, chartPath string, fail bool) error {
+	md, err := chartutil.LoadMetadata(chartPath)
+	if err != nil {
+		// Loading errors are reported by whatever uses the chart.
+		return nil
+	}
+	return checkDeprecatedMetadata(out, md, fail)
+}
+
+// checkDeprecatedMetadata is checkDeprecated for a chart's metadata.
+func checkDeprecatedMetadata(out io.Writer, md *chart.Metadata, fail bool) error {
+	if !md.Deprecated {
+		return nil
+	}
+	msg := fmt.Sprintf("chart %s is deprecated", md.Name)
+	if md.ReplacedBy != "" {
+		msg += fmt.Sprintf(" and replaced by %s", md.ReplacedBy)
+	}
+	if fail {
+		return fmt.Errorf("%s. Remove --no-deprecated to use it anyway", msg)
+	}
+	fmt.Fprintf(out, "WARNING: %s\n", msg)
+	return nil
+}
+
+// ensureDependencies makes sure that the chart's requirements are in its
+// charts/ directory. With --dep-up, missing dependencies of a chart directory
+// are built from its requirements.lock, or resolved if it has none.
+func (i *installCmd) ensureDependencies() error {
+	if i.chart != nil {
+		return checkDependencies(i.chart)
+	}
+	ch, err := chartutil.Load(i.chartPath)
+	if err != nil {
+		// Loading errors are reported by whatever uses the chart.
+		return nil
+	}
+	err = checkDependencies(ch)
+	if err == nil {
+		return nil
+	}
+	if fi, serr := os.Stat(i.chartPath); serr != nil || !fi.IsDir() {
+		return err
+	}
+	if !i.depUp {
+		return fmt.Errorf("%s, or install with --dep-up", err)
+	}
+
+	man := &downloader.Manager{
+		Out:       i.out,
+		ChartPath: i.chartPath,
+		HelmHome:  helmpath.Home(homePath()),
+		Keyring:   i.keyring,
+	}
+	if i.verify {
+		man.Verify = downloader.VerifyAlways
+	}
+	if err := man.Build(); err != nil {
+		return err
+	}
+
+	if ch, err = chartutil.Load(i.chartPath); err != nil {
+		return err
+	}
+	return checkDependencies(ch)
+}
+
 func generateName(nameTemplate string) (string, error) {
 	t, err := template.New("name-template").Funcs(sprig.TxtFuncMap()).Parse(nameTemplate)
 	if err != nil {
@@ -310,7 +664,12 @@ func generateName(nameTemplate string) (string, error) {
 	return b.String(), nil
 }
 
+// defaultNamespace returns the namespace of the Helm context, or else of the
+// kube context, or else "default".
 func defaultNamespace() string {
+	if contextNamespace != "" {
+		return contextNamespace
+	}
 	if ns, _, err := kube.GetConfig(kubeContext).Namespace(); err == nil {
 		return ns
 	}
@@ -0,0 +1,323 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig"
+	"github.com/ghodss/yaml"
+	"github.com/gosuri/uitable"
+
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/storage/driver"
+)
+
+// batchFile is the file given to 'helm install --batch-file'.
+type batchFile struct {
+	Releases []batchEntry `json:"releases"`
+}
+
+// batchEntry describes one or more releases of a chart.
+//
+// Name, Values, Set and Needs may use Go templates with .Namespace, so that an
+// entry with several namespaces yields a distinct release in each.
+type batchEntry struct {
+	Name       string   `json:"name"`
+	Chart      string   `json:"chart"`
+	Version    string   `json:"version"`
+	Namespace  string   `json:"namespace"`
+	Namespaces []string `json:"namespaces"`
+	Values     string   `json:"values"`
+	Set        string   `json:"set"`
+	Needs      []string `json:"needs"`
+}
+
+// batchRelease is a release of a batch, with its templates rendered.
+type batchRelease struct {
+	name      string
+	chart     string
+	version   string
+	namespace string
+	values    string
+	set       string
+	needs     []string
+
+	action string
+	result string
+}
+
+// loadBatchFile reads a batch file and expands it into releases, ordered so
+// that every release comes after the releases it needs.
+//
+// Relative chart and values paths are resolved against the directory of the
+// batch file.
+func loadBatchFile(filename, defaultNS string) ([]*batchRelease, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var bf batchFile
+	if err := yaml.Unmarshal(b, &bf); err != nil {
+		return nil, fmt.Errorf("cannot parse batch file %s: %s", filename, err)
+	}
+	dir := filepath.Dir(filename)
+
+	var rels []*batchRelease
+	for i, e := range bf.Releases {
+		if e.Name == "" || e.Chart == "" {
+			return nil, fmt.Errorf("%s: release %d needs a name and a chart", filename, i+1)
+		}
+		nss := e.Namespaces
+		if len(nss) == 0 {
+			nss = []string{e.Namespace}
+		} else if e.Namespace != "" {
+			return nil, fmt.Errorf("%s: release %q sets both namespace and namespaces", filename, e.Name)
+		}
+		for _, ns := range nss {
+			if ns == "" {
+				ns = defaultNS
+			}
+			r, err := e.expand(ns)
+			if err != nil {
+				return nil, fmt.Errorf("%s: release %q: %s", filename, e.Name, err)
+			}
+			r.chart = relativeTo(dir, r.chart)
+			if r.values != "" && !isRemoteValues(r.values) {
+				r.values = relativeTo(dir, r.values)
+			}
+			rels = append(rels, r)
+		}
+	}
+	return orderBatch(rels)
+}
+
+// expand renders the templates of an entry for a namespace.
+func (e batchEntry) expand(ns string) (*batchRelease, error) {
+	data := map[string]string{"Namespace": ns}
+	r := &batchRelease{chart: e.Chart, version: e.Version, namespace: ns}
+	var err error
+	if r.name, err = renderBatchTemplate(e.Name, data); err != nil {
+		return nil, err
+	}
+	if r.values, err = renderBatchTemplate(e.Values, data); err != nil {
+		return nil, err
+	}
+	if r.set, err = renderBatchTemplate(e.Set, data); err != nil {
+		return nil, err
+	}
+	for _, n := range e.Needs {
+		need, err := renderBatchTemplate(n, data)
+		if err != nil {
+			return nil, err
+		}
+		r.needs = append(r.needs, need)
+	}
+	return r, nil
+}
+
+func renderBatchTemplate(s string, data interface{}) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+	t, err := template.New("batch").Funcs(sprig.TxtFuncMap()).Option("missingkey=error").Parse(s)
+	if err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	if err := t.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// relativeTo resolves a relative path against dir, if something exists there.
+// Anything else, such as a repo/chart reference, is returned as it is.
+func relativeTo(dir, name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	p := filepath.Join(dir, name)
+	if _, err := os.Stat(p); err == nil {
+		return p
+	}
+	return name
+}
+
+// orderBatch sorts releases so that each comes after the releases it needs,
+// keeping the order of the file otherwise.
+func orderBatch(rels []*batchRelease) ([]*batchRelease, error) {
+	byName := map[string]*batchRelease{}
+	for _, r := range rels {
+		if _, ok := byName[r.name]; ok {
+			return nil, fmt.Errorf("release %q is listed more than once", r.name)
+		}
+		byName[r.name] = r
+	}
+
+	var sorted []*batchRelease
+	state := map[string]int{} // 1: visiting, 2: done
+	var visit func(r *batchRelease, path []string) error
+	visit = func(r *batchRelease, path []string) error {
+		switch state[r.name] {
+		case 1:
+			return fmt.Errorf("releases need each other: %s", strings.Join(append(path, r.name), " -> "))
+		case 2:
+			return nil
+		}
+		state[r.name] = 1
+		for _, n := range r.needs {
+			dep, ok := byName[n]
+			if !ok {
+				return fmt.Errorf("release %q needs %q, which is not in the batch", r.name, n)
+			}
+			if err := visit(dep, append(path, r.name)); err != nil {
+				return err
+			}
+		}
+		state[r.name] = 2
+		sorted = append(sorted, r)
+		return nil
+	}
+	for _, r := range rels {
+		if err := visit(r, nil); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
+
+// errBatchConflict is returned when --batch-file is combined with flags that
+// each release of the batch sets for itself.
+var errBatchConflict = errors.New("--batch-file cannot be used with a chart argument, --name, --name-template, --values, --values-from, --set, --set-json, --version, --show-only or --interactive")
+
+// runBatch installs, or upgrades, the releases of the batch file in order,
+// with the other flags of the install shared by all of them, and prints a
+// report at the end.
+//
+// A release that fails does not stop the batch, but the releases that need it
+// are skipped.
+func (i *installCmd) runBatch() error {
+	defaultNS := i.namespace
+	if defaultNS == "" {
+		defaultNS = defaultNamespace()
+	}
+	rels, err := loadBatchFile(i.batchFile, defaultNS)
+	if err != nil {
+		return err
+	}
+
+	results := map[string]*batchRelease{}
+	failed := 0
+	for _, r := range rels {
+		results[r.name] = r
+		if dep := failedNeed(r, results); dep != "" {
+			r.action, r.result = "skip", fmt.Sprintf("skipped: %s failed", dep)
+			failed++
+			continue
+		}
+
+		if _, err := i.client.ReleaseContent(r.name, helm.ContentReleaseVersion(1)); err != nil && strings.Contains(err.Error(), driver.ErrReleaseNotFound.Error()) {
+			r.action = "install"
+		} else {
+			r.action = "upgrade"
+		}
+		verb := "Upgrading"
+		if r.action == "install" {
+			verb = "Installing"
+		}
+		fmt.Fprintf(i.out, "==> %s %s in namespace %s\n", verb, r.name, r.namespace)
+
+		err := i.batchUpgrade(r).run()
+		if err != nil {
+			fmt.Fprintf(i.out, "Error: %s\n", err)
+			r.result = "failed: " + err.Error()
+			failed++
+		} else if i.dryRun {
+			r.result = "ok (dry run)"
+		} else {
+			r.result = "ok"
+		}
+		fmt.Fprintln(i.out)
+	}
+
+	tbl := uitable.New()
+	tbl.MaxColWidth = 60
+	tbl.AddRow("RELEASE", "NAMESPACE", "CHART", "ACTION", "RESULT")
+	for _, r := range rels {
+		tbl.AddRow(r.name, r.namespace, r.chart, r.action, r.result)
+	}
+	fmt.Fprintln(i.out, tbl)
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d releases failed", failed, len(rels))
+	}
+	return nil
+}
+
+// failedNeed returns the name of a release r needs that did not succeed.
+func failedNeed(r *batchRelease, results map[string]*batchRelease) string {
+	for _, n := range r.needs {
+		if dep := results[n]; dep != nil && dep.result != "ok" && dep.result != "ok (dry run)" {
+			return n
+		}
+	}
+	return ""
+}
+
+// batchUpgrade returns the 'helm upgrade --install' of a release of the batch.
+func (i *installCmd) batchUpgrade(r *batchRelease) *upgradeCmd {
+	return &upgradeCmd{
+		release:       r.name,
+		chart:         r.chart,
+		version:       r.version,
+		namespace:     r.namespace,
+		valuesFile:    r.values,
+		values:        r.set,
+		install:       true,
+		client:        i.client,
+		out:           i.out,
+		dryRun:        i.dryRun,
+		serverDryRun:  i.serverDryRun,
+		disableHooks:  i.disableHooks,
+		verify:        i.verify,
+		keyring:       i.keyring,
+		serverSide:    i.serverSide,
+		include:       i.include,
+		exclude:       i.exclude,
+		versionCheck:  i.versionCheck,
+		noDecrypt:     i.noDecrypt,
+		noDeprecated:  i.noDeprecated,
+		subNotes:      i.subNotes,
+		profile:       i.profile,
+		remoteValues:  i.remoteValues,
+		progress:      i.progress,
+		wait:          i.wait,
+		waitForJobs:   i.waitForJobs,
+		timeout:       i.timeout,
+		metadata:      i.metadata,
+		overrideGates: i.overrideGates,
+		skipPreflight: i.skipPreflight,
+	}
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/release"
+	rls "k8s.io/helm/pkg/proto/hapi/services"
+	"k8s.io/helm/pkg/storage/driver"
+)
+
+// batchClient records the installs and upgrades of a batch. Releases in
+// existing are upgraded and the others installed. Installs are recorded by
+// namespace, since the release name is not visible in the options. Calls
+// whose record is in fail fail.
+type batchClient struct {
+	fakeReleaseClient
+	existing map[string]bool
+	fail     map[string]bool
+	calls    []string
+}
+
+func (c *batchClient) record(call string) error {
+	c.calls = append(c.calls, call)
+	if c.fail[call] {
+		return fmt.Errorf("%s broke", call)
+	}
+	return nil
+}
+
+func (c *batchClient) ReleaseContent(name string, opts ...helm.ContentOption) (*rls.GetReleaseContentResponse, error) {
+	if !c.existing[name] {
+		return nil, driver.ErrReleaseNotFound
+	}
+	return &rls.GetReleaseContentResponse{Release: releaseMock(&releaseOptions{name: name})}, nil
+}
+
+func (c *batchClient) InstallRelease(chStr, ns string, opts ...helm.InstallOption) (*rls.InstallReleaseResponse, error) {
+	return &rls.InstallReleaseResponse{}, c.record("install " + filepath.Base(chStr) + " in " + ns)
+}
+
+func (c *batchClient) UpdateRelease(name string, chStr string, opts ...helm.UpdateOption) (*rls.UpdateReleaseResponse, error) {
+	return &rls.UpdateReleaseResponse{}, c.record("upgrade " + name)
+}
+
+func (c *batchClient) ReleaseStatus(name string, opts ...helm.StatusOption) (*rls.GetReleaseStatusResponse, error) {
+	return &rls.GetReleaseStatusResponse{Name: name, Info: &release.Info{Status: &release.Status{Code: release.Status_DEPLOYED}}}, nil
+}
+
+func writeBatchFile(t *testing.T, content string) (string, func()) {
+	dir, err := ioutil.TempDir("", "helm-batch-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	abs, _ := filepath.Abs("testdata/testcharts/alpine")
+	name := filepath.Join(dir, "releases.yaml")
+	if err := ioutil.WriteFile(name, []byte(strings.Replace(content, "ALPINE", abs, -1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return name, func() { os.RemoveAll(dir) }
+}
+
+const testBatch = `
+releases:
+  - name: web-{{ .Namespace }}
+    chart: ALPINE
+    namespaces: [dev, prod]
+    needs: ["db-{{ .Namespace }}"]
+  - name: db-{{ .Namespace }}
+    chart: ALPINE
+    namespaces: [dev, prod]
+  - name: cache
+    chart: ALPINE
+`
+
+func TestInstallBatch(t *testing.T) {
+	name, cleanup := writeBatchFile(t, testBatch)
+	defer cleanup()
+
+	c := &batchClient{existing: map[string]bool{"db-prod": true}}
+	buf := bytes.NewBuffer(nil)
+	cmd := newInstallCmd(c, buf)
+	cmd.ParseFlags([]string{"--batch-file", name, "--namespace", "default"})
+	if err := cmd.RunE(cmd, nil); err != nil {
+		t.Fatalf("%s\n%s", err, buf)
+	}
+
+	expect := []string{"install alpine in dev", "install alpine in dev", "upgrade db-prod", "install alpine in prod", "install alpine in default"}
+	if !reflect.DeepEqual(c.calls, expect) {
+		t.Errorf("expected calls %v, got %v", expect, c.calls)
+	}
+	for _, s := range []string{"==> Installing db-dev in namespace dev", "==> Upgrading db-prod in namespace prod", "cache   \tdefault  \t"} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("expected output to contain %q, got:\n%s", s, buf)
+		}
+	}
+}
+
+func TestInstallBatchFailure(t *testing.T) {
+	name, cleanup := writeBatchFile(t, testBatch)
+	defer cleanup()
+
+	c := &batchClient{
+		existing: map[string]bool{"db-dev": true},
+		fail:     map[string]bool{"upgrade db-dev": true},
+	}
+	buf := bytes.NewBuffer(nil)
+	cmd := newInstallCmd(c, buf)
+	cmd.ParseFlags([]string{"--batch-file", name, "--namespace", "default"})
+	err := cmd.RunE(cmd, nil)
+	if err == nil || err.Error() != "2 of 5 releases failed" {
+		t.Fatalf("expected 2 failed releases, got %v", err)
+	}
+	// web-dev is skipped, the others still run.
+	expect := []string{"upgrade db-dev", "install alpine in prod", "install alpine in prod", "install alpine in default"}
+	if !reflect.DeepEqual(c.calls, expect) {
+		t.Errorf("expected calls %v, got %v", expect, c.calls)
+	}
+	if !strings.Contains(buf.String(), "skipped: db-dev failed") {
+		t.Errorf("expected web-dev to be reported as skipped, got:\n%s", buf)
+	}
+}
+
+func TestLoadBatchFileErrors(t *testing.T) {
+	tests := map[string]string{
+		"releases:\n  - name: a\n":                                                              "needs a name and a chart",
+		"releases:\n  - {name: a, chart: c}\n  - {name: a, chart: c}\n":                         `release "a" is listed more than once`,
+		"releases:\n  - {name: a, chart: c, needs: [b]}\n":                                      `release "a" needs "b", which is not in the batch`,
+		"releases:\n  - {name: a, chart: c, needs: [b]}\n  - {name: b, chart: c, needs: [a]}\n": "releases need each other: a -> b -> a",
+		"releases:\n  - {name: a, chart: c, namespace: x, namespaces: [y]}\n":                   "sets both namespace and namespaces",
+		"releases:\n  - {name: \"{{ .Bogus }}\", chart: c}\n":                                   "Bogus",
+	}
+	for content, expect := range tests {
+		name, cleanup := writeBatchFile(t, content)
+		_, err := loadBatchFile(name, "default")
+		cleanup()
+		if err == nil || !strings.Contains(err.Error(), expect) {
+			t.Errorf("expected an error containing %q, got %v", expect, err)
+		}
+	}
+}
+
+func TestInstallBatchConflicts(t *testing.T) {
+	cmd := newInstallCmd(&batchClient{}, ioutil.Discard)
+	cmd.ParseFlags([]string{"--batch-file", "releases.yaml", "--set", "a=b"})
+	if err := cmd.RunE(cmd, nil); err != errBatchConflict {
+		t.Errorf("expected %q, got %v", errBatchConflict, err)
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"k8s.io/helm/pkg/chartutil"
+)
+
+// promptValues asks for the values annotated with @prompt in the chart's
+// values.yaml that are not set in vals yet, and sets the answers in vals.
+func promptValues(in io.Reader, out io.Writer, chartPath string, vals map[string]interface{}) error {
+	ch, err := chartutil.Load(chartPath)
+	if err != nil {
+		return err
+	}
+	if ch.Values == nil {
+		return nil
+	}
+	prompts, err := chartutil.ParseValuePrompts([]byte(ch.Values.Raw))
+	if err != nil {
+		return fmt.Errorf("invalid prompts in values.yaml of %s: %s", ch.Metadata.Name, err)
+	}
+
+	v := chartutil.Values(vals)
+	r := bufio.NewReader(in)
+	for _, p := range prompts {
+		if _, ok := v.PathValue(p.Path); ok && !isEmptyValue(p, v) {
+			continue
+		}
+		for {
+			fmt.Fprint(out, promptText(p))
+			line, err := r.ReadString('\n')
+			if err != nil && err != io.EOF {
+				return err
+			}
+			val, set, aerr := p.Answer(strings.TrimSpace(line))
+			if aerr != nil {
+				if err == io.EOF {
+					fmt.Fprintln(out)
+					return aerr
+				}
+				fmt.Fprintf(out, "  %s\n", aerr)
+				continue
+			}
+			if set {
+				v.SetPathValue(p.Path, val)
+			}
+			if err == io.EOF && line == "" {
+				fmt.Fprintln(out)
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// isEmptyValue reports whether a required value is set, but only to an empty
+// string, as charts commonly do for values that have no sensible default.
+func isEmptyValue(p *chartutil.ValuePrompt, v chartutil.Values) bool {
+	val, _ := v.PathValue(p.Path)
+	return p.Required && (val == nil || val == "")
+}
+
+func promptText(p *chartutil.ValuePrompt) string {
+	s := p.Prompt
+	if len(p.Enum) > 0 {
+		s += " (" + strings.Join(p.Enum, ", ") + ")"
+	}
+	if p.Default != nil && p.Default != "" {
+		s += fmt.Sprintf(" [%v]", p.Default)
+	} else if p.Required {
+		s += " (required)"
+	}
+	return s + ": "
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPromptValues(t *testing.T) {
+	// The first password is too short, and the size is not one of the choices.
+	in := strings.NewReader("short\nsecret-password\nhuge\nlarge\n")
+	out := bytes.NewBuffer(nil)
+	vals := map[string]interface{}{
+		"db": map[string]interface{}{"replicas": 3},
+	}
+	if err := promptValues(in, out, "testdata/testcharts/prompts", vals); err != nil {
+		t.Fatal(err)
+	}
+
+	if vals["adminPassword"] != "secret-password" {
+		t.Errorf("expected the second password, got %v", vals["adminPassword"])
+	}
+	if vals["size"] != "large" {
+		t.Errorf("expected size large, got %v", vals["size"])
+	}
+	if r := vals["db"].(map[string]interface{})["replicas"]; r != 3 {
+		t.Errorf("expected values set with --set to be kept, got %v", r)
+	}
+
+	o := out.String()
+	for _, e := range []string{
+		"Admin password (required): ",
+		"  adminPassword must match ^.{8,}$",
+		"Size of the cluster (small, medium, large) [small]: ",
+		"  size must be one of small, medium, large",
+	} {
+		if !strings.Contains(o, e) {
+			t.Errorf("expected output to contain %q, got %q", e, o)
+		}
+	}
+	if strings.Contains(o, "replicas") {
+		t.Errorf("expected no prompt for a value already set, got %q", o)
+	}
+}
+
+func TestPromptValuesEOF(t *testing.T) {
+	out := bytes.NewBuffer(nil)
+	err := promptValues(strings.NewReader(""), out, "testdata/testcharts/prompts", map[string]interface{}{})
+	if err == nil || !strings.Contains(err.Error(), "adminPassword is required") {
+		t.Errorf("expected a missing required value to fail, got %v", err)
+	}
+
+	// Defaults are taken when the input ends.
+	vals := map[string]interface{}{"adminPassword": "secret-password"}
+	if err := promptValues(strings.NewReader(""), out, "testdata/testcharts/prompts", vals); err != nil {
+		t.Fatal(err)
+	}
+	if vals["size"] != "small" {
+		t.Errorf("expected the default size, got %v", vals["size"])
+	}
+}
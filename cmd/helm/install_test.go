@@ -17,12 +17,23 @@ limitations under the License.
 package main
 
 import (
+	"bytes"
 	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
+
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/proto/hapi/release"
+	rls "k8s.io/helm/pkg/proto/hapi/services"
+	"k8s.io/helm/pkg/repo/repotest"
 )
 
 func TestInstall(t *testing.T) {
@@ -43,6 +54,14 @@ func TestInstall(t *testing.T) {
 			expected: "juno",
 			resp:     releaseMock(&releaseOptions{name: "juno"}),
 		},
+		// Install, wait for resources and jobs
+		{
+			name:     "install with wait",
+			args:     []string{"testdata/testcharts/alpine"},
+			flags:    strings.Split("--name aeneas --wait-for-jobs --timeout 60", " "),
+			expected: "aeneas",
+			resp:     releaseMock(&releaseOptions{name: "aeneas"}),
+		},
 		// Install, values from cli
 		{
 			name:     "install with values",
@@ -51,6 +70,50 @@ func TestInstall(t *testing.T) {
 			resp:     releaseMock(&releaseOptions{name: "virgil"}),
 			expected: "virgil",
 		},
+		// Install, values from --set-json
+		{
+			name:     "install with JSON values",
+			args:     []string{"testdata/testcharts/alpine"},
+			flags:    []string{"--set-json", `foo={"bar":[1,2]}`, "--set", "foo.baz=qux"},
+			resp:     releaseMock(&releaseOptions{name: "virgil"}),
+			expected: "virgil",
+		},
+		// Install, bad --set-json
+		{
+			name:  "install with invalid JSON values",
+			args:  []string{"testdata/testcharts/alpine"},
+			flags: []string{"--set-json", `foo={"bar":`},
+			err:   true,
+		},
+		// Install, with metadata
+		{
+			name:     "install with metadata",
+			args:     []string{"testdata/testcharts/alpine"},
+			flags:    []string{"--metadata", "commit=abc123,build=https://ci.example.com/42"},
+			resp:     releaseMock(&releaseOptions{name: "virgil"}),
+			expected: "virgil",
+		},
+		// Install, bad metadata
+		{
+			name:  "install with invalid metadata",
+			args:  []string{"testdata/testcharts/alpine"},
+			flags: []string{"--metadata", "commit"},
+			err:   true,
+		},
+		// Install, deprecated chart
+		{
+			name:     "install a deprecated chart",
+			args:     []string{"testdata/testcharts/deprecated"},
+			flags:    strings.Split("--name aeneas", " "),
+			expected: "WARNING: chart deprecated is deprecated and replaced by alpine",
+			resp:     releaseMock(&releaseOptions{name: "aeneas"}),
+		},
+		{
+			name:  "install a deprecated chart with --no-deprecated",
+			args:  []string{"testdata/testcharts/deprecated"},
+			flags: strings.Split("--name aeneas --no-deprecated", " "),
+			err:   true,
+		},
 		// Install, no charts
 		{
 			name: "install with no chart specified",
@@ -73,6 +136,14 @@ func TestInstall(t *testing.T) {
 			expected: "FOOBAR",
 			resp:     releaseMock(&releaseOptions{name: "FOOBAR"}),
 		},
+		// Install, only show selected templates
+		{
+			name:     "install with show-only",
+			args:     []string{"testdata/testcharts/alpine"},
+			flags:    strings.Split("--name aeneas --show-only templates/foo.tpl", " "),
+			expected: "(?s)# Source: pre-install-hook.yaml.*kind: Secret",
+			resp:     releaseMock(&releaseOptions{name: "aeneas"}),
+		},
 		// Install, perform chart verification along the way.
 		{
 			name:  "install with verification, missing provenance",
@@ -98,6 +169,139 @@ func TestInstall(t *testing.T) {
 	})
 }
 
+func TestInstallDepUp(t *testing.T) {
+	oldhome := helmHome
+	hh, err := tempHelmHome(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	helmHome = hh
+	defer func() {
+		os.RemoveAll(hh)
+		helmHome = oldhome
+	}()
+
+	srv := repotest.NewServer(hh)
+	defer srv.Stop()
+	if _, err := srv.CopyCharts("testdata/testcharts/*.tgz"); err != nil {
+		t.Fatal(err)
+	}
+	if err := createTestingChart(hh, "depup", srv.URL()); err != nil {
+		t.Fatal(err)
+	}
+	chartPath := filepath.Join(hh, "depup")
+
+	c := &fakeReleaseClient{rels: []*release.Release{releaseMock(&releaseOptions{name: "aeneas"})}}
+	var buf bytes.Buffer
+	cmd := newInstallCmd(c, &buf)
+	err = cmd.RunE(cmd, []string{chartPath})
+	if err == nil || !strings.Contains(err.Error(), "--dep-up") {
+		t.Fatalf("expected missing dependency error suggesting --dep-up, got %v", err)
+	}
+
+	cmd = newInstallCmd(c, &buf)
+	cmd.ParseFlags([]string{"--dep-up"})
+	if err := cmd.RunE(cmd, []string{chartPath}); err != nil {
+		t.Logf("Output: %s", buf.String())
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(chartPath, "charts/reqtest-0.1.0.tgz")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(chartPath, "requirements.lock")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// stdinClient records the chart of an install from a loaded chart.
+type stdinClient struct {
+	*fakeReleaseClient
+	chart *chart.Chart
+}
+
+func (c *stdinClient) InstallReleaseFromChart(ch *chart.Chart, ns string, opts ...helm.InstallOption) (*rls.InstallReleaseResponse, error) {
+	c.chart = ch
+	return c.fakeReleaseClient.InstallReleaseFromChart(ch, ns, opts...)
+}
+
+// withStdin runs f with os.Stdin reading data.
+func withStdin(t *testing.T, data []byte, f func()) {
+	tmp, err := ioutil.TempFile("", "helm-stdin-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	if _, err := tmp.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+
+	old := os.Stdin
+	os.Stdin = tmp
+	defer func() { os.Stdin = old }()
+	f()
+}
+
+// packChart returns the archive of the chart directory at path.
+func packChart(t *testing.T, path string, format chartutil.ArchiveFormat) []byte {
+	ch, err := chartutil.LoadDir(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := bytes.NewBuffer(nil)
+	if err := chartutil.WriteArchive(b, ch, format); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func TestInstallFromStdin(t *testing.T) {
+	tests := []struct {
+		name  string
+		stdin []byte
+		flags []string
+		err   string
+	}{
+		{name: "tgz", stdin: packChart(t, "testdata/testcharts/alpine", chartutil.FormatTgz)},
+		{name: "tzst", stdin: packChart(t, "testdata/testcharts/alpine", chartutil.FormatTzst)},
+		{name: "empty", err: "no chart archive"},
+		{name: "not an archive", stdin: []byte("name: alpine\n"), err: "cannot read the chart archive on stdin"},
+		{name: "verify", stdin: packChart(t, "testdata/testcharts/alpine", chartutil.FormatTgz), flags: []string{"--verify"}, err: "--verify"},
+		{name: "interactive", stdin: packChart(t, "testdata/testcharts/alpine", chartutil.FormatTgz), flags: []string{"--interactive"}, err: "--interactive"},
+		{name: "values", stdin: packChart(t, "testdata/testcharts/alpine", chartutil.FormatTgz), flags: []string{"-f", "-"}, err: "cannot both be read from stdin"},
+	}
+
+	for _, tt := range tests {
+		c := &stdinClient{fakeReleaseClient: &fakeReleaseClient{rels: []*release.Release{releaseMock(&releaseOptions{name: "aeneas"})}}}
+		var buf bytes.Buffer
+		var err error
+		withStdin(t, tt.stdin, func() {
+			cmd := newInstallCmd(c, &buf)
+			cmd.ParseFlags(tt.flags)
+			err = cmd.RunE(cmd, []string{"-"})
+		})
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%q: expected an error containing %q, got %v", tt.name, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %s", tt.name, err)
+			continue
+		}
+		if c.chart == nil || c.chart.Metadata.Name != "alpine" {
+			t.Errorf("%q: expected the chart read from stdin to be installed, got %v", tt.name, c.chart)
+		}
+		if !strings.Contains(buf.String(), "aeneas") {
+			t.Errorf("%q: expected the release to be printed, got %q", tt.name, buf.String())
+		}
+	}
+}
+
 type nameTemplateTestCase struct {
 	tpl              string
 	expected         string
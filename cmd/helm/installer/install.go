@@ -72,7 +72,7 @@ func generateDeployment(image string) *extensions.Deployment {
 	labels := generateLabels(map[string]string{"name": "tiller"})
 	d := &extensions.Deployment{
 		ObjectMeta: api.ObjectMeta{
-			Name:   "tiller-deploy",
+			Name:   deploymentName,
 			Labels: labels,
 		},
 		Spec: extensions.DeploymentSpec{
@@ -80,6 +80,12 @@ func generateDeployment(image string) *extensions.Deployment {
 			Template: api.PodTemplateSpec{
 				ObjectMeta: api.ObjectMeta{
 					Labels: labels,
+					// Let Prometheus find the metrics served on the
+					// probes port.
+					Annotations: map[string]string{
+						"prometheus.io/scrape": "true",
+						"prometheus.io/port":   "44135",
+					},
 				},
 				Spec: api.PodSpec{
 					Containers: []api.Container{
@@ -87,11 +93,11 @@ func generateDeployment(image string) *extensions.Deployment {
 							Name:            "tiller",
 							Image:           image,
 							ImagePullPolicy: "Always",
-							Ports:           []api.ContainerPort{{ContainerPort: 44134, Name: "tiller"}},
+							Ports:           []api.ContainerPort{{ContainerPort: 44134, Name: "tiller"}, {ContainerPort: 44135, Name: "http"}},
 							LivenessProbe: &api.Probe{
 								Handler: api.Handler{
 									HTTPGet: &api.HTTPGetAction{
-										Path: "/liveness",
+										Path: "/healthz",
 										Port: intstr.FromInt(44135),
 									},
 								},
@@ -101,12 +107,14 @@ func generateDeployment(image string) *extensions.Deployment {
 							ReadinessProbe: &api.Probe{
 								Handler: api.Handler{
 									HTTPGet: &api.HTTPGetAction{
-										Path: "/readiness",
+										Path: "/readyz",
 										Port: intstr.FromInt(44135),
 									},
 								},
 								InitialDelaySeconds: 1,
-								TimeoutSeconds:      1,
+								// Tiller checks the storage and the
+								// Kubernetes API for up to 2 seconds each.
+								TimeoutSeconds: 5,
 							},
 						},
 					},
@@ -0,0 +1,153 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package installer // import "k8s.io/helm/cmd/helm/installer"
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver"
+
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	"k8s.io/kubernetes/pkg/client/unversioned"
+)
+
+// deploymentName is the name of the Tiller deployment.
+const deploymentName = "tiller-deploy"
+
+// canaryTag is the tag of the canary Tiller image.
+const canaryTag = "canary"
+
+// rolloutPollInterval is how often WaitForRollout checks the deployment.
+var rolloutPollInterval = 2 * time.Second
+
+// ErrRolloutTimeout is returned when Tiller does not finish rolling out in time.
+var ErrRolloutTimeout = errors.New("timed out waiting for Tiller to roll out")
+
+// UpgradeOptions controls how Upgrade replaces an installed Tiller.
+type UpgradeOptions struct {
+	// Image is the image to upgrade to. If it is empty, the image is picked
+	// like Install does, except that a Tiller running the canary image stays
+	// on the canary image.
+	Image string
+	// Canary upgrades to the canary image.
+	Canary bool
+	// ForceDowngrade allows upgrading to an older version of Tiller. Release
+	// records written by a newer Tiller may use a storage format that an
+	// older one cannot read.
+	ForceDowngrade bool
+}
+
+// Upgrade replaces the image of the Tiller installed in namespace.
+//
+// It returns the image that Tiller was changed from and the one it was
+// changed to. If Tiller already runs that image, nothing is changed.
+func Upgrade(client unversioned.DeploymentsNamespacer, namespace string, opts UpgradeOptions) (string, string, error) {
+	d, err := client.Deployments(namespace).Get(deploymentName)
+	if err != nil {
+		return "", "", err
+	}
+	if len(d.Spec.Template.Spec.Containers) == 0 {
+		return "", "", fmt.Errorf("deployment %s has no containers", deploymentName)
+	}
+	c := &d.Spec.Template.Spec.Containers[0]
+	from := c.Image
+	to := upgradeImage(from, opts)
+
+	if to == from {
+		return from, to, nil
+	}
+	if isDowngrade(from, to) && !opts.ForceDowngrade {
+		return from, to, fmt.Errorf("refusing to downgrade Tiller from %s to %s: release records written by the newer Tiller may not be readable by the older one. Use --force-downgrade to downgrade anyway", from, to)
+	}
+
+	c.Image = to
+	_, err = client.Deployments(namespace).Update(d)
+	return from, to, err
+}
+
+// upgradeImage returns the image that a Tiller running current is upgraded to.
+func upgradeImage(current string, opts UpgradeOptions) string {
+	if opts.Image == "" && !opts.Canary && imageTag(current) == canaryTag {
+		// Canary installs are pinned to the canary image.
+		return deployment("", true).Spec.Template.Spec.Containers[0].Image
+	}
+	return deployment(opts.Image, opts.Canary).Spec.Template.Spec.Containers[0].Image
+}
+
+// isDowngrade reports whether changing from image from to image to moves to
+// an older version. Images whose tags are not versions never count as
+// downgrades.
+func isDowngrade(from, to string) bool {
+	fv, err := semver.NewVersion(imageTag(from))
+	if err != nil {
+		return false
+	}
+	tv, err := semver.NewVersion(imageTag(to))
+	if err != nil {
+		return false
+	}
+	return tv.LessThan(fv)
+}
+
+// imageTag returns the tag of an image reference, or "" if it has none.
+func imageTag(image string) string {
+	i := strings.LastIndex(image, ":")
+	if i < 0 || strings.Contains(image[i:], "/") {
+		return ""
+	}
+	return image[i+1:]
+}
+
+// ImageVersion returns the version that image is tagged with, or "" if its tag
+// is not a version.
+func ImageVersion(image string) string {
+	tag := imageTag(image)
+	if _, err := semver.NewVersion(tag); err != nil {
+		return ""
+	}
+	return tag
+}
+
+// WaitForRollout waits until every replica of the Tiller deployment in
+// namespace runs the current pod template and is available.
+func WaitForRollout(client unversioned.DeploymentsNamespacer, namespace string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		d, err := client.Deployments(namespace).Get(deploymentName)
+		if err != nil {
+			return err
+		}
+		if rolledOut(d) {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return ErrRolloutTimeout
+		}
+		time.Sleep(rolloutPollInterval)
+	}
+}
+
+func rolledOut(d *extensions.Deployment) bool {
+	s := d.Status
+	return s.ObservedGeneration >= d.Generation &&
+		s.UpdatedReplicas == d.Spec.Replicas &&
+		s.Replicas == d.Spec.Replicas &&
+		s.AvailableReplicas >= d.Spec.Replicas
+}
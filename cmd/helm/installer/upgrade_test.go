@@ -0,0 +1,120 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package installer // import "k8s.io/helm/cmd/helm/installer"
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	"k8s.io/kubernetes/pkg/client/unversioned/testclient"
+	"k8s.io/kubernetes/pkg/runtime"
+
+	"k8s.io/helm/pkg/version"
+)
+
+// fakeTiller serves a Tiller deployment running image, recording updates.
+func fakeTiller(image string) (*testclient.Fake, *extensions.Deployment) {
+	d := generateDeployment(image)
+	fake := &testclient.Fake{}
+	fake.AddReactor("get", "deployments", func(action testclient.Action) (bool, runtime.Object, error) {
+		return true, d, nil
+	})
+	fake.AddReactor("update", "deployments", func(action testclient.Action) (bool, runtime.Object, error) {
+		d = action.(testclient.UpdateAction).GetObject().(*extensions.Deployment)
+		return true, d, nil
+	})
+	return fake, d
+}
+
+func TestUpgrade(t *testing.T) {
+	current := defaultImage + ":" + version.Version
+	tests := []struct {
+		name    string
+		running string
+		opts    UpgradeOptions
+		expect  string
+		err     bool
+	}{
+		{"to the client version", defaultImage + ":v1.0.0", UpgradeOptions{}, current, false},
+		{"already current", current, UpgradeOptions{}, current, false},
+		{"custom image", current, UpgradeOptions{Image: "example.com/tiller:v9.0.0"}, "example.com/tiller:v9.0.0", false},
+		{"canary stays canary", defaultImage + ":canary", UpgradeOptions{}, defaultImage + ":canary", false},
+		{"canary to custom image", defaultImage + ":canary", UpgradeOptions{Image: "example.com/tiller:v2.0.0"}, "example.com/tiller:v2.0.0", false},
+		{"to canary", current, UpgradeOptions{Canary: true}, defaultImage + ":canary", false},
+		{"downgrade", defaultImage + ":v99.0.0", UpgradeOptions{}, defaultImage + ":v99.0.0", true},
+		{"forced downgrade", defaultImage + ":v99.0.0", UpgradeOptions{ForceDowngrade: true}, current, false},
+	}
+
+	for _, tt := range tests {
+		fake, _ := fakeTiller(tt.running)
+		from, to, err := Upgrade(fake.Extensions(), "default", tt.opts)
+		if (err != nil) != tt.err {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.err, err)
+			continue
+		}
+		if from != tt.running {
+			t.Errorf("%s: expected to upgrade from %s, got %s", tt.name, tt.running, from)
+		}
+
+		updated := false
+		for _, a := range fake.Actions() {
+			if u, ok := a.(testclient.UpdateAction); ok {
+				updated = true
+				if got := u.GetObject().(*extensions.Deployment).Spec.Template.Spec.Containers[0].Image; got != tt.expect {
+					t.Errorf("%s: expected image %s, got %s", tt.name, tt.expect, got)
+				}
+			}
+		}
+		if want := !tt.err && tt.expect != tt.running; updated != want {
+			t.Errorf("%s: expected update %v, got %v", tt.name, want, updated)
+		}
+		if !tt.err && to != tt.expect {
+			t.Errorf("%s: expected to upgrade to %s, got %s", tt.name, tt.expect, to)
+		}
+	}
+}
+
+func TestWaitForRollout(t *testing.T) {
+	defer func(d time.Duration) { rolloutPollInterval = d }(rolloutPollInterval)
+	rolloutPollInterval = time.Millisecond
+
+	fake, d := fakeTiller(defaultImage + ":v2.0.0")
+	d.Generation = 2
+	if err := WaitForRollout(fake.Extensions(), "default", 10*time.Millisecond); err != ErrRolloutTimeout {
+		t.Errorf("Expected a timeout, got %v", err)
+	}
+
+	d.Status = extensions.DeploymentStatus{ObservedGeneration: 2, Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1}
+	if err := WaitForRollout(fake.Extensions(), "default", time.Second); err != nil {
+		t.Errorf("Expected the rollout to be done, got %v", err)
+	}
+}
+
+func TestImageVersion(t *testing.T) {
+	tests := map[string]string{
+		"gcr.io/kubernetes-helm/tiller:v2.0.0": "v2.0.0",
+		"gcr.io/kubernetes-helm/tiller:canary": "",
+		"localhost:5000/tiller":                "",
+		"localhost:5000/tiller:v2.1.0":         "v2.1.0",
+	}
+	for image, expect := range tests {
+		if got := ImageVersion(image); got != expect {
+			t.Errorf("%s: expected %q, got %q", image, expect, got)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+)
+
+const keysDesc = `
+This command consists of multiple subcommands to manage the public keys that
+charts are verified with.
+
+The keys are kept in a keyring of Helm's own ($HELM_HOME/keyring.gpg), apart
+from the GnuPG keyring, and once a key has been imported into it that keyring
+is the default '--keyring' of every command that verifies charts. Keys can be
+trusted for specific repositories or charts, which adds them to the trust
+policy ($HELM_HOME/trust-policy.yaml).
+
+Example usage:
+    $ helm keys import maintainer.asc
+    $ helm keys trust maintainer@example.com --repo stable
+`
+
+func newKeysCmd(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "keys [FLAGS] list|import|export|trust|remove [ARGS]",
+		Short: "list, import, export, trust, and remove chart signing keys",
+		Long:  keysDesc,
+	}
+
+	cmd.AddCommand(newKeysListCmd(out))
+	cmd.AddCommand(newKeysImportCmd(out))
+	cmd.AddCommand(newKeysExportCmd(out))
+	cmd.AddCommand(newKeysTrustCmd(out))
+	cmd.AddCommand(newKeysRemoveCmd(out))
+
+	return cmd
+}
+
+// loadKeyring reads the keys in the keyring at path. A keyring that does
+// not exist yet holds no keys.
+func loadKeyring(path string) (openpgp.EntityList, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return openpgp.EntityList{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	keys, err := openpgp.ReadKeyRing(f)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read keyring %s: %s", path, err)
+	}
+	return keys, nil
+}
+
+// saveKeyring writes the public part of keys to the keyring at path. Once
+// the last key is removed the keyring is deleted, so that verification falls
+// back to the GnuPG keyring again.
+func saveKeyring(path string, keys openpgp.EntityList) error {
+	if len(keys) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	b := bytes.NewBuffer(nil)
+	for _, k := range keys {
+		if err := k.Serialize(b); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b.Bytes(), 0644)
+}
+
+// readKeys reads keys in either the binary or the ASCII armored format.
+func readKeys(data []byte) (openpgp.EntityList, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("-----BEGIN")) {
+		return openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+	}
+	return openpgp.ReadKeyRing(bytes.NewReader(data))
+}
+
+// writeArmoredKeys writes the public part of keys to out, ASCII armored.
+func writeArmoredKeys(out io.Writer, keys openpgp.EntityList) error {
+	w, err := armor.Encode(out, openpgp.PublicKeyType, nil)
+	if err != nil {
+		return err
+	}
+	for _, k := range keys {
+		if err := k.Serialize(w); err != nil {
+			return err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out)
+	return err
+}
+
+// findKeys returns the keys that query names: by the end of their
+// fingerprint, with at least 8 digits (so a key ID works too), or by a part
+// of one of their user IDs, such as an email address.
+func findKeys(keys openpgp.EntityList, query string) openpgp.EntityList {
+	id := strings.ToUpper(strings.Replace(query, " ", "", -1))
+	id = strings.TrimPrefix(id, "0X")
+	found := openpgp.EntityList{}
+	for _, k := range keys {
+		if len(id) >= 8 && strings.HasSuffix(keyFingerprint(k), id) {
+			found = append(found, k)
+			continue
+		}
+		for name := range k.Identities {
+			if strings.Contains(strings.ToLower(name), strings.ToLower(query)) {
+				found = append(found, k)
+				break
+			}
+		}
+	}
+	return found
+}
+
+// findKey returns the only key that query names.
+func findKey(keys openpgp.EntityList, query string) (*openpgp.Entity, error) {
+	found := findKeys(keys, query)
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("no key matching %q in the keyring. Import it with 'helm keys import'", query)
+	case 1:
+		return found[0], nil
+	}
+	names := []string{}
+	for _, k := range found {
+		names = append(names, keyFingerprint(k))
+	}
+	return nil, fmt.Errorf("%q matches %d keys (%s). Give its fingerprint", query, len(found), strings.Join(names, ", "))
+}
+
+func keyFingerprint(k *openpgp.Entity) string {
+	return fmt.Sprintf("%X", k.PrimaryKey.Fingerprint)
+}
+
+// keyName returns the user IDs of k, sorted.
+func keyName(k *openpgp.Entity) string {
+	ids := []string{}
+	for id := range k.Identities {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return strings.Join(ids, ", ")
+}
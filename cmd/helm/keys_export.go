@@ -0,0 +1,76 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/openpgp"
+
+	"k8s.io/helm/cmd/helm/helmpath"
+)
+
+const keysExportDesc = `
+This command writes public keys from Helm's keyring to standard output, ASCII
+armored, so that they can be shared and imported elsewhere.
+
+A KEY is a fingerprint, a key ID, or part of a user ID such as an email
+address. Without arguments, every key is exported.
+`
+
+type keysExportCmd struct {
+	keys []string
+	out  io.Writer
+	home helmpath.Home
+}
+
+func newKeysExportCmd(out io.Writer) *cobra.Command {
+	exp := &keysExportCmd{out: out}
+
+	cmd := &cobra.Command{
+		Use:   "export [flags] [KEY...]",
+		Short: "write public keys from Helm's keyring",
+		Long:  keysExportDesc,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			exp.keys = args
+			exp.home = helmpath.Home(homePath())
+			return exp.run()
+		},
+	}
+
+	return cmd
+}
+
+func (e *keysExportCmd) run() error {
+	keys, err := loadKeyring(e.home.Keyring())
+	if err != nil {
+		return err
+	}
+	if len(e.keys) > 0 {
+		selected := openpgp.EntityList{}
+		for _, q := range e.keys {
+			k, err := findKey(keys, q)
+			if err != nil {
+				return err
+			}
+			selected = append(selected, k)
+		}
+		keys = selected
+	}
+	return writeArmoredKeys(e.out, keys)
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/helm/cmd/helm/helmpath"
+)
+
+const keysImportDesc = `
+This command adds public keys to Helm's keyring.
+
+Each FILE holds one or more keys, binary or ASCII armored, as exported by
+'gpg --export' or 'helm keys export'. Use '-' to read keys from standard input.
+Only the public part of a key is imported, so a private key may be given too.
+A key that is already in the keyring is replaced.
+`
+
+type keysImportCmd struct {
+	files []string
+	in    io.Reader
+	out   io.Writer
+	home  helmpath.Home
+}
+
+func newKeysImportCmd(out io.Writer) *cobra.Command {
+	imp := &keysImportCmd{in: os.Stdin, out: out}
+
+	cmd := &cobra.Command{
+		Use:   "import [flags] FILE...",
+		Short: "add public keys to Helm's keyring",
+		Long:  keysImportDesc,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return errors.New("This command needs at least 1 argument: file")
+			}
+			imp.files = args
+			imp.home = helmpath.Home(homePath())
+			return imp.run()
+		},
+	}
+
+	return cmd
+}
+
+func (i *keysImportCmd) run() error {
+	keys, err := loadKeyring(i.home.Keyring())
+	if err != nil {
+		return err
+	}
+
+	for _, f := range i.files {
+		var data []byte
+		if f == "-" {
+			data, err = ioutil.ReadAll(i.in)
+		} else {
+			data, err = ioutil.ReadFile(f)
+		}
+		if err != nil {
+			return err
+		}
+		imported, err := readKeys(data)
+		if err != nil {
+			return fmt.Errorf("cannot read keys from %s: %s", f, err)
+		}
+		for _, k := range imported {
+			fp, action := keyFingerprint(k), "Imported"
+			for j, old := range keys {
+				if keyFingerprint(old) == fp {
+					keys = append(keys[:j], keys[j+1:]...)
+					action = "Updated"
+					break
+				}
+			}
+			keys = append(keys, k)
+			fmt.Fprintf(i.out, "%s key %s (%s)\n", action, fp, keyName(k))
+		}
+	}
+	return saveKeyring(i.home.Keyring(), keys)
+}
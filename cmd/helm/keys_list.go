@@ -0,0 +1,105 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"k8s.io/helm/cmd/helm/downloader"
+	"k8s.io/helm/cmd/helm/helmpath"
+)
+
+type keysListCmd struct {
+	out    io.Writer
+	home   helmpath.Home
+	output string
+}
+
+// keysListEntry is a key in the machine readable output of 'helm keys list'.
+type keysListEntry struct {
+	Fingerprint string   `json:"fingerprint"`
+	Name        string   `json:"name"`
+	TrustedFor  []string `json:"trustedFor"`
+}
+
+func newKeysListCmd(out io.Writer) *cobra.Command {
+	list := &keysListCmd{out: out}
+
+	cmd := &cobra.Command{
+		Use:   "list [flags]",
+		Short: "list the keys in Helm's keyring",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			list.home = helmpath.Home(homePath())
+			return list.run()
+		},
+	}
+	addOutputFlag(cmd.Flags(), &list.output, "o", "table")
+
+	return cmd
+}
+
+func (l *keysListCmd) run() error {
+	format, err := parseOutputFormat(l.output, "table")
+	if err != nil {
+		return err
+	}
+	keys, err := loadKeyring(l.home.Keyring())
+	if err != nil {
+		return err
+	}
+	policy, err := downloader.LoadTrustPolicy(l.home.TrustPolicyFile())
+	if os.IsNotExist(err) {
+		policy = &downloader.TrustPolicy{}
+	} else if err != nil {
+		return err
+	}
+
+	entries := []keysListEntry{}
+	for _, k := range keys {
+		e := keysListEntry{Fingerprint: keyFingerprint(k), Name: keyName(k), TrustedFor: []string{}}
+		for _, r := range policy.TrustedFor(e.Fingerprint) {
+			e.TrustedFor = append(e.TrustedFor, r.String())
+		}
+		entries = append(entries, e)
+	}
+	if !format.human() {
+		return format.write(l.out, entries)
+	}
+
+	if len(entries) == 0 {
+		fmt.Fprintln(l.out, "No keys. Import one with 'helm keys import'")
+		return nil
+	}
+	table := uitable.New()
+	table.MaxColWidth = 60
+	table.AddRow("FINGERPRINT", "NAME", "TRUSTED FOR")
+	for _, e := range entries {
+		trusted := "charts without a trust rule"
+		if len(e.TrustedFor) > 0 {
+			trusted = strings.Join(e.TrustedFor, "; ")
+		}
+		table.AddRow(e.Fingerprint, e.Name, trusted)
+	}
+	fmt.Fprintln(l.out, table)
+	return nil
+}
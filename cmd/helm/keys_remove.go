@@ -0,0 +1,74 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/helm/cmd/helm/helmpath"
+)
+
+type keysRemoveCmd struct {
+	key  string
+	out  io.Writer
+	home helmpath.Home
+}
+
+func newKeysRemoveCmd(out io.Writer) *cobra.Command {
+	remove := &keysRemoveCmd{out: out}
+
+	cmd := &cobra.Command{
+		Use:     "remove [flags] KEY",
+		Aliases: []string{"rm"},
+		Short:   "remove a key from Helm's keyring",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkArgsLength(len(args), "key"); err != nil {
+				return err
+			}
+			remove.key = args[0]
+			remove.home = helmpath.Home(homePath())
+			return remove.run()
+		},
+	}
+
+	return cmd
+}
+
+func (r *keysRemoveCmd) run() error {
+	keys, err := loadKeyring(r.home.Keyring())
+	if err != nil {
+		return err
+	}
+	k, err := findKey(keys, r.key)
+	if err != nil {
+		return err
+	}
+	for i, old := range keys {
+		if old == k {
+			keys = append(keys[:i], keys[i+1:]...)
+			break
+		}
+	}
+	if err := saveKeyring(r.home.Keyring(), keys); err != nil {
+		return err
+	}
+	fmt.Fprintf(r.out, "Removed key %s (%s)\n", keyFingerprint(k), keyName(k))
+	return nil
+}
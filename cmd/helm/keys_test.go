@@ -0,0 +1,152 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/helm/cmd/helm/downloader"
+	"k8s.io/helm/cmd/helm/helmpath"
+)
+
+// testKeyFingerprint is the fingerprint of testdata/helm-test-key.pub.
+const testKeyFingerprint = "5E615389B53CA37F0EE60BD3843BBF981FC18762"
+
+func runKeysCmd(newCmd func(io.Writer) *cobra.Command, args ...string) (string, error) {
+	buf := bytes.NewBuffer(nil)
+	cmd := newCmd(buf)
+	if err := cmd.ParseFlags(args); err != nil {
+		return "", err
+	}
+	err := cmd.RunE(cmd, cmd.Flags().Args())
+	return buf.String(), err
+}
+
+func TestKeysCmd(t *testing.T) {
+	hh, err := tempHelmHome(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := homePath()
+	helmHome = hh
+	defer func() {
+		helmHome = old
+		os.RemoveAll(hh)
+	}()
+	keyring := helmpath.Home(hh).Keyring()
+
+	out, err := runKeysCmd(newKeysImportCmd, "testdata/helm-test-key.pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "Imported key "+testKeyFingerprint) {
+		t.Errorf("unexpected output: %q", out)
+	}
+	if defaultKeyring() != keyring {
+		t.Errorf("expected the default keyring to be %s, got %s", keyring, defaultKeyring())
+	}
+	if _, err := downloader.VerifyChart("testdata/testcharts/signtest-0.1.0.tgz", keyring); err != nil {
+		t.Errorf("expected the chart to verify with the imported key: %s", err)
+	}
+
+	// Importing the private key replaces the key, but keeps only its public part.
+	out, err = runKeysCmd(newKeysImportCmd, "testdata/helm-test-key.secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "Updated key "+testKeyFingerprint) {
+		t.Errorf("unexpected output: %q", out)
+	}
+	keys, err := loadKeyring(keyring)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0].PrivateKey != nil {
+		t.Errorf("expected a single public key, got %d keys", len(keys))
+	}
+
+	out, err = runKeysCmd(newKeysListCmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, testKeyFingerprint) || !strings.Contains(out, "Helm Testing") || !strings.Contains(out, "charts without a trust rule") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+
+	if _, err := runKeysCmd(newKeysTrustCmd, "helm-testing"); err == nil {
+		t.Error("expected trust without a source to fail")
+	}
+	if _, err := runKeysCmd(newKeysTrustCmd, "nobody@example.com", "--repo", "stable"); err == nil {
+		t.Error("expected trusting an unknown key to fail")
+	}
+	out, err = runKeysCmd(newKeysTrustCmd, "helm-testing@helm.sh", "--repo", "stable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "for repository stable") {
+		t.Errorf("unexpected output: %q", out)
+	}
+	policy, err := downloader.LoadTrustPolicy(helmpath.Home(hh).TrustPolicyFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(policy.TrustedFor(testKeyFingerprint)) != 1 {
+		t.Errorf("expected the key in the trust policy, got %v", policy.Rules)
+	}
+	out, err = runKeysCmd(newKeysListCmd, "-o", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, `"repository stable"`) {
+		t.Errorf("unexpected output: %s", out)
+	}
+
+	out, err = runKeysCmd(newKeysExportCmd, "1FC18762")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exported, err := readKeys([]byte(out))
+	if err != nil {
+		t.Fatalf("cannot read exported keys: %s\n%s", err, out)
+	}
+	if len(exported) != 1 || keyFingerprint(exported[0]) != testKeyFingerprint {
+		t.Errorf("expected the test key to be exported, got %d keys", len(exported))
+	}
+	if _, err := runKeysCmd(newKeysExportCmd, "nobody@example.com"); err == nil {
+		t.Error("expected exporting an unknown key to fail")
+	}
+
+	out, err = runKeysCmd(newKeysRemoveCmd, testKeyFingerprint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "Removed key "+testKeyFingerprint) {
+		t.Errorf("unexpected output: %q", out)
+	}
+	if _, err := os.Stat(keyring); !os.IsNotExist(err) {
+		t.Error("expected the empty keyring to be deleted")
+	}
+	if defaultKeyring() == keyring {
+		t.Error("expected the default keyring to fall back to GnuPG's")
+	}
+}
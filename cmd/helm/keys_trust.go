@@ -0,0 +1,116 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/helm/cmd/helm/downloader"
+	"k8s.io/helm/cmd/helm/helmpath"
+)
+
+const keysTrustDesc = `
+This command trusts a key of Helm's keyring to sign the charts of a source,
+by adding it to the trust policy ($HELM_HOME/trust-policy.yaml).
+
+A chart downloaded with '--verify' from a source that has a trust rule must be
+signed by one of the keys of that rule, while any key of the keyring may sign
+the charts of a source that has none. '--repo', '--url' and '--chart' are
+globs matched against the repository name, the download URL and the chart
+name; a rule matches the charts that match all of those given. If a rule with
+exactly these patterns exists, the key is added to it. Otherwise a new rule is
+added, before any rule that matches every chart.
+
+A KEY is a fingerprint, a key ID, or part of a user ID such as an email
+address.
+`
+
+type keysTrustCmd struct {
+	key        string
+	repository string
+	url        string
+	chart      string
+	out        io.Writer
+	home       helmpath.Home
+}
+
+func newKeysTrustCmd(out io.Writer) *cobra.Command {
+	trust := &keysTrustCmd{out: out}
+
+	cmd := &cobra.Command{
+		Use:   "trust [flags] KEY",
+		Short: "trust a key to sign the charts of a repository",
+		Long:  keysTrustDesc,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkArgsLength(len(args), "key"); err != nil {
+				return err
+			}
+			trust.key = args[0]
+			trust.home = helmpath.Home(homePath())
+			return trust.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&trust.repository, "repo", "", "glob matched against the name of the repository charts come from")
+	f.StringVar(&trust.url, "url", "", "glob matched against the URL charts are downloaded from")
+	f.StringVar(&trust.chart, "chart", "", "glob matched against the chart name")
+
+	return cmd
+}
+
+func (t *keysTrustCmd) run() error {
+	if t.repository == "" && t.url == "" && t.chart == "" {
+		return errors.New("at least one of --repo, --url or --chart is required")
+	}
+	for _, g := range []string{t.repository, t.url, t.chart} {
+		if _, err := path.Match(g, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %s", g, err)
+		}
+	}
+
+	keys, err := loadKeyring(t.home.Keyring())
+	if err != nil {
+		return err
+	}
+	k, err := findKey(keys, t.key)
+	if err != nil {
+		return err
+	}
+
+	file := t.home.TrustPolicyFile()
+	policy, err := downloader.LoadTrustPolicy(file)
+	if os.IsNotExist(err) {
+		policy = &downloader.TrustPolicy{}
+	} else if err != nil {
+		return err
+	}
+	policy.Trust(t.repository, t.url, t.chart, keyFingerprint(k))
+	if err := policy.WriteFile(file); err != nil {
+		return err
+	}
+
+	rule := downloader.TrustRule{Repository: t.repository, URL: t.url, Chart: t.chart}
+	fmt.Fprintf(t.out, "Trusted key %s (%s) for %s\n", keyFingerprint(k), keyName(k), rule)
+	return nil
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/kube"
+)
+
+// kubeContexts fans a command out to the Tillers of several kube contexts,
+// one after another, as set with --kube-contexts.
+type kubeContexts struct {
+	names []string
+	// values are the CONTEXT=FILE pairs of --values-per-context.
+	values []string
+	// connect connects to the Tiller of a kube context, returning a client
+	// for it and a function that closes the connection.
+	connect func(kubeContext string) (helm.Interface, func(), error)
+}
+
+func (k *kubeContexts) addFlags(f *pflag.FlagSet) {
+	f.StringSliceVar(&k.names, "kube-contexts", []string{}, "run against the Tiller of each of these kubeconfig contexts in turn, instead of the current one")
+	f.StringSliceVar(&k.values, "values-per-context", []string{}, "merge a values file over --values for one of the --kube-contexts: CONTEXT=FILE, can be repeated")
+}
+
+func (k *kubeContexts) enabled() bool {
+	return len(k.names) > 0
+}
+
+// setupConnection is the PersistentPreRunE of a command with --kube-contexts.
+// Without them, it connects to Tiller as usual; with them, the connections
+// are made by run.
+func (k *kubeContexts) setupConnection(cmd *cobra.Command, args []string) error {
+	if !k.enabled() {
+		if len(k.values) > 0 {
+			return errors.New("--values-per-context needs --kube-contexts")
+		}
+		return setupConnection(cmd, args)
+	}
+	if err := applyContext(cmd); err != nil {
+		return err
+	}
+	if tillerHost != "" {
+		return errors.New("--kube-contexts cannot be used with --host or $HELM_HOST")
+	}
+	if asUser == "" && len(asGroups) > 0 {
+		return errors.New("--as-group requires --as")
+	}
+	requestID = helm.NewRequestID()
+	return nil
+}
+
+// overlays returns the values file of --values-per-context for each context.
+func (k *kubeContexts) overlays() (map[string]string, error) {
+	known := map[string]bool{}
+	for _, n := range k.names {
+		known[n] = true
+	}
+	res := map[string]string{}
+	for _, v := range k.values {
+		kv := strings.SplitN(v, "=", 2)
+		if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+			return nil, fmt.Errorf("invalid --values-per-context %q: expected CONTEXT=FILE", v)
+		}
+		if !known[kv[0]] {
+			return nil, fmt.Errorf("--values-per-context %q names a context that is not in --kube-contexts", v)
+		}
+		if _, ok := res[kv[0]]; ok {
+			return nil, fmt.Errorf("--values-per-context has more than one file for context %q", kv[0])
+		}
+		res[kv[0]] = kv[1]
+	}
+	return res, nil
+}
+
+// run calls fn for each kube context, with a client for its Tiller and its
+// values file of --values-per-context, if any. A context that fails does not
+// stop the others. The result of each is summarized once all have run.
+func (k *kubeContexts) run(out io.Writer, fn func(client helm.Interface, valuesOverlay string) error) error {
+	overlays, err := k.overlays()
+	if err != nil {
+		return err
+	}
+	connect := k.connect
+	if connect == nil {
+		connect = connectKubeContext
+	}
+
+	results := make([]string, len(k.names))
+	failed := 0
+	for i, name := range k.names {
+		fmt.Fprintf(out, "==> Kube context %s\n", name)
+		client, closeFn, err := connect(name)
+		if err == nil {
+			err = fn(client, overlays[name])
+			closeFn()
+		}
+		if err != nil {
+			fmt.Fprintf(out, "Error: %s\n", err)
+			results[i] = "failed: " + err.Error()
+			failed++
+		} else {
+			results[i] = "ok"
+		}
+		fmt.Fprintln(out)
+	}
+
+	tbl := uitable.New()
+	tbl.MaxColWidth = 60
+	tbl.AddRow("KUBE CONTEXT", "RESULT")
+	for i, name := range k.names {
+		tbl.AddRow(name, results[i])
+	}
+	fmt.Fprintln(out, tbl)
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d kube contexts failed", failed, len(k.names))
+	}
+	return nil
+}
+
+// connectKubeContext opens a tunnel to the Tiller of a kube context.
+func connectKubeContext(kubeContext string) (helm.Interface, func(), error) {
+	tunnel, err := newTillerPortForwarder(tillerNamespace, kubeContext)
+	if err != nil {
+		return nil, nil, err
+	}
+	host := fmt.Sprintf("localhost:%d", tunnel.Local)
+	if flagDebug {
+		fmt.Printf("Created tunnel to %s using local port: '%d'\n", kubeContext, tunnel.Local)
+	}
+	token := ""
+	if sendToken {
+		if config, err := kube.GetConfig(kubeContext).ClientConfig(); err == nil {
+			token = config.BearerToken
+		}
+	}
+	client := helm.NewClient(helm.Host(host), helm.UserToken(token), helm.Impersonate(asUser, asGroups), helm.RequestID(requestID))
+	return client, tunnel.Close, nil
+}
+
+// mergeValuesFile merges the values of a values file over vals. An empty
+// filename merges nothing.
+func mergeValuesFile(vals map[string]interface{}, filename string, opts remoteValues, decrypt bool) error {
+	if filename == "" {
+		return nil
+	}
+	data, err := readValuesFile(filename, opts, decrypt)
+	if err != nil {
+		return err
+	}
+	overlay := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &overlay); err != nil {
+		return fmt.Errorf("failed to parse %s: %s", filename, err)
+	}
+	chartutil.MergeValues(vals, overlay)
+	return nil
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+func TestInstallKubeContexts(t *testing.T) {
+	var connected []string
+	buf := bytes.NewBuffer(nil)
+	inst := &installCmd{
+		chartPath: "testdata/testcharts/alpine",
+		name:      "aeneas",
+		out:       buf,
+		contexts: kubeContexts{
+			names: []string{"east", "west"},
+			connect: func(kubeContext string) (helm.Interface, func(), error) {
+				connected = append(connected, kubeContext)
+				if kubeContext == "west" {
+					return nil, nil, errors.New("could not find tiller")
+				}
+				rels := []*release.Release{releaseMock(&releaseOptions{name: "aeneas"})}
+				return &fakeReleaseClient{rels: rels}, func() {}, nil
+			},
+		},
+	}
+
+	err := inst.runAll()
+	if err == nil || err.Error() != "1 of 2 kube contexts failed" {
+		t.Errorf("expected one context to fail, got %v", err)
+	}
+	if strings.Join(connected, ",") != "east,west" {
+		t.Errorf("expected both contexts to be connected to in order, got %v", connected)
+	}
+	for _, e := range []string{
+		"==> Kube context east\nNAME: aeneas",
+		"==> Kube context west\nError: could not find tiller",
+		"KUBE CONTEXT\tRESULT",
+		"east        \tok",
+		"west        \tfailed: could not find tiller",
+	} {
+		if !strings.Contains(buf.String(), e) {
+			t.Errorf("expected %q in\n%s", e, buf.String())
+		}
+	}
+}
+
+func TestKubeContextsOverlays(t *testing.T) {
+	tests := []struct {
+		values []string
+		expect map[string]string
+		err    string
+	}{
+		{
+			values: []string{"east=east.yaml", "west=west.yaml"},
+			expect: map[string]string{"east": "east.yaml", "west": "west.yaml"},
+		},
+		{values: []string{"east"}, err: "expected CONTEXT=FILE"},
+		{values: []string{"north=north.yaml"}, err: "not in --kube-contexts"},
+		{values: []string{"east=a.yaml", "east=b.yaml"}, err: "more than one file"},
+	}
+	for _, tt := range tests {
+		k := kubeContexts{names: []string{"east", "west"}, values: tt.values}
+		res, err := k.overlays()
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%v: expected error %q, got %v", tt.values, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: %s", tt.values, err)
+			continue
+		}
+		for ctx, f := range tt.expect {
+			if res[ctx] != f {
+				t.Errorf("%v: expected %q for %s, got %q", tt.values, f, ctx, res[ctx])
+			}
+		}
+	}
+}
+
+func TestValuesOverlay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-overlay-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	base, overlay := filepath.Join(dir, "values.yaml"), filepath.Join(dir, "west.yaml")
+	if err := ioutil.WriteFile(base, []byte("image:\n  repo: nginx\n  tag: \"1.13\"\nreplicas: 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(overlay, []byte("image:\n  tag: \"1.14\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	inst := &installCmd{valuesFile: base, valuesOverlay: overlay, values: "replicas=3"}
+	vals, err := inst.vals()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := "image:\n  repo: nginx\n  tag: \"1.14\"\nreplicas: 3\n"
+	if string(vals) != expect {
+		t.Errorf("expected %q, got %q", expect, vals)
+	}
+}
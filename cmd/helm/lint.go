@@ -23,7 +23,6 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/spf13/cobra"
 
@@ -39,18 +38,22 @@ the chart is well-formed.
 If the linter encounters things that will cause the chart to fail installation,
 it will emit [ERROR] messages. If it encounters issues that break with convention
 or recommendation, it will emit [WARNING] messages.
+
+A PATH of '-' lints a packaged chart read from stdin.
 `
 
 type lintCmd struct {
 	strict bool
 	paths  []string
 	out    io.Writer
+	in     io.Reader
 }
 
 func newLintCmd(out io.Writer) *cobra.Command {
 	l := &lintCmd{
 		paths: []string{"."},
 		out:   out,
+		in:    os.Stdin,
 	}
 	cmd := &cobra.Command{
 		Use:   "lint [flags] PATH",
@@ -82,7 +85,7 @@ func (l *lintCmd) run() error {
 	var total int
 	var failures int
 	for _, path := range l.paths {
-		if linter, err := lintChart(path); err != nil {
+		if linter, err := l.lint(path); err != nil {
 			fmt.Println("==> Skipping", path)
 			fmt.Println(err)
 		} else {
@@ -114,36 +117,58 @@ func (l *lintCmd) run() error {
 	return nil
 }
 
+// lint lints the chart at path, or the chart archive read from stdin if path
+// is "-".
+func (l *lintCmd) lint(path string) (support.Linter, error) {
+	if path == "-" {
+		return lintArchive(l.in)
+	}
+	return lintChart(path)
+}
+
 func lintChart(path string) (support.Linter, error) {
-	var chartPath string
-	linter := support.Linter{}
+	if !chartutil.IsArchive(path) {
+		return lintDir(path)
+	}
 
-	if strings.HasSuffix(path, ".tgz") {
-		tempDir, err := ioutil.TempDir("", "helm-lint")
-		if err != nil {
-			return linter, err
-		}
-		defer os.RemoveAll(tempDir)
+	file, err := os.Open(path)
+	if err != nil {
+		return support.Linter{}, err
+	}
+	defer file.Close()
+	return lintArchive(file)
+}
 
-		file, err := os.Open(path)
-		if err != nil {
-			return linter, err
-		}
-		defer file.Close()
+// lintArchive expands the chart archive read from r into a temporary
+// directory, and lints the chart in it.
+func lintArchive(r io.Reader) (support.Linter, error) {
+	linter := support.Linter{}
 
-		if err = chartutil.Expand(tempDir, file); err != nil {
-			return linter, err
-		}
+	tempDir, err := ioutil.TempDir("", "helm-lint")
+	if err != nil {
+		return linter, err
+	}
+	defer os.RemoveAll(tempDir)
 
-		base := strings.Split(filepath.Base(path), "-")[0]
-		chartPath = filepath.Join(tempDir, base)
-	} else {
-		chartPath = path
+	if err = chartutil.Expand(tempDir, r); err != nil {
+		return linter, err
+	}
+
+	// The archive holds a single directory, named after the chart.
+	dirs, err := ioutil.ReadDir(tempDir)
+	if err != nil {
+		return linter, err
+	}
+	if len(dirs) != 1 || !dirs[0].IsDir() {
+		return linter, errLintNoChart
 	}
+	return lintDir(filepath.Join(tempDir, dirs[0].Name()))
+}
 
+func lintDir(chartPath string) (support.Linter, error) {
 	// Guard: Error out of this is not a chart.
 	if _, err := os.Stat(filepath.Join(chartPath, "Chart.yaml")); err != nil {
-		return linter, errLintNoChart
+		return support.Linter{}, errLintNoChart
 	}
 
 	return lint.All(chartPath), nil
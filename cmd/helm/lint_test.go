@@ -17,7 +17,11 @@ limitations under the License.
 package main
 
 import (
+	"bytes"
+	"io/ioutil"
 	"testing"
+
+	"k8s.io/helm/pkg/chartutil"
 )
 
 var (
@@ -35,3 +39,17 @@ func TestLintChart(t *testing.T) {
 	}
 
 }
+
+func TestLintStdin(t *testing.T) {
+	for _, format := range []chartutil.ArchiveFormat{chartutil.FormatTgz, chartutil.FormatTzst} {
+		l := &lintCmd{in: bytes.NewReader(packChart(t, chartDirPath, format)), out: ioutil.Discard}
+		if _, err := l.lint("-"); err != nil {
+			t.Errorf("%s: %s", format, err)
+		}
+	}
+
+	l := &lintCmd{in: bytes.NewReader(nil), out: ioutil.Discard}
+	if _, err := l.lint("-"); err == nil {
+		t.Error("expected an empty stdin to fail")
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"k8s.io/helm/pkg/helm"
 	"k8s.io/helm/pkg/proto/hapi/release"
 	"k8s.io/helm/pkg/proto/hapi/services"
+	"k8s.io/helm/pkg/releaseutil"
 	"k8s.io/helm/pkg/timeconv"
 )
 
@@ -55,6 +56,11 @@ By default, up to 256 items may be returned. To limit this, use the '--max' flag
 Setting '--max' to 0 will not return all results. Rather, it will return the
 server's default, which may be much higher than 256. Pairing the '--max'
 flag with the '--offset' flag allows you to page through results.
+
+To find the release that a resource belongs to, use '--for-resource' with the
+kind and name of the resource. Only the releases that are fetched are searched.
+
+	$ helm list --for-resource Deployment/web
 `
 
 type listCmd struct {
@@ -70,9 +76,26 @@ type listCmd struct {
 	deployed   bool
 	failed     bool
 	superseded bool
+	output     string
+	resource   string
 	client     helm.Interface
 }
 
+// listResult is the machine readable output of 'helm list'.
+type listResult struct {
+	Next     string        `json:"next,omitempty"`
+	Releases []listRelease `json:"releases"`
+}
+
+type listRelease struct {
+	Name      string `json:"name"`
+	Revision  int32  `json:"revision"`
+	Updated   string `json:"updated"`
+	Status    string `json:"status"`
+	Chart     string `json:"chart"`
+	Namespace string `json:"namespace"`
+}
+
 func newListCmd(client helm.Interface, out io.Writer) *cobra.Command {
 	list := &listCmd{
 		out:    out,
@@ -90,7 +113,7 @@ func newListCmd(client helm.Interface, out io.Writer) *cobra.Command {
 				list.filter = strings.Join(args, " ")
 			}
 			if list.client == nil {
-				list.client = helm.NewClient(helm.Host(tillerHost))
+				list.client = helm.NewClient(helm.Host(tillerHost), helm.UserToken(userToken), helm.Impersonate(asUser, asGroups), helm.RequestID(requestID))
 			}
 			return list.run()
 		},
@@ -106,6 +129,9 @@ func newListCmd(client helm.Interface, out io.Writer) *cobra.Command {
 	f.BoolVar(&list.deleted, "deleted", false, "show deleted releases")
 	f.BoolVar(&list.deployed, "deployed", false, "show deployed releases. If no other is specified, this will be automatically enabled")
 	f.BoolVar(&list.failed, "failed", false, "show failed releases")
+	f.StringVar(&list.resource, "for-resource", "", "only show the release that owns the resource KIND/NAME, e.g. Service/web")
+	// -o is taken by --offset.
+	addOutputFlag(f, &list.output, "", "table")
 	// TODO: Do we want this as a feature of 'helm list'?
 	//f.BoolVar(&list.superseded, "history", true, "show historical releases")
 
@@ -113,6 +139,19 @@ func newListCmd(client helm.Interface, out io.Writer) *cobra.Command {
 }
 
 func (l *listCmd) run() error {
+	format, err := parseOutputFormat(l.output, "table")
+	if err != nil {
+		return err
+	}
+	var owns releaseutil.FilterFunc
+	if l.resource != "" {
+		parts := strings.SplitN(l.resource, "/", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("invalid resource %q, expected KIND/NAME", l.resource)
+		}
+		owns = releaseutil.ResourceFilter(parts[0], parts[1])
+	}
+
 	sortBy := services.ListSort_NAME
 	if l.byDate {
 		sortBy = services.ListSort_LAST_RELEASED
@@ -137,6 +176,13 @@ func (l *listCmd) run() error {
 	if err != nil {
 		return prettyError(err)
 	}
+	if owns != nil {
+		res.Releases = owns.Filter(res.Releases)
+	}
+
+	if !format.human() {
+		return format.write(l.out, listOutput(res.Next, res.Releases))
+	}
 
 	if len(res.Releases) == 0 {
 		return nil
@@ -192,6 +238,21 @@ func (l *listCmd) statusCodes() []release.Status_Code {
 	return status
 }
 
+func listOutput(next string, rels []*release.Release) listResult {
+	res := listResult{Next: next, Releases: []listRelease{}}
+	for _, r := range rels {
+		res.Releases = append(res.Releases, listRelease{
+			Name:      r.Name,
+			Revision:  r.Version,
+			Updated:   outputTime(r.Info.LastDeployed),
+			Status:    r.Info.Status.Code.String(),
+			Chart:     formatChartname(r.Chart),
+			Namespace: r.Namespace,
+		})
+	}
+	return res
+}
+
 func formatList(rels []*release.Release) string {
 	table := uitable.New()
 	table.MaxColWidth = 60
@@ -77,6 +77,24 @@ func TestListCmd(t *testing.T) {
 			// See note on previous test.
 			expected: "thomas-guide\natlas-guide",
 		},
+		{
+			name: "list as JSON",
+			args: []string{"--output", "json"},
+			resp: []*release.Release{
+				releaseMock(&releaseOptions{name: "atlas"}),
+			},
+			expected: `"releases": \[\s+\{\s+"name": "atlas",\s+"revision": 1,\s+"updated": "[0-9TZ:-]+",\s+"status": "DEPLOYED",\s+"chart": "foo-0.1.0-beta.1",`,
+		},
+		{
+			name:     "list without releases as YAML",
+			args:     []string{"--output", "yaml"},
+			expected: `^releases: \[\]\n$`,
+		},
+		{
+			name: "list with an unknown output format",
+			args: []string{"--output", "xml"},
+			err:  true,
+		},
 	}
 
 	var buf bytes.Buffer
@@ -97,3 +115,37 @@ func TestListCmd(t *testing.T) {
 		buf.Reset()
 	}
 }
+
+func TestListForResource(t *testing.T) {
+	web := releaseMock(&releaseOptions{name: "web"})
+	web.Manifest = "kind: Service\nmetadata:\n  name: web\n---\n" + mockManifest
+	web.Hooks = nil
+	rels := []*release.Release{releaseMock(&releaseOptions{name: "atlas"}), web}
+
+	tests := []struct {
+		resource string
+		expected string
+		err      bool
+	}{
+		{resource: "Service/web", expected: "web\n"},
+		{resource: "secret/fixture", expected: "atlas\nweb\n"},
+		{resource: "Job/pre-install-hook", expected: "atlas\n"},
+		{resource: "Service/cache", expected: ""},
+		{resource: "web", err: true},
+	}
+
+	var buf bytes.Buffer
+	for _, tt := range tests {
+		buf.Reset()
+		cmd := newListCmd(&fakeReleaseClient{rels: rels}, &buf)
+		args := []string{"-q", "--for-resource", tt.resource}
+		cmd.ParseFlags(args)
+		err := cmd.RunE(cmd, nil)
+		if (err != nil) != tt.err {
+			t.Errorf("%s: expected error: %v, got %v", tt.resource, tt.err, err)
+		}
+		if buf.String() != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.resource, tt.expected, buf.String())
+		}
+	}
+}
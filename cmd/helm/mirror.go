@@ -0,0 +1,450 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/spf13/cobra"
+
+	"k8s.io/helm/cmd/helm/downloader"
+	"k8s.io/helm/cmd/helm/helmpath"
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/provenance"
+	"k8s.io/helm/pkg/repo"
+)
+
+const mirrorDesc = `
+This command copies the charts of a chart repository into a local directory
+and generates an index for them, so that the directory can be served as a
+repository of its own, for example inside a network that cannot reach the
+original one.
+
+SOURCE is the URL of a repository, or the name of a repository added with
+'helm repo add'. Provenance files are copied along with the charts that have
+them, so that mirrored charts can still be verified. With '--verify', every
+chart must have one that is signed by a key in '--keyring', or the mirror
+fails. A repository given by name is also verified as its verification policy
+says ('helm repo add --verify-charts').
+
+'--chart' limits the mirror to the charts whose names match one of the given
+globs, and '--version' to the versions within a SemVer range. Pre-release
+versions are only mirrored with '--devel', and '--latest' mirrors only the
+newest selected version of each chart.
+
+Running the command again downloads only the charts that are missing from DIR,
+or whose digest differs from the one in the source index. What happens to the
+charts already in DIR is set by '--delete':
+
+    none        nothing is deleted (the default)
+    removed     charts that are no longer in the source repository are deleted
+    unselected  every chart that this run did not select is deleted, so that
+                DIR holds exactly the selected charts
+
+'--dry-run' shows what would be downloaded and deleted without changing DIR.
+
+The index is regenerated from the charts in DIR, as 'helm repo index' does, and
+'--url' sets the URL that DIR will be served at. To mirror into another
+repository, upload DIR to wherever that repository is served from.
+`
+
+// Deletion policies of 'helm mirror'.
+const (
+	mirrorDeleteNone       = "none"
+	mirrorDeleteRemoved    = "removed"
+	mirrorDeleteUnselected = "unselected"
+)
+
+type mirrorCmd struct {
+	source       string
+	dir          string
+	charts       []string
+	version      string
+	devel        bool
+	latest       bool
+	deletePolicy string
+	url          string
+	verify       bool
+	keyring      string
+	dryRun       bool
+
+	// sourceRepo is the source repository, if it was given by name.
+	sourceRepo *repo.Entry
+
+	out  io.Writer
+	home helmpath.Home
+}
+
+func newMirrorCmd(out io.Writer) *cobra.Command {
+	m := &mirrorCmd{out: out}
+
+	cmd := &cobra.Command{
+		Use:   "mirror [flags] SOURCE DIR",
+		Short: "copy the charts of a repository into a local directory",
+		Long:  mirrorDesc,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkArgsLength(len(args), "source repository", "destination directory"); err != nil {
+				return err
+			}
+			m.source, m.dir = args[0], args[1]
+			m.home = helmpath.Home(homePath())
+			return m.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringSliceVar(&m.charts, "chart", []string{}, "only mirror charts whose names match these globs, can be repeated")
+	f.StringVar(&m.version, "version", "", "only mirror chart versions within this SemVer range")
+	f.BoolVar(&m.devel, "devel", false, "also mirror pre-release versions")
+	f.BoolVar(&m.latest, "latest", false, "only mirror the newest selected version of each chart")
+	f.StringVar(&m.deletePolicy, "delete", mirrorDeleteNone, "which charts already in DIR to delete: none, removed or unselected")
+	f.StringVar(&m.url, "url", "", "url that DIR is served at, used in the generated index")
+	f.BoolVar(&m.verify, "verify", false, "refuse charts that are not signed by a key in the keyring")
+	f.StringVar(&m.keyring, "keyring", defaultKeyring(), "keyring containing public keys")
+	f.BoolVar(&m.dryRun, "dry-run", false, "show what would be downloaded and deleted, without doing it")
+
+	return cmd
+}
+
+func (m *mirrorCmd) run() error {
+	switch m.deletePolicy {
+	case mirrorDeleteNone, mirrorDeleteRemoved, mirrorDeleteUnselected:
+	default:
+		return fmt.Errorf("invalid --delete %q: must be one of none, removed or unselected", m.deletePolicy)
+	}
+	for _, p := range m.charts {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("invalid --chart pattern %q: %s", p, err)
+		}
+	}
+	var constraint *semver.Constraints
+	if m.version != "" {
+		c, err := semver.NewConstraint(m.version)
+		if err != nil {
+			return fmt.Errorf("invalid --version %q: %s", m.version, err)
+		}
+		constraint = c
+	}
+
+	dir, err := filepath.Abs(m.dir)
+	if err != nil {
+		return err
+	}
+	sourceURL, source, err := m.sourceIndex()
+	if err != nil {
+		return err
+	}
+	selected := m.selectVersions(source, constraint)
+
+	// The charts already in the mirror, indexed by file name.
+	local, err := repo.IndexDirectory(dir, "")
+	if err != nil {
+		return err
+	}
+
+	staging := ""
+	if !m.dryRun {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		// Charts are downloaded next to the mirror and moved into it once
+		// complete, so that an interrupted run leaves no partial archives.
+		if staging, err = ioutil.TempDir(dir, ".mirror-"); err != nil {
+			return err
+		}
+		defer os.RemoveAll(staging)
+	}
+
+	downloaded, current := 0, 0
+	keep := map[string]bool{}
+	for _, cv := range selected {
+		keep[cv.Name+" "+cv.Version] = true
+
+		u, err := mirrorChartURL(sourceURL, cv)
+		if err != nil {
+			return err
+		}
+		name := path.Base(u.Path)
+		if !chartutil.IsArchive(name) {
+			return fmt.Errorf("%s-%s: %s is not a chart archive", cv.Name, cv.Version, u)
+		}
+		dest := filepath.Join(dir, name)
+		if upToDate(dest, cv.Digest) {
+			current++
+			continue
+		}
+		downloaded++
+		if m.dryRun {
+			fmt.Fprintf(m.out, "Would download %s\n", name)
+			continue
+		}
+		if err := m.fetch(u, filepath.Join(staging, name)); err != nil {
+			return fmt.Errorf("%s-%s: %s", cv.Name, cv.Version, err)
+		}
+		if err := moveChart(filepath.Join(staging, name), dest); err != nil {
+			return err
+		}
+		fmt.Fprintf(m.out, "Downloaded %s\n", name)
+	}
+
+	deleted, err := m.prune(dir, local, source, keep)
+	if err != nil {
+		return err
+	}
+
+	if !m.dryRun {
+		if err := index(dir, m.url, "", 0); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(m.out, "Mirrored %d chart versions from %s: %d downloaded, %d up to date, %d deleted\n",
+		len(selected), m.source, downloaded, current, deleted)
+	return nil
+}
+
+// sourceIndex downloads the index of the source repository, and returns it
+// along with the URL its chart URLs are relative to.
+func (m *mirrorCmd) sourceIndex() (string, *repo.IndexFile, error) {
+	e := &repo.Entry{Name: m.source, URL: m.source}
+	if !strings.Contains(m.source, "://") {
+		rf, err := repo.LoadRepositoriesFile(m.home.RepositoryFile())
+		if err != nil {
+			return "", nil, err
+		}
+		e = nil
+		for _, r := range rf.Repositories {
+			if r.Name == m.source {
+				e = r
+				break
+			}
+		}
+		if e == nil {
+			return "", nil, fmt.Errorf("no repository named %q. Pass the URL of the repository, or add it with 'helm repo add'", m.source)
+		}
+		m.sourceRepo = e
+	}
+
+	tmp, err := ioutil.TempDir("", "helm-mirror-")
+	if err != nil {
+		return "", nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	f := filepath.Join(tmp, "index.yaml")
+	if err := e.DownloadIndex(f); err != nil {
+		return "", nil, fmt.Errorf("could not download the index of %s: %s", m.source, err)
+	}
+	i, err := repo.LoadIndexFile(f)
+	return e.URL, i, err
+}
+
+// selectVersions returns the chart versions of index that the filters select,
+// by chart name and then newest first.
+func (m *mirrorCmd) selectVersions(index *repo.IndexFile, constraint *semver.Constraints) []*repo.ChartVersion {
+	index.SortEntries()
+	names := make([]string, 0, len(index.Entries))
+	for n := range index.Entries {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	selected := []*repo.ChartVersion{}
+	for _, n := range names {
+		if len(m.charts) > 0 && !matchesChart(m.charts, n) {
+			continue
+		}
+		for _, cv := range index.Entries[n] {
+			if cv.Removed {
+				continue
+			}
+			v, err := semver.NewVersion(cv.Version)
+			if err != nil {
+				continue
+			}
+			if v.Prerelease() != "" && !m.devel {
+				continue
+			}
+			if constraint != nil && !constraint.Check(v) {
+				continue
+			}
+			selected = append(selected, cv)
+			if m.latest {
+				break
+			}
+		}
+	}
+	return selected
+}
+
+func matchesChart(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// mirrorChartURL returns the URL to download cv from, resolving relative URLs
+// against the URL of the repository.
+func mirrorChartURL(repoURL string, cv *repo.ChartVersion) (*url.URL, error) {
+	if len(cv.URLs) == 0 {
+		return nil, fmt.Errorf("%s-%s has no downloadable URLs", cv.Name, cv.Version)
+	}
+	base, err := url.Parse(strings.TrimSuffix(repoURL, "/") + "/")
+	if err != nil {
+		return nil, err
+	}
+	ref, err := url.Parse(cv.URLs[0])
+	if err != nil {
+		return nil, fmt.Errorf("%s-%s: invalid URL %q: %s", cv.Name, cv.Version, cv.URLs[0], err)
+	}
+	return base.ResolveReference(ref), nil
+}
+
+// upToDate reports whether the chart archive at path matches digest. Without
+// a digest to compare with, any archive that is there is taken to match.
+func upToDate(path, digest string) bool {
+	if _, err := os.Stat(path); err != nil {
+		return false
+	}
+	if digest == "" {
+		return true
+	}
+	d, err := provenance.DigestFile(path)
+	return err == nil && d == strings.TrimPrefix(digest, "sha256:")
+}
+
+// fetch downloads the chart at u to dest, along with its provenance file if
+// there is one, and verifies it if --verify, or the verification policy of the
+// source repository, says to.
+func (m *mirrorCmd) fetch(u *url.URL, dest string) error {
+	required, check, keyring := m.verify, m.verify, m.keyring
+	if m.sourceRepo != nil {
+		required = required || m.sourceRepo.Verify == repo.VerifyAlways
+		check = required || m.sourceRepo.Verify == repo.VerifyIfPresent
+		if m.sourceRepo.Keyring != "" {
+			keyring = m.sourceRepo.Keyring
+		}
+	}
+
+	if err := downloadFile(u.String(), dest); err != nil {
+		return err
+	}
+	err := downloadFile(u.String()+".prov", dest+".prov")
+	if err == errNotFound {
+		if required {
+			return errors.New("no provenance file to verify the chart with")
+		}
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if check {
+		if _, err := downloader.VerifyChart(dest, keyring); err != nil {
+			return fmt.Errorf("failed verification: %s", err)
+		}
+	}
+	return nil
+}
+
+var errNotFound = errors.New("not found")
+
+// downloadFile saves the content at href to dest, returning errNotFound if
+// there is none.
+func downloadFile(href, dest string) error {
+	resp, err := repo.Get(href)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch %s: %s", href, resp.Status)
+	}
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// moveChart moves a chart archive, and its provenance file if there is one,
+// from src to dest. A stale provenance file at dest is removed.
+func moveChart(src, dest string) error {
+	if err := os.Rename(src+".prov", dest+".prov"); os.IsNotExist(err) {
+		if err := os.Remove(dest + ".prov"); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+	return os.Rename(src, dest)
+}
+
+// prune deletes the charts in local, the charts already in the mirror at dir,
+// that the deletion policy says to, and returns how many there were.
+func (m *mirrorCmd) prune(dir string, local, source *repo.IndexFile, keep map[string]bool) (int, error) {
+	if m.deletePolicy == mirrorDeleteNone {
+		return 0, nil
+	}
+	files := []string{}
+	for _, cvs := range local.Entries {
+		for _, cv := range cvs {
+			switch {
+			case m.deletePolicy == mirrorDeleteUnselected && !keep[cv.Name+" "+cv.Version]:
+			case m.deletePolicy == mirrorDeleteRemoved && !source.Has(cv.Name, cv.Version):
+			default:
+				continue
+			}
+			files = append(files, cv.URLs[0])
+		}
+	}
+	sort.Strings(files)
+
+	for _, name := range files {
+		if m.dryRun {
+			fmt.Fprintf(m.out, "Would delete %s\n", name)
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			return 0, err
+		}
+		if err := os.Remove(filepath.Join(dir, name+".prov")); err != nil && !os.IsNotExist(err) {
+			return 0, err
+		}
+		fmt.Fprintf(m.out, "Deleted %s\n", name)
+	}
+	return len(files), nil
+}
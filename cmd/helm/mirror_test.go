@@ -0,0 +1,238 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"k8s.io/helm/pkg/repo"
+	"k8s.io/helm/pkg/repo/repotest"
+)
+
+func runMirror(source, dir string, flags ...string) (string, error) {
+	buf := bytes.NewBuffer(nil)
+	cmd := newMirrorCmd(buf)
+	if err := cmd.ParseFlags(flags); err != nil {
+		return "", err
+	}
+	err := cmd.RunE(cmd, []string{source, dir})
+	return buf.String(), err
+}
+
+// mirrored lists the files in dir, leaving out the index.
+func mirrored(t *testing.T, dir string) []string {
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, fi := range fis {
+		if fi.Name() != "index.yaml" {
+			names = append(names, fi.Name())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func mirrorServer(t *testing.T) (*repotest.Server, string) {
+	hh, err := tempHelmHome(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := repotest.NewServer(hh)
+	if _, err := srv.CopyCharts("testdata/testcharts/*.tgz*"); err != nil {
+		t.Fatal(err)
+	}
+	return srv, hh
+}
+
+func TestMirrorCmd(t *testing.T) {
+	srv, hh := mirrorServer(t)
+	old := homePath()
+	helmHome = hh
+	defer func() {
+		helmHome = old
+		srv.Stop()
+		os.RemoveAll(hh)
+	}()
+	dir := filepath.Join(hh, "mirror")
+
+	out, err := runMirror("test", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "Mirrored 5 chart versions from test: 5 downloaded, 0 up to date, 0 deleted") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	expect := []string{
+		"compressedchart-0.1.0.tgz",
+		"compressedchart-0.2.0.tgz",
+		"compressedchart-0.3.0.tgz",
+		"reqtest-0.1.0.tgz",
+		"signtest-0.1.0.tgz",
+		"signtest-0.1.0.tgz.prov",
+	}
+	if got := mirrored(t, dir); !reflect.DeepEqual(got, expect) {
+		t.Errorf("expected %v, got %v", expect, got)
+	}
+	i, err := repo.LoadIndexFile(filepath.Join(dir, "index.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(i.Entries) != 3 || len(i.Entries["compressedchart"]) != 3 {
+		t.Errorf("unexpected index entries: %v", i.Entries)
+	}
+
+	// Only missing or changed charts are downloaded again.
+	if err := ioutil.WriteFile(filepath.Join(dir, "reqtest-0.1.0.tgz"), []byte("corrupt"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out, err = runMirror(srv.URL(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "Downloaded reqtest-0.1.0.tgz") || !strings.Contains(out, "1 downloaded, 4 up to date") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
+
+func TestMirrorCmdFilters(t *testing.T) {
+	srv, hh := mirrorServer(t)
+	defer func() {
+		srv.Stop()
+		os.RemoveAll(hh)
+	}()
+
+	tests := []struct {
+		name   string
+		flags  []string
+		expect []string
+	}{
+		{
+			name:   "chart",
+			flags:  []string{"--chart", "sign*,reqtest"},
+			expect: []string{"reqtest-0.1.0.tgz", "signtest-0.1.0.tgz", "signtest-0.1.0.tgz.prov"},
+		},
+		{
+			name:   "version",
+			flags:  []string{"--chart", "compressedchart", "--version", "<0.3.0"},
+			expect: []string{"compressedchart-0.1.0.tgz", "compressedchart-0.2.0.tgz"},
+		},
+		{
+			name:   "latest",
+			flags:  []string{"--chart", "compressedchart", "--latest"},
+			expect: []string{"compressedchart-0.3.0.tgz"},
+		},
+		{
+			name:   "verify",
+			flags:  []string{"--chart", "signtest", "--verify", "--keyring", "testdata/helm-test-key.pub"},
+			expect: []string{"signtest-0.1.0.tgz", "signtest-0.1.0.tgz.prov"},
+		},
+	}
+	for _, tt := range tests {
+		dir := filepath.Join(hh, tt.name)
+		if _, err := runMirror(srv.URL(), dir, tt.flags...); err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+			continue
+		}
+		if got := mirrored(t, dir); !reflect.DeepEqual(got, tt.expect) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.expect, got)
+		}
+	}
+
+	dir := filepath.Join(hh, "unsigned")
+	_, err := runMirror(srv.URL(), dir, "--chart", "reqtest", "--verify", "--keyring", "testdata/helm-test-key.pub")
+	if err == nil || !strings.Contains(err.Error(), "no provenance file") {
+		t.Errorf("expected an unsigned chart to be refused, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "reqtest-0.1.0.tgz")); !os.IsNotExist(err) {
+		t.Error("expected the refused chart not to be mirrored")
+	}
+
+	dir = filepath.Join(hh, "dry-run")
+	out, err := runMirror(srv.URL(), dir, "--dry-run")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "Would download signtest-0.1.0.tgz") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Error("expected a dry run not to create the mirror")
+	}
+}
+
+func TestMirrorCmdDelete(t *testing.T) {
+	srv, hh := mirrorServer(t)
+	defer func() {
+		srv.Stop()
+		os.RemoveAll(hh)
+	}()
+	dir := filepath.Join(hh, "mirror")
+
+	if _, err := runMirror(srv.URL(), dir); err != nil {
+		t.Fatal(err)
+	}
+
+	// Charts removed from the source are kept, unless asked otherwise.
+	if err := os.Remove(filepath.Join(srv.Root(), "reqtest-0.1.0.tgz")); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.CreateIndex(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runMirror(srv.URL(), dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "reqtest-0.1.0.tgz")); err != nil {
+		t.Errorf("expected the removed chart to be kept: %s", err)
+	}
+	out, err := runMirror(srv.URL(), dir, "--delete", "removed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "Deleted reqtest-0.1.0.tgz") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+
+	if _, err := runMirror(srv.URL(), dir, "--chart", "signtest", "--delete", "unselected"); err != nil {
+		t.Fatal(err)
+	}
+	expect := []string{"signtest-0.1.0.tgz", "signtest-0.1.0.tgz.prov"}
+	if got := mirrored(t, dir); !reflect.DeepEqual(got, expect) {
+		t.Errorf("expected %v, got %v", expect, got)
+	}
+	i, err := repo.LoadIndexFile(filepath.Join(dir, "index.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(i.Entries) != 1 || !i.Has("signtest", "0.1.0") {
+		t.Errorf("expected only signtest in the index, got %v", i.Entries)
+	}
+
+	if _, err := runMirror(srv.URL(), dir, "--delete", "everything"); err == nil {
+		t.Error("expected an invalid --delete policy to fail")
+	}
+}
@@ -0,0 +1,256 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/Masterminds/semver"
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"k8s.io/helm/cmd/helm/helmpath"
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/release"
+	"k8s.io/helm/pkg/proto/hapi/services"
+	"k8s.io/helm/pkg/repo"
+)
+
+const outdatedDesc = `
+This command lists the deployed releases whose chart has a newer version in
+one of the configured repositories.
+
+Repositories are read from the local cache, so run 'helm repo update' first
+to compare against their latest contents. Pre-release versions are skipped,
+unless '--devel' is set. To limit which versions count as an update, pass a
+SemVer range with '--constraint', for example '~1.2' to only consider patch
+releases of 1.2.x.
+
+Releases of deprecated charts are always listed, with the chart that replaces
+them if the chart names one.
+`
+
+type outdatedCmd struct {
+	out        io.Writer
+	client     helm.Interface
+	home       helmpath.Home
+	constraint string
+	devel      bool
+	output     string
+}
+
+// outdatedRelease describes a release with a newer chart available.
+type outdatedRelease struct {
+	Release    string `json:"release"`
+	Chart      string `json:"chart"`
+	Current    string `json:"current"`
+	Latest     string `json:"latest"`
+	Repo       string `json:"repo"`
+	Deprecated bool   `json:"deprecated,omitempty"`
+	ReplacedBy string `json:"replacedBy,omitempty"`
+}
+
+func newOutdatedCmd(client helm.Interface, out io.Writer) *cobra.Command {
+	o := &outdatedCmd{
+		out:    out,
+		client: client,
+	}
+
+	cmd := &cobra.Command{
+		Use:               "outdated [flags]",
+		Short:             "list releases with newer chart versions available",
+		Long:              outdatedDesc,
+		PersistentPreRunE: setupConnection,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			o.home = helmpath.Home(homePath())
+			o.client = ensureHelmClient(o.client)
+			return o.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&o.constraint, "constraint", "", "only consider chart versions within this SemVer range")
+	f.BoolVar(&o.devel, "devel", false, "also consider pre-release versions")
+	addOutputFlag(f, &o.output, "o", "table")
+
+	return cmd
+}
+
+func (o *outdatedCmd) run() error {
+	format, err := parseOutputFormat(o.output, "table")
+	if err != nil {
+		return err
+	}
+
+	var constraint *semver.Constraints
+	if o.constraint != "" {
+		c, err := semver.NewConstraint(o.constraint)
+		if err != nil {
+			return fmt.Errorf("invalid --constraint %q: %s", o.constraint, err)
+		}
+		constraint = c
+	}
+
+	rels, err := o.deployedReleases()
+	if err != nil {
+		return prettyError(err)
+	}
+
+	// Warnings go to stderr, to keep them out of machine readable output.
+	indexes, err := o.loadIndexes(os.Stderr)
+	if err != nil {
+		return err
+	}
+
+	found := []outdatedRelease{}
+	for _, r := range rels {
+		if r.Chart == nil || r.Chart.Metadata == nil {
+			continue
+		}
+		if or, ok := o.check(r, indexes, constraint); ok {
+			found = append(found, or)
+		}
+	}
+
+	if !format.human() {
+		return format.write(o.out, found)
+	}
+
+	if len(found) == 0 {
+		fmt.Fprintln(o.out, "All releases are up to date")
+		return nil
+	}
+	table := uitable.New()
+	table.MaxColWidth = 60
+	table.AddRow("RELEASE", "CHART", "CURRENT", "LATEST", "REPO", "NOTES")
+	for _, f := range found {
+		notes := ""
+		if f.Deprecated {
+			notes = "deprecated"
+			if f.ReplacedBy != "" {
+				notes += ", use " + f.ReplacedBy
+			}
+		}
+		table.AddRow(f.Release, f.Chart, f.Current, f.Latest, f.Repo, notes)
+	}
+	fmt.Fprintln(o.out, table)
+	return nil
+}
+
+// check returns the newest version of the release's chart in indexes, if it
+// is newer than the deployed one or the chart is deprecated.
+func (o *outdatedCmd) check(r *release.Release, indexes map[string]*repo.IndexFile, constraint *semver.Constraints) (outdatedRelease, bool) {
+	md := r.Chart.Metadata
+	current, err := semver.NewVersion(md.Version)
+	if err != nil {
+		return outdatedRelease{}, false
+	}
+
+	res := outdatedRelease{Release: r.Name, Chart: md.Name, Current: md.Version}
+	var latest *semver.Version
+
+	// Visit repositories in a stable order, so that ties are reported the
+	// same way every time.
+	names := make([]string, 0, len(indexes))
+	for n := range indexes {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	for _, n := range names {
+		for _, cv := range indexes[n].Entries[md.Name] {
+			v, err := semver.NewVersion(cv.Version)
+			if err != nil {
+				continue
+			}
+			if v.Prerelease() != "" && !o.devel {
+				continue
+			}
+			if v.Equal(current) && cv.Deprecated {
+				res.Deprecated, res.ReplacedBy = true, cv.ReplacedBy
+			}
+			if constraint != nil && !constraint.Check(v) {
+				continue
+			}
+			if latest == nil || v.GreaterThan(latest) {
+				latest = v
+				res.Latest, res.Repo = cv.Version, n
+				if cv.Deprecated {
+					res.Deprecated, res.ReplacedBy = true, cv.ReplacedBy
+				}
+			}
+		}
+	}
+
+	if md.Deprecated {
+		res.Deprecated, res.ReplacedBy = true, md.ReplacedBy
+	}
+	if latest == nil {
+		return res, res.Deprecated
+	}
+	return res, latest.GreaterThan(current) || res.Deprecated
+}
+
+// deployedReleases lists all deployed releases, page by page.
+func (o *outdatedCmd) deployedReleases() ([]*release.Release, error) {
+	seen := map[string]bool{}
+	rels := []*release.Release{}
+	offset := ""
+	for {
+		res, err := o.client.ListReleases(
+			helm.ReleaseListStatuses([]release.Status_Code{release.Status_DEPLOYED}),
+			helm.ReleaseListSort(int32(services.ListSort_NAME)),
+			helm.ReleaseListOffset(offset),
+		)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range res.Releases {
+			if !seen[r.Name] {
+				seen[r.Name] = true
+				rels = append(rels, r)
+			}
+		}
+		// A page starts with the release it was asked to start at.
+		if res.Next == "" || res.Next == offset {
+			return rels, nil
+		}
+		offset = res.Next
+	}
+}
+
+// loadIndexes loads the cached index of every configured repository, and
+// warns on warn about those that cannot be loaded.
+func (o *outdatedCmd) loadIndexes(warn io.Writer) (map[string]*repo.IndexFile, error) {
+	rf, err := repo.LoadRepositoriesFile(o.home.RepositoryFile())
+	if err != nil {
+		return nil, err
+	}
+	indexes := map[string]*repo.IndexFile{}
+	for _, re := range rf.Repositories {
+		ind, err := repo.LoadCachedIndexFile(o.home.CacheIndex(re.Name))
+		if err != nil {
+			fmt.Fprintf(warn, "WARNING: Repo %q is corrupt, missing or expired. Try 'helm repo update'.\n", re.Name)
+			continue
+		}
+		indexes[re.Name] = ind
+	}
+	return indexes, nil
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"testing"
+
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/proto/hapi/release"
+	rls "k8s.io/helm/pkg/proto/hapi/services"
+)
+
+func TestOutdatedCmd(t *testing.T) {
+	rel := func(name, chartName, version string) *release.Release {
+		return releaseMock(&releaseOptions{
+			name:  name,
+			chart: &chart.Chart{Metadata: &chart.Metadata{Name: chartName, Version: version}},
+		})
+	}
+	rels := []*release.Release{
+		rel("aeneas", "alpine", "0.1.0"),
+		rel("juno", "mariadb", "0.3.0"),
+		rel("virgil", "legacydb", "0.1.0"),
+		rel("dido", "unknown", "1.0.0"),
+	}
+
+	tests := []struct {
+		name   string
+		flags  []string
+		expect string
+		reject string
+		err    bool
+	}{
+		{
+			name:   "outdated releases",
+			expect: `aeneas \talpine  \t0.1.0  \t0.2.0 \ttesting\t\s*\n.*virgil \tlegacydb\t0.1.0  \t0.1.0 \ttesting\tdeprecated, use testing/mariadb`,
+			reject: "juno|dido",
+		},
+		{
+			name:   "outdated releases within a constraint",
+			flags:  []string{"--constraint", "~0.1.0"},
+			expect: "virgil",
+			reject: "aeneas",
+		},
+		{
+			name:   "outdated releases as JSON",
+			flags:  []string{"--output", "json"},
+			expect: `"release": "aeneas",\s+"chart": "alpine",\s+"current": "0.1.0",\s+"latest": "0.2.0",\s+"repo": "testing"`,
+		},
+		{
+			name:  "outdated with an invalid constraint",
+			flags: []string{"--constraint", "not a range"},
+			err:   true,
+		},
+	}
+
+	oldhome := helmHome
+	helmHome = "testdata/helmhome"
+	defer func() { helmHome = oldhome }()
+
+	for _, tt := range tests {
+		buf := bytes.NewBuffer(nil)
+		cmd := newOutdatedCmd(&fakeReleaseClient{rels: rels}, buf)
+		cmd.ParseFlags(tt.flags)
+		err := cmd.RunE(cmd, []string{})
+		if (err != nil) != tt.err {
+			t.Errorf("%s: unexpected error state: %v", tt.name, err)
+			continue
+		}
+		if tt.expect != "" && !regexp.MustCompile(tt.expect).Match(buf.Bytes()) {
+			t.Errorf("%s: expected %q, got\n%s", tt.name, tt.expect, buf.String())
+		}
+		if tt.reject != "" && regexp.MustCompile(tt.reject).Match(buf.Bytes()) {
+			t.Errorf("%s: did not expect %q, got\n%s", tt.name, tt.reject, buf.String())
+		}
+	}
+}
+
+// pagedReleaseClient returns its releases two at a time.
+type pagedReleaseClient struct {
+	fakeReleaseClient
+	calls int
+}
+
+func (c *pagedReleaseClient) ListReleases(opts ...helm.ReleaseListOption) (*rls.ListReleasesResponse, error) {
+	start := c.calls * 2
+	c.calls++
+	res := &rls.ListReleasesResponse{Count: int64(len(c.rels))}
+	if start >= len(c.rels) {
+		return res, nil
+	}
+	end := start + 2
+	if end < len(c.rels) {
+		res.Next = c.rels[end].Name
+	} else {
+		end = len(c.rels)
+	}
+	res.Releases = c.rels[start:end]
+	return res, nil
+}
+
+func TestOutdatedCmdPages(t *testing.T) {
+	rels := []*release.Release{}
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		rels = append(rels, releaseMock(&releaseOptions{
+			name:  name,
+			chart: &chart.Chart{Metadata: &chart.Metadata{Name: "alpine", Version: "0.1.0"}},
+		}))
+	}
+
+	oldhome := helmHome
+	helmHome = "testdata/helmhome"
+	defer func() { helmHome = oldhome }()
+
+	buf := bytes.NewBuffer(nil)
+	client := &pagedReleaseClient{fakeReleaseClient: fakeReleaseClient{rels: rels}}
+	cmd := newOutdatedCmd(client, buf)
+	cmd.ParseFlags([]string{"--output", "json"})
+	if err := cmd.RunE(cmd, []string{}); err != nil {
+		t.Fatal(err)
+	}
+	if client.calls != 3 {
+		t.Errorf("expected 3 pages to be listed, got %d", client.calls)
+	}
+	var found []outdatedRelease
+	if err := json.Unmarshal(buf.Bytes(), &found); err != nil {
+		t.Fatalf("expected only JSON in the output, got %s: %s", buf.String(), err)
+	}
+	if len(found) != len(rels) {
+		t.Errorf("expected %d outdated releases, got %d", len(rels), len(found))
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Masterminds/sprig"
+	"github.com/ghodss/yaml"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/spf13/pflag"
+
+	"k8s.io/helm/pkg/timeconv"
+)
+
+// Machine readable output formats accepted by --output. Every command also
+// has a human readable format, which is its default, usually called "table".
+//
+// The field names of the JSON and YAML output are a contract: fields may be
+// added, but existing ones are not renamed or removed. They are documented in
+// docs/output_formats.md.
+const (
+	outputJSON           = "json"
+	outputYAML           = "yaml"
+	outputTemplatePrefix = "go-template="
+)
+
+// outputFormat is the parsed value of an --output flag.
+type outputFormat struct {
+	name string
+	tmpl *template.Template
+}
+
+// addOutputFlag adds the --output flag to f, defaulting to the human readable
+// format called human. shorthand is usually "o".
+func addOutputFlag(f *pflag.FlagSet, p *string, shorthand, human string) {
+	usage := fmt.Sprintf("output format. One of '%s', 'json', 'yaml' or 'go-template=TEMPLATE'", human)
+	f.StringVarP(p, "output", shorthand, human, usage)
+}
+
+// parseOutputFormat parses the value of an --output flag for a command whose
+// human readable format is called human.
+func parseOutputFormat(s, human string) (outputFormat, error) {
+	switch {
+	case s == human, s == outputJSON, s == outputYAML:
+		return outputFormat{name: s}, nil
+	case strings.HasPrefix(s, outputTemplatePrefix):
+		t, err := template.New("output").Funcs(sprig.TxtFuncMap()).Parse(strings.TrimPrefix(s, outputTemplatePrefix))
+		if err != nil {
+			return outputFormat{}, fmt.Errorf("invalid output template: %s", err)
+		}
+		return outputFormat{name: outputTemplatePrefix, tmpl: t}, nil
+	}
+	return outputFormat{}, fmt.Errorf("unknown output format %q. One of '%s', 'json', 'yaml' or 'go-template=TEMPLATE'", s, human)
+}
+
+// human reports whether the command should print its human readable format.
+func (o outputFormat) human() bool {
+	return o.name != outputJSON && o.name != outputYAML && o.tmpl == nil
+}
+
+// write prints v in the machine readable format.
+//
+// Templates are executed against the JSON form of v, so that they use the
+// same field names as the JSON and YAML output.
+func (o outputFormat) write(out io.Writer, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	switch o.name {
+	case outputJSON:
+		fmt.Fprintln(out, string(data))
+		return nil
+	case outputYAML:
+		y, err := yaml.JSONToYAML(data)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(y)
+		return err
+	}
+
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return err
+	}
+	return o.tmpl.Execute(out, generic)
+}
+
+// outputTime formats ts for machine readable output. A missing time is empty.
+func outputTime(ts *timestamp.Timestamp) string {
+	if ts == nil {
+		return ""
+	}
+	return timeconv.Time(ts).UTC().Format(time.RFC3339)
+}
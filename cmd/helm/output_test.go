@@ -0,0 +1,63 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestOutputFormat(t *testing.T) {
+	v := []repoListEntry{{Name: "stable", URL: "https://example.com/charts"}}
+
+	tests := []struct {
+		format string
+		expect string
+		err    bool
+	}{
+		{format: "json", expect: "[\n  {\n    \"name\": \"stable\",\n    \"url\": \"https://example.com/charts\"\n  }\n]\n"},
+		{format: "yaml", expect: "- name: stable\n  url: https://example.com/charts\n"},
+		{format: "go-template={{range .}}{{.name}} {{.url | upper}}{{end}}", expect: "stable HTTPS://EXAMPLE.COM/CHARTS"},
+		{format: "go-template={{", err: true},
+		{format: "xml", err: true},
+	}
+
+	for _, tt := range tests {
+		f, err := parseOutputFormat(tt.format, "table")
+		if (err != nil) != tt.err {
+			t.Errorf("%q: expected error %v, got %v", tt.format, tt.err, err)
+		}
+		if err != nil {
+			continue
+		}
+		if f.human() {
+			t.Errorf("%q: expected a machine readable format", tt.format)
+		}
+		var buf bytes.Buffer
+		if err := f.write(&buf, v); err != nil {
+			t.Errorf("%q: %s", tt.format, err)
+		}
+		if buf.String() != tt.expect {
+			t.Errorf("%q: expected %q, got %q", tt.format, tt.expect, buf.String())
+		}
+	}
+
+	f, err := parseOutputFormat("table", "table")
+	if err != nil || !f.human() {
+		t.Errorf("Expected 'table' to be the human readable format, got %v", err)
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"syscall"
 
 	"github.com/spf13/cobra"
@@ -31,8 +32,10 @@ import (
 	"k8s.io/helm/cmd/helm/helmpath"
 	"k8s.io/helm/pkg/chartutil"
 	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/chart"
 	"k8s.io/helm/pkg/provenance"
 	"k8s.io/helm/pkg/repo"
+	"k8s.io/helm/pkg/sbom"
 )
 
 const packageDesc = `
@@ -44,22 +47,49 @@ If no path is given, this will look in the present working directory for a
 Chart.yaml file, and (if found) build the current directory into a chart.
 
 Versioned chart archives are used by Helm package repositories.
+
+The archive is written to the current directory, or to the directory given
+with '--destination'. '--destination -' writes it to stdout instead, so that
+it can be piped to commands that read a chart from stdin:
+
+	$ helm package --save=false -d - ./mychart | helm install -
+
+With '--format tzst', the archive is compressed with zstd instead of gzip, and
+has an index of its files, so that commands such as 'helm inspect' can read a
+chart's Chart.yaml and values.yaml from a repository without downloading the
+whole archive. Older versions of Helm cannot read these archives.
+
+With '--sign', the provenance file records the sum of the archive with
+sha256, or with the algorithm named by '--hash'. Charts signed with sha512 or
+blake2b cannot be verified by older versions of Helm.
+
+With '--checksum', a NAME-VERSION.tgz.sha256sum file in the format read by
+'sha256sum -c' is written next to the archive. With '--sbom', a
+NAME-VERSION.cdx.json file holds a CycloneDX bill of materials listing the
+chart, its dependencies (with the digests of packaged dependency archives),
+and the container images referenced by its values and templates.
 `
 
 type packageCmd struct {
-	save    bool
-	sign    bool
-	path    string
-	key     string
-	keyring string
-	out     io.Writer
-	home    helmpath.Home
+	save        bool
+	sign        bool
+	path        string
+	destination string
+	key         string
+	keyring     string
+	hash        string
+	checksum    bool
+	sbom        bool
+	format      chartutil.ArchiveFormat
+	out         io.Writer
+	home        helmpath.Home
 }
 
 func newPackageCmd(client helm.Interface, out io.Writer) *cobra.Command {
 	pkg := &packageCmd{
 		out: out,
 	}
+	var format string
 
 	cmd := &cobra.Command{
 		Use:   "package [flags] [CHART_PATH] [...]",
@@ -70,6 +100,18 @@ func newPackageCmd(client helm.Interface, out io.Writer) *cobra.Command {
 			if len(args) == 0 {
 				return fmt.Errorf("This command needs at least one argument, the path to the chart.")
 			}
+			var err error
+			if pkg.format, err = chartutil.ParseArchiveFormat(format); err != nil {
+				return err
+			}
+			if pkg.destination == "-" {
+				if len(args) > 1 {
+					return errors.New("only one chart can be packaged to stdout")
+				}
+				if pkg.sign || pkg.checksum || pkg.sbom {
+					return errors.New("--sign, --checksum and --sbom cannot be used with --destination -")
+				}
+			}
 			if pkg.sign {
 				if pkg.key == "" {
 					return errors.New("--key is required for signing a package")
@@ -77,6 +119,9 @@ func newPackageCmd(client helm.Interface, out io.Writer) *cobra.Command {
 				if pkg.keyring == "" {
 					return errors.New("--keyring is required for signing a package")
 				}
+				if _, err := provenance.LookupHash(pkg.hash); err != nil {
+					return err
+				}
 			}
 			for i := 0; i < len(args); i++ {
 				pkg.path = args[i]
@@ -90,9 +135,14 @@ func newPackageCmd(client helm.Interface, out io.Writer) *cobra.Command {
 
 	f := cmd.Flags()
 	f.BoolVar(&pkg.save, "save", true, "save packaged chart to local chart repository")
+	f.StringVarP(&pkg.destination, "destination", "d", ".", "location to write the chart. '-' writes the archive to stdout")
 	f.BoolVar(&pkg.sign, "sign", false, "use a PGP private key to sign this package")
 	f.StringVar(&pkg.key, "key", "", "name of the key to use when signing. Used if --sign is true")
 	f.StringVar(&pkg.keyring, "keyring", defaultKeyring(), "location of a public keyring")
+	f.StringVar(&pkg.hash, "hash", provenance.DefaultHash, "hash algorithm the provenance file records the sum of the package with: "+strings.Join(provenance.HashNames(), ", "))
+	f.BoolVar(&pkg.checksum, "checksum", false, "write a .sha256sum file for the package")
+	f.BoolVar(&pkg.sbom, "sbom", false, "write a CycloneDX bill of materials for the package")
+	f.StringVar(&format, "format", string(chartutil.FormatTgz), "archive format: tgz, or tzst for zstd compression with an index of the files")
 
 	return cmd
 }
@@ -112,14 +162,18 @@ func (p *packageCmd) run(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("directory name (%s) and Chart.yaml name (%s) must match", filepath.Base(path), ch.Metadata.Name)
 	}
 
-	// Save to the current working directory.
-	cwd, err := os.Getwd()
+	if p.destination == "-" {
+		return p.stream(ch)
+	}
+
+	// Save to the destination directory.
+	dest, err := filepath.Abs(p.destination)
 	if err != nil {
 		return err
 	}
-	name, err := chartutil.Save(ch, cwd)
+	name, err := chartutil.SaveArchive(ch, dest, p.format)
 	if err == nil && flagDebug {
-		fmt.Fprintf(p.out, "Saved %s to current directory\n", name)
+		fmt.Fprintf(p.out, "Saved %s to %s\n", name, dest)
 	}
 
 	// Save to $HELM_HOME/local directory. This is second, because we don't want
@@ -134,12 +188,74 @@ func (p *packageCmd) run(cmd *cobra.Command, args []string) error {
 	}
 
 	if p.sign {
-		err = p.clearsign(name)
+		if err = p.clearsign(name); err != nil {
+			return err
+		}
+	}
+
+	if p.checksum || p.sbom {
+		err = p.supplyChainFiles(ch, path, name)
 	}
 
 	return err
 }
 
+// stream writes the archive of ch to stdout. Nothing else is printed there,
+// so that the archive can be piped to another command.
+func (p *packageCmd) stream(ch *chart.Chart) error {
+	if err := chartutil.WriteArchive(p.out, ch, p.format); err != nil {
+		return err
+	}
+	if p.save {
+		return repo.AddChartToLocalRepo(ch, p.home.LocalRepository())
+	}
+	return nil
+}
+
+// supplyChainFiles writes the checksum and SBOM files requested for the
+// archive at filename, packaged from the chart directory dir.
+func (p *packageCmd) supplyChainFiles(ch *chart.Chart, dir, filename string) error {
+	digest, err := provenance.DigestFile(filename)
+	if err != nil {
+		return err
+	}
+
+	if p.checksum {
+		sum := fmt.Sprintf("%s  %s\n", digest, filepath.Base(filename))
+		if err := ioutil.WriteFile(filename+".sha256sum", []byte(sum), 0644); err != nil {
+			return err
+		}
+	}
+
+	if !p.sbom {
+		return nil
+	}
+
+	// Packaged dependencies are listed with the digests of their archives.
+	digests := map[string]string{}
+	archives, err := filepath.Glob(filepath.Join(dir, "charts", "*"))
+	if err != nil {
+		return err
+	}
+	for _, a := range archives {
+		if !chartutil.IsArchive(a) {
+			continue
+		}
+		d, err := provenance.DigestFile(a)
+		if err != nil {
+			return err
+		}
+		digests[chartutil.TrimArchiveExt(filepath.Base(a))] = d
+	}
+
+	f, err := os.Create(chartutil.TrimArchiveExt(filename) + ".cdx.json")
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return sbom.New(ch, digest, digests).Write(f)
+}
+
 func (p *packageCmd) clearsign(filename string) error {
 	// Load keyring
 	signer, err := provenance.NewFromKeyring(p.keyring, p.key)
@@ -150,6 +266,7 @@ func (p *packageCmd) clearsign(filename string) error {
 	if err := signer.DecryptKey(promptUser); err != nil {
 		return err
 	}
+	signer.Hash = p.hash
 
 	sig, err := signer.ClearSign(filename)
 	if err != nil {
@@ -21,11 +21,13 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/spf13/cobra"
 
 	"k8s.io/helm/cmd/helm/helmpath"
+	"k8s.io/helm/pkg/chartutil"
 )
 
 func TestPackage(t *testing.T) {
@@ -72,6 +74,20 @@ func TestPackage(t *testing.T) {
 			expect:  "",
 			hasfile: "alpine-0.1.0.tgz",
 		},
+		{
+			name:   "package --destination - --sign testdata/testcharts/alpine",
+			args:   []string{"testdata/testcharts/alpine"},
+			flags:  map[string]string{"destination": "-", "sign": "1", "keyring": "testdata/helm-test-key.secret", "key": "helm-test"},
+			expect: "cannot be used with --destination -",
+			err:    true,
+		},
+		{
+			name:   "package --destination nosuchdir testdata/testcharts/alpine",
+			args:   []string{"testdata/testcharts/alpine"},
+			flags:  map[string]string{"destination": "nosuchdir"},
+			expect: "no such file or directory",
+			err:    true,
+		},
 		{
 			name:    "package --sign --key=KEY --keyring=KEYRING testdata/testcharts/alpine",
 			args:    []string{"testdata/testcharts/alpine"},
@@ -79,6 +95,41 @@ func TestPackage(t *testing.T) {
 			expect:  "",
 			hasfile: "alpine-0.1.0.tgz",
 		},
+		{
+			name:    "package --sign --hash sha512 testdata/testcharts/alpine",
+			args:    []string{"testdata/testcharts/alpine"},
+			flags:   map[string]string{"sign": "1", "keyring": "testdata/helm-test-key.secret", "key": "helm-test", "hash": "sha512"},
+			expect:  "",
+			hasfile: "alpine-0.1.0.tgz",
+		},
+		{
+			name:   "package --sign --hash md5",
+			args:   []string{"testdata/testcharts/alpine"},
+			flags:  map[string]string{"sign": "1", "keyring": "testdata/helm-test-key.secret", "key": "helm-test", "hash": "md5"},
+			expect: "unsupported hash algorithm",
+			err:    true,
+		},
+		{
+			name:    "package --checksum --sbom testdata/testcharts/alpine",
+			args:    []string{"testdata/testcharts/alpine"},
+			flags:   map[string]string{"checksum": "1", "sbom": "1"},
+			expect:  "",
+			hasfile: "alpine-0.1.0.tgz",
+		},
+		{
+			name:    "package --format tzst testdata/testcharts/alpine",
+			args:    []string{"testdata/testcharts/alpine"},
+			flags:   map[string]string{"format": "tzst", "sbom": "1"},
+			expect:  "",
+			hasfile: "alpine-0.1.0.tzst",
+		},
+		{
+			name:   "package --format zip",
+			args:   []string{"testdata/testcharts/alpine"},
+			flags:  map[string]string{"format": "zip"},
+			expect: "unknown chart archive format",
+			err:    true,
+		},
 	}
 
 	// Because these tests are destructive, we run them in a tempdir.
@@ -149,7 +200,52 @@ func TestPackage(t *testing.T) {
 			} else if fi.Size() == 0 {
 				t.Errorf("%q: provenance file is empty", tt.name)
 			}
+			if alg, ok := tt.flags["hash"]; ok {
+				if data, _ := ioutil.ReadFile(tt.hasfile + ".prov"); !strings.Contains(string(data), ": "+alg+":") {
+					t.Errorf("%q: expected the provenance file to record a %s sum", tt.name, alg)
+				}
+			}
+		}
+
+		if v, ok := tt.flags["checksum"]; ok && v == "1" {
+			data, err := ioutil.ReadFile(tt.hasfile + ".sha256sum")
+			if err != nil {
+				t.Errorf("%q: expected checksum file", tt.name)
+			} else if !regexp.MustCompile(`^[0-9a-f]{64}  ` + tt.hasfile + "\n$").Match(data) {
+				t.Errorf("%q: unexpected checksum file %q", tt.name, data)
+			}
 		}
+
+		if v, ok := tt.flags["sbom"]; ok && v == "1" {
+			data, err := ioutil.ReadFile(chartutil.TrimArchiveExt(tt.hasfile) + ".cdx.json")
+			if err != nil {
+				t.Errorf("%q: expected SBOM file", tt.name)
+			} else if !bytes.Contains(data, []byte(`"bomFormat": "CycloneDX"`)) {
+				t.Errorf("%q: unexpected SBOM %s", tt.name, data)
+			}
+		}
+	}
+}
+
+func TestPackageStdout(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	c := newPackageCmd(nil, buf)
+	setFlags(c, map[string]string{"destination": "-", "save": "0", "format": "tzst"})
+	if err := c.RunE(c, []string{"testdata/testcharts/alpine"}); err != nil {
+		t.Fatal(err)
+	}
+	ch, err := chartutil.LoadArchive(buf)
+	if err != nil {
+		t.Fatalf("expected an archive on stdout: %s", err)
+	}
+	if ch.Metadata.Name != "alpine" {
+		t.Errorf("expected the alpine chart, got %q", ch.Metadata.Name)
+	}
+
+	c = newPackageCmd(nil, bytes.NewBuffer(nil))
+	setFlags(c, map[string]string{"destination": "-", "save": "0"})
+	if err := c.RunE(c, []string{"testdata/testcharts/alpine", "testdata/testcharts/novals"}); err == nil {
+		t.Error("expected packaging several charts to stdout to fail")
 	}
 }
 
@@ -0,0 +1,81 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/pprof"
+)
+
+var (
+	// cpuProfile and memProfile are the files that profiles of the command
+	// are written to.
+	cpuProfile string
+	memProfile string
+
+	cpuProfileFile *os.File
+)
+
+// startProfiling starts the CPU profile of --cpuprofile, if it is set. It
+// runs once the flags are parsed.
+func startProfiling() {
+	startCPUProfile(os.Stderr)
+}
+
+func startCPUProfile(errOut io.Writer) {
+	if cpuProfile == "" {
+		return
+	}
+	f, err := os.Create(cpuProfile)
+	if err != nil {
+		fmt.Fprintf(errOut, "Error: cannot write CPU profile: %s\n", err)
+		return
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		fmt.Fprintf(errOut, "Error: cannot start CPU profile: %s\n", err)
+		f.Close()
+		return
+	}
+	cpuProfileFile = f
+}
+
+// stopProfiling stops the CPU profile, and writes the heap profile of
+// --memprofile if it is set. Problems are reported to errOut.
+func stopProfiling(errOut io.Writer) {
+	if cpuProfileFile != nil {
+		pprof.StopCPUProfile()
+		cpuProfileFile.Close()
+		cpuProfileFile = nil
+	}
+	if memProfile == "" {
+		return
+	}
+	f, err := os.Create(memProfile)
+	if err != nil {
+		fmt.Fprintf(errOut, "Error: cannot write memory profile: %s\n", err)
+		return
+	}
+	defer f.Close()
+	// Profile the live heap, rather than what has not been collected yet.
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		fmt.Fprintf(errOut, "Error: cannot write memory profile: %s\n", err)
+	}
+}
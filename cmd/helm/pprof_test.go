@@ -0,0 +1,55 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCPUAndMemoryProfiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-profile-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cpuProfile = filepath.Join(dir, "cpu.prof")
+	memProfile = filepath.Join(dir, "mem.prof")
+	defer func() { cpuProfile, memProfile = "", "" }()
+
+	errOut := bytes.NewBuffer(nil)
+	startCPUProfile(errOut)
+	stopProfiling(errOut)
+	if errOut.Len() > 0 {
+		t.Fatalf("unexpected errors: %s", errOut)
+	}
+	for _, f := range []string{cpuProfile, memProfile} {
+		if fi, err := os.Stat(f); err != nil || fi.Size() == 0 {
+			t.Errorf("expected a profile in %s, got %v", f, err)
+		}
+	}
+
+	memProfile = filepath.Join(dir, "missing", "mem.prof")
+	stopProfiling(errOut)
+	if errOut.Len() == 0 {
+		t.Error("expected an error for an unwritable memory profile")
+	}
+}
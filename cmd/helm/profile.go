@@ -0,0 +1,70 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/helm/pkg/chartutil"
+)
+
+// profilesKey is the key of values files that holds named values profiles.
+const profilesKey = "profiles"
+
+// applyProfile merges the named profile of a values file over the rest of it.
+//
+// Profiles live under the top-level "profiles" key:
+//
+//	replicas: 1
+//	profiles:
+//	  prod:
+//	    replicas: 3
+//
+// With profile "prod", the values above become "replicas: 3". The "profiles"
+// key itself is removed. If profile is empty, vals is left as it is, so
+// that charts with a value named "profiles" keep working.
+func applyProfile(vals map[string]interface{}, profile string) error {
+	if profile == "" {
+		return nil
+	}
+	profiles, ok := vals[profilesKey].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("profile %q requested, but the values have no %q table", profile, profilesKey)
+	}
+	p, ok := profiles[profile]
+	if !ok {
+		names := make([]string, 0, len(profiles))
+		for n := range profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("profile %q not found. Available profiles: %s", profile, strings.Join(names, ", "))
+	}
+	delete(vals, profilesKey)
+
+	if p == nil {
+		return nil
+	}
+	pt, ok := p.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("profile %q must be a table", profile)
+	}
+	chartutil.MergeValues(vals, pt)
+	return nil
+}
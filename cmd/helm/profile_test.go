@@ -0,0 +1,80 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ghodss/yaml"
+)
+
+const profileValues = `
+replicas: 1
+image:
+  name: web
+  tag: latest
+profiles:
+  prod:
+    replicas: 3
+    image:
+      tag: "1.0"
+  staging:
+`
+
+func TestApplyProfile(t *testing.T) {
+	tests := []struct {
+		profile string
+		expect  string
+		err     bool
+	}{
+		{profile: "prod", expect: "replicas: 3\nimage:\n  name: web\n  tag: \"1.0\"\n"},
+		{profile: "staging", expect: "replicas: 1\nimage:\n  name: web\n  tag: latest\n"},
+		{profile: "", expect: profileValues},
+		{profile: "dev", err: true},
+	}
+
+	for _, tt := range tests {
+		vals := map[string]interface{}{}
+		if err := yaml.Unmarshal([]byte(profileValues), &vals); err != nil {
+			t.Fatal(err)
+		}
+		err := applyProfile(vals, tt.profile)
+		if tt.err {
+			if err == nil {
+				t.Errorf("%q: expected an error", tt.profile)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %s", tt.profile, err)
+			continue
+		}
+
+		expect := map[string]interface{}{}
+		if err := yaml.Unmarshal([]byte(tt.expect), &expect); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(vals, expect) {
+			t.Errorf("%q: expected %v, got %v", tt.profile, expect, vals)
+		}
+	}
+
+	if err := applyProfile(map[string]interface{}{"replicas": 1}, "prod"); err == nil {
+		t.Error("expected an error for values without profiles")
+	}
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/helm/pkg/helm"
+)
+
+const promoteDesc = `
+This command promotes the blue/green revision of a release, made with
+'helm upgrade --blue-green'.
+
+Once the Deployments of the revision are ready, the Services of the release
+are switched over to them. The Deployments of the previous revision are kept
+for the grace period, so that connections to them can finish, and are then
+deleted:
+
+	$ helm upgrade happy-panda stable/wordpress --blue-green
+	$ helm promote happy-panda --grace-period 120
+
+To abandon the revision instead, roll back to the previous one.
+`
+
+type promoteCmd struct {
+	release     string
+	gracePeriod int64
+	timeout     int64
+	out         io.Writer
+	client      helm.Interface
+}
+
+func newPromoteCmd(c helm.Interface, out io.Writer) *cobra.Command {
+	p := &promoteCmd{out: out, client: c}
+
+	cmd := &cobra.Command{
+		Use:               "promote [flags] RELEASE_NAME",
+		Short:             "switch a release over to its blue/green revision",
+		Long:              promoteDesc,
+		PersistentPreRunE: setupConnection,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkArgsLength(len(args), "release name"); err != nil {
+				return err
+			}
+			p.release = args[0]
+			p.client = ensureHelmClient(p.client)
+			return p.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.Int64Var(&p.gracePeriod, "grace-period", 60, "time in seconds to keep the Deployments of the previous revision after the switch")
+	f.Int64Var(&p.timeout, "timeout", 300, "time in seconds to wait for the Deployments of the revision to be ready")
+
+	return cmd
+}
+
+func (p *promoteCmd) run() error {
+	res, err := p.client.PromoteRelease(p.release, helm.PromoteGracePeriod(p.gracePeriod), helm.PromoteTimeout(p.timeout))
+	if err != nil {
+		return prettyError(err)
+	}
+	fmt.Fprintf(p.out, "Release %q has been switched over to slot %s (revision %d).\n", p.release, res.Release.Info.BlueGreen.Slot, res.Release.Version)
+	return nil
+}
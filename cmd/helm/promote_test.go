@@ -0,0 +1,43 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+func TestPromoteCmd(t *testing.T) {
+	rel := releaseMock(&releaseOptions{name: "aeneas", version: 2})
+	rel.Info.BlueGreen = &release.BlueGreen{Phase: release.BlueGreen_PROMOTED, Slot: "green", Deployments: []string{"web-green"}}
+
+	out := bytes.NewBuffer(nil)
+	cmd := newPromoteCmd(&fakeReleaseClient{rels: []*release.Release{rel}}, out)
+	cmd.ParseFlags([]string{"--grace-period", "10"})
+	if err := cmd.RunE(cmd, []string{"aeneas"}); err != nil {
+		t.Fatal(err)
+	}
+	if expect := "Release \"aeneas\" has been switched over to slot green (revision 2).\n"; out.String() != expect {
+		t.Errorf("expected %q, got %q", expect, out.String())
+	}
+
+	if err := cmd.RunE(cmd, []string{}); err == nil {
+		t.Error("expected an error without a release name")
+	}
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/golang/protobuf/proto"
+	"github.com/spf13/cobra"
+
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+const releaseDesc = `
+This command moves releases between clusters, and protects them from changes.
+
+'helm release export' writes the record of every revision of a release,
+including its chart, values, manifests and hooks, into a single archive.
+'helm release import' stores those revisions in another Tiller, and can create
+the resources of the release there.
+
+'helm release lock' makes Tiller refuse to upgrade, roll back or delete a
+release until 'helm release unlock' is run, unless the change gives a reason
+with '--override-lock'.
+`
+
+// releaseManifestName is the name of the file that describes the contents of
+// a release archive.
+const releaseManifestName = "release.yaml"
+
+// releaseRevisionsDir is the directory of a release archive holding the
+// revisions, each one a protobuf-encoded hapi.release.Release.
+const releaseRevisionsDir = "revisions"
+
+// releaseManifest describes the contents of a release archive.
+type releaseManifest struct {
+	APIVersion string `json:"apiVersion"`
+	// Name and Namespace are those of the exported release.
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// Revisions lists the revision numbers stored under revisions/.
+	Revisions []int32 `json:"revisions"`
+}
+
+func newReleaseCmd(client helm.Interface, out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "release [FLAGS] export|import|lock|unlock [ARGS]",
+		Short:             "export, import, lock and unlock releases",
+		Long:              releaseDesc,
+		PersistentPreRunE: setupConnection,
+	}
+
+	cmd.AddCommand(newReleaseExportCmd(client, out))
+	cmd.AddCommand(newReleaseImportCmd(client, out))
+	cmd.AddCommand(newReleaseLockCmd(client, out))
+	cmd.AddCommand(newReleaseUnlockCmd(client, out))
+
+	return cmd
+}
+
+func revisionFile(name string, version int32) string {
+	return fmt.Sprintf("%s/%s.v%d", releaseRevisionsDir, name, version)
+}
+
+// archiveFile is a file in an archive written by writeArchive.
+type archiveFile struct {
+	name string
+	data []byte
+}
+
+// writeArchive writes files to w as a gzipped tar archive.
+func writeArchive(w io.Writer, files []archiveFile) error {
+	zw := gzip.NewWriter(w)
+	tw := tar.NewWriter(zw)
+	now := time.Now()
+	for _, f := range files {
+		hdr := &tar.Header{Name: f.name, Mode: 0644, Size: int64(len(f.data)), ModTime: now}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(f.data); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// readArchive reads the files of a gzipped tar archive, by name.
+func readArchive(r io.Reader) (map[string][]byte, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	files := map[string][]byte{}
+	tr := tar.NewReader(zr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return files, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		files[path.Clean(hdr.Name)] = data
+	}
+}
+
+// readRevision decodes revision version of release name from the files of
+// an archive.
+func readRevision(files map[string][]byte, name string, version int32) (*release.Release, error) {
+	data, ok := files[revisionFile(name, version)]
+	if !ok {
+		return nil, fmt.Errorf("revision %d of %q is missing", version, name)
+	}
+	rel := &release.Release{}
+	if err := proto.Unmarshal(data, rel); err != nil {
+		return nil, fmt.Errorf("could not read revision %d of %q: %s", version, name, err)
+	}
+	if rel.Name != name || rel.Version != version {
+		return nil, fmt.Errorf("revision %d of %q holds revision %d of %q", version, name, rel.Version, rel.Name)
+	}
+	return rel, nil
+}
+
+// writeReleaseArchive writes the revisions of a release, oldest first, to w
+// as a gzipped tar archive.
+func writeReleaseArchive(w io.Writer, rels []*release.Release) error {
+	m := &releaseManifest{APIVersion: "v1", Name: rels[0].Name, Namespace: rels[0].Namespace}
+	files := []archiveFile{{name: releaseManifestName}}
+	for _, r := range rels {
+		data, err := proto.Marshal(r)
+		if err != nil {
+			return err
+		}
+		files = append(files, archiveFile{revisionFile(r.Name, r.Version), data})
+		m.Revisions = append(m.Revisions, r.Version)
+	}
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		return err
+	}
+	files[0].data = data
+	return writeArchive(w, files)
+}
+
+// readReleaseArchive reads the revisions from an archive written by
+// writeReleaseArchive, checking them against its manifest.
+func readReleaseArchive(r io.Reader) (*releaseManifest, []*release.Release, error) {
+	files, err := readArchive(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, ok := files[releaseManifestName]
+	if !ok {
+		return nil, nil, fmt.Errorf("no %s found", releaseManifestName)
+	}
+	m := &releaseManifest{}
+	if err := yaml.Unmarshal(data, m); err != nil {
+		return nil, nil, fmt.Errorf("could not parse %s: %s", releaseManifestName, err)
+	}
+	if len(m.Revisions) == 0 {
+		return nil, nil, fmt.Errorf("no revisions of %q found", m.Name)
+	}
+
+	rels := []*release.Release{}
+	for _, v := range m.Revisions {
+		rel, err := readRevision(files, m.Name, v)
+		if err != nil {
+			return nil, nil, err
+		}
+		rels = append(rels, rel)
+	}
+	return m, rels, nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/release"
+	"k8s.io/helm/pkg/releaseutil"
+)
+
+const releaseExportDesc = `
+This command writes the revisions of a release to an archive,
+RELEASE.release.tgz, that 'helm release import' can load into another Tiller.
+
+The archive holds the full record of each revision: the chart, the values,
+the rendered manifests and the hooks. Up to 256 revisions are exported, the
+latest first; use '--max' to change this.
+`
+
+type releaseExportCmd struct {
+	name    string
+	max     int32
+	destdir string
+	out     io.Writer
+	client  helm.Interface
+}
+
+func newReleaseExportCmd(client helm.Interface, out io.Writer) *cobra.Command {
+	exp := &releaseExportCmd{out: out, client: client}
+
+	cmd := &cobra.Command{
+		Use:   "export [flags] RELEASE_NAME",
+		Short: "write the revisions of a release to an archive",
+		Long:  releaseExportDesc,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return errReleaseRequired
+			}
+			exp.name = args[0]
+			if exp.client == nil {
+				exp.client = helm.NewClient(helm.Host(tillerHost), helm.UserToken(userToken), helm.Impersonate(asUser, asGroups), helm.RequestID(requestID))
+			}
+			return exp.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.Int32Var(&exp.max, "max", 256, "maximum number of revisions to export")
+	f.StringVarP(&exp.destdir, "destination", "d", ".", "location to write the archive to")
+
+	return cmd
+}
+
+func (e *releaseExportCmd) run() error {
+	res, err := e.client.ReleaseHistory(e.name, helm.WithMaxHistory(e.max))
+	if err != nil {
+		return prettyError(err)
+	}
+	if len(res.Releases) == 0 {
+		return fmt.Errorf("release %q not found", e.name)
+	}
+	rels := append([]*release.Release{}, res.Releases...)
+	releaseutil.SortByRevision(rels)
+
+	dest := filepath.Join(e.destdir, e.name+".release.tgz")
+	f, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if err := writeReleaseArchive(f, rels); err != nil {
+		f.Close()
+		os.Remove(dest)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.out, "Exported %d revisions of %s to %s\n", len(rels), e.name, dest)
+	return nil
+}
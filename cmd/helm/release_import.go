@@ -0,0 +1,98 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+const releaseImportDesc = `
+This command stores the revisions of a release exported by 'helm release
+export' in Tiller, under the same name. A release of that name must not exist
+yet.
+
+By default only the release records are stored, which suits a cluster that
+already runs the resources of the release, such as one restored from a
+backup. With '--apply', the resources of the latest revision are created as
+well, if that revision is deployed. '--namespace' moves the release to
+another namespace.
+`
+
+type releaseImportCmd struct {
+	archive       string
+	namespace     string
+	apply         bool
+	overrideGates string
+	out           io.Writer
+	client        helm.Interface
+}
+
+func newReleaseImportCmd(client helm.Interface, out io.Writer) *cobra.Command {
+	imp := &releaseImportCmd{out: out, client: client}
+
+	cmd := &cobra.Command{
+		Use:   "import [flags] ARCHIVE",
+		Short: "store the revisions of an exported release",
+		Long:  releaseImportDesc,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkArgsLength(len(args), "archive"); err != nil {
+				return err
+			}
+			imp.archive = args[0]
+			if imp.client == nil {
+				imp.client = helm.NewClient(helm.Host(tillerHost), helm.UserToken(userToken), helm.Impersonate(asUser, asGroups), helm.RequestID(requestID))
+			}
+			return imp.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&imp.namespace, "namespace", "", "namespace to import the release into. Defaults to the namespace it was exported from")
+	f.BoolVar(&imp.apply, "apply", false, "create the resources of the latest revision")
+	f.StringVar(&imp.overrideGates, "override-gates", "", "apply the release even if the deployment windows or gates of Tiller do not allow it, giving the reason for the change")
+
+	return cmd
+}
+
+func (i *releaseImportCmd) run() error {
+	f, err := os.Open(i.archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	m, rels, err := readReleaseArchive(f)
+	if err != nil {
+		return fmt.Errorf("could not read release archive %s: %s", i.archive, err)
+	}
+
+	res, err := i.client.ImportRelease(rels, helm.ImportNamespace(i.namespace), helm.ImportApply(i.apply), helm.ImportOverrideGates(i.overrideGates))
+	if err != nil {
+		return prettyError(err)
+	}
+	fmt.Fprintf(i.out, "Imported %d revisions of %s\n", len(m.Revisions), m.Name)
+	if r := res.GetRelease(); i.apply && r.GetInfo().GetStatus() != nil && r.Info.Status.Code == release.Status_DEPLOYED {
+		fmt.Fprintf(i.out, "Created the resources of revision %d in namespace %s\n", r.Version, r.Namespace)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/helm/pkg/helm"
+)
+
+const releaseLockDesc = `
+This command locks a release, to protect it from accidental changes, e.g.
+during a change freeze. Tiller refuses to upgrade, roll back or delete a
+locked release, unless the command is given '--override-lock' with the reason
+for the change, which is recorded in the metadata of the revision it makes:
+
+	$ helm release lock happy-panda --reason "change freeze until Monday"
+	$ helm upgrade happy-panda stable/mariadb --override-lock "fix for INC-42"
+
+The lock is kept across upgrades and rollbacks, until 'helm release unlock'
+removes it. Dry runs of a locked release are not refused.
+`
+
+const releaseUnlockDesc = `
+This command removes the lock of a release set with 'helm release lock'. The
+reason for removing it is recorded in the metadata of the latest revision:
+
+	$ helm release unlock happy-panda --reason "change freeze is over"
+`
+
+type releaseLockCmd struct {
+	release string
+	reason  string
+	unlock  bool
+	out     io.Writer
+	client  helm.Interface
+}
+
+func newReleaseLockCmd(client helm.Interface, out io.Writer) *cobra.Command {
+	l := &releaseLockCmd{out: out, client: client}
+
+	cmd := &cobra.Command{
+		Use:   "lock [flags] RELEASE_NAME",
+		Short: "protect a release from upgrades, rollbacks and deletion",
+		Long:  releaseLockDesc,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkArgsLength(len(args), "release name"); err != nil {
+				return err
+			}
+			if l.reason == "" {
+				return errors.New("--reason is required")
+			}
+			l.release = args[0]
+			l.client = ensureHelmClient(l.client)
+			return l.run()
+		},
+	}
+
+	cmd.Flags().StringVar(&l.reason, "reason", "", "why the release is locked")
+
+	return cmd
+}
+
+func newReleaseUnlockCmd(client helm.Interface, out io.Writer) *cobra.Command {
+	l := &releaseLockCmd{out: out, client: client, unlock: true}
+
+	cmd := &cobra.Command{
+		Use:   "unlock [flags] RELEASE_NAME",
+		Short: "remove the lock of a release",
+		Long:  releaseUnlockDesc,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkArgsLength(len(args), "release name"); err != nil {
+				return err
+			}
+			if l.reason == "" {
+				return errors.New("--reason is required")
+			}
+			l.release = args[0]
+			l.client = ensureHelmClient(l.client)
+			return l.run()
+		},
+	}
+
+	cmd.Flags().StringVar(&l.reason, "reason", "", "why the release is unlocked")
+
+	return cmd
+}
+
+func (l *releaseLockCmd) run() error {
+	if _, err := l.client.SetReleaseLock(l.release, helm.LockReason(l.reason), helm.LockRemove(l.unlock)); err != nil {
+		return prettyError(err)
+	}
+	if l.unlock {
+		fmt.Fprintf(l.out, "Release %q has been unlocked.\n", l.release)
+		return nil
+	}
+	fmt.Fprintf(l.out, "Release %q has been locked: %s\n", l.release, l.reason)
+	return nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+func TestReleaseLock(t *testing.T) {
+	tests := []struct {
+		name   string
+		cmd    func(helm.Interface, *bytes.Buffer) *cobra.Command
+		args   []string
+		flags  []string
+		expect string
+		err    bool
+	}{
+		{
+			name:   "lock",
+			cmd:    func(c helm.Interface, out *bytes.Buffer) *cobra.Command { return newReleaseLockCmd(c, out) },
+			args:   []string{"aeneas"},
+			flags:  []string{"--reason", "change freeze"},
+			expect: "Release \"aeneas\" has been locked: change freeze\n",
+		},
+		{
+			name: "lock without a reason",
+			cmd:  func(c helm.Interface, out *bytes.Buffer) *cobra.Command { return newReleaseLockCmd(c, out) },
+			args: []string{"aeneas"},
+			err:  true,
+		},
+		{
+			name:   "unlock",
+			cmd:    func(c helm.Interface, out *bytes.Buffer) *cobra.Command { return newReleaseUnlockCmd(c, out) },
+			args:   []string{"aeneas"},
+			flags:  []string{"--reason", "freeze is over"},
+			expect: "Release \"aeneas\" has been unlocked.\n",
+		},
+		{
+			name: "unlock without a reason",
+			cmd:  func(c helm.Interface, out *bytes.Buffer) *cobra.Command { return newReleaseUnlockCmd(c, out) },
+			args: []string{"aeneas"},
+			err:  true,
+		},
+		{
+			name:  "no release",
+			cmd:   func(c helm.Interface, out *bytes.Buffer) *cobra.Command { return newReleaseUnlockCmd(c, out) },
+			flags: []string{"--reason", "freeze is over"},
+			err:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		c := &fakeReleaseClient{rels: []*release.Release{releaseMock(&releaseOptions{name: "aeneas"})}}
+		out := bytes.NewBuffer(nil)
+		cmd := tt.cmd(c, out)
+		cmd.ParseFlags(tt.flags)
+		err := cmd.RunE(cmd, tt.args)
+		if (err != nil) != tt.err {
+			t.Errorf("%q: expected error %v, got %v", tt.name, tt.err, err)
+			continue
+		}
+		if out.String() != tt.expect {
+			t.Errorf("%q: expected %q, got %q", tt.name, tt.expect, out.String())
+		}
+	}
+}
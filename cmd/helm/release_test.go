@@ -0,0 +1,103 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+func TestReleaseExportImport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-release-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// History comes latest first.
+	c := &fakeReleaseClient{rels: []*release.Release{
+		releaseMock(&releaseOptions{name: "angry-bird", version: 2}),
+		releaseMock(&releaseOptions{name: "angry-bird", version: 1, statusCode: release.Status_SUPERSEDED}),
+	}}
+	var buf bytes.Buffer
+	cmd := newReleaseExportCmd(c, &buf)
+	cmd.ParseFlags([]string{"-d", dir})
+	if err := cmd.RunE(cmd, []string{"angry-bird"}); err != nil {
+		t.Fatal(err)
+	}
+	archive := filepath.Join(dir, "angry-bird.release.tgz")
+	if expect := "Exported 2 revisions of angry-bird to " + archive + "\n"; buf.String() != expect {
+		t.Errorf("expected %q, got %q", expect, buf.String())
+	}
+
+	buf.Reset()
+	imported := &fakeReleaseClient{}
+	cmd = newReleaseImportCmd(imported, &buf)
+	cmd.ParseFlags([]string{"--apply"})
+	if err := cmd.RunE(cmd, []string{archive}); err != nil {
+		t.Fatal(err)
+	}
+	if expect := "Imported 2 revisions of angry-bird\nCreated the resources of revision 2 in namespace \n"; buf.String() != expect {
+		t.Errorf("expected %q, got %q", expect, buf.String())
+	}
+	if len(imported.rels) != 2 || imported.rels[0].Version != 1 || imported.rels[1].Version != 2 {
+		t.Fatalf("expected revisions 1 and 2, oldest first, got %v", imported.rels)
+	}
+	if got := imported.rels[1]; got.Manifest != mockManifest || got.Chart.Metadata.Name != "foo" || got.Config.Raw != `name: "value"` {
+		t.Errorf("revision was not exported in full: %v", got)
+	}
+}
+
+func TestReadReleaseArchiveErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  map[string]string
+		expect string
+	}{
+		{"no manifest", map[string]string{"README": "hi"}, "no release.yaml found"},
+		{"no revisions", map[string]string{"release.yaml": "name: angry-bird\n"}, `no revisions of "angry-bird" found`},
+		{"missing revision", map[string]string{"release.yaml": "name: angry-bird\nrevisions: [1]\n"}, `revision 1 of "angry-bird" is missing`},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(zw)
+		for name, data := range tt.files {
+			tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(data))})
+			tw.Write([]byte(data))
+		}
+		tw.Close()
+		zw.Close()
+
+		_, _, err := readReleaseArchive(&buf)
+		if err == nil || err.Error() != tt.expect {
+			t.Errorf("%s: expected %q, got %v", tt.name, tt.expect, err)
+		}
+	}
+
+	if _, _, err := readReleaseArchive(strings.NewReader("not an archive")); err == nil {
+		t.Error("expected an error for a file that is not an archive")
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package main
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"path/filepath"
@@ -28,12 +29,47 @@ import (
 	"k8s.io/helm/pkg/repo"
 )
 
+const repoAddDesc = `
+Add a chart repository, and download its index.
+
+Adding a repository that is already registered with the same URL refreshes its
+index, so that scripts can add the repositories they need without checking for
+them first. Adding a name that is registered with another URL fails, unless
+'--force-update' is given to replace it.
+
+The repositories file is locked while it is changed, so concurrent helm
+processes can safely add repositories.
+
+With '--verify-index', the repository's index is only used if it comes with a
+detached signature (index.yaml.sig) made by a key in the keyring given by
+'--keyring'. The setting is remembered, so 'helm repo update' verifies every
+later download of the index too.
+
+'--verify-charts' sets how the charts of the repository are verified whenever
+they are downloaded, by 'helm fetch', 'helm install', 'helm upgrade' and the
+other commands, without passing '--verify' to each of them:
+
+    always      refuse charts that do not verify, or have no provenance file
+    ifpresent   verify the charts that have a provenance file
+    never       only verify charts when a command is told to
+
+The index and the charts of a repository added with '--verify-index',
+'--verify-charts' or '--keyring' are verified with the keyring given by
+'--keyring', rather than with the default keyring of each command.
+`
+
 type repoAddCmd struct {
-	name     string
-	url      string
-	home     helmpath.Home
-	out      io.Writer
-	noupdate bool
+	name        string
+	url         string
+	home        helmpath.Home
+	out         io.Writer
+	noupdate    bool
+	forceUpdate bool
+
+	verifyIndex  bool
+	verifyCharts string
+	keyring      string
+	keyringSet   bool
 }
 
 func newRepoAddCmd(out io.Writer) *cobra.Command {
@@ -44,6 +80,7 @@ func newRepoAddCmd(out io.Writer) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "add [flags] [NAME] [URL]",
 		Short: "add a chart repository",
+		Long:  repoAddDesc,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := checkArgsLength(len(args), "name for the chart repository", "the url of the chart repository"); err != nil {
 				return err
@@ -52,21 +89,46 @@ func newRepoAddCmd(out io.Writer) *cobra.Command {
 			add.name = args[0]
 			add.url = args[1]
 			add.home = helmpath.Home(homePath())
+			add.keyringSet = cmd.Flags().Changed("keyring")
 
 			return add.run()
 		},
 	}
 	f := cmd.Flags()
 	f.BoolVar(&add.noupdate, "no-update", false, "raise error if repo is already registered")
+	f.BoolVar(&add.forceUpdate, "force-update", false, "replace the repo if it is already registered with another URL")
+	f.BoolVar(&add.verifyIndex, "verify-index", false, "require the repository index to be signed by a key in the keyring")
+	f.StringVar(&add.verifyCharts, "verify-charts", "", "how to verify the charts of the repository: always, ifpresent or never")
+	f.StringVar(&add.keyring, "keyring", defaultKeyring(), "keyring containing the public keys the index and charts may be signed with")
 	return cmd
 }
 
 func (a *repoAddCmd) run() error {
+	re := &repo.Entry{
+		Name: a.name,
+		URL:  a.url,
+	}
+	switch a.verifyCharts {
+	case "", repo.VerifyAlways, repo.VerifyIfPresent, repo.VerifyNever:
+		re.Verify = a.verifyCharts
+	default:
+		return fmt.Errorf("invalid --verify-charts %q: must be one of always, ifpresent or never", a.verifyCharts)
+	}
+	re.VerifyIndex = a.verifyIndex
+	if a.verifyIndex || a.verifyCharts != "" || a.keyringSet {
+		re.Keyring = a.keyring
+	}
+
 	var err error
-	if a.noupdate {
-		err = addRepository(a.name, a.url, a.home)
-	} else {
-		err = updateRepository(a.name, a.url, a.home)
+	switch {
+	case a.noupdate && a.forceUpdate:
+		return errors.New("--no-update and --force-update cannot be used together")
+	case a.noupdate:
+		err = addRepository(re, a.home)
+	case a.forceUpdate:
+		err = updateRepository(re, a.home)
+	default:
+		err = ensureRepository(re, a.home)
 	}
 	if err != nil {
 		return err
@@ -75,54 +137,95 @@ func (a *repoAddCmd) run() error {
 	return nil
 }
 
-func addRepository(name, url string, home helmpath.Home) error {
-	cif := home.CacheIndex(name)
-	if err := repo.DownloadIndexFile(name, url, cif); err != nil {
-		return fmt.Errorf("Looks like %q is not a valid chart repository or cannot be reached: %s", url, err.Error())
+func addRepository(re *repo.Entry, home helmpath.Home) error {
+	cif := home.CacheIndex(re.Name)
+	if err := re.DownloadIndex(cif); err != nil {
+		return fmt.Errorf("Looks like %q is not a valid chart repository or cannot be reached: %s", re.URL, err.Error())
 	}
 
-	return insertRepoLine(name, url, home)
+	return insertRepoLine(re, home)
 }
 
-func insertRepoLine(name, url string, home helmpath.Home) error {
-	cif := home.CacheIndex(name)
-	f, err := repo.LoadRepositoriesFile(home.RepositoryFile())
-	if err != nil {
-		return err
-	}
-
-	if f.Has(name) {
-		return fmt.Errorf("The repository name you provided (%s) already exists. Please specify a different name.", name)
-	}
-	f.Add(&repo.Entry{
-		Name:  name,
-		URL:   strings.TrimSuffix(url, "/"),
-		Cache: filepath.Base(cif),
+func insertRepoLine(re *repo.Entry, home helmpath.Home) error {
+	cif := home.CacheIndex(re.Name)
+	return repo.UpdateRepositoriesFile(home.RepositoryFile(), func(f *repo.RepoFile) error {
+		if f.Has(re.Name) {
+			return fmt.Errorf("The repository name you provided (%s) already exists. Please specify a different name.", re.Name)
+		}
+		f.Add(&repo.Entry{
+			Name:        re.Name,
+			URL:         strings.TrimSuffix(re.URL, "/"),
+			Cache:       filepath.Base(cif),
+			VerifyIndex: re.VerifyIndex,
+			Keyring:     re.Keyring,
+			Verify:      re.Verify,
+		})
+		return nil
 	})
-	return f.WriteFile(home.RepositoryFile(), 0644)
 }
 
-func updateRepository(name, url string, home helmpath.Home) error {
-	cif := home.CacheIndex(name)
-	if err := repo.DownloadIndexFile(name, url, cif); err != nil {
+func updateRepository(re *repo.Entry, home helmpath.Home) error {
+	cif := home.CacheIndex(re.Name)
+	if err := re.DownloadIndex(cif); err != nil {
 		return err
 	}
 
-	return updateRepoLine(name, url, home)
+	return updateRepoLine(re, home)
 }
 
-func updateRepoLine(name, url string, home helmpath.Home) error {
-	cif := home.CacheIndex(name)
-	f, err := repo.LoadRepositoriesFile(home.RepositoryFile())
-	if err != nil {
-		return err
+func updateRepoLine(re *repo.Entry, home helmpath.Home) error {
+	cif := home.CacheIndex(re.Name)
+	return repo.UpdateRepositoriesFile(home.RepositoryFile(), func(f *repo.RepoFile) error {
+		f.Update(&repo.Entry{
+			Name:        re.Name,
+			URL:         re.URL,
+			Cache:       filepath.Base(cif),
+			VerifyIndex: re.VerifyIndex,
+			Keyring:     re.Keyring,
+			Verify:      re.Verify,
+		})
+		return nil
+	})
+}
+
+// ensureRepository adds a repository, unless it is already registered with
+// the same URL, in which case only its index is refreshed.
+func ensureRepository(re *repo.Entry, home helmpath.Home) error {
+	// Fail before downloading anything if the name is already taken. The
+	// check is repeated under the lock, in case another helm took it since.
+	if f, err := repo.LoadRepositoriesFile(home.RepositoryFile()); err == nil {
+		if err := checkRepoURL(f, re.Name, re.URL); err != nil {
+			return err
+		}
+	}
+
+	cif := home.CacheIndex(re.Name)
+	if err := re.DownloadIndex(cif); err != nil {
+		return fmt.Errorf("Looks like %q is not a valid chart repository or cannot be reached: %s", re.URL, err.Error())
 	}
 
-	f.Update(&repo.Entry{
-		Name:  name,
-		URL:   url,
-		Cache: filepath.Base(cif),
+	return repo.UpdateRepositoriesFile(home.RepositoryFile(), func(f *repo.RepoFile) error {
+		if err := checkRepoURL(f, re.Name, re.URL); err != nil {
+			return err
+		}
+		f.Update(&repo.Entry{
+			Name:        re.Name,
+			URL:         strings.TrimSuffix(re.URL, "/"),
+			Cache:       filepath.Base(cif),
+			VerifyIndex: re.VerifyIndex,
+			Keyring:     re.Keyring,
+			Verify:      re.Verify,
+		})
+		return nil
 	})
+}
 
-	return f.WriteFile(home.RepositoryFile(), 0666)
+// checkRepoURL returns an error if name is registered with a URL other than url.
+func checkRepoURL(f *repo.RepoFile, name, url string) error {
+	for _, re := range f.Repositories {
+		if re.Name == name && strings.TrimSuffix(re.URL, "/") != strings.TrimSuffix(url, "/") {
+			return fmt.Errorf("repository %q is already registered with URL %s. Use --force-update to replace it", name, re.URL)
+		}
+	}
+	return nil
 }
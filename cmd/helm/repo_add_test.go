@@ -18,7 +18,10 @@ package main
 
 import (
 	"bytes"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"k8s.io/helm/cmd/helm/helmpath"
@@ -80,7 +83,7 @@ func TestRepoAdd(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if err := addRepository(testName, ts.URL(), hh); err != nil {
+	if err := addRepository(&repo.Entry{Name: testName, URL: ts.URL()}, hh); err != nil {
 		t.Error(err)
 	}
 
@@ -93,11 +96,161 @@ func TestRepoAdd(t *testing.T) {
 		t.Errorf("%s was not successfully inserted into %s", testName, hh.RepositoryFile())
 	}
 
-	if err := updateRepository(testName, ts.URL(), hh); err != nil {
+	if err := updateRepository(&repo.Entry{Name: testName, URL: ts.URL()}, hh); err != nil {
 		t.Errorf("Repository was not updated: %s", err)
 	}
 
-	if err := addRepository(testName, ts.URL(), hh); err == nil {
+	if err := addRepository(&repo.Entry{Name: testName, URL: ts.URL()}, hh); err == nil {
 		t.Errorf("Duplicate repository name was added")
 	}
 }
+
+func TestRepoAddExisting(t *testing.T) {
+	ts, thome, err := repotest.NewTempServer("testdata/testserver/*.*")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	oldhome := homePath()
+	helmHome = thome
+	hh := helmpath.Home(thome)
+	defer func() {
+		ts.Stop()
+		helmHome = oldhome
+		os.Remove(thome)
+	}()
+	if err := ensureTestHome(hh, t); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ensureRepository(&repo.Entry{Name: testName, URL: ts.URL()}, hh); err != nil {
+		t.Fatal(err)
+	}
+	if err := ensureRepository(&repo.Entry{Name: testName, URL: ts.URL() + "/"}, hh); err != nil {
+		t.Errorf("expected re-adding the same URL to succeed, got %s", err)
+	}
+
+	add := &repoAddCmd{name: testName, url: ts.URL() + "/other", home: hh, out: bytes.NewBuffer(nil)}
+	if err := add.run(); err == nil || !strings.Contains(err.Error(), "--force-update") {
+		t.Errorf("expected a different URL to be rejected, got %v", err)
+	}
+
+	add.url = ts.URL()
+	add.noupdate, add.forceUpdate = true, true
+	if err := add.run(); err == nil {
+		t.Error("expected --no-update and --force-update to be rejected together")
+	}
+
+	add.noupdate = false
+	if err := add.run(); err != nil {
+		t.Errorf("expected --force-update to succeed, got %s", err)
+	}
+	f, err := repo.LoadRepositoriesFile(hh.RepositoryFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for _, r := range f.Repositories {
+		if r.Name == testName {
+			n++
+		}
+	}
+	if n != 1 {
+		t.Errorf("expected one %s entry, got %d", testName, n)
+	}
+}
+
+func TestRepoAddVerifyIndex(t *testing.T) {
+	ts, thome, err := repotest.NewTempServer("testdata/testserver/*.*")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	oldhome := homePath()
+	helmHome = thome
+	hh := helmpath.Home(thome)
+	defer func() {
+		ts.Stop()
+		helmHome = oldhome
+		os.Remove(thome)
+	}()
+	if err := ensureTestHome(hh, t); err != nil {
+		t.Fatal(err)
+	}
+
+	indexFile := filepath.Join(ts.Root(), "index.yaml")
+	if err := signIndex(indexFile, "testdata/helm-test-key.secret", "helm-test"); err != nil {
+		t.Fatal(err)
+	}
+
+	re := &repo.Entry{Name: testName, URL: ts.URL(), VerifyIndex: true, Keyring: "testdata/helm-test-key.pub"}
+	if err := ensureRepository(re, hh); err != nil {
+		t.Fatal(err)
+	}
+	f, err := repo.LoadRepositoriesFile(hh.RepositoryFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range f.Repositories {
+		if r.Name == testName && (!r.VerifyIndex || r.Keyring != re.Keyring) {
+			t.Errorf("expected the verification settings to be saved, got %+v", r)
+		}
+	}
+
+	// Tamper with the index after it was signed.
+	fi, err := os.OpenFile(indexFile, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fi.WriteString("\n# tampered\n")
+	fi.Close()
+
+	buf := bytes.NewBuffer(nil)
+	updateCharts(f.Repositories, false, buf, hh)
+	if !strings.Contains(buf.String(), "failed verification") {
+		t.Errorf("expected repo update to reject the tampered index, got %q", buf.String())
+	}
+	if err := ensureRepository(re, hh); err == nil {
+		t.Error("expected re-adding the repository to reject the tampered index")
+	}
+}
+
+func TestRepoAddVerifyCharts(t *testing.T) {
+	srv, thome, err := repotest.NewTempServer("testdata/testserver/*.*")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	oldhome := homePath()
+	helmHome = thome
+	hh := helmpath.Home(thome)
+	defer func() {
+		srv.Stop()
+		helmHome = oldhome
+		os.Remove(thome)
+	}()
+	if err := ensureTestHome(hh, t); err != nil {
+		t.Fatal(err)
+	}
+
+	c := newRepoAddCmd(ioutil.Discard)
+	c.ParseFlags([]string{"--verify-charts", "sometimes"})
+	if err := c.RunE(c, []string{testName, srv.URL()}); err == nil || !strings.Contains(err.Error(), "invalid --verify-charts") {
+		t.Errorf("expected an invalid policy to be refused, got %v", err)
+	}
+
+	c = newRepoAddCmd(ioutil.Discard)
+	c.ParseFlags([]string{"--verify-charts", "always", "--keyring", "testdata/helm-test-key.pub"})
+	if err := c.RunE(c, []string{testName, srv.URL()}); err != nil {
+		t.Fatal(err)
+	}
+	f, err := repo.LoadRepositoriesFile(hh.RepositoryFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range f.Repositories {
+		if r.Name == testName && (r.Verify != repo.VerifyAlways || r.Keyring != "testdata/helm-test-key.pub" || r.VerifyIndex) {
+			t.Errorf("expected the verification settings to be saved, got %+v", r)
+		}
+	}
+}
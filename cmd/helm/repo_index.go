@@ -17,12 +17,16 @@ limitations under the License.
 package main
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"path/filepath"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"k8s.io/helm/pkg/provenance"
 	"k8s.io/helm/pkg/repo"
 )
 
@@ -35,13 +39,39 @@ set an absolute URL to the charts, use '--url' flag.
 To merge the generated index with an existing index file, use the '--merge'
 flag. In this case, the charts found in the current directory will be merged
 into the existing index, with local charts taking priority over existing charts.
+
+Every generated index gets a version one higher than the index it replaces (or
+the index it is merged into), and '--expires' sets how long the index is valid
+for. Clients refuse an index that is older than the last one they saw, or that
+has expired, so an expiring index must be regenerated and republished
+regularly.
+
+With '--from-registry', the charts stored in a namespace of an OCI registry
+are indexed instead of the charts in DIR, and the index is written to DIR.
+Each chart points at the URL of its archive in the registry, so the registry
+must allow anonymous pulls for classic repository clients to download it.
+
+To sign the index, use the '--sign' flag with the name of a private key in the
+given keyring. A detached signature is written to 'index.yaml.sig', which must be
+published alongside 'index.yaml' for users who add the repository with
+'helm repo add --verify-index'.
 `
 
 type repoIndexCmd struct {
-	dir   string
-	url   string
-	out   io.Writer
-	merge string
+	dir     string
+	url     string
+	out     io.Writer
+	merge   string
+	expires time.Duration
+
+	sign    bool
+	key     string
+	keyring string
+
+	registry  string
+	username  string
+	password  string
+	plainHTTP bool
 }
 
 func newRepoIndexCmd(out io.Writer) *cobra.Command {
@@ -67,6 +97,14 @@ func newRepoIndexCmd(out io.Writer) *cobra.Command {
 	f := cmd.Flags()
 	f.StringVar(&index.url, "url", "", "url of chart repository")
 	f.StringVar(&index.merge, "merge", "", "merge the generated index into the given index")
+	f.DurationVar(&index.expires, "expires", 0, "how long the index is valid for, e.g. 720h. By default it does not expire")
+	f.BoolVar(&index.sign, "sign", false, "use a PGP private key to sign the index")
+	f.StringVar(&index.key, "key", "", "name of the key to use when signing. Used if --sign is true")
+	f.StringVar(&index.keyring, "keyring", defaultKeyring(), "location of a public keyring")
+	f.StringVar(&index.registry, "from-registry", "", "index the charts stored in an OCI registry, e.g. oci://registry.example.com/project")
+	f.StringVar(&index.username, "registry-username", "", "username to authenticate to the registry with")
+	f.StringVar(&index.password, "registry-password", "", "password to authenticate to the registry with")
+	f.BoolVar(&index.plainHTTP, "plain-http", false, "reach the registry over HTTP rather than HTTPS")
 
 	return cmd
 }
@@ -77,23 +115,85 @@ func (i *repoIndexCmd) run() error {
 		return err
 	}
 
-	return index(path, i.url, i.merge)
-}
+	if i.sign && i.key == "" {
+		return errors.New("--key is required for signing an index")
+	}
 
-func index(dir, url, mergeTo string) error {
-	out := filepath.Join(dir, "index.yaml")
+	if i.registry != "" {
+		if i.url != "" {
+			return errors.New("--url cannot be used with --from-registry")
+		}
+		r, err := repo.NewRegistry(i.registry)
+		if err != nil {
+			return err
+		}
+		r.Username, r.Password, r.PlainHTTP = i.username, i.password, i.plainHTTP
+		idx, err := r.Index()
+		if err != nil {
+			return err
+		}
+		if err := writeIndex(filepath.Join(path, "index.yaml"), idx, i.merge, i.expires); err != nil {
+			return err
+		}
+	} else if err := index(path, i.url, i.merge, i.expires); err != nil {
+		return err
+	}
+	if i.sign {
+		return signIndex(filepath.Join(path, "index.yaml"), i.keyring, i.key)
+	}
+	return nil
+}
 
+func index(dir, url, mergeTo string, expires time.Duration) error {
 	i, err := repo.IndexDirectory(dir, url)
 	if err != nil {
 		return err
 	}
+	return writeIndex(filepath.Join(dir, "index.yaml"), i, mergeTo, expires)
+}
+
+// writeIndex writes the index i to out, versioned after the index already
+// there and merged into the index at mergeTo, if set.
+func writeIndex(out string, i *repo.IndexFile, mergeTo string, expires time.Duration) error {
+	if prev, err := repo.LoadIndexFile(out); err == nil {
+		i.Version = prev.Version
+	}
 	if mergeTo != "" {
 		i2, err := repo.LoadIndexFile(mergeTo)
 		if err != nil {
 			return fmt.Errorf("Merge failed: %s", err)
 		}
 		i.Merge(i2)
+		if i2.Version > i.Version {
+			i.Version = i2.Version
+		}
+	}
+	i.Version++
+	if expires > 0 {
+		i.Expires = i.Generated.Add(expires)
 	}
 	i.SortEntries()
 	return i.WriteFile(out, 0755)
 }
+
+// signIndex writes a detached signature of the index file at path to
+// path+repo.IndexSignatureSuffix.
+func signIndex(path, keyring, key string) error {
+	signer, err := provenance.NewFromKeyring(keyring, key)
+	if err != nil {
+		return err
+	}
+	if err := signer.DecryptKey(promptUser); err != nil {
+		return err
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	sig, err := signer.DetachSign(b)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path+repo.IndexSignatureSuffix, []byte(sig), 0644)
+}
@@ -23,6 +23,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"k8s.io/helm/pkg/repo"
 )
@@ -71,6 +72,9 @@ func TestRepoIndexCmd(t *testing.T) {
 	if vs[0].Version != expectedVersion {
 		t.Errorf("expected %q, got %q", expectedVersion, vs[0].Version)
 	}
+	if index.Version != 1 || !index.Expires.IsZero() {
+		t.Errorf("expected a first, non-expiring index version, got %d expiring %s", index.Version, index.Expires)
+	}
 
 	// Test with `--merge`
 
@@ -89,7 +93,7 @@ func TestRepoIndexCmd(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	c.ParseFlags([]string{"--merge", destIndex})
+	c.ParseFlags([]string{"--merge", destIndex, "--expires", "24h"})
 	if err := c.RunE(c, []string{dir}); err != nil {
 		t.Error(err)
 	}
@@ -112,6 +116,12 @@ func TestRepoIndexCmd(t *testing.T) {
 	if vs[0].Version != expectedVersion {
 		t.Errorf("expected %q, got %q", expectedVersion, vs[0].Version)
 	}
+	if index.Version != 2 {
+		t.Errorf("expected the index version to be bumped to 2, got %d", index.Version)
+	}
+	if want := index.Generated.Add(24 * time.Hour); !index.Expires.Equal(want) {
+		t.Errorf("expected the index to expire at %s, got %s", want, index.Expires)
+	}
 }
 
 func linkOrCopy(old, new string) error {
@@ -139,3 +149,11 @@ func copyFile(dst, src string) error {
 
 	return err
 }
+
+func TestRepoIndexCmdFromRegistryWithURL(t *testing.T) {
+	c := newRepoIndexCmd(bytes.NewBuffer(nil))
+	c.ParseFlags([]string{"--from-registry", "oci://registry.example.com/project", "--url", "https://charts.example.com"})
+	if err := c.RunE(c, []string{"."}); err == nil || err.Error() != "--url cannot be used with --from-registry" {
+		t.Errorf("expected --url to be refused, got %v", err)
+	}
+}
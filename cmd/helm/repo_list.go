@@ -20,6 +20,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"sync"
+	"time"
 
 	"github.com/gosuri/uitable"
 	"github.com/spf13/cobra"
@@ -28,9 +30,37 @@ import (
 	"k8s.io/helm/pkg/repo"
 )
 
+const repoListDesc = `
+List the chart repositories that have been added with 'helm repo add'.
+
+With '--check', each repository is probed: its index must be reachable, over
+a TLS connection with a valid certificate when it is served over HTTPS, the
+server must accept any credentials in its URL, the index must parse and must
+not have expired, and the index of a repository added with '--verify-index'
+must be signed. '--max-age' also fails repositories whose index was generated
+longer ago than that. The status of every repository is shown, and the
+command fails if any of them is unhealthy:
+
+	$ helm repo list --check --max-age 168h
+`
+
 type repoListCmd struct {
-	out  io.Writer
-	home helmpath.Home
+	out    io.Writer
+	home   helmpath.Home
+	output string
+	check  bool
+	maxAge time.Duration
+}
+
+// repoListEntry is a repository in the machine readable output of
+// 'helm repo list'.
+type repoListEntry struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// Status, Error and Generated are only set with --check.
+	Status    string     `json:"status,omitempty"`
+	Error     string     `json:"error,omitempty"`
+	Generated *time.Time `json:"generated,omitempty"`
 }
 
 func newRepoListCmd(out io.Writer) *cobra.Command {
@@ -41,29 +71,126 @@ func newRepoListCmd(out io.Writer) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "list [flags]",
 		Short: "list chart repositories",
+		Long:  repoListDesc,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			list.home = helmpath.Home(homePath())
 			return list.run()
 		},
 	}
+	f := cmd.Flags()
+	addOutputFlag(f, &list.output, "o", "table")
+	f.BoolVar(&list.check, "check", false, "probe each repository and show whether it is healthy")
+	f.DurationVar(&list.maxAge, "max-age", 0, "with --check, fail repositories whose index was generated longer ago than this")
 
 	return cmd
 }
 
 func (a *repoListCmd) run() error {
+	format, err := parseOutputFormat(a.output, "table")
+	if err != nil {
+		return err
+	}
+
 	f, err := repo.LoadRepositoriesFile(a.home.RepositoryFile())
 	if err != nil {
 		return err
 	}
+
+	var health []repo.Health
+	if a.check {
+		health = checkRepos(f.Repositories, a.maxAge)
+	}
+
+	if !format.human() {
+		entries := []repoListEntry{}
+		for i, re := range f.Repositories {
+			e := repoListEntry{Name: re.Name, URL: re.URL}
+			if a.check {
+				e.Status, e.Error = healthStatus(health[i])
+				if g := health[i].Generated; !g.IsZero() {
+					e.Generated = &g
+				}
+			}
+			entries = append(entries, e)
+		}
+		if err := format.write(a.out, entries); err != nil {
+			return err
+		}
+		return unhealthy(health)
+	}
 	if len(f.Repositories) == 0 {
 		return errors.New("no repositories to show")
 	}
 	table := uitable.New()
 	table.MaxColWidth = 50
-	table.AddRow("NAME", "URL")
-	for _, re := range f.Repositories {
-		table.AddRow(re.Name, re.URL)
+	if !a.check {
+		table.AddRow("NAME", "URL")
+		for _, re := range f.Repositories {
+			table.AddRow(re.Name, re.URL)
+		}
+		fmt.Fprintln(a.out, table)
+		return nil
+	}
+	table.AddRow("NAME", "URL", "STATUS", "INDEX AGE", "ERROR")
+	for i, re := range f.Repositories {
+		status, msg := healthStatus(health[i])
+		age := "unknown"
+		if g := health[i].Generated; !g.IsZero() {
+			age = shortDuration(time.Since(g))
+		}
+		table.AddRow(re.Name, re.URL, status, age, msg)
 	}
 	fmt.Fprintln(a.out, table)
+	return unhealthy(health)
+}
+
+// checkRepos checks all of repos at once, and returns their health in the
+// same order.
+func checkRepos(repos []*repo.Entry, maxAge time.Duration) []repo.Health {
+	health := make([]repo.Health, len(repos))
+	var wg sync.WaitGroup
+	for i, re := range repos {
+		wg.Add(1)
+		go func(i int, re *repo.Entry) {
+			defer wg.Done()
+			health[i] = re.Check(maxAge)
+		}(i, re)
+	}
+	wg.Wait()
+	return health
+}
+
+func healthStatus(h repo.Health) (string, string) {
+	if h.Err != nil {
+		return "failed", h.Err.Error()
+	}
+	return "ok", ""
+}
+
+// unhealthy returns an error if any of the repositories is unhealthy.
+func unhealthy(health []repo.Health) error {
+	n := 0
+	for _, h := range health {
+		if h.Err != nil {
+			n++
+		}
+	}
+	if n > 0 {
+		return fmt.Errorf("%d of %d repositories failed the check", n, len(health))
+	}
 	return nil
 }
+
+// shortDuration formats d in its largest whole unit, e.g. "40s", "12m", "5h"
+// or "3d".
+func shortDuration(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", d/time.Second)
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	}
+	return fmt.Sprintf("%dd", d/(24*time.Hour))
+}
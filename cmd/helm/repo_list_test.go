@@ -0,0 +1,78 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"k8s.io/helm/cmd/helm/helmpath"
+	"k8s.io/helm/pkg/repo"
+	"k8s.io/helm/pkg/repo/repotest"
+)
+
+func TestRepoListCheck(t *testing.T) {
+	srv, thome, err := repotest.NewTempServer("testdata/testserver/*.*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		srv.Stop()
+		os.RemoveAll(thome)
+	}()
+	home := helmpath.Home(thome)
+	if err := ensureTestHome(home, t); err != nil {
+		t.Fatal(err)
+	}
+
+	rf := repo.NewRepoFile()
+	rf.Add(&repo.Entry{Name: "good", URL: srv.URL()})
+	if err := rf.WriteFile(home.RepositoryFile(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := bytes.NewBuffer(nil)
+	list := &repoListCmd{out: out, home: home, output: "table", check: true}
+	if err := list.run(); err != nil {
+		t.Fatalf("expected a healthy repository to pass: %s\n%s", err, out)
+	}
+	if !strings.Contains(out.String(), "STATUS") || !strings.Contains(out.String(), "ok") {
+		t.Errorf("expected the status of the repository, got %q", out)
+	}
+
+	rf.Add(&repo.Entry{Name: "bad", URL: srv.URL() + "/no-such-repo"})
+	if err := rf.WriteFile(home.RepositoryFile(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out.Reset()
+	list = &repoListCmd{out: out, home: home, output: "json", check: true}
+	err = list.run()
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 repositories failed") {
+		t.Errorf("expected the broken repository to fail the check, got %v", err)
+	}
+	var entries []repoListEntry
+	if err := json.Unmarshal(out.Bytes(), &entries); err != nil {
+		t.Fatalf("%s: %q", err, out)
+	}
+	if len(entries) != 2 || entries[0].Status != "ok" || entries[1].Status != "failed" || !strings.Contains(entries[1].Error, "404") {
+		t.Errorf("unexpected entries %+v", entries)
+	}
+}
@@ -61,19 +61,16 @@ func (r *repoRemoveCmd) run() error {
 }
 
 func removeRepoLine(out io.Writer, name string, home helmpath.Home) error {
-	repoFile := home.RepositoryFile()
-	r, err := repo.LoadRepositoriesFile(repoFile)
+	err := repo.UpdateRepositoriesFile(home.RepositoryFile(), func(r *repo.RepoFile) error {
+		if !r.Remove(name) {
+			return fmt.Errorf("no repo named %q found", name)
+		}
+		return nil
+	})
 	if err != nil {
 		return err
 	}
 
-	if !r.Remove(name) {
-		return fmt.Errorf("no repo named %q found", name)
-	}
-	if err := r.WriteFile(repoFile, 0644); err != nil {
-		return err
-	}
-
 	if err := removeRepoCache(name, home); err != nil {
 		return err
 	}
@@ -90,5 +87,6 @@ func removeRepoCache(name string, home helmpath.Home) error {
 			return err
 		}
 	}
+	os.Remove(home.CacheIndex(name) + repo.IndexCacheSuffix)
 	return nil
 }
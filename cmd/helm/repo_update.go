@@ -79,19 +79,19 @@ func updateCharts(repos []*repo.Entry, verbose bool, out io.Writer, home helmpat
 	var wg sync.WaitGroup
 	for _, re := range repos {
 		wg.Add(1)
-		go func(n, u string) {
+		go func(re *repo.Entry) {
 			defer wg.Done()
-			if n == localRepository {
+			if re.Name == localRepository {
 				// We skip local because the indices are symlinked.
 				return
 			}
-			err := repo.DownloadIndexFile(n, u, home.CacheIndex(n))
+			err := re.DownloadIndex(home.CacheIndex(re.Name))
 			if err != nil {
-				fmt.Fprintf(out, "...Unable to get an update from the %q chart repository (%s):\n\t%s\n", n, u, err)
+				fmt.Fprintf(out, "...Unable to get an update from the %q chart repository (%s):\n\t%s\n", re.Name, re.URL, err)
 			} else {
-				fmt.Fprintf(out, "...Successfully got an update from the %q chart repository\n", n)
+				fmt.Fprintf(out, "...Successfully got an update from the %q chart repository\n", re.Name)
 			}
-		}(re.Name, re.URL)
+		}(re)
 	}
 	wg.Wait()
 	fmt.Fprintln(out, "Update Complete. ⎈ Happy Helming!⎈ ")
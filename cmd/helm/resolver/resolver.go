@@ -25,6 +25,7 @@ import (
 	"github.com/Masterminds/semver"
 
 	"k8s.io/helm/cmd/helm/helmpath"
+	"k8s.io/helm/pkg/chartref"
 	"k8s.io/helm/pkg/chartutil"
 	"k8s.io/helm/pkg/provenance"
 	"k8s.io/helm/pkg/repo"
@@ -60,13 +61,20 @@ func (r *Resolver) Resolve(reqs *chartutil.Requirements, repoNames map[string]st
 			return nil, fmt.Errorf("dependency %q has an invalid version/constraint format: %s", d.Name, err)
 		}
 
-		repoIndex, err := repo.LoadIndexFile(r.helmhome.CacheIndex(repoNames[d.Name]))
+		repoIndex, err := repo.LoadCachedIndexFile(r.helmhome.CacheIndex(repoNames[d.Name]))
 		if err != nil {
 			return nil, fmt.Errorf("no cached repo found. (try 'helm repo update'). %s", err)
 		}
 
 		vs, ok := repoIndex.Entries[d.Name]
 		if !ok {
+			names := make([]string, 0, len(repoIndex.Entries))
+			for n := range repoIndex.Entries {
+				names = append(names, n)
+			}
+			if s := chartref.Suggest(d.Name, names); s != "" {
+				return nil, fmt.Errorf("%s chart not found in repo %s. Did you mean %q?", d.Name, d.Repository, s)
+			}
 			return nil, fmt.Errorf("%s chart not found in repo %s", d.Name, d.Repository)
 		}
 
@@ -0,0 +1,145 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+const restoreDesc = `
+This command stores the releases in an archive written by 'helm backup' in
+Tiller.
+
+Every revision is checked against the checksum recorded in the backup first,
+and nothing is restored if any of them does not match. Releases that Tiller
+already has are skipped.
+
+By default only the release records are restored. With '--apply', the
+resources of the latest revision of each deployed release are created as well.
+`
+
+type restoreCmd struct {
+	file          string
+	apply         bool
+	overrideGates string
+	out           io.Writer
+	client        helm.Interface
+}
+
+func newRestoreCmd(client helm.Interface, out io.Writer) *cobra.Command {
+	r := &restoreCmd{out: out, client: client}
+
+	cmd := &cobra.Command{
+		Use:               "restore [flags] BACKUP",
+		Short:             "store the release records of a backup",
+		Long:              restoreDesc,
+		PersistentPreRunE: setupConnection,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkArgsLength(len(args), "backup"); err != nil {
+				return err
+			}
+			r.file = args[0]
+			if r.client == nil {
+				r.client = helm.NewClient(helm.Host(tillerHost), helm.UserToken(userToken), helm.Impersonate(asUser, asGroups), helm.RequestID(requestID))
+			}
+			return r.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&r.apply, "apply", false, "create the resources of the latest revision of each deployed release")
+	f.StringVar(&r.overrideGates, "override-gates", "", "apply the releases even if the deployment windows or gates of Tiller do not allow it, giving the reason for the change")
+
+	return cmd
+}
+
+func (r *restoreCmd) run() error {
+	f, err := os.Open(r.file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	m, rels, err := readBackup(f)
+	if err != nil {
+		return fmt.Errorf("could not read backup %s: %s", r.file, err)
+	}
+
+	failed := 0
+	for i, br := range m.Releases {
+		_, err := r.client.ImportRelease(rels[i], helm.ImportApply(r.apply), helm.ImportOverrideGates(r.overrideGates))
+		switch {
+		case err == nil:
+			fmt.Fprintf(r.out, "Restored %d revisions of %s\n", len(br.Revisions), br.Name)
+		case strings.Contains(err.Error(), "already exists"):
+			fmt.Fprintf(r.out, "Skipped %s, which already exists\n", br.Name)
+		default:
+			fmt.Fprintf(r.out, "Failed to restore %s: %s\n", br.Name, prettyError(err))
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d releases could not be restored", failed, len(m.Releases))
+	}
+	return nil
+}
+
+// readBackup reads an archive written by 'helm backup', returning the
+// revisions of each release in the order of the manifest. Every revision is
+// checked against its checksum.
+func readBackup(in io.Reader) (*backupManifest, [][]*release.Release, error) {
+	files, err := readArchive(in)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, ok := files[backupManifestName]
+	if !ok {
+		return nil, nil, fmt.Errorf("no %s found", backupManifestName)
+	}
+	m := &backupManifest{}
+	if err := yaml.Unmarshal(data, m); err != nil {
+		return nil, nil, fmt.Errorf("could not parse %s: %s", backupManifestName, err)
+	}
+
+	all := [][]*release.Release{}
+	for _, br := range m.Releases {
+		if len(br.Revisions) == 0 {
+			return nil, nil, fmt.Errorf("no revisions of %q found", br.Name)
+		}
+		rels := []*release.Release{}
+		for _, rev := range br.Revisions {
+			if sum := checksum(files[revisionFile(br.Name, rev.Revision)]); sum != rev.SHA256 {
+				return nil, nil, fmt.Errorf("revision %d of %q does not match its checksum", rev.Revision, br.Name)
+			}
+			rel, err := readRevision(files, br.Name, rev.Revision)
+			if err != nil {
+				return nil, nil, err
+			}
+			rels = append(rels, rel)
+		}
+		all = append(all, rels)
+	}
+	return m, all, nil
+}
@@ -32,12 +32,15 @@ The argument of the rollback command is the name of a release.
 `
 
 type rollbackCmd struct {
-	name         string
-	revision     int32
-	dryRun       bool
-	disableHooks bool
-	out          io.Writer
-	client       helm.Interface
+	name          string
+	revision      int32
+	dryRun        bool
+	disableHooks  bool
+	metadata      []string
+	overrideLock  string
+	overrideGates string
+	out           io.Writer
+	client        helm.Interface
 }
 
 func newRollbackCmd(c helm.Interface, out io.Writer) *cobra.Command {
@@ -72,16 +75,27 @@ func newRollbackCmd(c helm.Interface, out io.Writer) *cobra.Command {
 	f := cmd.Flags()
 	f.BoolVar(&rollback.dryRun, "dry-run", false, "simulate a rollback")
 	f.BoolVar(&rollback.disableHooks, "no-hooks", false, "prevent hooks from running during rollback")
+	f.StringSliceVar(&rollback.metadata, "metadata", []string{}, "record metadata on the new revision, such as the reason for the rollback: key1=val1,key2=val2")
+	f.StringVar(&rollback.overrideLock, "override-lock", "", "roll back the release even if it is locked, giving the reason for the change")
+	f.StringVar(&rollback.overrideGates, "override-gates", "", "roll back the release even if the deployment windows or gates of Tiller do not allow it, giving the reason for the change")
 
 	return cmd
 }
 
 func (r *rollbackCmd) run() error {
-	_, err := r.client.RollbackRelease(
+	metadata, err := parseMetadata(r.metadata)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.client.RollbackRelease(
 		r.name,
 		helm.RollbackDryRun(r.dryRun),
 		helm.RollbackDisableHooks(r.disableHooks),
 		helm.RollbackVersion(r.revision),
+		helm.RollbackMetadata(metadata),
+		helm.RollbackOverrideLock(r.overrideLock),
+		helm.RollbackOverrideGates(r.overrideGates),
 	)
 	if err != nil {
 		return prettyError(err)
@@ -19,6 +19,8 @@ package main
 import (
 	"fmt"
 	"io"
+	"os"
+	"path"
 	"strings"
 
 	"github.com/gosuri/uitable"
@@ -34,8 +36,19 @@ Search reads through all of the repositories configured on the system, and
 looks for matches.
 
 Repositories are managed with 'helm repo' commands.
+
+With '--endpoint' (or $HELM_SEARCH_ENDPOINT), search also queries a remote
+API that aggregates charts from many repositories, so charts can be found in
+repositories that have not been added yet. Results found both locally and
+remotely are shown once, and a SOURCE column shows where each came from.
 `
 
+// searchEndpointEnvVar sets the default remote search API.
+const searchEndpointEnvVar = "HELM_SEARCH_ENDPOINT"
+
+// localSearchSource is the source of results from the local repository caches.
+const localSearchSource = "local"
+
 // searchMaxScore suggests that any score higher than this is not considered a match.
 const searchMaxScore = 25
 
@@ -45,6 +58,19 @@ type searchCmd struct {
 
 	versions bool
 	regexp   bool
+	output   string
+	endpoint string
+}
+
+// searchResult is a chart in the machine readable output of 'helm search'.
+type searchResult struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+	Deprecated  bool   `json:"deprecated,omitempty"`
+	ReplacedBy  string `json:"replacedBy,omitempty"`
+	Source      string `json:"source,omitempty"`
+	RepoURL     string `json:"repoURL,omitempty"`
 }
 
 func newSearchCmd(out io.Writer) *cobra.Command {
@@ -62,70 +88,148 @@ func newSearchCmd(out io.Writer) *cobra.Command {
 	f := cmd.Flags()
 	f.BoolVarP(&sc.regexp, "regexp", "r", false, "use regular expressions for searching")
 	f.BoolVarP(&sc.versions, "versions", "l", false, "show the long listing, with each version of each chart on its own line")
+	f.StringVar(&sc.endpoint, "endpoint", os.Getenv(searchEndpointEnvVar), "also search the remote search API at this URL. Overrides $"+searchEndpointEnvVar)
+	addOutputFlag(f, &sc.output, "o", "table")
 
 	return cmd
 }
 
 func (s *searchCmd) run(args []string) error {
-	index, err := s.buildIndex()
+	format, err := parseOutputFormat(s.output, "table")
 	if err != nil {
 		return err
 	}
 
-	if len(args) == 0 {
-		s.showAllCharts(index)
-		return nil
+	// Keep warnings out of machine readable output.
+	warn := s.out
+	if !format.human() {
+		warn = os.Stderr
+	}
+	index, repos, err := s.buildIndex(warn)
+	if err != nil {
+		return err
 	}
 
 	q := strings.Join(args, " ")
-	res, err := index.Search(q, searchMaxScore, s.regexp)
-	if err != nil {
-		return nil
+	var res []*search.Result
+	if len(args) == 0 {
+		res = index.All()
+	} else {
+		res, err = index.Search(q, searchMaxScore, s.regexp)
+		if err != nil {
+			return nil
+		}
+	}
+
+	federated := s.endpoint != ""
+	if federated {
+		for _, r := range res {
+			r.Source = localSearchSource
+			r.RepoURL = repos[path.Dir(r.Name)]
+		}
+		remote, err := search.NewHTTPProvider(s.endpoint).Search(q, searchMaxScore, s.regexp, s.versions)
+		if err != nil {
+			fmt.Fprintf(warn, "WARNING: %s\n", err)
+		}
+		res = search.Merge(res, localNames(remote, repos))
 	}
 	search.SortScore(res)
 
-	fmt.Fprintln(s.out, s.formatSearchResults(res))
+	if !format.human() {
+		return format.write(s.out, searchOutput(res))
+	}
+	fmt.Fprintln(s.out, s.formatSearchResults(res, federated))
 
 	return nil
 }
 
-func (s *searchCmd) showAllCharts(i *search.Index) {
-	res := i.All()
-	search.SortScore(res)
-	fmt.Fprintln(s.out, s.formatSearchResults(res))
+func searchOutput(res []*search.Result) []searchResult {
+	out := []searchResult{}
+	for _, r := range res {
+		out = append(out, searchResult{
+			Name:        r.Name,
+			Version:     r.Chart.Version,
+			Description: r.Chart.Description,
+			Deprecated:  r.Chart.Deprecated,
+			ReplacedBy:  r.Chart.ReplacedBy,
+			Source:      r.Source,
+			RepoURL:     r.RepoURL,
+		})
+	}
+	return out
 }
 
-func (s *searchCmd) formatSearchResults(res []*search.Result) string {
+// localNames renames remote results in repositories that are also configured
+// locally after the local repository, so that they can be installed by the
+// name shown.
+func localNames(res []*search.Result, repos map[string]string) []*search.Result {
+	names := map[string]string{}
+	for name, u := range repos {
+		names[strings.TrimSuffix(u, "/")] = name
+	}
+	for _, r := range res {
+		if name, ok := names[strings.TrimSuffix(r.RepoURL, "/")]; ok && r.RepoURL != "" {
+			r.Name = path.Join(name, r.Chart.Name)
+		}
+	}
+	return res
+}
+
+func (s *searchCmd) formatSearchResults(res []*search.Result, sources bool) string {
 	if len(res) == 0 {
 		return "No results found"
 	}
 	table := uitable.New()
 	table.MaxColWidth = 50
-	table.AddRow("NAME", "VERSION", "DESCRIPTION")
+	if sources {
+		table.AddRow("NAME", "VERSION", "SOURCE", "DESCRIPTION")
+	} else {
+		table.AddRow("NAME", "VERSION", "DESCRIPTION")
+	}
 	for _, r := range res {
-		table.AddRow(r.Name, r.Chart.Version, r.Chart.Description)
+		if sources {
+			table.AddRow(r.Name, r.Chart.Version, r.Source, searchDescription(r.Chart))
+		} else {
+			table.AddRow(r.Name, r.Chart.Version, searchDescription(r.Chart))
+		}
 	}
 	return table.String()
 }
 
-func (s *searchCmd) buildIndex() (*search.Index, error) {
+// searchDescription returns the description of a chart, flagged if the chart
+// is deprecated.
+func searchDescription(c *repo.ChartVersion) string {
+	if !c.Deprecated {
+		return c.Description
+	}
+	if c.ReplacedBy != "" {
+		return fmt.Sprintf("(DEPRECATED, use %s) %s", c.ReplacedBy, c.Description)
+	}
+	return "(DEPRECATED) " + c.Description
+}
+
+// buildIndex indexes the local repository caches, and returns the URLs of the
+// repositories by name.
+func (s *searchCmd) buildIndex(warn io.Writer) (*search.Index, map[string]string, error) {
 	// Load the repositories.yaml
 	rf, err := repo.LoadRepositoriesFile(s.helmhome.RepositoryFile())
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	i := search.NewIndex()
+	repos := map[string]string{}
 	for _, re := range rf.Repositories {
 		n := re.Name
+		repos[n] = re.URL
 		f := s.helmhome.CacheIndex(n)
-		ind, err := repo.LoadIndexFile(f)
+		ind, err := repo.LoadCachedIndexFile(f)
 		if err != nil {
-			fmt.Fprintf(s.out, "WARNING: Repo %q is corrupt or missing. Try 'helm repo update'.", n)
+			fmt.Fprintf(warn, "WARNING: Repo %q is corrupt, missing or expired. Try 'helm repo update'.", n)
 			continue
 		}
 
 		i.AddRepo(n, ind, s.versions)
 	}
-	return i, nil
+	return i, repos, nil
 }
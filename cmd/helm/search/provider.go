@@ -0,0 +1,190 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"k8s.io/helm/pkg/repo"
+)
+
+// Provider is a source of search results other than the local repository
+// caches, such as a remote API that aggregates many repositories.
+type Provider interface {
+	// Name identifies the provider in the results it returns.
+	Name() string
+	// Search returns the charts that match term, scored like Index.Search. An
+	// empty term matches every chart. If all is true, every version of each
+	// chart is returned rather than only the newest.
+	Search(term string, threshold int, regexp, all bool) ([]*Result, error)
+}
+
+// HTTPProvider searches a remote aggregation API.
+//
+// The API is queried with GET <endpoint>?q=<term>, adding regexp=true and
+// versions=true as needed, and must answer with a JSON document of the form
+//
+//	{"results": [{"repository": {"name": "stable", "url": "https://..."},
+//	              "chart": {"name": "mariadb", "version": "0.3.0", ...}}]}
+//
+// where each chart is an index entry as found in index.yaml. The API may
+// return more than what matches; results are filtered and scored locally, so
+// that they rank the same way as local ones.
+type HTTPProvider struct {
+	Endpoint string
+	Client   *http.Client
+}
+
+// NewHTTPProvider creates a provider for the API at endpoint.
+func NewHTTPProvider(endpoint string) *HTTPProvider {
+	return &HTTPProvider{
+		Endpoint: endpoint,
+		Client:   &http.Client{Transport: repo.Client().Transport, Timeout: 30 * time.Second},
+	}
+}
+
+// Name returns the host of the endpoint.
+func (p *HTTPProvider) Name() string {
+	if u, err := url.Parse(p.Endpoint); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return p.Endpoint
+}
+
+type remoteResponse struct {
+	Results []remoteResult `json:"results"`
+}
+
+type remoteResult struct {
+	Repository struct {
+		Name string `json:"name"`
+		URL  string `json:"url"`
+	} `json:"repository"`
+	Chart *repo.ChartVersion `json:"chart"`
+}
+
+// Search queries the API and scores its answer.
+func (p *HTTPProvider) Search(term string, threshold int, regexp, all bool) ([]*Result, error) {
+	u, err := url.Parse(p.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	q.Set("q", term)
+	if regexp {
+		q.Set("regexp", "true")
+	}
+	if all {
+		q.Set("versions", "true")
+	}
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := repo.DefaultRetryPolicy.Do(p.Client, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("search at %s failed: %s", p.Endpoint, resp.Status)
+	}
+	var rr remoteResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rr); err != nil {
+		return nil, fmt.Errorf("search at %s returned an invalid response: %s", p.Endpoint, err)
+	}
+
+	indexes := map[string]*repo.IndexFile{}
+	urls := map[string]string{}
+	for _, r := range rr.Results {
+		if r.Chart == nil || r.Chart.Metadata == nil || r.Repository.Name == "" {
+			continue
+		}
+		ind, ok := indexes[r.Repository.Name]
+		if !ok {
+			ind = repo.NewIndexFile()
+			indexes[r.Repository.Name] = ind
+			urls[r.Repository.Name] = r.Repository.URL
+		}
+		ind.Entries[r.Chart.Name] = append(ind.Entries[r.Chart.Name], r.Chart)
+	}
+
+	i := NewIndex()
+	for name, ind := range indexes {
+		ind.SortEntries()
+		i.AddRepo(name, ind, all)
+	}
+	var res []*Result
+	if term == "" {
+		res = i.All()
+	} else if res, err = i.Search(term, threshold, regexp); err != nil {
+		return nil, err
+	}
+	for _, r := range res {
+		r.Source = p.Name()
+		r.RepoURL = urls[path.Dir(r.Name)]
+	}
+	return res, nil
+}
+
+// Merge combines result sets, dropping results that refer to a chart version
+// already seen in an earlier set. Charts are the same if they have the same
+// name and version and come from the same repository URL (or, if the URL is
+// unknown, have the same full name). The sources of the dropped duplicates
+// are added to the Source of the result that is kept.
+func Merge(sets ...[]*Result) []*Result {
+	seen := map[string]*Result{}
+	res := []*Result{}
+	for _, set := range sets {
+		for _, r := range set {
+			k := mergeKey(r)
+			if first, ok := seen[k]; ok {
+				if r.Source != "" && !containsSource(first.Source, r.Source) {
+					first.Source += "," + r.Source
+				}
+				continue
+			}
+			seen[k] = r
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+func mergeKey(r *Result) string {
+	if r.RepoURL != "" {
+		return strings.TrimSuffix(r.RepoURL, "/") + sep + r.Chart.Name + sep + r.Chart.Version
+	}
+	return r.Name + sep + r.Chart.Version
+}
+
+func containsSource(sources, s string) bool {
+	for _, e := range strings.Split(sources, ",") {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
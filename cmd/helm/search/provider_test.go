@@ -0,0 +1,99 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package search
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/repo"
+)
+
+func TestHTTPProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("versions") != "" {
+			t.Errorf("did not expect all versions to be asked for")
+		}
+		fmt.Fprint(w, `{"results": [
+			{"repository": {"name": "hub", "url": "http://hub.example.com"},
+			 "chart": {"name": "santa-maria", "version": "1.0.0", "description": "Three boats"}},
+			{"repository": {"name": "hub", "url": "http://hub.example.com"},
+			 "chart": {"name": "santa-maria", "version": "1.1.0", "description": "Three boats"}},
+			{"repository": {"name": "hub", "url": "http://hub.example.com"},
+			 "chart": {"name": "pinta", "version": "2.0.0", "description": "A boat"}}
+		]}`)
+	}))
+	defer srv.Close()
+
+	p := NewHTTPProvider(srv.URL + "/api/search")
+	res, err := p.Search("maria", 25, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(res))
+	}
+	r := res[0]
+	if r.Name != "hub/santa-maria" || r.Chart.Version != "1.1.0" {
+		t.Errorf("expected the newest santa-maria, got %s %s", r.Name, r.Chart.Version)
+	}
+	if r.Source != p.Name() || r.RepoURL != "http://hub.example.com" {
+		t.Errorf("expected source attribution, got %q %q", r.Source, r.RepoURL)
+	}
+
+	srv.Close()
+	if _, err := p.Search("maria", 25, false, false); err == nil {
+		t.Error("expected an unreachable endpoint to fail")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	result := func(name, version, source, url string) *Result {
+		return &Result{
+			Name:    name,
+			Chart:   &repo.ChartVersion{Metadata: &chart.Metadata{Name: "mariadb", Version: version}},
+			Source:  source,
+			RepoURL: url,
+		}
+	}
+	local := []*Result{
+		result("stable/mariadb", "0.3.0", "local", "http://example.com/charts"),
+	}
+	hubA := []*Result{
+		result("stable/mariadb", "0.3.0", "a", "http://example.com/charts/"),
+		result("stable/mariadb", "0.4.0", "a", "http://example.com/charts"),
+		result("other/mariadb", "0.3.0", "a", "http://mirror.example.com"),
+	}
+	hubB := []*Result{
+		result("stable/mariadb", "0.3.0", "b", "http://example.com/charts"),
+		result("stable/mariadb", "0.4.0", "a", "http://example.com/charts"),
+	}
+
+	res := Merge(local, hubA, hubB)
+	if len(res) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(res))
+	}
+	expect := []string{"local,a,b", "a", "a"}
+	for i, e := range expect {
+		if res[i].Source != e {
+			t.Errorf("result %d: expected source %q, got %q", i, e, res[i].Source)
+		}
+	}
+}
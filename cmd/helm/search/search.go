@@ -42,6 +42,11 @@ type Result struct {
 	Name  string
 	Score int
 	Chart *repo.ChartVersion
+	// Source names where the result was found, if it was merged from several
+	// sources.
+	Source string
+	// RepoURL is the URL of the repository the chart is in, if known.
+	RepoURL string
 }
 
 // Index is a searchable index of chart information.
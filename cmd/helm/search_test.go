@@ -18,6 +18,10 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 )
@@ -47,6 +51,11 @@ func TestSearchCmd(t *testing.T) {
 			flags:  []string{"--versions"},
 			expect: "NAME          \tVERSION\tDESCRIPTION                    \ntesting/alpine\t0.2.0  \tDeploy a basic Alpine Linux pod\ntesting/alpine\t0.1.0  \tDeploy a basic Alpine Linux pod",
 		},
+		{
+			name:   "search for 'legacy', expect a deprecated chart",
+			args:   []string{"legacy"},
+			expect: "NAME            \tVERSION\tDESCRIPTION                                       \ntesting/legacydb\t0.1.0  \t(DEPRECATED, use testing/mariadb) Chart for a l...",
+		},
 		{
 			name:   "search for 'syzygy', expect no matches",
 			args:   []string{"syzygy"},
@@ -88,3 +97,65 @@ func TestSearchCmd(t *testing.T) {
 		}
 	}
 }
+
+func TestSearchEndpoint(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if q := r.URL.Query().Get("q"); q != "maria" {
+			t.Errorf("expected the query to be passed on, got %q", q)
+		}
+		fmt.Fprint(w, `{"results": [
+			{"repository": {"name": "stable", "url": "http://example.com/charts/"},
+			 "chart": {"name": "mariadb", "version": "0.4.0", "description": "Chart for MariaDB"}},
+			{"repository": {"name": "stable", "url": "http://example.com/charts/"},
+			 "chart": {"name": "mariadb", "version": "0.3.0", "description": "Chart for MariaDB"}},
+			{"repository": {"name": "community", "url": "http://community.example.com"},
+			 "chart": {"name": "mariadb-galera", "version": "1.0.0", "description": "Chart for MariaDB Galera"}},
+			{"repository": {"name": "community", "url": "http://community.example.com"},
+			 "chart": {"name": "nginx", "version": "1.0.0", "description": "Chart for nginx"}}
+		]}`)
+	}))
+	defer srv.Close()
+
+	oldhome := helmHome
+	helmHome = "testdata/helmhome"
+	defer func() { helmHome = oldhome }()
+
+	buf := bytes.NewBuffer(nil)
+	cmd := newSearchCmd(buf)
+	cmd.ParseFlags([]string{"--versions", "--endpoint", srv.URL, "-o", "json"})
+	if err := cmd.RunE(cmd, []string{"maria"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []searchResult
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("%s: %s", err, buf.String())
+	}
+	remote := strings.TrimPrefix(srv.URL, "http://")
+	expect := []searchResult{
+		{Name: "community/mariadb-galera", Version: "1.0.0", Source: remote, RepoURL: "http://community.example.com"},
+		{Name: "testing/mariadb", Version: "0.4.0", Source: remote, RepoURL: "http://example.com/charts/"},
+		{Name: "testing/mariadb", Version: "0.3.0", Source: "local," + remote, RepoURL: "http://example.com/charts"},
+	}
+	if len(got) != len(expect) {
+		t.Fatalf("expected %d results, got %+v", len(expect), got)
+	}
+	for i, e := range expect {
+		g := got[i]
+		if g.Name != e.Name || g.Version != e.Version || g.Source != e.Source || g.RepoURL != e.RepoURL {
+			t.Errorf("result %d: expected %+v, got %+v", i, e, g)
+		}
+	}
+
+	// An unreachable endpoint only warns.
+	srv.Close()
+	buf.Reset()
+	cmd = newSearchCmd(buf)
+	cmd.ParseFlags([]string{"--endpoint", srv.URL})
+	if err := cmd.RunE(cmd, []string{"maria"}); err != nil {
+		t.Fatal(err)
+	}
+	if out := buf.String(); !strings.Contains(out, "WARNING") || !strings.Contains(out, "testing/mariadb\t0.3.0  \tlocal") {
+		t.Errorf("expected local results and a warning, got %q", out)
+	}
+}
@@ -70,7 +70,7 @@ func (s *serveCmd) run() error {
 	}
 
 	fmt.Fprintln(s.out, "Regenerating index. This may take a moment.")
-	if err := index(repoPath, "http://"+s.address, ""); err != nil {
+	if err := index(repoPath, "http://"+s.address, "", 0); err != nil {
 		return err
 	}
 
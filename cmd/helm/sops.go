@@ -0,0 +1,101 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// sopsCommand is the SOPS binary used to decrypt values files.
+var sopsCommand = "sops"
+
+// readValuesFile reads a values file from disk, or from an HTTP(S) URL, in
+// which case it is downloaded as opts say.
+//
+// Files encrypted with SOPS are decrypted unless decrypt is false. The
+// plaintext is only ever held in memory: it is read from the standard output
+// of 'sops --decrypt' and never written to disk.
+func readValuesFile(filename string, opts remoteValues, decrypt bool) ([]byte, error) {
+	remote := isRemoteValues(filename)
+
+	var b []byte
+	var err error
+	if remote {
+		b, err = fetchValues(filename, opts)
+	} else {
+		b, err = ioutil.ReadFile(filename)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !decrypt || !isSOPSEncrypted(b) {
+		return b, nil
+	}
+	if remote {
+		// Remote files are piped to sops rather than saved first.
+		return sopsDecrypt(filename, b)
+	}
+	return sopsDecrypt(filename, nil)
+}
+
+// isSOPSEncrypted reports whether data is a YAML document carrying SOPS
+// metadata.
+func isSOPSEncrypted(data []byte) bool {
+	var doc struct {
+		SOPS map[string]interface{} `json:"sops"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil || doc.SOPS == nil {
+		return false
+	}
+	_, ok := doc.SOPS["mac"]
+	return ok
+}
+
+// sopsDecrypt decrypts filename, or, if data is not nil, data read from stdin.
+func sopsDecrypt(filename string, data []byte) ([]byte, error) {
+	path, err := exec.LookPath(sopsCommand)
+	if err != nil {
+		return nil, fmt.Errorf("%s is encrypted with SOPS, but %q could not be found. Install SOPS or pass --no-decrypt", filename, sopsCommand)
+	}
+
+	input := filename
+	if data != nil {
+		input = "/dev/stdin"
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(path, "--decrypt", "--input-type", "yaml", "--output-type", "yaml", input)
+	if data != nil {
+		cmd.Stdin = bytes.NewReader(data)
+	}
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return nil, fmt.Errorf("failed to decrypt %s: %s", filename, msg)
+	}
+	return stdout.Bytes(), nil
+}
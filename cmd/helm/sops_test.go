@@ -0,0 +1,90 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const sopsValues = `password: ENC[AES256_GCM,data:c2VjcmV0,type:str]
+sops:
+    mac: ENC[AES256_GCM,data:bWFj,type:str]
+    version: 3.0.0
+`
+
+func TestIsSOPSEncrypted(t *testing.T) {
+	tests := []struct {
+		data   string
+		expect bool
+	}{
+		{sopsValues, true},
+		{"name: value\n", false},
+		{"sops: yes\n", false},
+		{"sops:\n    version: 3.0.0\n", false},
+		{"{{ not yaml", false},
+	}
+	for _, tt := range tests {
+		if got := isSOPSEncrypted([]byte(tt.data)); got != tt.expect {
+			t.Errorf("isSOPSEncrypted(%q): expected %t, got %t", tt.data, tt.expect, got)
+		}
+	}
+}
+
+func TestReadValuesFileSOPS(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "helm-sops-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	// A stand-in for sops that prints fixed plaintext.
+	fake := filepath.Join(tmp, "sops")
+	if err := ioutil.WriteFile(fake, []byte("#!/bin/sh\necho 'password: secret'\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	defer func(cmd string) { sopsCommand = cmd }(sopsCommand)
+	sopsCommand = fake
+
+	enc := filepath.Join(tmp, "secrets.enc.yaml")
+	if err := ioutil.WriteFile(enc, []byte(sopsValues), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := readValuesFile(enc, remoteValues{}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "password: secret\n" {
+		t.Errorf("expected decrypted values, got %q", b)
+	}
+
+	b, err = readValuesFile(enc, remoteValues{}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != sopsValues {
+		t.Errorf("expected values to be left encrypted, got %q", b)
+	}
+
+	sopsCommand = filepath.Join(tmp, "missing")
+	if _, err := readValuesFile(enc, remoteValues{}, true); err == nil {
+		t.Error("expected an error when sops is not installed")
+	}
+}
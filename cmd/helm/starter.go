@@ -0,0 +1,278 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"k8s.io/helm/cmd/helm/helmpath"
+	"k8s.io/helm/pkg/chartutil"
+)
+
+const starterHelp = `
+This command consists of multiple subcommands to manage the starters that
+'helm create --starter' scaffolds new charts from.
+
+A starter is a chart, so it is packaged with 'helm package' and published in
+a chart repository like any other chart. Installed starters live in
+$HELM_DATA_HOME/starters, one directory for each:
+
+	$ helm starter install myorg/webapp-starter
+	$ helm create --starter webapp-starter mychart
+`
+
+// starterFile records where the starters installed with 'helm starter
+// install' came from, so that 'helm starter update' can fetch them again.
+type starterFile struct {
+	APIVersion string          `json:"apiVersion"`
+	Starters   []*starterEntry `json:"starters"`
+}
+
+// starterEntry is an installed starter.
+type starterEntry struct {
+	Name string `json:"name"`
+	// Source is the chart reference, URL or path the starter was installed from.
+	Source string `json:"source"`
+	// Pinned is the --version the starter was installed with, if any.
+	Pinned string `json:"pinned,omitempty"`
+	// Version is the installed version of the starter chart.
+	Version string `json:"version"`
+	Verify  bool   `json:"verify,omitempty"`
+}
+
+func loadStarterFile(home helmpath.Home) (*starterFile, error) {
+	f := &starterFile{APIVersion: "v1"}
+	b, err := ioutil.ReadFile(home.StartersFile())
+	if os.IsNotExist(err) {
+		return f, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(b, f); err != nil {
+		return nil, fmt.Errorf("could not parse %s: %s", home.StartersFile(), err)
+	}
+	return f, nil
+}
+
+func (f *starterFile) get(name string) *starterEntry {
+	for _, s := range f.Starters {
+		if s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// set adds or replaces the entry with the name of s.
+func (f *starterFile) set(s *starterEntry) {
+	f.remove(s.Name)
+	f.Starters = append(f.Starters, s)
+	sort.Sort(starterEntries(f.Starters))
+}
+
+func (f *starterFile) remove(name string) {
+	for i, s := range f.Starters {
+		if s.Name == name {
+			f.Starters = append(f.Starters[:i], f.Starters[i+1:]...)
+			return
+		}
+	}
+}
+
+func (f *starterFile) save(home helmpath.Home) error {
+	b, err := yaml.Marshal(f)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(home.StartersFile(), b, 0644)
+}
+
+type starterEntries []*starterEntry
+
+func (s starterEntries) Len() int           { return len(s) }
+func (s starterEntries) Less(i, j int) bool { return s[i].Name < s[j].Name }
+func (s starterEntries) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// checkStarterName rejects names that are not a single directory in the
+// starters directory.
+func checkStarterName(name string) error {
+	if name == "" || strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid starter name %q", name)
+	}
+	return nil
+}
+
+func newStarterCmd(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "starter [FLAGS] install|list|update|remove [ARGS]",
+		Short: "install, list, update and remove chart starters",
+		Long:  starterHelp,
+	}
+
+	cmd.AddCommand(newStarterInstallCmd(out))
+	cmd.AddCommand(newStarterListCmd(out))
+	cmd.AddCommand(newStarterUpdateCmd(out))
+	cmd.AddCommand(newStarterRemoveCmd(out))
+
+	return cmd
+}
+
+type starterListCmd struct {
+	out    io.Writer
+	home   helmpath.Home
+	output string
+}
+
+// starterListEntry is a starter in the machine readable output of
+// 'helm starter list'.
+type starterListEntry struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Source  string `json:"source"`
+}
+
+func newStarterListCmd(out io.Writer) *cobra.Command {
+	list := &starterListCmd{out: out}
+
+	cmd := &cobra.Command{
+		Use:   "list [flags]",
+		Short: "list installed starters",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			list.home = helmpath.Home(homePath())
+			return list.run()
+		},
+	}
+	addOutputFlag(cmd.Flags(), &list.output, "o", "table")
+
+	return cmd
+}
+
+func (l *starterListCmd) run() error {
+	format, err := parseOutputFormat(l.output, "table")
+	if err != nil {
+		return err
+	}
+	entries, err := listStarters(l.home)
+	if err != nil {
+		return err
+	}
+
+	if !format.human() {
+		return format.write(l.out, entries)
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(l.out, "No starters installed")
+		return nil
+	}
+	table := uitable.New()
+	table.MaxColWidth = 60
+	table.AddRow("NAME", "VERSION", "SOURCE")
+	for _, e := range entries {
+		table.AddRow(e.Name, e.Version, e.Source)
+	}
+	fmt.Fprintln(l.out, table)
+	return nil
+}
+
+// listStarters lists the starters in the starters directory, including the
+// ones copied there by hand, whose source is "local".
+func listStarters(home helmpath.Home) ([]starterListEntry, error) {
+	f, err := loadStarterFile(home)
+	if err != nil {
+		return nil, err
+	}
+	infos, err := ioutil.ReadDir(home.Starters())
+	if os.IsNotExist(err) {
+		return []starterListEntry{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	res := []starterListEntry{}
+	for _, fi := range infos {
+		if !fi.IsDir() || strings.HasPrefix(fi.Name(), ".") {
+			continue
+		}
+		e := starterListEntry{Name: fi.Name(), Source: "local"}
+		if s := f.get(fi.Name()); s != nil {
+			e.Version, e.Source = s.Version, s.Source
+		} else if md, err := chartutil.LoadChartfile(filepath.Join(home.Starters(), fi.Name(), "Chart.yaml")); err == nil {
+			e.Version = md.Version
+		}
+		res = append(res, e)
+	}
+	return res, nil
+}
+
+type starterRemoveCmd struct {
+	out   io.Writer
+	names []string
+	home  helmpath.Home
+}
+
+func newStarterRemoveCmd(out io.Writer) *cobra.Command {
+	remove := &starterRemoveCmd{out: out}
+
+	cmd := &cobra.Command{
+		Use:     "remove [flags] NAME...",
+		Aliases: []string{"rm"},
+		Short:   "remove one or more starters",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return errors.New("the name of the starter to remove is required")
+			}
+			remove.names = args
+			remove.home = helmpath.Home(homePath())
+			return remove.run()
+		},
+	}
+
+	return cmd
+}
+
+func (r *starterRemoveCmd) run() error {
+	f, err := loadStarterFile(r.home)
+	if err != nil {
+		return err
+	}
+	for _, name := range r.names {
+		if err := checkStarterName(name); err != nil {
+			return err
+		}
+		dir := filepath.Join(r.home.Starters(), name)
+		if _, err := os.Stat(dir); err != nil {
+			return fmt.Errorf("starter %q is not installed", name)
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+		f.remove(name)
+		fmt.Fprintf(r.out, "Removed starter %q\n", name)
+	}
+	return f.save(r.home)
+}
@@ -0,0 +1,284 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/helm/cmd/helm/downloader"
+	"k8s.io/helm/cmd/helm/helmpath"
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+)
+
+const starterInstallHelp = `
+This command installs a starter from a chart repository, a chart URL, a chart
+archive or a chart directory. The starter is named after its chart, unless
+'--name' is given:
+
+	$ helm starter install myorg/webapp-starter
+	Installed starter "webapp-starter" 1.2.0 from myorg/webapp-starter
+
+'--version' installs a specific version of the starter chart and pins the
+starter to it, so that 'helm starter update' leaves it alone. Remove and
+install the starter again to change the version.
+`
+
+const starterUpdateHelp = `
+This command fetches the latest version of installed starters from where they
+were installed from. Without arguments, every starter installed with
+'helm starter install' is updated. Starters installed with '--version' are
+pinned to that version, and are not updated.
+
+Run 'helm repo update' first to see the latest versions in your repositories.
+`
+
+type starterInstallCmd struct {
+	source  string
+	name    string
+	version string
+	verify  bool
+	keyring string
+	out     io.Writer
+	home    helmpath.Home
+}
+
+func newStarterInstallCmd(out io.Writer) *cobra.Command {
+	inst := &starterInstallCmd{out: out}
+
+	cmd := &cobra.Command{
+		Use:   "install [flags] CHART",
+		Short: "install a starter from a chart repository",
+		Long:  starterInstallHelp,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkArgsLength(len(args), "chart"); err != nil {
+				return err
+			}
+			inst.source = args[0]
+			inst.home = helmpath.Home(homePath())
+			return inst.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&inst.name, "name", "", "name to install the starter as. Defaults to the name of its chart")
+	f.StringVar(&inst.version, "version", "", "version of the starter chart to install and pin the starter to. Defaults to the latest version")
+	f.BoolVar(&inst.verify, "verify", false, "verify the starter chart before installing it")
+	f.StringVar(&inst.keyring, "keyring", defaultKeyring(), "keyring containing public keys")
+
+	return cmd
+}
+
+func (i *starterInstallCmd) run() error {
+	f, err := loadStarterFile(i.home)
+	if err != nil {
+		return err
+	}
+	e := &starterEntry{Name: i.name, Source: i.source, Pinned: i.version, Verify: i.verify}
+	if fi, err := os.Stat(i.source); err == nil {
+		if e.Source, err = filepath.Abs(i.source); err != nil {
+			return err
+		}
+		if fi.IsDir() && i.verify {
+			return fmt.Errorf("cannot verify a directory")
+		}
+	}
+
+	tmp, err := starterTempDir(i.home)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	md, dir, err := fetchStarter(i.out, i.home, e, i.keyring, tmp)
+	if err != nil {
+		return err
+	}
+	if e.Name == "" {
+		e.Name = md.Name
+	}
+	if err := checkStarterName(e.Name); err != nil {
+		return err
+	}
+	dest := filepath.Join(i.home.Starters(), e.Name)
+	if _, err := os.Stat(dest); err == nil {
+		return fmt.Errorf("starter %q is already installed. Use 'helm starter update' to update it", e.Name)
+	}
+	if err := os.Rename(dir, dest); err != nil {
+		return err
+	}
+
+	e.Version = md.Version
+	f.set(e)
+	if err := f.save(i.home); err != nil {
+		return err
+	}
+	fmt.Fprintf(i.out, "Installed starter %q %s from %s\n", e.Name, e.Version, i.source)
+	return nil
+}
+
+type starterUpdateCmd struct {
+	names   []string
+	keyring string
+	out     io.Writer
+	home    helmpath.Home
+}
+
+func newStarterUpdateCmd(out io.Writer) *cobra.Command {
+	up := &starterUpdateCmd{out: out}
+
+	cmd := &cobra.Command{
+		Use:   "update [flags] [NAME...]",
+		Short: "update installed starters to their latest versions",
+		Long:  starterUpdateHelp,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			up.names = args
+			up.home = helmpath.Home(homePath())
+			return up.run()
+		},
+	}
+
+	cmd.Flags().StringVar(&up.keyring, "keyring", defaultKeyring(), "keyring containing public keys")
+
+	return cmd
+}
+
+func (u *starterUpdateCmd) run() error {
+	f, err := loadStarterFile(u.home)
+	if err != nil {
+		return err
+	}
+	entries := f.Starters
+	if len(u.names) > 0 {
+		entries = []*starterEntry{}
+		for _, name := range u.names {
+			e := f.get(name)
+			if e == nil {
+				return fmt.Errorf("starter %q was not installed with 'helm starter install'", name)
+			}
+			entries = append(entries, e)
+		}
+	}
+
+	for _, e := range entries {
+		if err := u.update(e); err != nil {
+			return fmt.Errorf("could not update starter %q: %s", e.Name, err)
+		}
+		if err := f.save(u.home); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *starterUpdateCmd) update(e *starterEntry) error {
+	if e.Pinned != "" {
+		fmt.Fprintf(u.out, "Starter %q is pinned to %s\n", e.Name, e.Pinned)
+		return nil
+	}
+	tmp, err := starterTempDir(u.home)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	md, dir, err := fetchStarter(u.out, u.home, e, u.keyring, tmp)
+	if err != nil {
+		return err
+	}
+	if md.Version == e.Version {
+		fmt.Fprintf(u.out, "Starter %q is up to date (%s)\n", e.Name, e.Version)
+		return nil
+	}
+
+	// Move the old version out of the way first, so that it is kept if the
+	// new one cannot be moved in.
+	dest := filepath.Join(u.home.Starters(), e.Name)
+	old := filepath.Join(tmp, "old")
+	if err := os.Rename(dest, old); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := os.Rename(dir, dest); err != nil {
+		os.Rename(old, dest)
+		return err
+	}
+	fmt.Fprintf(u.out, "Updated starter %q from %s to %s\n", e.Name, e.Version, md.Version)
+	e.Version = md.Version
+	return nil
+}
+
+// starterTempDir creates a temporary directory inside of the starters
+// directory, so that starters can be moved out of it into place.
+func starterTempDir(home helmpath.Home) (string, error) {
+	if err := os.MkdirAll(home.Starters(), 0755); err != nil {
+		return "", err
+	}
+	return ioutil.TempDir(home.Starters(), ".tmp-")
+}
+
+// fetchStarter fetches the starter chart of e and expands it in tmp. It
+// returns the metadata of the chart and the directory it was expanded into.
+func fetchStarter(out io.Writer, home helmpath.Home, e *starterEntry, keyring, tmp string) (*chart.Metadata, string, error) {
+	var archive string
+	fi, err := os.Stat(e.Source)
+	switch {
+	case err == nil && fi.IsDir():
+		ch, err := chartutil.LoadDir(e.Source)
+		if err != nil {
+			return nil, "", err
+		}
+		if archive, err = chartutil.Save(ch, tmp); err != nil {
+			return nil, "", err
+		}
+	case err == nil:
+		archive = e.Source
+		if e.Verify {
+			if _, err := downloader.VerifyChart(archive, keyring); err != nil {
+				return nil, "", err
+			}
+		}
+	default:
+		dl := downloader.ChartDownloader{
+			HelmHome: home,
+			Out:      out,
+			Keyring:  keyring,
+			Verify:   downloader.VerifyNever,
+		}
+		if e.Verify {
+			dl.Verify = downloader.VerifyAlways
+		}
+		if archive, _, err = dl.DownloadTo(e.Source, e.Pinned, tmp); err != nil {
+			return nil, "", err
+		}
+	}
+
+	ch, err := chartutil.Load(archive)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s is not a chart: %s", e.Source, err)
+	}
+	expanded := filepath.Join(tmp, "new")
+	if err := chartutil.ExpandFile(expanded, archive); err != nil {
+		return nil, "", err
+	}
+	return ch.Metadata, filepath.Join(expanded, ch.Metadata.Name), nil
+}
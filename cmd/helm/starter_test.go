@@ -0,0 +1,160 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/helm/cmd/helm/helmpath"
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/repo/repotest"
+)
+
+func TestStarterCmds(t *testing.T) {
+	hh, err := tempHelmHome(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := homePath()
+	helmHome = hh
+	defer func() {
+		helmHome = old
+		os.RemoveAll(hh)
+	}()
+	home := helmpath.Home(hh)
+
+	srv := repotest.NewServer(hh)
+	defer srv.Stop()
+	if _, err := srv.CopyCharts("testdata/testcharts/*.tgz*"); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.LinkIndices(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A starter kept in a local chart directory.
+	ch, err := chartutil.LoadDir("testdata/testcharts/alpine")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := filepath.Join(hh, "src")
+	os.Mkdir(src, 0755)
+	if err := chartutil.SaveDir(ch, src); err != nil {
+		t.Fatal(err)
+	}
+	localSrc := filepath.Join(src, ch.Metadata.Name)
+
+	starter := func(args ...string) (string, error) {
+		out := bytes.NewBuffer(nil)
+		cmd, _, err := newStarterCmd(out).Find(args[:1])
+		if err != nil {
+			return "", err
+		}
+		if err := cmd.ParseFlags(args[1:]); err != nil {
+			return "", err
+		}
+		err = cmd.RunE(cmd, cmd.Flags().Args())
+		return out.String(), err
+	}
+
+	out, err := starter("install", "test/signtest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "Installed starter \"signtest\" 0.1.0 from test/signtest\n" {
+		t.Errorf("unexpected output %q", out)
+	}
+	if _, err := os.Stat(filepath.Join(home.Starters(), "signtest", "Chart.yaml")); err != nil {
+		t.Errorf("expected the starter to be installed: %s", err)
+	}
+	if _, err := starter("install", "test/signtest"); err == nil || !strings.Contains(err.Error(), "already installed") {
+		t.Errorf("expected an error installing a starter twice, got %v", err)
+	}
+
+	if _, err := starter("install", localSrc, "--name", "mine"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := starter("install", "test/reqtest", "--name", "../evil"); err == nil {
+		t.Error("expected an error for an invalid starter name")
+	}
+	if _, err := starter("install", "test/reqtest", "--version", "0.1.0", "--name", "pinned"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A starter copied into the starters directory by hand.
+	if err := os.Rename(filepath.Join(home.Starters(), "signtest"), filepath.Join(home.Starters(), "handmade")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := starter("install", "test/signtest"); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err = starter("list")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range []string{"handmade\t0.1.0  \tlocal", "mine    \t0.1.0  \t" + localSrc, "pinned  \t0.1.0  \ttest/reqtest", "signtest\t0.1.0  \ttest/signtest"} {
+		if !strings.Contains(out, e) {
+			t.Errorf("expected the list to contain %q, got\n%s", e, out)
+		}
+	}
+
+	ch.Metadata.Version = "0.2.0"
+	if err := chartutil.SaveChartfile(filepath.Join(localSrc, "Chart.yaml"), ch.Metadata); err != nil {
+		t.Fatal(err)
+	}
+	out, err = starter("update")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "Updated starter \"mine\" from 0.1.0 to 0.2.0\nStarter \"pinned\" is pinned to 0.1.0\nStarter \"signtest\" is up to date (0.1.0)\n"
+	if out != expected {
+		t.Errorf("expected %q, got %q", expected, out)
+	}
+	if md, err := chartutil.LoadChartfile(filepath.Join(home.Starters(), "mine", "Chart.yaml")); err != nil || md.Version != "0.2.0" {
+		t.Errorf("expected version 0.2.0 of the starter to be installed, got %v (%v)", md, err)
+	}
+	if _, err := starter("update", "handmade"); err == nil {
+		t.Error("expected an error updating a starter installed by hand")
+	}
+
+	cc := &createCmd{home: home, name: filepath.Join(hh, "created"), starter: "mine", out: bytes.NewBuffer(nil)}
+	if err := cc.run(); err != nil {
+		t.Fatal(err)
+	}
+	if md, err := chartutil.LoadChartfile(filepath.Join(hh, "created", "Chart.yaml")); err != nil || md.Name != "created" {
+		t.Errorf("expected a chart created from the starter, got %v (%v)", md, err)
+	}
+
+	if _, err := starter("remove", "mine", "handmade"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := starter("remove", "mine"); err == nil {
+		t.Error("expected an error removing a starter that is not installed")
+	}
+	f, err := loadStarterFile(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Starters) != 2 || f.Starters[0].Name != "pinned" || f.Starters[1].Name != "signtest" {
+		t.Errorf("unexpected starters file %v", f.Starters)
+	}
+}
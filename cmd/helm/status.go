@@ -19,10 +19,12 @@ package main
 import (
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/release"
 	"k8s.io/helm/pkg/proto/hapi/services"
 	"k8s.io/helm/pkg/timeconv"
 )
@@ -36,6 +38,22 @@ type statusCmd struct {
 	out     io.Writer
 	client  helm.Interface
 	version int32
+	output  string
+}
+
+// statusResult is the machine readable output of 'helm status'.
+type statusResult struct {
+	Name         string `json:"name"`
+	Namespace    string `json:"namespace"`
+	Status       string `json:"status"`
+	LastDeployed string `json:"lastDeployed,omitempty"`
+	Details      string `json:"details,omitempty"`
+	Resources    string `json:"resources,omitempty"`
+	Notes        string `json:"notes,omitempty"`
+	Canary       string `json:"canary,omitempty"`
+	BlueGreen    string `json:"blueGreen,omitempty"`
+
+	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
 func newStatusCmd(client helm.Interface, out io.Writer) *cobra.Command {
@@ -55,27 +73,58 @@ func newStatusCmd(client helm.Interface, out io.Writer) *cobra.Command {
 			}
 			status.release = args[0]
 			if status.client == nil {
-				status.client = helm.NewClient(helm.Host(tillerHost))
+				status.client = helm.NewClient(helm.Host(tillerHost), helm.UserToken(userToken), helm.Impersonate(asUser, asGroups), helm.RequestID(requestID))
 			}
 			return status.run()
 		},
 	}
 
 	cmd.PersistentFlags().Int32Var(&status.version, "revision", 0, "if set, display the status of the named release with revision")
+	addOutputFlag(cmd.Flags(), &status.output, "o", "table")
 
 	return cmd
 }
 
 func (s *statusCmd) run() error {
+	format, err := parseOutputFormat(s.output, "table")
+	if err != nil {
+		return err
+	}
+
 	res, err := s.client.ReleaseStatus(s.release, helm.StatusReleaseVersion(s.version))
 	if err != nil {
 		return prettyError(err)
 	}
 
+	if !format.human() {
+		return format.write(s.out, statusOutput(res))
+	}
 	PrintStatus(s.out, res)
 	return nil
 }
 
+func statusOutput(res *services.GetReleaseStatusResponse) statusResult {
+	st := statusResult{
+		Name:         res.Name,
+		Namespace:    res.Namespace,
+		Status:       res.Info.Status.Code.String(),
+		LastDeployed: outputTime(res.Info.LastDeployed),
+		Resources:    res.Info.Status.Resources,
+		Notes:        res.Info.Status.Notes,
+		Metadata:     res.Info.Metadata,
+	}
+	if res.Info.Canary != nil {
+		st.Canary = formatCanary(res.Info.Canary)
+	}
+	if res.Info.BlueGreen != nil {
+		st.BlueGreen = formatBlueGreen(res.Info.BlueGreen)
+	}
+	if res.Info.Status.Details != nil {
+		st.Details = res.Info.Status.Details.String()
+	}
+	return st
+}
+
 // PrintStatus prints out the status of a release. Shared because also used by
 // install / upgrade
 func PrintStatus(out io.Writer, res *services.GetReleaseStatusResponse) {
@@ -84,9 +133,21 @@ func PrintStatus(out io.Writer, res *services.GetReleaseStatusResponse) {
 	}
 	fmt.Fprintf(out, "NAMESPACE: %s\n", res.Namespace)
 	fmt.Fprintf(out, "STATUS: %s\n", res.Info.Status.Code)
+	if res.Info.Canary != nil {
+		fmt.Fprintf(out, "CANARY: %s\n", formatCanary(res.Info.Canary))
+	}
+	if res.Info.BlueGreen != nil {
+		fmt.Fprintf(out, "BLUE/GREEN: %s\n", formatBlueGreen(res.Info.BlueGreen))
+	}
 	if res.Info.Status.Details != nil {
 		fmt.Fprintf(out, "Details: %s\n", res.Info.Status.Details)
 	}
+	if len(res.Info.Metadata) > 0 {
+		fmt.Fprintf(out, "METADATA:\n")
+		for _, kv := range formatMetadata(res.Info.Metadata) {
+			fmt.Fprintf(out, "  %s\n", kv)
+		}
+	}
 	fmt.Fprintf(out, "\n")
 	if len(res.Info.Status.Resources) > 0 {
 		fmt.Fprintf(out, "RESOURCES:\n%s\n", res.Info.Status.Resources)
@@ -95,3 +156,19 @@ func PrintStatus(out io.Writer, res *services.GetReleaseStatusResponse) {
 		fmt.Fprintf(out, "NOTES:\n%s\n", res.Info.Status.Notes)
 	}
 }
+
+// formatCanary describes the canary of a revision, e.g.
+// "ROLLED_BACK, 20% (web-canary): the canary did not become ready".
+func formatCanary(c *release.Canary) string {
+	s := fmt.Sprintf("%s, %d%% (%s)", c.Phase, c.Percent, strings.Join(c.Deployments, ", "))
+	if c.Message != "" {
+		s += ": " + c.Message
+	}
+	return s
+}
+
+// formatBlueGreen describes the blue/green rollout of a revision, e.g.
+// "STAGED, slot green (web-green)".
+func formatBlueGreen(bg *release.BlueGreen) string {
+	return fmt.Sprintf("%s, slot %s (%s)", bg.Phase, bg.Slot, strings.Join(bg.Deployments, ", "))
+}
@@ -26,6 +26,14 @@ The above is equivalent to the YAML document
 	topname:
 	  subname: value
 
+Lists are written in braces (name={a,b,c}) and their items can be set by
+index (servers[0].port=80). Bare values are typed: integers without leading
+zeros, true, false and null become int64, bool and nil, while double-quoted
+values are always strings. A backslash escapes the next character.
+
+A null value is kept in the result so that merging it over chart defaults
+removes the key.
+
 This package provides a parser and utilities for converting the strvals format
 to other formats.
 */
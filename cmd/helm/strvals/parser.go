@@ -17,17 +17,25 @@ package strvals
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
+	"io/ioutil"
+	"regexp"
 	"strconv"
 	"strings"
 
 	"github.com/ghodss/yaml"
 )
 
-// ErrNotList indicates that a non-list was treated as a list.
-var ErrNotList = errors.New("not a list")
+// MaxIndex is the largest list index a set line may use, so that a typo does
+// not allocate a huge list.
+const MaxIndex = 65536
+
+// intPattern matches the values that are turned into integers. Numbers with
+// leading zeros or a sign are kept as strings, so that values such as "007"
+// survive unchanged.
+var intPattern = regexp.MustCompile(`^(0|-?[1-9][0-9]*)$`)
 
 // ToYAML takes a string of arguments and converts to a YAML document.
 func ToYAML(s string) (string, error) {
@@ -44,9 +52,7 @@ func ToYAML(s string) (string, error) {
 // A set line is of the form name1=value1,name2=value2
 func Parse(s string) (map[string]interface{}, error) {
 	vals := map[string]interface{}{}
-	scanner := bytes.NewBufferString(s)
-	t := newParser(scanner, vals)
-	err := t.parse()
+	err := ParseInto(s, vals)
 	return vals, err
 }
 
@@ -55,192 +61,309 @@ func Parse(s string) (map[string]interface{}, error) {
 // If the strval string has a key that exists in dest, it overwrites the
 // dest version.
 func ParseInto(s string, dest map[string]interface{}) error {
-	scanner := bytes.NewBufferString(s)
-	t := newParser(scanner, dest)
-	return t.parse()
+	return newParser(s, dest, false).parse()
+}
+
+// ParseJSONInto parses a line of the form name1=JSON1,name2=JSON2, where each
+// value is a JSON document, and merges the result into dest.
+func ParseJSONInto(s string, dest map[string]interface{}) error {
+	return newParser(s, dest, true).parse()
 }
 
 // parser is a simple parser that takes a strvals line and parses it into a
 // map representation.
 type parser struct {
-	sc   *bytes.Buffer
+	s    string
+	pos  int
 	data map[string]interface{}
+	// json is true if values are JSON documents.
+	json bool
 }
 
-func newParser(sc *bytes.Buffer, data map[string]interface{}) *parser {
-	return &parser{sc: sc, data: data}
+func newParser(s string, data map[string]interface{}, json bool) *parser {
+	return &parser{s: s, data: data, json: json}
 }
 
 func (t *parser) parse() error {
-	for {
-		err := t.key(t.data)
-		if err == nil {
-			continue
+	for !t.eof() {
+		path, err := t.key()
+		if err != nil {
+			return err
 		}
-		if err == io.EOF {
-			return nil
+		name := pathName(path)
+
+		var val interface{}
+		if t.json {
+			val, err = t.jsonVal()
+		} else {
+			val, err = t.val()
+		}
+		if err != nil {
+			return fmt.Errorf("key %q: %s", name, err)
 		}
-		return err
+		if !t.eof() && !t.consume(',') {
+			return fmt.Errorf("key %q: unexpected %q after value", name, t.s[t.pos])
+		}
+
+		v, err := setIn(t.data, path, val)
+		if err != nil {
+			return fmt.Errorf("key %q: %s", name, err)
+		}
+		t.data = v.(map[string]interface{})
 	}
+	return nil
+}
+
+func (t *parser) eof() bool {
+	return t.pos >= len(t.s)
 }
 
-func runeSet(r []rune) map[rune]bool {
-	s := make(map[rune]bool, len(r))
-	for _, rr := range r {
-		s[rr] = true
+// consume skips the next byte if it is c, and reports whether it did.
+func (t *parser) consume(c byte) bool {
+	if !t.eof() && t.s[t.pos] == c {
+		t.pos++
+		return true
 	}
-	return s
+	return false
 }
 
-func (t *parser) key(data map[string]interface{}) error {
-	stop := runeSet([]rune{'=', ',', '.'})
+// key parses a key up to and including the "=" that ends it. Each part of the
+// returned path is a string for a map key, or an int for a list index.
+func (t *parser) key() ([]interface{}, error) {
+	path := []interface{}{}
 	for {
-		switch k, last, err := runesUntil(t.sc, stop); {
-		case err != nil:
-			if len(k) == 0 {
-				return err
-			}
-			return fmt.Errorf("key %q has no value", string(k))
-			//set(data, string(k), "")
-			//return err
-		case last == '=':
-			//End of key. Consume =, Get value.
-			// FIXME: Get value list first
-			vl, e := t.valList()
-			switch e {
-			case nil:
-				set(data, string(k), vl)
-				return nil
-			case io.EOF:
-				set(data, string(k), "")
-				return e
-			case ErrNotList:
-				v, e := t.val()
-				set(data, string(k), typedVal(v))
-				return e
-			default:
-				return e
+		k, err := t.until(".[=,")
+		if err != nil {
+			return nil, err
+		}
+		if k == "" {
+			if t.eof() || t.s[t.pos] == ',' {
+				return nil, fmt.Errorf("key %q has no value", pathName(path))
 			}
+			return nil, fmt.Errorf("key %q has an empty part", pathName(path)+string(t.s[t.pos]))
+		}
+		path = append(path, k)
 
-		case last == ',':
-			// No value given. Set the value to empty string. Return error.
-			set(data, string(k), "")
-			return fmt.Errorf("key %q has no value (cannot end with ,)", string(k))
-		case last == '.':
-			// First, create or find the target map.
-			inner := map[string]interface{}{}
-			if _, ok := data[string(k)]; ok {
-				inner = data[string(k)].(map[string]interface{})
+		for t.consume('[') {
+			i, err := t.index()
+			if err != nil {
+				return nil, fmt.Errorf("key %q: %s", pathName(path), err)
 			}
+			path = append(path, i)
+		}
 
-			// Recurse
-			e := t.key(inner)
-			if len(inner) == 0 {
-				return fmt.Errorf("key map %q has no value", string(k))
-			}
-			set(data, string(k), inner)
-			return e
+		switch {
+		case t.consume('.'):
+			continue
+		case t.consume('='):
+			return path, nil
+		case t.eof(), t.s[t.pos] == ',':
+			return nil, fmt.Errorf("key %q has no value", pathName(path))
 		}
+		return nil, fmt.Errorf("key %q: unexpected %q", pathName(path), t.s[t.pos])
 	}
 }
 
-func set(data map[string]interface{}, key string, val interface{}) {
-	// If key is empty, don't set it.
-	if len(key) == 0 {
-		return
+// index parses a list index after its "[", up to and including the "]".
+func (t *parser) index() (int, error) {
+	end := strings.IndexByte(t.s[t.pos:], ']')
+	if end < 0 {
+		return 0, errors.New("list index must terminate with ']'")
 	}
-	data[key] = val
-}
-
-func (t *parser) val() ([]rune, error) {
-	stop := runeSet([]rune{','})
-	v, _, err := runesUntil(t.sc, stop)
-	return v, err
+	digits := t.s[t.pos : t.pos+end]
+	t.pos += end + 1
+	i, err := strconv.Atoi(digits)
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("invalid list index %q", digits)
+	}
+	if i > MaxIndex {
+		return 0, fmt.Errorf("list index %d is larger than %d", i, MaxIndex)
+	}
+	return i, nil
 }
 
-func (t *parser) valList() ([]interface{}, error) {
-	r, _, e := t.sc.ReadRune()
-	if e != nil {
-		return []interface{}{}, e
+// until reads up to, but not including, the first unescaped byte in stop. A
+// backslash escapes the character that follows it.
+func (t *parser) until(stop string) (string, error) {
+	b := bytes.NewBuffer(nil)
+	for !t.eof() {
+		c := t.s[t.pos]
+		switch {
+		case strings.IndexByte(stop, c) >= 0:
+			return b.String(), nil
+		case c == '\\':
+			if t.pos+1 >= len(t.s) {
+				return "", errors.New("unterminated escape at end of input")
+			}
+			b.WriteByte(t.s[t.pos+1])
+			t.pos += 2
+		default:
+			b.WriteByte(c)
+			t.pos++
+		}
 	}
+	return b.String(), nil
+}
 
-	if r != '{' {
-		t.sc.UnreadRune()
-		return []interface{}{}, ErrNotList
+// val parses a value: a list in braces, or a scalar.
+func (t *parser) val() (interface{}, error) {
+	if !t.consume('{') {
+		return t.scalar(",", false)
 	}
 
 	list := []interface{}{}
-	stop := runeSet([]rune{',', '}'})
+	t.skipSpace()
+	if t.consume('}') {
+		return list, nil
+	}
 	for {
-		switch v, last, err := runesUntil(t.sc, stop); {
-		case err != nil:
-			if err == io.EOF {
-				err = errors.New("list must terminate with '}'")
-			}
-			return list, err
-		case last == '}':
-			// If this is followed by ',', consume it.
-			if r, _, e := t.sc.ReadRune(); e == nil && r != ',' {
-				t.sc.UnreadRune()
-			}
-			list = append(list, typedVal(v))
+		v, err := t.scalar(",}", true)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, v)
+		switch {
+		case t.consume(','):
+		case t.consume('}'):
 			return list, nil
-		case last == ',':
-			list = append(list, typedVal(v))
+		default:
+			return nil, errors.New("list must terminate with '}'")
 		}
 	}
 }
 
-func runesUntil(in io.RuneReader, stop map[rune]bool) ([]rune, rune, error) {
-	v := []rune{}
-	for {
-		switch r, _, e := in.ReadRune(); {
-		case e != nil:
-			return v, r, e
-		case inMap(r, stop):
-			return v, r, nil
-		case r == '\\':
-			next, _, e := in.ReadRune()
-			if e != nil {
-				return v, next, e
-			}
-			v = append(v, next)
-		default:
-			v = append(v, r)
+// scalar parses a quoted string, or an unquoted value ending before a byte
+// in stop. If trim is true, as it is for list items, spaces around the value
+// are ignored.
+//
+// Quoted strings are always strings, and so are values with an escaped
+// character. Other values are typed: "true" and "false" become booleans,
+// integers become int64 and "null" becomes nil; everything else is a string.
+func (t *parser) scalar(stop string, trim bool) (interface{}, error) {
+	if trim {
+		t.skipSpace()
+	}
+	if t.consume('"') {
+		v, err := t.until(`"`)
+		if err != nil {
+			return nil, err
+		}
+		if !t.consume('"') {
+			return nil, errors.New("quoted value must terminate with '\"'")
+		}
+		if trim {
+			t.skipSpace()
 		}
+		return v, nil
+	}
+
+	start := t.pos
+	v, err := t.until(stop)
+	if err != nil {
+		return nil, err
+	}
+	if trim {
+		v = strings.TrimRight(v, " ")
 	}
+	if strings.Contains(t.s[start:t.pos], "\\") {
+		return v, nil
+	}
+	return typedVal(v), nil
 }
 
-func inMap(k rune, m map[rune]bool) bool {
-	_, ok := m[k]
-	return ok
+func (t *parser) skipSpace() {
+	for t.consume(' ') {
+	}
 }
 
-func (t *parser) listVal() []rune {
-	v := []rune{}
-	for {
-		switch r, _, e := t.sc.ReadRune(); {
-		case e != nil:
-			// End of input or error with reader stops value parsing.
-			return v
-		case r == '\\':
-			//Escape char. Consume next and append.
-			next, _, e := t.sc.ReadRune()
-			if e != nil {
-				return v
-			}
-			v = append(v, next)
-		case r == ',':
-			//End of key. Consume ',' and return.
-			return v
+// jsonVal parses a JSON document.
+func (t *parser) jsonVal() (interface{}, error) {
+	r := strings.NewReader(t.s[t.pos:])
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %s", err)
+	}
+	rest, _ := ioutil.ReadAll(dec.Buffered())
+	t.pos = len(t.s) - r.Len() - len(rest)
+	return jsonNumbers(v), nil
+}
+
+// jsonNumbers turns the json.Numbers in v into int64 where they are integers,
+// and float64 otherwise, like YAML does.
+func jsonNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = jsonNumbers(e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = jsonNumbers(e)
+		}
+	}
+	return v
+}
+
+// setIn sets path in cur to val, creating the maps and lists along the path as
+// needed, and returns the updated cur. Lists are padded with nil items up to
+// the index that is set.
+func setIn(cur interface{}, path []interface{}, val interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return val, nil
+	}
+	switch k := path[0].(type) {
+	case string:
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			m = map[string]interface{}{}
+		}
+		v, err := setIn(m[k], path[1:], val)
+		if err != nil {
+			return nil, err
+		}
+		m[k] = v
+		return m, nil
+	case int:
+		l, _ := cur.([]interface{})
+		if k >= len(l) {
+			l = append(l, make([]interface{}, k+1-len(l))...)
+		}
+		v, err := setIn(l[k], path[1:], val)
+		if err != nil {
+			return nil, err
+		}
+		l[k] = v
+		return l, nil
+	}
+	return nil, fmt.Errorf("invalid path element %v", path[0])
+}
+
+// pathName formats a path the way it is written in a set line.
+func pathName(path []interface{}) string {
+	b := bytes.NewBuffer(nil)
+	for _, p := range path {
+		switch p := p.(type) {
+		case int:
+			fmt.Fprintf(b, "[%d]", p)
 		default:
-			v = append(v, r)
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			fmt.Fprint(b, p)
 		}
 	}
+	return b.String()
 }
 
-func typedVal(v []rune) interface{} {
-	val := string(v)
+func typedVal(val string) interface{} {
 	if strings.EqualFold(val, "true") {
 		return true
 	}
@@ -249,8 +372,14 @@ func typedVal(v []rune) interface{} {
 		return false
 	}
 
-	if iv, err := strconv.ParseInt(val, 10, 64); err == nil {
-		return iv
+	if val == "null" {
+		return nil
+	}
+
+	if intPattern.MatchString(val) {
+		if iv, err := strconv.ParseInt(val, 10, 64); err == nil {
+			return iv
+		}
 	}
 
 	return val
@@ -155,6 +155,125 @@ func TestParseSet(t *testing.T) {
 			str: "name1={1021,902",
 			err: true,
 		},
+		{
+			"name1={}",
+			map[string]interface{}{"name1": []string{}},
+			false,
+		},
+		{
+			"name1={a, b , c}",
+			map[string]interface{}{"name1": []string{"a", "b", "c"}},
+			false,
+		},
+		{
+			`name1={"a,b", "c"}`,
+			map[string]interface{}{"name1": []string{"a,b", "c"}},
+			false,
+		},
+		{
+			str: "name1={a,b}c",
+			err: true,
+		},
+		// Types
+		{
+			"a=true,b=FALSE,c=42,d=-7,e=0",
+			map[string]interface{}{"a": true, "b": false, "c": 42, "d": -7, "e": 0},
+			false,
+		},
+		{
+			"a=007,b=+1,c=1.5,d=1e3,e=99999999999999999999",
+			map[string]interface{}{"a": "007", "b": "+1", "c": "1.5", "d": "1e3", "e": "99999999999999999999"},
+			false,
+		},
+		{
+			`a="42",b="true",c="null"`,
+			map[string]interface{}{"a": "42", "b": "true", "c": "null"},
+			false,
+		},
+		{
+			`a=\42,b=\true`,
+			map[string]interface{}{"a": "42", "b": "true"},
+			false,
+		},
+		{
+			"name1=null",
+			map[string]interface{}{"name1": nil},
+			false,
+		},
+		// Quoting and escaping
+		{
+			`name1="one,two=three",name2="{{ .Release.Name }}"`,
+			map[string]interface{}{"name1": "one,two=three", "name2": "{{ .Release.Name }}"},
+			false,
+		},
+		{
+			`name1="say \"hi\"",name2=a "b" c`,
+			map[string]interface{}{"name1": `say "hi"`, "name2": `a "b" c`},
+			false,
+		},
+		{
+			"name1=a{b}c",
+			map[string]interface{}{"name1": "a{b}c"},
+			false,
+		},
+		{
+			`outer\.name=value`,
+			map[string]interface{}{"outer.name": "value"},
+			false,
+		},
+		{
+			str: `name1="unterminated`,
+			err: true,
+		},
+		{
+			str: `name1="quoted"trailing`,
+			err: true,
+		},
+		// List indexes
+		{
+			"servers[0].port=80,servers[0].host=a,servers[1].port=81",
+			map[string]interface{}{"servers": []map[string]interface{}{{"port": 80, "host": "a"}, {"port": 81}}},
+			false,
+		},
+		{
+			"list[2]=c",
+			map[string]interface{}{"list": []interface{}{nil, nil, "c"}},
+			false,
+		},
+		{
+			"matrix[1][0]=x",
+			map[string]interface{}{"matrix": []interface{}{nil, []string{"x"}}},
+			false,
+		},
+		{
+			"outer.list[0]={a,b}",
+			map[string]interface{}{"outer": map[string]interface{}{"list": []interface{}{[]string{"a", "b"}}}},
+			false,
+		},
+		{
+			str: "list[a]=b",
+			err: true,
+		},
+		{
+			str: "list[-1]=b",
+			err: true,
+		},
+		{
+			str: "list[0=b",
+			err: true,
+		},
+		{
+			str: "list[65537]=b",
+			err: true,
+		},
+		{
+			str: "list[0]x=b",
+			err: true,
+		},
+		{
+			str: "list[0]",
+			err: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -230,3 +349,105 @@ func TestToYAML(t *testing.T) {
 		t.Errorf("Expected %q, got %q", expect, o)
 	}
 }
+
+func TestParseIntoIndexes(t *testing.T) {
+	got := map[string]interface{}{
+		"servers": []interface{}{
+			map[string]interface{}{"host": "a", "port": 80},
+			map[string]interface{}{"host": "b", "port": 80},
+		},
+		"scalar": "value",
+	}
+	input := "servers[1].port=81,servers[2].host=c,scalar.inner=value"
+	expect := map[string]interface{}{
+		"servers": []interface{}{
+			map[string]interface{}{"host": "a", "port": 80},
+			map[string]interface{}{"host": "b", "port": 81},
+			map[string]interface{}{"host": "c"},
+		},
+		"scalar": map[string]interface{}{"inner": "value"},
+	}
+
+	if err := ParseInto(input, got); err != nil {
+		t.Fatal(err)
+	}
+
+	y1, err := yaml.Marshal(expect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	y2, err := yaml.Marshal(got)
+	if err != nil {
+		t.Fatalf("Error serializing parsed value: %s", err)
+	}
+
+	if string(y1) != string(y2) {
+		t.Errorf("%s: Expected:\n%s\nGot:\n%s", input, y1, y2)
+	}
+}
+
+func TestParseJSONInto(t *testing.T) {
+	tests := []struct {
+		str    string
+		expect map[string]interface{}
+		err    bool
+	}{
+		{
+			`name1={"a":[1,2.5,"x"],"b":{"c":true}},name2="value"`,
+			map[string]interface{}{
+				"name1": map[string]interface{}{"a": []interface{}{1, 2.5, "x"}, "b": map[string]interface{}{"c": true}},
+				"name2": "value",
+			},
+			false,
+		},
+		{
+			`outer.list[1]=["a","b"]`,
+			map[string]interface{}{"outer": map[string]interface{}{"list": []interface{}{nil, []string{"a", "b"}}}},
+			false,
+		},
+		{
+			`name1=null`,
+			map[string]interface{}{"name1": nil},
+			false,
+		},
+		{
+			str: `name1={"a":`,
+			err: true,
+		},
+		{
+			str: `name1=value`,
+			err: true,
+		},
+		{
+			str: `name1=1 2`,
+			err: true,
+		},
+	}
+
+	for _, tt := range tests {
+		got := map[string]interface{}{}
+		err := ParseJSONInto(tt.str, got)
+		if err != nil {
+			if tt.err {
+				continue
+			}
+			t.Fatalf("%s: %s", tt.str, err)
+		}
+		if tt.err {
+			t.Errorf("%s: Expected error. Got nil", tt.str)
+		}
+
+		y1, err := yaml.Marshal(tt.expect)
+		if err != nil {
+			t.Fatal(err)
+		}
+		y2, err := yaml.Marshal(got)
+		if err != nil {
+			t.Fatalf("Error serializing parsed value: %s", err)
+		}
+
+		if string(y1) != string(y2) {
+			t.Errorf("%s: Expected:\n%s\nGot:\n%s", tt.str, y1, y2)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/helm/pkg/helm"
+)
+
+const syncHelp = `
+This command reverts the changes made to the resources of a release outside
+of Helm, as reported by 'helm drift': fields changed since the release was
+installed or upgraded are set back, fields added are removed, and resources
+that were deleted are created again.
+
+	$ helm sync happy-panda --fields-to-ignore Deployment:spec.replicas
+	KIND      	NAME               	NAMESPACE	STATUS
+	Deployment	happy-panda-mariadb	default  	changed
+
+	Deployment happy-panda-mariadb:
+	  + metadata.labels.hotfix: "true"
+	Reverted 1 resource of release "happy-panda"
+
+Fields managed outside of Helm on purpose are left alone if they are listed in
+'--fields-to-ignore' or in the 'helm.sh/ignore-fields' annotation of the
+resource; see 'helm drift --help' for how to write them. The deployed revision
+of the release is synced, and no new revision is recorded.
+`
+
+type syncCmd struct {
+	release       string
+	ignore        []string
+	overrideGates string
+	out           io.Writer
+	client        helm.Interface
+}
+
+func newSyncCmd(client helm.Interface, out io.Writer) *cobra.Command {
+	s := &syncCmd{out: out, client: client}
+
+	cmd := &cobra.Command{
+		Use:               "sync [flags] RELEASE_NAME",
+		Short:             "revert changes made to the resources of a release outside of Helm",
+		Long:              syncHelp,
+		PersistentPreRunE: setupConnection,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkArgsLength(len(args), "release name"); err != nil {
+				return err
+			}
+			s.release = args[0]
+			if s.client == nil {
+				s.client = helm.NewClient(helm.Host(tillerHost), helm.UserToken(userToken), helm.Impersonate(asUser, asGroups), helm.RequestID(requestID))
+			}
+			return s.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringSliceVar(&s.ignore, "fields-to-ignore", []string{}, "fields managed outside of Helm, as [KIND[/NAME]:]PATH. Can be repeated or separated with commas")
+	f.StringVar(&s.overrideGates, "override-gates", "", "sync the release even if the deployment windows or gates of Tiller do not allow it, giving the reason for the change")
+
+	return cmd
+}
+
+func (s *syncCmd) run() error {
+	res, err := s.client.SyncRelease(s.release, helm.SyncIgnoreFields(s.ignore), helm.SyncOverrideGates(s.overrideGates))
+	if err != nil {
+		return prettyError(err)
+	}
+
+	switch len(res.Resources) {
+	case 0:
+		fmt.Fprintf(s.out, "Release %q is in sync\n", s.release)
+	case 1:
+		fmt.Fprint(s.out, formatDrift(res.Resources))
+		fmt.Fprintf(s.out, "Reverted 1 resource of release %q\n", s.release)
+	default:
+		fmt.Fprint(s.out, formatDrift(res.Resources))
+		fmt.Fprintf(s.out, "Reverted %d resources of release %q\n", len(res.Resources), s.release)
+	}
+	return nil
+}
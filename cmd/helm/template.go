@@ -0,0 +1,435 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+	kversion "k8s.io/kubernetes/pkg/version"
+
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/engine"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/releaseutil"
+	"k8s.io/helm/pkg/timeconv"
+	"k8s.io/helm/pkg/version"
+)
+
+const templateDesc = `
+This command renders a chart's templates locally and prints the output.
+
+Rendering happens entirely on the client: no Tiller server or Kubernetes
+cluster is contacted. Any values that would normally be looked up in the
+cluster are stubbed out instead. In particular, '.Capabilities' reports only
+the API versions given with '--api-versions' (plus "v1"), and the Kubernetes
+version given with '--kube-version'.
+
+	$ helm template --api-versions batch/v2alpha1 --kube-version 1.5.1 ./redis
+
+To check templates that depend on the cluster's capabilities, give several
+versions to '--kube-version' along with '--matrix'. The chart is rendered once
+for each version, with the API versions a cluster of that version serves by
+default added to '--api-versions'. The first rendering is printed in full, and
+each of the others as a diff against it:
+
+	$ helm template --kube-version 1.8,1.9,1.16 --matrix ./redis
+
+With '--coverage', a coverage report is printed instead of the manifests. It
+lists the template files, named templates ('define') and branches of 'if',
+'range' and 'with' that the given values did not exercise, which helps to find
+dead template code and untested branches.
+
+Rendering fails if two templates, or two documents of one template, define the
+same resource, that is one with the same API group, kind, namespace and name.
+Hooks are not checked.
+
+A CHART of '-' reads a packaged chart from stdin:
+
+	$ helm package -d - ./redis | helm template -
+`
+
+type templateCmd struct {
+	chartPath    string
+	chart        *chart.Chart // read from stdin, if chartPath is "-"
+	name         string
+	namespace    string
+	valuesFile   string
+	values       string
+	jsonValues   string
+	apiVersions  []string
+	kubeVersion  string
+	noDecrypt    bool
+	profile      string
+	remoteValues remoteValues
+	coverage     bool
+	matrix       bool
+	out          io.Writer
+	in           io.Reader
+}
+
+func newTemplateCmd(out io.Writer) *cobra.Command {
+	t := &templateCmd{out: out, in: os.Stdin}
+
+	cmd := &cobra.Command{
+		Use:   "template [flags] CHART",
+		Short: "locally render templates",
+		Long:  templateDesc,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkArgsLength(len(args), "chart path"); err != nil {
+				return err
+			}
+			t.chartPath = args[0]
+			if t.chartPath == "-" {
+				if t.valuesFile == "-" {
+					return errors.New("the chart and the values file cannot both be read from stdin")
+				}
+				var err error
+				if t.chart, err = readStdinChart(t.in); err != nil {
+					return err
+				}
+			}
+			return t.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&t.name, "name", "n", "RELEASE-NAME", "release name")
+	f.StringVar(&t.namespace, "namespace", "default", "namespace the release would be installed into")
+	f.StringVarP(&t.valuesFile, "values", "f", "", "specify values in a YAML file or a URL (append #sha256=DIGEST to pin its content)")
+	f.StringVar(&t.values, "set", "", "set values on the command line. Separate values with commas: key1=val1,key2=val2")
+	f.StringVar(&t.jsonValues, "set-json", "", "set JSON values on the command line. Separate values with commas: key1=JSON1,key2=JSON2")
+	f.StringVar(&t.profile, "profile", "", "merge the named profile of the values file over its other values")
+	t.remoteValues.addFlags(f)
+	f.BoolVar(&t.noDecrypt, "no-decrypt", false, "do not decrypt SOPS-encrypted values files")
+	f.StringSliceVar(&t.apiVersions, "api-versions", []string{}, "API versions (and group/version/Kind resources) reported by .Capabilities.APIVersions")
+	f.BoolVar(&t.coverage, "coverage", false, "print which templates and branches were executed instead of the rendered manifests")
+	f.StringVar(&t.kubeVersion, "kube-version", chartutil.DefaultKubeVersion.GitVersion, "Kubernetes version reported by .Capabilities.KubeVersion. With --matrix, a comma-separated list of versions")
+	f.BoolVar(&t.matrix, "matrix", false, "render the chart for each of the --kube-version versions, and print the differences between them")
+
+	return cmd
+}
+
+func (t *templateCmd) run() error {
+	if t.matrix {
+		return t.runMatrix()
+	}
+	if strings.Contains(t.kubeVersion, ",") {
+		return errors.New("several --kube-version values need --matrix")
+	}
+
+	if t.coverage {
+		cov := engine.NewCoverage()
+		if _, err := t.renderWith(engine.New().WithCoverage(cov)); err != nil {
+			return err
+		}
+		fmt.Fprint(t.out, formatCoverage(cov.Blocks()))
+		return nil
+	}
+
+	m, err := t.render()
+	if err != nil {
+		return err
+	}
+	if err := t.checkDuplicates(m); err != nil {
+		return err
+	}
+	fmt.Fprint(t.out, m)
+	return nil
+}
+
+// checkDuplicates fails if more than one document of a rendered manifest
+// defines the same resource, listing the templates that define each one.
+func (t *templateCmd) checkDuplicates(manifest string) error {
+	dups, err := releaseutil.FindDuplicates(manifest, t.namespace)
+	if err != nil || len(dups) == 0 {
+		return err
+	}
+	b := bytes.NewBufferString("resources are defined more than once:")
+	for _, d := range dups {
+		fmt.Fprintf(b, "\n  %s: %s", d, strings.Join(d.Sources, ", "))
+	}
+	return errors.New(b.String())
+}
+
+// render renders the chart and returns the manifests, in the same
+// "# Source:" annotated format Tiller stores them in.
+func (t *templateCmd) render() (string, error) {
+	return t.renderWith(engine.New())
+}
+
+func (t *templateCmd) renderWith(eng *engine.Engine) (string, error) {
+	kv, err := parseKubeVersion(t.kubeVersion)
+	if err != nil {
+		return "", err
+	}
+	files, err := t.renderFor(eng, kv, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return joinFiles(files), nil
+}
+
+// joinFiles joins rendered templates into a manifest, annotating each with
+// the template it was rendered from.
+func joinFiles(files map[string]string) string {
+	b := bytes.NewBuffer(nil)
+	for _, name := range sortedNames(files) {
+		fmt.Fprintf(b, "---\n# Source: %s\n%s\n", name, files[name])
+	}
+	return b.String()
+}
+
+// renderFor renders the chart for a cluster of the given Kubernetes version
+// that serves apiVersions in addition to those given with --api-versions.
+func (t *templateCmd) renderFor(eng *engine.Engine, kv *kversion.Info, apiVersions []string) (map[string]string, error) {
+	c, err := t.loadChart()
+	if err != nil {
+		return nil, err
+	}
+
+	rawVals, err := (&installCmd{valuesFile: t.valuesFile, values: t.values, jsonValues: t.jsonValues, noDecrypt: t.noDecrypt, profile: t.profile, remoteValues: t.remoteValues}).vals()
+	if err != nil {
+		return nil, err
+	}
+
+	versions := append(append([]string{"v1"}, apiVersions...), t.apiVersions...)
+	caps := &chartutil.Capabilities{
+		APIVersions:   chartutil.NewVersionSet(versions...),
+		KubeVersion:   kv,
+		TillerVersion: version.GetVersionProto(),
+	}
+
+	options := chartutil.ReleaseOptions{Name: t.name, Time: timeconv.Now(), Namespace: t.namespace}
+	return renderFiles(eng, c, rawVals, options, caps)
+}
+
+func (t *templateCmd) loadChart() (*chart.Chart, error) {
+	if t.chart != nil {
+		return t.chart, nil
+	}
+	p, err := filepath.Abs(t.chartPath)
+	if err != nil {
+		return nil, err
+	}
+	c, err := chartutil.Load(p)
+	if err != nil {
+		return nil, prettyError(err)
+	}
+	return c, nil
+}
+
+// runMatrix renders the chart for each --kube-version, printing the first
+// rendering in full and the differences of the others from it.
+func (t *templateCmd) runMatrix() error {
+	if t.coverage {
+		return errors.New("--coverage cannot be used with --matrix")
+	}
+	var versions []*kversion.Info
+	for _, v := range strings.Split(t.kubeVersion, ",") {
+		kv, err := parseKubeVersion(strings.TrimSpace(v))
+		if err != nil {
+			return err
+		}
+		versions = append(versions, kv)
+	}
+
+	var (
+		base     map[string]string
+		baseName string
+		failed   int
+	)
+	for _, kv := range versions {
+		minor, _ := strconv.Atoi(kv.Minor)
+		files, err := t.renderFor(engine.New(), kv, chartutil.KubeAPIVersions(minor))
+		if err == nil {
+			err = t.checkDuplicates(joinFiles(files))
+		}
+		if err != nil {
+			fmt.Fprintf(t.out, "==> Kubernetes %s: %s\n", kv.GitVersion, err)
+			failed++
+			continue
+		}
+		if base == nil {
+			base, baseName = files, kv.GitVersion
+			fmt.Fprintf(t.out, "==> Kubernetes %s\n", kv.GitVersion)
+			fmt.Fprint(t.out, joinFiles(files))
+			continue
+		}
+
+		diff := diffFiles(base, files)
+		if diff == "" {
+			fmt.Fprintf(t.out, "==> Kubernetes %s: same as %s\n", kv.GitVersion, baseName)
+			continue
+		}
+		fmt.Fprintf(t.out, "==> Kubernetes %s: differences from %s\n%s", kv.GitVersion, baseName, diff)
+	}
+	if failed > 0 {
+		return fmt.Errorf("rendering failed for %d of %d Kubernetes versions", failed, len(versions))
+	}
+	return nil
+}
+
+// diffFiles returns a diff of the templates that differ between two
+// renderings, or the empty string if there are no differences.
+func diffFiles(a, b map[string]string) string {
+	all := map[string]string{}
+	for name := range a {
+		all[name] = ""
+	}
+	for name := range b {
+		all[name] = ""
+	}
+
+	out := bytes.NewBuffer(nil)
+	for _, name := range sortedNames(all) {
+		x, inA := a[name]
+		y, inB := b[name]
+		switch {
+		case x == y && inA == inB:
+		case !inA:
+			fmt.Fprintf(out, "# Source: %s (only in this version)\n", name)
+			fmt.Fprintln(out, "+ "+strings.Replace(strings.TrimSuffix(y, "\n"), "\n", "\n+ ", -1))
+		case !inB:
+			fmt.Fprintf(out, "# Source: %s (not rendered in this version)\n", name)
+		default:
+			fmt.Fprintf(out, "# Source: %s\n", name)
+			fmt.Fprintln(out, diffLines(x, y))
+		}
+	}
+	return out.String()
+}
+
+func sortedNames(files map[string]string) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// renderFiles renders a chart with the given values, returning the rendered
+// manifests by template name.
+func renderFiles(eng *engine.Engine, c *chart.Chart, rawVals []byte, options chartutil.ReleaseOptions, caps *chartutil.Capabilities) (map[string]string, error) {
+	vals, err := chartutil.ToRenderValues(c, &chart.Config{Raw: string(rawVals)}, options, caps)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := eng.Render(c, vals)
+	if err != nil {
+		if re, ok := err.(*engine.RenderError); ok && flagDebug && re.Source != "" {
+			return nil, fmt.Errorf("%s\n\n%s:\n%s", err, re.File, re.Source)
+		}
+		return nil, err
+	}
+
+	for name, content := range files {
+		// Skip partials, notes and empty files, just like Tiller does.
+		if strings.HasPrefix(path.Base(name), "_") || strings.HasSuffix(name, "NOTES.txt") {
+			delete(files, name)
+			continue
+		}
+		if len(strings.TrimSpace(content)) == 0 {
+			delete(files, name)
+		}
+	}
+	return files, nil
+}
+
+// formatCoverage summarizes the coverage of each template file, followed by
+// the blocks that were never executed.
+func formatCoverage(blocks []engine.CoverageBlock) string {
+	type count struct{ total, hit int }
+	files := []string{}
+	counts := map[string]*count{}
+	missed := []engine.CoverageBlock{}
+	var all count
+	for _, b := range blocks {
+		c, ok := counts[b.Template]
+		if !ok {
+			c = &count{}
+			counts[b.Template] = c
+			files = append(files, b.Template)
+		}
+		c.total++
+		all.total++
+		if b.Hits > 0 {
+			c.hit++
+			all.hit++
+		} else {
+			missed = append(missed, b)
+		}
+	}
+
+	percent := func(c *count) string {
+		if c.total == 0 {
+			return "-"
+		}
+		return fmt.Sprintf("%.1f%%", 100*float64(c.hit)/float64(c.total))
+	}
+
+	tbl := uitable.New()
+	tbl.AddRow("TEMPLATE", "BLOCKS", "EXECUTED", "COVERAGE")
+	for _, f := range files {
+		c := counts[f]
+		tbl.AddRow(f, c.total, c.hit, percent(c))
+	}
+	tbl.AddRow("TOTAL", all.total, all.hit, percent(&all))
+
+	b := bytes.NewBufferString(tbl.String() + "\n")
+	if len(missed) > 0 {
+		b.WriteString("\nNot executed:\n")
+		tbl = uitable.New()
+		for _, m := range missed {
+			what := m.Kind
+			if m.Kind == engine.BlockDefine {
+				what = fmt.Sprintf("define %q", m.Name)
+			}
+			tbl.AddRow(fmt.Sprintf("%s:%d", m.Template, m.Line), what)
+		}
+		b.WriteString(tbl.String() + "\n")
+	}
+	return b.String()
+}
+
+// parseKubeVersion turns a version such as "1.5.1" or "v1.5.1" into the
+// version information reported by the Kubernetes API server.
+func parseKubeVersion(v string) (*kversion.Info, error) {
+	sv, err := semver.NewVersion(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --kube-version %q: %s", v, err)
+	}
+	return &kversion.Info{
+		Major:      fmt.Sprint(sv.Major()),
+		Minor:      fmt.Sprint(sv.Minor()),
+		GitVersion: "v" + sv.String(),
+	}, nil
+}
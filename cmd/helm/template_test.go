@@ -0,0 +1,154 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"k8s.io/helm/pkg/chartutil"
+)
+
+func TestTemplateCmd(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+		err      bool
+	}{
+		{
+			name:     "render a chart",
+			args:     []string{"testdata/testcharts/alpine", "--name", "aeneas"},
+			expected: []string{"# Source: alpine/templates/alpine-pod.yaml", `name: "aeneas-my-alpine"`},
+		},
+		{
+			name:     "stub capabilities",
+			args:     []string{"testdata/testcharts/capabilities", "--api-versions", "batch/v2alpha1,apps/v1beta1/StatefulSet", "--kube-version", "v1.5.1"},
+			expected: []string{"kube: v1.5.1", `major: "1"`, `cronjob: "true"`, `statefulset: "true"`, `v1: "true"`, "tiller: v2"},
+		},
+		{
+			name:     "report coverage",
+			args:     []string{"testdata/testcharts/alpine", "--coverage"},
+			expected: []string{"alpine/templates/alpine-pod.yaml", "TOTAL", "100.0%"},
+		},
+		{
+			name: "duplicate resources",
+			args: []string{"testdata/testcharts/duplicates"},
+			err:  true,
+		},
+		{
+			name: "bad kube version",
+			args: []string{"testdata/testcharts/alpine", "--kube-version", "latest"},
+			err:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		buf := bytes.NewBuffer(nil)
+		cmd := newTemplateCmd(buf)
+		cmd.SetArgs(tt.args)
+		err := cmd.Execute()
+		if (err != nil) != tt.err {
+			t.Errorf("%q. expected error: %v, got %v", tt.name, tt.err, err)
+			continue
+		}
+		for _, e := range tt.expected {
+			if !strings.Contains(buf.String(), e) {
+				t.Errorf("%q. expected %q in\n%s", tt.name, e, buf.String())
+			}
+		}
+	}
+}
+
+func TestTemplateDuplicates(t *testing.T) {
+	cmd := newTemplateCmd(bytes.NewBuffer(nil))
+	cmd.SetArgs([]string{"testdata/testcharts/duplicates", "--namespace", "prod"})
+	err := cmd.Execute()
+	if err == nil {
+		t.Fatal("expected duplicate resources to fail")
+	}
+	expected := "resources are defined more than once:\n" +
+		"  ConfigMap prod/web: duplicates/templates/other.yaml, duplicates/templates/web.yaml\n" +
+		"  Deployment.apps prod/web: duplicates/templates/other.yaml, duplicates/templates/web.yaml"
+	if err.Error() != expected {
+		t.Errorf("expected error %q, got %q", expected, err)
+	}
+}
+
+func TestTemplateFromStdin(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	withStdin(t, packChart(t, "testdata/testcharts/alpine", chartutil.FormatTgz), func() {
+		cmd := newTemplateCmd(buf)
+		cmd.SetArgs([]string{"-", "--name", "aeneas", "--kube-version", "1.5,1.6", "--matrix"})
+		if err := cmd.Execute(); err != nil {
+			t.Fatal(err)
+		}
+	})
+	for _, e := range []string{"# Source: alpine/templates/alpine-pod.yaml", `name: "aeneas-my-alpine"`, "same as v1.5.0"} {
+		if !strings.Contains(buf.String(), e) {
+			t.Errorf("expected %q in\n%s", e, buf.String())
+		}
+	}
+
+	withStdin(t, nil, func() {
+		cmd := newTemplateCmd(bytes.NewBuffer(nil))
+		cmd.SetArgs([]string{"-"})
+		if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "no chart archive") {
+			t.Errorf("expected an empty stdin to fail, got %v", err)
+		}
+	})
+}
+
+func TestTemplateMatrix(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	cmd := newTemplateCmd(buf)
+	cmd.SetArgs([]string{"testdata/testcharts/capabilities", "--kube-version", "1.8,1.9", "--matrix"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range []string{
+		"==> Kubernetes v1.8.0\n---\n# Source: capabilities/templates/",
+		`  appsv1: "false"`,
+		"==> Kubernetes v1.9.0: differences from v1.8.0\n# Source: capabilities/templates/",
+		"+   kube: v1.9.0\n-   kube: v1.8.0",
+		`+   appsv1: "true"` + "\n" + `-   appsv1: "false"`,
+	} {
+		if !strings.Contains(buf.String(), e) {
+			t.Errorf("expected %q in\n%s", e, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), "-   cronjob") {
+		t.Errorf("expected unchanged lines not to be marked as changed, got\n%s", buf.String())
+	}
+
+	buf.Reset()
+	cmd = newTemplateCmd(buf)
+	cmd.SetArgs([]string{"testdata/testcharts/alpine", "--kube-version", "1.5, 1.6", "--matrix"})
+	if err := cmd.Execute(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "==> Kubernetes v1.6.0: same as v1.5.0") {
+		t.Errorf("expected identical renderings to be reported, got\n%s", buf.String())
+	}
+
+	cmd = newTemplateCmd(bytes.NewBuffer(nil))
+	cmd.SetArgs([]string{"testdata/testcharts/alpine", "--kube-version", "1.5,1.6"})
+	if err := cmd.Execute(); err == nil || !strings.Contains(err.Error(), "--matrix") {
+		t.Errorf("expected several versions without --matrix to fail, got %v", err)
+	}
+}
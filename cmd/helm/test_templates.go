@@ -0,0 +1,603 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/engine"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/releaseutil"
+	"k8s.io/helm/pkg/timeconv"
+	"k8s.io/helm/pkg/version"
+)
+
+const testTemplatesDesc = `
+This command renders a chart locally and checks the output against the test
+suites in the chart's tests/ directory. No cluster is needed, so template
+regressions can be caught as part of a chart's build.
+
+Every file matching tests/*_test.yaml is a suite:
+
+	suite: deployment
+	templates:
+	  - templates/deployment.yaml
+	tests:
+	  - it: sets the replica count
+	    set:
+	      replicaCount: 3
+	    asserts:
+	      - equal:
+	          path: spec.replicas
+	          value: 3
+	      - matchSnapshot: {}
+
+Each test renders the chart with its 'values' files (relative to the chart) and
+'set' values merged over the chart's defaults, then runs its assertions on the
+documents rendered from the suite's templates (or the test's 'template').
+
+Paths address fields with dots and indexes, e.g.
+'spec.template.spec.containers[0].image' or 'metadata.labels["app.kubernetes.io/name"]'.
+Available assertions are equal and notEqual (path, value), matchRegex (path,
+pattern), exists and notExists (path), hasDocuments (count) and matchSnapshot.
+Add 'documentIndex' to an assertion to check a single document instead of all
+of them.
+
+matchSnapshot compares the rendered output with the snapshot stored under
+tests/__snapshot__/ and prints a diff if they differ. Missing snapshots are
+written on the first run; pass --update-snapshot to overwrite changed ones.
+`
+
+// snapshotDir is the directory, under a chart's tests, that holds snapshots.
+const snapshotDir = "__snapshot__"
+
+// testReleaseTime is the release time rendered into tests, fixed so that
+// snapshots are stable.
+var testReleaseTime = time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+
+type testTemplatesCmd struct {
+	chartPath      string
+	updateSnapshot bool
+	out            io.Writer
+}
+
+func newTestTemplatesCmd(out io.Writer) *cobra.Command {
+	tc := &testTemplatesCmd{out: out}
+
+	cmd := &cobra.Command{
+		Use:   "test-templates [flags] CHART",
+		Short: "run a chart's template tests without a cluster",
+		Long:  testTemplatesDesc,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := checkArgsLength(len(args), "chart path"); err != nil {
+				return err
+			}
+			tc.chartPath = args[0]
+			return tc.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVarP(&tc.updateSnapshot, "update-snapshot", "u", false, "overwrite snapshots that no longer match")
+
+	return cmd
+}
+
+// templateSuite is a file of template tests.
+type templateSuite struct {
+	Suite     string   `json:"suite"`
+	Templates []string `json:"templates"`
+	Release   struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"release"`
+	Tests []*templateTest `json:"tests"`
+}
+
+type templateTest struct {
+	It       string                 `json:"it"`
+	Template string                 `json:"template"`
+	Values   []string               `json:"values"`
+	Set      map[string]interface{} `json:"set"`
+	Asserts  []*templateAssertion   `json:"asserts"`
+}
+
+type templateAssertion struct {
+	DocumentIndex *int            `json:"documentIndex"`
+	Equal         *pathAssertion  `json:"equal"`
+	NotEqual      *pathAssertion  `json:"notEqual"`
+	MatchRegex    *pathAssertion  `json:"matchRegex"`
+	Exists        *pathAssertion  `json:"exists"`
+	NotExists     *pathAssertion  `json:"notExists"`
+	HasDocuments  *countAssertion `json:"hasDocuments"`
+	MatchSnapshot *struct{}       `json:"matchSnapshot"`
+}
+
+type pathAssertion struct {
+	Path    string      `json:"path"`
+	Value   interface{} `json:"value"`
+	Pattern string      `json:"pattern"`
+}
+
+type countAssertion struct {
+	Count int `json:"count"`
+}
+
+// renderedDoc is one YAML document rendered from a template.
+type renderedDoc struct {
+	template string
+	content  map[string]interface{}
+}
+
+// snapshots are the snapshots of one suite, keyed by test name and position.
+type snapshots struct {
+	path    string
+	entries map[string]string
+	changed bool
+}
+
+func (tc *testTemplatesCmd) run() error {
+	dir, err := filepath.Abs(tc.chartPath)
+	if err != nil {
+		return err
+	}
+	if fi, err := os.Stat(dir); err != nil {
+		return err
+	} else if !fi.IsDir() {
+		return fmt.Errorf("%s is not a chart directory", tc.chartPath)
+	}
+	c, err := chartutil.Load(dir)
+	if err != nil {
+		return prettyError(err)
+	}
+
+	suites, err := filepath.Glob(filepath.Join(dir, "tests", "*_test.yaml"))
+	if err != nil {
+		return err
+	}
+	if len(suites) == 0 {
+		return fmt.Errorf("no test suites found in %s", filepath.Join(tc.chartPath, "tests"))
+	}
+	sort.Strings(suites)
+
+	var passed, failed, written int
+	for _, file := range suites {
+		results, err := tc.runSuite(c, dir, file)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, file)
+		status := "PASS"
+		for _, r := range results {
+			if len(r.failures) > 0 {
+				status = "FAIL"
+			}
+		}
+		fmt.Fprintf(tc.out, "%s\t%s\n", status, rel)
+		for _, r := range results {
+			written += r.written
+			if len(r.failures) == 0 {
+				passed++
+				continue
+			}
+			failed++
+			fmt.Fprintf(tc.out, "\t- %s\n", r.name)
+			for _, f := range r.failures {
+				fmt.Fprintf(tc.out, "\t\t%s\n", strings.Replace(f, "\n", "\n\t\t", -1))
+			}
+		}
+	}
+
+	fmt.Fprintf(tc.out, "\nTests: %d passed, %d failed, %d total\n", passed, failed, passed+failed)
+	if written > 0 {
+		fmt.Fprintf(tc.out, "Snapshots: %d written\n", written)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d tests failed", failed, passed+failed)
+	}
+	return nil
+}
+
+type testResult struct {
+	name     string
+	failures []string
+	written  int
+}
+
+func (tc *testTemplatesCmd) runSuite(c *chart.Chart, dir, file string) ([]testResult, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var suite templateSuite
+	if err := yaml.Unmarshal(b, &suite); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %s", file, err)
+	}
+
+	snaps, err := loadSnapshots(filepath.Join(filepath.Dir(file), snapshotDir, strings.TrimSuffix(filepath.Base(file), ".yaml")+".snap"))
+	if err != nil {
+		return nil, err
+	}
+
+	kv, err := parseKubeVersion(chartutil.DefaultKubeVersion.GitVersion)
+	if err != nil {
+		return nil, err
+	}
+	caps := &chartutil.Capabilities{
+		APIVersions:   chartutil.DefaultVersionSet,
+		KubeVersion:   kv,
+		TillerVersion: version.GetVersionProto(),
+	}
+	options := chartutil.ReleaseOptions{
+		Name:      suite.Release.Name,
+		Namespace: suite.Release.Namespace,
+		Time:      timeconv.Timestamp(testReleaseTime),
+	}
+	if options.Name == "" {
+		options.Name = "RELEASE-NAME"
+	}
+	if options.Namespace == "" {
+		options.Namespace = "default"
+	}
+
+	results := []testResult{}
+	for _, test := range suite.Tests {
+		r := testResult{name: test.It}
+		files, err := renderTest(c, dir, test, options, caps)
+		if err != nil {
+			r.failures = append(r.failures, err.Error())
+			results = append(results, r)
+			continue
+		}
+
+		templates := suite.Templates
+		if test.Template != "" {
+			templates = []string{test.Template}
+		}
+		names, docs, err := selectDocs(c.Metadata.Name, files, templates)
+		if err != nil {
+			r.failures = append(r.failures, err.Error())
+			results = append(results, r)
+			continue
+		}
+
+		snapshot := 0
+		for _, a := range test.Asserts {
+			if a.MatchSnapshot != nil {
+				snapshot++
+				key := fmt.Sprintf("%s %d", test.It, snapshot)
+				got := snapshotContent(names, files)
+				if err := snaps.match(key, got, tc.updateSnapshot, &r.written); err != nil {
+					r.failures = append(r.failures, err.Error())
+				}
+				continue
+			}
+			if err := a.check(docs); err != nil {
+				r.failures = append(r.failures, err.Error())
+			}
+		}
+		results = append(results, r)
+	}
+
+	if err := snaps.save(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// renderTest renders the chart with the values of a test.
+func renderTest(c *chart.Chart, dir string, test *templateTest, options chartutil.ReleaseOptions, caps *chartutil.Capabilities) (map[string]string, error) {
+	vals := map[string]interface{}{}
+	for _, f := range test.Values {
+		b, err := ioutil.ReadFile(filepath.Join(dir, f))
+		if err != nil {
+			return nil, err
+		}
+		m := map[string]interface{}{}
+		if err := yaml.Unmarshal(b, &m); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %s", f, err)
+		}
+		chartutil.MergeValues(vals, m)
+	}
+	chartutil.MergeValues(vals, test.Set)
+
+	raw, err := yaml.Marshal(vals)
+	if err != nil {
+		return nil, err
+	}
+	return renderFiles(engine.New(), c, raw, options, caps)
+}
+
+// selectDocs returns the names of the rendered templates that match templates
+// (all of them, if templates is empty) and the documents they contain.
+//
+// Template names may be given with or without the leading chart name.
+func selectDocs(chartName string, files map[string]string, templates []string) ([]string, []renderedDoc, error) {
+	names := []string{}
+	for name := range files {
+		if len(templates) == 0 {
+			names = append(names, name)
+			continue
+		}
+		for _, t := range templates {
+			if name == t || name == chartName+"/"+t {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	sort.Strings(names)
+
+	docs := []renderedDoc{}
+	for _, name := range names {
+		split, err := releaseutil.SplitManifest(files[name])
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: could not parse rendered output: %s", name, err)
+		}
+		for _, d := range split {
+			m := map[string]interface{}{}
+			if err := yaml.Unmarshal([]byte(d.Content), &m); err != nil {
+				return nil, nil, fmt.Errorf("%s: could not parse rendered output: %s", name, err)
+			}
+			docs = append(docs, renderedDoc{template: name, content: m})
+		}
+	}
+	return names, docs, nil
+}
+
+// check runs a non-snapshot assertion against the documents.
+func (a *templateAssertion) check(docs []renderedDoc) error {
+	if a.HasDocuments != nil {
+		if len(docs) != a.HasDocuments.Count {
+			return fmt.Errorf("hasDocuments: expected %d documents, got %d", a.HasDocuments.Count, len(docs))
+		}
+		return nil
+	}
+
+	if a.DocumentIndex != nil {
+		i := *a.DocumentIndex
+		if i < 0 || i >= len(docs) {
+			return fmt.Errorf("documentIndex %d is out of range: %d documents were rendered", i, len(docs))
+		}
+		docs = docs[i : i+1]
+	}
+	if len(docs) == 0 {
+		return errors.New("no documents were rendered")
+	}
+
+	for _, d := range docs {
+		if err := a.checkDoc(d.content); err != nil {
+			return fmt.Errorf("%s: %s", d.template, err)
+		}
+	}
+	return nil
+}
+
+func (a *templateAssertion) checkDoc(doc map[string]interface{}) error {
+	switch {
+	case a.Equal != nil:
+		got, _, err := lookupPath(doc, a.Equal.Path)
+		if err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(got, a.Equal.Value) {
+			return fmt.Errorf("equal: expected %s to be %s, got %s", a.Equal.Path, formatValue(a.Equal.Value), formatValue(got))
+		}
+	case a.NotEqual != nil:
+		got, _, err := lookupPath(doc, a.NotEqual.Path)
+		if err != nil {
+			return err
+		}
+		if reflect.DeepEqual(got, a.NotEqual.Value) {
+			return fmt.Errorf("notEqual: expected %s not to be %s", a.NotEqual.Path, formatValue(got))
+		}
+	case a.MatchRegex != nil:
+		re, err := regexp.Compile(a.MatchRegex.Pattern)
+		if err != nil {
+			return fmt.Errorf("matchRegex: invalid pattern: %s", err)
+		}
+		got, _, err := lookupPath(doc, a.MatchRegex.Path)
+		if err != nil {
+			return err
+		}
+		s, ok := got.(string)
+		if !ok {
+			return fmt.Errorf("matchRegex: expected %s to be a string, got %s", a.MatchRegex.Path, formatValue(got))
+		}
+		if !re.MatchString(s) {
+			return fmt.Errorf("matchRegex: expected %s to match %q, got %q", a.MatchRegex.Path, a.MatchRegex.Pattern, s)
+		}
+	case a.Exists != nil:
+		if _, ok, err := lookupPath(doc, a.Exists.Path); err != nil {
+			return err
+		} else if !ok {
+			return fmt.Errorf("exists: expected %s to be set", a.Exists.Path)
+		}
+	case a.NotExists != nil:
+		if got, ok, err := lookupPath(doc, a.NotExists.Path); err != nil {
+			return err
+		} else if ok {
+			return fmt.Errorf("notExists: expected %s not to be set, got %s", a.NotExists.Path, formatValue(got))
+		}
+	default:
+		return errors.New("unknown or empty assertion")
+	}
+	return nil
+}
+
+func formatValue(v interface{}) string {
+	if v == nil {
+		return "null"
+	}
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return strings.TrimSpace(string(b))
+}
+
+// pathToken matches one step of a path: a key, an index or a quoted key.
+var pathToken = regexp.MustCompile(`^(?:\.?([^.\[\]]+)|\[(\d+)\]|\["([^"]*)"\])`)
+
+// lookupPath finds the value at a path such as "spec.containers[0].image" in
+// a document. The boolean reports whether the value is present.
+func lookupPath(doc interface{}, p string) (interface{}, bool, error) {
+	cur := doc
+	for rest := p; rest != ""; {
+		m := pathToken.FindStringSubmatch(rest)
+		if m == nil {
+			return nil, false, fmt.Errorf("invalid path %q", p)
+		}
+		rest = rest[len(m[0]):]
+
+		if m[2] != "" {
+			i, _ := strconv.Atoi(m[2])
+			l, ok := cur.([]interface{})
+			if !ok || i >= len(l) {
+				return nil, false, nil
+			}
+			cur = l[i]
+			continue
+		}
+
+		key := m[1]
+		if m[0][0] == '[' {
+			key = m[3]
+		}
+		t, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, false, nil
+		}
+		if cur, ok = t[key]; !ok {
+			return nil, false, nil
+		}
+	}
+	return cur, true, nil
+}
+
+// snapshotContent is the rendered output of the named templates, as stored
+// in a snapshot.
+func snapshotContent(names []string, files map[string]string) string {
+	b := bytes.NewBuffer(nil)
+	for _, name := range names {
+		fmt.Fprintf(b, "# Source: %s\n%s\n", name, strings.TrimSpace(files[name]))
+	}
+	return b.String()
+}
+
+func loadSnapshots(path string) (*snapshots, error) {
+	s := &snapshots{path: path, entries: map[string]string{}}
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(b, &s.entries); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %s", path, err)
+	}
+	return s, nil
+}
+
+// match compares got with the snapshot stored under key. New snapshots are
+// recorded, and so are changed ones if update is set.
+func (s *snapshots) match(key, got string, update bool, written *int) error {
+	want, ok := s.entries[key]
+	if ok && want == got {
+		return nil
+	}
+	if ok && !update {
+		return fmt.Errorf("matchSnapshot: output differs from the snapshot (run with --update-snapshot to accept it)\n%s", diffLines(want, got))
+	}
+	s.entries[key] = got
+	s.changed = true
+	*written++
+	return nil
+}
+
+func (s *snapshots) save() error {
+	if !s.changed {
+		return nil
+	}
+	b, err := yaml.Marshal(s.entries)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.path, b, 0644)
+}
+
+// diffLines returns a line diff of two texts, with removed lines prefixed by
+// "-" and added lines by "+".
+func diffLines(a, b string) string {
+	x := strings.Split(strings.TrimSuffix(a, "\n"), "\n")
+	y := strings.Split(strings.TrimSuffix(b, "\n"), "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of x[i:]
+	// and y[j:].
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	out := bytes.NewBuffer(nil)
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			fmt.Fprintf(out, "  %s\n", x[i])
+			i++
+			j++
+		case j < len(y) && (i == len(x) || lcs[i][j+1] >= lcs[i+1][j]):
+			fmt.Fprintf(out, "+ %s\n", y[j])
+			j++
+		default:
+			fmt.Fprintf(out, "- %s\n", x[i])
+			i++
+		}
+	}
+	return strings.TrimSuffix(out.String(), "\n")
+}
@@ -0,0 +1,241 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testTemplatesDeployment = `apiVersion: extensions/v1beta1
+kind: Deployment
+metadata:
+  name: {{ .Release.Name }}-web
+  labels:
+    app.kubernetes.io/name: web
+spec:
+  replicas: {{ .Values.replicas }}
+  template:
+    spec:
+      containers:
+      - name: web
+        image: "{{ .Values.image }}"
+{{- if .Values.service }}
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: {{ .Release.Name }}-web
+{{- end }}
+`
+
+const testTemplatesSuite = `suite: web
+templates:
+  - templates/web.yaml
+tests:
+  - it: uses the defaults
+    asserts:
+      - hasDocuments:
+          count: 1
+      - equal:
+          path: spec.replicas
+          value: 1
+      - equal:
+          path: metadata.labels["app.kubernetes.io/name"]
+          value: web
+      - matchRegex:
+          path: spec.template.spec.containers[0].image
+          pattern: '^nginx:'
+      - notExists:
+          path: spec.strategy
+      - matchSnapshot: {}
+  - it: adds a service
+    set:
+      service: true
+    asserts:
+      - hasDocuments:
+          count: 2
+      - equal:
+          path: kind
+          value: Service
+        documentIndex: 1
+`
+
+func writeTestTemplatesChart(t *testing.T, suite string) string {
+	dir, err := ioutil.TempDir("", "helm-test-templates-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"Chart.yaml":             "name: web\nversion: 0.1.0\n",
+		"values.yaml":            "replicas: 1\nimage: nginx:1.11\nservice: false\n",
+		"templates/web.yaml":     testTemplatesDeployment,
+		"tests/web_test.yaml":    suite,
+		"tests/values/big.yaml":  "replicas: 5\n",
+		"templates/_helpers.tpl": `{{ define "web.name" }}web{{ end }}`,
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func runTestTemplates(dir string, args ...string) (string, error) {
+	buf := bytes.NewBuffer(nil)
+	cmd := newTestTemplatesCmd(buf)
+	cmd.SetArgs(append(args, dir))
+	err := cmd.Execute()
+	return buf.String(), err
+}
+
+func TestTestTemplatesCmd(t *testing.T) {
+	dir := writeTestTemplatesChart(t, testTemplatesSuite)
+	defer os.RemoveAll(dir)
+
+	out, err := runTestTemplates(dir)
+	if err != nil {
+		t.Fatalf("%s\n%s", err, out)
+	}
+	for _, e := range []string{"PASS\ttests/web_test.yaml", "Tests: 2 passed, 0 failed, 2 total", "Snapshots: 1 written"} {
+		if !strings.Contains(out, e) {
+			t.Errorf("expected %q in\n%s", e, out)
+		}
+	}
+	snap, err := ioutil.ReadFile(filepath.Join(dir, "tests", snapshotDir, "web_test.snap"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(snap), "RELEASE-NAME-web") {
+		t.Errorf("unexpected snapshot:\n%s", snap)
+	}
+
+	// A second run compares against the snapshot that was just written.
+	out, err = runTestTemplates(dir)
+	if err != nil {
+		t.Fatalf("%s\n%s", err, out)
+	}
+	if strings.Contains(out, "Snapshots:") {
+		t.Errorf("expected no snapshots to be written:\n%s", out)
+	}
+
+	// Changing the chart breaks the snapshot until it is updated.
+	values := filepath.Join(dir, "values.yaml")
+	if err := ioutil.WriteFile(values, []byte("replicas: 1\nimage: nginx:1.12\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out, err = runTestTemplates(dir)
+	if err == nil {
+		t.Fatalf("expected the snapshot to fail:\n%s", out)
+	}
+	for _, e := range []string{"FAIL\ttests/web_test.yaml", "- uses the defaults", `-         image: "nginx:1.11"`, `+         image: "nginx:1.12"`} {
+		if !strings.Contains(out, e) {
+			t.Errorf("expected %q in\n%s", e, out)
+		}
+	}
+	if out, err = runTestTemplates(dir, "--update-snapshot"); err != nil {
+		t.Fatalf("%s\n%s", err, out)
+	}
+	if out, err = runTestTemplates(dir); err != nil {
+		t.Fatalf("%s\n%s", err, out)
+	}
+}
+
+func TestTestTemplatesFailures(t *testing.T) {
+	suite := `suite: web
+tests:
+  - it: reads values files
+    values:
+      - tests/values/big.yaml
+    asserts:
+      - equal:
+          path: spec.replicas
+          value: 3
+      - exists:
+          path: spec.template.spec.containers[1]
+      - hasDocuments:
+          count: 2
+  - it: checks an out of range document
+    asserts:
+      - notEqual:
+          path: kind
+          value: Deployment
+        documentIndex: 3
+`
+	dir := writeTestTemplatesChart(t, suite)
+	defer os.RemoveAll(dir)
+
+	out, err := runTestTemplates(dir)
+	if err == nil {
+		t.Fatalf("expected failures:\n%s", out)
+	}
+	for _, e := range []string{
+		"equal: expected spec.replicas to be 3, got 5",
+		"exists: expected spec.template.spec.containers[1] to be set",
+		"hasDocuments: expected 2 documents, got 1",
+		"documentIndex 3 is out of range",
+		"Tests: 0 passed, 2 failed, 2 total",
+	} {
+		if !strings.Contains(out, e) {
+			t.Errorf("expected %q in\n%s", e, out)
+		}
+	}
+}
+
+func TestLookupPath(t *testing.T) {
+	doc := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]interface{}{"app.kubernetes.io/name": "web"},
+		},
+		"spec": map[string]interface{}{
+			"containers": []interface{}{map[string]interface{}{"image": "nginx"}},
+		},
+	}
+	tests := []struct {
+		path   string
+		expect interface{}
+		found  bool
+	}{
+		{`spec.containers[0].image`, "nginx", true},
+		{`metadata.labels["app.kubernetes.io/name"]`, "web", true},
+		{`spec.containers[1].image`, nil, false},
+		{`spec.replicas`, nil, false},
+		{`metadata.labels.app`, nil, false},
+	}
+	for _, tt := range tests {
+		got, found, err := lookupPath(doc, tt.path)
+		if err != nil {
+			t.Errorf("%s: %s", tt.path, err)
+			continue
+		}
+		if found != tt.found || got != tt.expect {
+			t.Errorf("%s: expected %v (%t), got %v (%t)", tt.path, tt.expect, tt.found, got, found)
+		}
+	}
+	if _, _, err := lookupPath(doc, "spec[x]"); err == nil {
+		t.Error("expected an invalid path error")
+	}
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/helm/pkg/helm"
+)
+
+const tillerDesc = `
+This command consists of subcommands to administer the Tiller server itself,
+rather than the releases it manages.
+`
+
+func newTillerCmd(client helm.Interface, out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "tiller [FLAGS] maintain [ARGS]",
+		Short:             "administer the Tiller server",
+		Long:              tillerDesc,
+		PersistentPreRunE: setupConnection,
+	}
+
+	cmd.AddCommand(newTillerMaintainCmd(client, out))
+
+	return cmd
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+
+	"k8s.io/helm/pkg/helm"
+)
+
+const tillerMaintainDesc = `
+This command maintains the release records that Tiller stores, and reports
+how many revisions of each release are stored and how large they are.
+
+With '--history-max', the history of each release is compacted to that many
+revisions. The oldest revisions are removed, but never the deployed revision
+of a release or the revisions after it, so that a release can still be
+rolled back to the revision it runs.
+
+The records left behind by a 'helm delete --purge' that did not finish,
+where every revision that is left is superseded, are removed.
+
+The records are also checked: records that cannot be read, releases with
+more than one deployed revision and, if Tiller signs its records, records
+whose signature does not verify are reported, and the command fails.
+
+'--dry-run' reports what would be removed without removing anything.
+`
+
+type tillerMaintainCmd struct {
+	maxHistory int32
+	dryRun     bool
+	out        io.Writer
+	client     helm.Interface
+}
+
+func newTillerMaintainCmd(client helm.Interface, out io.Writer) *cobra.Command {
+	m := &tillerMaintainCmd{out: out, client: client}
+
+	cmd := &cobra.Command{
+		Use:   "maintain [flags]",
+		Short: "compact, clean up and check the release storage",
+		Long:  tillerMaintainDesc,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if m.maxHistory < 0 {
+				return errors.New("--history-max cannot be negative")
+			}
+			m.client = ensureHelmClient(m.client)
+			return m.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.Int32Var(&m.maxHistory, "history-max", 0, "the most revisions of each release to keep. 0 keeps every revision")
+	f.BoolVar(&m.dryRun, "dry-run", false, "report what would be removed without removing it")
+
+	return cmd
+}
+
+func (m *tillerMaintainCmd) run() error {
+	res, err := m.client.MaintainStorage(helm.MaintainMaxHistory(m.maxHistory), helm.MaintainDryRun(m.dryRun))
+	if err != nil {
+		return prettyError(err)
+	}
+
+	removedHeader := "REMOVED"
+	if m.dryRun {
+		removedHeader = "TO REMOVE"
+	}
+	tbl := uitable.New()
+	tbl.AddRow("RELEASE", "REVISIONS", "BYTES", removedHeader, "NOTE")
+	var revisions, removed int32
+	var size int64
+	for _, u := range res.Releases {
+		note := ""
+		if u.Orphaned {
+			note = "orphaned by a purge"
+		}
+		tbl.AddRow(u.Name, u.Revisions, u.Bytes, u.Removed, note)
+		revisions += u.Revisions
+		removed += u.Removed
+		size += u.Bytes
+	}
+	fmt.Fprintln(m.out, tbl)
+
+	verb := "Removed"
+	if m.dryRun {
+		verb = "Would remove"
+	}
+	fmt.Fprintf(m.out, "%d revisions of %d releases are stored (%d bytes). %s %d revisions.\n", revisions, len(res.Releases), size, verb, removed)
+
+	if len(res.Problems) == 0 {
+		return nil
+	}
+	fmt.Fprintln(m.out, "\nPROBLEMS:")
+	for _, p := range res.Problems {
+		fmt.Fprintf(m.out, "  %s\n", p)
+	}
+	return fmt.Errorf("%d problems found in the release storage", len(res.Problems))
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"k8s.io/helm/pkg/helm"
+	rls "k8s.io/helm/pkg/proto/hapi/services"
+)
+
+type fakeMaintainClient struct {
+	fakeReleaseClient
+	res *rls.MaintainStorageResponse
+}
+
+func (c *fakeMaintainClient) MaintainStorage(opts ...helm.MaintainOption) (*rls.MaintainStorageResponse, error) {
+	return c.res, nil
+}
+
+func TestTillerMaintain(t *testing.T) {
+	releases := []*rls.StorageUsage{
+		{Name: "aeneas", Revisions: 12, Bytes: 48000, Removed: 2},
+		{Name: "dido", Revisions: 3, Bytes: 9000, Removed: 3, Orphaned: true},
+	}
+	tests := []struct {
+		name     string
+		flags    []string
+		problems []string
+		expect   []string
+		err      bool
+	}{
+		{
+			name:  "compact",
+			flags: []string{"--history-max", "10"},
+			expect: []string{
+				"RELEASE\tREVISIONS\tBYTES\tREMOVED\tNOTE",
+				"aeneas \t12       \t48000\t2      \t",
+				"dido   \t3        \t9000 \t3      \torphaned by a purge",
+				"15 revisions of 2 releases are stored (57000 bytes). Removed 5 revisions.",
+			},
+		},
+		{
+			name:   "dry run",
+			flags:  []string{"--history-max", "10", "--dry-run"},
+			expect: []string{"TO REMOVE", "Would remove 5 revisions."},
+		},
+		{
+			name:     "problems",
+			problems: []string{"release dido has 2 deployed revisions: v1, v2"},
+			expect:   []string{"PROBLEMS:\n  release dido has 2 deployed revisions: v1, v2\n"},
+			err:      true,
+		},
+		{
+			name:  "negative history",
+			flags: []string{"--history-max", "-1"},
+			err:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		c := &fakeMaintainClient{res: &rls.MaintainStorageResponse{Releases: releases, Problems: tt.problems}}
+		out := bytes.NewBuffer(nil)
+		cmd := newTillerMaintainCmd(c, out)
+		cmd.ParseFlags(tt.flags)
+		err := cmd.RunE(cmd, nil)
+		if (err != nil) != tt.err {
+			t.Errorf("%q: expected error %v, got %v", tt.name, tt.err, err)
+		}
+		for _, e := range tt.expect {
+			if !strings.Contains(out.String(), e) {
+				t.Errorf("%q: expected %q in\n%s", tt.name, e, out.String())
+			}
+		}
+	}
+}
@@ -19,7 +19,7 @@ package main
 import (
 	"fmt"
 	"io"
-	"io/ioutil"
+	"strconv"
 	"strings"
 
 	"github.com/ghodss/yaml"
@@ -40,22 +40,60 @@ version will be specified unless the '--version' flag is set.
 
 To override values in a chart, use either the '--values' flag and pass in a file
 or use the '--set' flag and pass configuration from the command line.
+
+To upgrade only part of a release, use '--include' and '--exclude' with
+template path globs or Kind/name selectors. Resources rendered from the other
+templates are left as they are.
+
+To upgrade only the resources of a subchart of an umbrella chart, name it with
+'--only-subchart'. The whole chart is still rendered with the new values, but
+only the templates of that subchart, and of its own subcharts, are applied.
+Nested subcharts are named by their path, e.g. 'backend/redis':
+
+	$ helm upgrade my-release ./umbrella --only-subchart redis --set redis.image.tag=4.0.2
 `
 
 type upgradeCmd struct {
-	release      string
-	chart        string
-	out          io.Writer
-	client       helm.Interface
-	dryRun       bool
-	disableHooks bool
-	valuesFile   string
-	values       string
-	verify       bool
-	keyring      string
-	install      bool
-	namespace    string
-	version      string
+	release       string
+	chart         string
+	out           io.Writer
+	client        helm.Interface
+	dryRun        bool
+	serverDryRun  bool
+	disableHooks  bool
+	valuesFile    string
+	contexts      kubeContexts
+	valuesOverlay string // merged over valuesFile, for one of contexts
+	valuesFrom    valuesFrom
+	values        string
+	jsonValues    string
+	verify        bool
+	keyring       string
+	install       bool
+	namespace     string
+	version       string
+	serverSide    bool
+	include       []string
+	exclude       []string
+	onlySubcharts []string
+	versionCheck  bool
+	noDecrypt     bool
+	noDeprecated  bool
+	subNotes      bool
+	profile       string
+	remoteValues  remoteValues
+	progress      bool
+	wait          bool
+	waitForJobs   bool
+	timeout       int64
+	metadata      []string
+	overrideLock  string
+	overrideGates string
+	skipPreflight bool
+	canary        string
+	canaryWatch   int64
+	canaryCheck   string
+	blueGreen     bool
 }
 
 func newUpgradeCmd(client helm.Interface, out io.Writer) *cobra.Command {
@@ -69,7 +107,7 @@ func newUpgradeCmd(client helm.Interface, out io.Writer) *cobra.Command {
 		Use:               "upgrade [RELEASE] [CHART]",
 		Short:             "upgrade a release",
 		Long:              upgradeDesc,
-		PersistentPreRunE: setupConnection,
+		PersistentPreRunE: upgrade.contexts.setupConnection,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := checkArgsLength(len(args), "release name", "chart path"); err != nil {
 				return err
@@ -77,6 +115,16 @@ func newUpgradeCmd(client helm.Interface, out io.Writer) *cobra.Command {
 
 			upgrade.release = args[0]
 			upgrade.chart = args[1]
+			if contextNamespace != "" && !cmd.Flags().Changed("namespace") {
+				upgrade.namespace = contextNamespace
+			}
+			if upgrade.contexts.enabled() {
+				return upgrade.contexts.run(upgrade.out, func(client helm.Interface, valuesOverlay string) error {
+					u := *upgrade
+					u.client, u.valuesOverlay = client, valuesOverlay
+					return u.run()
+				})
+			}
 			upgrade.client = ensureHelmClient(upgrade.client)
 
 			return upgrade.run()
@@ -84,9 +132,10 @@ func newUpgradeCmd(client helm.Interface, out io.Writer) *cobra.Command {
 	}
 
 	f := cmd.Flags()
-	f.StringVarP(&upgrade.valuesFile, "values", "f", "", "path to a values YAML file")
-	f.BoolVar(&upgrade.dryRun, "dry-run", false, "simulate an upgrade")
+	f.StringVarP(&upgrade.valuesFile, "values", "f", "", "path or URL of a values YAML file (append #sha256=DIGEST to a URL to pin its content)")
+	addDryRunFlag(f, &upgrade.dryRun, &upgrade.serverDryRun, "simulate an upgrade. With --dry-run=server, the Kubernetes API also validates the upgraded resources")
 	f.StringVar(&upgrade.values, "set", "", "set values on the command line. Separate values with commas: key1=val1,key2=val2")
+	f.StringVar(&upgrade.jsonValues, "set-json", "", "set JSON values on the command line. Separate values with commas: key1=JSON1,key2=JSON2")
 	f.BoolVar(&upgrade.disableHooks, "disable-hooks", false, "disable pre/post upgrade hooks. DEPRECATED. Use no-hooks")
 	f.BoolVar(&upgrade.disableHooks, "no-hooks", false, "disable pre/post upgrade hooks")
 	f.BoolVar(&upgrade.verify, "verify", false, "verify the provenance of the chart before upgrading")
@@ -94,6 +143,31 @@ func newUpgradeCmd(client helm.Interface, out io.Writer) *cobra.Command {
 	f.BoolVarP(&upgrade.install, "install", "i", false, "if a release by this name doesn't already exist, run an install")
 	f.StringVar(&upgrade.namespace, "namespace", "default", "namespace to install the release into (only used if --install is set)")
 	f.StringVar(&upgrade.version, "version", "", "specify the exact chart version to use. If this is not specified, the latest version is used")
+	f.BoolVar(&upgrade.serverSide, "server-side", false, "update resources using server-side apply instead of client-side patching")
+	f.BoolVar(&upgrade.versionCheck, "force-version-check", true, "refuse to upgrade if the chart's kubeVersion or tillerVersion constraints are not met")
+	f.BoolVar(&upgrade.skipPreflight, "skip-preflight", false, "do not run the pre-flight checks of the chart")
+	f.BoolVar(&upgrade.noDeprecated, "no-deprecated", false, "refuse to upgrade to deprecated charts")
+	f.BoolVar(&upgrade.subNotes, "render-subchart-notes", false, "also render and show the notes of subcharts")
+	f.StringVar(&upgrade.profile, "profile", "", "merge the named profile of the values file over its other values")
+	upgrade.remoteValues.addFlags(f)
+	f.BoolVar(&upgrade.noDecrypt, "no-decrypt", false, "do not decrypt SOPS-encrypted values files")
+	f.BoolVar(&upgrade.progress, "progress", false, "print each change to the release's resources as it is made")
+	f.StringSliceVar(&upgrade.include, "include", []string{}, "only upgrade templates matching these path globs or Kind/name selectors")
+	f.StringSliceVar(&upgrade.exclude, "exclude", []string{}, "skip templates matching these path globs or Kind/name selectors")
+	f.StringSliceVar(&upgrade.onlySubcharts, "only-subchart", []string{}, "only upgrade the resources of this subchart, can be repeated")
+	f.BoolVar(&upgrade.wait, "wait", false, "wait until the release's Pods, Deployments, PersistentVolumeClaims and Services are ready before marking the release as successful")
+	f.BoolVar(&upgrade.waitForJobs, "wait-for-jobs", false, "also wait for the release's Jobs to complete. Implies --wait")
+	f.Int64Var(&upgrade.timeout, "timeout", 300, "time in seconds to wait with --wait, or for a canary to become ready")
+	f.StringSliceVar(&upgrade.metadata, "metadata", []string{}, "record metadata on the new revision, such as the commit being deployed: key1=val1,key2=val2")
+	f.StringVar(&upgrade.overrideLock, "override-lock", "", "upgrade the release even if it is locked, giving the reason for the change")
+	f.StringVar(&upgrade.overrideGates, "override-gates", "", "upgrade the release even if the deployment windows or gates of Tiller do not allow it, giving the reason for the change")
+	f.StringVar(&upgrade.canary, "canary", "", "first run this share of the replicas of each Deployment with the upgrade, e.g. 20%, and only roll it out in full if the canary passes")
+	f.Int64Var(&upgrade.canaryWatch, "canary-duration", 0, "time in seconds to watch the ready canary before it is promoted")
+	f.StringVar(&upgrade.canaryCheck, "canary-check", "", "URL Tiller posts the canary to once it has been watched. It must answer 200 OK for the canary to pass")
+	f.BoolVar(&upgrade.blueGreen, "blue-green", false, "run the Deployments of the upgrade next to the current ones, and only switch the Services over to them with 'helm promote'")
+
+	upgrade.valuesFrom.addFlags(f)
+	upgrade.contexts.addFlags(f)
 
 	f.MarkDeprecated("disable-hooks", "use --no-hooks instead")
 
@@ -105,6 +179,10 @@ func (u *upgradeCmd) run() error {
 	if err != nil {
 		return err
 	}
+	source, err := chartSource(u.chart, u.version, chartPath)
+	if err != nil {
+		return err
+	}
 
 	if u.install {
 		// If a release does not exist, install it. If another error occurs during
@@ -115,35 +193,89 @@ func (u *upgradeCmd) run() error {
 		// inside of the grpc.rpcError message.
 		_, err := u.client.ReleaseContent(u.release, helm.ContentReleaseVersion(1))
 		if err != nil && strings.Contains(err.Error(), driver.ErrReleaseNotFound.Error()) {
+			if len(u.onlySubcharts) > 0 {
+				return fmt.Errorf("release %q does not exist, and --only-subchart cannot install part of a chart", u.release)
+			}
 			fmt.Fprintf(u.out, "Release %q does not exist. Installing it now.\n", u.release)
 			ic := &installCmd{
-				chartPath:    chartPath,
-				client:       u.client,
-				out:          u.out,
-				name:         u.release,
-				valuesFile:   u.valuesFile,
-				dryRun:       u.dryRun,
-				verify:       u.verify,
-				disableHooks: u.disableHooks,
-				keyring:      u.keyring,
-				values:       u.values,
-				namespace:    u.namespace,
+				chartPath:     chartPath,
+				source:        source,
+				client:        u.client,
+				out:           u.out,
+				name:          u.release,
+				valuesFile:    u.valuesFile,
+				valuesOverlay: u.valuesOverlay,
+				valuesFrom:    u.valuesFrom,
+				dryRun:        u.dryRun,
+				serverDryRun:  u.serverDryRun,
+				verify:        u.verify,
+				disableHooks:  u.disableHooks,
+				keyring:       u.keyring,
+				values:        u.values,
+				jsonValues:    u.jsonValues,
+				namespace:     u.namespace,
+				serverSide:    u.serverSide,
+				include:       u.include,
+				exclude:       u.exclude,
+				versionCheck:  u.versionCheck,
+				noDecrypt:     u.noDecrypt,
+				noDeprecated:  u.noDeprecated,
+				subNotes:      u.subNotes,
+				profile:       u.profile,
+				remoteValues:  u.remoteValues,
+				progress:      u.progress,
+				wait:          u.wait,
+				waitForJobs:   u.waitForJobs,
+				timeout:       u.timeout,
+				metadata:      u.metadata,
+				overrideGates: u.overrideGates,
+				skipPreflight: u.skipPreflight,
 			}
 			return ic.run()
 		}
 	}
 
+	if err := checkDeprecated(u.out, chartPath, u.noDeprecated); err != nil {
+		return err
+	}
+
 	rawVals, err := u.vals()
 	if err != nil {
 		return err
 	}
 
-	_, err = u.client.UpdateRelease(
-		u.release,
-		chartPath,
+	metadata, err := parseMetadata(u.metadata)
+	if err != nil {
+		return err
+	}
+	canary, err := parseCanary(u.canary)
+	if err != nil {
+		return err
+	}
+
+	opts := []helm.UpdateOption{
 		helm.UpdateValueOverrides(rawVals),
 		helm.UpgradeDryRun(u.dryRun),
-		helm.UpgradeDisableHooks(u.disableHooks))
+		helm.UpgradeServerDryRun(u.serverDryRun),
+		helm.UpgradeDisableHooks(u.disableHooks),
+		helm.UpgradeServerSideApply(u.serverSide),
+		helm.UpgradeTemplateFilter(u.include, u.exclude),
+		helm.UpgradeOnlySubcharts(u.onlySubcharts),
+		helm.UpgradeDisableVersionCheck(!u.versionCheck),
+		helm.UpgradeSkipPreflight(u.skipPreflight),
+		helm.UpgradeSubchartNotes(u.subNotes),
+		helm.UpgradeChartSource(source),
+		helm.UpgradeWait(u.wait, u.waitForJobs, u.timeout),
+		helm.UpgradeMetadata(metadata),
+		helm.UpgradeOverrideLock(u.overrideLock),
+		helm.UpgradeOverrideGates(u.overrideGates),
+		helm.UpgradeCanary(canary, u.canaryWatch, u.canaryCheck),
+		helm.UpgradeBlueGreen(u.blueGreen),
+	}
+	if u.progress {
+		opts = append(opts, helm.UpgradeProgress(printProgress(u.out)))
+	}
+	_, err = u.client.UpdateRelease(u.release, chartPath, opts...)
 	if err != nil {
 		return fmt.Errorf("UPGRADE FAILED: %v", prettyError(err))
 	}
@@ -166,7 +298,7 @@ func (u *upgradeCmd) vals() ([]byte, error) {
 
 	// User specified a values file via -f/--values
 	if u.valuesFile != "" {
-		bytes, err := ioutil.ReadFile(u.valuesFile)
+		bytes, err := readValuesFile(u.valuesFile, u.remoteValues, !u.noDecrypt)
 		if err != nil {
 			return []byte{}, err
 		}
@@ -176,9 +308,37 @@ func (u *upgradeCmd) vals() ([]byte, error) {
 		}
 	}
 
+	if err := applyProfile(base, u.profile); err != nil {
+		return []byte{}, err
+	}
+
+	if err := mergeValuesFile(base, u.valuesOverlay, u.remoteValues, !u.noDecrypt); err != nil {
+		return []byte{}, err
+	}
+
+	if err := u.valuesFrom.merge(base, u.namespace); err != nil {
+		return []byte{}, err
+	}
+
+	if err := strvals.ParseJSONInto(u.jsonValues, base); err != nil {
+		return []byte{}, fmt.Errorf("failed parsing --set-json data: %s", err)
+	}
+
 	if err := strvals.ParseInto(u.values, base); err != nil {
 		return []byte{}, fmt.Errorf("failed parsing --set data: %s", err)
 	}
 
 	return yaml.Marshal(base)
 }
+
+// parseCanary parses the percentage of --canary, such as "20%".
+func parseCanary(s string) (int32, error) {
+	if s == "" {
+		return 0, nil
+	}
+	p, err := strconv.Atoi(strings.TrimSuffix(s, "%"))
+	if err != nil || p < 1 || p > 100 {
+		return 0, fmt.Errorf("invalid --canary %q: expected a percentage from 1%% to 100%%", s)
+	}
+	return int32(p), nil
+}
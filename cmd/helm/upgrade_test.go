@@ -76,6 +76,27 @@ func TestUpgradeCmd(t *testing.T) {
 			resp:     releaseMock(&releaseOptions{name: "zany-bunny", version: 1, chart: ch}),
 			expected: "zany-bunny has been upgraded. Happy Helming!\n",
 		},
+		{
+			name:     "upgrade only a subchart",
+			args:     []string{"funny-bunny", chartPath},
+			flags:    []string{"--only-subchart", "redis"},
+			resp:     releaseMock(&releaseOptions{name: "funny-bunny", version: 2, chart: ch}),
+			expected: "funny-bunny has been upgraded. Happy Helming!\n",
+		},
+		{
+			name:     "upgrade a release with a canary",
+			args:     []string{"funny-bunny", chartPath},
+			flags:    []string{"--canary", "20%", "--canary-duration", "60"},
+			resp:     releaseMock(&releaseOptions{name: "funny-bunny", version: 2, chart: ch}),
+			expected: "funny-bunny has been upgraded. Happy Helming!\n",
+		},
+		{
+			name:  "upgrade a release with an invalid canary",
+			args:  []string{"funny-bunny", chartPath},
+			flags: []string{"--canary", "120%"},
+			resp:  releaseMock(&releaseOptions{name: "funny-bunny", version: 2, chart: ch}),
+			err:   true,
+		},
 	}
 
 	cmd := func(c *fakeReleaseClient, out io.Writer) *cobra.Command {
@@ -0,0 +1,46 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/helm/pkg/helm"
+)
+
+const valuesDesc = `
+This command manages the override values stored on a release.
+
+Overrides are kept by Tiller with the release, and are merged over the values
+of every upgrade of the release, so that a small change of its configuration
+does not need every values file of the release to be given again.
+`
+
+func newValuesCmd(client helm.Interface, out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "values [FLAGS] set [ARGS]",
+		Short:             "manage the override values stored on a release",
+		Long:              valuesDesc,
+		PersistentPreRunE: setupConnection,
+	}
+
+	cmd.AddCommand(newValuesSetCmd(client, out))
+
+	return cmd
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/pflag"
+
+	"k8s.io/helm/pkg/chartutil"
+)
+
+// valuesFromKey is the key of a ConfigMap or Secret that values are read
+// from, unless --values-from names another.
+const valuesFromKey = "values.yaml"
+
+// valuesFrom reads values from the ConfigMaps and Secrets of the cluster, as
+// set with --values-from.
+type valuesFrom struct {
+	sources []string
+	// get fetches the data of a ConfigMap or Secret. It defaults to fetching
+	// it from the cluster of the kube context.
+	get func(kind, namespace, name string) (map[string][]byte, error)
+}
+
+func (v *valuesFrom) addFlags(f *pflag.FlagSet) {
+	f.StringSliceVar(&v.sources, "values-from", []string{}, "merge values YAML from a ConfigMap or Secret of the cluster: configmap/NAME or secret/NAME, optionally as KIND/NAMESPACE/NAME:KEY. Can be repeated")
+}
+
+// valuesSource is a parsed --values-from.
+type valuesSource struct {
+	kind, namespace, name, key string
+}
+
+func (s valuesSource) String() string {
+	return fmt.Sprintf("%s %s/%s", s.kind, s.namespace, s.name)
+}
+
+// parseValuesSource parses a --values-from of the form
+// KIND/[NAMESPACE/]NAME[:KEY], looking objects without a namespace up in
+// namespace.
+func parseValuesSource(s, namespace string) (valuesSource, error) {
+	src := valuesSource{namespace: namespace, key: valuesFromKey}
+	ref := s
+	if i := strings.LastIndex(ref, ":"); i >= 0 {
+		ref, src.key = ref[:i], ref[i+1:]
+	}
+	parts := strings.Split(ref, "/")
+	switch len(parts) {
+	case 2:
+		src.kind, src.name = parts[0], parts[1]
+	case 3:
+		src.kind, src.namespace, src.name = parts[0], parts[1], parts[2]
+	default:
+		return src, fmt.Errorf("invalid --values-from %q: expected configmap/NAME or secret/NAME", s)
+	}
+	switch strings.ToLower(src.kind) {
+	case "configmap", "cm":
+		src.kind = "ConfigMap"
+	case "secret":
+		src.kind = "Secret"
+	default:
+		return src, fmt.Errorf("invalid --values-from %q: values can only be read from a configmap or a secret", s)
+	}
+	if src.namespace == "" || src.name == "" || src.key == "" {
+		return src, fmt.Errorf("invalid --values-from %q: expected configmap/NAME or secret/NAME", s)
+	}
+	return src, nil
+}
+
+// merge merges the values of each --values-from over vals, in order.
+func (v *valuesFrom) merge(vals map[string]interface{}, namespace string) error {
+	get := v.get
+	if get == nil {
+		get = getClusterValues
+	}
+	for _, s := range v.sources {
+		src, err := parseValuesSource(s, namespace)
+		if err != nil {
+			return err
+		}
+		data, err := get(src.kind, src.namespace, src.name)
+		if err != nil {
+			return fmt.Errorf("could not read values from %s: %s", src, err)
+		}
+		raw, ok := data[src.key]
+		if !ok {
+			return fmt.Errorf("%s has no key %q", src, src.key)
+		}
+		overlay := map[string]interface{}{}
+		if err := yaml.Unmarshal(raw, &overlay); err != nil {
+			return fmt.Errorf("failed to parse %q of %s: %s", src.key, src, err)
+		}
+		chartutil.MergeValues(vals, overlay)
+	}
+	return nil
+}
+
+// getClusterValues fetches the data of a ConfigMap or Secret from the
+// cluster of the kube context.
+func getClusterValues(kind, namespace, name string) (map[string][]byte, error) {
+	_, client, err := getKubeClient(kubeContext)
+	if err != nil {
+		return nil, err
+	}
+	if kind == "Secret" {
+		s, err := client.Secrets(namespace).Get(name)
+		if err != nil {
+			return nil, err
+		}
+		return s.Data, nil
+	}
+	cm, err := client.ConfigMaps(namespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+	data := make(map[string][]byte, len(cm.Data))
+	for k, v := range cm.Data {
+		data[k] = []byte(v)
+	}
+	return data, nil
+}
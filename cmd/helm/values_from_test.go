@@ -0,0 +1,93 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseValuesSource(t *testing.T) {
+	tests := []struct {
+		in     string
+		expect valuesSource
+		err    bool
+	}{
+		{"configmap/env", valuesSource{"ConfigMap", "apps", "env", "values.yaml"}, false},
+		{"cm/env:prod.yaml", valuesSource{"ConfigMap", "apps", "env", "prod.yaml"}, false},
+		{"secret/platform/db", valuesSource{"Secret", "platform", "db", "values.yaml"}, false},
+		{"Secret/platform/db:values", valuesSource{"Secret", "platform", "db", "values"}, false},
+		{"deployment/web", valuesSource{}, true},
+		{"env", valuesSource{}, true},
+		{"configmap/", valuesSource{}, true},
+		{"configmap/env:", valuesSource{}, true},
+		{"secret/a/b/c", valuesSource{}, true},
+	}
+	for _, tt := range tests {
+		src, err := parseValuesSource(tt.in, "apps")
+		if (err != nil) != tt.err {
+			t.Errorf("%q: expected error %t, got %v", tt.in, tt.err, err)
+			continue
+		}
+		if !tt.err && src != tt.expect {
+			t.Errorf("%q: expected %+v, got %+v", tt.in, tt.expect, src)
+		}
+	}
+}
+
+func TestValuesFrom(t *testing.T) {
+	objects := map[string]map[string][]byte{
+		"ConfigMap apps/env":    {"values.yaml": []byte("image:\n  repo: nginx\n  tag: \"1.13\"\nreplicas: 2\n")},
+		"Secret platform/creds": {"values.yaml": []byte("db:\n  password: hunter2\n")},
+	}
+	var asked []string
+	get := func(kind, namespace, name string) (map[string][]byte, error) {
+		key := kind + " " + namespace + "/" + name
+		asked = append(asked, key)
+		if data, ok := objects[key]; ok {
+			return data, nil
+		}
+		return nil, errors.New("not found")
+	}
+
+	inst := &installCmd{
+		namespace:  "apps",
+		values:     "replicas=3",
+		valuesFrom: valuesFrom{sources: []string{"configmap/env", "secret/platform/creds"}, get: get},
+	}
+	vals, err := inst.vals()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := "db:\n  password: hunter2\nimage:\n  repo: nginx\n  tag: \"1.13\"\nreplicas: 3\n"
+	if string(vals) != expect {
+		t.Errorf("expected %q, got %q", expect, vals)
+	}
+	if strings.Join(asked, ", ") != "ConfigMap apps/env, Secret platform/creds" {
+		t.Errorf("unexpected lookups: %v", asked)
+	}
+
+	inst.valuesFrom.sources = []string{"configmap/env:other.yaml"}
+	if _, err := inst.vals(); err == nil || err.Error() != `ConfigMap apps/env has no key "other.yaml"` {
+		t.Errorf("expected a missing key error, got %v", err)
+	}
+	inst.valuesFrom.sources = []string{"secret/missing"}
+	if _, err := inst.vals(); err == nil || err.Error() != "could not read values from Secret apps/missing: not found" {
+		t.Errorf("expected a lookup error, got %v", err)
+	}
+}
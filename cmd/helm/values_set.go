@@ -0,0 +1,118 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/ghodss/yaml"
+	"github.com/spf13/cobra"
+
+	"k8s.io/helm/cmd/helm/strvals"
+	"k8s.io/helm/pkg/helm"
+)
+
+const valuesSetDesc = `
+This command stores override values on a release. The values are given as
+key=value pairs, in the same format as for '--set', and are merged into the
+overrides the release already has:
+
+	$ helm values set happy-panda image.tag=1.2.4 replicaCount=3
+
+The overrides take effect with the next 'helm upgrade' of the release, where
+they take precedence over the values of '--values' and '--set', and are kept
+for the upgrades after it. '--apply' upgrades the release to a new revision
+of the same chart right away instead.
+
+A value of null removes the default of the chart. To remove overrides, give
+the ones to keep with '--replace', which replaces all of the overrides of the
+release; '--replace' without any values removes them all.
+`
+
+type valuesSetCmd struct {
+	release string
+	values  []string
+	replace bool
+	apply   bool
+	out     io.Writer
+	client  helm.Interface
+}
+
+func newValuesSetCmd(client helm.Interface, out io.Writer) *cobra.Command {
+	set := &valuesSetCmd{out: out, client: client}
+
+	cmd := &cobra.Command{
+		Use:   "set [flags] RELEASE_NAME [KEY=VALUE] [...]",
+		Short: "store override values on a release",
+		Long:  valuesSetDesc,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return errReleaseRequired
+			}
+			set.release, set.values = args[0], args[1:]
+			if len(set.values) == 0 && !set.replace {
+				return errors.New("no values given; use --replace to remove all of the overrides")
+			}
+			set.client = ensureHelmClient(set.client)
+			return set.run()
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&set.replace, "replace", false, "replace all of the overrides of the release, instead of merging the values into them")
+	f.BoolVar(&set.apply, "apply", false, "upgrade the release with the new overrides now, instead of with its next upgrade")
+
+	return cmd
+}
+
+func (v *valuesSetCmd) run() error {
+	vals := map[string]interface{}{}
+	for _, kv := range v.values {
+		if err := strvals.ParseInto(kv, vals); err != nil {
+			return fmt.Errorf("failed parsing %q: %s", kv, err)
+		}
+	}
+	raw, err := yaml.Marshal(vals)
+	if err != nil {
+		return err
+	}
+	if len(vals) == 0 {
+		raw = nil
+	}
+
+	res, err := v.client.SetReleaseValues(v.release, raw, helm.SetValuesReplace(v.replace), helm.SetValuesApply(v.apply))
+	if err != nil {
+		return prettyError(err)
+	}
+
+	rel := res.GetRelease()
+	if rel == nil {
+		return nil
+	}
+	if v.apply {
+		fmt.Fprintf(v.out, "Release %q has been upgraded to revision %d with its overrides.\n", v.release, rel.Version)
+		return nil
+	}
+	if rel.Overrides == nil || rel.Overrides.Raw == "" {
+		fmt.Fprintf(v.out, "Release %q has no overrides.\n", v.release)
+		return nil
+	}
+	fmt.Fprintf(v.out, "Overrides of release %q, applied with its next upgrade:\n%s", v.release, rel.Overrides.Raw)
+	return nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/proto/hapi/release"
+	rls "k8s.io/helm/pkg/proto/hapi/services"
+)
+
+type fakeSetValuesClient struct {
+	fakeReleaseClient
+	raw []byte
+}
+
+func (c *fakeSetValuesClient) SetReleaseValues(name string, rawVals []byte, opts ...helm.SetValuesOption) (*rls.SetReleaseValuesResponse, error) {
+	c.raw = rawVals
+	rel := releaseMock(&releaseOptions{name: name})
+	rel.Version = 2
+	if len(rawVals) > 0 {
+		rel.Overrides = &chart.Config{Raw: string(rawVals)}
+	}
+	return &rls.SetReleaseValuesResponse{Release: rel}, nil
+}
+
+func TestValuesSet(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		flags  []string
+		raw    string
+		expect string
+		err    bool
+	}{
+		{
+			name:   "set values",
+			args:   []string{"aeneas", "image.tag=1.2.4", "replicaCount=3"},
+			raw:    "image:\n  tag: 1.2.4\nreplicaCount: 3\n",
+			expect: "Overrides of release \"aeneas\", applied with its next upgrade:\nimage:\n  tag: 1.2.4\nreplicaCount: 3\n",
+		},
+		{
+			name:   "apply values",
+			args:   []string{"aeneas", "name=value"},
+			flags:  []string{"--apply"},
+			raw:    "name: value\n",
+			expect: "Release \"aeneas\" has been upgraded to revision 2 with its overrides.\n",
+		},
+		{
+			name:   "remove all overrides",
+			args:   []string{"aeneas"},
+			flags:  []string{"--replace"},
+			expect: "Release \"aeneas\" has no overrides.\n",
+		},
+		{
+			name: "no values",
+			args: []string{"aeneas"},
+			err:  true,
+		},
+		{
+			name: "no release",
+			err:  true,
+		},
+		{
+			name: "bad value",
+			args: []string{"aeneas", "name"},
+			err:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		c := &fakeSetValuesClient{fakeReleaseClient: fakeReleaseClient{rels: []*release.Release{releaseMock(&releaseOptions{name: "aeneas"})}}}
+		out := bytes.NewBuffer(nil)
+		cmd := newValuesSetCmd(c, out)
+		cmd.ParseFlags(tt.flags)
+		err := cmd.RunE(cmd, tt.args)
+		if (err != nil) != tt.err {
+			t.Errorf("%q: expected error %v, got %v", tt.name, tt.err, err)
+			continue
+		}
+		if tt.err {
+			continue
+		}
+		if string(c.raw) != tt.raw {
+			t.Errorf("%q: expected values %q, got %q", tt.name, tt.raw, c.raw)
+		}
+		if !strings.HasSuffix(out.String(), tt.expect) {
+			t.Errorf("%q: expected output %q, got %q", tt.name, tt.expect, out.String())
+		}
+	}
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"k8s.io/helm/pkg/repo"
+)
+
+// digestPrefix introduces the digest a remote values file is pinned to, as in
+// https://example.com/values.yaml#sha256=<hex>.
+const digestPrefix = "sha256="
+
+// isRemoteValues reports whether a values file name refers to an HTTP(S) URL.
+func isRemoteValues(name string) bool {
+	return strings.HasPrefix(name, "http://") || strings.HasPrefix(name, "https://")
+}
+
+// remoteValues are the options for downloading remote values files, as set
+// with --values-header, --ca-file, --cert-file and --key-file.
+type remoteValues struct {
+	// headers are "Name: value" pairs added to each request.
+	headers []string
+	// caFile is the CA bundle that servers are verified with, instead of
+	// the system's trusted certificates.
+	caFile string
+	// certFile and keyFile are the client certificate that identifies helm
+	// to servers.
+	certFile, keyFile string
+}
+
+func (r *remoteValues) addFlags(f *pflag.FlagSet) {
+	f.StringSliceVar(&r.headers, "values-header", []string{}, "add a \"Name: value\" header to requests for a remote values file")
+	f.StringVar(&r.caFile, "ca-file", "", "verify the HTTPS servers of remote values files using this CA bundle")
+	f.StringVar(&r.certFile, "cert-file", "", "identify to the HTTPS servers of remote values files using this SSL certificate file")
+	f.StringVar(&r.keyFile, "key-file", "", "identify to the HTTPS servers of remote values files using this SSL key file")
+}
+
+// client returns the HTTP client that remote values files are downloaded
+// with.
+func (r remoteValues) client() (*http.Client, error) {
+	if r.caFile == "" && r.certFile == "" && r.keyFile == "" {
+		return repo.Client(), nil
+	}
+	if (r.certFile == "") != (r.keyFile == "") {
+		return nil, errors.New("--cert-file and --key-file must be used together")
+	}
+
+	cfg := &tls.Config{}
+	if r.certFile != "" {
+		cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load the client certificate: %s", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	if r.caFile != "" {
+		b, err := ioutil.ReadFile(r.caFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("no certificates found in %s", r.caFile)
+		}
+	}
+
+	// A client of its own, so that the shared one keeps trusting the system's
+	// certificates.
+	c := repo.NewClient(repo.DefaultClientOptions)
+	c.Transport.(*http.Transport).TLSClientConfig = cfg
+	return c, nil
+}
+
+// fetchValues downloads a remote values file.
+//
+// Credentials in the URL are sent using basic authentication, and the headers
+// of opts are added to the request. If the URL fragment pins a sha256 digest,
+// the downloaded content must match it.
+func fetchValues(ref string, opts remoteValues) ([]byte, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return nil, fmt.Errorf("invalid values URL %q: %s", ref, err)
+	}
+
+	var digest string
+	if u.Fragment != "" {
+		if !strings.HasPrefix(u.Fragment, digestPrefix) {
+			return nil, fmt.Errorf("unsupported values URL fragment %q: expected %s<hex>", u.Fragment, digestPrefix)
+		}
+		digest = strings.ToLower(strings.TrimPrefix(u.Fragment, digestPrefix))
+		u.Fragment = ""
+	}
+	user := u.User
+	u.User = nil
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if user != nil {
+		pass, _ := user.Password()
+		req.SetBasicAuth(user.Username(), pass)
+	}
+	for _, h := range opts.headers {
+		parts := strings.SplitN(h, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("invalid header %q: expected \"Name: value\"", h)
+		}
+		req.Header.Add(strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]))
+	}
+
+	client, err := opts.client()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := repo.DefaultRetryPolicy.Do(client, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: %s", u, resp.Status)
+	}
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if digest != "" {
+		sum := sha256.Sum256(b)
+		if got := hex.EncodeToString(sum[:]); got != digest {
+			return nil, fmt.Errorf("digest mismatch for %s: expected sha256 %s, got %s", u, digest, got)
+		}
+	}
+	return b, nil
+}
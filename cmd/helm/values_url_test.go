@@ -0,0 +1,196 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetchValues(t *testing.T) {
+	const content = "name: value\n"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("X-Env") != "prod" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(content))
+	}))
+	defer srv.Close()
+
+	sum := sha256.Sum256([]byte(content))
+	digest := hex.EncodeToString(sum[:])
+	base := strings.Replace(srv.URL, "http://", "http://user:pass@", 1) + "/values.yaml"
+	headers := []string{"X-Env: prod"}
+
+	tests := []struct {
+		name    string
+		ref     string
+		headers []string
+		err     string
+	}{
+		{"basic", base, headers, ""},
+		{"pinned", base + "#sha256=" + digest, headers, ""},
+		{"mismatch", base + "#sha256=" + strings.Repeat("0", 64), headers, "digest mismatch"},
+		{"bad fragment", base + "#md5=abc", headers, "unsupported values URL fragment"},
+		{"no auth", srv.URL + "/values.yaml", headers, "401"},
+		{"missing header", base, nil, "403"},
+		{"bad header", base, []string{"X-Env"}, "invalid header"},
+	}
+	for _, tt := range tests {
+		b, err := fetchValues(tt.ref, remoteValues{headers: tt.headers})
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+			continue
+		}
+		if string(b) != content {
+			t.Errorf("%s: expected %q, got %q", tt.name, content, b)
+		}
+	}
+}
+
+func TestFetchValuesHidesCredentials(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	ref := strings.Replace(srv.URL, "http://", "http://user:secret@", 1)
+	_, err := fetchValues(ref, remoteValues{})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if strings.Contains(err.Error(), "secret") {
+		t.Errorf("error leaks credentials: %s", err)
+	}
+}
+
+// writeClientCert writes a self-signed client certificate and its key to dir.
+func writeClientCert(t *testing.T, dir string) (*x509.Certificate, string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "helm"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	if err := ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return cert, certFile, keyFile
+}
+
+func TestFetchValuesTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-values-tls-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	client, certFile, keyFile := writeClientCert(t, dir)
+
+	const content = "name: value\n"
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(content))
+	}))
+	clients := x509.NewCertPool()
+	clients.AddCert(client)
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clients}
+	srv.StartTLS()
+	defer srv.Close()
+
+	caFile := filepath.Join(dir, "ca.crt")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := ioutil.WriteFile(caFile, ca, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ref := srv.URL + "/values.yaml"
+	tests := []struct {
+		name string
+		opts remoteValues
+		err  string
+	}{
+		{"system CAs", remoteValues{}, "certificate"},
+		{"no client certificate", remoteValues{caFile: caFile}, "tls"},
+		{"no key", remoteValues{caFile: caFile, certFile: certFile}, "must be used together"},
+		{"empty CA bundle", remoteValues{caFile: keyFile, certFile: certFile, keyFile: keyFile}, "no certificates found"},
+		{"client certificate", remoteValues{caFile: caFile, certFile: certFile, keyFile: keyFile}, ""},
+	}
+	for _, tt := range tests {
+		b, err := fetchValues(ref, tt.opts)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+			continue
+		}
+		if string(b) != content {
+			t.Errorf("%s: expected %q, got %q", tt.name, content, b)
+		}
+	}
+}
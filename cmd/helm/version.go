@@ -21,12 +21,14 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 
 	"k8s.io/helm/pkg/helm"
+	pbversion "k8s.io/helm/pkg/proto/hapi/version"
 	"k8s.io/helm/pkg/version"
 )
 
@@ -44,21 +46,59 @@ Server: &version.Version{SemVer:"v2.0.0", GitCommit:"b0c113dfb9f612a9add796549da
 - GitTreeState is "clean" if there are no local code changes when this binary was
   built, and "dirty" if the binary was built from locally modified code.
 
-To print just the client version, use '--client'. To print just the server version,
-use '--server'.
+To print just the client version, use '--client'. This never connects to the
+cluster, so it also works where there is no cluster access. To print just the
+server version, use '--server'.
+
+'--short' prints just the versions, e.g. "Client: v2.0.0+gff52399", and
+'--template' formats them with a Go template, e.g.
+
+    $ helm version --client --template '{{.client.semVer}}'
+    v2.0.0
+
+See docs/output_formats.md for the fields.
+
+If the client and server versions are not compatible, a warning is printed to
+stderr. They are compatible if they have the same major version, and the server
+minor version is at least the client's. Pre-release versions are only
+compatible with themselves.
 `
 
 type versionCmd struct {
 	out        io.Writer
+	warn       io.Writer
 	client     helm.Interface
 	showClient bool
 	showServer bool
+	short      bool
+	template   string
+	output     string
+}
+
+// versionResult is the machine readable output of 'helm version'.
+type versionResult struct {
+	Client *versionInfo `json:"client,omitempty"`
+	Server *versionInfo `json:"server,omitempty"`
+}
+
+type versionInfo struct {
+	SemVer       string `json:"semVer"`
+	GitCommit    string `json:"gitCommit"`
+	GitTreeState string `json:"gitTreeState"`
+}
+
+func newVersionInfo(v *pbversion.Version) *versionInfo {
+	if v == nil {
+		return nil
+	}
+	return &versionInfo{SemVer: v.SemVer, GitCommit: v.GitCommit, GitTreeState: v.GitTreeState}
 }
 
 func newVersionCmd(c helm.Interface, out io.Writer) *cobra.Command {
 	version := &versionCmd{
 		client: c,
 		out:    out,
+		warn:   os.Stderr,
 	}
 
 	cmd := &cobra.Command{
@@ -74,39 +114,91 @@ func newVersionCmd(c helm.Interface, out io.Writer) *cobra.Command {
 				// We do this manually instead of in PreRun because we only
 				// need a tunnel if server version is requested.
 				setupConnection(cmd, args)
+				version.client = ensureHelmClient(version.client)
 			}
-			version.client = ensureHelmClient(version.client)
 			return version.run()
 		},
 	}
 	f := cmd.Flags()
 	f.BoolVarP(&version.showClient, "client", "c", false, "if set, show the client version")
 	f.BoolVarP(&version.showServer, "server", "s", false, "if set, show the server version")
+	f.BoolVar(&version.short, "short", false, "print the version numbers only")
+	f.StringVar(&version.template, "template", "", "format the versions with a Go template. Shorthand for '--output go-template=TEMPLATE'")
+	addOutputFlag(f, &version.output, "o", "table")
 
 	return cmd
 }
 
 func (v *versionCmd) run() error {
+	if v.template != "" {
+		if v.output != "table" {
+			return errors.New("--template cannot be used with --output")
+		}
+		v.output = outputTemplatePrefix + v.template
+	}
+	format, err := parseOutputFormat(v.output, "table")
+	if err != nil {
+		return err
+	}
 
+	res := versionResult{}
 	if v.showClient {
 		cv := version.GetVersionProto()
-		fmt.Fprintf(v.out, "Client: %#v\n", cv)
+		res.Client = newVersionInfo(cv)
+		if format.human() {
+			v.print("Client", cv)
+		}
 	}
 
-	if !v.showServer {
+	if v.showServer {
+		resp, err := v.client.GetVersion()
+		if err != nil {
+			if grpc.Code(err) == codes.Unimplemented {
+				return errors.New("server is too old to know its version")
+			}
+			if flagDebug {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			return errors.New("cannot connect to Tiller")
+		}
+		res.Server = newVersionInfo(resp.Version)
+		if format.human() {
+			v.print("Server", resp.Version)
+		}
+	}
+
+	if res.Client != nil && res.Server != nil && !version.IsCompatible(res.Client.SemVer, res.Server.SemVer) {
+		fmt.Fprintf(v.warn, "WARNING: client version %s and server version %s are not compatible. Run 'helm init --upgrade' to upgrade Tiller, or install a matching client.\n", res.Client.SemVer, res.Server.SemVer)
+	}
+
+	if format.human() {
 		return nil
 	}
+	return format.write(v.out, res)
+}
 
-	resp, err := v.client.GetVersion()
-	if err != nil {
-		if grpc.Code(err) == codes.Unimplemented {
-			return errors.New("server is too old to know its version")
-		}
-		if flagDebug {
-			fmt.Fprintln(os.Stderr, err)
-		}
-		return errors.New("cannot connect to Tiller")
+// print prints the human readable form of a client or server version.
+func (v *versionCmd) print(label string, ver *pbversion.Version) {
+	if !v.short {
+		fmt.Fprintf(v.out, "%s: %#v\n", label, ver)
+		return
+	}
+	if ver == nil {
+		fmt.Fprintf(v.out, "%s: unknown\n", label)
+		return
+	}
+	fmt.Fprintf(v.out, "%s: %s\n", label, shortVersion(ver))
+}
+
+// shortVersion formats ver as its semantic version with the abbreviated commit
+// it was built from as build metadata, e.g. "v2.0.0+gff52399".
+func shortVersion(ver *pbversion.Version) string {
+	commit := ver.GitCommit
+	if len(commit) > 7 {
+		commit = commit[:7]
+	}
+	if commit == "" || strings.Contains(ver.SemVer, "+") {
+		return ver.SemVer
 	}
-	fmt.Fprintf(v.out, "Server: %#v\n", resp.Version)
-	return nil
+	return ver.SemVer + "+g" + commit
 }
@@ -20,6 +20,9 @@ import (
 	"strings"
 	"testing"
 
+	"k8s.io/helm/pkg/helm"
+	rls "k8s.io/helm/pkg/proto/hapi/services"
+	pbversion "k8s.io/helm/pkg/proto/hapi/version"
 	"k8s.io/helm/pkg/version"
 )
 
@@ -60,3 +63,81 @@ func TestVersion(t *testing.T) {
 		}
 	}
 }
+
+func TestVersionFormats(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		expect string
+		err    bool
+	}{
+		{"short", []string{"--server", "--short"}, "Server: 1.2.3-fakeclient+testonly\n", false},
+		{"template", []string{"--server", "--template", "{{.server.semVer}}"}, "1.2.3-fakeclient+testonly", false},
+		{"template and output", []string{"--template", "{{.server.semVer}}", "-o", "json"}, "", true},
+	}
+
+	for _, tt := range tests {
+		b := new(bytes.Buffer)
+		cmd := newVersionCmd(&fakeReleaseClient{}, b)
+		cmd.ParseFlags(tt.args)
+		err := cmd.RunE(cmd, tt.args)
+		if (err != nil) != tt.err {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.err, err)
+		}
+		if b.String() != tt.expect {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expect, b.String())
+		}
+	}
+}
+
+func TestVersionSkew(t *testing.T) {
+	tests := []struct {
+		client, server string
+		warn           bool
+	}{
+		{"v2.1.0", "v2.1.3", false},
+		{"v2.1.0", "v2.2.0", false},
+		{"v2.1.0", "v2.0.0", true},
+		{"v2.1.0", "v3.0.0", true},
+	}
+
+	defer func(v string) { version.Version = v }(version.Version)
+	for _, tt := range tests {
+		version.Version = tt.client
+		warn := new(bytes.Buffer)
+		v := &versionCmd{
+			out:        new(bytes.Buffer),
+			warn:       warn,
+			client:     &fakeVersionClient{semVer: tt.server},
+			showClient: true,
+			showServer: true,
+			output:     "table",
+		}
+		if err := v.run(); err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Contains(warn.String(), "not compatible"); got != tt.warn {
+			t.Errorf("client %s, server %s: expected warning %v, got %q", tt.client, tt.server, tt.warn, warn.String())
+		}
+	}
+}
+
+func TestShortVersion(t *testing.T) {
+	v := &pbversion.Version{SemVer: "v2.0.0", GitCommit: "ff52399e51bb880526e9cd0ed8386f6433b74da1"}
+	if got := shortVersion(v); got != "v2.0.0+gff52399" {
+		t.Errorf("Expected v2.0.0+gff52399, got %q", got)
+	}
+	v.GitCommit = ""
+	if got := shortVersion(v); got != "v2.0.0" {
+		t.Errorf("Expected v2.0.0, got %q", got)
+	}
+}
+
+type fakeVersionClient struct {
+	fakeReleaseClient
+	semVer string
+}
+
+func (c *fakeVersionClient) GetVersion(opts ...helm.VersionOption) (*rls.GetVersionResponse, error) {
+	return &rls.GetVersionResponse{Version: &pbversion.Version{SemVer: c.semVer}}, nil
+}
@@ -17,20 +17,105 @@ limitations under the License.
 package main
 
 import (
+	"bytes"
+	"fmt"
 	"net/http"
+	"time"
+
+	"k8s.io/helm/pkg/metrics"
+	"k8s.io/helm/pkg/storage/driver"
+	"k8s.io/helm/pkg/tiller/environment"
 )
 
-func readinessProbe(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusOK)
+// healthCheckTimeout bounds each check, so that a probe is answered before
+// the kubelet gives up on it. See the probes of the Deployment created by
+// helm init.
+const healthCheckTimeout = 2 * time.Second
+
+// healthCheck reports whether a dependency of Tiller is available.
+type healthCheck struct {
+	name  string
+	check func() error
+}
+
+// readinessChecks verify that Tiller can reach its release storage and the
+// Kubernetes API.
+func readinessChecks(env *environment.Environment) []healthCheck {
+	return []healthCheck{
+		{"storage", func() error {
+			_, err := env.Releases.Query(map[string]string{"OWNER": "TILLER", "NAME": "tiller-readiness-check"})
+			if err == driver.ErrReleaseNotFound {
+				return nil
+			}
+			return err
+		}},
+		{"kubernetes", func() error {
+			c, err := env.KubeClient.APIClient()
+			if err != nil {
+				return err
+			}
+			_, err = c.Discovery().ServerVersion()
+			return err
+		}},
+	}
+}
+
+// healthHandler runs the checks. It responds with "ok" if they all pass,
+// and with 503 and the result of every check if one does not. The results
+// are always listed if the "verbose" parameter is set.
+func healthHandler(checks []healthCheck) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		b := bytes.NewBuffer(nil)
+		failed := false
+		for _, c := range checks {
+			if err := runCheck(c); err != nil {
+				failed = true
+				fmt.Fprintf(b, "[-]%s failed: %s\n", c.name, err)
+				continue
+			}
+			fmt.Fprintf(b, "[+]%s ok\n", c.name)
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		if failed {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			b.WriteTo(w)
+			return
+		}
+		if _, verbose := r.URL.Query()["verbose"]; verbose {
+			b.WriteTo(w)
+		}
+		fmt.Fprintln(w, "ok")
+	}
 }
 
-func livenessProbe(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusOK)
+func runCheck(c healthCheck) error {
+	errc := make(chan error, 1)
+	go func() { errc <- c.check() }()
+	select {
+	case err := <-errc:
+		return err
+	case <-time.After(healthCheckTimeout):
+		return fmt.Errorf("timed out after %s", healthCheckTimeout)
+	}
 }
 
-func newProbesMux() *http.ServeMux {
+// newProbesMux serves the probes of Tiller and its metrics.
+//
+// /healthz reports that Tiller is running. It does not check Tiller's
+// dependencies, so that the kubelet does not restart Tiller while the
+// Kubernetes API is unavailable. /readyz also checks that the storage and
+// the Kubernetes API can be reached. /liveness and /readiness are kept for
+// Deployments created by older versions of helm init.
+func newProbesMux(env *environment.Environment) *http.ServeMux {
 	mux := http.NewServeMux()
-	mux.HandleFunc("/readiness", readinessProbe)
-	mux.HandleFunc("/liveness", livenessProbe)
+	live := healthHandler(nil)
+	ready := healthHandler(readinessChecks(env))
+	mux.HandleFunc("/healthz", live)
+	mux.HandleFunc("/liveness", live)
+	mux.HandleFunc("/readyz", ready)
+	mux.HandleFunc("/readiness", ready)
+	mux.Handle("/metrics", metrics.Handler())
 	return mux
 }
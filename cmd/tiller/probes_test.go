@@ -17,28 +17,88 @@ limitations under the License.
 package main
 
 import (
+	"errors"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"strings"
 	"testing"
+
+	"k8s.io/helm/pkg/storage"
+	"k8s.io/helm/pkg/storage/driver"
+	"k8s.io/helm/pkg/tiller/environment"
+	unversionedclient "k8s.io/kubernetes/pkg/client/unversioned"
 )
 
+func probesEnv() *environment.Environment {
+	e := environment.New()
+	e.Releases = storage.Init(driver.NewMemory())
+	e.KubeClient = &environment.PrintingKubeClient{Out: os.Stdout}
+	return e
+}
+
 func TestProbesServer(t *testing.T) {
-	mux := newProbesMux()
+	mux := newProbesMux(probesEnv())
 	srv := httptest.NewServer(mux)
 	defer srv.Close()
-	resp, err := http.Get(srv.URL + "/readiness")
+
+	for _, p := range []string{"/healthz", "/readyz", "/liveness", "/readiness", "/metrics"} {
+		resp, err := http.Get(srv.URL + p)
+		if err != nil {
+			t.Fatalf("GET %s returned an error (%s)", p, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET %s returned status code %d, expected %d", p, resp.StatusCode, http.StatusOK)
+		}
+	}
+
+	resp, err := http.Get(srv.URL + "/readyz?verbose")
 	if err != nil {
-		t.Fatalf("GET /readiness returned an error (%s)", err)
+		t.Fatalf("GET /readyz?verbose returned an error (%s)", err)
 	}
-	if resp.StatusCode != http.StatusOK {
-		t.Fatalf("GET /readiness returned status code %d, expected %d", resp.StatusCode, http.StatusOK)
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	if expect := "[+]storage ok\n[+]kubernetes ok\nok\n"; string(body) != expect {
+		t.Errorf("expected %q, got %q", expect, body)
+	}
+}
+
+type unreachableKubeClient struct {
+	environment.PrintingKubeClient
+}
+
+func (*unreachableKubeClient) APIClient() (unversionedclient.Interface, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestReadyzUnavailable(t *testing.T) {
+	e := probesEnv()
+	e.KubeClient = &unreachableKubeClient{}
+	srv := httptest.NewServer(newProbesMux(e))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/readyz")
+	if err != nil {
+		t.Fatalf("GET /readyz returned an error (%s)", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected status code %d, got %d", http.StatusServiceUnavailable, resp.StatusCode)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	if !strings.Contains(string(body), "[-]kubernetes failed: connection refused") {
+		t.Errorf("expected the failed check to be reported, got %q", body)
 	}
 
-	resp, err = http.Get(srv.URL + "/liveness")
+	// Liveness does not depend on the cluster.
+	resp, err = http.Get(srv.URL + "/healthz")
 	if err != nil {
-		t.Fatalf("GET /liveness returned an error (%s)", err)
+		t.Fatalf("GET /healthz returned an error (%s)", err)
 	}
+	resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		t.Fatalf("GET /liveness returned status code %d, expected %d", resp.StatusCode, http.StatusOK)
+		t.Errorf("expected /healthz to be ok, got %d", resp.StatusCode)
 	}
 }
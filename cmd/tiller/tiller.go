@@ -17,19 +17,31 @@ limitations under the License.
 package main // import "k8s.io/helm/cmd/tiller"
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"k8s.io/helm/pkg/gate"
+	"k8s.io/helm/pkg/kube"
+	"k8s.io/helm/pkg/notify"
+	"k8s.io/helm/pkg/policy"
 	"k8s.io/helm/pkg/proto/hapi/services"
+	"k8s.io/helm/pkg/provenance"
 	"k8s.io/helm/pkg/storage"
 	"k8s.io/helm/pkg/storage/driver"
 	"k8s.io/helm/pkg/tiller"
 	"k8s.io/helm/pkg/tiller/environment"
+	"k8s.io/helm/pkg/tiller/gateway"
+	"k8s.io/helm/pkg/valueref"
+	"k8s.io/kubernetes/pkg/client/unversioned"
 )
 
 const (
@@ -52,9 +64,56 @@ var (
 	probeAddr     = ":44135"
 	traceAddr     = ":44136"
 	enableTracing = false
+	enablePprof   = false
 	store         = storageConfigMap
+
+	// encryptionSecret names a Secret holding the keys used to encrypt
+	// release records at rest.
+	encryptionSecret = ""
+
+	// signingKeyring and signingKey locate the private key release records
+	// are signed with.
+	signingKeyring = ""
+	signingKey     = ""
+
+	// impersonateUsers makes Tiller act on the cluster as its callers.
+	impersonateUsers = false
+
+	// policyWebhook and policyOPA locate the policy releases must comply with.
+	policyWebhook = ""
+	policyOPA     = ""
+
+	// chartRulesFile restricts the charts Tiller installs.
+	chartRulesFile = ""
+
+	// gatewayAddr is the address of the HTTP+JSON gateway, if there is one.
+	gatewayAddr = ""
+
+	// notifyConfig lists the webhooks that are told about releases.
+	notifyConfig = ""
+
+	// deployGates holds the deployment windows and gates of releases.
+	deployGates = ""
+
+	// valueResolvers configures the resolvers of references to secrets in
+	// values.
+	valueResolvers = ""
+
+	// debug enables verbose logging, such as of retried Kubernetes requests.
+	debug = false
+
+	// annotateSources annotates resources with the templates they came from.
+	annotateSources = true
 )
 
+// policyClient is the HTTP client used to evaluate policy, to ask deployment
+// gates and to resolve references to secrets. Releases wait on it, so it must
+// not hang.
+var policyClient = &http.Client{Timeout: 30 * time.Second}
+
+// notifyClient is the HTTP client used to post notifications.
+var notifyClient = &http.Client{Timeout: 10 * time.Second}
+
 const globalUsage = `The Kubernetes Helm server.
 
 Tiller is the server for Helm. It provides in-cluster resource management.
@@ -75,11 +134,30 @@ func main() {
 	p := rootCommand.PersistentFlags()
 	p.StringVarP(&grpcAddr, "listen", "l", ":44134", "address:port to listen on")
 	p.StringVar(&store, "storage", storageConfigMap, "storage driver to use. One of 'configmap' or 'memory'")
+	p.StringVar(&encryptionSecret, "storage-encryption-secret", "", "name of a Secret in the Tiller namespace holding the keys used to encrypt stored releases")
+	p.StringVar(&signingKeyring, "storage-signing-keyring", "", "keyring holding the unencrypted private key used to sign stored releases")
+	p.StringVar(&signingKey, "storage-signing-key", "", "name of the key in --storage-signing-keyring to sign stored releases with")
+	p.BoolVar(&impersonateUsers, "impersonate-users", false, "act on the cluster as the user whose bearer token the client sends, rather than as Tiller's service account")
+	p.StringVar(&policyWebhook, "policy-webhook", "", "URL of a webhook that must allow releases before they are applied")
+	p.StringVar(&policyOPA, "policy-opa", "", "URL of an Open Policy Agent document of denial messages, e.g. http://opa:8181/v1/data/helm/deny")
+	p.StringVar(&chartRulesFile, "chart-rules", "", "YAML file of rules restricting the charts that may be installed")
+	p.StringVar(&gatewayAddr, "gateway-listen", "", "address:port to serve the release API as JSON over HTTP on. Disabled if empty. Requires --impersonate-users")
+	p.StringVar(&notifyConfig, "notify-config", "", "YAML file of webhooks to notify when releases are installed, upgraded, rolled back or deleted")
+	p.StringVar(&deployGates, "deploy-gates", "", "YAML file of the deployment windows and gates that must allow releases to be changed")
+	p.StringVar(&valueResolvers, "value-resolvers", "", "YAML file configuring the resolvers of ref+ references to secrets in values, e.g. in Vault or AWS Secrets Manager")
 	p.BoolVar(&enableTracing, "trace", false, "enable rpc tracing")
+	p.BoolVar(&enablePprof, "pprof", false, "serve pprof profiles and runtime variables on port 44136")
+	p.BoolVar(&annotateSources, "annotate-sources", true, "annotate the resources of releases with the chart template each was rendered from (helm.sh/chart-source)")
+	p.BoolVar(&debug, "debug", false, "enable verbose output, including Kubernetes API requests that are retried")
 	rootCommand.Execute()
 }
 
 func start(c *cobra.Command, args []string) {
+	if kc, ok := env.KubeClient.(*kube.Client); ok {
+		kc.Debug = debug
+		kc.AnnotateSources = annotateSources
+	}
+
 	switch store {
 	case storageMemory:
 		env.Releases = storage.Init(driver.NewMemory())
@@ -88,7 +166,77 @@ func start(c *cobra.Command, args []string) {
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Cannot initialize Kubernetes connection: %s", err)
 		}
-		env.Releases = storage.Init(driver.NewConfigMaps(c.ConfigMaps(environment.TillerNamespace)))
+		cfgmaps := driver.NewConfigMaps(c.ConfigMaps(environment.TillerNamespace))
+		if encryptionSecret != "" {
+			kp, err := loadKeyProvider(c, encryptionSecret)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Cannot load storage encryption keys: %s\n", err)
+				os.Exit(1)
+			}
+			cfgmaps.KeyProvider = kp
+		}
+		env.Releases = storage.Init(cfgmaps)
+	}
+
+	if signingKeyring != "" {
+		signer, err := loadSigner(signingKeyring, signingKey)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot load storage signing key: %s\n", err)
+			os.Exit(1)
+		}
+		env.Releases.Signer = signer
+	}
+
+	switch {
+	case policyWebhook != "" && policyOPA != "":
+		fmt.Fprintln(os.Stderr, "Only one of --policy-webhook and --policy-opa can be set")
+		os.Exit(1)
+	case policyWebhook != "":
+		env.Policy = &policy.Webhook{URL: policyWebhook, Client: policyClient}
+	case policyOPA != "":
+		env.Policy = &policy.OPA{URL: policyOPA, Client: policyClient}
+	}
+
+	if notifyConfig != "" {
+		hooks, err := notify.LoadWebhooks(notifyConfig)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot load notification webhooks: %s\n", err)
+			os.Exit(1)
+		}
+		hooks.Client = notifyClient
+		env.Notifier = hooks
+	}
+
+	if deployGates != "" {
+		gates, err := gate.LoadConfig(deployGates)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot load deployment gates: %s\n", err)
+			os.Exit(1)
+		}
+		gates.Client = policyClient
+		env.Gates = gates
+	}
+
+	if valueResolvers != "" {
+		cfg, err := valueref.LoadConfig(valueResolvers)
+		if err == nil {
+			cfg.Client = policyClient
+			env.ValuePolicy = cfg.Allow
+			env.ValueResolvers, err = cfg.Resolvers()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot load value resolvers: %s\n", err)
+			os.Exit(1)
+		}
+	}
+
+	var chartRules *tiller.ChartRules
+	if chartRulesFile != "" {
+		var err error
+		if chartRules, err = tiller.LoadChartRules(chartRulesFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot load chart rules: %s\n", err)
+			os.Exit(1)
+		}
 	}
 
 	lstn, err := net.Listen("tcp", grpcAddr)
@@ -100,15 +248,39 @@ func start(c *cobra.Command, args []string) {
 	fmt.Printf("Tiller is listening on %s\n", grpcAddr)
 	fmt.Printf("Probes server is listening on %s\n", probeAddr)
 	fmt.Printf("Storage driver is %s\n", env.Releases.Name())
+	if len(env.ValueResolvers) > 0 {
+		fmt.Printf("Value references are resolved for %s\n", strings.Join(env.ValueResolvers.Schemes(), ", "))
+		if len(env.ValuePolicy) == 0 {
+			fmt.Fprintln(os.Stderr, "Warning: no namespace is allowed to refer to secrets: list them under allow in --value-resolvers")
+		}
+	}
 
-	if enableTracing {
-		startTracing(traceAddr)
+	if enableTracing || enablePprof {
+		startDebugServer(traceAddr, enableTracing)
+	}
+
+	gatewayErrCh := make(chan error)
+	if gatewayAddr != "" {
+		// The gateway only passes on the caller's token, so without
+		// impersonation nothing would check it.
+		if !impersonateUsers {
+			fmt.Fprintln(os.Stderr, "--gateway-listen requires --impersonate-users")
+			os.Exit(1)
+		}
+		fmt.Printf("Gateway is listening on %s\n", gatewayAddr)
+		go func() {
+			if err := http.ListenAndServe(gatewayAddr, gateway.New(grpcAddr)); err != nil {
+				gatewayErrCh <- err
+			}
+		}()
 	}
 
 	srvErrCh := make(chan error)
 	probeErrCh := make(chan error)
 	go func() {
 		svc := tiller.NewReleaseServer(env)
+		svc.ImpersonateUsers = impersonateUsers
+		svc.ChartRules = chartRules
 		services.RegisterReleaseServiceServer(rootServer, svc)
 		if err := rootServer.Serve(lstn); err != nil {
 			srvErrCh <- err
@@ -116,7 +288,7 @@ func start(c *cobra.Command, args []string) {
 	}()
 
 	go func() {
-		mux := newProbesMux()
+		mux := newProbesMux(env)
 		if err := http.ListenAndServe(probeAddr, mux); err != nil {
 			probeErrCh <- err
 		}
@@ -128,5 +300,59 @@ func start(c *cobra.Command, args []string) {
 		os.Exit(1)
 	case err := <-probeErrCh:
 		fmt.Fprintf(os.Stderr, "Probes server died: %s\n", err)
+	case err := <-gatewayErrCh:
+		fmt.Fprintf(os.Stderr, "Gateway died: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+// loadKeyProvider builds an AES key provider from the named Secret.
+//
+// The "key" entry holds the active key. Any other entries are previous keys,
+// kept so that releases written before a key rotation can still be read.
+func loadKeyProvider(c unversioned.Interface, name string) (driver.KeyProvider, error) {
+	secret, err := c.Secrets(environment.TillerNamespace).Get(name)
+	if err != nil {
+		return nil, err
+	}
+	active, ok := secret.Data["key"]
+	if !ok {
+		return nil, fmt.Errorf("secret %q has no \"key\" entry", name)
+	}
+	names := []string{}
+	for k := range secret.Data {
+		if k != "key" {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+	keys := [][]byte{active}
+	for _, k := range names {
+		keys = append(keys, secret.Data[k])
+	}
+	kp, err := driver.NewAESKeyProvider(keys...)
+	if err != nil {
+		return nil, err
+	}
+	return kp, nil
+}
+
+// loadSigner loads the named private key from a keyring. Tiller signs
+// releases unattended, so the key must not be protected by a passphrase.
+func loadSigner(keyring, key string) (*provenance.Signatory, error) {
+	signer, err := provenance.NewFromKeyring(keyring, key)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case signer.Entity == nil && key == "":
+		return nil, errors.New("--storage-signing-key is required")
+	case signer.Entity == nil:
+		return nil, fmt.Errorf("no key %q in %s", key, keyring)
+	case signer.Entity.PrivateKey == nil:
+		return nil, fmt.Errorf("key %q is not a private key", key)
+	case signer.Entity.PrivateKey.Encrypted:
+		return nil, fmt.Errorf("key %q is protected by a passphrase", key)
 	}
+	return signer, nil
 }
@@ -45,3 +45,25 @@ func TestInit(t *testing.T) {
 		t.Fatalf("Template engine GoTplEngine returned nil.")
 	}
 }
+
+func TestLoadSigner(t *testing.T) {
+	const keyring = "../../pkg/provenance/testdata/helm-test-key.secret"
+	if _, err := loadSigner(keyring, "helm-testing@helm.sh"); err != nil {
+		t.Errorf("expected the test key to load, got %s", err)
+	}
+
+	tests := []struct {
+		keyring, key, err string
+	}{
+		{keyring, "", "--storage-signing-key is required"},
+		{keyring, "nobody", `no key "nobody" in ` + keyring},
+		{"../../pkg/provenance/testdata/helm-test-key.pub", "helm-testing", `key "helm-testing" is not a private key`},
+		{"../../pkg/provenance/testdata/helm-password-key.secret", "fake", `key "fake" is protected by a passphrase`},
+	}
+	for _, tt := range tests {
+		_, err := loadSigner(tt.keyring, tt.key)
+		if err == nil || err.Error() != tt.err {
+			t.Errorf("%s %q: expected %q, got %v", tt.keyring, tt.key, tt.err, err)
+		}
+	}
+}
@@ -21,14 +21,18 @@ import (
 	"log"
 	"net/http"
 
+	// The debug server serves runtime variables and profiles.
+	_ "expvar"
 	_ "net/http/pprof"
 
 	"google.golang.org/grpc"
 )
 
-func startTracing(addr string) {
-	fmt.Printf("Tracing server is listening on %s\n", addr)
-	grpc.EnableTracing = true
+// startDebugServer serves runtime variables and pprof profiles on addr, and
+// traces of gRPC requests if tracing is set.
+func startDebugServer(addr string, tracing bool) {
+	fmt.Printf("Debug server is listening on %s\n", addr)
+	grpc.EnableTracing = tracing
 
 	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/" {
@@ -0,0 +1,296 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*Package chartref parses and validates references to charts.
+
+A chart can be referred to as REPO/NAME, for a chart in a repository added
+with 'helm repo add', by the URL of its archive, with an oci:// reference to
+a chart in an OCI registry, or by its path on the local file system. Parse
+tells them apart, validates them and normalizes them, so that every command
+accepts the same references and fails on bad ones with the same errors.
+*/
+package chartref // import "k8s.io/helm/pkg/chartref"
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver"
+)
+
+// Kind is what a chart reference refers to.
+type Kind int
+
+const (
+	// Path is a chart directory or archive on the local file system.
+	Path Kind = iota
+	// Repo is a chart in a repository added with 'helm repo add', e.g.
+	// stable/nginx.
+	Repo
+	// URL is the HTTP or HTTPS URL of a chart archive.
+	URL
+	// OCI is a chart in an OCI registry, e.g.
+	// oci://registry.example.com/project/nginx:1.2.3.
+	OCI
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Path:
+		return "path"
+	case Repo:
+		return "repository reference"
+	case URL:
+		return "URL"
+	case OCI:
+		return "OCI reference"
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// Ref is a parsed chart reference.
+type Ref struct {
+	Kind Kind
+	// Repo is the repository name of a Repo reference.
+	Repo string
+	// Name is the chart name of a Repo or OCI reference.
+	Name string
+	// Tag is the version in an OCI reference, if it has one.
+	Tag string
+	// Location is the cleaned path of a Path, or the normalized URL of a URL
+	// or OCI reference.
+	Location string
+}
+
+// String returns the reference in its normalized form.
+func (r *Ref) String() string {
+	if r.Kind == Repo {
+		return r.Repo + "/" + r.Name
+	}
+	return r.Location
+}
+
+// Error is a chart reference, name or version that is not valid.
+type Error struct {
+	// What is what was invalid, e.g. `chart reference "nginx"`.
+	What string
+	// Reason says what is wrong with it.
+	Reason string
+	// Suggestion, if set, is what was probably meant.
+	Suggestion string
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("invalid %s: %s", e.What, e.Reason)
+	if e.Suggestion != "" {
+		msg += fmt.Sprintf(". Did you mean %q?", e.Suggestion)
+	}
+	return msg
+}
+
+// namePattern is what chart and repository names may look like.
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ValidateName checks that name can be the name of a chart.
+func ValidateName(name string) error {
+	return validateName("chart name", name)
+}
+
+// ValidateRepoName checks that name can be the name of a repository.
+func ValidateRepoName(name string) error {
+	return validateName("repository name", name)
+}
+
+func validateName(what, name string) error {
+	if name == "" {
+		return &Error{What: what + ` ""`, Reason: "must not be empty"}
+	}
+	if !namePattern.MatchString(name) {
+		return &Error{
+			What:       fmt.Sprintf("%s %q", what, name),
+			Reason:     "must start with a letter or digit, and contain only letters, digits, '.', '_' and '-'",
+			Suggestion: cleanName(name),
+		}
+	}
+	return nil
+}
+
+// cleanName returns name with the characters a name may not have removed, or
+// "" if nothing is left.
+func cleanName(name string) string {
+	s := strings.TrimLeft(strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			return r
+		case r == ' ':
+			return '-'
+		}
+		return -1
+	}, name), "._-")
+	if s == name {
+		return ""
+	}
+	return s
+}
+
+// ValidateVersion checks that version is empty, a version or a version
+// constraint such as "~1.2".
+func ValidateVersion(version string) error {
+	if version == "" {
+		return nil
+	}
+	if _, err := semver.NewConstraint(version); err != nil {
+		return &Error{What: fmt.Sprintf("version %q", version), Reason: err.Error(), Suggestion: strings.Replace(version, " ", "", -1)}
+	}
+	return nil
+}
+
+// ValidateRepoURL checks that u can be the URL of a chart repository: an HTTP
+// or HTTPS URL with a host.
+func ValidateRepoURL(u string) error {
+	invalid := func(reason string) error {
+		return &Error{What: fmt.Sprintf("repository URL %q", u), Reason: reason}
+	}
+	parsed, err := url.Parse(strings.TrimSpace(u))
+	if err != nil {
+		return invalid(err.Error())
+	}
+	if s := strings.ToLower(parsed.Scheme); s != "http" && s != "https" {
+		return invalid("must be an http or https URL")
+	}
+	if parsed.Host == "" {
+		return invalid("the URL has no host")
+	}
+	return nil
+}
+
+// Parse parses and validates a chart reference.
+//
+// Surrounding whitespace is trimmed, paths are cleaned, and the schemes and
+// hosts of URLs are lowercased. Anything that exists on the local file
+// system is a Path, and so is anything that starts with "/", "." or "~", and
+// does not exist. A reference with a scheme must be an HTTP, HTTPS or OCI
+// URL, and anything else must be of the form REPO/NAME.
+func Parse(ref string) (*Ref, error) {
+	if _, err := os.Stat(strings.TrimSpace(ref)); err == nil {
+		return &Ref{Kind: Path, Location: filepath.Clean(strings.TrimSpace(ref))}, nil
+	}
+	return ParseRemote(ref)
+}
+
+// ParseRemote is Parse for a reference to a chart that is not on the local
+// file system, so that REPO/NAME is not taken for a path even if it exists.
+// References that start with "/", "." or "~" are still paths.
+func ParseRemote(ref string) (*Ref, error) {
+	ref = strings.TrimSpace(ref)
+	invalid := func(reason, suggestion string) error {
+		return &Error{What: fmt.Sprintf("chart reference %q", ref), Reason: reason, Suggestion: suggestion}
+	}
+	if ref == "" {
+		return nil, invalid("must not be empty", "")
+	}
+
+	if filepath.IsAbs(ref) || strings.HasPrefix(ref, ".") || strings.HasPrefix(ref, "~") {
+		return &Ref{Kind: Path, Location: filepath.Clean(ref)}, nil
+	}
+
+	if i := strings.Index(ref, "://"); i >= 0 {
+		return parseURL(ref, strings.ToLower(ref[:i]), invalid)
+	}
+
+	parts := strings.Split(ref, "/")
+	switch {
+	case len(parts) == 1 && strings.Count(ref, ":") == 1:
+		// stable:nginx
+		return nil, invalid("expected REPO/NAME", strings.Replace(ref, ":", "/", 1))
+	case len(parts) == 1:
+		return nil, invalid("expected REPO/NAME, a chart URL or the path of a chart", "")
+	case len(parts) > 2:
+		return nil, invalid("expected REPO/NAME, but it has more than one '/'", suggestRepoRef(parts))
+	}
+	r := &Ref{Kind: Repo, Repo: parts[0], Name: parts[1]}
+	if err := ValidateRepoName(r.Repo); err != nil {
+		return nil, invalid(err.(*Error).Reason, suggestRepoRef([]string{cleanName(r.Repo), r.Name}))
+	}
+	if err := ValidateName(r.Name); err != nil {
+		return nil, invalid(err.(*Error).Reason, suggestRepoRef([]string{r.Repo, cleanName(r.Name)}))
+	}
+	return r, nil
+}
+
+// suggestRepoRef joins the non-empty parts of a reference as REPO/NAME, if
+// there are exactly two.
+func suggestRepoRef(parts []string) string {
+	nonEmpty := []string{}
+	for _, p := range parts {
+		if p != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+	if len(nonEmpty) != 2 {
+		return ""
+	}
+	return nonEmpty[0] + "/" + nonEmpty[1]
+}
+
+func parseURL(ref, scheme string, invalid func(reason, suggestion string) error) (*Ref, error) {
+	switch scheme {
+	case "http", "https", "oci":
+	case "file":
+		return nil, invalid("file URLs are not supported", ref[len("file://"):])
+	default:
+		return nil, invalid(fmt.Sprintf("unsupported scheme %q: must be http, https or oci", scheme), "")
+	}
+
+	u, err := url.Parse(ref)
+	if err != nil {
+		return nil, invalid(err.Error(), "")
+	}
+	u.Scheme = scheme
+	u.Host = strings.ToLower(u.Host)
+	if u.Host == "" {
+		return nil, invalid("the URL has no host", "")
+	}
+	p := strings.Trim(u.Path, "/")
+	if p == "" {
+		return nil, invalid("the URL has no path to a chart", "")
+	}
+
+	if scheme != "oci" {
+		return &Ref{Kind: URL, Location: u.String()}, nil
+	}
+
+	// oci://HOST/NAMESPACE/NAME[:TAG]
+	name := p[strings.LastIndex(p, "/")+1:]
+	r := &Ref{Kind: OCI}
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		name, r.Tag = name[:i], name[i+1:]
+		if err := ValidateVersion(r.Tag); err != nil {
+			return nil, invalid(err.(*Error).Reason, "")
+		}
+	}
+	if err := ValidateName(name); err != nil {
+		return nil, invalid(err.(*Error).Reason, "")
+	}
+	r.Name = name
+	u.Path = "/" + p
+	r.Location = u.String()
+	return r, nil
+}
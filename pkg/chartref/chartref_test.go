@@ -0,0 +1,141 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartref
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		ref      string
+		kind     Kind
+		expect   string
+		fail     string
+		suggests string
+	}{
+		{ref: "stable/nginx", kind: Repo, expect: "stable/nginx"},
+		{ref: "  stable/nginx-ingress ", kind: Repo, expect: "stable/nginx-ingress"},
+		{ref: "HTTPS://Example.COM/charts/nginx-1.2.3.tgz", kind: URL, expect: "https://example.com/charts/nginx-1.2.3.tgz"},
+		{ref: "http://example.com:8080/nginx-1.2.3.tgz", kind: URL, expect: "http://example.com:8080/nginx-1.2.3.tgz"},
+		{ref: "oci://registry.example.com/project/nginx:1.2.3", kind: OCI, expect: "oci://registry.example.com/project/nginx:1.2.3"},
+		{ref: "oci://registry.example.com/nginx/", kind: OCI, expect: "oci://registry.example.com/nginx"},
+		{ref: "../chartref", kind: Path, expect: "../chartref"},
+		{ref: "./charts/../nginx", kind: Path, expect: "nginx"},
+		{ref: "/no/such/chart", kind: Path, expect: "/no/such/chart"},
+
+		{ref: "", fail: "must not be empty"},
+		{ref: "nginx", fail: "expected REPO/NAME"},
+		{ref: "stable:nginx", fail: "expected REPO/NAME", suggests: "stable/nginx"},
+		{ref: "stable//nginx", fail: "more than one '/'", suggests: "stable/nginx"},
+		{ref: "stable/nginx/ingress", fail: "more than one '/'"},
+		{ref: "stable/nginx ingress", fail: "only letters", suggests: "stable/nginx-ingress"},
+		{ref: "stable/-nginx", fail: "must start with", suggests: "stable/nginx"},
+		{ref: "file:///charts/nginx-1.2.3.tgz", fail: "file URLs", suggests: "/charts/nginx-1.2.3.tgz"},
+		{ref: "ftp://example.com/nginx-1.2.3.tgz", fail: "unsupported scheme"},
+		{ref: "https:///nginx-1.2.3.tgz", fail: "no host"},
+		{ref: "https://example.com/", fail: "no path"},
+		{ref: "oci://registry.example.com/nginx:one two", fail: "invalid"},
+	}
+	for _, tt := range tests {
+		r, err := Parse(tt.ref)
+		if tt.fail != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.fail) {
+				t.Errorf("%q: expected an error containing %q, got %v", tt.ref, tt.fail, err)
+				continue
+			}
+			if s := err.(*Error).Suggestion; s != tt.suggests {
+				t.Errorf("%q: expected the suggestion %q, got %q", tt.ref, tt.suggests, s)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %s", tt.ref, err)
+			continue
+		}
+		if r.Kind != tt.kind {
+			t.Errorf("%q: expected a %s, got a %s", tt.ref, tt.kind, r.Kind)
+		}
+		if r.String() != tt.expect {
+			t.Errorf("%q: expected %q, got %q", tt.ref, tt.expect, r.String())
+		}
+	}
+
+	r, err := Parse("oci://registry.example.com/project/nginx:1.2.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Name != "nginx" || r.Tag != "1.2.3" {
+		t.Errorf("unexpected OCI reference %+v", r)
+	}
+
+	// ParseRemote does not look at the file system.
+	if r, err := ParseRemote("chartref"); err == nil || r != nil {
+		t.Errorf("expected a single name to be invalid, got %v", r)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, name := range []string{"nginx", "nginx-ingress", "Nginx_2.0"} {
+		if err := ValidateName(name); err != nil {
+			t.Errorf("%q: %s", name, err)
+		}
+	}
+	for _, name := range []string{"", "-nginx", "nginx/ingress", "nginx ingress"} {
+		if err := ValidateName(name); err == nil {
+			t.Errorf("%q: expected an invalid chart name", name)
+		}
+	}
+	for _, v := range []string{"", "1.2.3", "v1.2.3", "~1.2", ">=1.0, <2.0", "1.2.x"} {
+		if err := ValidateVersion(v); err != nil {
+			t.Errorf("%q: %s", v, err)
+		}
+	}
+	for _, v := range []string{"latest", ">= 1 <"} {
+		if err := ValidateVersion(v); err == nil {
+			t.Errorf("%q: expected an invalid version", v)
+		}
+	}
+	for _, u := range []string{"http://127.0.0.1:8879", "https://example.com/charts/"} {
+		if err := ValidateRepoURL(u); err != nil {
+			t.Errorf("%q: %s", u, err)
+		}
+	}
+	for _, u := range []string{"", "example.com/charts", "ftp://example.com", "https://"} {
+		if err := ValidateRepoURL(u); err == nil {
+			t.Errorf("%q: expected an invalid repository URL", u)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"nginx-ingress", "nginx-ingress-controller", "mariadb", "mysql", "Redis"}
+	tests := map[string]string{
+		"nginx":   "nginx-ingress",
+		"mariabd": "mariadb",
+		"mysq":    "mysql",
+		"redis":   "Redis",
+		"postgre": "",
+		"":        "",
+	}
+	for name, expect := range tests {
+		if got := Suggest(name, candidates); got != expect {
+			t.Errorf("%q: expected %q, got %q", name, expect, got)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartref
+
+import (
+	"strings"
+)
+
+// Suggest returns the candidate that name most likely was meant to be, or ""
+// if none is close enough.
+//
+// A candidate that matches regardless of case is preferred, then one that
+// name is a prefix of, and then the candidate the fewest edits away, if that
+// is about a third of the length of name or fewer.
+func Suggest(name string, candidates []string) string {
+	if name == "" {
+		return ""
+	}
+	lower := strings.ToLower(name)
+	best, bestScore := "", -1
+	for _, c := range candidates {
+		lc := strings.ToLower(c)
+		var score int
+		switch {
+		case lc == lower:
+			score = 0
+		case strings.HasPrefix(lc, lower):
+			// Shorter extensions first: nginx-ingress before nginx-ingress-controller.
+			score = 1000 + len(lc) - len(lower)
+		default:
+			d := distance(lower, lc)
+			if d > len(lower)/3+1 {
+				continue
+			}
+			score = 2000 + d
+		}
+		if bestScore < 0 || score < bestScore || score == bestScore && c < best {
+			best, bestScore = c, score
+		}
+	}
+	return best
+}
+
+// distance is the Levenshtein distance between a and b.
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
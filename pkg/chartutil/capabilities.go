@@ -0,0 +1,111 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"sort"
+
+	"k8s.io/kubernetes/pkg/version"
+
+	tversion "k8s.io/helm/pkg/proto/hapi/version"
+)
+
+// DefaultVersionSet is the default version set, which includes only Core V1 ("v1").
+var DefaultVersionSet = NewVersionSet("v1")
+
+// DefaultKubeVersion is the Kubernetes version assumed when none can be discovered.
+var DefaultKubeVersion = &version.Info{
+	Major:      "1",
+	Minor:      "4",
+	GitVersion: "v1.4.0",
+}
+
+// kubeAPIChanges lists the API versions that each minor release of Kubernetes,
+// starting with 1.4, added to and removed from the ones it serves by default.
+var kubeAPIChanges = []struct {
+	minor          int
+	added, removed []string
+}{
+	{4, []string{"v1", "apps/v1alpha1", "autoscaling/v1", "batch/v1", "batch/v2alpha1", "extensions/v1beta1", "policy/v1alpha1", "rbac.authorization.k8s.io/v1alpha1", "storage.k8s.io/v1beta1"}, nil},
+	{5, []string{"apps/v1beta1", "policy/v1beta1"}, []string{"apps/v1alpha1", "policy/v1alpha1"}},
+	{6, []string{"autoscaling/v2alpha1", "rbac.authorization.k8s.io/v1beta1", "storage.k8s.io/v1"}, nil},
+	{7, []string{"networking.k8s.io/v1"}, nil},
+	{8, []string{"apps/v1beta2", "autoscaling/v2beta1", "batch/v1beta1", "rbac.authorization.k8s.io/v1"}, []string{"autoscaling/v2alpha1"}},
+	{9, []string{"apps/v1"}, nil},
+	{14, []string{"networking.k8s.io/v1beta1"}, nil},
+	{16, nil, []string{"apps/v1beta1", "apps/v1beta2"}},
+}
+
+// KubeAPIVersions returns the API versions that a cluster running the given
+// minor release of Kubernetes 1 serves by default, for simulating a cluster.
+//
+// Releases older than 1.4 are treated like 1.4, and releases newer than the
+// last one that changed the set like that one.
+func KubeAPIVersions(minor int) []string {
+	set := map[string]bool{}
+	for _, c := range kubeAPIChanges {
+		if c.minor > minor && c.minor != kubeAPIChanges[0].minor {
+			break
+		}
+		for _, v := range c.added {
+			set[v] = true
+		}
+		for _, v := range c.removed {
+			delete(set, v)
+		}
+	}
+	res := make([]string, 0, len(set))
+	for v := range set {
+		res = append(res, v)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// Capabilities describes the capabilities of the Kubernetes cluster that Tiller
+// is attached to, and of Tiller itself.
+//
+// It is exposed to templates as .Capabilities.
+type Capabilities struct {
+	// APIVersions lists the available API versions ("extensions/v1beta1") and
+	// resources ("extensions/v1beta1/Deployment").
+	APIVersions VersionSet
+	// KubeVersion is the Kubernetes version.
+	KubeVersion *version.Info
+	// TillerVersion is the Tiller version.
+	TillerVersion *tversion.Version
+}
+
+// VersionSet is a set of Kubernetes API versions.
+type VersionSet map[string]struct{}
+
+// NewVersionSet creates a new version set from a list of strings.
+func NewVersionSet(apiVersions ...string) VersionSet {
+	vs := VersionSet{}
+	for _, v := range apiVersions {
+		vs[v] = struct{}{}
+	}
+	return vs
+}
+
+// Has returns true if the version string is in the set.
+//
+//	vs.Has("extensions/v1beta1")
+func (v VersionSet) Has(apiVersion string) bool {
+	_, ok := v[apiVersion]
+	return ok
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"testing"
+)
+
+func TestKubeAPIVersions(t *testing.T) {
+	tests := []struct {
+		minor      int
+		has, lacks []string
+	}{
+		{2, []string{"v1", "extensions/v1beta1"}, []string{"apps/v1beta1"}},
+		{5, []string{"apps/v1beta1", "policy/v1beta1"}, []string{"apps/v1alpha1", "apps/v1"}},
+		{8, []string{"apps/v1beta2", "batch/v1beta1"}, []string{"apps/v1", "autoscaling/v2alpha1"}},
+		{9, []string{"apps/v1", "apps/v1beta2"}, nil},
+		{20, []string{"apps/v1", "networking.k8s.io/v1beta1"}, []string{"apps/v1beta1", "apps/v1beta2"}},
+	}
+	for _, tt := range tests {
+		vs := NewVersionSet(KubeAPIVersions(tt.minor)...)
+		for _, v := range tt.has {
+			if !vs.Has(v) {
+				t.Errorf("1.%d: expected %s", tt.minor, v)
+			}
+		}
+		for _, v := range tt.lacks {
+			if vs.Has(v) {
+				t.Errorf("1.%d: did not expect %s", tt.minor, v)
+			}
+		}
+	}
+}
@@ -24,6 +24,8 @@ A chart can be represented on the file system in one of two ways:
 	- As a directory that contains a Chart.yaml file and other chart things.
 	- As a tarred gzipped file containing a directory that then contains a
 	Chart.yaml file.
+	- As a tar file compressed with zstd, with an index of its files (see
+	FormatTzst).
 
 This package provides utilitites for working with those file formats.
 
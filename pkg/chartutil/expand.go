@@ -18,19 +18,49 @@ package chartutil
 
 import (
 	"archive/tar"
-	"compress/gzip"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // Expand uncompresses and extracts a chart into the specified directory.
+//
+// File contents, line endings included, are written exactly as archived.
+// Files are readable and writable by their owner whatever mode the archive
+// records, and directories are created with mode 0755.
+//
+// Entries that would be written outside of dir (absolute paths, or paths that
+// climb out with ".."), and symbolic or hard links that point outside of dir,
+// are rejected, so that an untrusted chart cannot overwrite other files. Paths
+// are checked as they are on disk, following the links extracted before them,
+// so that links cannot be chained to climb out either.
 func Expand(dir string, r io.Reader) error {
-	gr, err := gzip.NewReader(r)
+	return expand(dir, r, false)
+}
+
+// ExpandInsecure is like Expand, but writes every entry where its path says,
+// even outside of dir. It must only be used on trusted archives.
+func ExpandInsecure(dir string, r io.Reader) error {
+	return expand(dir, r, true)
+}
+
+func expand(dir string, r io.Reader, insecure bool) error {
+	gr, err := decompress(r)
 	if err != nil {
 		return err
 	}
 	defer gr.Close()
+
+	var root string
+	if !insecure {
+		if root, err = realDir(dir); err != nil {
+			return err
+		}
+	}
+	var links []string
+
 	tr := tar.NewReader(gr)
 	for {
 		header, err := tr.Next()
@@ -40,38 +70,131 @@ func Expand(dir string, r io.Reader) error {
 			return err
 		}
 
-		//split header name and create missing directories
-		d, _ := filepath.Split(header.Name)
-		fullDir := filepath.Join(dir, d)
-		_, err = os.Stat(fullDir)
-		if err != nil && d != "" {
-			if err := os.MkdirAll(fullDir, 0700); err != nil {
+		// Archive paths use forward slashes, but older versions of Helm on
+		// Windows wrote backslashes.
+		name := filepath.FromSlash(strings.Replace(header.Name, "\\", "/", -1))
+		if !insecure && !inTree(name) {
+			return fmt.Errorf("refusing to extract %q: it is outside of the chart directory", header.Name)
+		}
+		if !insecure {
+			full := header.Typeflag != tar.TypeSymlink && header.Typeflag != tar.TypeLink
+			if !within(root, resolve(root, filepath.Dir(name))) || (full && !within(root, resolve(root, name))) {
+				return fmt.Errorf("refusing to extract %q: a link in its path leads outside of the chart directory", header.Name)
+			}
+		}
+		path := longPath(filepath.Clean(filepath.Join(dir, name)))
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err = os.MkdirAll(path, 0755); err != nil {
 				return err
 			}
+			continue
+		case tar.TypeReg, tar.TypeRegA, tar.TypeSymlink, tar.TypeLink:
+		default:
+			// Devices, FIFOs and the like have no place in a chart.
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
 		}
 
-		path := filepath.Clean(filepath.Join(dir, header.Name))
-		info := header.FileInfo()
-		if info.IsDir() {
-			if err = os.MkdirAll(path, info.Mode()); err != nil {
+		switch header.Typeflag {
+		case tar.TypeSymlink:
+			// A link target is relative to the directory holding the link.
+			target := filepath.FromSlash(header.Linkname)
+			if !insecure && (filepath.IsAbs(target) || !inTree(filepath.Join(filepath.Dir(name), target)) ||
+				!within(root, resolve(resolve(root, filepath.Dir(name)), target))) {
+				return fmt.Errorf("refusing to extract %q: it links to %q, outside of the chart directory", header.Name, header.Linkname)
+			}
+			os.Remove(path)
+			if err := os.Symlink(target, path); err != nil {
+				return err
+			}
+			links = append(links, name)
+			continue
+		case tar.TypeLink:
+			// A hard link target is relative to the root of the archive.
+			target := filepath.FromSlash(header.Linkname)
+			if !insecure && (!inTree(target) || !within(root, resolve(root, target))) {
+				return fmt.Errorf("refusing to extract %q: it links to %q, outside of the chart directory", header.Name, header.Linkname)
+			}
+			os.Remove(path)
+			if err := os.Link(filepath.Join(dir, target), path); err != nil {
 				return err
 			}
 			continue
 		}
 
-		file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, info.Mode())
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, header.FileInfo().Mode().Perm()|0600)
 		if err != nil {
 			return err
 		}
-		defer file.Close()
 		_, err = io.Copy(file, tr)
+		if cerr := file.Close(); err == nil {
+			err = cerr
+		}
 		if err != nil {
 			return err
 		}
 	}
+
+	// A link whose target did not exist when it was extracted may have been
+	// led outside by links extracted after it.
+	if !insecure {
+		for _, l := range links {
+			path := filepath.Join(dir, l)
+			if real, err := filepath.EvalSymlinks(path); err == nil && !within(root, real) {
+				os.Remove(path)
+				return fmt.Errorf("refusing to extract %q: it links outside of the chart directory", filepath.ToSlash(l))
+			}
+		}
+	}
 	return nil
 }
 
+// realDir creates dir if it does not exist, and returns its absolute path
+// with symbolic links resolved.
+func realDir(dir string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(abs)
+}
+
+// resolve returns the path rel below the directory base, following the
+// symbolic links on its way that exist on disk. The components that do not
+// exist yet are joined as they are.
+func resolve(base, rel string) string {
+	sep := string(filepath.Separator)
+	parts := strings.Split(rel, sep)
+	for i := len(parts); i > 0; i-- {
+		if real, err := filepath.EvalSymlinks(base + sep + strings.Join(parts[:i], sep)); err == nil {
+			return filepath.Join(append([]string{real}, parts[i:]...)...)
+		}
+	}
+	return filepath.Join(append([]string{base}, parts...)...)
+}
+
+// within reports whether path is root or inside of it.
+func within(root, path string) bool {
+	return path == root || strings.HasPrefix(path, strings.TrimSuffix(root, string(filepath.Separator))+string(filepath.Separator))
+}
+
+// inTree reports whether the relative path name stays inside of the directory
+// it is relative to.
+func inTree(name string) bool {
+	if filepath.IsAbs(name) || filepath.VolumeName(name) != "" || strings.HasPrefix(name, string(filepath.Separator)) {
+		return false
+	}
+	clean := filepath.Clean(name)
+	return clean != ".." && !strings.HasPrefix(clean, ".."+string(filepath.Separator))
+}
+
 // ExpandFile expands the src file into the dest directory.
 func ExpandFile(dest, src string) error {
 	h, err := os.Open(src)
@@ -81,3 +204,14 @@ func ExpandFile(dest, src string) error {
 	defer h.Close()
 	return Expand(dest, h)
 }
+
+// ExpandFileInsecure expands the src file into the dest directory, like
+// ExpandInsecure.
+func ExpandFileInsecure(dest, src string) error {
+	h, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer h.Close()
+	return ExpandInsecure(dest, h)
+}
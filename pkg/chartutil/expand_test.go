@@ -0,0 +1,226 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// windowsArchive returns a chart archive like those older versions of Helm
+// wrote on Windows, with backslashes in its paths and no permissions.
+func windowsArchive(t *testing.T) []byte {
+	b := bytes.NewBuffer(nil)
+	zw := gzip.NewWriter(b)
+	tw := tar.NewWriter(zw)
+	files := []struct{ name, body string }{
+		{`winchart\Chart.yaml`, "name: winchart\r\nversion: 0.1.0\r\n"},
+		{`winchart\templates\cm.yaml`, "kind: ConfigMap\r\n"},
+	}
+	for _, f := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: f.name, Size: int64(len(f.body))}); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(f.body))
+	}
+	tw.Close()
+	zw.Close()
+	return b.Bytes()
+}
+
+func TestExpand(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "helm-expand-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	if err := Expand(tmp, bytes.NewReader(windowsArchive(t))); err != nil {
+		t.Fatal(err)
+	}
+
+	p := filepath.Join(tmp, "winchart", "templates", "cm.yaml")
+	fi, err := os.Stat(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode().Perm()&0600 != 0600 {
+		t.Errorf("expected %s to be readable and writable by its owner, got %v", p, fi.Mode())
+	}
+	b, err := ioutil.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "kind: ConfigMap\r\n" {
+		t.Errorf("expected content to be unchanged, got %q", b)
+	}
+}
+
+func TestLoadArchiveBackslashes(t *testing.T) {
+	c, err := LoadArchive(bytes.NewReader(windowsArchive(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Metadata.Name != "winchart" {
+		t.Errorf("expected chart winchart, got %q", c.Metadata.Name)
+	}
+	if len(c.Templates) != 1 || c.Templates[0].Name != "templates/cm.yaml" {
+		t.Errorf("expected templates/cm.yaml, got %v", c.Templates)
+	}
+}
+
+func tarball(t *testing.T, headers ...*tar.Header) []byte {
+	b := bytes.NewBuffer(nil)
+	zw := gzip.NewWriter(b)
+	tw := tar.NewWriter(zw)
+	for _, h := range headers {
+		body := []byte("data")
+		if h.Typeflag == tar.TypeReg {
+			h.Size = int64(len(body))
+		}
+		if err := tw.WriteHeader(h); err != nil {
+			t.Fatal(err)
+		}
+		if h.Typeflag == tar.TypeReg {
+			tw.Write(body)
+		}
+	}
+	tw.Close()
+	zw.Close()
+	return b.Bytes()
+}
+
+func TestExpandUnsafe(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []*tar.Header
+	}{
+		{"parent directory", []*tar.Header{{Name: "mychart/../../evil", Typeflag: tar.TypeReg, Mode: 0644}}},
+		{"absolute path", []*tar.Header{{Name: "/tmp/evil", Typeflag: tar.TypeReg, Mode: 0644}}},
+		{"symlink out of tree", []*tar.Header{{Name: "mychart/link", Typeflag: tar.TypeSymlink, Linkname: "../../etc"}}},
+		{"absolute symlink", []*tar.Header{{Name: "mychart/link", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"}}},
+		{"hard link out of tree", []*tar.Header{{Name: "mychart/link", Typeflag: tar.TypeLink, Linkname: "../evil"}}},
+	}
+
+	for _, tt := range tests {
+		tmp, err := ioutil.TempDir("", "helm-expand-")
+		if err != nil {
+			t.Fatal(err)
+		}
+		dir := filepath.Join(tmp, "a", "b")
+		os.MkdirAll(dir, 0755)
+
+		if err := Expand(dir, bytes.NewReader(tarball(t, tt.headers...))); err == nil {
+			t.Errorf("%s: expected the archive to be rejected", tt.name)
+		}
+		if _, err := os.Lstat(filepath.Join(tmp, "evil")); err == nil {
+			t.Errorf("%s: a file was written outside of the directory", tt.name)
+		}
+		os.RemoveAll(tmp)
+	}
+}
+
+func TestExpandChainedLinks(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []*tar.Header
+	}{
+		{"link through a link", []*tar.Header{
+			{Name: "d", Typeflag: tar.TypeSymlink, Linkname: "."},
+			{Name: "d/e", Typeflag: tar.TypeSymlink, Linkname: ".."},
+			{Name: "e/pwned", Typeflag: tar.TypeReg, Mode: 0644},
+		}},
+		{"link redirected by a later link", []*tar.Header{
+			{Name: "x", Typeflag: tar.TypeSymlink, Linkname: "n/.."},
+			{Name: "n", Typeflag: tar.TypeSymlink, Linkname: "."},
+			{Name: "x/pwned", Typeflag: tar.TypeReg, Mode: 0644},
+		}},
+		{"dangling link redirected by a later link", []*tar.Header{
+			{Name: "x", Typeflag: tar.TypeSymlink, Linkname: "n/../pwned"},
+			{Name: "n", Typeflag: tar.TypeSymlink, Linkname: "."},
+		}},
+		{"hard link through a link", []*tar.Header{
+			{Name: "d", Typeflag: tar.TypeSymlink, Linkname: "."},
+			{Name: "x", Typeflag: tar.TypeLink, Linkname: "d/../pwned"},
+		}},
+	}
+
+	for _, tt := range tests {
+		tmp, err := ioutil.TempDir("", "helm-expand-")
+		if err != nil {
+			t.Fatal(err)
+		}
+		dir := filepath.Join(tmp, "a", "b")
+		os.MkdirAll(dir, 0755)
+		// The target of the hard link.
+		ioutil.WriteFile(filepath.Join(tmp, "a", "pwned"), []byte("outside"), 0644)
+
+		if err := Expand(dir, bytes.NewReader(tarball(t, tt.headers...))); err == nil {
+			t.Errorf("%s: expected the archive to be rejected", tt.name)
+		}
+		if b, _ := ioutil.ReadFile(filepath.Join(tmp, "a", "pwned")); string(b) != "outside" {
+			t.Errorf("%s: a file was written outside of the directory", tt.name)
+		}
+		os.RemoveAll(tmp)
+	}
+}
+
+func TestExpandLinks(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "helm-expand-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	archive := tarball(t,
+		&tar.Header{Name: "mychart/values.yaml", Typeflag: tar.TypeReg, Mode: 0644},
+		&tar.Header{Name: "mychart/templates/values.yaml", Typeflag: tar.TypeSymlink, Linkname: "../values.yaml"},
+		&tar.Header{Name: "mychart/copy.yaml", Typeflag: tar.TypeLink, Linkname: "mychart/values.yaml"},
+	)
+	if err := Expand(tmp, bytes.NewReader(archive)); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{"mychart/templates/values.yaml", "mychart/copy.yaml"} {
+		b, err := ioutil.ReadFile(filepath.Join(tmp, p))
+		if err != nil || string(b) != "data" {
+			t.Errorf("expected %s to link to values.yaml, got %q (%v)", p, b, err)
+		}
+	}
+}
+
+func TestExpandInsecure(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "helm-expand-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	dir := filepath.Join(tmp, "a")
+	os.MkdirAll(dir, 0755)
+
+	archive := tarball(t, &tar.Header{Name: "../outside", Typeflag: tar.TypeReg, Mode: 0644})
+	if err := ExpandInsecure(dir, bytes.NewReader(archive)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(tmp, "outside")); err != nil {
+		t.Errorf("expected ExpandInsecure to honor the archived path: %s", err)
+	}
+}
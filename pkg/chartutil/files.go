@@ -16,6 +16,13 @@ limitations under the License.
 package chartutil
 
 import (
+	"encoding/base64"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
 	"github.com/gobwas/glob"
 	"github.com/golang/protobuf/ptypes/any"
 )
@@ -60,6 +67,36 @@ func (f Files) Get(name string) string {
 	return string(f.GetBytes(name))
 }
 
+// FileTemplateExt is the extension of file templates. A file under the
+// chart's files/ directory with this extension is rendered by the engine
+// before the chart's templates, and its output is stored under its name
+// without the extension, e.g. files/nginx.conf.tpl renders to files/nginx.conf.
+const FileTemplateExt = ".tpl"
+
+// IsFileTemplate reports whether the chart file at name is a file template.
+func IsFileTemplate(name string) bool {
+	return strings.HasPrefix(name, "files/") && strings.HasSuffix(name, FileTemplateExt)
+}
+
+// GetTemplate returns the rendered output of a file template. The name may be
+// given with or without the FileTemplateExt extension.
+//
+// Unlike Get, it fails if there is no such file template, so that a typo does
+// not silently embed an empty file.
+//
+//	{{.Files.GetTemplate "files/nginx.conf"}}
+func (f Files) GetTemplate(name string) (string, error) {
+	name = strings.TrimSuffix(name, FileTemplateExt)
+	if _, ok := f[name+FileTemplateExt]; !ok || !IsFileTemplate(name+FileTemplateExt) {
+		return "", fmt.Errorf("no file template %q", name+FileTemplateExt)
+	}
+	v, ok := f[name]
+	if !ok {
+		return "", fmt.Errorf("file template %q is not rendered yet: file templates cannot get each other", name+FileTemplateExt)
+	}
+	return string(v), nil
+}
+
 // Glob takes a glob pattern and returns another files object only containing
 // matched  files.
 //
@@ -83,3 +120,79 @@ func (f Files) Glob(pattern string) Files {
 
 	return nf
 }
+
+// AsConfig turns the files into the data of a ConfigMap, keyed by the base
+// name of each file, as a YAML object. The sources of file templates are left
+// out, as their rendered output is in the files as well.
+//
+// If two files have the same base name, the one that sorts last wins.
+//
+// This is designed to be called from a template.
+//
+//	data:
+//	{{ (.Files.Glob "config/*").AsConfig | indent 2 }}
+func (f Files) AsConfig() string {
+	m := map[string]string{}
+	for _, k := range f.embeddable() {
+		m[path.Base(k)] = string(f[k])
+	}
+	return filesYaml(m)
+}
+
+// AsSecrets turns the files into the data of a Secret, like AsConfig, but
+// with the contents base64 encoded.
+//
+// This is designed to be called from a template.
+//
+//	data:
+//	{{ (.Files.Glob "secrets/*").AsSecrets | indent 2 }}
+func (f Files) AsSecrets() string {
+	m := map[string]string{}
+	for _, k := range f.embeddable() {
+		m[path.Base(k)] = base64.StdEncoding.EncodeToString(f[k])
+	}
+	return filesYaml(m)
+}
+
+// Lines returns the lines of a file, without their line endings. A missing
+// file has no lines.
+//
+// This is designed to be called from a template.
+//
+//	{{ range .Files.Lines "foo/bar.txt" }}
+//	- {{ . }}{{ end }}
+func (f Files) Lines(name string) []string {
+	s := strings.TrimSuffix(strings.Replace(f.Get(name), "\r\n", "\n", -1), "\n")
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(s, "\n")
+}
+
+// embeddable returns the sorted names of the files, leaving out the sources of
+// file templates whose output has been rendered.
+func (f Files) embeddable() []string {
+	names := make([]string, 0, len(f))
+	for k := range f {
+		if IsFileTemplate(k) {
+			if _, ok := f[strings.TrimSuffix(k, FileTemplateExt)]; ok {
+				continue
+			}
+		}
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func filesYaml(m map[string]string) string {
+	if len(m) == 0 {
+		return ""
+	}
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		// Swallow errors inside of a template.
+		return ""
+	}
+	return string(data)
+}
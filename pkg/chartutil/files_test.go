@@ -68,3 +68,77 @@ func TestFileGlob(t *testing.T) {
 		t.Errorf("Wrong globbed file content. Expected %s, got %s", expect, m)
 	}
 }
+
+func TestFileGetTemplate(t *testing.T) {
+	f := Files{
+		"files/nginx.conf.tpl": []byte("listen {{ .Values.port }};"),
+		"files/nginx.conf":     []byte("listen 80;"),
+		"files/pending.tpl":    []byte("{{ .Values.port }}"),
+		"ship/captain.txt.tpl": []byte("The Captain"),
+	}
+
+	for _, name := range []string{"files/nginx.conf", "files/nginx.conf.tpl"} {
+		got, err := f.GetTemplate(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != "listen 80;" {
+			t.Errorf("GetTemplate(%q) = %q", name, got)
+		}
+	}
+
+	if _, err := f.GetTemplate("files/missing.conf"); err == nil || err.Error() != `no file template "files/missing.conf.tpl"` {
+		t.Errorf("unexpected error for a missing template: %v", err)
+	}
+	if _, err := f.GetTemplate("ship/captain.txt"); err == nil {
+		t.Error("expected an error for a file outside of files/")
+	}
+	if _, err := f.GetTemplate("files/pending"); err == nil {
+		t.Error("expected an error for a template that is not rendered")
+	}
+}
+
+func TestFileAsConfig(t *testing.T) {
+	f := NewFiles(getTestFiles())
+	f["files/app.conf.tpl"] = []byte("{{ .Values.x }}")
+	f["files/app.conf"] = []byte("x")
+
+	expect := "author.txt: Joseph Conrad\nname.txt: The Secret Sharer\n"
+	if got := f.Glob("story/**").AsConfig(); got != expect {
+		t.Errorf("Expected %q, got %q", expect, got)
+	}
+	if got, expect := f.Glob("files/*").AsConfig(), "app.conf: x\n"; got != expect {
+		t.Errorf("Expected the source of a file template to be left out, got %q", got)
+	}
+	if got := f.Glob("nothing/*").AsConfig(); got != "" {
+		t.Errorf("Expected no files to give no data, got %q", got)
+	}
+}
+
+func TestFileAsSecrets(t *testing.T) {
+	f := NewFiles(getTestFiles())
+
+	expect := "author.txt: Sm9zZXBoIENvbnJhZA==\nname.txt: VGhlIFNlY3JldCBTaGFyZXI=\n"
+	if got := f.Glob("story/**").AsSecrets(); got != expect {
+		t.Errorf("Expected %q, got %q", expect, got)
+	}
+}
+
+func TestFileLines(t *testing.T) {
+	f := Files{
+		"unix.txt":    []byte("one\ntwo\n"),
+		"windows.txt": []byte("one\r\ntwo"),
+		"empty.txt":   []byte(""),
+	}
+	for _, name := range []string{"unix.txt", "windows.txt"} {
+		lines := f.Lines(name)
+		if len(lines) != 2 || lines[0] != "one" || lines[1] != "two" {
+			t.Errorf("Lines(%q) = %q", name, lines)
+		}
+	}
+	for _, name := range []string{"empty.txt", "missing.txt"} {
+		if lines := f.Lines(name); len(lines) != 0 {
+			t.Errorf("Lines(%q) = %q, expected no lines", name, lines)
+		}
+	}
+}
@@ -19,7 +19,6 @@ package chartutil
 import (
 	"archive/tar"
 	"bytes"
-	"compress/gzip"
 	"errors"
 	"fmt"
 	"io"
@@ -58,9 +57,19 @@ type afile struct {
 	data []byte
 }
 
-// LoadArchive loads from a reader containing a compressed tar archive.
+// LoadArchive loads from a reader containing a compressed tar archive, in
+// either of the formats Save and SaveArchive write.
 func LoadArchive(in io.Reader) (*chart.Chart, error) {
-	unzipped, err := gzip.NewReader(in)
+	return loadArchive(in, 0)
+}
+
+// maxDeflateRatio is the most DEFLATE, and so gzip, can compress data by.
+const maxDeflateRatio = 1032
+
+// loadArchive loads a chart archive whose entries hold at most limit bytes in
+// all, if limit is set. See readEntry.
+func loadArchive(in io.Reader, limit int64) (*chart.Chart, error) {
+	unzipped, err := decompress(in)
 	if err != nil {
 		return &chart.Chart{}, err
 	}
@@ -69,7 +78,6 @@ func LoadArchive(in io.Reader) (*chart.Chart, error) {
 	files := []*afile{}
 	tr := tar.NewReader(unzipped)
 	for {
-		b := bytes.NewBuffer(nil)
 		hd, err := tr.Next()
 		if err == io.EOF {
 			break
@@ -84,19 +92,19 @@ func LoadArchive(in io.Reader) (*chart.Chart, error) {
 			continue
 		}
 
-		parts := strings.Split(hd.Name, "/")
+		parts := archivePath(hd.Name)
 		n := strings.Join(parts[1:], "/")
 
 		if parts[0] == "Chart.yaml" {
 			return nil, errors.New("chart yaml not in base directory")
 		}
 
-		if _, err := io.Copy(b, tr); err != nil {
+		data, err := readEntry(tr, hd.Size, limit)
+		if err != nil {
 			return &chart.Chart{}, err
 		}
 
-		files = append(files, &afile{name: n, data: b.Bytes()})
-		b.Reset()
+		files = append(files, &afile{name: n, data: data})
 	}
 
 	if len(files) == 0 {
@@ -106,6 +114,123 @@ func LoadArchive(in io.Reader) (*chart.Chart, error) {
 	return loadFiles(files)
 }
 
+// archivePath splits the name of an archive entry into its path elements.
+//
+// Archives written on Windows by older versions of Helm may use backslashes as
+// path separators.
+func archivePath(name string) []string {
+	return strings.Split(strings.Replace(name, "\\", "/", -1), "/")
+}
+
+// readEntry reads the current entry of an archive, which claims to be size
+// bytes long.
+//
+// If the archive is known to hold no more than limit bytes, and size is within
+// it, the entry is read into a slice of exactly that size: growing a buffer as
+// the data is read would allocate up to twice the size of every file, and
+// large charts are often dominated by a few large files. Other sizes are not
+// trusted. The slice then grows as the data arrives, doubling from 1MB, so that
+// an entry which lies about its size cannot make the reader allocate more than
+// the archive really holds.
+func readEntry(r io.Reader, size, limit int64) ([]byte, error) {
+	if size <= 0 {
+		return ioutil.ReadAll(r)
+	}
+	if size <= limit {
+		buf := make([]byte, size)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return buf, nil
+	}
+	const chunk = 1 << 20
+	buf := make([]byte, 0, minSize(size, chunk))
+	for {
+		if len(buf) == cap(buf) {
+			if int64(len(buf)) >= size {
+				return buf, nil
+			}
+			grown := make([]byte, len(buf), minSize(size, 2*int64(cap(buf))))
+			copy(grown, buf)
+			buf = grown
+		}
+		n, err := r.Read(buf[len(buf):cap(buf)])
+		buf = buf[:len(buf)+n]
+		if err == io.EOF {
+			return buf, nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func minSize(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// LoadMetadata loads only the Chart.yaml of the chart at name, which may be a
+// directory or an archive.
+//
+// A tgz archive is read to its end, so that a corrupt archive is still
+// reported, but the contents of its other files are skipped rather than held
+// in memory. Of a tzst archive, only the index and Chart.yaml are read.
+// Callers that need nothing but the metadata of a large chart should use this
+// instead of Load.
+func LoadMetadata(name string) (*chart.Metadata, error) {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	if fi.IsDir() {
+		return LoadChartfile(filepath.Join(name, ChartfileName))
+	}
+
+	raw, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer raw.Close()
+	if isTzst(raw) {
+		c, err := LoadArchiveFiles(raw, fi.Size(), ChartfileName)
+		if err != nil {
+			return nil, err
+		}
+		return c.Metadata, nil
+	}
+	unzipped, err := decompress(raw)
+	if err != nil {
+		return nil, err
+	}
+	defer unzipped.Close()
+
+	var md *chart.Metadata
+	tr := tar.NewReader(unzipped)
+	for {
+		hd, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if parts := archivePath(hd.Name); len(parts) == 2 && parts[1] == ChartfileName && md == nil {
+			data, err := readEntry(tr, hd.Size, 0)
+			if err != nil {
+				return nil, err
+			}
+			if md, err = UnmarshalChartfile(data); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if md == nil || md.Name == "" {
+		return nil, errors.New("chart metadata (Chart.yaml) missing")
+	}
+	return md, nil
+}
+
 func loadFiles(files []*afile) (*chart.Chart, error) {
 	c := &chart.Chart{}
 	subcharts := map[string][]*afile{}
@@ -151,14 +276,15 @@ func loadFiles(files []*afile) (*chart.Chart, error) {
 		var err error
 		if strings.IndexAny(n, "_.") == 0 {
 			continue
-		} else if filepath.Ext(n) == ".tgz" {
+		} else if IsArchive(n) {
 			file := files[0]
 			if file.name != n {
 				return c, fmt.Errorf("error unpacking tar in %s: expected %s, got %s", c.Metadata.Name, n, file.name)
 			}
 			// Untar the chart and add to c.Dependencies
-			b := bytes.NewBuffer(file.data)
-			sc, err = LoadArchive(b)
+			sc, err = LoadArchive(bytes.NewReader(file.data))
+			// The loaded chart holds its own copy of everything it needs.
+			file.data = nil
 		} else {
 			// We have to trim the prefix off of every file, and ignore any file
 			// that is in charts/, but isn't actually a chart.
@@ -186,7 +312,8 @@ func loadFiles(files []*afile) (*chart.Chart, error) {
 
 // LoadFile loads from an archive file.
 func LoadFile(name string) (*chart.Chart, error) {
-	if fi, err := os.Stat(name); err != nil {
+	fi, err := os.Stat(name)
+	if err != nil {
 		return nil, err
 	} else if fi.IsDir() {
 		return nil, errors.New("cannot load a directory")
@@ -198,7 +325,13 @@ func LoadFile(name string) (*chart.Chart, error) {
 	}
 	defer raw.Close()
 
-	return LoadArchive(raw)
+	// The size of a gzip file bounds what it can hold, so its entries can be
+	// read into slices of their size.
+	limit := int64(0)
+	if !isTzst(raw) {
+		limit = fi.Size() * maxDeflateRatio
+	}
+	return loadArchive(raw, limit)
 }
 
 // LoadDir loads from a directory.
@@ -17,6 +17,7 @@ limitations under the License.
 package chartutil
 
 import (
+	"bytes"
 	"testing"
 
 	"k8s.io/helm/pkg/proto/hapi/chart"
@@ -156,3 +157,72 @@ func verifyFrobnitz(t *testing.T, c *chart.Chart) {
 		t.Error("No template data.")
 	}
 }
+
+func TestLoadMetadata(t *testing.T) {
+	for _, name := range []string{"testdata/frobnitz", "testdata/frobnitz-1.2.3.tgz"} {
+		md, err := LoadMetadata(name)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		if md.Name != "frobnitz" || md.Version != "1.2.3" {
+			t.Errorf("%s: unexpected metadata %v", name, md)
+		}
+	}
+
+	if _, err := LoadMetadata("testdata/frobnitz/values.yaml"); err == nil {
+		t.Error("expected a file that is not an archive to fail")
+	}
+}
+
+func TestReadEntry(t *testing.T) {
+	data := bytes.Repeat([]byte("x"), 3<<20+17)
+	for _, limit := range []int64{0, 1 << 30} {
+		for _, size := range []int64{int64(len(data)), 0, 10} {
+			b, err := readEntry(bytes.NewReader(data), size, limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := len(data)
+			if size > 0 {
+				want = int(size)
+			}
+			if len(b) != want {
+				t.Errorf("size %d, limit %d: expected %d bytes, got %d", size, limit, want, len(b))
+			}
+			if size > 0 && limit > 0 && cap(b) != want {
+				t.Errorf("size %d, limit %d: expected a slice of exactly %d bytes, got %d", size, limit, want, cap(b))
+			}
+		}
+	}
+
+	// An entry claiming to be larger than it is only gets what is there.
+	b, err := readEntry(bytes.NewReader([]byte("short")), 1<<40, 1<<20)
+	if err != nil || string(b) != "short" {
+		t.Errorf("expected the short entry to be read, got %q (%v)", b, err)
+	}
+	// Within the limit, it is cut short.
+	if _, err := readEntry(bytes.NewReader([]byte("short")), 10, 1<<20); err == nil {
+		t.Error("expected a truncated entry to fail")
+	}
+}
+
+// BenchmarkReadEntry compares reading a large entry whose size is trusted with
+// reading one whose size is not.
+func BenchmarkReadEntry(b *testing.B) {
+	data := bytes.Repeat([]byte("x"), 48<<20)
+	size := int64(len(data))
+	for _, bb := range []struct {
+		name  string
+		limit int64
+	}{{"trusted", size}, {"untrusted", 0}} {
+		b.Run(bb.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(size)
+			for i := 0; i < b.N; i++ {
+				if _, err := readEntry(bytes.NewReader(data), size, bb.limit); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,43 @@
+// Copyright 2016 The Kubernetes Authors All rights reserved.
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// +build windows
+
+package chartutil
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// maxPath is the longest path most Windows APIs accept (MAX_PATH), less room
+// for a file name when the path is a directory.
+const maxPath = 248
+
+// longPath returns path in the extended-length form (\\?\C:\...) if it is
+// too long for the Windows APIs to accept otherwise. Deeply nested charts
+// unpacked into a deep directory easily exceed the limit.
+func longPath(path string) string {
+	if len(path) < maxPath || strings.HasPrefix(path, `\\?\`) {
+		return path
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	if strings.HasPrefix(abs, `\\`) {
+		// UNC paths take the \\?\UNC\server\share form.
+		return `\\?\UNC\` + abs[2:]
+	}
+	return `\\?\` + abs
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/ghodss/yaml"
+
+	"k8s.io/helm/pkg/proto/hapi/chart"
+)
+
+// PreflightDir is the directory of a chart that holds its pre-flight checks.
+const PreflightDir = "checks"
+
+// PreflightCheck is a requirement on the cluster that a chart is installed
+// into, which Tiller checks before it applies anything.
+//
+// Checks are declared in the YAML files of the checks/ directory of a chart:
+//
+//	checks:
+//	- name: prometheus-operator
+//	  crds:
+//	  - servicemonitors.monitoring.coreos.com
+//	  message: Install the Prometheus Operator before this chart.
+//	- name: cluster-size
+//	  minNodes: 3
+//
+// A check passes if all of its requirements are met.
+type PreflightCheck struct {
+	// Name identifies the check in error messages.
+	Name string `json:"name"`
+	// Message tells the user what to do if the check fails.
+	Message string `json:"message,omitempty"`
+	// APIVersions must all be served by the cluster. An entry is an API
+	// group version such as "batch/v1", or a kind such as
+	// "monitoring.coreos.com/v1/ServiceMonitor".
+	APIVersions []string `json:"apiVersions,omitempty"`
+	// CRDs are the names of CustomResourceDefinitions that must exist, e.g.
+	// "servicemonitors.monitoring.coreos.com".
+	CRDs []string `json:"crds,omitempty"`
+	// MinNodes is the number of nodes the cluster must have at least.
+	MinNodes int `json:"minNodes,omitempty"`
+	// Resources must exist in the cluster.
+	Resources []PreflightResource `json:"resources,omitempty"`
+
+	// File is the file of the chart that the check was declared in.
+	File string `json:"-"`
+}
+
+// PreflightResource identifies a resource that a pre-flight check requires.
+type PreflightResource struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// Namespace is left empty for cluster-scoped resources, and for
+	// resources in the namespace of the release.
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+func (r PreflightResource) String() string {
+	if r.Namespace == "" {
+		return r.Kind + " " + r.Name
+	}
+	return r.Kind + " " + r.Namespace + "/" + r.Name
+}
+
+// preflightFile is the format of a file in the checks/ directory.
+type preflightFile struct {
+	Checks []*PreflightCheck `json:"checks"`
+}
+
+// ParsePreflightChecks parses the pre-flight checks of a file of the checks/
+// directory.
+func ParsePreflightChecks(data []byte) ([]*PreflightCheck, error) {
+	f := &preflightFile{}
+	if err := yaml.Unmarshal(data, f); err != nil {
+		return nil, err
+	}
+	for i, c := range f.Checks {
+		if c == nil {
+			return nil, fmt.Errorf("check %d is empty", i+1)
+		}
+		if err := c.validate(); err != nil {
+			if c.Name == "" {
+				return nil, fmt.Errorf("check %d: %s", i+1, err)
+			}
+			return nil, fmt.Errorf("check %q: %s", c.Name, err)
+		}
+	}
+	return f.Checks, nil
+}
+
+func (c *PreflightCheck) validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("no name")
+	}
+	if c.MinNodes < 0 {
+		return fmt.Errorf("minNodes is negative")
+	}
+	if len(c.APIVersions) == 0 && len(c.CRDs) == 0 && c.MinNodes == 0 && len(c.Resources) == 0 {
+		return fmt.Errorf("no requirements")
+	}
+	for _, r := range c.Resources {
+		if r.APIVersion == "" || r.Kind == "" || r.Name == "" {
+			return fmt.Errorf("resources need an apiVersion, a kind and a name")
+		}
+	}
+	return nil
+}
+
+// LoadPreflightChecks returns the pre-flight checks of a chart and of its
+// subcharts.
+func LoadPreflightChecks(ch *chart.Chart) ([]*PreflightCheck, error) {
+	name := ""
+	if ch.Metadata != nil {
+		name = ch.Metadata.Name
+	}
+	var checks []*PreflightCheck
+	for _, f := range ch.Files {
+		if path.Dir(f.TypeUrl) != PreflightDir || !isYAML(f.TypeUrl) {
+			continue
+		}
+		cs, err := ParsePreflightChecks(f.Value)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse %s of chart %s: %s", f.TypeUrl, name, err)
+		}
+		for _, c := range cs {
+			c.File = path.Join(name, f.TypeUrl)
+		}
+		checks = append(checks, cs...)
+	}
+	for _, dep := range ch.Dependencies {
+		cs, err := LoadPreflightChecks(dep)
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, cs...)
+	}
+	return checks, nil
+}
+
+func isYAML(name string) bool {
+	return strings.HasSuffix(name, ".yaml") || strings.HasSuffix(name, ".yml")
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/any"
+
+	"k8s.io/helm/pkg/proto/hapi/chart"
+)
+
+func TestParsePreflightChecks(t *testing.T) {
+	checks, err := ParsePreflightChecks([]byte(`checks:
+- name: operator
+  crds: [widgets.example.com]
+  message: Install the widget operator first.
+- name: size
+  minNodes: 3
+  resources:
+  - apiVersion: v1
+    kind: ConfigMap
+    namespace: kube-system
+    name: cluster-info
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checks) != 2 {
+		t.Fatalf("expected 2 checks, got %d", len(checks))
+	}
+	if c := checks[0]; c.Name != "operator" || len(c.CRDs) != 1 || c.CRDs[0] != "widgets.example.com" || c.Message == "" {
+		t.Errorf("unexpected check: %+v", c)
+	}
+	if c := checks[1]; c.MinNodes != 3 || len(c.Resources) != 1 || c.Resources[0].String() != "ConfigMap kube-system/cluster-info" {
+		t.Errorf("unexpected check: %+v", c)
+	}
+}
+
+func TestParsePreflightChecksInvalid(t *testing.T) {
+	tests := map[string]string{
+		"checks:\n- crds: [a.example.com]\n":                     "check 1: no name",
+		"checks:\n- name: empty\n":                               `check "empty": no requirements`,
+		"checks:\n- name: neg\n  minNodes: -1\n":                 `check "neg": minNodes is negative`,
+		"checks:\n- name: res\n  resources:\n  - kind: Secret\n": `check "res": resources need an apiVersion, a kind and a name`,
+		"checks:\n- name: [\n":                                   "",
+	}
+	for data, expect := range tests {
+		_, err := ParsePreflightChecks([]byte(data))
+		if err == nil {
+			t.Errorf("expected %q to be rejected", data)
+			continue
+		}
+		if expect != "" && err.Error() != expect {
+			t.Errorf("expected %q, got %q", expect, err)
+		}
+	}
+}
+
+func TestLoadPreflightChecks(t *testing.T) {
+	check := []byte("checks:\n- name: size\n  minNodes: 2\n")
+	ch := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "top"},
+		Files: []*any.Any{
+			{TypeUrl: "checks/size.yaml", Value: check},
+			{TypeUrl: "checks/README.md", Value: []byte("not a check")},
+			{TypeUrl: "files/checks.yaml", Value: []byte("not: a check")},
+		},
+		Dependencies: []*chart.Chart{{
+			Metadata: &chart.Metadata{Name: "sub"},
+			Files:    []*any.Any{{TypeUrl: "checks/size.yml", Value: check}},
+		}},
+	}
+	checks, err := LoadPreflightChecks(ch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checks) != 2 || checks[0].File != "top/checks/size.yaml" || checks[1].File != "sub/checks/size.yml" {
+		t.Errorf("unexpected checks: %+v", checks)
+	}
+}
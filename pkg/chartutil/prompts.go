@@ -0,0 +1,169 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ValuePrompt describes a value to ask the user for before installing a chart.
+//
+// Prompts are declared with comments directly above a key in values.yaml:
+//
+//	# @prompt Password of the admin user
+//	# @required
+//	# @pattern ^.{8,}$
+//	adminPassword: ""
+//
+//	# @prompt Size of the cluster
+//	# @enum small,medium,large
+//	size: small
+//
+// Only keys with a @prompt annotation are asked for. @type may be string
+// (the default), int or bool. The value in values.yaml is the default answer.
+type ValuePrompt struct {
+	// Path is the dotted path of the value, e.g. "db.password".
+	Path     string
+	Prompt   string
+	Required bool
+	Type     string
+	Pattern  string
+	Enum     []string
+	Default  interface{}
+}
+
+// ParseValuePrompts reads the prompt annotations of a values.yaml file.
+func ParseValuePrompts(data []byte) ([]*ValuePrompt, error) {
+	vals, err := ReadValues(data)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := scanValueComments(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var prompts []*ValuePrompt
+	for _, c := range comments {
+		var p *ValuePrompt
+		for _, l := range c.lines {
+			if !strings.HasPrefix(l, "@") {
+				continue
+			}
+			if p == nil {
+				p = &ValuePrompt{}
+			}
+			if err := p.annotate(l); err != nil {
+				return nil, fmt.Errorf("line %d: %s", c.line, err)
+			}
+		}
+		if p == nil {
+			continue
+		}
+		if p.Prompt == "" {
+			return nil, fmt.Errorf("line %d: annotations without @prompt", c.line)
+		}
+		p.Path = c.path
+		p.Default, _ = vals.PathValue(p.Path)
+		prompts = append(prompts, p)
+	}
+	return prompts, nil
+}
+
+func (p *ValuePrompt) annotate(a string) error {
+	parts := strings.SplitN(a, " ", 2)
+	arg := ""
+	if len(parts) == 2 {
+		arg = strings.TrimSpace(parts[1])
+	}
+	switch parts[0] {
+	case "@prompt":
+		if arg == "" {
+			return fmt.Errorf("@prompt needs a text")
+		}
+		p.Prompt = arg
+	case "@required":
+		p.Required = true
+	case "@type":
+		if arg != "string" && arg != "int" && arg != "bool" {
+			return fmt.Errorf("unknown @type %q", arg)
+		}
+		p.Type = arg
+	case "@pattern":
+		if _, err := regexp.Compile(arg); err != nil {
+			return fmt.Errorf("invalid @pattern: %s", err)
+		}
+		p.Pattern = arg
+	case "@enum":
+		for _, e := range strings.Split(arg, ",") {
+			p.Enum = append(p.Enum, strings.TrimSpace(e))
+		}
+	default:
+		return fmt.Errorf("unknown annotation %s", parts[0])
+	}
+	return nil
+}
+
+// Answer validates an answer to the prompt and converts it to the value's
+// type. An empty answer selects the default, if there is one.
+//
+// The second return value is false if the value is to be left unset.
+func (p *ValuePrompt) Answer(answer string) (interface{}, bool, error) {
+	if answer == "" {
+		if p.Default != nil && p.Default != "" {
+			return p.Default, true, nil
+		}
+		if p.Required {
+			return nil, false, fmt.Errorf("a value for %s is required", p.Path)
+		}
+		return nil, false, nil
+	}
+
+	if len(p.Enum) > 0 {
+		found := false
+		for _, e := range p.Enum {
+			found = found || e == answer
+		}
+		if !found {
+			return nil, false, fmt.Errorf("%s must be one of %s", p.Path, strings.Join(p.Enum, ", "))
+		}
+	}
+	if p.Pattern != "" {
+		if !regexp.MustCompile(p.Pattern).MatchString(answer) {
+			return nil, false, fmt.Errorf("%s must match %s", p.Path, p.Pattern)
+		}
+	}
+
+	switch p.Type {
+	case "int":
+		i, err := strconv.ParseInt(answer, 10, 64)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s must be an integer", p.Path)
+		}
+		return i, true, nil
+	case "bool":
+		b, err := strconv.ParseBool(answer)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s must be true or false", p.Path)
+		}
+		return b, true, nil
+	}
+	return answer, true, nil
+}
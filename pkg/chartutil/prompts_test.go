@@ -0,0 +1,142 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"strings"
+	"testing"
+)
+
+const promptValuesYAML = `# Comments without annotations are ignored.
+name: web
+
+# @prompt Password of the admin user
+# @required
+adminPassword: ""
+
+script: |
+  # @prompt not a prompt
+  key: value
+
+db:
+  image: postgres
+  # @prompt Number of replicas
+  # @type int
+  replicas: 3
+  "storage":
+    # @prompt Storage class
+    # @enum standard,fast
+    name: standard
+
+# @prompt Enable metrics
+# @type bool
+metrics:
+`
+
+func TestParseValuePrompts(t *testing.T) {
+	prompts, err := ParseValuePrompts([]byte(promptValuesYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expect := []struct {
+		path, prompt, typ string
+		required          bool
+		def               interface{}
+	}{
+		{"adminPassword", "Password of the admin user", "", true, ""},
+		{"db.replicas", "Number of replicas", "int", false, float64(3)},
+		{"db.storage.name", "Storage class", "", false, "standard"},
+		{"metrics", "Enable metrics", "bool", false, nil},
+	}
+	if len(prompts) != len(expect) {
+		t.Fatalf("expected %d prompts, got %d", len(expect), len(prompts))
+	}
+	for i, e := range expect {
+		p := prompts[i]
+		if p.Path != e.path || p.Prompt != e.prompt || p.Type != e.typ || p.Required != e.required || p.Default != e.def {
+			t.Errorf("prompt %d: expected %+v, got %+v", i, e, p)
+		}
+	}
+	if enum := prompts[2].Enum; len(enum) != 2 || enum[1] != "fast" {
+		t.Errorf("expected the enum choices, got %v", enum)
+	}
+}
+
+func TestParseValuePromptsErrors(t *testing.T) {
+	for _, data := range []string{
+		"# @required\nname: web\n",
+		"# @prompt Name\n# @type float\nname: web\n",
+		"# @prompt Name\n# @pattern [\nname: web\n",
+		"# @prompt\nname: web\n",
+		"# @prompt Name\n# @frobnicate\nname: web\n",
+	} {
+		if _, err := ParseValuePrompts([]byte(data)); err == nil {
+			t.Errorf("expected %q to fail", data)
+		}
+	}
+}
+
+func TestValuePromptAnswer(t *testing.T) {
+	p := &ValuePrompt{Path: "replicas", Type: "int", Default: float64(3)}
+	if v, set, err := p.Answer(""); err != nil || !set || v != float64(3) {
+		t.Errorf("expected the default, got %v %v %v", v, set, err)
+	}
+	if v, _, err := p.Answer("5"); err != nil || v != int64(5) {
+		t.Errorf("expected 5, got %v %v", v, err)
+	}
+	if _, _, err := p.Answer("five"); err == nil || !strings.Contains(err.Error(), "integer") {
+		t.Errorf("expected a non-integer to fail, got %v", err)
+	}
+
+	p = &ValuePrompt{Path: "debug", Type: "bool"}
+	if _, set, err := p.Answer(""); err != nil || set {
+		t.Errorf("expected an optional value without default to be left unset, got %v %v", set, err)
+	}
+	if v, _, err := p.Answer("true"); err != nil || v != true {
+		t.Errorf("expected true, got %v %v", v, err)
+	}
+
+	p = &ValuePrompt{Path: "name", Required: true, Pattern: "^[a-z]+$"}
+	if _, _, err := p.Answer(""); err == nil {
+		t.Error("expected a required value to fail without an answer")
+	}
+	if _, _, err := p.Answer("Web"); err == nil {
+		t.Error("expected an answer not matching the pattern to fail")
+	}
+}
+
+func TestValuesPath(t *testing.T) {
+	v := Values{"db": map[string]interface{}{"name": "pg"}, "port": 80}
+	if val, ok := v.PathValue("db.name"); !ok || val != "pg" {
+		t.Errorf("expected pg, got %v", val)
+	}
+	if _, ok := v.PathValue("port.number"); ok {
+		t.Error("expected a path through a scalar not to be found")
+	}
+	v.SetPathValue("db.auth.user", "admin")
+	v.SetPathValue("port.number", 8080)
+	if val, _ := v.PathValue("db.auth.user"); val != "admin" {
+		t.Errorf("expected admin, got %v", val)
+	}
+	if val, _ := v.PathValue("db.name"); val != "pg" {
+		t.Errorf("expected siblings to be kept, got %v", val)
+	}
+	if val, _ := v.PathValue("port.number"); val != 8080 {
+		t.Errorf("expected the scalar to be replaced by a table, got %v", val)
+	}
+}
@@ -21,8 +21,10 @@ import (
 	"compress/gzip"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
 
 	"github.com/ghodss/yaml"
@@ -35,7 +37,7 @@ var headerBytes = []byte("+aHR0cHM6Ly95b3V0dS5iZS96OVV6MWljandyTQo=")
 // SaveDir saves a chart as files in a directory.
 func SaveDir(c *chart.Chart, dest string) error {
 	// Create the chart directory
-	outdir := filepath.Join(dest, c.Metadata.Name)
+	outdir := longPath(filepath.Join(dest, c.Metadata.Name))
 	if err := os.Mkdir(outdir, 0755); err != nil {
 		return err
 	}
@@ -48,7 +50,7 @@ func SaveDir(c *chart.Chart, dest string) error {
 	// Save values.yaml
 	if c.Values != nil && len(c.Values.Raw) > 0 {
 		vf := filepath.Join(outdir, ValuesfileName)
-		if err := ioutil.WriteFile(vf, []byte(c.Values.Raw), 0755); err != nil {
+		if err := ioutil.WriteFile(vf, []byte(c.Values.Raw), 0644); err != nil {
 			return err
 		}
 	}
@@ -61,16 +63,14 @@ func SaveDir(c *chart.Chart, dest string) error {
 
 	// Save templates
 	for _, f := range c.Templates {
-		n := filepath.Join(outdir, f.Name)
-		if err := ioutil.WriteFile(n, f.Data, 0755); err != nil {
+		if err := writeChartFile(outdir, f.Name, f.Data); err != nil {
 			return err
 		}
 	}
 
 	// Save files
 	for _, f := range c.Files {
-		n := filepath.Join(outdir, f.TypeUrl)
-		if err := ioutil.WriteFile(n, f.Value, 0755); err != nil {
+		if err := writeChartFile(outdir, f.TypeUrl, f.Value); err != nil {
 			return err
 		}
 	}
@@ -95,6 +95,12 @@ func SaveDir(c *chart.Chart, dest string) error {
 //
 // This returns the absolute path to the chart archive file.
 func Save(c *chart.Chart, outDir string) (string, error) {
+	return SaveArchive(c, outDir, FormatTgz)
+}
+
+// SaveArchive is like Save, but writes the archive in the given format, with
+// the extension of that format.
+func SaveArchive(c *chart.Chart, outDir string, format ArchiveFormat) (string, error) {
 	// Create archive
 	if fi, err := os.Stat(outDir); err != nil {
 		return "", err
@@ -112,79 +118,108 @@ func Save(c *chart.Chart, outDir string) (string, error) {
 	} else if cfile.Version == "" {
 		return "", errors.New("no chart version specified (Chart.yaml)")
 	}
+	if _, err := ParseArchiveFormat(string(format)); err != nil {
+		return "", err
+	}
 
-	filename := fmt.Sprintf("%s-%s.tgz", cfile.Name, cfile.Version)
+	filename := fmt.Sprintf("%s-%s%s", cfile.Name, cfile.Version, format.Ext())
 	filename = filepath.Join(outDir, filename)
 	f, err := os.Create(filename)
 	if err != nil {
 		return "", err
 	}
 
+	err = WriteArchive(f, c, format)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(filename)
+	}
+	return filename, err
+}
+
+// WriteArchive writes c to w as a chart archive in the given format.
+func WriteArchive(w io.Writer, c *chart.Chart, format ArchiveFormat) error {
+	if format == FormatTzst {
+		return writeTzst(w, c)
+	}
+
 	// Wrap in gzip writer
-	zipper := gzip.NewWriter(f)
+	zipper := gzip.NewWriter(w)
 	zipper.Header.Extra = headerBytes
 	zipper.Header.Comment = "Helm"
 
 	// Wrap in tar writer
 	twriter := tar.NewWriter(zipper)
-	rollback := false
-	defer func() {
-		twriter.Close()
-		zipper.Close()
-		f.Close()
-		if rollback {
-			os.Remove(filename)
-		}
-	}()
-
 	if err := writeTarContents(twriter, c, ""); err != nil {
-		rollback = true
+		return err
 	}
-	return filename, err
+	if err := twriter.Close(); err != nil {
+		return err
+	}
+	return zipper.Close()
 }
 
 func writeTarContents(out *tar.Writer, c *chart.Chart, prefix string) error {
-	base := filepath.Join(prefix, c.Metadata.Name)
+	files, err := archiveFiles(c, prefix)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if err := writeToTar(out, f.name, f.data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// archiveFiles returns the files of c, and of its dependencies, in the order
+// they are archived, named by their paths in the archive.
+func archiveFiles(c *chart.Chart, prefix string) ([]*afile, error) {
+	// Archive paths always use forward slashes, whatever the OS.
+	base := path.Join(prefix, c.Metadata.Name)
 
 	// Save Chart.yaml
 	cdata, err := yaml.Marshal(c.Metadata)
 	if err != nil {
-		return err
-	}
-	if err := writeToTar(out, base+"/Chart.yaml", cdata); err != nil {
-		return err
+		return nil, err
 	}
+	files := []*afile{{name: base + "/Chart.yaml", data: cdata}}
 
 	// Save values.yaml
 	if c.Values != nil && len(c.Values.Raw) > 0 {
-		if err := writeToTar(out, base+"/values.yaml", []byte(c.Values.Raw)); err != nil {
-			return err
-		}
+		files = append(files, &afile{name: base + "/values.yaml", data: []byte(c.Values.Raw)})
 	}
 
 	// Save templates
 	for _, f := range c.Templates {
-		n := filepath.Join(base, f.Name)
-		if err := writeToTar(out, n, f.Data); err != nil {
-			return err
-		}
+		files = append(files, &afile{name: path.Join(base, filepath.ToSlash(f.Name)), data: f.Data})
 	}
 
 	// Save files
 	for _, f := range c.Files {
-		n := filepath.Join(base, f.TypeUrl)
-		if err := writeToTar(out, n, f.Value); err != nil {
-			return err
-		}
+		files = append(files, &afile{name: path.Join(base, filepath.ToSlash(f.TypeUrl)), data: f.Value})
 	}
 
 	// Save dependencies
 	for _, dep := range c.Dependencies {
-		if err := writeTarContents(out, dep, base+"/charts"); err != nil {
-			return err
+		df, err := archiveFiles(dep, base+"/charts")
+		if err != nil {
+			return nil, err
 		}
+		files = append(files, df...)
 	}
-	return nil
+	return files, nil
+}
+
+// writeChartFile writes the chart file with the slash-separated name into dir.
+func writeChartFile(dir, name string, data []byte) error {
+	n := filepath.Join(dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(n), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(n, data, 0644)
 }
 
 // writeToTar writes a single file to a tar archive.
@@ -192,7 +227,7 @@ func writeToTar(out *tar.Writer, name string, body []byte) error {
 	// TODO: Do we need to create dummy parent directory names if none exist?
 	h := &tar.Header{
 		Name: name,
-		Mode: 0755,
+		Mode: 0644,
 		Size: int64(len(body)),
 	}
 	if err := out.WriteHeader(h); err != nil {
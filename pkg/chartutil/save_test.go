@@ -17,8 +17,13 @@ limitations under the License.
 package chartutil
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -66,6 +71,32 @@ func TestSave(t *testing.T) {
 	}
 }
 
+func TestWriteArchive(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{
+			Name:    "ahab",
+			Version: "1.2.3.4",
+		},
+		Values: &chart.Config{
+			Raw: "ship: Pequod",
+		},
+	}
+
+	for _, format := range []ArchiveFormat{FormatTgz, FormatTzst} {
+		b := bytes.NewBuffer(nil)
+		if err := WriteArchive(b, c, format); err != nil {
+			t.Fatalf("%s: %s", format, err)
+		}
+		c2, err := LoadArchive(b)
+		if err != nil {
+			t.Fatalf("%s: %s", format, err)
+		}
+		if c2.Metadata.Name != c.Metadata.Name || c2.Values.Raw != c.Values.Raw {
+			t.Errorf("%s: expected the chart to be read back, got %v", format, c2)
+		}
+	}
+}
+
 func TestSaveDir(t *testing.T) {
 	tmp, err := ioutil.TempDir("", "helm-")
 	if err != nil {
@@ -99,3 +130,45 @@ func TestSaveDir(t *testing.T) {
 		t.Fatal("Values data did not match")
 	}
 }
+
+func TestSaveSlashes(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "helm-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	c := &chart.Chart{
+		Metadata:  &chart.Metadata{Name: "ahab", Version: "1.2.3"},
+		Templates: []*chart.Template{{Name: filepath.Join("templates", "whale.yaml"), Data: []byte("moby")}},
+	}
+	where, err := Save(c, tmp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(where)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gr)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(h.Name, `\`) {
+			t.Errorf("expected archive paths to use forward slashes, got %q", h.Name)
+		}
+		if h.Mode != 0644 {
+			t.Errorf("expected %s to have mode 0644, got %o", h.Name, h.Mode)
+		}
+	}
+}
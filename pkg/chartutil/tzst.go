@@ -0,0 +1,439 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+
+	"k8s.io/helm/pkg/proto/hapi/chart"
+)
+
+// ArchiveFormat is the file format of a packaged chart.
+type ArchiveFormat string
+
+const (
+	// FormatTgz is a gzip compressed tar archive. Every version of Helm can
+	// read it.
+	FormatTgz ArchiveFormat = "tgz"
+	// FormatTzst is a zstd compressed tar archive with an index of its files.
+	//
+	// It holds the same tar stream as a tgz archive, compressed in a series
+	// of zstd frames that each hold whole files, so that a file can be read
+	// by decompressing only the frame that holds it. The frames together are
+	// a valid zstd stream, so 'zstd -d | tar x' unpacks the archive as well.
+	//
+	// The frames are followed by a skippable frame, which zstd decoders
+	// ignore, holding the index: a zstd compressed, JSON encoded
+	// ArchiveIndex, then its compressed length as a little endian uint32 and
+	// the magic "HIDX". The last 8 bytes of an archive are therefore enough
+	// to find its index.
+	FormatTzst ArchiveFormat = "tzst"
+)
+
+// ParseArchiveFormat returns the archive format with the given name.
+func ParseArchiveFormat(name string) (ArchiveFormat, error) {
+	switch f := ArchiveFormat(name); f {
+	case FormatTgz, FormatTzst:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown chart archive format %q (use %s or %s)", name, FormatTgz, FormatTzst)
+}
+
+// Ext returns the file extension of archives in the format, such as ".tgz".
+func (f ArchiveFormat) Ext() string {
+	return "." + string(f)
+}
+
+// IsArchive reports whether name has the extension of a chart archive.
+func IsArchive(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == FormatTgz.Ext() || ext == FormatTzst.Ext()
+}
+
+// TrimArchiveExt removes the extension of a chart archive from name, if it
+// has one.
+func TrimArchiveExt(name string) string {
+	if IsArchive(name) {
+		return name[:len(name)-len(filepath.Ext(name))]
+	}
+	return name
+}
+
+// ArchiveIndex lists the files of a tzst chart archive.
+type ArchiveIndex struct {
+	Files []ArchiveEntry `json:"files"`
+
+	// end is the offset of the index frame, where the data frames end.
+	end int64
+}
+
+// ArchiveEntry locates one file of a tzst chart archive.
+type ArchiveEntry struct {
+	// Name is the path of the file in the archive, e.g. "mychart/Chart.yaml".
+	Name string `json:"name"`
+	// Offset and Length locate the zstd frame holding the file.
+	Offset int64 `json:"offset"`
+	Length int64 `json:"length"`
+	// Start is where the tar header of the file is in the decompressed frame.
+	Start int64 `json:"start"`
+	// Size is the size of the file itself.
+	Size int64 `json:"size"`
+}
+
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+const (
+	// indexFrameMagic is one of the sixteen magic numbers zstd reserves for
+	// skippable frames.
+	indexFrameMagic = 0x184d2a5e
+	indexMagic      = "HIDX"
+	// maxIndexSize limits the index to read, so that a corrupt archive cannot
+	// make the reader allocate gigabytes.
+	maxIndexSize = 16 << 20
+	// maxFrameMemory limits the size of a decompressed frame in the same
+	// way.
+	maxFrameMemory = 1 << 30
+	// frameSize is about how much tar data is compressed into one frame.
+	// Files share frames, which compress far better than a frame per file
+	// would, and reading any one file decompresses at most one frame of
+	// about this size, or the frame of that file alone if it is larger.
+	frameSize = 1 << 20
+)
+
+// tzstWriter writes a tzst archive, one file at a time.
+type tzstWriter struct {
+	w   io.Writer
+	enc *zstd.Encoder
+	idx ArchiveIndex
+	// offset is where the frame being built will be written.
+	offset int64
+	// frame is the tar data of the frame being built, and pending are the
+	// positions in idx.Files of the files in it.
+	frame   bytes.Buffer
+	pending []int
+}
+
+// writeTzst writes c to w as a tzst archive.
+func writeTzst(w io.Writer, c *chart.Chart) error {
+	files, err := archiveFiles(c, "")
+	if err != nil {
+		return err
+	}
+	enc, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedBestCompression))
+	if err != nil {
+		return err
+	}
+	defer enc.Close()
+
+	// Chart.yaml and values.yaml, which always come first, get a small frame
+	// of their own, since they are what is most often read without the rest.
+	top := c.Metadata.Name + "/"
+	meta := func(name string) bool {
+		return name == top+ChartfileName || name == top+ValuesfileName
+	}
+	tw := &tzstWriter{w: w, enc: enc}
+	for i, f := range files {
+		if err := tw.add(f.name, f.data); err != nil {
+			return err
+		}
+		if meta(f.name) && i+1 < len(files) && !meta(files[i+1].name) {
+			if err := tw.flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return tw.close()
+}
+
+func (t *tzstWriter) add(name string, data []byte) error {
+	var entry bytes.Buffer
+	tw := tar.NewWriter(&entry)
+	if err := writeToTar(tw, name, data); err != nil {
+		return err
+	}
+	// Flush pads the entry, but does not end the tar stream.
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if t.frame.Len() > 0 && t.frame.Len()+entry.Len() > frameSize {
+		if err := t.flush(); err != nil {
+			return err
+		}
+	}
+	t.pending = append(t.pending, len(t.idx.Files))
+	t.idx.Files = append(t.idx.Files, ArchiveEntry{
+		Name:  name,
+		Start: int64(t.frame.Len()),
+		Size:  int64(len(data)),
+	})
+	t.frame.Write(entry.Bytes())
+	if t.frame.Len() >= frameSize {
+		return t.flush()
+	}
+	return nil
+}
+
+// flush compresses the frame being built and writes it out.
+func (t *tzstWriter) flush() error {
+	if t.frame.Len() == 0 {
+		return nil
+	}
+	out := t.enc.EncodeAll(t.frame.Bytes(), nil)
+	if _, err := t.w.Write(out); err != nil {
+		return err
+	}
+	for _, i := range t.pending {
+		t.idx.Files[i].Offset = t.offset
+		t.idx.Files[i].Length = int64(len(out))
+	}
+	t.offset += int64(len(out))
+	t.frame.Reset()
+	t.pending = nil
+	return nil
+}
+
+// close ends the tar stream and writes the index.
+func (t *tzstWriter) close() error {
+	if err := tar.NewWriter(&t.frame).Close(); err != nil {
+		return err
+	}
+	if err := t.flush(); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(t.idx)
+	if err != nil {
+		return err
+	}
+	data = t.enc.EncodeAll(data, nil)
+	frame := make([]byte, 8, 8+len(data)+8)
+	binary.LittleEndian.PutUint32(frame, indexFrameMagic)
+	binary.LittleEndian.PutUint32(frame[4:], uint32(len(data)+8))
+	frame = append(frame, data...)
+	frame = append(frame, 0, 0, 0, 0)
+	binary.LittleEndian.PutUint32(frame[len(frame)-4:], uint32(len(data)))
+	frame = append(frame, indexMagic...)
+	_, err = t.w.Write(frame)
+	return err
+}
+
+// ReadArchiveIndex reads the index of the tzst archive r, which is size bytes
+// long.
+func ReadArchiveIndex(r io.ReaderAt, size int64) (*ArchiveIndex, error) {
+	errCorrupt := errors.New("corrupt tzst archive: index not found")
+	if size < 16 {
+		return nil, errCorrupt
+	}
+	trailer := make([]byte, 8)
+	if _, err := r.ReadAt(trailer, size-8); err != nil {
+		return nil, err
+	}
+	if string(trailer[4:]) != indexMagic {
+		return nil, errCorrupt
+	}
+	n := int64(binary.LittleEndian.Uint32(trailer))
+	if n > maxIndexSize || n > size-16 {
+		return nil, errCorrupt
+	}
+
+	frame := make([]byte, 8+n)
+	start := size - 8 - n - 8
+	if _, err := r.ReadAt(frame, start); err != nil {
+		return nil, err
+	}
+	if binary.LittleEndian.Uint32(frame) != indexFrameMagic || int64(binary.LittleEndian.Uint32(frame[4:])) != n+8 {
+		return nil, errCorrupt
+	}
+	dec, err := newTzstDecoder()
+	if err != nil {
+		return nil, err
+	}
+	defer dec.Close()
+	data, err := dec.DecodeAll(frame[8:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("corrupt tzst archive index: %s", err)
+	}
+	idx := &ArchiveIndex{}
+	if err := json.Unmarshal(data, idx); err != nil {
+		return nil, fmt.Errorf("corrupt tzst archive index: %s", err)
+	}
+	for _, e := range idx.Files {
+		if e.Offset < 0 || e.Length <= 0 || e.Offset+e.Length > start || e.Start < 0 || e.Size < 0 {
+			return nil, fmt.Errorf("corrupt tzst archive index: bad entry for %s", e.Name)
+		}
+	}
+	idx.end = start
+	return idx, nil
+}
+
+func newTzstDecoder() (*zstd.Decoder, error) {
+	return zstd.NewReader(nil, zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxMemory(maxFrameMemory))
+}
+
+// ReadFile reads the file with the given path out of the archive r, which the
+// index belongs to.
+func (idx *ArchiveIndex) ReadFile(r io.ReaderAt, name string) ([]byte, error) {
+	for _, e := range idx.Files {
+		if e.Name == name {
+			fr, err := newFrameReader(r)
+			if err != nil {
+				return nil, err
+			}
+			defer fr.close()
+			return fr.read(e)
+		}
+	}
+	return nil, fmt.Errorf("%s not found in archive", name)
+}
+
+// frameReader reads files out of the frames of a tzst archive, keeping the
+// last frame it decompressed for the files that share it.
+type frameReader struct {
+	r   io.ReaderAt
+	dec *zstd.Decoder
+	off int64
+	raw []byte
+}
+
+func newFrameReader(r io.ReaderAt) (*frameReader, error) {
+	dec, err := newTzstDecoder()
+	if err != nil {
+		return nil, err
+	}
+	return &frameReader{r: r, dec: dec, off: -1}, nil
+}
+
+func (f *frameReader) close() {
+	f.dec.Close()
+}
+
+func (f *frameReader) read(e ArchiveEntry) ([]byte, error) {
+	if e.Offset != f.off {
+		frame := make([]byte, e.Length)
+		if _, err := f.r.ReadAt(frame, e.Offset); err != nil {
+			return nil, err
+		}
+		raw, err := f.dec.DecodeAll(frame, nil)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %s", e.Name, err)
+		}
+		f.off, f.raw = e.Offset, raw
+	}
+	if e.Start >= int64(len(f.raw)) {
+		return nil, fmt.Errorf("corrupt tzst archive: index entry for %s is outside of its frame", e.Name)
+	}
+
+	tr := tar.NewReader(bytes.NewReader(f.raw[e.Start:]))
+	hd, err := tr.Next()
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %s", e.Name, err)
+	}
+	if hd.Name != e.Name || hd.Size != e.Size {
+		return nil, fmt.Errorf("corrupt tzst archive: index entry for %s does not match its frame", e.Name)
+	}
+	// The frame is decompressed already, so it bounds the size of the entry.
+	return readEntry(tr, hd.Size, int64(len(f.raw))-e.Start)
+}
+
+// LoadArchiveFiles loads only the named files of the tzst archive r, which is
+// size bytes long, into a chart. Names are relative to the chart, such as
+// "Chart.yaml" or "values.yaml", and names missing from the archive are
+// skipped.
+//
+// Only the index and the frames holding those files are read, so over a
+// connection that supports range requests most of a large archive is never
+// downloaded. Subcharts are not loaded.
+func LoadArchiveFiles(r io.ReaderAt, size int64, names ...string) (*chart.Chart, error) {
+	idx, err := ReadArchiveIndex(r, size)
+	if err != nil {
+		return nil, err
+	}
+	want := map[string]bool{}
+	for _, n := range names {
+		want[n] = true
+	}
+	fr, err := newFrameReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer fr.close()
+
+	files := []*afile{}
+	for _, e := range idx.Files {
+		parts := archivePath(e.Name)
+		if len(parts) < 2 {
+			continue
+		}
+		n := strings.Join(parts[1:], "/")
+		if !want[n] {
+			continue
+		}
+		data, err := fr.read(e)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, &afile{name: n, data: data})
+	}
+	return loadFiles(files)
+}
+
+// isTzst reports whether r starts with the magic number of a zstd frame.
+func isTzst(r io.ReaderAt) bool {
+	head := make([]byte, len(zstdMagic))
+	if _, err := r.ReadAt(head, 0); err != nil {
+		return false
+	}
+	return bytes.Equal(head, zstdMagic)
+}
+
+// decompress returns a reader of the tar stream in the chart archive in, which
+// may be in either format.
+func decompress(in io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(in)
+	if head, _ := br.Peek(len(zstdMagic)); !bytes.Equal(head, zstdMagic) {
+		return gzip.NewReader(br)
+	}
+
+	// The index must be found to tell where the data frames end.
+	data, err := ioutil.ReadAll(br)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := ReadArchiveIndex(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	dec, err := zstd.NewReader(bytes.NewReader(data[:idx.end]), zstd.WithDecoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	return dec.IOReadCloser(), nil
+}
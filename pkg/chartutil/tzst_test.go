@@ -0,0 +1,149 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/helm/pkg/proto/hapi/chart"
+)
+
+func TestSaveTzst(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "helm-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	c, err := Load("testdata/frobnitz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	where, err := SaveArchive(c, tmp, FormatTzst)
+	if err != nil {
+		t.Fatalf("Failed to save: %s", err)
+	}
+	if filepath.Base(where) != "frobnitz-1.2.3.tzst" {
+		t.Fatalf("Expected frobnitz-1.2.3.tzst, got %s", where)
+	}
+
+	c2, err := Load(where)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifyFrobnitz(t, c2)
+	verifyChart(t, c2)
+	verifyRequirements(t, c2)
+
+	md, err := LoadMetadata(where)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if md.Name != "frobnitz" {
+		t.Errorf("Expected metadata of frobnitz, got %s", md.Name)
+	}
+
+	dest := filepath.Join(tmp, "expanded")
+	f, err := os.Open(where)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := Expand(dest, f); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dest, "frobnitz", "charts", "mariner", "Chart.yaml")); err != nil {
+		t.Error(err)
+	}
+}
+
+// countingReaderAt counts the bytes read from it.
+type countingReaderAt struct {
+	r *bytes.Reader
+	n int64
+}
+
+func (c *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	n, err := c.r.ReadAt(p, off)
+	c.n += int64(n)
+	return n, err
+}
+
+func TestLoadArchiveFiles(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "helm-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+
+	// A template that does not compress well, so that skipping it shows.
+	big := make([]byte, 256<<10)
+	rand.New(rand.NewSource(1)).Read(big)
+	c := &chart.Chart{
+		Metadata:  &chart.Metadata{Name: "ahab", Version: "1.2.3"},
+		Values:    &chart.Config{Raw: "ship: Pequod"},
+		Templates: []*chart.Template{{Name: "templates/whale.yaml", Data: big}},
+	}
+	where, err := SaveArchive(c, tmp, FormatTzst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(where)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := &countingReaderAt{r: bytes.NewReader(data)}
+	c2, err := LoadArchiveFiles(r, int64(len(data)), ChartfileName, ValuesfileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c2.Metadata.Name != "ahab" || c2.Values.Raw != "ship: Pequod" {
+		t.Errorf("Unexpected chart %v", c2)
+	}
+	if len(c2.Templates) != 0 {
+		t.Errorf("Expected no templates, got %d", len(c2.Templates))
+	}
+	if r.n > int64(len(data))/10 {
+		t.Errorf("Expected to read a fraction of the %d bytes, read %d", len(data), r.n)
+	}
+
+	if _, err := LoadArchive(bytes.NewReader(data[:len(data)-4])); err == nil || !strings.Contains(err.Error(), "index") {
+		t.Errorf("Expected a truncated archive to fail, got %v", err)
+	}
+}
+
+func TestParseArchiveFormat(t *testing.T) {
+	if f, err := ParseArchiveFormat("tzst"); err != nil || f != FormatTzst {
+		t.Errorf("Expected tzst, got %q, %v", f, err)
+	}
+	if _, err := ParseArchiveFormat("zip"); err == nil {
+		t.Error("Expected an error for zip")
+	}
+	if !IsArchive("a-1.0.0.tzst") || IsArchive("a-1.0.0.tar") {
+		t.Error("IsArchive does not match the archive extensions")
+	}
+	if n := TrimArchiveExt("a-1.0.0.tzst"); n != "a-1.0.0" {
+		t.Errorf("Expected a-1.0.0, got %s", n)
+	}
+}
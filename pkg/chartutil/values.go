@@ -90,6 +90,51 @@ func (v Values) Encode(w io.Writer) error {
 	return err
 }
 
+// PathValue returns the value at a dotted path, such as "db.password".
+//
+// The second return value is false if there is no value at the path.
+func (v Values) PathValue(path string) (interface{}, bool) {
+	names := strings.Split(path, ".")
+	table := map[string]interface{}(v)
+	for _, n := range names[:len(names)-1] {
+		next, ok := table[n]
+		if !ok {
+			return nil, false
+		}
+		if table, ok = asTable(next); !ok {
+			return nil, false
+		}
+	}
+	val, ok := table[names[len(names)-1]]
+	return val, ok
+}
+
+// SetPathValue sets the value at a dotted path, creating tables along the way
+// and replacing anything in the way that is not a table.
+func (v Values) SetPathValue(path string, val interface{}) {
+	names := strings.Split(path, ".")
+	table := map[string]interface{}(v)
+	for _, n := range names[:len(names)-1] {
+		next, ok := asTable(table[n])
+		if !ok {
+			next = map[string]interface{}{}
+			table[n] = next
+		}
+		table = next
+	}
+	table[names[len(names)-1]] = val
+}
+
+func asTable(v interface{}) (map[string]interface{}, bool) {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		return t, true
+	case Values:
+		return t, true
+	}
+	return nil, false
+}
+
 func tableLookup(v Values, simple string) (Values, error) {
 	v2, ok := v[simple]
 	if !ok {
@@ -128,6 +173,20 @@ func ReadValuesFile(filename string) (Values, error) {
 	return ReadValues(data)
 }
 
+// MergeValues merges src into dst. Values from src take precedence; tables
+// present in both are merged recursively.
+func MergeValues(dst, src map[string]interface{}) {
+	for k, v := range src {
+		if st, ok := v.(map[string]interface{}); ok {
+			if dt, ok := dst[k].(map[string]interface{}); ok {
+				MergeValues(dt, st)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+}
+
 // CoalesceValues coalesces all of the values in a chart (and its subcharts).
 //
 // Values are coalesced together using the following rules:
@@ -253,10 +312,13 @@ func coalesceValues(c *chart.Chart, v map[string]interface{}) (map[string]interf
 	}
 
 	for key, val := range nv {
-		if _, ok := v[key]; !ok {
+		if value, ok := v[key]; !ok {
 			// If the key is not in v, copy it from nv.
 			v[key] = val
-		} else if dest, ok := v[key].(map[string]interface{}); ok {
+		} else if value == nil {
+			// An explicit null removes the default.
+			delete(v, key)
+		} else if dest, ok := value.(map[string]interface{}); ok {
 			// if v[key] is a table, merge nv's val table into v[key].
 			src, ok := val.(map[string]interface{})
 			if !ok {
@@ -278,6 +340,11 @@ func coalesceTables(dst, src map[string]interface{}) map[string]interface{} {
 	// Because dest has higher precedence than src, dest values override src
 	// values.
 	for key, val := range src {
+		if dv, ok := dst[key]; ok && dv == nil {
+			// An explicit null removes the default.
+			delete(dst, key)
+			continue
+		}
 		if istable(val) {
 			if innerdst, ok := dst[key]; !ok {
 				dst[key] = val
@@ -307,7 +374,9 @@ type ReleaseOptions struct {
 }
 
 // ToRenderValues composes the struct from the data coming from the Releases, Charts and Values files
-func ToRenderValues(chrt *chart.Chart, chrtVals *chart.Config, options ReleaseOptions) (Values, error) {
+//
+// caps describes the cluster and Tiller capabilities, exposed as .Capabilities.
+func ToRenderValues(chrt *chart.Chart, chrtVals *chart.Config, options ReleaseOptions, caps *Capabilities) (Values, error) {
 
 	top := map[string]interface{}{
 		"Release": map[string]interface{}{
@@ -316,8 +385,9 @@ func ToRenderValues(chrt *chart.Chart, chrtVals *chart.Config, options ReleaseOp
 			"Namespace": options.Namespace,
 			"Service":   "Tiller",
 		},
-		"Chart": chrt.Metadata,
-		"Files": NewFiles(chrt.Files),
+		"Chart":        chrt.Metadata,
+		"Files":        NewFiles(chrt.Files),
+		"Capabilities": caps,
 	}
 
 	vals, err := CoalesceValues(chrt, chrtVals)
@@ -0,0 +1,269 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SchemaFile is the name of the optional JSON schema of a chart's values.
+const SchemaFile = "values.schema.json"
+
+// ValueDoc documents one value of a chart.
+type ValueDoc struct {
+	// Path is the dotted path of the value, e.g. "db.password".
+	Path        string
+	Type        string
+	Default     interface{}
+	Description string
+}
+
+// DocumentValues lists the values of a chart, sorted by path.
+//
+// Every value in values.yaml that is not a table is listed, with the comment
+// directly above its key (annotation lines starting with "@" left out) as
+// its description, or else the text of its @prompt annotation. If schema, a
+// JSON schema of the values, is not empty, its types and descriptions take
+// precedence, and values that the schema defines but values.yaml leaves out
+// are listed too.
+func DocumentValues(values, schema []byte) ([]*ValueDoc, error) {
+	vals, err := ReadValues(values)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := scanValueComments(values)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := map[string]*ValueDoc{}
+	collectValueDocs(docs, "", vals)
+	for _, c := range comments {
+		d, ok := docs[c.path]
+		if !ok {
+			continue
+		}
+		var text []string
+		prompt := ""
+		for _, l := range c.lines {
+			switch {
+			case strings.HasPrefix(l, "@prompt "):
+				prompt = strings.TrimSpace(strings.TrimPrefix(l, "@prompt "))
+			case l != "" && !strings.HasPrefix(l, "@"):
+				text = append(text, l)
+			}
+		}
+		d.Description = strings.Join(text, " ")
+		if d.Description == "" {
+			d.Description = prompt
+		}
+	}
+
+	if len(bytes.TrimSpace(schema)) > 0 {
+		var s valueSchema
+		if err := json.Unmarshal(schema, &s); err != nil {
+			return nil, err
+		}
+		applySchema(docs, "", &s)
+	}
+
+	res := make([]*ValueDoc, 0, len(docs))
+	for _, d := range docs {
+		res = append(res, d)
+	}
+	sort.Sort(byValuePath(res))
+	return res, nil
+}
+
+type byValuePath []*ValueDoc
+
+func (b byValuePath) Len() int           { return len(b) }
+func (b byValuePath) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byValuePath) Less(i, j int) bool { return b[i].Path < b[j].Path }
+
+func collectValueDocs(docs map[string]*ValueDoc, prefix string, v map[string]interface{}) {
+	for k, val := range v {
+		path := prefix + k
+		if t, ok := asTable(val); ok && len(t) > 0 {
+			collectValueDocs(docs, path+".", t)
+			continue
+		}
+		docs[path] = &ValueDoc{Path: path, Type: valueType(val), Default: val}
+	}
+}
+
+func valueType(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case bool:
+		return "bool"
+	case string:
+		return "string"
+	case float64:
+		if t == math.Trunc(t) {
+			return "int"
+		}
+		return "float"
+	case []interface{}:
+		return "list"
+	case map[string]interface{}, Values:
+		return "map"
+	}
+	return ""
+}
+
+// valueSchema is the part of a JSON schema that documents values.
+type valueSchema struct {
+	Type        interface{}             `json:"type"`
+	Description string                  `json:"description"`
+	Default     interface{}             `json:"default"`
+	Properties  map[string]*valueSchema `json:"properties"`
+}
+
+func applySchema(docs map[string]*ValueDoc, path string, s *valueSchema) {
+	if len(s.Properties) > 0 {
+		for k, p := range s.Properties {
+			if path == "" {
+				applySchema(docs, k, p)
+			} else {
+				applySchema(docs, path+"."+k, p)
+			}
+		}
+		return
+	}
+	if path == "" {
+		return
+	}
+	d, ok := docs[path]
+	if !ok {
+		d = &ValueDoc{Path: path, Default: s.Default}
+		docs[path] = d
+	}
+	if t := schemaType(s.Type); t != "" {
+		d.Type = t
+	}
+	if s.Description != "" {
+		d.Description = s.Description
+	}
+}
+
+// schemaType maps JSON schema types to the names used for values.
+func schemaType(t interface{}) string {
+	var types []string
+	switch tt := t.(type) {
+	case string:
+		types = []string{tt}
+	case []interface{}:
+		for _, e := range tt {
+			if s, ok := e.(string); ok {
+				types = append(types, s)
+			}
+		}
+	}
+	for i, s := range types {
+		switch s {
+		case "integer":
+			types[i] = "int"
+		case "number":
+			types[i] = "float"
+		case "boolean":
+			types[i] = "bool"
+		case "array":
+			types[i] = "list"
+		case "object":
+			types[i] = "map"
+		}
+	}
+	return strings.Join(types, "|")
+}
+
+// valueComment is the comment directly above a key in a values file.
+type valueComment struct {
+	path string
+	// line is the line number of the key.
+	line  int
+	lines []string
+}
+
+var valueKeyLine = regexp.MustCompile(`^(\s*)("[^"]+"|'[^']+'|[^\s#:'"-][^\s:]*):(?:\s+(.*))?$`)
+
+// scanValueComments finds the comments directly above keys in a values file,
+// with the "#" markers stripped. Keys inside lists are not recognized.
+func scanValueComments(data []byte) ([]valueComment, error) {
+	type key struct {
+		indent int
+		name   string
+	}
+	var (
+		res     []valueComment
+		stack   []key
+		pending []string
+		// Lines indented further than a block scalar's key belong to it.
+		block = -1
+	)
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimRight(sc.Text(), " \t\r")
+		trimmed := strings.TrimSpace(line)
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if block >= 0 {
+			if trimmed == "" || indent > block {
+				continue
+			}
+			block = -1
+		}
+
+		switch {
+		case trimmed == "":
+			pending = nil
+			continue
+		case strings.HasPrefix(trimmed, "#"):
+			pending = append(pending, strings.TrimSpace(strings.TrimLeft(trimmed, "#")))
+			continue
+		}
+
+		m := valueKeyLine.FindStringSubmatch(line)
+		if m == nil {
+			pending = nil
+			continue
+		}
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		stack = append(stack, key{indent: indent, name: strings.Trim(m[2], `"'`)})
+		if v := m[3]; strings.HasPrefix(v, "|") || strings.HasPrefix(v, ">") {
+			block = indent
+		}
+
+		if pending != nil {
+			names := make([]string, len(stack))
+			for i, k := range stack {
+				names[i] = k.name
+			}
+			res = append(res, valueComment{path: strings.Join(names, "."), line: n, lines: pending})
+			pending = nil
+		}
+	}
+	return res, sc.Err()
+}
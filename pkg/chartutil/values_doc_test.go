@@ -0,0 +1,88 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"testing"
+)
+
+func TestDocumentValues(t *testing.T) {
+	values := []byte(`## The image to run.
+## ref: https://hub.docker.com/_/postgres
+image: postgres
+
+# @prompt Replicas
+# How many replicas to run.
+replicas: 3
+ratio: 0.5
+tags: [a, b]
+annotations: {}
+
+# Not attached to anything.
+
+db:
+  # The user name.
+  user: admin
+  password:
+`)
+	schema := []byte(`{
+  "properties": {
+    "db": {
+      "properties": {
+        "password": {"type": "string", "description": "The password."},
+        "port": {"type": "integer", "default": 5432}
+      }
+    },
+    "ratio": {"type": "number"}
+  }
+}`)
+
+	docs, err := DocumentValues(values, schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []ValueDoc{
+		{Path: "annotations", Type: "map"},
+		{Path: "db.password", Type: "string", Description: "The password."},
+		{Path: "db.port", Type: "int", Default: float64(5432)},
+		{Path: "db.user", Type: "string", Default: "admin", Description: "The user name."},
+		{Path: "image", Type: "string", Default: "postgres", Description: "The image to run. ref: https://hub.docker.com/_/postgres"},
+		{Path: "ratio", Type: "float", Default: 0.5},
+		{Path: "replicas", Type: "int", Default: float64(3), Description: "How many replicas to run."},
+		{Path: "tags", Type: "list"},
+	}
+	if len(docs) != len(expect) {
+		t.Fatalf("expected %d values, got %d", len(expect), len(docs))
+	}
+	for i, e := range expect {
+		d := docs[i]
+		if d.Path != e.Path || d.Type != e.Type || d.Description != e.Description {
+			t.Errorf("value %d: expected %+v, got %+v", i, e, d)
+		}
+		switch e.Path {
+		case "annotations", "tags":
+			continue
+		}
+		if d.Default != e.Default {
+			t.Errorf("value %s: expected default %v, got %v", e.Path, e.Default, d.Default)
+		}
+	}
+
+	if _, err := DocumentValues(values, []byte("{")); err == nil {
+		t.Error("expected an invalid schema to fail")
+	}
+}
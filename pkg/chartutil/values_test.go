@@ -20,12 +20,16 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"testing"
 	"text/template"
 
 	"github.com/golang/protobuf/ptypes/any"
+	kversion "k8s.io/kubernetes/pkg/version"
+
 	"k8s.io/helm/pkg/proto/hapi/chart"
 	"k8s.io/helm/pkg/timeconv"
+	"k8s.io/helm/pkg/version"
 )
 
 func TestReadValues(t *testing.T) {
@@ -106,7 +110,13 @@ where:
 		Namespace: "al Basrah",
 	}
 
-	res, err := ToRenderValues(c, v, o)
+	caps := &Capabilities{
+		APIVersions:   DefaultVersionSet,
+		TillerVersion: version.GetVersionProto(),
+		KubeVersion:   &kversion.Info{Major: "1"},
+	}
+
+	res, err := ToRenderValues(c, v, o, caps)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -121,6 +131,15 @@ where:
 	if data := res["Files"].(Files)["scheherazade/shahryar.txt"]; string(data) != "1,001 Nights" {
 		t.Errorf("Expected file '1,001 Nights', got %q", string(data))
 	}
+	if !res["Capabilities"].(*Capabilities).APIVersions.Has("v1") {
+		t.Error("Expected Capabilities to have v1 as an API")
+	}
+	if res["Capabilities"].(*Capabilities).TillerVersion.SemVer == "" {
+		t.Error("Expected Capabilities to have a Tiller version")
+	}
+	if res["Capabilities"].(*Capabilities).KubeVersion.Major != "1" {
+		t.Error("Expected Capabilities to have a Kube version")
+	}
 
 	var vals Values
 	vals = res["Values"].(Values)
@@ -260,6 +279,29 @@ pequod:
     scope: whale
 `
 
+func TestMergeValues(t *testing.T) {
+	dst := map[string]interface{}{
+		"name":  "web",
+		"image": map[string]interface{}{"repository": "nginx", "tag": "1.11"},
+		"ports": []interface{}{80},
+	}
+	src := map[string]interface{}{
+		"image": map[string]interface{}{"tag": "1.13"},
+		"ports": []interface{}{8080},
+		"name":  map[string]interface{}{"override": true},
+	}
+	MergeValues(dst, src)
+
+	expect := map[string]interface{}{
+		"name":  map[string]interface{}{"override": true},
+		"image": map[string]interface{}{"repository": "nginx", "tag": "1.13"},
+		"ports": []interface{}{8080},
+	}
+	if !reflect.DeepEqual(dst, expect) {
+		t.Errorf("Expected %v, got %v", expect, dst)
+	}
+}
+
 func TestCoalesceValues(t *testing.T) {
 	tchart := "testdata/moby"
 	c, err := LoadDir(tchart)
@@ -315,6 +357,7 @@ func TestCoalesceTables(t *testing.T) {
 			"friends": []string{"Tashtego"},
 		},
 		"boat": "pequod",
+		"mate": nil,
 	}
 	src := map[string]interface{}{
 		"occupation": "whaler",
@@ -322,6 +365,7 @@ func TestCoalesceTables(t *testing.T) {
 			"state":  "MA",
 			"street": "234 Spouter Inn Ct.",
 		},
+		"mate":    "Starbuck",
 		"details": "empty",
 		"boat": map[string]interface{}{
 			"mast": true,
@@ -365,4 +409,8 @@ func TestCoalesceTables(t *testing.T) {
 	if dst["boat"].(string) != "pequod" {
 		t.Errorf("Expected boat string, got %v", dst["boat"])
 	}
+
+	if mate, ok := dst["mate"]; ok {
+		t.Errorf("Expected a null to remove the mate, got %v", mate)
+	}
 }
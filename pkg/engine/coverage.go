@@ -0,0 +1,214 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"text/template/parse"
+)
+
+// coverageFunc is the template function that instrumented templates call to
+// record that a block was executed.
+const coverageFunc = "__helmCoverage"
+
+// Block kinds reported by Coverage.
+const (
+	BlockTemplate  = "template"
+	BlockDefine    = "define"
+	BlockIf        = "if"
+	BlockElse      = "else"
+	BlockRange     = "range"
+	BlockRangeElse = "range-else"
+	BlockWith      = "with"
+	BlockWithElse  = "with-else"
+)
+
+// CoverageBlock is a part of a template that is executed as a whole: a
+// template file, a named template, or one branch of a conditional.
+type CoverageBlock struct {
+	// Template is the file the block is in.
+	Template string
+	// Kind is the kind of block, e.g. BlockIf or BlockDefine.
+	Kind string
+	// Name is the name of a named template. It is empty for other blocks.
+	Name string
+	// Line is the line of the template the block starts on.
+	Line int
+	// Hits is the number of times the block was executed.
+	Hits int
+}
+
+// Coverage records which blocks of a chart's templates were executed.
+//
+// Set it on an Engine with WithCoverage. A Coverage may be shared by several
+// renders, in which case the hits of all of them are added up.
+type Coverage struct {
+	mu     sync.Mutex
+	blocks map[string]*CoverageBlock
+}
+
+// NewCoverage creates an empty Coverage.
+func NewCoverage() *Coverage {
+	return &Coverage{blocks: map[string]*CoverageBlock{}}
+}
+
+// Blocks returns the blocks seen so far, ordered by template and line.
+func (c *Coverage) Blocks() []CoverageBlock {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	res := make([]CoverageBlock, 0, len(c.blocks))
+	for _, b := range c.blocks {
+		res = append(res, *b)
+	}
+	sort.Sort(byPosition(res))
+	return res
+}
+
+type byPosition []CoverageBlock
+
+func (p byPosition) Len() int      { return len(p) }
+func (p byPosition) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
+func (p byPosition) Less(i, j int) bool {
+	if p[i].Template != p[j].Template {
+		return p[i].Template < p[j].Template
+	}
+	if p[i].Line != p[j].Line {
+		return p[i].Line < p[j].Line
+	}
+	// A file comes before the blocks on its first line.
+	if (p[i].Kind == BlockTemplate) != (p[j].Kind == BlockTemplate) {
+		return p[i].Kind == BlockTemplate
+	}
+	if p[i].Kind != p[j].Kind {
+		return p[i].Kind < p[j].Kind
+	}
+	return p[i].Name < p[j].Name
+}
+
+// hit records an execution of the block with the given id.
+func (c *Coverage) hit(id string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if b, ok := c.blocks[id]; ok {
+		b.Hits++
+	}
+	return ""
+}
+
+// instrument rewrites the parsed templates of t so that every block records
+// its executions in c. sources holds the text of the templates by file name.
+func (c *Coverage) instrument(t *template.Template, sources map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, tpl := range t.Templates() {
+		tree := tpl.Tree
+		if tree == nil || tree.Root == nil {
+			continue
+		}
+		src := sources[tree.ParseName]
+		if tpl.Name() == tree.ParseName {
+			c.mark(tree, tree.Root, &CoverageBlock{Template: tree.ParseName, Kind: BlockTemplate, Line: 1})
+		} else {
+			c.mark(tree, tree.Root, &CoverageBlock{Template: tree.ParseName, Kind: BlockDefine, Name: tpl.Name(), Line: line(src, tree.Root)})
+		}
+		c.walk(tree, src, tree.Root)
+	}
+}
+
+func (c *Coverage) walk(tree *parse.Tree, src string, list *parse.ListNode) {
+	if list == nil {
+		return
+	}
+	for _, n := range list.Nodes {
+		var b *parse.BranchNode
+		var kind, elseKind string
+		switch n := n.(type) {
+		case *parse.IfNode:
+			b, kind, elseKind = &n.BranchNode, BlockIf, BlockElse
+		case *parse.RangeNode:
+			b, kind, elseKind = &n.BranchNode, BlockRange, BlockRangeElse
+		case *parse.WithNode:
+			b, kind, elseKind = &n.BranchNode, BlockWith, BlockWithElse
+		default:
+			continue
+		}
+
+		c.walk(tree, src, b.List)
+		c.walk(tree, src, b.ElseList)
+		c.mark(tree, b.List, &CoverageBlock{Template: tree.ParseName, Kind: kind, Line: line(src, b)})
+		// "else if" is parsed as an else branch holding a single if. The
+		// nested if is measured on its own, so the else is not.
+		if b.ElseList != nil && !isElseIf(b.ElseList) {
+			c.mark(tree, b.ElseList, &CoverageBlock{Template: tree.ParseName, Kind: elseKind, Line: line(src, b.ElseList)})
+		}
+	}
+}
+
+func isElseIf(list *parse.ListNode) bool {
+	if len(list.Nodes) != 1 {
+		return false
+	}
+	switch list.Nodes[0].(type) {
+	case *parse.IfNode, *parse.WithNode:
+		return true
+	}
+	return false
+}
+
+// mark registers a block and makes list record its executions.
+func (c *Coverage) mark(tree *parse.Tree, list *parse.ListNode, b *CoverageBlock) {
+	if list == nil {
+		return
+	}
+	id := b.Template + ":" + strconv.Itoa(b.Line) + ":" + strconv.Itoa(int(list.Position())) + ":" + b.Kind + ":" + b.Name
+	if _, ok := c.blocks[id]; !ok {
+		c.blocks[id] = b
+	}
+
+	call := &parse.ActionNode{
+		NodeType: parse.NodeAction,
+		Pos:      list.Position(),
+		Pipe: &parse.PipeNode{
+			NodeType: parse.NodePipe,
+			Pos:      list.Position(),
+			Cmds: []*parse.CommandNode{{
+				NodeType: parse.NodeCommand,
+				Pos:      list.Position(),
+				Args: []parse.Node{
+					parse.NewIdentifier(coverageFunc).SetTree(tree).SetPos(list.Position()),
+					&parse.StringNode{NodeType: parse.NodeString, Pos: list.Position(), Quoted: strconv.Quote(id), Text: id},
+				},
+			}},
+		},
+	}
+	list.Nodes = append([]parse.Node{call}, list.Nodes...)
+}
+
+// line returns the line of src that n starts on.
+func line(src string, n parse.Node) int {
+	pos := int(n.Position())
+	if pos > len(src) {
+		pos = len(src)
+	}
+	return 1 + strings.Count(src[:pos], "\n")
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"testing"
+
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+)
+
+func TestCoverage(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "moby"},
+		Templates: []*chart.Template{
+			{Name: "templates/_helpers.tpl", Data: []byte(`{{ define "used" }}used{{ end }}
+{{ define "unused" }}unused{{ end }}`)},
+			{Name: "templates/main.yaml", Data: []byte(`{{ include "used" . }}
+{{- if .Values.a }}
+a
+{{- else if .Values.b }}
+b
+{{- else }}
+neither
+{{- end }}
+{{- range .Values.items }}
+{{ . }}
+{{- else }}
+none
+{{- end }}
+{{- with .Values.a }}{{ . }}{{ end }}`)},
+		},
+	}
+	vals := map[string]interface{}{
+		"Values": map[string]interface{}{"b": true, "items": []interface{}{"x", "y"}},
+	}
+
+	cov := NewCoverage()
+	out, err := New().WithCoverage(cov).Render(c, chartutil.Values(vals))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := "used\nb\nx\ny"; out["moby/templates/main.yaml"] != expect {
+		t.Errorf("instrumentation changed the output: expected %q, got %q", expect, out["moby/templates/main.yaml"])
+	}
+
+	type block struct {
+		template, kind, name string
+		line, hits           int
+	}
+	expect := []block{
+		{"moby/templates/_helpers.tpl", BlockTemplate, "", 1, 1},
+		{"moby/templates/_helpers.tpl", BlockDefine, "used", 1, 1},
+		{"moby/templates/_helpers.tpl", BlockDefine, "unused", 2, 0},
+		{"moby/templates/main.yaml", BlockTemplate, "", 1, 1},
+		{"moby/templates/main.yaml", BlockIf, "", 2, 0},
+		{"moby/templates/main.yaml", BlockIf, "", 4, 1},
+		{"moby/templates/main.yaml", BlockElse, "", 6, 0},
+		{"moby/templates/main.yaml", BlockRange, "", 9, 2},
+		{"moby/templates/main.yaml", BlockRangeElse, "", 11, 0},
+		{"moby/templates/main.yaml", BlockWith, "", 14, 0},
+	}
+	got := cov.Blocks()
+	if len(got) != len(expect) {
+		t.Fatalf("expected %d blocks, got %d: %+v", len(expect), len(got), got)
+	}
+	for i, e := range expect {
+		g := got[i]
+		if g.Template != e.template || g.Kind != e.kind || g.Name != e.name || g.Line != e.line || g.Hits != e.hits {
+			t.Errorf("block %d: expected %+v, got %+v", i, e, g)
+		}
+	}
+
+	// Rendering again adds to the same blocks.
+	if _, err := New().WithCoverage(cov).Render(c, chartutil.Values(vals)); err != nil {
+		t.Fatal(err)
+	}
+	if got := cov.Blocks(); len(got) != len(expect) || got[3].Hits != 2 {
+		t.Errorf("expected hits to accumulate, got %+v", got)
+	}
+}
@@ -18,9 +18,15 @@ package engine
 
 import (
 	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
 	"fmt"
 	"path"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
 	"text/template"
 
 	"github.com/Masterminds/sprig"
@@ -30,6 +36,10 @@ import (
 	"k8s.io/helm/pkg/proto/hapi/chart"
 )
 
+// LookupFunc fetches a live object from the cluster by API version, kind,
+// namespace and name. It backs the "lookup" template function.
+type LookupFunc func(apiVersion, kind, namespace, name string) (map[string]interface{}, error)
+
 // Engine is an implementation of 'cmd/tiller/environment'.Engine that uses Go templates.
 type Engine struct {
 	// FuncMap contains the template functions that will be passed to each
@@ -38,8 +48,35 @@ type Engine struct {
 	// If strict is enabled, template rendering will fail if a template references
 	// a value that was not passed in.
 	Strict bool
+	// LookupFunc, if set, is called by the "lookup" template function. If it is
+	// not set, lookup always returns an empty object.
+	LookupFunc LookupFunc
+	// Seed is the secret from which "stableRandAlphaNum" derives its values.
+	// If it is empty, a new random seed is used for every call to Render.
+	Seed []byte
+	// Coverage, if set, records which templates, named templates and
+	// conditional branches are executed.
+	Coverage *Coverage
+	// Parallelism is the number of templates rendered at once. If it is zero,
+	// one template is rendered per CPU.
+	Parallelism int
+	// MaxIncludeDepth is the deepest that calls of "include" may nest. If it
+	// is zero, DefaultMaxIncludeDepth is used.
+	MaxIncludeDepth int
+	// MaxFileTemplateSize is the largest output, in bytes, that a file
+	// template may render to. If it is zero, DefaultMaxFileTemplateSize is
+	// used.
+	MaxFileTemplateSize int
 }
 
+// DefaultMaxIncludeDepth is the include depth limit of an Engine that does not
+// set one.
+const DefaultMaxIncludeDepth = 1000
+
+// DefaultMaxFileTemplateSize is the file template size limit of an Engine that
+// does not set one. It is the most data that a ConfigMap or Secret can hold.
+const DefaultMaxFileTemplateSize = 1 << 20
+
 // New creates a new Go template Engine instance.
 //
 // The FuncMap is initialized here. You may modify the FuncMap _prior to_ the
@@ -54,6 +91,30 @@ func New() *Engine {
 	}
 }
 
+// WithLookup returns a copy of the engine whose "lookup" template function
+// queries the cluster through fn.
+func (e *Engine) WithLookup(fn LookupFunc) *Engine {
+	c := *e
+	c.LookupFunc = fn
+	return &c
+}
+
+// WithSeed returns a copy of the engine whose stable random functions derive
+// their values from seed.
+func (e *Engine) WithSeed(seed []byte) *Engine {
+	c := *e
+	c.Seed = seed
+	return &c
+}
+
+// WithCoverage returns a copy of the engine that records the blocks of the
+// templates that it executes in cov.
+func (e *Engine) WithCoverage(cov *Coverage) *Engine {
+	c := *e
+	c.Coverage = cov
+	return &c
+}
+
 // FuncMap returns a mapping of all of the functions that Engine has.
 //
 // Because some functions are late-bound (e.g. contain context-sensitive
@@ -64,6 +125,12 @@ func New() *Engine {
 //
 //	- "include": This is late-bound in Engine.Render(). The version
 //	   included in the FuncMap is a placeholder.
+//	- "tpl": This is late-bound in Engine.Render(). The version
+//	   included in the FuncMap is a placeholder.
+//	- "lookup": This is late-bound in Engine.Render(). The version
+//	   included in the FuncMap always returns an empty object.
+//	- "stableRandAlphaNum": This is late-bound in Engine.Render(). The
+//	   version included in the FuncMap uses a throwaway seed.
 func FuncMap() template.FuncMap {
 	f := sprig.TxtFuncMap()
 	delete(f, "env")
@@ -75,11 +142,62 @@ func FuncMap() template.FuncMap {
 	// This is a placeholder for the "include" function, which is
 	// late-bound to a template. By declaring it here, we preserve the
 	// integrity of the linter.
-	f["include"] = func(string, interface{}) string { return "not implemented" }
+	f["include"] = func(string, interface{}) (string, error) { return "not implemented", nil }
+
+	// This is a placeholder for the "tpl" function, which is late-bound to a
+	// template for the same reason as "include".
+	f["tpl"] = func(string, interface{}) (string, error) { return "not implemented", nil }
+
+	// This is a placeholder for the "lookup" function, which is late-bound to
+	// the cluster the chart is being installed into.
+	f["lookup"] = emptyLookup
+
+	// This is a placeholder for the "stableRandAlphaNum" function, which is
+	// late-bound to the seed of the release being rendered.
+	f["stableRandAlphaNum"] = stableRandAlphaNum(nil)
 
 	return f
 }
 
+func emptyLookup(apiVersion, kind, namespace, name string) (map[string]interface{}, error) {
+	return map[string]interface{}{}, nil
+}
+
+const alphaNum = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// stableRandAlphaNum returns a template function that generates a random
+// alphanumeric string of the given length for a key.
+//
+// The string is derived from seed and key with HMAC-SHA256, so the same seed
+// and key always produce the same string. If seed is empty, a random one is
+// generated.
+func stableRandAlphaNum(seed []byte) func(string, int) (string, error) {
+	if len(seed) == 0 {
+		seed = make([]byte, 32)
+		if _, err := rand.Read(seed); err != nil {
+			return func(string, int) (string, error) { return "", err }
+		}
+	}
+	return func(key string, length int) (string, error) {
+		out := make([]byte, 0, length)
+		for block := 0; len(out) < length; block++ {
+			mac := hmac.New(sha256.New, seed)
+			fmt.Fprintf(mac, "%d\x00%s", block, key)
+			for _, b := range mac.Sum(nil) {
+				// Discard the bytes that would bias the modulo.
+				if b >= 248 {
+					continue
+				}
+				out = append(out, alphaNum[int(b)%len(alphaNum)])
+				if len(out) == length {
+					break
+				}
+			}
+		}
+		return string(out), nil
+	}
+}
+
 func toYaml(v interface{}) string {
 	data, err := yaml.Marshal(v)
 	if err != nil {
@@ -96,6 +214,11 @@ func toYaml(v interface{}) string {
 // This will look in the chart's 'templates' data (e.g. the 'templates/' directory)
 // and attempt to render the templates there using the values passed in.
 //
+// File templates (see chartutil.IsFileTemplate) are rendered first, with the
+// same values and named templates as the templates of their chart, so that
+// the templates can embed their output with ".Files.GetTemplate". They are
+// not part of the returned map.
+//
 // Values are scoped to their templates. A dependency template will not have
 // access to the values set for its parent. If chart "foo" includes chart "bar",
 // "bar" will not have access to the values for "foo".
@@ -120,6 +243,11 @@ type renderable struct {
 	tpl string
 	// vals are the values to be supplied to the template.
 	vals chartutil.Values
+	// file is the chart file name of a file template, e.g.
+	// "files/nginx.conf.tpl", and empty for other templates.
+	file string
+	// files are the chart files that a file template's output is stored in.
+	files chartutil.Files
 }
 
 // alterFuncMap takes the Engine's FuncMap and adds context-specific functions.
@@ -132,13 +260,22 @@ func (e *Engine) alterFuncMap(t *template.Template) template.FuncMap {
 		funcMap[k] = v
 	}
 
-	// Add the 'include' function here so we can close over t.
-	funcMap["include"] = func(name string, data interface{}) string {
-		buf := bytes.NewBuffer(nil)
-		if err := t.ExecuteTemplate(buf, name, data); err != nil {
-			buf.WriteString(err.Error())
-		}
-		return buf.String()
+	// Add the 'include' and 'tpl' functions here so we can close over t.
+	in := e.newIncluder(t)
+	funcMap["include"] = in.include
+	funcMap["tpl"] = in.tpl
+
+	// Add the 'lookup' function here so that it uses the engine's cluster
+	// connection, if there is one.
+	funcMap["lookup"] = emptyLookup
+	if e.LookupFunc != nil {
+		funcMap["lookup"] = e.LookupFunc
+	}
+
+	funcMap["stableRandAlphaNum"] = stableRandAlphaNum(e.Seed)
+
+	if e.Coverage != nil {
+		funcMap[coverageFunc] = e.Coverage.hit
 	}
 
 	return funcMap
@@ -173,26 +310,166 @@ func (e *Engine) render(tpls map[string]renderable) (map[string]string, error) {
 		files = append(files, fname)
 	}
 
-	rendered := make(map[string]string, len(files))
-	var buf bytes.Buffer
+	if e.Coverage != nil {
+		sources := make(map[string]string, len(tpls))
+		for fname, r := range tpls {
+			sources[fname] = r.tpl
+		}
+		e.Coverage.instrument(t, sources)
+	}
+
+	// Render in a stable order, so that the error reported for a chart with
+	// several broken templates is always the same one. File templates go
+	// first, because templates embed their output.
+	sort.Strings(files)
+	fileTpls, manifests := []string{}, []string{}
 	for _, file := range files {
-		// At render time, add information about the template that is being rendered.
-		vals := tpls[file].vals
-		vals["Template"] = map[string]interface{}{"Name": file}
-		if err := t.ExecuteTemplate(&buf, file, vals); err != nil {
-			return map[string]string{}, fmt.Errorf("render error in %q: %s", file, err)
+		if tpls[file].file != "" {
+			fileTpls = append(fileTpls, file)
+		} else {
+			manifests = append(manifests, file)
 		}
+	}
 
-		// Work around the issue where Go will emit "<no value>" even if Options(missing=zero)
-		// is set. Since missing=error will never get here, we do not need to handle
-		// the Strict case.
-		rendered[file] = strings.Replace(buf.String(), "<no value>", "", -1)
-		buf.Reset()
+	if len(fileTpls) > 0 {
+		out, err := e.execute(t, fileTpls, tpls)
+		if err != nil {
+			return map[string]string{}, err
+		}
+		max := e.MaxFileTemplateSize
+		if max <= 0 {
+			max = DefaultMaxFileTemplateSize
+		}
+		for n, file := range fileTpls {
+			r := tpls[file]
+			if len(out[n]) > max {
+				return map[string]string{}, fmt.Errorf("file template %q renders to %d bytes, more than the limit of %d", file, len(out[n]), max)
+			}
+			name := strings.TrimSuffix(r.file, chartutil.FileTemplateExt)
+			if _, ok := r.files[name]; ok {
+				return map[string]string{}, fmt.Errorf("file template %q would replace the chart file %q", file, name)
+			}
+			r.files[name] = []byte(out[n])
+		}
 	}
 
+	out, err := e.execute(t, manifests, tpls)
+	if err != nil {
+		return map[string]string{}, err
+	}
+	rendered := make(map[string]string, len(manifests))
+	for n, file := range manifests {
+		rendered[file] = out[n]
+	}
 	return rendered, nil
 }
 
+// execute renders the named templates of t, in parallel if the engine allows
+// it, and returns their output in the same order.
+func (e *Engine) execute(t *template.Template, files []string, tpls map[string]renderable) ([]string, error) {
+	workers := e.Parallelism
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(files) {
+		workers = len(files)
+	}
+
+	out := make([]string, len(files))
+	errs := make([]error, len(files))
+	// Templates can modify their values (with a function of the engine's
+	// FuncMap, for example), so every file is rendered with its own copy of
+	// its values: no file sees the changes of another, whatever the order they
+	// are rendered in.
+	if workers <= 1 {
+		for n, file := range files {
+			out[n], errs[n] = executeFile(t, file, copyMap(tpls[file].vals))
+		}
+	} else {
+		// Every worker executes its own clone of the templates, so that each
+		// has an "include" that follows only the worker's own execution.
+		clones := make([]*template.Template, workers)
+		for w := range clones {
+			c, err := t.Clone()
+			if err != nil {
+				return nil, err
+			}
+			in := e.newIncluder(c)
+			clones[w] = c.Funcs(template.FuncMap{"include": in.include, "tpl": in.tpl})
+		}
+
+		next := make(chan int)
+		var wg sync.WaitGroup
+		for _, c := range clones {
+			wg.Add(1)
+			go func(t *template.Template) {
+				defer wg.Done()
+				for n := range next {
+					out[n], errs[n] = executeFile(t, files[n], copyMap(tpls[files[n]].vals))
+				}
+			}(c)
+		}
+		for n := range files {
+			next <- n
+		}
+		close(next)
+		wg.Wait()
+	}
+
+	for n, file := range files {
+		if errs[n] != nil {
+			return nil, newRenderError(file, errs[n], tpls)
+		}
+	}
+	return out, nil
+}
+
+// executeFile renders a single template file with vals.
+//
+// Neither t nor vals may be shared with another execution that runs at the
+// same time. Errors are returned as text/template reports them; render turns
+// them into a RenderError.
+func executeFile(t *template.Template, file string, vals chartutil.Values) (string, error) {
+	// At render time, add information about the template that is being rendered.
+	vals["Template"] = map[string]interface{}{"Name": file}
+	var buf bytes.Buffer
+	if err := t.ExecuteTemplate(&buf, file, vals); err != nil {
+		return "", err
+	}
+
+	// Work around the issue where Go will emit "<no value>" even if Options(missing=zero)
+	// is set. Since missing=error will never get here, we do not need to handle
+	// the Strict case.
+	return strings.Replace(buf.String(), "<no value>", "", -1), nil
+}
+
+// copyMap returns a deep copy of the maps and lists in m. Other values, such
+// as the chart metadata, are shared with m.
+func copyMap(m map[string]interface{}) chartutil.Values {
+	res := make(chartutil.Values, len(m))
+	for k, v := range m {
+		res[k] = copyValue(v)
+	}
+	return res
+}
+
+func copyValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case chartutil.Values:
+		return copyMap(v)
+	case map[string]interface{}:
+		return map[string]interface{}(copyMap(v))
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for n, e := range v {
+			res[n] = copyValue(e)
+		}
+		return res
+	default:
+		return v
+	}
+}
+
 // allTemplates returns all templates for a chart and its dependencies.
 //
 // As it goes, it also prepares the values in a scope-sensitive manner.
@@ -214,6 +491,15 @@ func recAllTpls(c *chart.Chart, templates map[string]renderable, parentVals char
 		// If this is the top of the rendering tree, assume that parentVals
 		// is already resolved to the authoritative values.
 		cvals = parentVals
+		if hasFileTemplates(c) {
+			// The output of file templates is added to the files, so give
+			// the chart its own instead of changing the caller's.
+			cvals = chartutil.Values{}
+			for k, v := range parentVals {
+				cvals[k] = v
+			}
+			cvals["Files"] = chartutil.NewFiles(c.Files)
+		}
 	} else if c.Metadata != nil && c.Metadata.Name != "" {
 		// If there is a {{.Values.ThisChart}} in the parent metadata,
 		// copy that into the {{.Values}} for this template.
@@ -225,10 +511,11 @@ func recAllTpls(c *chart.Chart, templates map[string]renderable, parentVals char
 		}
 
 		cvals = map[string]interface{}{
-			"Values":  newVals,
-			"Release": parentVals["Release"],
-			"Chart":   c.Metadata,
-			"Files":   chartutil.NewFiles(c.Files),
+			"Values":       newVals,
+			"Release":      parentVals["Release"],
+			"Chart":        c.Metadata,
+			"Files":        chartutil.NewFiles(c.Files),
+			"Capabilities": parentVals["Capabilities"],
 		}
 	}
 
@@ -249,4 +536,28 @@ func recAllTpls(c *chart.Chart, templates map[string]renderable, parentVals char
 			vals: cvals,
 		}
 	}
+	for _, f := range c.Files {
+		if !chartutil.IsFileTemplate(f.TypeUrl) {
+			continue
+		}
+		files, ok := cvals["Files"].(chartutil.Files)
+		if !ok {
+			continue
+		}
+		templates[path.Join(newParentID, f.TypeUrl)] = renderable{
+			tpl:   string(f.Value),
+			vals:  cvals,
+			file:  f.TypeUrl,
+			files: files,
+		}
+	}
+}
+
+func hasFileTemplates(c *chart.Chart) bool {
+	for _, f := range c.Files {
+		if chartutil.IsFileTemplate(f.TypeUrl) {
+			return true
+		}
+	}
+	return false
 }
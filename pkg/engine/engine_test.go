@@ -17,7 +17,9 @@ limitations under the License.
 package engine
 
 import (
+	"encoding/base64"
 	"fmt"
+	"strings"
 	"sync"
 	"testing"
 
@@ -182,6 +184,80 @@ func TestParallelRenderInternals(t *testing.T) {
 	wg.Wait()
 }
 
+func TestRenderConcurrentTemplates(t *testing.T) {
+	vals := chartutil.Values{"Values": map[string]interface{}{"list": []interface{}{"a", "b"}}}
+	other := chartutil.Values{"Values": map[string]interface{}{"list": []interface{}{"c"}}}
+	tpls := map[string]renderable{}
+	for i := 0; i < 200; i++ {
+		v := vals
+		if i%2 == 1 {
+			v = other
+		}
+		name := fmt.Sprintf("chart/templates/t%03d.yaml", i)
+		tpls[name] = renderable{tpl: `{{.Template.Name}}:{{range .Values.list}}{{.}}{{end}}`, vals: v}
+	}
+
+	e := New()
+	e.Parallelism = 8
+	out, err := e.render(tpls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != len(tpls) {
+		t.Fatalf("expected %d templates, got %d", len(tpls), len(out))
+	}
+	for i := 0; i < 200; i++ {
+		name := fmt.Sprintf("chart/templates/t%03d.yaml", i)
+		expect := name + ":ab"
+		if i%2 == 1 {
+			expect = name + ":c"
+		}
+		if out[name] != expect {
+			t.Errorf("expected %q, got %q", expect, out[name])
+		}
+	}
+
+	// With several broken templates, the first one in file order is reported.
+	tpls["chart/templates/t010.yaml"] = renderable{tpl: `{{index .Values.list 9}}`, vals: vals}
+	tpls["chart/templates/t150.yaml"] = renderable{tpl: `{{index .Values.list 9}}`, vals: vals}
+	for i := 0; i < 5; i++ {
+		_, err := e.render(tpls)
+		if err == nil || !strings.Contains(err.Error(), "t010.yaml") {
+			t.Fatalf("expected the error in t010.yaml, got %v", err)
+		}
+	}
+}
+
+func TestRenderMutatingTemplates(t *testing.T) {
+	vals := chartutil.Values{"Values": chartutil.Values{"seen": ""}}
+	tpls := map[string]renderable{}
+	for i := 0; i < 50; i++ {
+		name := fmt.Sprintf("chart/templates/t%03d.yaml", i)
+		tpls[name] = renderable{tpl: `{{ set .Values "seen" (printf "%s%s" .Values.seen "x") }}{{ .Values.seen }}`, vals: vals}
+	}
+
+	for _, parallelism := range []int{1, 8} {
+		e := New()
+		e.Parallelism = parallelism
+		e.FuncMap["set"] = func(m map[string]interface{}, k string, v interface{}) string {
+			m[k] = v
+			return ""
+		}
+		out, err := e.render(tpls)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for name, got := range out {
+			if got != "x" {
+				t.Errorf("parallelism %d: expected %s to see only its own change, got %q", parallelism, name, got)
+			}
+		}
+	}
+	if got := vals["Values"].(chartutil.Values)["seen"]; got != "" {
+		t.Errorf("expected the values of the caller to be left alone, got %q", got)
+	}
+}
+
 func TestAllTemplates(t *testing.T) {
 	ch1 := &chart.Chart{
 		Metadata: &chart.Metadata{Name: "ch1"},
@@ -249,6 +325,99 @@ func TestRenderDependency(t *testing.T) {
 
 }
 
+func TestRenderFileTemplates(t *testing.T) {
+	files := func(kv ...string) []*any.Any {
+		fs := []*any.Any{}
+		for i := 0; i < len(kv); i += 2 {
+			fs = append(fs, &any.Any{TypeUrl: kv[i], Value: []byte(kv[i+1])})
+		}
+		return fs
+	}
+	ch := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "web"},
+		Templates: []*chart.Template{
+			{Name: "templates/_helpers.tpl", Data: []byte(`{{ define "web.port" }}{{ .Values.port }}{{ end }}`)},
+			{Name: "templates/configmap.yaml", Data: []byte(`{{ .Files.GetTemplate "files/nginx.conf" }}`)},
+		},
+		Files: files(
+			"files/nginx.conf.tpl", `listen {{ include "web.port" . }};`,
+			"files/raw.conf", `listen {{ .Values.port }};`,
+		),
+		Dependencies: []*chart.Chart{
+			{
+				Metadata: &chart.Metadata{Name: "rules"},
+				Templates: []*chart.Template{
+					{Name: "templates/rules.yaml", Data: []byte(`{{ .Files.Get "files/rules.yml" }}`)},
+				},
+				Files: files("files/rules.yml.tpl", `for: {{ .Values.for }}`),
+			},
+		},
+	}
+	vals := chartutil.Values{
+		"Values": chartutil.Values{"port": 80, "rules": chartutil.Values{"for": "5m"}},
+		"Files":  chartutil.NewFiles(ch.Files),
+	}
+
+	for _, parallelism := range []int{1, 4} {
+		e := New()
+		e.Parallelism = parallelism
+		out, err := e.Render(ch, vals)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, expect := out["web/templates/configmap.yaml"], "listen 80;"; got != expect {
+			t.Errorf("Expected %q, got %q", expect, got)
+		}
+		if got, expect := out["web/charts/rules/templates/rules.yaml"], "for: 5m"; got != expect {
+			t.Errorf("Expected %q, got %q", expect, got)
+		}
+		if _, ok := out["web/files/nginx.conf.tpl"]; ok {
+			t.Error("Expected file templates to be left out of the rendered manifests")
+		}
+	}
+	if _, ok := vals["Files"].(chartutil.Files)["files/nginx.conf"]; ok {
+		t.Error("Expected the files of the caller to be left alone")
+	}
+
+	e := New()
+	e.MaxFileTemplateSize = 8
+	_, err := e.Render(ch, vals)
+	if err == nil || err.Error() != `file template "web/files/nginx.conf.tpl" renders to 10 bytes, more than the limit of 8` {
+		t.Errorf("Expected the size limit to be enforced, got %v", err)
+	}
+
+	ch.Files = append(ch.Files, files("files/nginx.conf", "stale")...)
+	_, err = New().Render(ch, vals)
+	if err == nil || !strings.Contains(err.Error(), `would replace the chart file "files/nginx.conf"`) {
+		t.Errorf("Expected a conflicting chart file to fail rendering, got %v", err)
+	}
+}
+
+func TestRenderBinaryFiles(t *testing.T) {
+	keystore := []byte{0xfe, 0xed, 0xfe, 0xed, 0x00, 0x00, 0x00, 0x02, 0xff, 0x0a, 0x0d}
+	ch := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "java"},
+		Templates: []*chart.Template{
+			{Name: "templates/get.yaml", Data: []byte(`{{ .Files.Get "keystore.jks" | b64enc }}`)},
+			{Name: "templates/secrets.yaml", Data: []byte(`{{ (.Files.Glob "*.jks").AsSecrets }}`)},
+		},
+		Files: []*any.Any{{TypeUrl: "keystore.jks", Value: keystore}},
+	}
+	vals := chartutil.Values{"Files": chartutil.NewFiles(ch.Files)}
+
+	out, err := New().Render(ch, vals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := base64.StdEncoding.EncodeToString(keystore)
+	if got := out["java/templates/get.yaml"]; got != expect {
+		t.Errorf("Expected %q, got %q", expect, got)
+	}
+	if got := out["java/templates/secrets.yaml"]; got != "keystore.jks: "+expect+"\n" {
+		t.Errorf("Expected %q, got %q", "keystore.jks: "+expect+"\n", got)
+	}
+}
+
 func TestRenderNestedValues(t *testing.T) {
 	e := New()
 
@@ -423,3 +592,82 @@ func TestAlterFuncMap(t *testing.T) {
 		t.Errorf("Expected %q, got %q (%v)", expect, got, out)
 	}
 }
+
+func TestLookup(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "vault"},
+		Templates: []*chart.Template{
+			{Name: "templates/secret", Data: []byte(`{{$s := lookup "v1" "Secret" "ns" "pw"}}{{if $s}}{{$s.data.password}}{{else}}generated{{end}}`)},
+		},
+		Values: &chart.Config{Raw: ``},
+	}
+	v := chartutil.Values{"Values": &chart.Config{Raw: ""}, "Chart": c.Metadata}
+
+	out, err := New().Render(c, v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := out["vault/templates/secret"]; got != "generated" {
+		t.Errorf("Expected lookup to find nothing without a cluster, got %q", got)
+	}
+
+	var args []string
+	e := New().WithLookup(func(apiVersion, kind, namespace, name string) (map[string]interface{}, error) {
+		args = []string{apiVersion, kind, namespace, name}
+		return map[string]interface{}{"data": map[string]interface{}{"password": "c2VjcmV0"}}, nil
+	})
+	out, err = e.Render(c, v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := out["vault/templates/secret"]; got != "c2VjcmV0" {
+		t.Errorf("Expected existing password, got %q", got)
+	}
+	if fmt.Sprint(args) != "[v1 Secret ns pw]" {
+		t.Errorf("Unexpected lookup arguments %v", args)
+	}
+
+	e = New().WithLookup(func(apiVersion, kind, namespace, name string) (map[string]interface{}, error) {
+		return nil, fmt.Errorf("forbidden")
+	})
+	if _, err := e.Render(c, v); err == nil {
+		t.Error("Expected lookup errors to fail rendering")
+	}
+}
+
+func TestStableRandAlphaNum(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "vault"},
+		Templates: []*chart.Template{
+			{Name: "templates/secret", Data: []byte(`{{stableRandAlphaNum "password" 24}} {{stableRandAlphaNum "other" 24}}`)},
+		},
+		Values: &chart.Config{Raw: ``},
+	}
+	v := chartutil.Values{"Values": &chart.Config{Raw: ""}, "Chart": c.Metadata}
+
+	render := func(e *Engine) string {
+		out, err := e.Render(c, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return out["vault/templates/secret"]
+	}
+
+	seed := []byte("0123456789abcdef0123456789abcdef")
+	first := render(New().WithSeed(seed))
+	if len(first) != 49 {
+		t.Fatalf("Expected two 24 character strings, got %q", first)
+	}
+	if first[:24] == first[25:] {
+		t.Errorf("Expected different values for different keys, got %q", first)
+	}
+	if again := render(New().WithSeed(seed)); again != first {
+		t.Errorf("Expected %q for the same seed, got %q", first, again)
+	}
+	if other := render(New().WithSeed([]byte("another seed"))); other == first {
+		t.Errorf("Expected a different value for a different seed")
+	}
+	if unseeded := render(New()); unseeded == first {
+		t.Errorf("Expected a random value without a seed")
+	}
+}
@@ -0,0 +1,165 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// sourceContext is the number of lines shown on either side of the failing
+// line in RenderError.Source.
+const sourceContext = 2
+
+// shownIncludes is the number of include calls that RenderError.Error shows
+// from either end of a long chain.
+const shownIncludes = 5
+
+// execErrorLocation matches the location that text/template puts in front of
+// an execution error. A failure inside of an "include" has one location for
+// every template on the way down to it, outermost first.
+var execErrorLocation = regexp.MustCompile(`template: (.+?):(\d+):(\d+): executing "(.+?)" at <(.*?)>: (?:error calling include: )?`)
+
+// IncludeFrame is a call of a named template with "include".
+type IncludeFrame struct {
+	// Name is the named template that was included.
+	Name string
+	// File, Line and Column locate the include call.
+	File   string
+	Line   int
+	Column int
+}
+
+// RenderError is a failure to execute a chart template.
+type RenderError struct {
+	// Template is the template file that was being rendered, including the
+	// path of its chart, e.g. "mychart/charts/sub/templates/service.yaml".
+	Template string
+	// File, Line and Column locate the action that failed. File differs from
+	// Template when the action is in a named template defined in another file.
+	File   string
+	Line   int
+	Column int
+	// Action is the action that failed, e.g. ".Values.image.tag".
+	Action string
+	// Includes are the include calls that led to the failing action,
+	// outermost first.
+	Includes []IncludeFrame
+	// Message is the underlying error.
+	Message string
+	// Source is an excerpt of File around the failing line. It is empty if
+	// the location of the failure is not known.
+	Source string
+}
+
+func (e *RenderError) Error() string {
+	b := bytes.NewBufferString(fmt.Sprintf("render error in %q: ", e.Template))
+	if e.File != "" {
+		fmt.Fprintf(b, "%s:%d:%d: ", e.File, e.Line, e.Column)
+	}
+	if e.Action != "" {
+		fmt.Fprintf(b, "at <%s>: ", e.Action)
+	}
+	b.WriteString(e.Message)
+	for i := len(e.Includes) - 1; i >= 0; i-- {
+		if i == len(e.Includes)-shownIncludes-1 && i >= shownIncludes {
+			fmt.Fprintf(b, " (... %d more includes)", i-shownIncludes+1)
+			i = shownIncludes - 1
+		}
+		in := e.Includes[i]
+		fmt.Fprintf(b, " (in %q included from %s:%d:%d)", in.Name, in.File, in.Line, in.Column)
+	}
+	return b.String()
+}
+
+// newRenderError describes the error err from executing the template file.
+// sources holds the text of every template, for the excerpt.
+func newRenderError(file string, err error, sources map[string]renderable) *RenderError {
+	re := &RenderError{Template: file, Message: err.Error()}
+
+	msg := err.Error()
+	locs := execErrorLocation.FindAllStringSubmatchIndex(msg, -1)
+	// Only a chain of locations that runs from the start of the message down
+	// to the cause is trusted, in case the cause itself quotes a location.
+	end := 0
+	for n, loc := range locs {
+		if loc[0] != end {
+			locs = locs[:n]
+			break
+		}
+		end = loc[1]
+	}
+	if len(locs) == 0 {
+		return re
+	}
+
+	for n, loc := range locs {
+		f := msg[loc[2]:loc[3]]
+		line, _ := strconv.Atoi(msg[loc[4]:loc[5]])
+		col, _ := strconv.Atoi(msg[loc[6]:loc[7]])
+		if n > 0 {
+			re.Includes = append(re.Includes, IncludeFrame{
+				Name:   msg[loc[8]:loc[9]],
+				File:   re.File,
+				Line:   re.Line,
+				Column: re.Column,
+			})
+		}
+		re.File, re.Line, re.Column = f, line, col
+		re.Action = msg[loc[10]:loc[11]]
+	}
+	re.Message = msg[end:]
+
+	if r, ok := sources[re.File]; ok {
+		re.Source = excerpt(r.tpl, re.Line, re.Column)
+	}
+	return re
+}
+
+// excerpt returns the lines of src around line, numbered, with the failing
+// line marked and a caret under col. Like text/template, it counts columns in
+// bytes from zero.
+func excerpt(src string, line, col int) string {
+	lines := strings.Split(src, "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	first, last := line-sourceContext, line+sourceContext
+	if first < 1 {
+		first = 1
+	}
+	if last > len(lines) {
+		last = len(lines)
+	}
+	width := len(strconv.Itoa(last))
+
+	b := bytes.NewBuffer(nil)
+	for n := first; n <= last; n++ {
+		mark := " "
+		if n == line {
+			mark = ">"
+		}
+		fmt.Fprintf(b, "%s %*d | %s\n", mark, width, n, lines[n-1])
+		if n == line && col >= 0 {
+			fmt.Fprintf(b, "  %*s | %s^\n", width, "", strings.Repeat(" ", col))
+		}
+	}
+	return b.String()
+}
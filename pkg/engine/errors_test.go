@@ -0,0 +1,129 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+)
+
+func TestRenderErrorIncludes(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "web"},
+		Templates: []*chart.Template{
+			{Name: "templates/deployment.yaml", Data: []byte("kind: Deployment\nspec:\n  image: {{ include \"web.image\" . }}\n")},
+			{Name: "templates/_helpers.tpl", Data: []byte("{{- define \"web.image\" -}}\n{{ include \"web.tag\" . }}\n{{- end -}}\n{{- define \"web.tag\" -}}\n{{ .Values.image.tag }}\n{{- end -}}\n")},
+		},
+		Values: &chart.Config{Raw: ``},
+	}
+	v := chartutil.Values{"Values": chartutil.Values{}, "Chart": c.Metadata}
+
+	_, err := New().Render(c, v)
+	re, ok := err.(*RenderError)
+	if !ok {
+		t.Fatalf("Expected a RenderError, got %v", err)
+	}
+
+	if re.Template != "web/templates/deployment.yaml" {
+		t.Errorf("Unexpected template %q", re.Template)
+	}
+	if re.File != "web/templates/_helpers.tpl" || re.Line != 5 || re.Column != 10 {
+		t.Errorf("Unexpected location %s:%d:%d", re.File, re.Line, re.Column)
+	}
+	if re.Action != ".Values.image.tag" {
+		t.Errorf("Unexpected action %q", re.Action)
+	}
+	if !strings.Contains(re.Message, "nil pointer") {
+		t.Errorf("Unexpected message %q", re.Message)
+	}
+
+	expect := []IncludeFrame{
+		{Name: "web.image", File: "web/templates/deployment.yaml", Line: 3, Column: 12},
+		{Name: "web.tag", File: "web/templates/_helpers.tpl", Line: 2, Column: 3},
+	}
+	if len(re.Includes) != len(expect) {
+		t.Fatalf("Expected includes %v, got %v", expect, re.Includes)
+	}
+	for i, in := range expect {
+		if re.Includes[i] != in {
+			t.Errorf("Expected include %v, got %v", in, re.Includes[i])
+		}
+	}
+
+	msg := err.Error()
+	for _, s := range []string{
+		`render error in "web/templates/deployment.yaml": web/templates/_helpers.tpl:5:10: at <.Values.image.tag>`,
+		`(in "web.tag" included from web/templates/_helpers.tpl:2:3)`,
+		`(in "web.image" included from web/templates/deployment.yaml:3:12)`,
+	} {
+		if !strings.Contains(msg, s) {
+			t.Errorf("Expected %q in %q", s, msg)
+		}
+	}
+	if strings.Contains(msg, "error calling include") {
+		t.Errorf("Expected the include chain to be summarized, got %q", msg)
+	}
+
+	source := "  3 | {{- end -}}\n" +
+		"  4 | {{- define \"web.tag\" -}}\n" +
+		"> 5 | {{ .Values.image.tag }}\n" +
+		"    |           ^\n" +
+		"  6 | {{- end -}}\n" +
+		"  7 | \n"
+	if re.Source != source {
+		t.Errorf("Expected source\n%s\ngot\n%s", source, re.Source)
+	}
+}
+
+func TestRenderErrorWithoutLocation(t *testing.T) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "web"},
+		Templates: []*chart.Template{
+			{Name: "templates/svc.yaml", Data: []byte(`{{ include "missing" . }}`)},
+		},
+		Values: &chart.Config{Raw: ``},
+	}
+	v := chartutil.Values{"Values": chartutil.Values{}, "Chart": c.Metadata}
+
+	_, err := New().Render(c, v)
+	re, ok := err.(*RenderError)
+	if !ok {
+		t.Fatalf("Expected a RenderError, got %v", err)
+	}
+	if re.Template != "web/templates/svc.yaml" || re.File != "web/templates/svc.yaml" || re.Line != 1 {
+		t.Errorf("Unexpected location %s:%d in %s", re.File, re.Line, re.Template)
+	}
+	if !strings.Contains(re.Message, `no template "missing"`) {
+		t.Errorf("Unexpected message %q", re.Message)
+	}
+	if len(re.Includes) != 0 {
+		t.Errorf("Expected no includes, got %v", re.Includes)
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	src := "a\nb\nc"
+	if got := excerpt(src, 1, 0); got != "> 1 | a\n    | ^\n  2 | b\n  3 | c\n" {
+		t.Errorf("Unexpected excerpt %q", got)
+	}
+	if got := excerpt(src, 9, 0); got != "" {
+		t.Errorf("Expected no excerpt for a line out of range, got %q", got)
+	}
+}
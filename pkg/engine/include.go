@@ -0,0 +1,124 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+)
+
+// includeCall is a call of "include" that has not returned yet.
+type includeCall struct {
+	name string
+	data interface{}
+}
+
+// includer implements the "include" template function for one execution at a
+// time. It keeps track of the named templates the execution is inside of, so
+// that a template that includes itself fails instead of recursing until Tiller
+// runs out of memory.
+type includer struct {
+	t     *template.Template
+	max   int
+	calls []includeCall
+}
+
+func (e *Engine) newIncluder(t *template.Template) *includer {
+	max := e.MaxIncludeDepth
+	if max <= 0 {
+		max = DefaultMaxIncludeDepth
+	}
+	return &includer{t: t, max: max}
+}
+
+// include executes the named template with data and returns its output.
+//
+// Including a template with the same data as a call that is still running can
+// only ever recurse forever, so it is reported as a cycle right away. Other
+// recursion, such as a template that walks a tree by including itself for
+// every child, is allowed up to the depth limit.
+func (in *includer) include(name string, data interface{}) (string, error) {
+	for i, c := range in.calls {
+		if c.name == name && reflect.DeepEqual(c.data, data) {
+			return "", fmt.Errorf("include cycle: %s", in.chain(i, name))
+		}
+	}
+	if len(in.calls) >= in.max {
+		return "", fmt.Errorf("include depth of %d exceeded including %q", in.max, name)
+	}
+
+	in.calls = append(in.calls, includeCall{name: name, data: data})
+	defer func() { in.calls = in.calls[:len(in.calls)-1] }()
+
+	buf := bytes.NewBuffer(nil)
+	if err := in.t.ExecuteTemplate(buf, name, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// tplName is the name that "tpl" parses its text as, and that calls of "tpl"
+// are tracked by.
+const tplName = "tpl"
+
+// tpl renders text as a template with data, and returns its output. The
+// text may include the named templates of the chart, like any template.
+//
+// Calls of "tpl" count towards the include depth, and rendering the same
+// text with the same data from within itself is a cycle, as with "include".
+func (in *includer) tpl(text string, data interface{}) (string, error) {
+	call := includeCall{name: tplName, data: []interface{}{text, data}}
+	for i, c := range in.calls {
+		if reflect.DeepEqual(c, call) {
+			return "", fmt.Errorf("include cycle: %s", in.chain(i, tplName))
+		}
+	}
+	if len(in.calls) >= in.max {
+		return "", fmt.Errorf("include depth of %d exceeded rendering %q", in.max, text)
+	}
+
+	// Parse into a clone, so that the text cannot redefine the chart's named
+	// templates for the rest of the render.
+	t, err := in.t.Clone()
+	if err != nil {
+		return "", err
+	}
+	if _, err := t.New(tplName).Parse(text); err != nil {
+		return "", err
+	}
+
+	in.calls = append(in.calls, call)
+	defer func() { in.calls = in.calls[:len(in.calls)-1] }()
+
+	buf := bytes.NewBuffer(nil)
+	if err := t.ExecuteTemplate(buf, tplName, data); err != nil {
+		return "", err
+	}
+	return strings.Replace(buf.String(), "<no value>", "", -1), nil
+}
+
+// chain names the templates from the running call at index i back to name.
+func (in *includer) chain(i int, name string) string {
+	names := make([]string, 0, len(in.calls)-i+1)
+	for _, c := range in.calls[i:] {
+		names = append(names, fmt.Sprintf("%q", c.name))
+	}
+	return strings.Join(append(names, fmt.Sprintf("%q", name)), " -> ")
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+)
+
+func renderTemplates(e *Engine, tpls map[string]string) (map[string]string, error) {
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "loop"},
+		Values:   &chart.Config{Raw: ``},
+	}
+	for name, data := range tpls {
+		c.Templates = append(c.Templates, &chart.Template{Name: name, Data: []byte(data)})
+	}
+	v := chartutil.Values{"Values": chartutil.Values{"depth": 3}, "Chart": c.Metadata}
+	return e.Render(c, v)
+}
+
+func TestIncludeCycle(t *testing.T) {
+	for _, parallelism := range []int{1, 4} {
+		e := New()
+		e.Parallelism = parallelism
+		_, err := renderTemplates(e, map[string]string{
+			"templates/a.yaml":     `{{ include "a" . }}`,
+			"templates/b.yaml":     `b`,
+			"templates/_a.tpl":     `{{ define "a" }}{{ include "b" . }}{{ end }}`,
+			"templates/_b.tpl":     `{{ define "b" }}{{ include "a" . }}{{ end }}`,
+			"templates/other.yaml": `other`,
+		})
+		if err == nil {
+			t.Fatal("Expected an include cycle to fail rendering")
+		}
+		if !strings.Contains(err.Error(), `include cycle: "a" -> "b" -> "a"`) {
+			t.Errorf("Expected the cycle to be named, got %q", err)
+		}
+	}
+}
+
+func TestIncludeRecursion(t *testing.T) {
+	// A template may include itself as long as its data changes.
+	out, err := renderTemplates(New(), map[string]string{
+		"templates/count.yaml": `{{ include "count" 3 }}`,
+		"templates/_count.tpl": `{{ define "count" }}{{ . }}{{ if gt . 0 }} {{ include "count" (sub . 1) }}{{ end }}{{ end }}`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := out["loop/templates/count.yaml"]; got != "3 2 1 0" {
+		t.Errorf("Expected %q, got %q", "3 2 1 0", got)
+	}
+
+	e := New()
+	e.MaxIncludeDepth = 20
+	_, err = renderTemplates(e, map[string]string{
+		"templates/count.yaml": `{{ include "count" 0 }}`,
+		"templates/_count.tpl": `{{ define "count" }}{{ include "count" (add1 .) }}{{ end }}`,
+	})
+	if err == nil {
+		t.Fatal("Expected unbounded recursion to fail rendering")
+	}
+	msg := err.Error()
+	if !strings.Contains(msg, `include depth of 20 exceeded including "count"`) {
+		t.Errorf("Expected the depth limit to be reported, got %q", msg)
+	}
+	if !strings.Contains(msg, "(... 10 more includes)") {
+		t.Errorf("Expected a long include chain to be shortened, got %q", msg)
+	}
+}
+
+func TestTpl(t *testing.T) {
+	out, err := renderTemplates(New(), map[string]string{
+		"templates/greeting.yaml": `{{ tpl "{{ include \"name\" . }} is {{ .Values.depth }} deep" . }}`,
+		"templates/_name.tpl":     `{{ define "name" }}{{ .Chart.Name }}{{ end }}`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, expect := out["loop/templates/greeting.yaml"], "loop is 3 deep"; got != expect {
+		t.Errorf("Expected %q, got %q", expect, got)
+	}
+
+	_, err = renderTemplates(New(), map[string]string{
+		"templates/loop.yaml": `{{ include "loop" "{{ include \"loop\" . }}" }}`,
+		"templates/_loop.tpl": `{{ define "loop" }}{{ tpl . . }}{{ end }}`,
+	})
+	if err == nil || !strings.Contains(err.Error(), "include cycle") {
+		t.Errorf("Expected a tpl cycle to fail rendering, got %v", err)
+	}
+}
@@ -0,0 +1,314 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*Package gate decides whether releases may be changed now.
+
+Tiller can be configured with deployment windows, the days and times of day
+in which releases may be changed, and with gates, HTTP endpoints that must
+approve each change. Both can be limited to some namespaces and operations,
+so that, for example, production namespaces are only changed during office
+hours, while other namespaces are not restricted.
+*/
+package gate // import "k8s.io/helm/pkg/gate"
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+)
+
+// Request describes the change to a release that is about to be made. It is
+// posted to gates as JSON.
+type Request struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Revision  int32  `json:"revision"`
+	// Operation is "install", "upgrade", "rollback" or "delete".
+	Operation    string `json:"operation"`
+	Chart        string `json:"chart"`
+	ChartVersion string `json:"chartVersion"`
+}
+
+// Scope limits a window or gate to some releases. An empty list matches
+// everything.
+type Scope struct {
+	// Namespaces are path globs, e.g. "prod-*".
+	Namespaces []string `json:"namespaces"`
+	Operations []string `json:"operations"`
+}
+
+// matches reports whether the scope includes r.
+func (s Scope) matches(r *Request) bool {
+	if len(s.Operations) > 0 && !contains(s.Operations, r.Operation) {
+		return false
+	}
+	if len(s.Namespaces) == 0 {
+		return true
+	}
+	for _, p := range s.Namespaces {
+		if ok, _ := path.Match(p, r.Namespace); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Window is a time in which releases may be changed.
+type Window struct {
+	Scope
+	// Days are the days of the week the window opens on, as "mon" to "sun".
+	// If it is empty, the window opens every day.
+	Days []string `json:"days"`
+	// Start and End are times of the day, as "15:04". A window whose end is
+	// not after its start closes the next day.
+	Start string `json:"start"`
+	End   string `json:"end"`
+	// Timezone is the IANA name of the time zone of Start and End. It
+	// defaults to UTC.
+	Timezone string `json:"timezone"`
+
+	start, end time.Duration
+	loc        *time.Location
+}
+
+// Gate is an HTTP endpoint that must approve changes. It is posted the
+// Request, and approves the change by answering 200 OK; the body of any other
+// answer is reported as the reason for the rejection.
+type Gate struct {
+	Scope
+	URL string `json:"url"`
+	// Headers are added to the requests, e.g. for authentication.
+	Headers map[string]string `json:"headers"`
+}
+
+// Config holds the windows and gates of Tiller.
+type Config struct {
+	Windows []*Window `json:"windows"`
+	Gates   []*Gate   `json:"gates"`
+	// Client is the HTTP client used. If nil, http.DefaultClient is used.
+	Client *http.Client `json:"-"`
+
+	now func() time.Time
+}
+
+// Error is returned for changes that are not allowed now.
+type Error struct {
+	Release   string
+	Operation string
+	Reasons   []string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("release %s cannot be changed (%s) now: %s", e.Release, e.Operation, strings.Join(e.Reasons, "; "))
+}
+
+// maxReasonBytes is the most of the answer of a gate that is reported.
+const maxReasonBytes = 1024
+
+var days = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// LoadConfig reads windows and gates from a YAML file:
+//
+//	windows:
+//	  - namespaces: [prod-*]
+//	    days: [mon, tue, wed, thu]
+//	    start: "09:00"
+//	    end: "17:00"
+//	    timezone: Europe/Berlin
+//	gates:
+//	  - url: https://ci.example.com/deploy-gate
+//	    operations: [install, upgrade, rollback]
+func LoadConfig(filename string) (*Config, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	c := &Config{}
+	if err := yaml.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %s", filename, err)
+	}
+	for _, w := range c.Windows {
+		if err := w.parse(); err != nil {
+			return nil, fmt.Errorf("%s: %s", filename, err)
+		}
+	}
+	for _, g := range c.Gates {
+		if g.URL == "" {
+			return nil, fmt.Errorf("%s: gate without a url", filename)
+		}
+	}
+	return c, nil
+}
+
+func (w *Window) parse() error {
+	for _, d := range w.Days {
+		if _, ok := days[strings.ToLower(d)]; !ok {
+			return fmt.Errorf("window has unknown day %q: expected one of mon, tue, wed, thu, fri, sat or sun", d)
+		}
+	}
+	var err error
+	if w.start, err = parseTimeOfDay(w.Start); err != nil {
+		return fmt.Errorf("window has invalid start: %s", err)
+	}
+	if w.end, err = parseTimeOfDay(w.End); err != nil {
+		return fmt.Errorf("window has invalid end: %s", err)
+	}
+	w.loc = time.UTC
+	if w.Timezone != "" {
+		if w.loc, err = time.LoadLocation(w.Timezone); err != nil {
+			return fmt.Errorf("window has unknown timezone %q: %s", w.Timezone, err)
+		}
+	}
+	return nil
+}
+
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a time of day, e.g. 09:30", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// String describes the window, e.g. "mon,tue 09:00-17:00 Europe/Berlin".
+func (w *Window) String() string {
+	d := "daily"
+	if len(w.Days) > 0 {
+		d = strings.Join(w.Days, ",")
+	}
+	return fmt.Sprintf("%s %s-%s %s", d, w.Start, w.End, w.loc)
+}
+
+// open reports whether the window is open at t.
+func (w *Window) open(t time.Time) bool {
+	loc := w.loc
+	if loc == nil {
+		loc = time.UTC
+	}
+	t = t.In(loc)
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	tod := t.Sub(midnight)
+	if w.start < w.end {
+		return w.opensOn(t.Weekday()) && tod >= w.start && tod < w.end
+	}
+	// The window closes the day after it opens.
+	return (w.opensOn(t.Weekday()) && tod >= w.start) || (w.opensOn((t.Weekday()+6)%7) && tod < w.end)
+}
+
+func (w *Window) opensOn(d time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, s := range w.Days {
+		if days[strings.ToLower(s)] == d {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns an *Error if r may not be made now: if windows apply to r and
+// none of them is open, or if a gate that applies to r does not approve it.
+// Gates that cannot be reached reject the change.
+func (c *Config) Check(r *Request) error {
+	now := time.Now()
+	if c.now != nil {
+		now = c.now()
+	}
+
+	var reasons, windows []string
+	inWindow := false
+	for _, w := range c.Windows {
+		if !w.matches(r) {
+			continue
+		}
+		windows = append(windows, w.String())
+		if w.open(now) {
+			inWindow = true
+			break
+		}
+	}
+	if len(windows) > 0 && !inWindow {
+		reasons = append(reasons, "outside of the deployment windows ("+strings.Join(windows, ", ")+")")
+	}
+
+	for _, g := range c.Gates {
+		if !g.matches(r) {
+			continue
+		}
+		if err := g.approve(c.Client, r); err != nil {
+			reasons = append(reasons, err.Error())
+		}
+	}
+
+	if len(reasons) > 0 {
+		return &Error{Release: r.Name, Operation: r.Operation, Reasons: reasons}
+	}
+	return nil
+}
+
+// approve posts r to the gate, and returns why it was not approved, if it
+// was not.
+func (g *Gate) approve(client *http.Client, r *Request) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	body, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", g.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range g.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("gate %s could not be reached: %s", g.URL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	b, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxReasonBytes))
+	if msg := bytes.TrimSpace(b); len(msg) > 0 {
+		return fmt.Errorf("gate %s answered %s: %s", g.URL, resp.Status, msg)
+	}
+	return fmt.Errorf("gate %s answered %s", g.URL, resp.Status)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
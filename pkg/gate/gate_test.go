@@ -0,0 +1,142 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gate
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	tests := []struct {
+		config string
+		err    string
+	}{
+		{config: "windows:\n  - days: [mon, Fri]\n    start: \"09:00\"\n    end: \"17:30\"\ngates:\n  - url: http://gate\n"},
+		{config: "windows:\n  - days: [monday]\n    start: \"09:00\"\n    end: \"17:00\"\n", err: "unknown day"},
+		{config: "windows:\n  - start: \"9am\"\n    end: \"17:00\"\n", err: "invalid start"},
+		{config: "gates:\n  - headers: {a: b}\n", err: "gate without a url"},
+	}
+	for _, tt := range tests {
+		f, err := ioutil.TempFile("", "gates")
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.WriteString(tt.config)
+		f.Close()
+		_, err = LoadConfig(f.Name())
+		os.Remove(f.Name())
+		if tt.err == "" && err != nil {
+			t.Errorf("%q: %s", tt.config, err)
+		}
+		if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("%q: expected error %q, got %v", tt.config, tt.err, err)
+		}
+	}
+}
+
+func TestWindowOpen(t *testing.T) {
+	office := &Window{Days: []string{"mon", "tue", "wed", "thu", "fri"}, Start: "09:00", End: "17:00"}
+	night := &Window{Days: []string{"sat"}, Start: "22:00", End: "06:00"}
+	for _, w := range []*Window{office, night} {
+		if err := w.parse(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		w      *Window
+		t      string
+		expect bool
+	}{
+		{office, "2017-03-06T09:00:00Z", true}, // Monday
+		{office, "2017-03-06T16:59:00Z", true},
+		{office, "2017-03-06T17:00:00Z", false},
+		{office, "2017-03-06T08:59:00Z", false},
+		{office, "2017-03-05T12:00:00Z", false}, // Sunday
+		{night, "2017-03-04T23:00:00Z", true},   // Saturday
+		{night, "2017-03-05T05:00:00Z", true},   // early on Sunday
+		{night, "2017-03-05T07:00:00Z", false},
+		{night, "2017-03-05T23:00:00Z", false},
+		{night, "2017-03-04T05:00:00Z", false},
+	}
+	for _, tt := range tests {
+		at, _ := time.Parse(time.RFC3339, tt.t)
+		if got := tt.w.open(at); got != tt.expect {
+			t.Errorf("%s at %s: expected open %t, got %t", tt.w, tt.t, tt.expect, got)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	var got Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&got)
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if got.Name == "frozen" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte("incident in progress\n"))
+		}
+	}))
+	defer srv.Close()
+
+	window := &Window{Scope: Scope{Namespaces: []string{"prod-*"}}, Days: []string{"mon"}, Start: "09:00", End: "17:00"}
+	if err := window.parse(); err != nil {
+		t.Fatal(err)
+	}
+	monday, _ := time.Parse(time.RFC3339, "2017-03-06T12:00:00Z")
+	sunday := monday.Add(-24 * time.Hour)
+	c := &Config{
+		Windows: []*Window{window},
+		Gates: []*Gate{{
+			Scope:   Scope{Operations: []string{"install", "upgrade"}},
+			URL:     srv.URL,
+			Headers: map[string]string{"Authorization": "Bearer secret"},
+		}},
+		now: func() time.Time { return monday },
+	}
+
+	if err := c.Check(&Request{Name: "web", Namespace: "prod-eu", Operation: "upgrade"}); err != nil {
+		t.Errorf("expected the upgrade to be allowed, got %s", err)
+	}
+	if got.Name != "web" || got.Operation != "upgrade" {
+		t.Errorf("expected the gate to be posted the request, got %+v", got)
+	}
+
+	err := c.Check(&Request{Name: "frozen", Namespace: "dev", Operation: "install"})
+	if err == nil || !strings.HasSuffix(err.Error(), "answered 503 Service Unavailable: incident in progress") {
+		t.Errorf("expected the gate to reject the install, got %v", err)
+	}
+
+	c.now = func() time.Time { return sunday }
+	err = c.Check(&Request{Name: "web", Namespace: "prod-eu", Operation: "delete"})
+	if err == nil || err.Error() != "release web cannot be changed (delete) now: outside of the deployment windows (mon 09:00-17:00 UTC)" {
+		t.Errorf("expected the delete to be outside of the windows, got %v", err)
+	}
+	if err := c.Check(&Request{Name: "web", Namespace: "dev", Operation: "delete"}); err != nil {
+		t.Errorf("expected a namespace without windows to be allowed, got %s", err)
+	}
+}
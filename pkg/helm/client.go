@@ -14,13 +14,15 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package helm // import "k8s.io/helm/pkg/helm"
+package helm
 
 import (
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 
 	"k8s.io/helm/pkg/chartutil"
+	cpb "k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/proto/hapi/release"
 	rls "k8s.io/helm/pkg/proto/hapi/services"
 )
 
@@ -43,13 +45,21 @@ func (h *Client) Option(opts ...Option) *Client {
 	return h
 }
 
+// call returns a copy of the client for a single call, so that the options
+// of the call do not carry over to later calls.
+func (h *Client) call() *Client {
+	c := *h
+	return &c
+}
+
 // ListReleases lists the current releases.
 func (h *Client) ListReleases(opts ...ReleaseListOption) (*rls.ListReleasesResponse, error) {
+	h = h.call()
 	for _, opt := range opts {
 		opt(&h.opts)
 	}
 	req := &h.opts.listReq
-	ctx := NewContext()
+	ctx := h.opts.context()
 
 	if h.opts.before != nil {
 		if err := h.opts.before(ctx, req); err != nil {
@@ -59,15 +69,22 @@ func (h *Client) ListReleases(opts ...ReleaseListOption) (*rls.ListReleasesRespo
 	return h.list(ctx, req)
 }
 
-// InstallRelease installs a new chart and returns the release response.
+// InstallRelease loads a chart from chstr, installs it and returns the release
+// response.
 func (h *Client) InstallRelease(chstr, ns string, opts ...InstallOption) (*rls.InstallReleaseResponse, error) {
 	// load the chart to install
 	chart, err := chartutil.Load(chstr)
 	if err != nil {
 		return nil, err
 	}
+	return h.InstallReleaseFromChart(chart, ns, opts...)
+}
 
+// InstallReleaseFromChart installs a chart that is already loaded and returns
+// the release response.
+func (h *Client) InstallReleaseFromChart(chart *cpb.Chart, ns string, opts ...InstallOption) (*rls.InstallReleaseResponse, error) {
 	// apply the install options
+	h = h.call()
 	for _, opt := range opts {
 		opt(&h.opts)
 	}
@@ -77,7 +94,7 @@ func (h *Client) InstallRelease(chstr, ns string, opts ...InstallOption) (*rls.I
 	req.DryRun = h.opts.dryRun
 	req.DisableHooks = h.opts.disableHooks
 	req.ReuseName = h.opts.reuseName
-	ctx := NewContext()
+	ctx := h.opts.context()
 
 	if h.opts.before != nil {
 		if err := h.opts.before(ctx, req); err != nil {
@@ -90,6 +107,7 @@ func (h *Client) InstallRelease(chstr, ns string, opts ...InstallOption) (*rls.I
 // DeleteRelease uninstalls a named release and returns the response.
 func (h *Client) DeleteRelease(rlsName string, opts ...DeleteOption) (*rls.UninstallReleaseResponse, error) {
 	// apply the uninstall options
+	h = h.call()
 	for _, opt := range opts {
 		opt(&h.opts)
 	}
@@ -106,7 +124,7 @@ func (h *Client) DeleteRelease(rlsName string, opts ...DeleteOption) (*rls.Unins
 	req := &h.opts.uninstallReq
 	req.Name = rlsName
 	req.DisableHooks = h.opts.disableHooks
-	ctx := NewContext()
+	ctx := h.opts.context()
 
 	if h.opts.before != nil {
 		if err := h.opts.before(ctx, req); err != nil {
@@ -116,15 +134,20 @@ func (h *Client) DeleteRelease(rlsName string, opts ...DeleteOption) (*rls.Unins
 	return h.delete(ctx, req)
 }
 
-// UpdateRelease updates a release to a new/different chart
+// UpdateRelease updates a release to a new/different chart loaded from chstr
 func (h *Client) UpdateRelease(rlsName string, chstr string, opts ...UpdateOption) (*rls.UpdateReleaseResponse, error) {
 	// load the chart to update
 	chart, err := chartutil.Load(chstr)
 	if err != nil {
 		return nil, err
 	}
+	return h.UpdateReleaseFromChart(rlsName, chart, opts...)
+}
 
+// UpdateReleaseFromChart updates a release to a chart that is already loaded
+func (h *Client) UpdateReleaseFromChart(rlsName string, chart *cpb.Chart, opts ...UpdateOption) (*rls.UpdateReleaseResponse, error) {
 	// apply the update options
+	h = h.call()
 	for _, opt := range opts {
 		opt(&h.opts)
 	}
@@ -133,7 +156,7 @@ func (h *Client) UpdateRelease(rlsName string, chstr string, opts ...UpdateOptio
 	req.DryRun = h.opts.dryRun
 	req.Name = rlsName
 	req.DisableHooks = h.opts.disableHooks
-	ctx := NewContext()
+	ctx := h.opts.context()
 
 	if h.opts.before != nil {
 		if err := h.opts.before(ctx, req); err != nil {
@@ -145,11 +168,12 @@ func (h *Client) UpdateRelease(rlsName string, chstr string, opts ...UpdateOptio
 
 // GetVersion returns the server version
 func (h *Client) GetVersion(opts ...VersionOption) (*rls.GetVersionResponse, error) {
+	h = h.call()
 	for _, opt := range opts {
 		opt(&h.opts)
 	}
 	req := &rls.GetVersionRequest{}
-	ctx := NewContext()
+	ctx := h.opts.context()
 
 	if h.opts.before != nil {
 		if err := h.opts.before(ctx, req); err != nil {
@@ -161,6 +185,7 @@ func (h *Client) GetVersion(opts ...VersionOption) (*rls.GetVersionResponse, err
 
 // RollbackRelease rolls back a release to the previous version
 func (h *Client) RollbackRelease(rlsName string, opts ...RollbackOption) (*rls.RollbackReleaseResponse, error) {
+	h = h.call()
 	for _, opt := range opts {
 		opt(&h.opts)
 	}
@@ -168,7 +193,7 @@ func (h *Client) RollbackRelease(rlsName string, opts ...RollbackOption) (*rls.R
 	req.DisableHooks = h.opts.disableHooks
 	req.DryRun = h.opts.dryRun
 	req.Name = rlsName
-	ctx := NewContext()
+	ctx := h.opts.context()
 
 	if h.opts.before != nil {
 		if err := h.opts.before(ctx, req); err != nil {
@@ -180,12 +205,13 @@ func (h *Client) RollbackRelease(rlsName string, opts ...RollbackOption) (*rls.R
 
 // ReleaseStatus returns the given release's status.
 func (h *Client) ReleaseStatus(rlsName string, opts ...StatusOption) (*rls.GetReleaseStatusResponse, error) {
+	h = h.call()
 	for _, opt := range opts {
 		opt(&h.opts)
 	}
 	req := &h.opts.statusReq
 	req.Name = rlsName
-	ctx := NewContext()
+	ctx := h.opts.context()
 
 	if h.opts.before != nil {
 		if err := h.opts.before(ctx, req); err != nil {
@@ -197,12 +223,13 @@ func (h *Client) ReleaseStatus(rlsName string, opts ...StatusOption) (*rls.GetRe
 
 // ReleaseContent returns the configuration for a given release.
 func (h *Client) ReleaseContent(rlsName string, opts ...ContentOption) (*rls.GetReleaseContentResponse, error) {
+	h = h.call()
 	for _, opt := range opts {
 		opt(&h.opts)
 	}
 	req := &h.opts.contentReq
 	req.Name = rlsName
-	ctx := NewContext()
+	ctx := h.opts.context()
 
 	if h.opts.before != nil {
 		if err := h.opts.before(ctx, req); err != nil {
@@ -214,13 +241,14 @@ func (h *Client) ReleaseContent(rlsName string, opts ...ContentOption) (*rls.Get
 
 // ReleaseHistory returns a release's revision history.
 func (h *Client) ReleaseHistory(rlsName string, opts ...HistoryOption) (*rls.GetHistoryResponse, error) {
+	h = h.call()
 	for _, opt := range opts {
 		opt(&h.opts)
 	}
 
 	req := &h.opts.histReq
 	req.Name = rlsName
-	ctx := NewContext()
+	ctx := h.opts.context()
 
 	if h.opts.before != nil {
 		if err := h.opts.before(ctx, req); err != nil {
@@ -230,6 +258,145 @@ func (h *Client) ReleaseHistory(rlsName string, opts ...HistoryOption) (*rls.Get
 	return h.history(ctx, req)
 }
 
+// ImportRelease stores the revisions of a release exported from another
+// Tiller.
+func (h *Client) ImportRelease(revisions []*release.Release, opts ...ImportOption) (*rls.ImportReleaseResponse, error) {
+	h = h.call()
+	for _, opt := range opts {
+		opt(&h.opts)
+	}
+
+	req := &h.opts.importReq
+	req.Releases = revisions
+	ctx := h.opts.context()
+
+	if h.opts.before != nil {
+		if err := h.opts.before(ctx, req); err != nil {
+			return nil, err
+		}
+	}
+	return h.importRelease(ctx, req)
+}
+
+// ReleaseDrift compares the resources of a release with the live objects in
+// the cluster.
+func (h *Client) ReleaseDrift(rlsName string, opts ...DriftOption) (*rls.GetReleaseDriftResponse, error) {
+	h = h.call()
+	for _, opt := range opts {
+		opt(&h.opts)
+	}
+
+	req := &h.opts.driftReq
+	req.Name = rlsName
+	ctx := h.opts.context()
+
+	if h.opts.before != nil {
+		if err := h.opts.before(ctx, req); err != nil {
+			return nil, err
+		}
+	}
+	return h.drift(ctx, req)
+}
+
+// SyncRelease reverts the changes made to the live objects of a release
+// since it was installed or upgraded.
+func (h *Client) SyncRelease(rlsName string, opts ...SyncOption) (*rls.SyncReleaseResponse, error) {
+	h = h.call()
+	for _, opt := range opts {
+		opt(&h.opts)
+	}
+
+	req := &h.opts.syncReq
+	req.Name = rlsName
+	ctx := h.opts.context()
+
+	if h.opts.before != nil {
+		if err := h.opts.before(ctx, req); err != nil {
+			return nil, err
+		}
+	}
+	return h.sync(ctx, req)
+}
+
+// SetReleaseValues stores values on a release, to be merged over the values of
+// its upgrades.
+func (h *Client) SetReleaseValues(rlsName string, rawVals []byte, opts ...SetValuesOption) (*rls.SetReleaseValuesResponse, error) {
+	h = h.call()
+	for _, opt := range opts {
+		opt(&h.opts)
+	}
+
+	req := &h.opts.setValuesReq
+	req.Name = rlsName
+	req.Values = &cpb.Config{Raw: string(rawVals)}
+	ctx := h.opts.context()
+
+	if h.opts.before != nil {
+		if err := h.opts.before(ctx, req); err != nil {
+			return nil, err
+		}
+	}
+	return h.setValues(ctx, req)
+}
+
+// MaintainStorage compacts the release history Tiller stores, removes the
+// records left behind by purges, and reports on the storage.
+func (h *Client) MaintainStorage(opts ...MaintainOption) (*rls.MaintainStorageResponse, error) {
+	h = h.call()
+	for _, opt := range opts {
+		opt(&h.opts)
+	}
+
+	req := &h.opts.maintainReq
+	ctx := h.opts.context()
+
+	if h.opts.before != nil {
+		if err := h.opts.before(ctx, req); err != nil {
+			return nil, err
+		}
+	}
+	return h.maintain(ctx, req)
+}
+
+// SetReleaseLock locks a release, or unlocks it with LockRemove.
+func (h *Client) SetReleaseLock(rlsName string, opts ...LockOption) (*rls.SetReleaseLockResponse, error) {
+	h = h.call()
+	for _, opt := range opts {
+		opt(&h.opts)
+	}
+
+	req := &h.opts.lockReq
+	req.Name = rlsName
+	ctx := h.opts.context()
+
+	if h.opts.before != nil {
+		if err := h.opts.before(ctx, req); err != nil {
+			return nil, err
+		}
+	}
+	return h.lock(ctx, req)
+}
+
+// PromoteRelease switches the Services of a release over to its blue/green
+// revision.
+func (h *Client) PromoteRelease(rlsName string, opts ...PromoteOption) (*rls.PromoteReleaseResponse, error) {
+	h = h.call()
+	for _, opt := range opts {
+		opt(&h.opts)
+	}
+
+	req := &h.opts.promoteReq
+	req.Name = rlsName
+	ctx := h.opts.context()
+
+	if h.opts.before != nil {
+		if err := h.opts.before(ctx, req); err != nil {
+			return nil, err
+		}
+	}
+	return h.promote(ctx, req)
+}
+
 // Executes tiller.ListReleases RPC.
 func (h *Client) list(ctx context.Context, req *rls.ListReleasesRequest) (*rls.ListReleasesResponse, error) {
 	c, err := grpc.Dial(h.opts.host, grpc.WithInsecure())
@@ -256,7 +423,19 @@ func (h *Client) install(ctx context.Context, req *rls.InstallReleaseRequest) (*
 	defer c.Close()
 
 	rlc := rls.NewReleaseServiceClient(c)
-	return rlc.InstallRelease(ctx, req)
+	if h.opts.progress == nil {
+		return rlc.InstallRelease(ctx, req)
+	}
+
+	s, err := rlc.InstallReleaseProgress(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	r, err := recvProgress(s, h.opts.progress)
+	if err != nil {
+		return nil, err
+	}
+	return &rls.InstallReleaseResponse{Release: r}, nil
 }
 
 // Executes tiller.UninstallRelease RPC.
@@ -280,7 +459,42 @@ func (h *Client) update(ctx context.Context, req *rls.UpdateReleaseRequest) (*rl
 	defer c.Close()
 
 	rlc := rls.NewReleaseServiceClient(c)
-	return rlc.UpdateRelease(ctx, req)
+	if h.opts.progress == nil {
+		return rlc.UpdateRelease(ctx, req)
+	}
+
+	s, err := rlc.UpdateReleaseProgress(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	r, err := recvProgress(s, h.opts.progress)
+	if err != nil {
+		return nil, err
+	}
+	return &rls.UpdateReleaseResponse{Release: r}, nil
+}
+
+// progressStream is the client side of InstallReleaseProgress and
+// UpdateReleaseProgress.
+type progressStream interface {
+	Recv() (*rls.ReleaseProgressResponse, error)
+}
+
+// recvProgress passes the events of a progress stream to fn until the
+// release arrives.
+func recvProgress(s progressStream, fn func(*rls.ResourceEvent)) (*release.Release, error) {
+	for {
+		res, err := s.Recv()
+		if err != nil {
+			return nil, err
+		}
+		if res.Event != nil {
+			fn(res.Event)
+		}
+		if res.Release != nil {
+			return res.Release, nil
+		}
+	}
 }
 
 // Executes tiller.RollbackRelease RPC.
@@ -342,3 +556,87 @@ func (h *Client) history(ctx context.Context, req *rls.GetHistoryRequest) (*rls.
 	rlc := rls.NewReleaseServiceClient(c)
 	return rlc.GetHistory(ctx, req)
 }
+
+// Executes tiller.ImportRelease RPC.
+func (h *Client) importRelease(ctx context.Context, req *rls.ImportReleaseRequest) (*rls.ImportReleaseResponse, error) {
+	c, err := grpc.Dial(h.opts.host, grpc.WithInsecure())
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	rlc := rls.NewReleaseServiceClient(c)
+	return rlc.ImportRelease(ctx, req)
+}
+
+// Executes tiller.GetReleaseDrift RPC.
+func (h *Client) drift(ctx context.Context, req *rls.GetReleaseDriftRequest) (*rls.GetReleaseDriftResponse, error) {
+	c, err := grpc.Dial(h.opts.host, grpc.WithInsecure())
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	rlc := rls.NewReleaseServiceClient(c)
+	return rlc.GetReleaseDrift(ctx, req)
+}
+
+// Executes tiller.SyncRelease RPC.
+func (h *Client) sync(ctx context.Context, req *rls.SyncReleaseRequest) (*rls.SyncReleaseResponse, error) {
+	c, err := grpc.Dial(h.opts.host, grpc.WithInsecure())
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	rlc := rls.NewReleaseServiceClient(c)
+	return rlc.SyncRelease(ctx, req)
+}
+
+// Executes tiller.SetReleaseValues RPC.
+func (h *Client) setValues(ctx context.Context, req *rls.SetReleaseValuesRequest) (*rls.SetReleaseValuesResponse, error) {
+	c, err := grpc.Dial(h.opts.host, grpc.WithInsecure())
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	rlc := rls.NewReleaseServiceClient(c)
+	return rlc.SetReleaseValues(ctx, req)
+}
+
+// Executes tiller.MaintainStorage RPC.
+func (h *Client) maintain(ctx context.Context, req *rls.MaintainStorageRequest) (*rls.MaintainStorageResponse, error) {
+	c, err := grpc.Dial(h.opts.host, grpc.WithInsecure())
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	rlc := rls.NewReleaseServiceClient(c)
+	return rlc.MaintainStorage(ctx, req)
+}
+
+// Executes tiller.SetReleaseLock RPC.
+func (h *Client) lock(ctx context.Context, req *rls.SetReleaseLockRequest) (*rls.SetReleaseLockResponse, error) {
+	c, err := grpc.Dial(h.opts.host, grpc.WithInsecure())
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	rlc := rls.NewReleaseServiceClient(c)
+	return rlc.SetReleaseLock(ctx, req)
+}
+
+// Executes tiller.PromoteRelease RPC.
+func (h *Client) promote(ctx context.Context, req *rls.PromoteReleaseRequest) (*rls.PromoteReleaseResponse, error) {
+	c, err := grpc.Dial(h.opts.host, grpc.WithInsecure())
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	rlc := rls.NewReleaseServiceClient(c)
+	return rlc.PromoteRelease(ctx, req)
+}
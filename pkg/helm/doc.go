@@ -0,0 +1,33 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*Package helm is the Go client of the Helm API.
+
+A Client talks to Tiller over gRPC to install, upgrade, roll back, list,
+inspect and delete releases. It is the client the helm command uses, and
+it can be used by other programs in the same way:
+
+	client := helm.NewClient(helm.Host("localhost:44134"))
+	res, err := client.ReleaseStatus("web")
+
+Options passed to NewClient or Client.Option apply to every call, and
+options passed to a call apply to that call only.
+
+Within a major release, the exported API of this package is only added to.
+Every call sends the API version the client speaks (see version.APIVersion),
+so that Tiller can serve clients of other release versions.
+*/
+package helm // import "k8s.io/helm/pkg/helm"
@@ -20,15 +20,18 @@ import (
 	"errors"
 	"path/filepath"
 	"reflect"
+	"strconv"
 	"testing"
 
 	"github.com/golang/protobuf/proto"
 	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
 
 	"k8s.io/helm/pkg/chartutil"
 	cpb "k8s.io/helm/pkg/proto/hapi/chart"
 	rls "k8s.io/helm/pkg/proto/hapi/release"
 	tpb "k8s.io/helm/pkg/proto/hapi/services"
+	"k8s.io/helm/pkg/version"
 )
 
 // path to example charts relative to pkg/helm.
@@ -107,6 +110,8 @@ func TestInstallRelease_VerifyOptions(t *testing.T) {
 		DisableHooks: disableHooks,
 		Namespace:    namespace,
 		ReuseName:    reuseName,
+		Wait:         true,
+		Timeout:      60,
 	}
 
 	// Options used in InstallRelease
@@ -116,6 +121,7 @@ func TestInstallRelease_VerifyOptions(t *testing.T) {
 		ReleaseName(releaseName),
 		InstallReuseName(reuseName),
 		InstallDisableHooks(disableHooks),
+		InstallWait(true, false, 60),
 	}
 
 	// BeforeCall option to intercept helm client InstallReleaseRequest
@@ -184,6 +190,7 @@ func TestUpdateRelease_VerifyOptions(t *testing.T) {
 		Values:       &cpb.Config{Raw: string(overrides)},
 		DryRun:       dryRun,
 		DisableHooks: disableHooks,
+		Metadata:     map[string]string{"commit": "abc123"},
 	}
 
 	// Options used in UpdateRelease
@@ -191,6 +198,7 @@ func TestUpdateRelease_VerifyOptions(t *testing.T) {
 		UpgradeDryRun(dryRun),
 		UpdateValueOverrides(overrides),
 		UpgradeDisableHooks(disableHooks),
+		UpgradeMetadata(map[string]string{"commit": "abc123"}),
 	}
 
 	// BeforeCall option to intercept helm client UpdateReleaseRequest
@@ -300,6 +308,103 @@ func TestReleaseContent_VerifyOptions(t *testing.T) {
 	NewClient(b4c).ReleaseContent(releaseName, ContentReleaseVersion(revision))
 }
 
+// Verify a loaded chart is sent as is by InstallReleaseFromChart.
+func TestInstallReleaseFromChart(t *testing.T) {
+	chart := loadChart(t, "alpine")
+
+	called := false
+	b4c := BeforeCall(func(_ context.Context, msg proto.Message) error {
+		called = true
+		act, ok := msg.(*tpb.InstallReleaseRequest)
+		if !ok {
+			t.Fatalf("expected message of type InstallReleaseRequest, got %T\n", msg)
+		}
+		if act.Chart != chart || act.Namespace != "default" || act.Name != "test" {
+			t.Errorf("unexpected request: %#+v", act)
+		}
+		return errSkip
+	})
+
+	NewClient(b4c).InstallReleaseFromChart(chart, "default", ReleaseName("test"))
+	if !called {
+		t.Error("expected the request to be sent")
+	}
+}
+
+// Verify the options of one call do not carry over to the next.
+func TestCallOptions_NotCarriedOver(t *testing.T) {
+	var reqs []*tpb.GetReleaseContentRequest
+	b4c := BeforeCall(func(_ context.Context, msg proto.Message) error {
+		reqs = append(reqs, msg.(*tpb.GetReleaseContentRequest))
+		return errSkip
+	})
+
+	client := NewClient(b4c)
+	client.ReleaseContent("first", ContentReleaseVersion(3))
+	client.ReleaseContent("second")
+
+	if len(reqs) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(reqs))
+	}
+	assert(t, &tpb.GetReleaseContentRequest{Name: "first", Version: 3}, reqs[0])
+	assert(t, &tpb.GetReleaseContentRequest{Name: "second"}, reqs[1])
+}
+
+// Verify the UserToken option sends the token with calls.
+func TestUserToken_SentInContext(t *testing.T) {
+	b4c := BeforeCall(func(ctx context.Context, msg proto.Message) error {
+		md, ok := metadata.FromContext(ctx)
+		if !ok {
+			t.Fatal("expected call metadata")
+		}
+		assert(t, []string{"s3cr3t"}, md["x-helm-user-token"])
+		if len(md["x-helm-api-client"]) != 1 {
+			t.Errorf("expected the client version to be sent, got %v", md)
+		}
+		assert(t, []string{strconv.Itoa(version.APIVersion)}, md["x-helm-api-version"])
+		return errSkip
+	})
+
+	NewClient(b4c, UserToken("s3cr3t")).ReleaseStatus("boring-release")
+}
+
+// Verify the Impersonate option sends the identity with calls.
+func TestImpersonate_SentInContext(t *testing.T) {
+	b4c := BeforeCall(func(ctx context.Context, msg proto.Message) error {
+		md, ok := metadata.FromContext(ctx)
+		if !ok {
+			t.Fatal("expected call metadata")
+		}
+		assert(t, []string{"ci"}, md["x-helm-as-user"])
+		assert(t, []string{"dev", "ops"}, md["x-helm-as-group"])
+		return errSkip
+	})
+
+	NewClient(b4c, Impersonate("ci", []string{"dev", "ops"})).ReleaseStatus("boring-release")
+}
+
+// Verify the RequestID option sends the ID with calls.
+func TestRequestID_SentInContext(t *testing.T) {
+	id := NewRequestID()
+	if len(id) != 16 {
+		t.Fatalf("expected a request ID of 16 hex digits, got %q", id)
+	}
+	if NewRequestID() == id {
+		t.Error("expected request IDs to differ")
+	}
+
+	b4c := BeforeCall(func(ctx context.Context, msg proto.Message) error {
+		md, ok := metadata.FromContext(ctx)
+		if !ok {
+			t.Fatal("expected call metadata")
+		}
+		assert(t, []string{id}, md["x-helm-request-id"])
+		return errSkip
+	})
+
+	NewClient(b4c, RequestID(id)).ReleaseStatus("boring-release")
+}
+
 func assert(t *testing.T, expect, actual interface{}) {
 	if !reflect.DeepEqual(expect, actual) {
 		t.Fatalf("expected %#+v, actual %#+v\n", expect, actual)
@@ -17,18 +17,36 @@ limitations under the License.
 package helm
 
 import (
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/proto/hapi/release"
 	rls "k8s.io/helm/pkg/proto/hapi/services"
 )
 
 // Interface for helm client for mocking in tests
+//
+// Interface is part of the stable client API: methods are only added to it
+// in a new minor release, never removed or changed. Code that implements it,
+// such as a mock, should embed a Client or another implementation if it must
+// keep compiling across minor releases.
 type Interface interface {
 	ListReleases(opts ...ReleaseListOption) (*rls.ListReleasesResponse, error)
 	InstallRelease(chStr, namespace string, opts ...InstallOption) (*rls.InstallReleaseResponse, error)
+	InstallReleaseFromChart(chart *chart.Chart, namespace string, opts ...InstallOption) (*rls.InstallReleaseResponse, error)
 	DeleteRelease(rlsName string, opts ...DeleteOption) (*rls.UninstallReleaseResponse, error)
 	ReleaseStatus(rlsName string, opts ...StatusOption) (*rls.GetReleaseStatusResponse, error)
 	UpdateRelease(rlsName, chStr string, opts ...UpdateOption) (*rls.UpdateReleaseResponse, error)
+	UpdateReleaseFromChart(rlsName string, chart *chart.Chart, opts ...UpdateOption) (*rls.UpdateReleaseResponse, error)
 	RollbackRelease(rlsName string, opts ...RollbackOption) (*rls.RollbackReleaseResponse, error)
 	ReleaseContent(rlsName string, opts ...ContentOption) (*rls.GetReleaseContentResponse, error)
 	ReleaseHistory(rlsName string, opts ...HistoryOption) (*rls.GetHistoryResponse, error)
 	GetVersion(opts ...VersionOption) (*rls.GetVersionResponse, error)
+	ImportRelease(revisions []*release.Release, opts ...ImportOption) (*rls.ImportReleaseResponse, error)
+	ReleaseDrift(rlsName string, opts ...DriftOption) (*rls.GetReleaseDriftResponse, error)
+	SyncRelease(rlsName string, opts ...SyncOption) (*rls.SyncReleaseResponse, error)
+	SetReleaseValues(rlsName string, rawVals []byte, opts ...SetValuesOption) (*rls.SetReleaseValuesResponse, error)
+	MaintainStorage(opts ...MaintainOption) (*rls.MaintainStorageResponse, error)
+	SetReleaseLock(rlsName string, opts ...LockOption) (*rls.SetReleaseLockResponse, error)
+	PromoteRelease(rlsName string, opts ...PromoteOption) (*rls.PromoteReleaseResponse, error)
 }
+
+var _ Interface = &Client{}
@@ -17,6 +17,10 @@ limitations under the License.
 package helm
 
 import (
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+
 	"github.com/golang/protobuf/proto"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc/metadata"
@@ -62,6 +66,29 @@ type options struct {
 	before func(context.Context, proto.Message) error
 	// release history options are applied directly to the get release history request
 	histReq rls.GetHistoryRequest
+	// release import options are applied directly to the import release request
+	importReq rls.ImportReleaseRequest
+	// release drift options are applied directly to the get release drift request
+	driftReq rls.GetReleaseDriftRequest
+	// release sync options are applied directly to the sync release request
+	syncReq rls.SyncReleaseRequest
+	// set release values options are applied directly to the set release values request
+	setValuesReq rls.SetReleaseValuesRequest
+	// maintain storage options are applied directly to the maintain storage request
+	maintainReq rls.MaintainStorageRequest
+	// set release lock options are applied directly to the set release lock request
+	lockReq rls.SetReleaseLockRequest
+	// promote release options are applied directly to the promote release request
+	promoteReq rls.PromoteReleaseRequest
+	// if set, install and update stream their progress to this function
+	progress func(*rls.ResourceEvent)
+	// Kubernetes bearer token identifying the user to Tiller
+	userToken string
+	// user and groups Tiller should impersonate on the cluster
+	asUser   string
+	asGroups []string
+	// ID that Tiller tags the log messages of every call with
+	requestID string
 }
 
 // Host specifies the host address of the Tiller release server, (default = ":44134").
@@ -71,6 +98,41 @@ func Host(host string) Option {
 	}
 }
 
+// UserToken sends a Kubernetes bearer token along with every call, so that a
+// Tiller acting on the cluster as its users can tell who the caller is.
+func UserToken(token string) Option {
+	return func(opts *options) {
+		opts.userToken = token
+	}
+}
+
+// Impersonate asks Tiller to perform the Kubernetes operations of every call
+// as user, a member of groups, instead of with its own identity.
+func Impersonate(user string, groups []string) Option {
+	return func(opts *options) {
+		opts.asUser = user
+		opts.asGroups = groups
+	}
+}
+
+// RequestID sends id along with every call. Tiller tags its log messages for
+// the calls with it, so that they can be found from the error the user sees.
+// See NewRequestID.
+func RequestID(id string) Option {
+	return func(opts *options) {
+		opts.requestID = id
+	}
+}
+
+// NewRequestID returns a random ID for the calls of one command.
+func NewRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
 // BeforeCall returns an option that allows intercepting a helm client rpc
 // before being sent OTA to tiller. The intercepting function should return
 // an error to indicate that the call should not proceed or nil otherwise.
@@ -177,6 +239,48 @@ func DeletePurge(purge bool) DeleteOption {
 	}
 }
 
+// DeleteOverrideLock deletes a locked release, recording reason as the
+// reason for the change.
+func DeleteOverrideLock(reason string) DeleteOption {
+	return func(opts *options) {
+		opts.uninstallReq.OverrideLock = reason
+	}
+}
+
+// DeleteOverrideGates deletes a release even if the deployment windows or
+// gates of Tiller do not allow it, recording reason as the reason for the
+// change.
+func DeleteOverrideGates(reason string) DeleteOption {
+	return func(opts *options) {
+		opts.uninstallReq.OverrideGates = reason
+	}
+}
+
+// DeleteCascade sets the propagation policy of the deletion: "background",
+// "foreground" or "orphan".
+func DeleteCascade(policy string) DeleteOption {
+	return func(opts *options) {
+		opts.uninstallReq.Cascade = policy
+	}
+}
+
+// DeleteIncludeKept will (if true) also delete the resources that their
+// resource policy keeps.
+func DeleteIncludeKept(include bool) DeleteOption {
+	return func(opts *options) {
+		opts.uninstallReq.IncludeKept = include
+	}
+}
+
+// DeleteWait makes Tiller delete the resources of the release kind by kind,
+// and wait for up to timeout seconds until they are gone.
+func DeleteWait(wait bool, timeout int64) DeleteOption {
+	return func(opts *options) {
+		opts.uninstallReq.Wait = wait
+		opts.uninstallReq.Timeout = timeout
+	}
+}
+
 // InstallDryRun will (if true) execute an installation as a dry run.
 func InstallDryRun(dry bool) InstallOption {
 	return func(opts *options) {
@@ -184,6 +288,14 @@ func InstallDryRun(dry bool) InstallOption {
 	}
 }
 
+// InstallServerDryRun will (if true) have a dry run send the resources of the
+// release to the Kubernetes API for validation, as a server-side dry run.
+func InstallServerDryRun(server bool) InstallOption {
+	return func(opts *options) {
+		opts.instReq.ServerDryRun = server
+	}
+}
+
 // InstallDisableHooks disables hooks during installation.
 func InstallDisableHooks(disable bool) InstallOption {
 	return func(opts *options) {
@@ -198,6 +310,55 @@ func InstallReuseName(reuse bool) InstallOption {
 	}
 }
 
+// InstallServerSideApply will (if true) create resources using server-side apply.
+func InstallServerSideApply(serverSide bool) InstallOption {
+	return func(opts *options) {
+		opts.instReq.ServerSide = serverSide
+	}
+}
+
+// InstallOverrideGates installs a release even if the deployment windows or
+// gates of Tiller do not allow it, recording reason on the release as the
+// reason for the change.
+func InstallOverrideGates(reason string) InstallOption {
+	return func(opts *options) {
+		opts.instReq.OverrideGates = reason
+	}
+}
+
+// InstallTemplateFilter limits an install to the templates matching one of the
+// include patterns (if any) and none of the exclude patterns. Patterns are
+// template path globs or Kind/name selectors.
+func InstallTemplateFilter(include, exclude []string) InstallOption {
+	return func(opts *options) {
+		opts.instReq.IncludeTemplates = include
+		opts.instReq.ExcludeTemplates = exclude
+	}
+}
+
+// InstallDisableVersionCheck will (if true) skip the chart's kubeVersion and
+// tillerVersion checks.
+func InstallDisableVersionCheck(disable bool) InstallOption {
+	return func(opts *options) {
+		opts.instReq.DisableVersionCheck = disable
+	}
+}
+
+// InstallSkipPreflight will (if true) skip the pre-flight checks of the chart.
+func InstallSkipPreflight(skip bool) InstallOption {
+	return func(opts *options) {
+		opts.instReq.SkipPreflight = skip
+	}
+}
+
+// InstallSubchartNotes will (if true) append the notes of subcharts to those
+// of the chart.
+func InstallSubchartNotes(render bool) InstallOption {
+	return func(opts *options) {
+		opts.instReq.SubchartNotes = render
+	}
+}
+
 // RollbackDisableHooks will disable hooks for a rollback operation
 func RollbackDisableHooks(disable bool) RollbackOption {
 	return func(opts *options) {
@@ -219,6 +380,30 @@ func RollbackVersion(ver int32) RollbackOption {
 	}
 }
 
+// RollbackMetadata records metadata on the revision a rollback creates.
+func RollbackMetadata(metadata map[string]string) RollbackOption {
+	return func(opts *options) {
+		opts.rollbackReq.Metadata = metadata
+	}
+}
+
+// RollbackOverrideLock rolls back a locked release, recording reason on the
+// new revision as the reason for the change.
+func RollbackOverrideLock(reason string) RollbackOption {
+	return func(opts *options) {
+		opts.rollbackReq.OverrideLock = reason
+	}
+}
+
+// RollbackOverrideGates rolls back a release even if the deployment windows
+// or gates of Tiller do not allow it, recording reason on the new revision as
+// the reason for the change.
+func RollbackOverrideGates(reason string) RollbackOption {
+	return func(opts *options) {
+		opts.rollbackReq.OverrideGates = reason
+	}
+}
+
 // UpgradeDisableHooks will disable hooks for an upgrade operation.
 func UpgradeDisableHooks(disable bool) UpdateOption {
 	return func(opts *options) {
@@ -233,6 +418,170 @@ func UpgradeDryRun(dry bool) UpdateOption {
 	}
 }
 
+// UpgradeServerDryRun will (if true) have a dry run send the resources of the
+// upgrade to the Kubernetes API for validation, as a server-side dry run.
+func UpgradeServerDryRun(server bool) UpdateOption {
+	return func(opts *options) {
+		opts.updateReq.ServerDryRun = server
+	}
+}
+
+// UpgradeServerSideApply will (if true) update resources using server-side apply.
+func UpgradeServerSideApply(serverSide bool) UpdateOption {
+	return func(opts *options) {
+		opts.updateReq.ServerSide = serverSide
+	}
+}
+
+// UpgradeTemplateFilter limits an upgrade to the templates matching one of the
+// include patterns (if any) and none of the exclude patterns. Resources from
+// other templates are left as they are.
+func UpgradeTemplateFilter(include, exclude []string) UpdateOption {
+	return func(opts *options) {
+		opts.updateReq.IncludeTemplates = include
+		opts.updateReq.ExcludeTemplates = exclude
+	}
+}
+
+// UpgradeOnlySubcharts limits the upgrade to the templates of the named
+// subcharts. Resources rendered from other templates are left as they are.
+func UpgradeOnlySubcharts(names []string) UpdateOption {
+	return func(opts *options) {
+		opts.updateReq.OnlySubcharts = names
+	}
+}
+
+// UpgradeDisableVersionCheck will (if true) skip the chart's kubeVersion and
+// tillerVersion checks.
+func UpgradeDisableVersionCheck(disable bool) UpdateOption {
+	return func(opts *options) {
+		opts.updateReq.DisableVersionCheck = disable
+	}
+}
+
+// UpgradeSkipPreflight will (if true) skip the pre-flight checks of the chart.
+func UpgradeSkipPreflight(skip bool) UpdateOption {
+	return func(opts *options) {
+		opts.updateReq.SkipPreflight = skip
+	}
+}
+
+// InstallProgress streams the changes made to the release's resources to fn
+// while the release is installed.
+func InstallProgress(fn func(*rls.ResourceEvent)) InstallOption {
+	return func(opts *options) {
+		opts.progress = fn
+	}
+}
+
+// UpgradeProgress streams the changes made to the release's resources to fn
+// while the release is upgraded.
+func UpgradeProgress(fn func(*rls.ResourceEvent)) UpdateOption {
+	return func(opts *options) {
+		opts.progress = fn
+	}
+}
+
+// UpgradeSubchartNotes will (if true) append the notes of subcharts to those
+// of the chart.
+func UpgradeSubchartNotes(render bool) UpdateOption {
+	return func(opts *options) {
+		opts.updateReq.SubchartNotes = render
+	}
+}
+
+// InstallChartSource tells Tiller where the chart to install came from, for it
+// to check the chart against its rules.
+func InstallChartSource(src *rls.ChartSource) InstallOption {
+	return func(opts *options) {
+		opts.instReq.ChartSource = src
+	}
+}
+
+// UpgradeChartSource tells Tiller where the chart to upgrade to came from, for
+// it to check the chart against its rules.
+func UpgradeChartSource(src *rls.ChartSource) UpdateOption {
+	return func(opts *options) {
+		opts.updateReq.ChartSource = src
+	}
+}
+
+// InstallWait makes Tiller wait until the resources of the release are ready,
+// for up to timeout seconds, before it marks the release as deployed. With
+// jobs, Jobs must complete too.
+func InstallWait(wait, jobs bool, timeout int64) InstallOption {
+	return func(opts *options) {
+		opts.instReq.Wait = wait
+		opts.instReq.WaitForJobs = jobs
+		opts.instReq.Timeout = timeout
+	}
+}
+
+// UpgradeWait makes Tiller wait until the resources of the release are ready,
+// for up to timeout seconds, before it marks the release as deployed. With
+// jobs, Jobs must complete too.
+func UpgradeWait(wait, jobs bool, timeout int64) UpdateOption {
+	return func(opts *options) {
+		opts.updateReq.Wait = wait
+		opts.updateReq.WaitForJobs = jobs
+		opts.updateReq.Timeout = timeout
+	}
+}
+
+// UpgradeCanary makes Tiller first run percent of the replicas of each
+// Deployment of the release with the upgrade. The canary is watched for
+// duration seconds once it is ready, and must pass the check at checkURL, if
+// set, before the upgrade is rolled out in full.
+func UpgradeCanary(percent int32, duration int64, checkURL string) UpdateOption {
+	return func(opts *options) {
+		opts.updateReq.CanaryPercent = percent
+		opts.updateReq.CanaryDuration = duration
+		opts.updateReq.CanaryCheckUrl = checkURL
+	}
+}
+
+// UpgradeBlueGreen (if true) runs the Deployments of the upgrade next to the
+// current ones, and leaves the Services of the release as they are until it
+// is promoted.
+func UpgradeBlueGreen(blueGreen bool) UpdateOption {
+	return func(opts *options) {
+		opts.updateReq.BlueGreen = blueGreen
+	}
+}
+
+// InstallMetadata records metadata, such as the commit or CI build being
+// deployed, on the release.
+func InstallMetadata(metadata map[string]string) InstallOption {
+	return func(opts *options) {
+		opts.instReq.Metadata = metadata
+	}
+}
+
+// UpgradeMetadata records metadata, such as the commit or CI build being
+// deployed, on the new revision.
+func UpgradeMetadata(metadata map[string]string) UpdateOption {
+	return func(opts *options) {
+		opts.updateReq.Metadata = metadata
+	}
+}
+
+// UpgradeOverrideLock upgrades a locked release, recording reason on the new
+// revision as the reason for the change.
+func UpgradeOverrideLock(reason string) UpdateOption {
+	return func(opts *options) {
+		opts.updateReq.OverrideLock = reason
+	}
+}
+
+// UpgradeOverrideGates upgrades a release even if the deployment windows or
+// gates of Tiller do not allow it, recording reason on the new revision as
+// the reason for the change.
+func UpgradeOverrideGates(reason string) UpdateOption {
+	return func(opts *options) {
+		opts.updateReq.OverrideGates = reason
+	}
+}
+
 // ContentOption allows setting optional attributes when
 // performing a GetReleaseContent tiller rpc.
 type ContentOption func(*options)
@@ -286,8 +635,176 @@ func WithMaxHistory(max int32) HistoryOption {
 	}
 }
 
+// ImportOption allows configuring optional request data for
+// issuing an ImportRelease rpc.
+type ImportOption func(*options)
+
+// ImportNamespace sets the namespace to import a release into, in place of
+// the one it was exported from.
+func ImportNamespace(ns string) ImportOption {
+	return func(opts *options) {
+		opts.importReq.Namespace = ns
+	}
+}
+
+// ImportApply sets whether the resources of an imported release are created.
+func ImportApply(apply bool) ImportOption {
+	return func(opts *options) {
+		opts.importReq.Apply = apply
+	}
+}
+
+// ImportOverrideGates applies an imported release even if the deployment
+// windows or gates of Tiller do not allow it, recording reason on the release
+// as the reason for the change.
+func ImportOverrideGates(reason string) ImportOption {
+	return func(opts *options) {
+		opts.importReq.OverrideGates = reason
+	}
+}
+
+// DriftOption allows configuring optional request data for
+// issuing a GetReleaseDrift rpc.
+type DriftOption func(*options)
+
+// DriftReleaseVersion sets the revision of the release to compare with the
+// cluster.
+func DriftReleaseVersion(version int32) DriftOption {
+	return func(opts *options) {
+		opts.driftReq.Version = version
+	}
+}
+
+// DriftIgnoreFields sets the paths of the fields that do not count as drift.
+func DriftIgnoreFields(fields []string) DriftOption {
+	return func(opts *options) {
+		opts.driftReq.IgnoreFields = fields
+	}
+}
+
+// SyncOption allows configuring optional request data for
+// issuing a SyncRelease rpc.
+type SyncOption func(*options)
+
+// SyncIgnoreFields sets the paths of the fields that sync leaves alone.
+func SyncIgnoreFields(fields []string) SyncOption {
+	return func(opts *options) {
+		opts.syncReq.IgnoreFields = fields
+	}
+}
+
+// SyncOverrideGates syncs a release even if the deployment windows or gates
+// of Tiller do not allow it, giving reason as the reason for the change.
+func SyncOverrideGates(reason string) SyncOption {
+	return func(opts *options) {
+		opts.syncReq.OverrideGates = reason
+	}
+}
+
+// SetValuesOption allows configuring optional request data for
+// issuing a SetReleaseValues rpc.
+type SetValuesOption func(*options)
+
+// SetValuesReplace replaces the overrides of the release, instead of merging
+// the values into them.
+func SetValuesReplace(replace bool) SetValuesOption {
+	return func(opts *options) {
+		opts.setValuesReq.Replace = replace
+	}
+}
+
+// SetValuesApply upgrades the release with the new overrides right away.
+func SetValuesApply(apply bool) SetValuesOption {
+	return func(opts *options) {
+		opts.setValuesReq.Apply = apply
+	}
+}
+
+// MaintainOption allows configuring optional request data for
+// issuing a MaintainStorage rpc.
+type MaintainOption func(*options)
+
+// MaintainMaxHistory compacts the history of each release to at most max
+// revisions.
+func MaintainMaxHistory(max int32) MaintainOption {
+	return func(opts *options) {
+		opts.maintainReq.MaxHistory = max
+	}
+}
+
+// MaintainDryRun reports what would be removed without removing it.
+func MaintainDryRun(dry bool) MaintainOption {
+	return func(opts *options) {
+		opts.maintainReq.DryRun = dry
+	}
+}
+
+// LockOption allows configuring optional request data for
+// issuing a SetReleaseLock rpc.
+type LockOption func(*options)
+
+// LockReason gives the reason for locking a release.
+func LockReason(reason string) LockOption {
+	return func(opts *options) {
+		opts.lockReq.Reason = reason
+	}
+}
+
+// LockRemove unlocks the release, instead of locking it.
+func LockRemove(unlock bool) LockOption {
+	return func(opts *options) {
+		opts.lockReq.Unlock = unlock
+	}
+}
+
+// PromoteOption allows setting optional attributes when
+// performing a PromoteRelease tiller rpc.
+type PromoteOption func(*options)
+
+// PromoteGracePeriod keeps the Deployments of the previous revision for
+// seconds after the Services are switched over.
+func PromoteGracePeriod(seconds int64) PromoteOption {
+	return func(opts *options) {
+		opts.promoteReq.GracePeriod = seconds
+	}
+}
+
+// PromoteTimeout waits for up to seconds for the Deployments of the revision
+// to be ready.
+func PromoteTimeout(seconds int64) PromoteOption {
+	return func(opts *options) {
+		opts.promoteReq.Timeout = seconds
+	}
+}
+
 // NewContext creates a versioned context.
 func NewContext() context.Context {
-	md := metadata.Pairs("x-helm-api-client", version.Version)
+	return metadata.NewContext(context.TODO(), versionMetadata())
+}
+
+// versionMetadata tells Tiller the release and API versions of the client.
+func versionMetadata() metadata.MD {
+	return metadata.Pairs(
+		"x-helm-api-client", version.Version,
+		"x-helm-api-version", strconv.Itoa(version.APIVersion),
+	)
+}
+
+// context creates a versioned context carrying the user token, the identity
+// to impersonate and the request ID, if any.
+func (o *options) context() context.Context {
+	md := versionMetadata()
+	if o.userToken != "" {
+		md["x-helm-user-token"] = []string{o.userToken}
+	}
+	if o.asUser != "" {
+		md["x-helm-as-user"] = []string{o.asUser}
+	}
+	if len(o.asGroups) > 0 {
+		md["x-helm-as-group"] = o.asGroups
+	}
+	if o.requestID != "" {
+		md["x-helm-request-id"] = []string{o.requestID}
+	}
 	return metadata.NewContext(context.TODO(), md)
 }
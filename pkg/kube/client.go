@@ -18,16 +18,20 @@ package kube // import "k8s.io/helm/pkg/kube"
 
 import (
 	"bytes"
+	"encoding/json"
 	goerrors "errors"
 	"fmt"
 	"io"
 	"log"
+	"net/http"
 	"reflect"
 	"strings"
 	"time"
 
 	"k8s.io/kubernetes/pkg/api"
 	"k8s.io/kubernetes/pkg/api/errors"
+	"k8s.io/kubernetes/pkg/api/meta"
+	apiunversioned "k8s.io/kubernetes/pkg/api/unversioned"
 	"k8s.io/kubernetes/pkg/apimachinery/registered"
 	"k8s.io/kubernetes/pkg/apis/batch"
 	"k8s.io/kubernetes/pkg/client/unversioned"
@@ -35,6 +39,7 @@ import (
 	"k8s.io/kubernetes/pkg/kubectl"
 	cmdutil "k8s.io/kubernetes/pkg/kubectl/cmd/util"
 	"k8s.io/kubernetes/pkg/kubectl/resource"
+	"k8s.io/kubernetes/pkg/labels"
 	"k8s.io/kubernetes/pkg/runtime"
 	"k8s.io/kubernetes/pkg/util/strategicpatch"
 	"k8s.io/kubernetes/pkg/util/yaml"
@@ -44,6 +49,15 @@ import (
 // ErrNoObjectsVisited indicates that during a visit operation, no matching objects were found.
 var ErrNoObjectsVisited = goerrors.New("no objects visited")
 
+// ApplyPatchType is the patch type used for server-side apply requests.
+//
+// Kubernetes releases that predate server-side apply reject it as an
+// unsupported media type.
+const ApplyPatchType api.PatchType = "application/apply-patch+yaml"
+
+// FieldManager is the manager name recorded for fields set via server-side apply.
+const FieldManager = "helm"
+
 // Client represents a client capable of communicating with the Kubernetes API.
 type Client struct {
 	*cmdutil.Factory
@@ -58,6 +72,34 @@ type Client struct {
 	Validate bool
 	// SchemaCacheDir is the path for loading cached schema.
 	SchemaCacheDir string
+	// Reporter, if set, is told about every change made to a resource.
+	Reporter ProgressReporter
+	// Backoff is the retry policy for requests that fail with a transient
+	// error. If Steps is zero, DefaultBackoff is used.
+	Backoff Backoff
+	// Debug logs every retry.
+	Debug bool
+	// Owner, if set, is the release revision the resources belong to. See
+	// OwnedBy.
+	Owner *Owner
+	// AnnotateSources sets AnnotationSource on the resources of an owner,
+	// to the template named by the "# Source:" comment of each one.
+	AnnotateSources bool
+	// Log, if set, writes the log messages of the client instead of
+	// log.Output.
+	Log func(calldepth int, s string) error
+
+	// config is the configuration the client was created with.
+	config clientcmd.ClientConfig
+}
+
+// ProgressReporter receives the changes a Client makes to resources, as they
+// are made.
+type ProgressReporter interface {
+	// Report is called after action ("created", "patched", "unchanged",
+	// "applied", "deleted" or "ready") was performed on a resource. An error
+	// is logged; it does not fail the change.
+	Report(action, kind, name, namespace string) error
 }
 
 // New create a new Client
@@ -67,6 +109,41 @@ func New(config clientcmd.ClientConfig) *Client {
 		IncludeThirdPartyAPIs: true,
 		Validate:              true,
 		SchemaCacheDir:        clientcmd.RecommendedSchemaFile,
+		AnnotateSources:       true,
+		config:                config,
+	}
+}
+
+// WithReporter returns a copy of the client that reports its changes to r.
+func (c *Client) WithReporter(r ProgressReporter) *Client {
+	cp := *c
+	cp.Reporter = r
+	return &cp
+}
+
+// WithLog returns a copy of the client that writes its log messages with
+// output.
+func (c *Client) WithLog(output func(calldepth int, s string) error) *Client {
+	cp := *c
+	cp.Log = output
+	return &cp
+}
+
+func (c *Client) logf(format string, v ...interface{}) {
+	output := log.Output
+	if c.Log != nil {
+		output = c.Log
+	}
+	output(2, fmt.Sprintf(format, v...))
+}
+
+// report tells the reporter, if there is one, that action was performed on
+// the resource described by info.
+func (c *Client) report(action string, info *resource.Info) {
+	if c.Reporter != nil {
+		if err := c.Reporter.Report(action, info.Mapping.GroupVersionKind.Kind, info.Name, info.Namespace); err != nil {
+			c.logf("warning: failed to report progress: %s", err)
+		}
 	}
 }
 
@@ -99,13 +176,22 @@ func (c *Client) Create(namespace string, reader io.Reader) error {
 	if err := c.ensureNamespace(namespace); err != nil {
 		return err
 	}
-	return perform(c, namespace, reader, createResource)
+	reader, srcs := c.sources(reader)
+	return perform(c, namespace, reader, func(info *resource.Info) error {
+		c.label(info)
+		srcs.annotate(info)
+		if err := c.createResource(info); err != nil {
+			return err
+		}
+		c.report("created", info)
+		return nil
+	})
 }
 
 func (c *Client) newBuilder(namespace string, reader io.Reader) *resource.Builder {
 	schema, err := c.Validator(c.Validate, c.SchemaCacheDir)
 	if err != nil {
-		log.Printf("warning: failed to load schema: %s", err)
+		c.logf("warning: failed to load schema: %s", err)
 	}
 	return c.NewBuilder(c.IncludeThirdPartyAPIs).
 		ContinueOnError().
@@ -124,7 +210,7 @@ func (c *Client) Get(namespace string, reader io.Reader) (string, error) {
 	// that when we print them, they come looking good (headers apply to subgroups, etc.)
 	objs := make(map[string][]runtime.Object)
 	err := perform(c, namespace, reader, func(info *resource.Info) error {
-		log.Printf("Doing get for: '%s'", info.Name)
+		c.logf("Doing get for: '%s'", info.Name)
 		obj, err := resource.NewHelper(info.Client, info.Mapping).Get(info.Namespace, info.Name, info.Export)
 		if err != nil {
 			return err
@@ -132,7 +218,7 @@ func (c *Client) Get(namespace string, reader io.Reader) (string, error) {
 		// We need to grab the ObjectReference so we can correctly group the objects.
 		or, err := api.GetReference(obj)
 		if err != nil {
-			log.Printf("FAILED GetReference for: %#v\n%v", obj, err)
+			c.logf("FAILED GetReference for: %#v\n%v", obj, err)
 			return err
 		}
 
@@ -157,7 +243,7 @@ func (c *Client) Get(namespace string, reader io.Reader) (string, error) {
 		for _, o := range ot {
 			err = p.PrintObj(o, buf)
 			if err != nil {
-				log.Printf("failed to print object type '%s', object: '%s' :\n %v", t, o, err)
+				c.logf("failed to print object type '%s', object: '%s' :\n %v", t, o, err)
 				return "", err
 			}
 		}
@@ -169,6 +255,55 @@ func (c *Client) Get(namespace string, reader io.Reader) (string, error) {
 	return buf.String(), err
 }
 
+// Lookup fetches a live object by API version, kind, namespace and name and
+// returns it as a generic map, in the shape it has in YAML manifests.
+//
+// If name is empty, all objects of that kind in the namespace are returned as
+// a list (with the objects under "items"). A missing object yields an empty
+// map rather than an error.
+func (c *Client) Lookup(apiVersion, kind, namespace, name string) (map[string]interface{}, error) {
+	gv, err := apiunversioned.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return nil, err
+	}
+	mapper, _ := c.Object(c.IncludeThirdPartyAPIs)
+	mapping, err := mapper.RESTMapping(gv.WithKind(kind).GroupKind(), gv.Version)
+	if err != nil {
+		return nil, err
+	}
+	client, err := c.ClientForMapping(mapping)
+	if err != nil {
+		return nil, err
+	}
+	if mapping.Scope.Name() != meta.RESTScopeNameNamespace {
+		namespace = ""
+	}
+
+	helper := resource.NewHelper(client, mapping)
+	var obj runtime.Object
+	if name == "" {
+		obj, err = helper.List(namespace, apiVersion, labels.Everything(), false)
+	} else {
+		obj, err = helper.Get(namespace, name, false)
+	}
+	switch {
+	case errors.IsNotFound(err):
+		return map[string]interface{}{}, nil
+	case errors.IsForbidden(err):
+		return nil, fmt.Errorf("lookup of %s %q in namespace %q is forbidden: Tiller's service account needs get and list access to %s", kind, name, namespace, mapping.Resource)
+	case err != nil:
+		return nil, err
+	}
+
+	data, err := runtime.Encode(c.JSONEncoder(), obj)
+	if err != nil {
+		return nil, err
+	}
+	res := map[string]interface{}{}
+	err = json.Unmarshal(data, &res)
+	return res, err
+}
+
 // Update reads in the current configuration and a target configuration from io.reader
 //  and creates resources that don't already exists, updates resources that have been modified
 //  in the target configuration and deletes resources from the current configuration that are
@@ -181,6 +316,7 @@ func (c *Client) Update(namespace string, currentReader, targetReader io.Reader)
 		return fmt.Errorf("failed decoding reader into objects: %s", err)
 	}
 
+	targetReader, srcs := c.sources(targetReader)
 	target := c.newBuilder(namespace, targetReader).Do()
 	if target.Err() != nil {
 		return fmt.Errorf("failed decoding reader into objects: %s", target.Err())
@@ -195,34 +331,44 @@ func (c *Client) Update(namespace string, currentReader, targetReader io.Reader)
 			return err
 		}
 
-		helper := resource.NewHelper(info.Client, info.Mapping)
-		if _, err := helper.Get(info.Namespace, info.Name, info.Export); err != nil {
+		c.label(info)
+		srcs.annotate(info)
+		live, err := c.getLive(info)
+		if err != nil {
 			if !errors.IsNotFound(err) {
 				return fmt.Errorf("Could not get information about the resource: err: %s", err)
 			}
 
 			// Since the resource does not exist, create it.
-			if err := createResource(info); err != nil {
+			if err := c.createResource(info); err != nil {
 				return fmt.Errorf("failed to create resource: %s", err)
 			}
 
 			kind := info.Mapping.GroupVersionKind.Kind
-			log.Printf("Created a new %s called %s\n", kind, info.Name)
+			c.logf("Created a new %s called %s\n", kind, info.Name)
+			c.report("created", info)
 			return nil
 		}
+		if err := c.checkOwner(info, live); err != nil {
+			return err
+		}
 
 		currentObj, err := getCurrentObject(info, currentInfos)
 		if err != nil {
 			return err
 		}
 
-		if err := updateResource(info, currentObj); err != nil {
+		err = c.retry("patch", info, func() error { return updateResource(info, currentObj) })
+		if err != nil {
 			if alreadyExistErr, ok := err.(ErrAlreadyExists); ok {
-				log.Printf(alreadyExistErr.errorMsg)
+				c.logf(alreadyExistErr.errorMsg)
+				c.report("unchanged", info)
 			} else {
-				log.Printf("error updating the resource %s:\n\t %v", info.Name, err)
+				c.logf("error updating the resource %s:\n\t %v", info.Name, err)
 				updateErrors = append(updateErrors, err.Error())
 			}
+		} else {
+			c.report("patched", info)
 		}
 
 		return nil
@@ -233,7 +379,83 @@ func (c *Client) Update(namespace string, currentReader, targetReader io.Reader)
 	} else if len(updateErrors) != 0 {
 		return fmt.Errorf(strings.Join(updateErrors, " && "))
 	}
-	deleteUnwantedResources(currentInfos, targetInfos)
+	c.deleteUnwantedResources(currentInfos, targetInfos)
+	return nil
+}
+
+// Apply creates or updates kubernetes resources from an io.reader using server-side apply.
+//
+// Each resource is sent to the API server in full, and the server merges it into
+// the live object while recording FieldManager as the owner of the fields it sets.
+// Resources that are present in currentReader but not in targetReader are deleted.
+// currentReader may be nil, in which case nothing is deleted.
+//
+// Namespace will set the namespace
+func (c *Client) Apply(namespace string, currentReader, targetReader io.Reader) error {
+	if err := c.ensureNamespace(namespace); err != nil {
+		return err
+	}
+
+	var currentInfos []*resource.Info
+	if currentReader != nil {
+		var err error
+		if currentInfos, err = c.newBuilder(namespace, currentReader).Do().Infos(); err != nil {
+			return fmt.Errorf("failed decoding reader into objects: %s", err)
+		}
+	}
+
+	targetInfos := []*resource.Info{}
+	targetReader, srcs := c.sources(targetReader)
+	err := perform(c, namespace, targetReader, func(info *resource.Info) error {
+		targetInfos = append(targetInfos, info)
+		c.label(info)
+		srcs.annotate(info)
+		if err := c.checkLiveOwner(info); err != nil {
+			return err
+		}
+		if err := c.retry("apply", info, func() error { return applyResource(info, false) }); err != nil {
+			return fmt.Errorf("failed to apply %s: %s", info.Name, err)
+		}
+		c.logf("Applied %s %s\n", info.Mapping.GroupVersionKind.Kind, info.Name)
+		c.report("applied", info)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	c.deleteUnwantedResources(currentInfos, targetInfos)
+	return nil
+}
+
+// DryRun sends the resources from an io.reader to the API server as a
+// server-side dry run. The server validates them and runs its admission
+// webhooks as it would for a real apply, but persists nothing.
+//
+// Every resource is sent, and the errors of all of them are returned together.
+//
+// Namespace will set the namespace
+func (c *Client) DryRun(namespace string, reader io.Reader) error {
+	var errs []string
+	reader, srcs := c.sources(reader)
+	err := perform(c, namespace, reader, func(info *resource.Info) error {
+		c.label(info)
+		srcs.annotate(info)
+		err := c.checkLiveOwner(info)
+		if err == nil {
+			err = c.retry("dry-run", info, func() error { return applyResource(info, true) })
+		}
+		if err != nil {
+			kind := info.Mapping.GroupVersionKind.Kind
+			errs = append(errs, fmt.Sprintf("%s %q: %s", kind, info.Name, err))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("server-side dry run failed:\n%s", strings.Join(errs, "\n"))
+	}
 	return nil
 }
 
@@ -241,29 +463,130 @@ func (c *Client) Update(namespace string, currentReader, targetReader io.Reader)
 //
 // Namespace will set the namespace
 func (c *Client) Delete(namespace string, reader io.Reader) error {
-	return perform(c, namespace, reader, func(info *resource.Info) error {
-		log.Printf("Starting delete for %s %s", info.Name, info.Mapping.GroupVersionKind.Kind)
+	return c.DeleteWithOptions(namespace, reader, DeleteOptions{})
+}
+
+// Propagation policies of DeleteOptions.
+const (
+	PropagationBackground = "background"
+	PropagationForeground = "foreground"
+	PropagationOrphan     = "orphan"
+)
+
+// DeleteOptions controls how DeleteWithOptions deletes resources.
+type DeleteOptions struct {
+	// Propagation is what happens to the objects that the Kubernetes garbage
+	// collector considers dependents of a deleted resource, such as the Pods
+	// of a ReplicaSet: PropagationBackground deletes them after the resource,
+	// PropagationForeground before it, and PropagationOrphan leaves them
+	// alone. If empty, resources are deleted the way kubectl deletes them.
+	Propagation string
+	// Wait, if true, waits until the resources are gone from the cluster.
+	// With foreground propagation, that is once their dependents are too.
+	Wait bool
+	// Timeout is how long to wait. It defaults to DefaultWaitTimeout.
+	Timeout time.Duration
+}
+
+// DeleteWithOptions deletes the resources in reader, as opts asks.
+//
+// Namespace will set the namespace
+func (c *Client) DeleteWithOptions(namespace string, reader io.Reader, opts DeleteOptions) error {
+	policy, err := propagationPolicy(opts.Propagation)
+	if err != nil {
+		return err
+	}
+	var deleted []*resource.Info
+	err = perform(c, namespace, reader, func(info *resource.Info) error {
+		c.logf("Starting delete for %s %s", info.Name, info.Mapping.GroupVersionKind.Kind)
+		deleted = append(deleted, info)
+
+		if policy != "" {
+			err := c.retry("delete", info, func() error {
+				return c.skipIfNotFound(deleteResourceWithPolicy(info, policy))
+			})
+			return c.reportDeleted(info, err)
+		}
 
 		reaper, err := c.Reaper(info.Mapping)
 		if err != nil {
 			// If there is no reaper for this resources, delete it.
 			if kubectl.IsNoSuchReaperError(err) {
-				err := resource.NewHelper(info.Client, info.Mapping).Delete(info.Namespace, info.Name)
-				return skipIfNotFound(err)
+				err := c.retry("delete", info, func() error {
+					return c.skipIfNotFound(resource.NewHelper(info.Client, info.Mapping).Delete(info.Namespace, info.Name))
+				})
+				return c.reportDeleted(info, err)
 			}
 
 			return err
 		}
 
-		log.Printf("Using reaper for deleting %s", info.Name)
-		err = reaper.Stop(info.Namespace, info.Name, 0, nil)
-		return skipIfNotFound(err)
+		c.logf("Using reaper for deleting %s", info.Name)
+		err = c.retry("delete", info, func() error {
+			return c.skipIfNotFound(reaper.Stop(info.Namespace, info.Name, 0, nil))
+		})
+		return c.reportDeleted(info, err)
 	})
+	if err != nil || !opts.Wait {
+		return err
+	}
+	return c.waitForDeletion(deleted, opts.Timeout)
+}
+
+// propagationPolicy returns the Kubernetes name of a propagation policy of
+// DeleteOptions.
+func propagationPolicy(p string) (string, error) {
+	switch strings.ToLower(p) {
+	case "":
+		return "", nil
+	case PropagationBackground:
+		return "Background", nil
+	case PropagationForeground:
+		return "Foreground", nil
+	case PropagationOrphan:
+		return "Orphan", nil
+	}
+	return "", fmt.Errorf("unknown propagation policy %q, must be %s, %s or %s", p, PropagationBackground, PropagationForeground, PropagationOrphan)
 }
 
-func skipIfNotFound(err error) error {
+// waitForDeletion waits until the resources described by infos are gone.
+func (c *Client) waitForDeletion(infos []*resource.Info, timeout time.Duration) error {
+	if timeout == 0 {
+		timeout = DefaultWaitTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	for _, info := range infos {
+		kind := info.Mapping.GroupVersionKind.Kind
+		for {
+			_, err := c.getLive(info)
+			if errors.IsNotFound(err) {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			if time.Now().After(deadline) {
+				return fmt.Errorf("timed out after %s waiting for %s %q to be deleted", timeout, kind, info.Name)
+			}
+			c.logf("Waiting for %s %q to be deleted", kind, info.Name)
+			time.Sleep(waitInterval)
+		}
+	}
+	return nil
+}
+
+// reportDeleted reports the deletion of a resource, unless err is set, and
+// returns err.
+func (c *Client) reportDeleted(info *resource.Info, err error) error {
+	if err == nil {
+		c.report("deleted", info)
+	}
+	return err
+}
+
+func (c *Client) skipIfNotFound(err error) error {
 	if err != nil && errors.IsNotFound(err) {
-		log.Printf("%v", err)
+		c.logf("%v", err)
 		return nil
 	}
 	return err
@@ -284,7 +607,13 @@ func skipIfNotFound(err error) error {
 func (c *Client) WatchUntilReady(namespace string, reader io.Reader) error {
 	// For jobs, there's also the option to do poll c.Jobs(namespace).Get():
 	// https://github.com/adamreese/kubernetes/blob/master/test/e2e/job.go#L291-L300
-	return perform(c, namespace, reader, watchUntilReady)
+	return perform(c, namespace, reader, func(info *resource.Info) error {
+		if err := c.watchUntilReady(info); err != nil {
+			return err
+		}
+		c.report("ready", info)
+		return nil
+	})
 }
 
 func perform(c *Client, namespace string, reader io.Reader, fn ResourceActorFunc) error {
@@ -303,15 +632,74 @@ func perform(c *Client, namespace string, reader io.Reader, fn ResourceActorFunc
 	return nil
 }
 
+// createResource creates the resource described by info, retrying transient
+// errors. A retry that finds the resource already exists means that an
+// earlier try succeeded after all.
+func (c *Client) createResource(info *resource.Info) error {
+	retried := false
+	err := c.retry("create", info, func() error {
+		err := createResource(info)
+		if retried && errors.IsAlreadyExists(err) {
+			return nil
+		}
+		retried = true
+		return err
+	})
+	if errors.IsAlreadyExists(err) {
+		if oerr := c.checkLiveOwner(info); oerr != nil {
+			return oerr
+		}
+	}
+	return err
+}
+
 func createResource(info *resource.Info) error {
 	_, err := resource.NewHelper(info.Client, info.Mapping).Create(info.Namespace, true, info.Object)
 	return err
 }
 
+// applyResource sends info to the API server with server-side apply. A dry
+// run takes over fields set by other managers instead of reporting conflicts,
+// as it changes nothing anyway.
+func applyResource(info *resource.Info, dryRun bool) error {
+	encoder := api.Codecs.LegacyCodec(registered.EnabledVersions()...)
+	data, err := runtime.Encode(encoder, info.Object)
+	if err != nil {
+		return err
+	}
+
+	req := info.Client.Patch(ApplyPatchType).
+		NamespaceIfScoped(info.Namespace, info.Namespaced()).
+		Resource(info.Mapping.Resource).
+		Name(info.Name).
+		Param("fieldManager", FieldManager)
+	if dryRun {
+		req = req.Param("dryRun", "All").Param("force", "true")
+	}
+	_, err = req.Body(data).Do().Get()
+	if se, ok := err.(*errors.StatusError); ok && se.Status().Code == http.StatusUnsupportedMediaType {
+		return fmt.Errorf("server-side apply is not supported by this cluster: %s", err)
+	}
+	return err
+}
+
 func deleteResource(info *resource.Info) error {
 	return resource.NewHelper(info.Client, info.Mapping).Delete(info.Namespace, info.Name)
 }
 
+// deleteResourceWithPolicy deletes the resource described by info with a
+// propagation policy, which the client library does not know of yet.
+func deleteResourceWithPolicy(info *resource.Info, policy string) error {
+	body := fmt.Sprintf(`{"kind":"DeleteOptions","apiVersion":"v1","propagationPolicy":%q}`, policy)
+	return info.Client.Delete().
+		NamespaceIfScoped(info.Namespace, info.Namespaced()).
+		Resource(info.Mapping.Resource).
+		Name(info.Name).
+		Body([]byte(body)).
+		Do().
+		Error()
+}
+
 func updateResource(target *resource.Info, currentObj runtime.Object) error {
 
 	encoder := api.Codecs.LegacyCodec(registered.EnabledVersions()...)
@@ -350,14 +738,14 @@ func updateResource(target *resource.Info, currentObj runtime.Object) error {
 	return err
 }
 
-func watchUntilReady(info *resource.Info) error {
+func (c *Client) watchUntilReady(info *resource.Info) error {
 	w, err := resource.NewHelper(info.Client, info.Mapping).WatchSingle(info.Namespace, info.Name, info.ResourceVersion)
 	if err != nil {
 		return err
 	}
 
 	kind := info.Mapping.GroupVersionKind.Kind
-	log.Printf("Watching for changes to %s %s", kind, info.Name)
+	c.logf("Watching for changes to %s %s", kind, info.Name)
 	timeout := time.Minute * 5
 
 	// What we watch for depends on the Kind.
@@ -373,17 +761,17 @@ func watchUntilReady(info *resource.Info) error {
 			// we get. We care mostly about jobs, where what we want to see is
 			// the status go into a good state. For other types, like ReplicaSet
 			// we don't really do anything to support these as hooks.
-			log.Printf("Add/Modify event for %s: %v", info.Name, e.Type)
+			c.logf("Add/Modify event for %s: %v", info.Name, e.Type)
 			if kind == "Job" {
-				return waitForJob(e, info.Name)
+				return c.waitForJob(e, info.Name)
 			}
 			return true, nil
 		case watch.Deleted:
-			log.Printf("Deleted event for %s", info.Name)
+			c.logf("Deleted event for %s", info.Name)
 			return true, nil
 		case watch.Error:
 			// Handle error and return with an error.
-			log.Printf("Error event for %s", info.Name)
+			c.logf("Error event for %s", info.Name)
 			return true, fmt.Errorf("Failed to deploy %s", info.Name)
 		default:
 			return false, nil
@@ -395,7 +783,7 @@ func watchUntilReady(info *resource.Info) error {
 // waitForJob is a helper that waits for a job to complete.
 //
 // This operates on an event returned from a watcher.
-func waitForJob(e watch.Event, name string) (bool, error) {
+func (c *Client) waitForJob(e watch.Event, name string) (bool, error) {
 	o, ok := e.Object.(*batch.Job)
 	if !ok {
 		return true, fmt.Errorf("Expected %s to be a *batch.Job, got %T", name, o)
@@ -409,7 +797,7 @@ func waitForJob(e watch.Event, name string) (bool, error) {
 		}
 	}
 
-	log.Printf("%s: Jobs active: %d, jobs failed: %d, jobs succeeded: %d", name, o.Status.Active, o.Status.Failed, o.Status.Succeeded)
+	c.logf("%s: Jobs active: %d, jobs failed: %d, jobs succeeded: %d", name, o.Status.Active, o.Status.Failed, o.Status.Succeeded)
 	return false, nil
 }
 
@@ -428,13 +816,19 @@ func (c *Client) ensureNamespace(namespace string) error {
 	return nil
 }
 
-func deleteUnwantedResources(currentInfos, targetInfos []*resource.Info) {
+func (c *Client) deleteUnwantedResources(currentInfos, targetInfos []*resource.Info) {
 	for _, cInfo := range currentInfos {
 		if _, ok := findMatchingInfo(cInfo, targetInfos); !ok {
-			log.Printf("Deleting %s...", cInfo.Name)
-			if err := deleteResource(cInfo); err != nil {
-				log.Printf("Failed to delete %s, err: %s", cInfo.Name, err)
+			if err := c.checkLiveOwner(cInfo); err != nil {
+				c.logf("Leaving %s alone: %s", cInfo.Name, err)
+				continue
+			}
+			c.logf("Deleting %s...", cInfo.Name)
+			if err := c.retry("delete", cInfo, func() error { return deleteResource(cInfo) }); err != nil {
+				c.logf("Failed to delete %s, err: %s", cInfo.Name, err)
+				continue
 			}
+			c.report("deleted", cInfo)
 		}
 	}
 }
@@ -22,6 +22,7 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strings"
 	"testing"
 
@@ -67,6 +68,216 @@ func TestUpdateResource(t *testing.T) {
 	}
 }
 
+func TestApplyResource(t *testing.T) {
+	info := createFakeInfo("nginx", map[string]string{"app": "nginx"})
+	marshaledObj, err := runtime.Encode(testapi.Default.Codec(), info.Object)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var method, manager string
+	info.Client = &fake.RESTClient{
+		NegotiatedSerializer: testapi.Default.NegotiatedSerializer(),
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			method = req.Method
+			manager = req.URL.Query().Get("fieldManager")
+			header := http.Header{}
+			header.Set("Content-Type", runtime.ContentTypeJSON)
+			return &http.Response{
+				StatusCode: 200,
+				Header:     header,
+				Body:       ioutil.NopCloser(bytes.NewReader(marshaledObj)),
+			}, nil
+		})}
+
+	if err := applyResource(info, false); err != nil {
+		t.Fatal(err)
+	}
+	if method != "PATCH" {
+		t.Errorf("expected PATCH request, got %s", method)
+	}
+	if manager != FieldManager {
+		t.Errorf("expected field manager %q, got %q", FieldManager, manager)
+	}
+}
+
+func TestApplyResourceDryRun(t *testing.T) {
+	info := createFakeInfo("nginx", map[string]string{"app": "nginx"})
+	marshaledObj, err := runtime.Encode(testapi.Default.Codec(), info.Object)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var query url.Values
+	info.Client = &fake.RESTClient{
+		NegotiatedSerializer: testapi.Default.NegotiatedSerializer(),
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			query = req.URL.Query()
+			header := http.Header{}
+			header.Set("Content-Type", runtime.ContentTypeJSON)
+			return &http.Response{
+				StatusCode: 200,
+				Header:     header,
+				Body:       ioutil.NopCloser(bytes.NewReader(marshaledObj)),
+			}, nil
+		})}
+
+	if err := applyResource(info, true); err != nil {
+		t.Fatal(err)
+	}
+	if query.Get("dryRun") != "All" {
+		t.Errorf("expected a dry run, got query %q", query.Encode())
+	}
+	if query.Get("force") != "true" {
+		t.Errorf("expected a dry run to force conflicts, got query %q", query.Encode())
+	}
+}
+
+func TestDeleteResourceWithPolicy(t *testing.T) {
+	info := createFakeInfo("nginx", nil)
+
+	var method string
+	var body map[string]interface{}
+	info.Client = &fake.RESTClient{
+		NegotiatedSerializer: testapi.Default.NegotiatedSerializer(),
+		Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+			method = req.Method
+			if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+				t.Errorf("could not decode the delete options: %s", err)
+			}
+			header := http.Header{}
+			header.Set("Content-Type", runtime.ContentTypeJSON)
+			return &http.Response{
+				StatusCode: 200,
+				Header:     header,
+				Body:       ioutil.NopCloser(strings.NewReader(`{"kind":"Status","apiVersion":"v1","status":"Success"}`)),
+			}, nil
+		})}
+
+	if err := deleteResourceWithPolicy(info, "Foreground"); err != nil {
+		t.Fatal(err)
+	}
+	if method != "DELETE" {
+		t.Errorf("expected DELETE request, got %s", method)
+	}
+	if body["kind"] != "DeleteOptions" || body["propagationPolicy"] != "Foreground" {
+		t.Errorf("unexpected delete options: %v", body)
+	}
+}
+
+func TestPropagationPolicy(t *testing.T) {
+	for in, expect := range map[string]string{"": "", "background": "Background", "Foreground": "Foreground", "orphan": "Orphan"} {
+		got, err := propagationPolicy(in)
+		if err != nil || got != expect {
+			t.Errorf("expected %q to be %q, got %q (%v)", in, expect, got, err)
+		}
+	}
+	if _, err := propagationPolicy("sideways"); err == nil {
+		t.Error("expected an unknown policy to be rejected")
+	}
+}
+
+func TestLookup(t *testing.T) {
+	pod := &api.Pod{
+		TypeMeta:   unversioned.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: api.ObjectMeta{Name: "nginx", Namespace: "test"},
+	}
+	marshaledObj, err := runtime.Encode(testapi.Default.Codec(), pod)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var path string
+	c := New(nil)
+	c.IncludeThirdPartyAPIs = false
+	c.ClientForMapping = func(mapping *meta.RESTMapping) (resource.RESTClient, error) {
+		return &fake.RESTClient{
+			NegotiatedSerializer: testapi.Default.NegotiatedSerializer(),
+			Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+				path = req.URL.Path
+				header := http.Header{}
+				header.Set("Content-Type", runtime.ContentTypeJSON)
+				if strings.HasSuffix(req.URL.Path, "/missing") {
+					return &http.Response{StatusCode: 404, Header: header, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+				}
+				return &http.Response{
+					StatusCode: 200,
+					Header:     header,
+					Body:       ioutil.NopCloser(bytes.NewReader(marshaledObj)),
+				}, nil
+			}),
+		}, nil
+	}
+
+	obj, err := c.Lookup("v1", "Pod", "test", "nginx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != "/namespaces/test/pods/nginx" {
+		t.Errorf("unexpected request path %q", path)
+	}
+	if md, ok := obj["metadata"].(map[string]interface{}); !ok || md["name"] != "nginx" {
+		t.Errorf("expected to look up pod nginx, got %v", obj)
+	}
+
+	obj, err = c.Lookup("v1", "Pod", "test", "missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(obj) != 0 {
+		t.Errorf("expected an empty object for a missing pod, got %v", obj)
+	}
+}
+
+type recordingReporter []string
+
+func (r *recordingReporter) Report(action, kind, name, namespace string) error {
+	*r = append(*r, action+" "+kind+"/"+name+" in "+namespace)
+	return nil
+}
+
+func TestUpdateReportsProgress(t *testing.T) {
+	svc := &api.Service{
+		TypeMeta:   unversioned.TypeMeta{APIVersion: "v1", Kind: "Service"},
+		ObjectMeta: api.ObjectMeta{Name: "my-service", Namespace: "test"},
+	}
+	marshaledObj, err := runtime.Encode(testapi.Default.Codec(), svc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rep := &recordingReporter{}
+	c := New(nil).WithReporter(rep)
+	c.IncludeThirdPartyAPIs = false
+	c.Validator = func(validate bool, cacheDir string) (validation.Schema, error) {
+		return validation.NullSchema{}, nil
+	}
+	c.ClientForMapping = func(mapping *meta.RESTMapping) (resource.RESTClient, error) {
+		return &fake.RESTClient{
+			NegotiatedSerializer: testapi.Default.NegotiatedSerializer(),
+			Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+				header := http.Header{}
+				header.Set("Content-Type", runtime.ContentTypeJSON)
+				if req.Method == "GET" {
+					return &http.Response{StatusCode: 404, Header: header, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+				}
+				return &http.Response{
+					StatusCode: 201,
+					Header:     header,
+					Body:       ioutil.NopCloser(bytes.NewReader(marshaledObj)),
+				}, nil
+			}),
+		}, nil
+	}
+
+	if err := c.Update("test", strings.NewReader(""), strings.NewReader(testServiceManifest)); err != nil {
+		t.Fatal(err)
+	}
+	if expect := []string{"created Service/my-service in test"}; strings.Join(*rep, ",") != strings.Join(expect, ",") {
+		t.Errorf("expected %v, got %v", expect, *rep)
+	}
+}
+
 func TestPerform(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -0,0 +1,104 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "k8s.io/helm/pkg/kube"
+
+import (
+	"net/http"
+
+	"github.com/spf13/pflag"
+	"k8s.io/kubernetes/pkg/apis/authentication"
+	"k8s.io/kubernetes/pkg/client/restclient"
+	"k8s.io/kubernetes/pkg/client/unversioned/clientcmd"
+	clientcmdapi "k8s.io/kubernetes/pkg/client/unversioned/clientcmd/api"
+	cmdutil "k8s.io/kubernetes/pkg/kubectl/cmd/util"
+)
+
+// Impersonate returns a copy of the client that acts on the cluster as user,
+// a member of groups, instead of as the owner of its own credentials.
+//
+// The credentials of the client must be allowed to impersonate users and
+// groups. The API server then authorizes every request as if user had made it.
+func (c *Client) Impersonate(user string, groups []string) *Client {
+	config := c.config
+	if config == nil {
+		// The same configuration cmdutil.NewFactory falls back to.
+		config = cmdutil.DefaultClientConfig(pflag.NewFlagSet("", pflag.ContinueOnError))
+	}
+	cp := *c
+	cp.Factory = cmdutil.NewFactory(&impersonatingConfig{base: config, user: user, groups: groups})
+	return &cp
+}
+
+// impersonatingConfig is a ClientConfig whose client configurations
+// impersonate a user.
+type impersonatingConfig struct {
+	base   clientcmd.ClientConfig
+	user   string
+	groups []string
+}
+
+func (i *impersonatingConfig) RawConfig() (clientcmdapi.Config, error) {
+	return i.base.RawConfig()
+}
+
+func (i *impersonatingConfig) Namespace() (string, bool, error) {
+	return i.base.Namespace()
+}
+
+func (i *impersonatingConfig) ConfigAccess() clientcmd.ConfigAccess {
+	return i.base.ConfigAccess()
+}
+
+func (i *impersonatingConfig) ClientConfig() (*restclient.Config, error) {
+	cfg, err := i.base.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	cfg.Impersonate = i.user
+
+	// The client configuration has no field for the groups, so they are added
+	// to the requests by the transport.
+	if len(i.groups) > 0 {
+		wrap := cfg.WrapTransport
+		cfg.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
+			if wrap != nil {
+				rt = wrap(rt)
+			}
+			return &groupImpersonator{groups: i.groups, delegate: rt}
+		}
+	}
+	return cfg, nil
+}
+
+// groupImpersonator sets the groups to impersonate on every request.
+type groupImpersonator struct {
+	groups   []string
+	delegate http.RoundTripper
+}
+
+func (g *groupImpersonator) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := new(http.Request)
+	*r = *req
+	r.Header = make(http.Header, len(req.Header)+1)
+	for k, v := range req.Header {
+		r.Header[k] = v
+	}
+	for _, group := range g.groups {
+		r.Header.Add(authentication.ImpersonateGroupHeader, group)
+	}
+	return g.delegate.RoundTrip(r)
+}
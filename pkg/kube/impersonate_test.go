@@ -0,0 +1,94 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/client/restclient"
+	"k8s.io/kubernetes/pkg/client/unversioned/clientcmd"
+	clientcmdapi "k8s.io/kubernetes/pkg/client/unversioned/clientcmd/api"
+)
+
+type staticConfig struct {
+	cfg restclient.Config
+}
+
+func (s *staticConfig) RawConfig() (clientcmdapi.Config, error)   { return clientcmdapi.Config{}, nil }
+func (s *staticConfig) Namespace() (string, bool, error)          { return "default", false, nil }
+func (s *staticConfig) ConfigAccess() clientcmd.ConfigAccess      { return nil }
+func (s *staticConfig) ClientConfig() (*restclient.Config, error) { cp := s.cfg; return &cp, nil }
+
+type headerRecorder struct {
+	header http.Header
+}
+
+func (h *headerRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	h.header = req.Header
+	return &http.Response{StatusCode: http.StatusOK}, nil
+}
+
+func TestImpersonatingConfig(t *testing.T) {
+	ic := &impersonatingConfig{
+		base:   &staticConfig{restclient.Config{Host: "https://kube.example.com"}},
+		user:   "jane",
+		groups: []string{"dev", "ops"},
+	}
+	cfg, err := ic.ClientConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Host != "https://kube.example.com" {
+		t.Errorf("expected the base configuration to be kept, got host %q", cfg.Host)
+	}
+	if cfg.Impersonate != "jane" {
+		t.Errorf("expected to impersonate jane, got %q", cfg.Impersonate)
+	}
+
+	rec := &headerRecorder{}
+	req, _ := http.NewRequest("GET", "https://kube.example.com/api", nil)
+	if _, err := cfg.WrapTransport(rec).RoundTrip(req); err != nil {
+		t.Fatal(err)
+	}
+	if got := rec.header["Impersonate-Group"]; !reflect.DeepEqual(got, []string{"dev", "ops"}) {
+		t.Errorf("expected groups dev and ops, got %v", got)
+	}
+	if len(req.Header) != 0 {
+		t.Errorf("expected the original request to be left alone, got %v", req.Header)
+	}
+}
+
+func TestImpersonatingConfigNoGroups(t *testing.T) {
+	ic := &impersonatingConfig{base: &staticConfig{}, user: "jane"}
+	cfg, err := ic.ClientConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.WrapTransport != nil {
+		t.Error("expected no transport wrapper without groups")
+	}
+}
+
+func TestImpersonateKeepsReporter(t *testing.T) {
+	r := &recordingReporter{}
+	c := New(&staticConfig{}).WithReporter(r).Impersonate("jane", nil)
+	if c.Reporter != r {
+		t.Error("expected the reporter to be kept")
+	}
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "k8s.io/helm/pkg/kube"
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"k8s.io/kubernetes/pkg/api/errors"
+	"k8s.io/kubernetes/pkg/api/meta"
+	"k8s.io/kubernetes/pkg/kubectl/resource"
+	"k8s.io/kubernetes/pkg/runtime"
+)
+
+// The labels a Client that is owned by a release sets on the resources it
+// creates and updates.
+const (
+	// LabelManagedBy is always ManagedByTiller.
+	LabelManagedBy = "helm.sh/managed-by"
+	// LabelRelease is the name of the release that owns the resource.
+	LabelRelease = "helm.sh/release"
+	// LabelRevision is the release revision that last changed the resource.
+	LabelRevision = "helm.sh/revision"
+	// LabelChart is the name and version of the chart, as in "mysql-0.1.0".
+	LabelChart = "helm.sh/chart"
+)
+
+// ManagedByTiller is the value of LabelManagedBy.
+const ManagedByTiller = "Tiller"
+
+// maxLabelValue is the longest value Kubernetes accepts for a label.
+const maxLabelValue = 63
+
+// Owner identifies the release revision that manages resources.
+type Owner struct {
+	Release  string
+	Revision int32
+	Chart    string
+}
+
+// Labels returns the labels that mark a resource as owned by o.
+func (o Owner) Labels() map[string]string {
+	l := map[string]string{
+		LabelManagedBy: ManagedByTiller,
+		LabelRelease:   o.Release,
+		LabelRevision:  strconv.Itoa(int(o.Revision)),
+	}
+	if o.Chart != "" {
+		l[LabelChart] = labelValue(o.Chart)
+	}
+	return l
+}
+
+// labelValue makes s a valid label value. Build metadata in chart versions
+// ("1.0.0+abc") is the only thing charts commonly have that labels do not
+// allow.
+func labelValue(s string) string {
+	s = strings.Replace(s, "+", "_", -1)
+	if len(s) > maxLabelValue {
+		s = s[:maxLabelValue]
+	}
+	return strings.TrimRight(s, "-_.")
+}
+
+// ErrOwnedByOtherRelease is returned when a release would take over a
+// resource that belongs to another release.
+type ErrOwnedByOtherRelease struct {
+	Kind      string
+	Name      string
+	Namespace string
+	// Owner is the release that owns the resource.
+	Owner string
+	// Release is the release that tried to take it over.
+	Release string
+}
+
+func (e ErrOwnedByOtherRelease) Error() string {
+	return fmt.Sprintf("%s %q in namespace %q is owned by release %q and cannot be used by release %q", e.Kind, e.Name, e.Namespace, e.Owner, e.Release)
+}
+
+// OwnedBy returns a copy of the client that labels every resource it creates
+// or updates as owned by o.
+//
+// The client refuses to create or update a resource that already exists and
+// is labeled as owned by another release, and when an update drops a
+// resource from the release, it leaves the resource alone if another release
+// owns it by now. Resources without the labels, such as those created before
+// Tiller labeled them, are treated as they always were.
+func (c *Client) OwnedBy(o Owner) *Client {
+	cp := *c
+	cp.Owner = &o
+	return &cp
+}
+
+// ReleaseOf returns the release that obj is labeled as owned by, or "" if it
+// is not managed by Tiller.
+func ReleaseOf(obj runtime.Object) string {
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		return ""
+	}
+	l := m.GetLabels()
+	if l[LabelManagedBy] != ManagedByTiller {
+		return ""
+	}
+	return l[LabelRelease]
+}
+
+// label sets the ownership labels on the object described by info, if the
+// client has an owner.
+func (c *Client) label(info *resource.Info) {
+	if c.Owner == nil {
+		return
+	}
+	m, err := meta.Accessor(info.Object)
+	if err != nil {
+		// Objects without metadata have nowhere to put labels.
+		return
+	}
+	l := m.GetLabels()
+	if l == nil {
+		l = map[string]string{}
+	}
+	for k, v := range c.Owner.Labels() {
+		l[k] = v
+	}
+	m.SetLabels(l)
+}
+
+// checkOwner returns ErrOwnedByOtherRelease if live, the object in the
+// cluster for info, is owned by a release other than the client's owner.
+func (c *Client) checkOwner(info *resource.Info, live runtime.Object) error {
+	if c.Owner == nil {
+		return nil
+	}
+	if owner := ReleaseOf(live); owner != "" && owner != c.Owner.Release {
+		return ErrOwnedByOtherRelease{
+			Kind:      info.Mapping.GroupVersionKind.Kind,
+			Name:      info.Name,
+			Namespace: info.Namespace,
+			Owner:     owner,
+			Release:   c.Owner.Release,
+		}
+	}
+	return nil
+}
+
+// getLive fetches the object in the cluster for info.
+func (c *Client) getLive(info *resource.Info) (runtime.Object, error) {
+	var live runtime.Object
+	err := c.retry("get", info, func() error {
+		var err error
+		live, err = resource.NewHelper(info.Client, info.Mapping).Get(info.Namespace, info.Name, info.Export)
+		return err
+	})
+	return live, err
+}
+
+// checkLiveOwner is checkOwner for the object currently in the cluster. A
+// missing object has no owner; any other error fetching it is returned, as the
+// owner cannot be told.
+func (c *Client) checkLiveOwner(info *resource.Info) error {
+	if c.Owner == nil {
+		return nil
+	}
+	live, err := c.getLive(info)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return c.checkOwner(info, live)
+}
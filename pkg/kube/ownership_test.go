@@ -0,0 +1,181 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api/errors"
+	"k8s.io/kubernetes/pkg/api/meta"
+	"k8s.io/kubernetes/pkg/api/testapi"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	api "k8s.io/kubernetes/pkg/api/v1"
+	"k8s.io/kubernetes/pkg/api/validation"
+	"k8s.io/kubernetes/pkg/client/unversioned/fake"
+	"k8s.io/kubernetes/pkg/kubectl/resource"
+	"k8s.io/kubernetes/pkg/runtime"
+)
+
+func TestOwnerLabels(t *testing.T) {
+	l := Owner{Release: "web", Revision: 3, Chart: "nginx-1.0.0+build.1"}.Labels()
+	expect := map[string]string{
+		LabelManagedBy: "Tiller",
+		LabelRelease:   "web",
+		LabelRevision:  "3",
+		LabelChart:     "nginx-1.0.0_build.1",
+	}
+	for k, v := range expect {
+		if l[k] != v {
+			t.Errorf("expected %s=%q, got %q", k, v, l[k])
+		}
+	}
+}
+
+// ownedService sets up a fake Kubernetes API for c whose my-service Service
+// is owned by release, and which records the requests it gets. If hidden is
+// set, the service is not found until it has been created.
+func ownedService(t *testing.T, c *Client, release string, hidden bool, requests *[]string) {
+	ownedServiceStatus(t, c, release, hidden, 0, requests)
+}
+
+// ownedServiceStatus is ownedService with GET requests failing with status,
+// if it is not zero.
+func ownedServiceStatus(t *testing.T, c *Client, release string, hidden bool, status int, requests *[]string) {
+	svc := &api.Service{
+		TypeMeta: unversioned.TypeMeta{APIVersion: "v1", Kind: "Service"},
+		ObjectMeta: api.ObjectMeta{
+			Name:      "my-service",
+			Namespace: "test",
+			Labels:    Owner{Release: release, Revision: 1}.Labels(),
+		},
+	}
+	live, err := runtime.Encode(testapi.Default.Codec(), svc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.IncludeThirdPartyAPIs = false
+	c.Validator = func(validate bool, cacheDir string) (validation.Schema, error) {
+		return validation.NullSchema{}, nil
+	}
+	c.ClientForMapping = func(mapping *meta.RESTMapping) (resource.RESTClient, error) {
+		return &fake.RESTClient{
+			NegotiatedSerializer: testapi.Default.NegotiatedSerializer(),
+			Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+				header := http.Header{}
+				header.Set("Content-Type", runtime.ContentTypeJSON)
+				*requests = append(*requests, req.Method)
+				switch req.Method {
+				case "GET":
+					if status != 0 {
+						return &http.Response{StatusCode: status, Header: header, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+					}
+					if hidden {
+						return &http.Response{StatusCode: 404, Header: header, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+					}
+					return &http.Response{StatusCode: 200, Header: header, Body: ioutil.NopCloser(bytes.NewReader(live))}, nil
+				case "POST":
+					body, _ := ioutil.ReadAll(req.Body)
+					if !strings.Contains(string(body), `"helm.sh/release":"web"`) {
+						t.Errorf("expected the new resource to be labeled, got %s", body)
+					}
+					exists := errors.NewAlreadyExists(unversioned.GroupResource{Resource: "services"}, "my-service").Status()
+					body, _ = runtime.Encode(testapi.Default.Codec(), &exists)
+					hidden = false
+					return &http.Response{StatusCode: 409, Header: header, Body: ioutil.NopCloser(bytes.NewReader(body))}, nil
+				}
+				return &http.Response{StatusCode: 200, Header: header, Body: ioutil.NopCloser(bytes.NewReader(live))}, nil
+			}),
+		}, nil
+	}
+}
+
+func TestCreateRefusesResourceOfOtherRelease(t *testing.T) {
+	var requests []string
+	c := New(nil).OwnedBy(Owner{Release: "web", Revision: 1})
+	ownedService(t, c, "db", true, &requests)
+
+	// Another release creates the service between the check and the create.
+	err := c.Update("test", strings.NewReader(""), strings.NewReader(testServiceManifest))
+	if expect := `Service "my-service" in namespace "test" is owned by release "db" and cannot be used by release "web"`; err == nil || !strings.HasSuffix(err.Error(), expect) {
+		t.Errorf("expected %q, got %v", expect, err)
+	}
+}
+
+func TestUpdateRefusesResourceOfOtherRelease(t *testing.T) {
+	var requests []string
+	c := New(nil).OwnedBy(Owner{Release: "web", Revision: 2})
+	ownedService(t, c, "db", false, &requests)
+
+	err := c.Update("test", strings.NewReader(testServiceManifest), strings.NewReader(testServiceManifest))
+	if _, ok := err.(ErrOwnedByOtherRelease); !ok {
+		t.Fatalf("expected ErrOwnedByOtherRelease, got %v", err)
+	}
+	if strings.Join(requests, ",") != "GET" {
+		t.Errorf("expected the resource to be left alone, got requests %v", requests)
+	}
+}
+
+func TestUpdateKeepsDroppedResourceOfOtherRelease(t *testing.T) {
+	var requests []string
+	c := New(nil).OwnedBy(Owner{Release: "web", Revision: 2})
+	ownedService(t, c, "db", false, &requests)
+
+	if err := c.Update("test", strings.NewReader(testServiceManifest), strings.NewReader("")); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(requests, ",") != "GET" {
+		t.Errorf("expected the resource not to be deleted, got requests %v", requests)
+	}
+
+	requests = nil
+	c = New(nil).OwnedBy(Owner{Release: "db", Revision: 2})
+	ownedService(t, c, "db", false, &requests)
+	if err := c.Update("test", strings.NewReader(testServiceManifest), strings.NewReader("")); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(requests, ",") != "GET,DELETE" {
+		t.Errorf("expected the resource to be deleted, got requests %v", requests)
+	}
+}
+
+func TestUpdateFailsWhenOwnerCannotBeRead(t *testing.T) {
+	var requests []string
+	c := New(nil).OwnedBy(Owner{Release: "web", Revision: 2})
+	c.Backoff = Backoff{Steps: 1}
+	ownedServiceStatus(t, c, "db", false, http.StatusInternalServerError, &requests)
+
+	if err := c.Update("test", strings.NewReader(testServiceManifest), strings.NewReader("")); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(requests, ",") != "GET" {
+		t.Errorf("expected the resource not to be deleted, got requests %v", requests)
+	}
+
+	requests = nil
+	err := c.Update("test", strings.NewReader(testServiceManifest), strings.NewReader(testServiceManifest))
+	if err == nil {
+		t.Fatal("expected an error when the live resource cannot be read")
+	}
+	if strings.Join(requests, ",") != "GET" {
+		t.Errorf("expected the resource to be left alone, got requests %v", requests)
+	}
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "k8s.io/helm/pkg/kube"
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"k8s.io/kubernetes/pkg/api/errors"
+	apiunversioned "k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/kubectl/resource"
+)
+
+// Backoff controls how a Client retries a request to the Kubernetes API that
+// failed with a transient error.
+type Backoff struct {
+	// Steps is the number of times a request is tried, including the first.
+	Steps int
+	// Duration is the wait before the first retry. It doubles with every
+	// retry after that.
+	Duration time.Duration
+	// Cap is the longest wait between two tries.
+	Cap time.Duration
+}
+
+// DefaultBackoff is the retry policy of a Client that does not set one: five
+// tries over about 7.5 seconds.
+var DefaultBackoff = Backoff{Steps: 5, Duration: 500 * time.Millisecond, Cap: 10 * time.Second}
+
+// sleep is replaced in tests.
+var sleep = time.Sleep
+
+// RetryError is returned when a request still fails with a transient error
+// after every retry.
+type RetryError struct {
+	// Verb, Kind and Name describe the request, e.g. "create", "Service", "web".
+	Verb, Kind, Name string
+	// Attempts is the number of times the request was tried.
+	Attempts int
+	// Err is the error of the last try.
+	Err error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("%s %s %q failed after %d attempts: %s", e.Verb, e.Kind, e.Name, e.Attempts, e.Err)
+}
+
+// backoff returns the retry policy of the client.
+func (c *Client) backoff() Backoff {
+	if c.Backoff.Steps <= 0 {
+		return DefaultBackoff
+	}
+	return c.Backoff
+}
+
+// retry calls fn for the resource described by info until it succeeds, fails
+// with an error that is not transient, or runs out of tries.
+//
+// Retries are logged when the client is in debug mode. Errors that are not
+// transient are returned as they are, so that callers can still inspect them.
+func (c *Client) retry(verb string, info *resource.Info, fn func() error) error {
+	b := c.backoff()
+	wait := b.Duration
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || !IsTransient(err) {
+			return err
+		}
+		kind := info.Mapping.GroupVersionKind.Kind
+		if attempt >= b.Steps {
+			return &RetryError{Verb: verb, Kind: kind, Name: info.Name, Attempts: attempt, Err: err}
+		}
+
+		delay := wait
+		if s, ok := errors.SuggestsClientDelay(err); ok && s > 0 {
+			delay = time.Duration(s) * time.Second
+		}
+		if b.Cap > 0 && delay > b.Cap {
+			delay = b.Cap
+		}
+		if c.Debug {
+			c.logf("retrying %s of %s %q in %s (attempt %d of %d): %s", verb, kind, info.Name, delay, attempt+1, b.Steps, err)
+		}
+		sleep(delay)
+		wait *= 2
+	}
+}
+
+// IsTransient reports whether err is likely to go away if the request is
+// retried: a conflict, a timeout, a server error, throttling, an admission
+// webhook that could not be reached, or a dropped connection.
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	if status, ok := err.(errors.APIStatus); ok {
+		s := status.Status()
+		switch s.Reason {
+		case apiunversioned.StatusReasonConflict, apiunversioned.StatusReasonServerTimeout, apiunversioned.StatusReasonTimeout:
+			return true
+		}
+		if s.Code >= http.StatusInternalServerError || s.Code == http.StatusTooManyRequests {
+			return true
+		}
+		return isWebhookUnavailable(s.Message)
+	}
+	if ne, ok := err.(net.Error); ok && (ne.Timeout() || ne.Temporary()) {
+		return true
+	}
+	if err == io.ErrUnexpectedEOF || err == io.EOF {
+		return true
+	}
+	msg := err.Error()
+	for _, s := range []string{"connection refused", "connection reset by peer", "TLS handshake timeout", "i/o timeout", "unexpected EOF"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return isWebhookUnavailable(msg)
+}
+
+// isWebhookUnavailable reports whether msg says that the API server could not
+// call an admission webhook, rather than that the webhook rejected the request.
+func isWebhookUnavailable(msg string) bool {
+	if !strings.Contains(msg, "failed calling") || !strings.Contains(msg, "webhook") {
+		return false
+	}
+	for _, s := range []string{"connection refused", "no endpoints available", "timeout", "deadline exceeded", "EOF", "service unavailable"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"bytes"
+	goerrors "errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/kubernetes/pkg/api/errors"
+	"k8s.io/kubernetes/pkg/api/meta"
+	"k8s.io/kubernetes/pkg/api/testapi"
+	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/api/validation"
+	"k8s.io/kubernetes/pkg/client/unversioned/fake"
+	"k8s.io/kubernetes/pkg/kubectl/resource"
+	"k8s.io/kubernetes/pkg/runtime"
+)
+
+func TestIsTransient(t *testing.T) {
+	pods := unversioned.GroupResource{Resource: "pods"}
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"conflict", errors.NewConflict(pods, "web", goerrors.New("the object has been modified")), true},
+		{"server timeout", errors.NewServerTimeout(pods, "create", 1), true},
+		{"internal error", errors.NewInternalError(goerrors.New("etcd is down")), true},
+		{"service unavailable", errors.NewGenericServerResponse(http.StatusServiceUnavailable, "POST", pods, "web", "", 0, true), true},
+		{"webhook unreachable", errors.NewBadRequest(`Internal error occurred: failed calling admission webhook "policy.example.com": Post https://policy/validate: dial tcp 10.0.0.1:443: connection refused`), true},
+		{"webhook denial", errors.NewBadRequest(`admission webhook "policy.example.com" denied the request: no latest tags`), false},
+		{"connection reset", goerrors.New("read tcp 10.0.0.2:52123->10.0.0.1:443: read: connection reset by peer"), true},
+		{"unexpected EOF", io.ErrUnexpectedEOF, true},
+		{"not found", errors.NewNotFound(pods, "web"), false},
+		{"already exists", errors.NewAlreadyExists(pods, "web"), false},
+		{"invalid", errors.NewBadRequest("spec.containers: Required value"), false},
+		{"forbidden", errors.NewForbidden(pods, "web", goerrors.New("no")), false},
+		{"nil", nil, false},
+	}
+	for _, tt := range tests {
+		if got := IsTransient(tt.err); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+	var waits []time.Duration
+	defer func(f func(time.Duration)) { sleep = f }(sleep)
+	sleep = func(d time.Duration) { waits = append(waits, d) }
+
+	info := createFakeInfo("nginx", nil)
+	info.Mapping = &meta.RESTMapping{GroupVersionKind: unversioned.GroupVersionKind{Kind: "Pod"}}
+	c := &Client{Backoff: Backoff{Steps: 4, Duration: time.Second, Cap: 3 * time.Second}, Debug: true}
+
+	calls := 0
+	err := c.retry("create", info, func() error {
+		calls++
+		return errors.NewInternalError(goerrors.New("etcd is down"))
+	})
+	re, ok := err.(*RetryError)
+	if !ok {
+		t.Fatalf("Expected a RetryError, got %v", err)
+	}
+	if calls != 4 || re.Attempts != 4 {
+		t.Errorf("Expected 4 tries, got %d (%d reported)", calls, re.Attempts)
+	}
+	if !strings.Contains(err.Error(), `create Pod "nginx" failed after 4 attempts`) {
+		t.Errorf("Unexpected error %q", err)
+	}
+	if expect := []time.Duration{time.Second, 2 * time.Second, 3 * time.Second}; len(waits) != 3 || waits[0] != expect[0] || waits[1] != expect[1] || waits[2] != expect[2] {
+		t.Errorf("Expected waits %v, got %v", expect, waits)
+	}
+
+	calls = 0
+	notFound := errors.NewNotFound(unversioned.GroupResource{Resource: "pods"}, "nginx")
+	err = c.retry("get", info, func() error {
+		calls++
+		return notFound
+	})
+	if err != notFound || calls != 1 {
+		t.Errorf("Expected permanent errors to be returned at once, got %v after %d tries", err, calls)
+	}
+}
+
+func TestCreateRetriesTransientErrors(t *testing.T) {
+	defer func(f func(time.Duration)) { sleep = f }(sleep)
+	sleep = func(time.Duration) {}
+
+	posts := 0
+	rep := &recordingReporter{}
+	c := New(nil).WithReporter(rep)
+	c.IncludeThirdPartyAPIs = false
+	c.Validator = func(validate bool, cacheDir string) (validation.Schema, error) {
+		return validation.NullSchema{}, nil
+	}
+	c.ClientForMapping = func(mapping *meta.RESTMapping) (resource.RESTClient, error) {
+		return &fake.RESTClient{
+			NegotiatedSerializer: testapi.Default.NegotiatedSerializer(),
+			Client: fake.CreateHTTPClient(func(req *http.Request) (*http.Response, error) {
+				header := http.Header{}
+				header.Set("Content-Type", runtime.ContentTypeJSON)
+				if req.Method == "GET" {
+					return &http.Response{StatusCode: 404, Header: header, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+				}
+				posts++
+				switch posts {
+				case 1:
+					return &http.Response{StatusCode: 503, Header: header, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+				case 2:
+					// The create went through, but the response was lost.
+					return nil, io.ErrUnexpectedEOF
+				}
+				status := errors.NewAlreadyExists(unversioned.GroupResource{Resource: "services"}, "my-service").Status()
+				body, _ := runtime.Encode(testapi.Default.Codec(), &status)
+				return &http.Response{StatusCode: 409, Header: header, Body: ioutil.NopCloser(bytes.NewReader(body))}, nil
+			}),
+		}, nil
+	}
+
+	if err := c.Update("test", strings.NewReader(""), strings.NewReader(testServiceManifest)); err != nil {
+		t.Fatal(err)
+	}
+	if posts != 3 {
+		t.Errorf("Expected 3 tries, got %d", posts)
+	}
+	if expect := "created Service/my-service in test"; strings.Join(*rep, ",") != expect {
+		t.Errorf("expected %v, got %v", expect, *rep)
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "k8s.io/helm/pkg/kube"
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+
+	"k8s.io/kubernetes/pkg/api/meta"
+	"k8s.io/kubernetes/pkg/kubectl/resource"
+
+	"k8s.io/helm/pkg/releaseutil"
+)
+
+// AnnotationSource is the template a resource was rendered from, including
+// the path of its chart, as in "wordpress/charts/mariadb/templates/svc.yaml".
+const AnnotationSource = "helm.sh/chart-source"
+
+// templateSources maps the kind and name of the resources of a manifest to
+// the templates they were rendered from.
+type templateSources map[string]string
+
+// sources reads the "# Source:" comments of the manifest in reader, if the
+// client annotates the resources it owns with their templates. It returns a
+// reader for the manifest along with them.
+//
+// A manifest that cannot be read has no sources; building the resources of it
+// reports the error.
+func (c *Client) sources(reader io.Reader) (io.Reader, templateSources) {
+	if c.Owner == nil || !c.AnnotateSources {
+		return reader, nil
+	}
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return bytes.NewReader(data), nil
+	}
+	docs, err := releaseutil.SplitManifest(string(data))
+	if err != nil {
+		return bytes.NewReader(data), nil
+	}
+	srcs := templateSources{}
+	for _, d := range docs {
+		key := d.Kind + "/" + d.Name
+		if s, ok := srcs[key]; ok && s != d.Source {
+			// The same kind and name in several namespaces is ambiguous.
+			srcs[key] = ""
+			continue
+		}
+		srcs[key] = d.Source
+	}
+	return bytes.NewReader(data), srcs
+}
+
+// annotate sets AnnotationSource on the object described by info, if its
+// template is known.
+func (s templateSources) annotate(info *resource.Info) {
+	src := s[info.Mapping.GroupVersionKind.Kind+"/"+info.Name]
+	if src == "" {
+		return
+	}
+	m, err := meta.Accessor(info.Object)
+	if err != nil {
+		return
+	}
+	a := m.GetAnnotations()
+	if a == nil {
+		a = map[string]string{}
+	}
+	a[AnnotationSource] = src
+	m.SetAnnotations(a)
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api/meta"
+)
+
+const sourcesManifest = `---
+# Source: web/templates/pod.yaml
+apiVersion: v1
+kind: Pod
+metadata:
+  name: nginx
+---
+# Source: web/charts/db/templates/pod.yaml
+apiVersion: v1
+kind: Pod
+metadata:
+  name: db
+---
+# Source: web/templates/db.yaml
+apiVersion: v1
+kind: Pod
+metadata:
+  name: db
+  namespace: other
+`
+
+func TestSourcesAnnotate(t *testing.T) {
+	c := New(nil).OwnedBy(Owner{Release: "web", Revision: 1})
+	reader, srcs := c.sources(strings.NewReader(sourcesManifest))
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != sourcesManifest {
+		t.Errorf("expected the manifest to be read unchanged, got %q", data)
+	}
+
+	info := createFakeInfo("nginx", nil)
+	srcs.annotate(info)
+	m, _ := meta.Accessor(info.Object)
+	if a := m.GetAnnotations()[AnnotationSource]; a != "web/templates/pod.yaml" {
+		t.Errorf("expected the pod to be annotated with its template, got %q", a)
+	}
+
+	// The db pods of two templates cannot be told apart.
+	info = createFakeInfo("db", nil)
+	info.Name = "db"
+	srcs.annotate(info)
+	m, _ = meta.Accessor(info.Object)
+	if _, ok := m.GetAnnotations()[AnnotationSource]; ok {
+		t.Errorf("expected an ambiguous resource not to be annotated, got %v", m.GetAnnotations())
+	}
+
+	c.AnnotateSources = false
+	if _, srcs := c.sources(strings.NewReader(sourcesManifest)); srcs != nil {
+		t.Errorf("expected no sources with AnnotateSources off, got %v", srcs)
+	}
+	if _, srcs := New(nil).sources(strings.NewReader(sourcesManifest)); srcs != nil {
+		t.Errorf("expected no sources without an owner, got %v", srcs)
+	}
+}
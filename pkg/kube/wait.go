@@ -0,0 +1,182 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "k8s.io/helm/pkg/kube"
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/api/meta"
+	"k8s.io/kubernetes/pkg/apis/batch"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	"k8s.io/kubernetes/pkg/kubectl/resource"
+	"k8s.io/kubernetes/pkg/runtime"
+	"k8s.io/kubernetes/pkg/util/jsonpath"
+)
+
+// ReadyWhenAnnotation holds a custom readiness condition for a resource, of
+// the form "{JSONPATH}=VALUE", e.g. "{.status.phase}=Ready". It replaces the
+// built-in readiness check of the resource's kind.
+const ReadyWhenAnnotation = "helm.sh/ready-when"
+
+// DefaultWaitTimeout is how long Wait waits if WaitOptions has no timeout.
+const DefaultWaitTimeout = 5 * time.Minute
+
+// waitInterval is the time between two readiness checks of a resource.
+var waitInterval = 2 * time.Second
+
+// WaitOptions controls what Wait waits for.
+type WaitOptions struct {
+	// Timeout is how long to wait for all of the resources to be ready.
+	Timeout time.Duration
+	// Jobs, if true, waits for Jobs to complete successfully.
+	Jobs bool
+}
+
+// Wait waits until the resources given in the reader are ready, or the timeout
+// expires.
+//
+// Pods are ready when their containers are, Deployments, ReplicaSets and
+// ReplicationControllers when all of their replicas are updated and ready,
+// DaemonSets when they are scheduled on all nodes, PersistentVolumeClaims when
+// they are bound and Services of type LoadBalancer when they have an ingress.
+// Jobs are ready when they complete, if opts.Jobs is set. Resources annotated
+// with ReadyWhenAnnotation are ready when their condition holds. All other
+// resources are ready as soon as they exist.
+func (c *Client) Wait(namespace string, reader io.Reader, opts WaitOptions) error {
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = DefaultWaitTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	return perform(c, namespace, reader, func(info *resource.Info) error {
+		kind := info.Mapping.GroupVersionKind.Kind
+		for {
+			live, err := c.getLive(info)
+			if err != nil {
+				return err
+			}
+			ready, err := c.isReady(live, opts)
+			if err != nil {
+				return fmt.Errorf("%s %q is not ready: %s", kind, info.Name, err)
+			}
+			if ready {
+				c.report("ready", info)
+				return nil
+			}
+			if time.Now().After(deadline) {
+				return fmt.Errorf("timed out after %s waiting for %s %q to be ready", timeout, kind, info.Name)
+			}
+			c.logf("Waiting for %s %q to be ready", kind, info.Name)
+			time.Sleep(waitInterval)
+		}
+	})
+}
+
+// isReady reports whether obj, a live object, is ready. An error means that
+// it will never become ready.
+func (c *Client) isReady(obj runtime.Object, opts WaitOptions) (bool, error) {
+	if m, err := meta.Accessor(obj); err == nil {
+		if cond, ok := m.GetAnnotations()[ReadyWhenAnnotation]; ok {
+			data, err := c.toMap(obj)
+			if err != nil {
+				return false, err
+			}
+			return conditionHolds(cond, data)
+		}
+	}
+
+	switch o := obj.(type) {
+	case *api.Pod:
+		if o.Status.Phase == api.PodFailed {
+			return false, fmt.Errorf("pod failed: %s", o.Status.Reason)
+		}
+		return api.IsPodReady(o), nil
+	case *extensions.Deployment:
+		s := o.Status
+		return s.ObservedGeneration >= o.Generation && s.UpdatedReplicas >= o.Spec.Replicas && s.AvailableReplicas >= o.Spec.Replicas, nil
+	case *extensions.ReplicaSet:
+		return o.Status.ObservedGeneration >= o.Generation && o.Status.ReadyReplicas >= o.Spec.Replicas, nil
+	case *api.ReplicationController:
+		return o.Status.ObservedGeneration >= o.Generation && o.Status.ReadyReplicas >= o.Spec.Replicas, nil
+	case *extensions.DaemonSet:
+		return o.Status.CurrentNumberScheduled >= o.Status.DesiredNumberScheduled, nil
+	case *api.PersistentVolumeClaim:
+		return o.Status.Phase == api.ClaimBound, nil
+	case *api.Service:
+		return o.Spec.Type != api.ServiceTypeLoadBalancer || len(o.Status.LoadBalancer.Ingress) > 0, nil
+	case *batch.Job:
+		if !opts.Jobs {
+			return true, nil
+		}
+		for _, cond := range o.Status.Conditions {
+			if cond.Type == batch.JobComplete && cond.Status == api.ConditionTrue {
+				return true, nil
+			} else if cond.Type == batch.JobFailed && cond.Status == api.ConditionTrue {
+				return false, fmt.Errorf("job failed: %s", cond.Reason)
+			}
+		}
+		return false, nil
+	}
+	return true, nil
+}
+
+// toMap returns obj as a generic map, in the shape it has in YAML manifests.
+func (c *Client) toMap(obj runtime.Object) (map[string]interface{}, error) {
+	if u, ok := obj.(*runtime.Unstructured); ok {
+		return u.Object, nil
+	}
+	data, err := runtime.Encode(c.JSONEncoder(), obj)
+	if err != nil {
+		return nil, err
+	}
+	res := map[string]interface{}{}
+	err = json.Unmarshal(data, &res)
+	return res, err
+}
+
+// parseCondition splits a readiness condition of the form "{JSONPATH}=VALUE".
+func parseCondition(cond string) (*jsonpath.JSONPath, string, error) {
+	i := strings.LastIndex(cond, "}")
+	if !strings.HasPrefix(cond, "{") || i < 0 || !strings.HasPrefix(cond[i+1:], "=") {
+		return nil, "", fmt.Errorf("invalid %s condition %q: must be of the form {JSONPATH}=VALUE", ReadyWhenAnnotation, cond)
+	}
+	j := jsonpath.New(ReadyWhenAnnotation)
+	if err := j.Parse(cond[:i+1]); err != nil {
+		return nil, "", fmt.Errorf("invalid %s condition %q: %s", ReadyWhenAnnotation, cond, err)
+	}
+	return j, cond[i+2:], nil
+}
+
+// conditionHolds reports whether the readiness condition cond holds for obj.
+// A JSONPath that does not match anything yet does not hold.
+func conditionHolds(cond string, obj map[string]interface{}) (bool, error) {
+	j, want, err := parseCondition(cond)
+	if err != nil {
+		return false, err
+	}
+	b := bytes.NewBuffer(nil)
+	if err := j.Execute(b, obj); err != nil {
+		return false, nil
+	}
+	return strings.TrimSpace(b.String()) == want, nil
+}
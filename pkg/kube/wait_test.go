@@ -0,0 +1,102 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube // import "k8s.io/helm/pkg/kube"
+
+import (
+	"testing"
+
+	"k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/apis/batch"
+	"k8s.io/kubernetes/pkg/apis/extensions"
+	"k8s.io/kubernetes/pkg/runtime"
+)
+
+func TestIsReady(t *testing.T) {
+	completed := &batch.Job{Status: batch.JobStatus{Conditions: []batch.JobCondition{{Type: batch.JobComplete, Status: api.ConditionTrue}}}}
+	deployment := func(replicas, available int32) *extensions.Deployment {
+		d := &extensions.Deployment{Spec: extensions.DeploymentSpec{Replicas: 2}}
+		d.Status.UpdatedReplicas, d.Status.AvailableReplicas = replicas, available
+		return d
+	}
+	phase := func(p string) *runtime.Unstructured {
+		return &runtime.Unstructured{Object: map[string]interface{}{
+			"kind":     "Database",
+			"metadata": map[string]interface{}{"name": "db", "annotations": map[string]interface{}{ReadyWhenAnnotation: "{.status.phase}=Ready"}},
+			"status":   map[string]interface{}{"phase": p},
+		}}
+	}
+
+	tests := []struct {
+		name  string
+		obj   runtime.Object
+		opts  WaitOptions
+		ready bool
+		err   bool
+	}{
+		{"config map", &api.ConfigMap{}, WaitOptions{}, true, false},
+		{"unavailable deployment", deployment(2, 1), WaitOptions{}, false, false},
+		{"available deployment", deployment(2, 2), WaitOptions{}, true, false},
+		{"unbound claim", &api.PersistentVolumeClaim{}, WaitOptions{}, false, false},
+		{"running job", &batch.Job{}, WaitOptions{}, true, false},
+		{"running job with jobs", &batch.Job{}, WaitOptions{Jobs: true}, false, false},
+		{"completed job with jobs", completed, WaitOptions{Jobs: true}, true, false},
+		{"failed job with jobs", &batch.Job{Status: batch.JobStatus{Conditions: []batch.JobCondition{{Type: batch.JobFailed, Status: api.ConditionTrue}}}}, WaitOptions{Jobs: true}, false, true},
+		{"custom condition pending", phase("Pending"), WaitOptions{}, false, false},
+		{"custom condition met", phase("Ready"), WaitOptions{}, true, false},
+	}
+	c := &Client{}
+	for _, tt := range tests {
+		ready, err := c.isReady(tt.obj, tt.opts)
+		if (err != nil) != tt.err {
+			t.Errorf("%s: expected error %t, got %v", tt.name, tt.err, err)
+		}
+		if ready != tt.ready {
+			t.Errorf("%s: expected ready %t, got %t", tt.name, tt.ready, ready)
+		}
+	}
+}
+
+func TestConditionHolds(t *testing.T) {
+	obj := map[string]interface{}{
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Synced", "status": "False"},
+				map[string]interface{}{"type": "Ready", "status": "True"},
+			},
+		},
+	}
+	tests := []struct {
+		cond string
+		ok   bool
+		err  bool
+	}{
+		{`{.status.conditions[?(@.type=="Ready")].status}=True`, true, false},
+		{`{.status.conditions[?(@.type=="Synced")].status}=True`, false, false},
+		{`{.status.phase}=Ready`, false, false},
+		{`.status.phase=Ready`, false, true},
+		{`{.status.phase}`, false, true},
+	}
+	for _, tt := range tests {
+		ok, err := conditionHolds(tt.cond, obj)
+		if (err != nil) != tt.err {
+			t.Errorf("%s: expected error %t, got %v", tt.cond, tt.err, err)
+		}
+		if ok != tt.ok {
+			t.Errorf("%s: expected %t, got %t", tt.cond, tt.ok, ok)
+		}
+	}
+}
@@ -32,5 +32,6 @@ func All(basedir string) support.Linter {
 	rules.Chartfile(&linter)
 	rules.Values(&linter)
 	rules.Templates(&linter)
+	rules.Checks(&linter)
 	return linter
 }
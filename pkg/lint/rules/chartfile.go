@@ -54,6 +54,7 @@ func Chartfile(linter *support.Linter) {
 	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartEngine(chartFile))
 	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartMaintainer(chartFile))
 	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartSources(chartFile))
+	linter.RunLinterRule(support.ErrorSev, chartFileName, validateChartVersionConstraints(chartFile))
 }
 
 func validateChartYamlNotDirectory(chartPath string) error {
@@ -139,6 +140,8 @@ func validateChartMaintainer(cf *chart.Metadata) error {
 			return errors.New("each maintainer requires a name")
 		} else if maintainer.Email != "" && !govalidator.IsEmail(maintainer.Email) {
 			return fmt.Errorf("invalid email '%s' for maintainer '%s'", maintainer.Email, maintainer.Name)
+		} else if maintainer.Url != "" && !govalidator.IsRequestURL(maintainer.Url) {
+			return fmt.Errorf("invalid url '%s' for maintainer '%s'", maintainer.Url, maintainer.Name)
 		}
 	}
 	return nil
@@ -152,3 +155,17 @@ func validateChartSources(cf *chart.Metadata) error {
 	}
 	return nil
 }
+
+func validateChartVersionConstraints(cf *chart.Metadata) error {
+	if cf.TillerVersion != "" {
+		if _, err := semver.NewConstraint(cf.TillerVersion); err != nil {
+			return fmt.Errorf("tillerVersion '%s' is not a valid SemVer range", cf.TillerVersion)
+		}
+	}
+	if cf.KubeVersion != "" {
+		if _, err := semver.NewConstraint(cf.KubeVersion); err != nil {
+			return fmt.Errorf("kubeVersion '%s' is not a valid SemVer range", cf.KubeVersion)
+		}
+	}
+	return nil
+}
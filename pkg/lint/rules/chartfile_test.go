@@ -142,34 +142,38 @@ func TestValidateChartMaintainer(t *testing.T) {
 	var failTest = []struct {
 		Name     string
 		Email    string
+		URL      string
 		ErrorMsg string
 	}{
-		{"", "", "each maintainer requires a name"},
-		{"", "test@test.com", "each maintainer requires a name"},
-		{"John Snow", "wrongFormatEmail.com", "invalid email"},
+		{"", "", "", "each maintainer requires a name"},
+		{"", "test@test.com", "", "each maintainer requires a name"},
+		{"John Snow", "wrongFormatEmail.com", "", "invalid email"},
+		{"John Snow", "", "wrongFormatURL", "invalid url"},
 	}
 
 	var successTest = []struct {
 		Name  string
 		Email string
+		URL   string
 	}{
-		{"John Snow", ""},
-		{"John Snow", "john@winterfell.com"},
+		{"John Snow", "", ""},
+		{"John Snow", "john@winterfell.com", ""},
+		{"John Snow", "john@winterfell.com", "https://winterfell.com/john"},
 	}
 
 	for _, test := range failTest {
-		badChart.Maintainers = []*chart.Maintainer{{Name: test.Name, Email: test.Email}}
+		badChart.Maintainers = []*chart.Maintainer{{Name: test.Name, Email: test.Email, Url: test.URL}}
 		err := validateChartMaintainer(badChart)
 		if err == nil || !strings.Contains(err.Error(), test.ErrorMsg) {
-			t.Errorf("validateChartMaintainer(%s, %s) to return \"%s\", got no error", test.Name, test.Email, test.ErrorMsg)
+			t.Errorf("validateChartMaintainer(%s, %s, %s) to return \"%s\", got no error", test.Name, test.Email, test.URL, test.ErrorMsg)
 		}
 	}
 
 	for _, test := range successTest {
-		badChart.Maintainers = []*chart.Maintainer{{Name: test.Name, Email: test.Email}}
+		badChart.Maintainers = []*chart.Maintainer{{Name: test.Name, Email: test.Email, Url: test.URL}}
 		err := validateChartMaintainer(badChart)
 		if err != nil {
-			t.Errorf("validateChartMaintainer(%s, %s) to return no error, got %s", test.Name, test.Email, err.Error())
+			t.Errorf("validateChartMaintainer(%s, %s, %s) to return no error, got %s", test.Name, test.Email, test.URL, err.Error())
 		}
 	}
 }
@@ -194,6 +198,29 @@ func TestValidateChartSources(t *testing.T) {
 	}
 }
 
+func TestValidateChartVersionConstraints(t *testing.T) {
+	var failTest = []struct {
+		Tiller   string
+		Kube     string
+		ErrorMsg string
+	}{
+		{"not-a-range", "", "tillerVersion 'not-a-range' is not a valid SemVer range"},
+		{"", ">>1.4", "kubeVersion '>>1.4' is not a valid SemVer range"},
+	}
+	for _, test := range failTest {
+		c := &chart.Metadata{TillerVersion: test.Tiller, KubeVersion: test.Kube}
+		err := validateChartVersionConstraints(c)
+		if err == nil || !strings.Contains(err.Error(), test.ErrorMsg) {
+			t.Errorf("validateChartVersionConstraints(%s, %s) to return \"%s\", got %v", test.Tiller, test.Kube, test.ErrorMsg, err)
+		}
+	}
+
+	c := &chart.Metadata{TillerVersion: ">=2.0.0", KubeVersion: "~1.4.0"}
+	if err := validateChartVersionConstraints(c); err != nil {
+		t.Errorf("validateChartVersionConstraints to return no error, got %s", err)
+	}
+}
+
 func TestChartfile(t *testing.T) {
 	linter := support.Linter{ChartDir: badChartDir}
 	Chartfile(&linter)
@@ -0,0 +1,48 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"io/ioutil"
+	"path/filepath"
+
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/lint/support"
+)
+
+// Checks lints the pre-flight checks in the checks/ directory of a chart.
+func Checks(linter *support.Linter) {
+	// The checks directory is optional.
+	files, _ := ioutil.ReadDir(filepath.Join(linter.ChartDir, chartutil.PreflightDir))
+	for _, fi := range files {
+		ext := filepath.Ext(fi.Name())
+		if fi.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		path := filepath.Join(chartutil.PreflightDir, fi.Name())
+		linter.RunLinterRule(support.ErrorSev, path, validateChecksFile(filepath.Join(linter.ChartDir, path)))
+	}
+}
+
+func validateChecksFile(filename string) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	_, err = chartutil.ParsePreflightChecks(data)
+	return err
+}
@@ -0,0 +1,43 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rules
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/helm/pkg/lint/support"
+)
+
+func TestChecks(t *testing.T) {
+	linter := support.Linter{ChartDir: "testdata/badchecks"}
+	Checks(&linter)
+	res := linter.Messages
+
+	if len(res) != 1 {
+		t.Fatalf("Expected one error, got %d, %v", len(res), res)
+	}
+	if !strings.Contains(res[0].Err.Error(), `check "nothing": no requirements`) || res[0].Path != "checks/empty.yaml" {
+		t.Errorf("Unexpected error: %s", res[0])
+	}
+
+	linter = support.Linter{ChartDir: goodChartDir}
+	Checks(&linter)
+	if len(linter.Messages) != 0 {
+		t.Errorf("Expected no errors for a chart without checks, got %v", linter.Messages)
+	}
+}
@@ -18,18 +18,24 @@ package rules
 
 import (
 	"bytes"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"strings"
 	"text/template"
+	"unicode/utf8"
 
 	"github.com/ghodss/yaml"
 	"k8s.io/helm/pkg/chartutil"
 	"k8s.io/helm/pkg/engine"
 	"k8s.io/helm/pkg/lint/support"
+	"k8s.io/helm/pkg/releaseutil"
 	"k8s.io/helm/pkg/timeconv"
+	"k8s.io/helm/pkg/version"
 )
 
 // Templates lints the templates in the Linter.
@@ -54,7 +60,12 @@ func Templates(linter *support.Linter) {
 	}
 
 	options := chartutil.ReleaseOptions{Name: "testRelease", Time: timeconv.Now(), Namespace: "testNamespace"}
-	valuesToRender, err := chartutil.ToRenderValues(chart, chart.Values, options)
+	caps := &chartutil.Capabilities{
+		APIVersions:   chartutil.DefaultVersionSet,
+		KubeVersion:   chartutil.DefaultKubeVersion,
+		TillerVersion: version.GetVersionProto(),
+	}
+	valuesToRender, err := chartutil.ToRenderValues(chart, chart.Values, options, caps)
 	if err != nil {
 		// FIXME: This seems to generate a duplicate, but I can't find where the first
 		// error is coming from.
@@ -75,6 +86,7 @@ func Templates(linter *support.Linter) {
 	- {{}} include | quote
 	- Generated content is a valid Yaml file
 	- Metadata.Namespace is not set
+	- ConfigMaps and Secrets fit into a Kubernetes object
 	*/
 	for _, template := range chart.Templates {
 		fileName, preExecutedTemplate := template.Name, template.Data
@@ -94,6 +106,10 @@ func Templates(linter *support.Linter) {
 		// linter.RunLinterRule(support.WarningSev, path, validateQuotes(string(preExecutedTemplate)))
 
 		renderedContent := renderedContentMap[filepath.Join(chart.GetMetadata().Name, fileName)]
+		if !linter.RunLinterRule(support.ErrorSev, path, validateTextContent(renderedContent)) {
+			continue
+		}
+
 		var yamlStruct K8sYamlStruct
 		// Even though K8sYamlStruct only defines Metadata namespace, an error in any other
 		// key will be raised as well
@@ -105,6 +121,21 @@ func Templates(linter *support.Linter) {
 			continue
 		}
 	}
+
+	// Files are mostly embedded by the templates of subcharts, too, so the
+	// size of every rendered resource is checked.
+	names := make([]string, 0, len(renderedContentMap))
+	for name := range renderedContentMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if filepath.Ext(name) != ".yaml" {
+			continue
+		}
+		path = strings.TrimPrefix(name, chart.GetMetadata().Name+"/")
+		linter.RunLinterRule(support.WarningSev, path, validateDataSize(renderedContentMap[name]))
+	}
 }
 
 // Validation functions
@@ -190,6 +221,64 @@ func validateNoMissingValues(templatesPath string, chartValues chartutil.Values,
 	return nil
 }
 
+// validateTextContent checks that a rendered template is text, which a binary
+// file embedded without encoding it is not.
+func validateTextContent(content string) error {
+	if !utf8.ValidString(content) {
+		return errors.New("rendered content is not valid UTF-8. Embed binary files with b64enc or .Files.AsSecrets")
+	}
+	return nil
+}
+
+// maxDataSize is the most data that a ConfigMap or Secret can hold.
+const maxDataSize = 1 << 20
+
+// dataObject stubs the data of a ConfigMap or Secret.
+type dataObject struct {
+	Kind     string
+	Metadata struct {
+		Name string
+	}
+	Data       map[string]string
+	StringData map[string]string `json:"stringData"`
+}
+
+// validateDataSize checks that the ConfigMaps and Secrets in a rendered
+// template hold no more data than Kubernetes allows. Content that is not
+// valid YAML is left to the other template checks.
+func validateDataSize(content string) error {
+	docs, err := releaseutil.SplitManifest(content)
+	if err != nil {
+		return nil
+	}
+	for _, doc := range docs {
+		var obj dataObject
+		if err := yaml.Unmarshal([]byte(doc.Content), &obj); err != nil {
+			continue
+		}
+		size := 0
+		switch obj.Kind {
+		case "ConfigMap":
+			for k, v := range obj.Data {
+				size += len(k) + len(v)
+			}
+		case "Secret":
+			for k, v := range obj.Data {
+				size += len(k) + base64.StdEncoding.DecodedLen(len(v))
+			}
+			for k, v := range obj.StringData {
+				size += len(k) + len(v)
+			}
+		default:
+			continue
+		}
+		if size > maxDataSize {
+			return fmt.Errorf("%s %q holds %d bytes of data, more than the %d bytes that Kubernetes allows", obj.Kind, obj.Metadata.Name, size, maxDataSize)
+		}
+	}
+	return nil
+}
+
 func validateYamlContent(err error) error {
 	if err != nil {
 		return fmt.Errorf("unable to parse YAML\n\t%s", err)
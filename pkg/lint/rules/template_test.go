@@ -76,3 +76,32 @@ func TestTemplateIntegrationHappyPath(t *testing.T) {
 		t.Fatalf("Expected no error, got %d, %v", len(res), res)
 	}
 }
+
+func TestValidateTextContent(t *testing.T) {
+	if err := validateTextContent("data:\n  key: value\n"); err != nil {
+		t.Errorf("Unexpected error for text content: %s", err)
+	}
+	if err := validateTextContent("data:\n  key: \xfe\xed\xfe\xed\n"); err == nil || !strings.Contains(err.Error(), "b64enc") {
+		t.Errorf("Expected an error for binary content, got %v", err)
+	}
+}
+
+func TestValidateDataSize(t *testing.T) {
+	big := strings.Repeat("x", maxDataSize)
+
+	if err := validateDataSize("kind: ConfigMap\nmetadata:\n  name: small\ndata:\n  key: value\n"); err != nil {
+		t.Errorf("Unexpected error for a small ConfigMap: %s", err)
+	}
+	if err := validateDataSize("kind: Deployment\nmetadata:\n  name: web\nspec:\n  replicas: 1\n---\nkind: ConfigMap\nmetadata:\n  name: big\ndata:\n  key: " + big + "\n"); err == nil || !strings.Contains(err.Error(), `ConfigMap "big" holds 1048579 bytes`) {
+		t.Errorf("Expected an error for a big ConfigMap, got %v", err)
+	}
+
+	// Secret data is counted decoded.
+	encoded := strings.Repeat("eHh4", maxDataSize/4)
+	if err := validateDataSize("kind: Secret\nmetadata:\n  name: cert\ndata:\n  k: " + encoded + "\n"); err != nil {
+		t.Errorf("Unexpected error for a Secret under the limit once decoded: %s", err)
+	}
+	if err := validateDataSize("kind: Secret\nmetadata:\n  name: cert\nstringData:\n  k: " + big + "\n"); err == nil {
+		t.Error("Expected an error for a big Secret")
+	}
+}
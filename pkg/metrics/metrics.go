@@ -0,0 +1,290 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*Package metrics exposes counters, gauges and histograms to Prometheus.
+
+Metrics are created once, usually as package variables, and are served
+together by Handler in the Prometheus text format:
+
+	var requests = metrics.NewCounter("myapp_requests_total", "Requests by result.", "result")
+
+	requests.Inc("success")
+
+A metric may have labels. Every update passes a value for each of them, in
+the order they were declared.
+*/
+package metrics // import "k8s.io/helm/pkg/metrics"
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are histogram buckets, in seconds, for the latency of
+// typical network requests.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// contentType is the content type of the Prometheus text format.
+const contentType = "text/plain; version=0.0.4"
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]*vec{}
+)
+
+// vec is a metric with its series, one for each set of label values.
+type vec struct {
+	name    string
+	help    string
+	typ     string
+	labels  []string
+	buckets []float64
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	values []string
+	value  float64
+	// counts are the observations of a histogram in each bucket, and above
+	// the last one.
+	counts []uint64
+}
+
+func newVec(name, help, typ string, labels []string) *vec {
+	v := &vec{name: name, help: help, typ: typ, labels: labels, series: map[string]*series{}}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[name]; ok {
+		panic(fmt.Sprintf("metrics: %s is created twice", name))
+	}
+	registry[name] = v
+	return v
+}
+
+// with returns the series of the label values. v.mu must be held.
+func (v *vec) with(values []string) *series {
+	if len(values) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: %s has %d labels, got %d values", v.name, len(v.labels), len(values)))
+	}
+	key := strings.Join(values, "\xff")
+	s, ok := v.series[key]
+	if !ok {
+		s = &series{values: append([]string{}, values...)}
+		if v.buckets != nil {
+			s.counts = make([]uint64, len(v.buckets)+1)
+		}
+		v.series[key] = s
+	}
+	return s
+}
+
+// Counter is a metric that only goes up, such as a number of requests.
+type Counter struct {
+	v *vec
+}
+
+// NewCounter creates a counter with the given labels.
+func NewCounter(name, help string, labels ...string) *Counter {
+	return &Counter{newVec(name, help, "counter", labels)}
+}
+
+// Inc adds one to the series of the label values.
+func (c *Counter) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add adds delta, which must not be negative, to the series of the label
+// values.
+func (c *Counter) Add(delta float64, values ...string) {
+	if delta < 0 {
+		panic(fmt.Sprintf("metrics: counter %s cannot decrease", c.v.name))
+	}
+	c.v.mu.Lock()
+	defer c.v.mu.Unlock()
+	c.v.with(values).value += delta
+}
+
+// Gauge is a metric that goes up and down, such as a number of requests in
+// progress.
+type Gauge struct {
+	v *vec
+}
+
+// NewGauge creates a gauge with the given labels.
+func NewGauge(name, help string, labels ...string) *Gauge {
+	return &Gauge{newVec(name, help, "gauge", labels)}
+}
+
+// Inc adds one to the series of the label values.
+func (g *Gauge) Inc(values ...string) {
+	g.Add(1, values...)
+}
+
+// Dec subtracts one from the series of the label values.
+func (g *Gauge) Dec(values ...string) {
+	g.Add(-1, values...)
+}
+
+// Add adds delta to the series of the label values.
+func (g *Gauge) Add(delta float64, values ...string) {
+	g.v.mu.Lock()
+	defer g.v.mu.Unlock()
+	g.v.with(values).value += delta
+}
+
+// Set sets the series of the label values.
+func (g *Gauge) Set(value float64, values ...string) {
+	g.v.mu.Lock()
+	defer g.v.mu.Unlock()
+	g.v.with(values).value = value
+}
+
+// Histogram is a metric that counts observations, such as the latency of
+// requests, in buckets.
+type Histogram struct {
+	v *vec
+}
+
+// NewHistogram creates a histogram with the given labels. buckets are the
+// upper bounds of the buckets, in increasing order.
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if !sort.Float64sAreSorted(buckets) {
+		panic(fmt.Sprintf("metrics: buckets of %s are not sorted", name))
+	}
+	for _, l := range labels {
+		if l == "le" {
+			panic(fmt.Sprintf("metrics: histogram %s cannot have an le label", name))
+		}
+	}
+	v := newVec(name, help, "histogram", labels)
+	v.buckets = append([]float64{}, buckets...)
+	return &Histogram{v}
+}
+
+// Observe records an observation in the series of the label values.
+func (h *Histogram) Observe(value float64, values ...string) {
+	h.v.mu.Lock()
+	defer h.v.mu.Unlock()
+	s := h.v.with(values)
+	s.counts[sort.SearchFloat64s(h.v.buckets, value)]++
+	s.value += value
+}
+
+// Write writes all metrics to w in the Prometheus text format.
+func Write(w io.Writer) error {
+	registryMu.Lock()
+	vecs := make([]*vec, 0, len(registry))
+	for _, v := range registry {
+		vecs = append(vecs, v)
+	}
+	registryMu.Unlock()
+	sort.Sort(byName(vecs))
+
+	b := bytes.NewBuffer(nil)
+	for _, v := range vecs {
+		v.write(b)
+	}
+	_, err := b.WriteTo(w)
+	return err
+}
+
+// Handler serves all metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		Write(w)
+	})
+}
+
+type byName []*vec
+
+func (b byName) Len() int           { return len(b) }
+func (b byName) Less(i, j int) bool { return b[i].name < b[j].name }
+func (b byName) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func (v *vec) write(b *bytes.Buffer) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	fmt.Fprintf(b, "# HELP %s %s\n", v.name, helpEscaper.Replace(v.help))
+	fmt.Fprintf(b, "# TYPE %s %s\n", v.name, v.typ)
+
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s := v.series[k]
+		if v.buckets == nil {
+			writeSample(b, v.name, v.labels, s.values, s.value)
+			continue
+		}
+		labels := append(append([]string{}, v.labels...), "le")
+		var count uint64
+		for i, n := range s.counts {
+			count += n
+			le := math.Inf(1)
+			if i < len(v.buckets) {
+				le = v.buckets[i]
+			}
+			writeSample(b, v.name+"_bucket", labels, append(append([]string{}, s.values...), formatFloat(le)), float64(count))
+		}
+		writeSample(b, v.name+"_sum", v.labels, s.values, s.value)
+		writeSample(b, v.name+"_count", v.labels, s.values, float64(count))
+	}
+}
+
+func writeSample(b *bytes.Buffer, name string, labels, values []string, value float64) {
+	b.WriteString(name)
+	if len(labels) > 0 {
+		b.WriteByte('{')
+		for i, l := range labels {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			fmt.Fprintf(b, "%s=\"%s\"", l, labelEscaper.Replace(values[i]))
+		}
+		b.WriteByte('}')
+	}
+	b.WriteByte(' ')
+	b.WriteString(formatFloat(value))
+	b.WriteByte('\n')
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
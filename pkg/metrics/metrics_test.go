@@ -0,0 +1,125 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// output returns the lines written for the named metric.
+func output(t *testing.T, name string) string {
+	b := bytes.NewBuffer(nil)
+	if err := Write(b); err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	for _, l := range strings.Split(b.String(), "\n") {
+		if strings.HasPrefix(l, name) || strings.HasPrefix(l, "# HELP "+name+" ") || strings.HasPrefix(l, "# TYPE "+name+" ") {
+			lines = append(lines, l)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestCounter(t *testing.T) {
+	c := NewCounter("test_counter_total", "Things by result.", "result")
+	c.Inc("success")
+	c.Inc("success")
+	c.Add(2.5, "fail\"ure")
+
+	expect := `# HELP test_counter_total Things by result.
+# TYPE test_counter_total counter
+test_counter_total{result="fail\"ure"} 2.5
+test_counter_total{result="success"} 2`
+	if got := output(t, "test_counter_total"); got != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, got)
+	}
+}
+
+func TestGauge(t *testing.T) {
+	g := NewGauge("test_gauge", "Things in flight.")
+	g.Inc()
+	g.Inc()
+	g.Dec()
+
+	expect := `# HELP test_gauge Things in flight.
+# TYPE test_gauge gauge
+test_gauge 1`
+	if got := output(t, "test_gauge"); got != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, got)
+	}
+
+	g.Set(7)
+	if got := output(t, "test_gauge"); !strings.HasSuffix(got, "\ntest_gauge 7") {
+		t.Errorf("expected the gauge to be set, got\n%s", got)
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	h := NewHistogram("test_seconds", "How long things take.", []float64{1, 5}, "op")
+	h.Observe(0.5, "get")
+	h.Observe(1, "get")
+	h.Observe(3, "get")
+	h.Observe(10, "get")
+
+	expect := `# HELP test_seconds How long things take.
+# TYPE test_seconds histogram
+test_seconds_bucket{op="get",le="1"} 2
+test_seconds_bucket{op="get",le="5"} 3
+test_seconds_bucket{op="get",le="+Inf"} 4
+test_seconds_sum{op="get"} 14.5
+test_seconds_count{op="get"} 4`
+	if got := output(t, "test_seconds"); got != expect {
+		t.Errorf("expected\n%s\ngot\n%s", expect, got)
+	}
+}
+
+func TestLabelValueCount(t *testing.T) {
+	c := NewCounter("test_labels_total", "Things.", "a", "b")
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for a missing label value")
+		}
+	}()
+	c.Inc("only-a")
+}
+
+func TestCreatedTwice(t *testing.T) {
+	NewCounter("test_twice_total", "Things.")
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for a metric created twice")
+		}
+	}()
+	NewGauge("test_twice_total", "Things.")
+}
+
+func TestHandler(t *testing.T) {
+	NewCounter("test_handler_total", "Things.").Inc()
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := w.Header().Get("Content-Type"); ct != contentType {
+		t.Errorf("expected content type %q, got %q", contentType, ct)
+	}
+	if !strings.Contains(w.Body.String(), "\ntest_handler_total 1\n") {
+		t.Errorf("expected the counter to be served, got\n%s", w.Body.String())
+	}
+}
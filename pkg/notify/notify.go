@@ -0,0 +1,114 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*Package notify tells other systems about the lifecycle of releases.
+
+Tiller hands an Event to a Notifier whenever it has installed, upgraded,
+rolled back or deleted a release, successfully or not. Webhooks, the
+Notifier that Tiller is configured with, posts events to HTTP endpoints such
+as Slack incoming webhooks, with payloads built from templates.
+*/
+package notify // import "k8s.io/helm/pkg/notify"
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// The operations that events are sent for.
+const (
+	Install  = "install"
+	Upgrade  = "upgrade"
+	Rollback = "rollback"
+	Delete   = "delete"
+)
+
+// Event describes an operation on a release.
+type Event struct {
+	// Operation is one of Install, Upgrade, Rollback or Delete.
+	Operation string `json:"operation"`
+	// Succeeded reports whether the operation succeeded. If it did not,
+	// Error says why.
+	Succeeded bool      `json:"succeeded"`
+	Error     string    `json:"error,omitempty"`
+	Release   Release   `json:"release"`
+	Chart     Chart     `json:"chart"`
+	Diff      Diff      `json:"diff"`
+	Time      time.Time `json:"time"`
+}
+
+// Release identifies the release that an event is about.
+type Release struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Revision  int32  `json:"revision"`
+	// Status is the status of the release after the operation, e.g.
+	// "DEPLOYED" or "FAILED".
+	Status string `json:"status"`
+}
+
+// Chart identifies the chart of the release.
+type Chart struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Diff summarizes how the operation changed the resources of the release, or
+// would have, if it failed. Resources are given as Kind/name.
+type Diff struct {
+	Added   []string `json:"added"`
+	Changed []string `json:"changed"`
+	Removed []string `json:"removed"`
+}
+
+// String summarizes the diff, e.g. "1 added, 2 changed, 0 removed".
+func (d Diff) String() string {
+	return fmt.Sprintf("%d added, %d changed, %d removed", len(d.Added), len(d.Changed), len(d.Removed))
+}
+
+var pastTense = map[string]string{
+	Install:  "installed",
+	Upgrade:  "upgraded",
+	Rollback: "rolled back",
+	Delete:   "deleted",
+}
+
+// Summary describes the event in a sentence, for chat messages.
+func (e *Event) Summary() string {
+	chart := e.Chart.Name
+	if e.Chart.Version != "" {
+		chart += " " + e.Chart.Version
+	}
+	if !e.Succeeded {
+		return fmt.Sprintf("Failed to %s release %s (%s, revision %d) in %s: %s", e.Operation, e.Release.Name, chart, e.Release.Revision, e.Release.Namespace, e.Error)
+	}
+	if e.Operation == Delete {
+		return fmt.Sprintf("Deleted release %s (%s) in %s: %s", e.Release.Name, chart, e.Release.Namespace, e.Diff)
+	}
+	verb := pastTense[e.Operation]
+	if verb == "" {
+		verb = e.Operation
+	}
+	return fmt.Sprintf("%s release %s to %s, revision %d, in %s: %s", strings.Title(verb), e.Release.Name, chart, e.Release.Revision, e.Release.Namespace, e.Diff)
+}
+
+// Notifier is told about events.
+type Notifier interface {
+	// Notify is told about an event. It must not block the operation that
+	// the event is about for long, and must not modify the event.
+	Notify(*Event)
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import "testing"
+
+var upgradeEvent = &Event{
+	Operation: Upgrade,
+	Succeeded: true,
+	Release:   Release{Name: "web", Namespace: "default", Revision: 3, Status: "DEPLOYED"},
+	Chart:     Chart{Name: "nginx", Version: "1.2.0"},
+	Diff:      Diff{Added: []string{"Service/web"}, Changed: []string{"Deployment/web", "ConfigMap/web"}},
+}
+
+func TestSummary(t *testing.T) {
+	failed := *upgradeEvent
+	failed.Succeeded = false
+	failed.Error = "timed out waiting for Deployment/web"
+
+	deleted := *upgradeEvent
+	deleted.Operation = Delete
+
+	tests := []struct {
+		event  *Event
+		expect string
+	}{
+		{upgradeEvent, "Upgraded release web to nginx 1.2.0, revision 3, in default: 1 added, 2 changed, 0 removed"},
+		{&failed, "Failed to upgrade release web (nginx 1.2.0, revision 3) in default: timed out waiting for Deployment/web"},
+		{&deleted, "Deleted release web (nginx 1.2.0) in default: 1 added, 2 changed, 0 removed"},
+	}
+	for _, tt := range tests {
+		if got := tt.event.Summary(); got != tt.expect {
+			t.Errorf("expected %q, got %q", tt.expect, got)
+		}
+	}
+}
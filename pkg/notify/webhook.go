@@ -0,0 +1,220 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"text/template"
+
+	"github.com/Masterminds/sprig"
+	"github.com/ghodss/yaml"
+)
+
+// The types of webhooks.
+const (
+	// Generic webhooks are sent the event as JSON, unless they have a
+	// payload template.
+	Generic = "generic"
+	// Slack webhooks are sent a message with the summary of the event.
+	Slack = "slack"
+)
+
+// The outcomes of operations that webhooks can be limited to.
+const (
+	Success = "success"
+	Failure = "failure"
+)
+
+// slackPayload is the default payload of Slack webhooks.
+const slackPayload = `{"text": {{ json .Summary }}}`
+
+// Webhook is an HTTP endpoint that is posted events.
+type Webhook struct {
+	URL string `json:"url"`
+	// Type is Generic or Slack. If it is empty, the webhook is Generic.
+	Type string `json:"type"`
+	// Operations limits the webhook to events of these operations. If it is
+	// empty, the webhook is posted events of all operations.
+	Operations []string `json:"operations"`
+	// Outcomes limits the webhook to operations with these outcomes, Success
+	// or Failure. If it is empty, both are posted.
+	Outcomes []string `json:"outcomes"`
+	// Payload is a Go template of the body that is posted, executed with the
+	// Event. The "json" function encodes a value as JSON.
+	Payload string `json:"payload"`
+	// Headers are added to the requests.
+	Headers map[string]string `json:"headers"`
+}
+
+// Webhooks is a Notifier that posts events to webhooks.
+type Webhooks struct {
+	Hooks []*Webhook `json:"webhooks"`
+	// Client is the HTTP client used. If nil, http.DefaultClient is used.
+	Client *http.Client `json:"-"`
+}
+
+// LoadWebhooks reads webhooks from a YAML file:
+//
+//	webhooks:
+//	  - url: https://hooks.slack.com/services/...
+//	    type: slack
+//	    outcomes: [failure]
+//	  - url: https://deploys.example.com/events
+//	    operations: [install, upgrade]
+func LoadWebhooks(filename string) (*Webhooks, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	w := &Webhooks{}
+	if err := yaml.Unmarshal(b, w); err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %s", filename, err)
+	}
+	for _, h := range w.Hooks {
+		if err := h.check(); err != nil {
+			return nil, fmt.Errorf("%s: %s", filename, err)
+		}
+	}
+	return w, nil
+}
+
+// check reports the first problem with the webhook, if any.
+func (h *Webhook) check() error {
+	if h.URL == "" {
+		return fmt.Errorf("webhook without a url")
+	}
+	switch h.Type {
+	case "", Generic, Slack:
+	default:
+		return fmt.Errorf("webhook %s has unknown type %q: expected %q or %q", h.URL, h.Type, Generic, Slack)
+	}
+	for _, op := range h.Operations {
+		if _, ok := pastTense[op]; !ok {
+			return fmt.Errorf("webhook %s has unknown operation %q", h.URL, op)
+		}
+	}
+	for _, o := range h.Outcomes {
+		if o != Success && o != Failure {
+			return fmt.Errorf("webhook %s has unknown outcome %q: expected %q or %q", h.URL, o, Success, Failure)
+		}
+	}
+	_, err := h.template()
+	return err
+}
+
+// template parses the payload template of the webhook. It returns nil if the
+// event is posted as JSON.
+func (h *Webhook) template() (*template.Template, error) {
+	payload := h.Payload
+	if payload == "" && h.Type == Slack {
+		payload = slackPayload
+	}
+	if payload == "" {
+		return nil, nil
+	}
+	funcs := sprig.TxtFuncMap()
+	funcs["json"] = func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	}
+	tpl, err := template.New(h.URL).Funcs(funcs).Parse(payload)
+	if err != nil {
+		return nil, fmt.Errorf("webhook %s has an invalid payload: %s", h.URL, err)
+	}
+	return tpl, nil
+}
+
+// wants reports whether the webhook is posted e.
+func (h *Webhook) wants(e *Event) bool {
+	outcome := Success
+	if !e.Succeeded {
+		outcome = Failure
+	}
+	return (len(h.Operations) == 0 || contains(h.Operations, e.Operation)) &&
+		(len(h.Outcomes) == 0 || contains(h.Outcomes, outcome))
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Notify implements Notifier. Events are posted in the background, and
+// failures are logged.
+func (w *Webhooks) Notify(e *Event) {
+	for _, h := range w.Hooks {
+		if !h.wants(e) {
+			continue
+		}
+		go func(h *Webhook) {
+			if err := h.Post(w.Client, e); err != nil {
+				log.Printf("notify: %s", err)
+			}
+		}(h)
+	}
+}
+
+// Post posts e to the webhook with client, or http.DefaultClient if client
+// is nil.
+func (h *Webhook) Post(client *http.Client, e *Event) error {
+	tpl, err := h.template()
+	if err != nil {
+		return err
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	var body []byte
+	if tpl != nil {
+		var buf bytes.Buffer
+		if err := tpl.Execute(&buf, e); err != nil {
+			return fmt.Errorf("cannot build the payload for %s: %s", h.URL, err)
+		}
+		body = buf.Bytes()
+	} else if body, err = json.Marshal(e); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", h.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range h.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		b, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("%s returned %s: %s", h.URL, resp.Status, bytes.TrimSpace(b))
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notify
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recorder is an endpoint that records the requests it is posted.
+type recorder struct {
+	status int
+	bodies chan string
+	header chan http.Header
+}
+
+func newRecorder(status int) (*recorder, *httptest.Server) {
+	r := &recorder{status: status, bodies: make(chan string, 10), header: make(chan http.Header, 10)}
+	return r, httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		b, _ := ioutil.ReadAll(req.Body)
+		r.bodies <- string(b)
+		r.header <- req.Header
+		w.WriteHeader(r.status)
+		w.Write([]byte("nope\n"))
+	}))
+}
+
+func TestWebhookPost(t *testing.T) {
+	rec, srv := newRecorder(http.StatusOK)
+	defer srv.Close()
+
+	// Generic webhooks are sent the event.
+	if err := (&Webhook{URL: srv.URL, Headers: map[string]string{"X-Token": "s3cr3t"}}).Post(nil, upgradeEvent); err != nil {
+		t.Fatal(err)
+	}
+	var e Event
+	if err := json.Unmarshal([]byte(<-rec.bodies), &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Release.Name != "web" || e.Operation != Upgrade || len(e.Diff.Changed) != 2 {
+		t.Errorf("unexpected event %+v", e)
+	}
+	h := <-rec.header
+	if h.Get("X-Token") != "s3cr3t" || h.Get("Content-Type") != "application/json" {
+		t.Errorf("unexpected headers %v", h)
+	}
+
+	// Slack webhooks are sent the summary.
+	if err := (&Webhook{URL: srv.URL, Type: Slack}).Post(nil, upgradeEvent); err != nil {
+		t.Fatal(err)
+	}
+	expect := `{"text": "Upgraded release web to nginx 1.2.0, revision 3, in default: 1 added, 2 changed, 0 removed"}`
+	if got := <-rec.bodies; got != expect {
+		t.Errorf("expected %s, got %s", expect, got)
+	}
+	<-rec.header
+
+	// Payload templates override both.
+	payload := `{"release": {{ json .Release.Name }}, "changed": {{ .Diff.Changed | join ", " | json }}}`
+	if err := (&Webhook{URL: srv.URL, Type: Slack, Payload: payload}).Post(nil, upgradeEvent); err != nil {
+		t.Fatal(err)
+	}
+	expect = `{"release": "web", "changed": "Deployment/web, ConfigMap/web"}`
+	if got := <-rec.bodies; got != expect {
+		t.Errorf("expected %s, got %s", expect, got)
+	}
+}
+
+func TestWebhookPostFailure(t *testing.T) {
+	_, srv := newRecorder(http.StatusForbidden)
+	defer srv.Close()
+
+	err := (&Webhook{URL: srv.URL}).Post(nil, upgradeEvent)
+	if err == nil || !strings.Contains(err.Error(), "returned 403 Forbidden: nope") {
+		t.Errorf("expected the failure to be reported, got %v", err)
+	}
+}
+
+func TestWebhooksNotify(t *testing.T) {
+	rec, srv := newRecorder(http.StatusOK)
+	defer srv.Close()
+
+	w := &Webhooks{Hooks: []*Webhook{
+		{URL: srv.URL + "/all"},
+		{URL: srv.URL + "/failures", Outcomes: []string{Failure}},
+		{URL: srv.URL + "/installs", Operations: []string{Install}},
+	}}
+	w.Notify(upgradeEvent)
+
+	select {
+	case body := <-rec.bodies:
+		if !strings.Contains(body, `"operation":"upgrade"`) {
+			t.Errorf("unexpected body %s", body)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the event to be posted")
+	}
+	select {
+	case body := <-rec.bodies:
+		t.Errorf("expected only one webhook to be posted, got another %s", body)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestLoadWebhooks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-notify-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		config string
+		err    string
+	}{
+		{"webhooks:\n  - url: https://hooks.slack.com/x\n    type: slack\n    outcomes: [failure]\n  - url: https://example.com\n    operations: [install, upgrade]\n", ""},
+		{"webhooks:\n  - type: slack\n", "webhook without a url"},
+		{"webhooks:\n  - url: https://example.com\n    type: teams\n", `unknown type "teams"`},
+		{"webhooks:\n  - url: https://example.com\n    operations: [deploy]\n", `unknown operation "deploy"`},
+		{"webhooks:\n  - url: https://example.com\n    outcomes: [partial]\n", `unknown outcome "partial"`},
+		{"webhooks:\n  - url: https://example.com\n    payload: '{{ .Release'\n", "invalid payload"},
+	}
+	for _, tt := range tests {
+		file := filepath.Join(dir, "webhooks.yaml")
+		if err := ioutil.WriteFile(file, []byte(tt.config), 0644); err != nil {
+			t.Fatal(err)
+		}
+		w, err := LoadWebhooks(file)
+		if tt.err == "" {
+			if err != nil {
+				t.Errorf("unexpected error: %s", err)
+			} else if len(w.Hooks) != 2 || w.Hooks[0].Type != Slack || w.Hooks[1].Operations[1] != Upgrade {
+				t.Errorf("unexpected webhooks %+v", w.Hooks)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("expected an error containing %q, got %v", tt.err, err)
+		}
+	}
+}
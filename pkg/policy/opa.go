@@ -0,0 +1,62 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// OPA evaluates reviews with an Open Policy Agent server.
+//
+// URL is the data API document of a set of denial messages, e.g.
+// "http://opa:8181/v1/data/helm/deny". The review is the input of the query,
+// so a Rego policy might read:
+//
+//	package helm
+//
+//	deny[msg] {
+//		r := input.resources[_]
+//		r.kind == "Service"
+//		r.spec.type == "LoadBalancer"
+//		msg := sprintf("Service/%s: load balancers are not allowed", [r.metadata.name])
+//	}
+//
+// Each element of the set is a violation, either a message or an object with
+// "resource" and "message" keys. An undefined document allows the release.
+type OPA struct {
+	URL string
+	// Client is the HTTP client used. If nil, http.DefaultClient is used.
+	Client *http.Client
+}
+
+type opaRequest struct {
+	Input *Review `json:"input"`
+}
+
+type opaResponse struct {
+	Result []json.RawMessage `json:"result"`
+}
+
+// Evaluate implements Evaluator.
+func (o *OPA) Evaluate(r *Review) ([]Violation, error) {
+	var res opaResponse
+	if err := postJSON(o.Client, o.URL, &opaRequest{Input: r}, &res); err != nil {
+		return nil, err
+	}
+	return decodeViolations(res.Result)
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*Package policy evaluates the resources of a release against cluster policy.
+
+Tiller sends every release it is about to apply, together with its rendered
+resources, to an Evaluator. A release that violates policy is rejected, and the
+violations are reported to the client.
+
+Two evaluators are provided: Webhook, which posts the review to an HTTP
+endpoint, and OPA, which queries a policy of an Open Policy Agent server (which
+in turn loads Rego policies and bundles).
+*/
+package policy // import "k8s.io/helm/pkg/policy"
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/ghodss/yaml"
+
+	"k8s.io/helm/pkg/releaseutil"
+)
+
+// Review is the description of a release sent for evaluation.
+type Review struct {
+	Release Release `json:"release"`
+	// Resources are the rendered resources of the release, hooks included.
+	Resources []map[string]interface{} `json:"resources"`
+}
+
+// Release identifies the release under review.
+type Release struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Revision  int32  `json:"revision"`
+	// Operation is "install", "upgrade" or "rollback".
+	Operation    string `json:"operation"`
+	Chart        string `json:"chart"`
+	ChartVersion string `json:"chartVersion"`
+}
+
+// Violation is a breach of policy.
+type Violation struct {
+	// Resource identifies the offending resource as Kind/name. It is empty for
+	// violations of the release as a whole.
+	Resource string `json:"resource,omitempty"`
+	Message  string `json:"message"`
+}
+
+func (v Violation) String() string {
+	if v.Resource == "" {
+		return v.Message
+	}
+	return v.Resource + ": " + v.Message
+}
+
+// Evaluator decides whether a release complies with policy.
+type Evaluator interface {
+	// Evaluate returns the violations found in the review, if any. An error
+	// means that the review could not be evaluated at all.
+	Evaluate(*Review) ([]Violation, error)
+}
+
+// Error is returned for releases that violate policy.
+type Error struct {
+	Release    string
+	Violations []Violation
+}
+
+func (e *Error) Error() string {
+	b := bytes.NewBufferString(fmt.Sprintf("release %s violates policy:", e.Release))
+	for _, v := range e.Violations {
+		b.WriteString("\n  " + v.String())
+	}
+	return b.String()
+}
+
+// Resources parses a YAML stream of manifests into the resources of a review.
+func Resources(manifest string) ([]map[string]interface{}, error) {
+	docs, err := releaseutil.SplitManifest(manifest)
+	if err != nil {
+		return nil, err
+	}
+	res := []map[string]interface{}{}
+	for _, doc := range docs {
+		var r map[string]interface{}
+		if err := yaml.Unmarshal([]byte(doc.Content), &r); err != nil {
+			return nil, fmt.Errorf("could not parse manifest: %s", err)
+		}
+		if len(r) > 0 {
+			res = append(res, r)
+		}
+	}
+	return res, nil
+}
+
+// decodeViolations reads violations given either as plain messages or as
+// Violation objects.
+func decodeViolations(raw []json.RawMessage) ([]Violation, error) {
+	vs := make([]Violation, 0, len(raw))
+	for _, r := range raw {
+		var msg string
+		if err := json.Unmarshal(r, &msg); err == nil {
+			vs = append(vs, Violation{Message: msg})
+			continue
+		}
+		var v Violation
+		if err := json.Unmarshal(r, &v); err != nil {
+			return nil, fmt.Errorf("invalid violation %s", r)
+		}
+		vs = append(vs, v)
+	}
+	return vs, nil
+}
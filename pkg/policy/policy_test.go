@@ -0,0 +1,159 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const manifest = `
+---
+# Source: web/templates/svc.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  type: LoadBalancer
+---
+# Source: web/templates/empty.yaml
+`
+
+func review(t *testing.T) *Review {
+	res, err := Resources(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Review{
+		Release:   Release{Name: "web", Namespace: "default", Revision: 1, Operation: "install", Chart: "web", ChartVersion: "0.1.0"},
+		Resources: res,
+	}
+}
+
+func TestResources(t *testing.T) {
+	res, err := Resources(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 {
+		t.Fatalf("expected 1 resource, got %d", len(res))
+	}
+	if res[0]["kind"] != "Service" {
+		t.Errorf("expected a Service, got %v", res[0]["kind"])
+	}
+
+	if _, err := Resources("kind: [Service"); err == nil {
+		t.Error("expected a parse error")
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	var got Review
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(`{"allowed": false, "violations": [{"resource": "Service/web", "message": "no load balancers"}, "ask the platform team"]}`))
+	}))
+	defer srv.Close()
+
+	vs, err := (&Webhook{URL: srv.URL}).Evaluate(review(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []Violation{{Resource: "Service/web", Message: "no load balancers"}, {Message: "ask the platform team"}}
+	if !reflect.DeepEqual(vs, expect) {
+		t.Errorf("expected %v, got %v", expect, vs)
+	}
+	if got.Release.Name != "web" || len(got.Resources) != 1 {
+		t.Errorf("unexpected review sent: %v", got)
+	}
+}
+
+func TestWebhookDeniedWithoutReason(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"allowed": false}`))
+	}))
+	defer srv.Close()
+
+	vs, err := (&Webhook{URL: srv.URL}).Evaluate(review(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vs) != 1 {
+		t.Errorf("expected a violation, got %v", vs)
+	}
+}
+
+func TestWebhookError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	_, err := (&Webhook{URL: srv.URL}).Evaluate(review(t))
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("expected the server error, got %v", err)
+	}
+}
+
+func TestOPA(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Input Review `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		if len(req.Input.Resources) == 0 {
+			w.Write([]byte(`{}`))
+			return
+		}
+		w.Write([]byte(`{"result": ["Service/web: load balancers are not allowed"]}`))
+	}))
+	defer srv.Close()
+
+	opa := &OPA{URL: srv.URL}
+	vs, err := opa.Evaluate(review(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vs) != 1 || vs[0].Message != "Service/web: load balancers are not allowed" {
+		t.Errorf("unexpected violations %v", vs)
+	}
+
+	vs, err = opa.Evaluate(&Review{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vs) != 0 {
+		t.Errorf("expected an undefined document to allow the release, got %v", vs)
+	}
+}
+
+func TestError(t *testing.T) {
+	err := &Error{Release: "web", Violations: []Violation{{Resource: "Service/web", Message: "no load balancers"}, {Message: "too big"}}}
+	expect := "release web violates policy:\n  Service/web: no load balancers\n  too big"
+	if err.Error() != expect {
+		t.Errorf("expected %q, got %q", expect, err.Error())
+	}
+}
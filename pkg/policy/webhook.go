@@ -0,0 +1,86 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+// Webhook evaluates reviews by posting them, as JSON, to an HTTP endpoint.
+//
+// The endpoint answers with a JSON object:
+//
+//	{"allowed": false, "violations": [{"resource": "Deployment/web", "message": "runs as root"}]}
+//
+// Violations may also be given as plain strings.
+type Webhook struct {
+	URL string
+	// Client is the HTTP client used. If nil, http.DefaultClient is used.
+	Client *http.Client
+}
+
+type webhookResponse struct {
+	Allowed    bool              `json:"allowed"`
+	Violations []json.RawMessage `json:"violations"`
+}
+
+// Evaluate implements Evaluator.
+func (w *Webhook) Evaluate(r *Review) ([]Violation, error) {
+	var res webhookResponse
+	if err := postJSON(w.Client, w.URL, r, &res); err != nil {
+		return nil, err
+	}
+	vs, err := decodeViolations(res.Violations)
+	if err != nil {
+		return nil, err
+	}
+	if !res.Allowed && len(vs) == 0 {
+		vs = append(vs, Violation{Message: "rejected by " + w.URL})
+	}
+	return vs, nil
+}
+
+// postJSON posts in to url and decodes the response into out.
+func postJSON(client *http.Client, url string, in, out interface{}) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s: %s", url, resp.Status, bytes.TrimSpace(b))
+	}
+	if err := json.Unmarshal(b, out); err != nil {
+		return fmt.Errorf("invalid response from %s: %s", url, err)
+	}
+	return nil
+}
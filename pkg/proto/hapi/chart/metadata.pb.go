@@ -40,6 +40,8 @@ type Maintainer struct {
 	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
 	// Email is an optional email address to contact the named maintainer
 	Email string `protobuf:"bytes,2,opt,name=email" json:"email,omitempty"`
+	// URL is an optional URL to an address for the named maintainer
+	Url string `protobuf:"bytes,3,opt,name=url" json:"url,omitempty"`
 }
 
 func (m *Maintainer) Reset()                    { *m = Maintainer{} }
@@ -47,9 +49,9 @@ func (m *Maintainer) String() string            { return proto.CompactTextString
 func (*Maintainer) ProtoMessage()               {}
 func (*Maintainer) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{0} }
 
-// 	Metadata for a Chart file. This models the structure of a Chart.yaml file.
+// Metadata for a Chart file. This models the structure of a Chart.yaml file.
 //
-// 	Spec: https://k8s.io/helm/blob/master/docs/design/chart_format.md#the-chart-file
+// Spec: https://k8s.io/helm/blob/master/docs/design/chart_format.md#the-chart-file
 type Metadata struct {
 	// The name of the chart
 	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
@@ -71,6 +73,14 @@ type Metadata struct {
 	Icon string `protobuf:"bytes,9,opt,name=icon" json:"icon,omitempty"`
 	// The API Version of this chart.
 	ApiVersion string `protobuf:"bytes,10,opt,name=apiVersion" json:"apiVersion,omitempty"`
+	// A SemVer range of compatible Tiller versions
+	TillerVersion string `protobuf:"bytes,11,opt,name=tillerVersion" json:"tillerVersion,omitempty"`
+	// A SemVer range of compatible Kubernetes versions
+	KubeVersion string `protobuf:"bytes,12,opt,name=kubeVersion" json:"kubeVersion,omitempty"`
+	// Whether or not this chart is deprecated
+	Deprecated bool `protobuf:"varint,13,opt,name=deprecated" json:"deprecated,omitempty"`
+	// The name of the chart that replaces this one, if it is deprecated
+	ReplacedBy string `protobuf:"bytes,14,opt,name=replacedBy" json:"replacedBy,omitempty"`
 }
 
 func (m *Metadata) Reset()                    { *m = Metadata{} }
@@ -94,24 +104,28 @@ func init() {
 func init() { proto.RegisterFile("hapi/chart/metadata.proto", fileDescriptor2) }
 
 var fileDescriptor2 = []byte{
-	// 290 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x6c, 0x91, 0x4d, 0x4b, 0xf4, 0x30,
-	0x14, 0x85, 0xdf, 0x4e, 0xbf, 0x6f, 0x37, 0xc3, 0xe5, 0x65, 0x88, 0x2e, 0xa4, 0x74, 0xd5, 0x55,
-	0x07, 0x14, 0xc4, 0xb5, 0x20, 0x2e, 0x74, 0x3a, 0x52, 0xfc, 0x00, 0x77, 0xb1, 0x0d, 0x36, 0x68,
-	0x93, 0x92, 0x44, 0xc5, 0xff, 0xe8, 0x8f, 0x92, 0xa6, 0x9d, 0x99, 0x2e, 0xdc, 0xdd, 0x73, 0x9e,
-	0x9e, 0xdb, 0x9c, 0x04, 0x8e, 0x5a, 0xda, 0xf3, 0x75, 0xdd, 0x52, 0x65, 0xd6, 0x1d, 0x33, 0xb4,
-	0xa1, 0x86, 0x16, 0xbd, 0x92, 0x46, 0x22, 0x0c, 0xa8, 0xb0, 0x28, 0x3b, 0x07, 0xd8, 0x50, 0x2e,
-	0x0c, 0xe5, 0x82, 0x29, 0x44, 0xf0, 0x04, 0xed, 0x18, 0x71, 0x52, 0x27, 0x8f, 0x2b, 0x3b, 0xe3,
-	0x7f, 0xf0, 0x59, 0x47, 0xf9, 0x3b, 0x59, 0x58, 0x73, 0x14, 0xd9, 0xcf, 0x02, 0xa2, 0xcd, 0xb4,
-	0xf6, 0xcf, 0x18, 0x82, 0xd7, 0xca, 0x8e, 0x4d, 0x29, 0x3b, 0x23, 0x81, 0x50, 0xcb, 0x0f, 0x55,
-	0x33, 0x4d, 0xdc, 0xd4, 0xcd, 0xe3, 0x6a, 0x27, 0x07, 0xf2, 0xc9, 0x94, 0xe6, 0x52, 0x10, 0xcf,
-	0x06, 0x76, 0x12, 0x53, 0x48, 0x1a, 0xa6, 0x6b, 0xc5, 0x7b, 0x33, 0x50, 0xdf, 0xd2, 0xb9, 0x85,
-	0xc7, 0x10, 0xbd, 0xb1, 0xef, 0x2f, 0xa9, 0x1a, 0x4d, 0x02, 0xbb, 0x76, 0xaf, 0xf1, 0x02, 0x92,
-	0x6e, 0x5f, 0x4f, 0x93, 0x30, 0x75, 0xf3, 0xe4, 0x74, 0x55, 0x1c, 0x2e, 0xa0, 0x38, 0xb4, 0xaf,
-	0xe6, 0x9f, 0xe2, 0x0a, 0x02, 0x26, 0x5e, 0xb9, 0x60, 0x24, 0xb2, 0xbf, 0x9c, 0xd4, 0xd0, 0x8b,
-	0xd7, 0x52, 0x90, 0x78, 0xec, 0x35, 0xcc, 0x78, 0x02, 0x40, 0x7b, 0xfe, 0x38, 0x15, 0x00, 0x4b,
-	0x66, 0x4e, 0x96, 0x42, 0x70, 0x35, 0xa6, 0x13, 0x08, 0x1f, 0xca, 0x9b, 0x72, 0xfb, 0x54, 0x2e,
-	0xff, 0x61, 0x0c, 0xfe, 0xf5, 0xf6, 0xfe, 0xee, 0x76, 0xe9, 0x5c, 0x86, 0xcf, 0xbe, 0x3d, 0xce,
-	0x4b, 0x60, 0x9f, 0xe8, 0xec, 0x37, 0x00, 0x00, 0xff, 0xff, 0x65, 0x86, 0x8b, 0xda, 0xbf, 0x01,
-	0x00, 0x00,
+	// 353 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x92, 0xcb, 0x4b, 0xc3, 0x40,
+	0x10, 0xc6, 0x8d, 0x69, 0x5e, 0x13, 0x2b, 0x65, 0x91, 0xb2, 0x7a, 0x90, 0x50, 0x3c, 0xe4, 0x94,
+	0x82, 0x5e, 0x3c, 0x17, 0x44, 0x41, 0xdb, 0x4a, 0xf0, 0x01, 0xde, 0xb6, 0xc9, 0x60, 0x97, 0xe6,
+	0xc5, 0x66, 0xab, 0xf4, 0xff, 0xf4, 0x0f, 0x92, 0xdd, 0x24, 0x6d, 0x04, 0x6f, 0xf3, 0xfd, 0xbe,
+	0xc9, 0x4c, 0xbe, 0x61, 0xe1, 0x7c, 0xcd, 0x2a, 0x3e, 0x4d, 0xd6, 0x4c, 0xc8, 0x69, 0x8e, 0x92,
+	0xa5, 0x4c, 0xb2, 0xa8, 0x12, 0xa5, 0x2c, 0x09, 0x28, 0x2b, 0xd2, 0xd6, 0xe4, 0x01, 0x60, 0xce,
+	0x78, 0x21, 0x19, 0x2f, 0x50, 0x10, 0x02, 0x83, 0x82, 0xe5, 0x48, 0x8d, 0xc0, 0x08, 0xbd, 0x58,
+	0xd7, 0xe4, 0x0c, 0x2c, 0xcc, 0x19, 0xcf, 0xe8, 0xb1, 0x86, 0x8d, 0x20, 0x23, 0x30, 0xb7, 0x22,
+	0xa3, 0xa6, 0x66, 0xaa, 0x9c, 0xfc, 0x98, 0xe0, 0xce, 0xdb, 0x45, 0xff, 0x0e, 0x22, 0x30, 0x58,
+	0x97, 0x39, 0xb6, 0x73, 0x74, 0x4d, 0x28, 0x38, 0x75, 0xb9, 0x15, 0x09, 0xd6, 0xd4, 0x0c, 0xcc,
+	0xd0, 0x8b, 0x3b, 0xa9, 0x9c, 0x2f, 0x14, 0x35, 0x2f, 0x0b, 0x3a, 0xd0, 0x1f, 0x74, 0x92, 0x04,
+	0xe0, 0xa7, 0x58, 0x27, 0x82, 0x57, 0x52, 0xb9, 0x96, 0x76, 0xfb, 0x88, 0x5c, 0x80, 0xbb, 0xc1,
+	0xdd, 0x77, 0x29, 0xd2, 0x9a, 0xda, 0x7a, 0xec, 0x5e, 0x93, 0x5b, 0xf0, 0xf3, 0x7d, 0xe0, 0x9a,
+	0x3a, 0x81, 0x19, 0xfa, 0xd7, 0xe3, 0xe8, 0x70, 0x92, 0xe8, 0x70, 0x8f, 0xb8, 0xdf, 0x4a, 0xc6,
+	0x60, 0x63, 0xf1, 0xc9, 0x0b, 0xa4, 0xae, 0x5e, 0xd9, 0x2a, 0x95, 0x8b, 0x27, 0x65, 0x41, 0xbd,
+	0x26, 0x97, 0xaa, 0xc9, 0x25, 0x00, 0xab, 0xf8, 0x5b, 0x1b, 0x00, 0xb4, 0xd3, 0x23, 0xe4, 0x0a,
+	0x86, 0x92, 0x67, 0x19, 0x8a, 0xae, 0xc5, 0xd7, 0x2d, 0x7f, 0xa1, 0x4a, 0xba, 0xd9, 0xae, 0xb0,
+	0xeb, 0x39, 0x69, 0x92, 0xf6, 0x90, 0xda, 0x93, 0x62, 0x25, 0x30, 0x61, 0x12, 0x53, 0x3a, 0x0c,
+	0x8c, 0xd0, 0x8d, 0x7b, 0x44, 0xf9, 0x02, 0xab, 0x8c, 0x25, 0x98, 0xce, 0x76, 0xf4, 0xb4, 0xf9,
+	0x8f, 0x03, 0x99, 0x04, 0x60, 0xdf, 0x35, 0x29, 0x7c, 0x70, 0x5e, 0x17, 0x8f, 0x8b, 0xe5, 0xfb,
+	0x62, 0x74, 0x44, 0x3c, 0xb0, 0xee, 0x97, 0x2f, 0xcf, 0x4f, 0x23, 0x63, 0xe6, 0x7c, 0x58, 0xfa,
+	0x2c, 0x2b, 0x5b, 0x3f, 0x9e, 0x9b, 0xdf, 0x01, 0x00, 0xb1, 0x43, 0xe3, 0xaf, 0x59, 0x02, 0x00,
+	0x00,
 }
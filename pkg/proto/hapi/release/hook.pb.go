@@ -13,8 +13,12 @@ It is generated from these files:
 
 It has these top-level messages:
 	Hook
+	HookExecution
 	Info
+	Canary
+	BlueGreen
 	Release
+	Lock
 	Status
 */
 package release
@@ -77,6 +81,33 @@ func (x Hook_Event) String() string {
 }
 func (Hook_Event) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{0, 0} }
 
+type HookExecution_Phase int32
+
+const (
+	HookExecution_UNKNOWN   HookExecution_Phase = 0
+	HookExecution_RUNNING   HookExecution_Phase = 1
+	HookExecution_SUCCEEDED HookExecution_Phase = 2
+	HookExecution_FAILED    HookExecution_Phase = 3
+)
+
+var HookExecution_Phase_name = map[int32]string{
+	0: "UNKNOWN",
+	1: "RUNNING",
+	2: "SUCCEEDED",
+	3: "FAILED",
+}
+var HookExecution_Phase_value = map[string]int32{
+	"UNKNOWN":   0,
+	"RUNNING":   1,
+	"SUCCEEDED": 2,
+	"FAILED":    3,
+}
+
+func (x HookExecution_Phase) String() string {
+	return proto.EnumName(HookExecution_Phase_name, int32(x))
+}
+func (HookExecution_Phase) EnumDescriptor() ([]byte, []int) { return fileDescriptor0, []int{1, 0} }
+
 // Hook defines a hook object.
 type Hook struct {
 	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
@@ -90,6 +121,8 @@ type Hook struct {
 	Events []Hook_Event `protobuf:"varint,5,rep,packed,name=events,enum=hapi.release.Hook_Event" json:"events,omitempty"`
 	// LastRun indicates the date/time this was last run.
 	LastRun *google_protobuf.Timestamp `protobuf:"bytes,6,opt,name=last_run,json=lastRun" json:"last_run,omitempty"`
+	// LastExecution reports on the last time this hook was run.
+	LastExecution *HookExecution `protobuf:"bytes,7,opt,name=last_execution,json=lastExecution" json:"last_execution,omitempty"`
 }
 
 func (m *Hook) Reset()                    { *m = Hook{} }
@@ -104,34 +137,88 @@ func (m *Hook) GetLastRun() *google_protobuf.Timestamp {
 	return nil
 }
 
+func (m *Hook) GetLastExecution() *HookExecution {
+	if m != nil {
+		return m.LastExecution
+	}
+	return nil
+}
+
+// HookExecution reports on a single run of a hook.
+type HookExecution struct {
+	// Event is the event the hook was run for.
+	Event Hook_Event `protobuf:"varint,1,opt,name=event,enum=hapi.release.Hook_Event" json:"event,omitempty"`
+	// StartedAt is when the hook was created.
+	StartedAt *google_protobuf.Timestamp `protobuf:"bytes,2,opt,name=started_at,json=startedAt" json:"started_at,omitempty"`
+	// CompletedAt is when the hook finished or failed.
+	CompletedAt *google_protobuf.Timestamp `protobuf:"bytes,3,opt,name=completed_at,json=completedAt" json:"completed_at,omitempty"`
+	// Phase is the outcome of the run.
+	Phase HookExecution_Phase `protobuf:"varint,4,opt,name=phase,enum=hapi.release.HookExecution_Phase" json:"phase,omitempty"`
+	// Message describes why a failed run failed.
+	Message string `protobuf:"bytes,5,opt,name=message" json:"message,omitempty"`
+	// Logs tells where to find the output of the hook, e.g. a kubectl command.
+	Logs string `protobuf:"bytes,6,opt,name=logs" json:"logs,omitempty"`
+}
+
+func (m *HookExecution) Reset()                    { *m = HookExecution{} }
+func (m *HookExecution) String() string            { return proto.CompactTextString(m) }
+func (*HookExecution) ProtoMessage()               {}
+func (*HookExecution) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
+
+func (m *HookExecution) GetStartedAt() *google_protobuf.Timestamp {
+	if m != nil {
+		return m.StartedAt
+	}
+	return nil
+}
+
+func (m *HookExecution) GetCompletedAt() *google_protobuf.Timestamp {
+	if m != nil {
+		return m.CompletedAt
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*Hook)(nil), "hapi.release.Hook")
+	proto.RegisterType((*HookExecution)(nil), "hapi.release.HookExecution")
 	proto.RegisterEnum("hapi.release.Hook_Event", Hook_Event_name, Hook_Event_value)
+	proto.RegisterEnum("hapi.release.HookExecution_Phase", HookExecution_Phase_name, HookExecution_Phase_value)
 }
 
 func init() { proto.RegisterFile("hapi/release/hook.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 321 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x4c, 0x8f, 0x4d, 0x6e, 0xea, 0x30,
-	0x14, 0x85, 0x5f, 0x20, 0x24, 0x70, 0xe1, 0xd1, 0xd4, 0x93, 0x5a, 0x4c, 0x8a, 0x18, 0x31, 0x72,
-	0x2a, 0xaa, 0x2e, 0x00, 0x8a, 0xd5, 0x56, 0x44, 0x01, 0x99, 0xa0, 0x4a, 0x9d, 0x20, 0xa3, 0x1a,
-	0x88, 0x20, 0x71, 0x44, 0x4c, 0xd7, 0xd3, 0xf5, 0x75, 0x15, 0x95, 0x9d, 0x1f, 0x75, 0x76, 0xfd,
-	0xdd, 0xcf, 0xc7, 0x3e, 0x70, 0x77, 0xe4, 0x59, 0xec, 0x5f, 0xc4, 0x59, 0xf0, 0x5c, 0xf8, 0x47,
-	0x29, 0x4f, 0x24, 0xbb, 0x48, 0x25, 0x51, 0x4f, 0x2f, 0x48, 0xb9, 0x18, 0xdc, 0x1f, 0xa4, 0x3c,
-	0x9c, 0x85, 0x6f, 0x76, 0xbb, 0xeb, 0xde, 0x57, 0x71, 0x22, 0x72, 0xc5, 0x93, 0xac, 0xd0, 0x47,
-	0x3f, 0x0d, 0xb0, 0x5f, 0xa5, 0x3c, 0x21, 0x04, 0x76, 0xca, 0x13, 0x81, 0xad, 0xa1, 0x35, 0xee,
-	0x30, 0x33, 0x6b, 0x76, 0x8a, 0xd3, 0x4f, 0xdc, 0x28, 0x98, 0x9e, 0x35, 0xcb, 0xb8, 0x3a, 0xe2,
-	0x66, 0xc1, 0xf4, 0x8c, 0x06, 0xd0, 0x4e, 0x78, 0x1a, 0xef, 0x45, 0xae, 0xb0, 0x6d, 0x78, 0x7d,
-	0x46, 0x0f, 0xe0, 0x88, 0x2f, 0x91, 0xaa, 0x1c, 0xb7, 0x86, 0xcd, 0x71, 0x7f, 0x82, 0xc9, 0xdf,
-	0x0f, 0x12, 0xfd, 0x36, 0xa1, 0x5a, 0x60, 0xa5, 0x87, 0x9e, 0xa0, 0x7d, 0xe6, 0xb9, 0xda, 0x5e,
-	0xae, 0x29, 0x76, 0x86, 0xd6, 0xb8, 0x3b, 0x19, 0x90, 0xa2, 0x06, 0xa9, 0x6a, 0x90, 0xa8, 0xaa,
-	0xc1, 0x5c, 0xed, 0xb2, 0x6b, 0x3a, 0xfa, 0xb6, 0xa0, 0x65, 0x82, 0x50, 0x17, 0xdc, 0x4d, 0xb8,
-	0x08, 0x97, 0xef, 0xa1, 0xf7, 0x0f, 0xdd, 0x40, 0x77, 0xc5, 0xe8, 0xf6, 0x2d, 0x5c, 0x47, 0xd3,
-	0x20, 0xf0, 0x2c, 0xe4, 0x41, 0x6f, 0xb5, 0x5c, 0x47, 0x35, 0x69, 0xa0, 0x3e, 0x80, 0x56, 0xe6,
-	0x34, 0xa0, 0x11, 0xf5, 0x9a, 0xe6, 0x8a, 0x36, 0x4a, 0x60, 0x57, 0x19, 0x9b, 0xd5, 0x0b, 0x9b,
-	0xce, 0xa9, 0xd7, 0xaa, 0x33, 0x2a, 0xe2, 0x18, 0xc2, 0xe8, 0x96, 0x2d, 0x83, 0x60, 0x36, 0x7d,
-	0x5e, 0x78, 0x2e, 0xba, 0x85, 0xff, 0xc6, 0xa9, 0x51, 0x7b, 0xd6, 0xf9, 0x70, 0xcb, 0xde, 0x3b,
-	0xc7, 0x54, 0x79, 0xfc, 0x0d, 0x00, 0x00, 0xff, 0xff, 0xa4, 0x2e, 0x6f, 0xbd, 0xc8, 0x01, 0x00,
-	0x00,
+	// 488 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x92, 0xcb, 0x8e, 0xda, 0x30,
+	0x14, 0x86, 0x1b, 0x42, 0x08, 0x1c, 0x2e, 0x4d, 0xbd, 0xa9, 0x45, 0x17, 0xa5, 0xac, 0x58, 0x85,
+	0x8a, 0xaa, 0xaa, 0x2a, 0xb5, 0x8b, 0x00, 0xee, 0x14, 0x4d, 0x14, 0x90, 0x01, 0x55, 0xea, 0x06,
+	0x79, 0x66, 0x3c, 0x80, 0x48, 0xe2, 0x08, 0x9b, 0xaa, 0x8f, 0xd3, 0x27, 0xe8, 0x23, 0xf5, 0x59,
+	0x2a, 0x3b, 0x17, 0x75, 0x54, 0x69, 0x66, 0x77, 0xfc, 0xfb, 0xfb, 0xed, 0x73, 0x83, 0x97, 0x07,
+	0x96, 0x1d, 0xc7, 0x67, 0x1e, 0x73, 0x26, 0xf9, 0xf8, 0x20, 0xc4, 0xc9, 0xcf, 0xce, 0x42, 0x09,
+	0xd4, 0xd1, 0x17, 0x7e, 0x71, 0xd1, 0x7f, 0xbd, 0x17, 0x62, 0x1f, 0xf3, 0xb1, 0xb9, 0xbb, 0xb9,
+	0xdc, 0x8f, 0xd5, 0x31, 0xe1, 0x52, 0xb1, 0x24, 0xcb, 0xf1, 0xe1, 0x6f, 0x1b, 0xea, 0x5f, 0x85,
+	0x38, 0x21, 0x04, 0xf5, 0x94, 0x25, 0x1c, 0x5b, 0x03, 0x6b, 0xd4, 0xa2, 0x26, 0xd6, 0xda, 0xe9,
+	0x98, 0xde, 0xe1, 0x5a, 0xae, 0xe9, 0x58, 0x6b, 0x19, 0x53, 0x07, 0x6c, 0xe7, 0x9a, 0x8e, 0x51,
+	0x1f, 0x9a, 0x09, 0x4b, 0x8f, 0xf7, 0x5c, 0x2a, 0x5c, 0x37, 0x7a, 0x75, 0x46, 0x6f, 0xa1, 0xc1,
+	0x7f, 0xf0, 0x54, 0x49, 0xec, 0x0c, 0xec, 0x51, 0x6f, 0x82, 0xfd, 0x7f, 0x13, 0xf4, 0xf5, 0xdf,
+	0x3e, 0xd1, 0x00, 0x2d, 0x38, 0xf4, 0x1e, 0x9a, 0x31, 0x93, 0x6a, 0x77, 0xbe, 0xa4, 0xb8, 0x31,
+	0xb0, 0x46, 0xed, 0x49, 0xdf, 0xcf, 0xcb, 0xf0, 0xcb, 0x32, 0xfc, 0x4d, 0x59, 0x06, 0x75, 0x35,
+	0x4b, 0x2f, 0x29, 0x9a, 0x42, 0xcf, 0xd8, 0xf8, 0x4f, 0x7e, 0x7b, 0x51, 0x47, 0x91, 0x62, 0xd7,
+	0x98, 0x5f, 0xfd, 0xff, 0x21, 0x29, 0x11, 0xda, 0xd5, 0x96, 0xea, 0x38, 0xfc, 0x65, 0x81, 0x63,
+	0x92, 0x41, 0x6d, 0x70, 0xb7, 0xd1, 0x75, 0xb4, 0xfc, 0x16, 0x79, 0xcf, 0xd0, 0x73, 0x68, 0xaf,
+	0x28, 0xd9, 0x2d, 0xa2, 0xf5, 0x26, 0x08, 0x43, 0xcf, 0x42, 0x1e, 0x74, 0x56, 0xcb, 0xf5, 0xa6,
+	0x52, 0x6a, 0xa8, 0x07, 0xa0, 0x91, 0x39, 0x09, 0xc9, 0x86, 0x78, 0xb6, 0xb1, 0x68, 0xa2, 0x10,
+	0xea, 0xe5, 0x1b, 0xdb, 0xd5, 0x15, 0x0d, 0xe6, 0xc4, 0x73, 0xaa, 0x37, 0x4a, 0xa5, 0x61, 0x14,
+	0x4a, 0x76, 0x74, 0x19, 0x86, 0xd3, 0x60, 0x76, 0xed, 0xb9, 0xe8, 0x05, 0x74, 0x0d, 0x53, 0x49,
+	0xcd, 0xe1, 0x9f, 0x1a, 0x74, 0x1f, 0xd4, 0x80, 0x7c, 0x70, 0x4c, 0xe7, 0xcc, 0xe8, 0x1e, 0x6b,
+	0x70, 0x8e, 0xa1, 0x8f, 0x00, 0x52, 0xb1, 0xb3, 0xe2, 0x77, 0x3b, 0xa6, 0x70, 0xed, 0xc9, 0x0e,
+	0xb7, 0x0a, 0x3a, 0x50, 0xe8, 0x33, 0x74, 0x6e, 0x45, 0x92, 0xc5, 0xbc, 0x30, 0xdb, 0x4f, 0x9a,
+	0xdb, 0x15, 0x1f, 0x28, 0xf4, 0x01, 0x9c, 0xec, 0xc0, 0x24, 0x37, 0x4b, 0xd2, 0x9b, 0xbc, 0x79,
+	0x64, 0x32, 0xfe, 0x4a, 0x83, 0x34, 0xe7, 0x11, 0x06, 0x37, 0xe1, 0x52, 0xb2, 0x3d, 0xc7, 0x8e,
+	0xd9, 0xaf, 0xf2, 0xa8, 0xd7, 0x31, 0x16, 0x7b, 0x69, 0x16, 0xa5, 0x45, 0x4d, 0x3c, 0xfc, 0x04,
+	0x8e, 0x71, 0x3f, 0x1c, 0x62, 0x1b, 0x5c, 0xba, 0x8d, 0xa2, 0x45, 0x74, 0xe5, 0x59, 0xa8, 0x0b,
+	0xad, 0xf5, 0x76, 0x36, 0x23, 0x64, 0x4e, 0xe6, 0x5e, 0x0d, 0x01, 0x34, 0xbe, 0x04, 0x8b, 0x90,
+	0xcc, 0x3d, 0x7b, 0xda, 0xfa, 0xee, 0x16, 0x19, 0xdd, 0x34, 0x4c, 0x41, 0xef, 0xfe, 0x0e, 0x00,
+	0xe8, 0x47, 0x82, 0x4f, 0x6d, 0x03, 0x00, 0x00,
 }
@@ -14,6 +14,69 @@ var _ = proto.Marshal
 var _ = fmt.Errorf
 var _ = math.Inf
 
+type Canary_Phase int32
+
+const (
+	// RUNNING means the canary Deployments are being rolled out.
+	Canary_RUNNING Canary_Phase = 0
+	// VERIFYING means the canary Deployments are ready, and are being
+	// watched and checked.
+	Canary_VERIFYING Canary_Phase = 1
+	// PROMOTED means the canary passed, and the revision was rolled out
+	// in full.
+	Canary_PROMOTED Canary_Phase = 2
+	// ROLLED_BACK means the canary failed, and was removed again.
+	Canary_ROLLED_BACK Canary_Phase = 3
+)
+
+var Canary_Phase_name = map[int32]string{
+	0: "RUNNING",
+	1: "VERIFYING",
+	2: "PROMOTED",
+	3: "ROLLED_BACK",
+}
+var Canary_Phase_value = map[string]int32{
+	"RUNNING":     0,
+	"VERIFYING":   1,
+	"PROMOTED":    2,
+	"ROLLED_BACK": 3,
+}
+
+func (x Canary_Phase) String() string {
+	return proto.EnumName(Canary_Phase_name, int32(x))
+}
+func (Canary_Phase) EnumDescriptor() ([]byte, []int) { return fileDescriptor1, []int{1, 0} }
+
+type BlueGreen_Phase int32
+
+const (
+	// STAGED means the Deployments of the revision are running, but the
+	// Services still send traffic to those of the previous revision.
+	BlueGreen_STAGED BlueGreen_Phase = 0
+	// SWITCHED means the Services send traffic to the Deployments of the
+	// revision, and those of the previous revision are kept for a grace
+	// period.
+	BlueGreen_SWITCHED BlueGreen_Phase = 1
+	// PROMOTED means the Deployments of the previous revision are gone.
+	BlueGreen_PROMOTED BlueGreen_Phase = 2
+)
+
+var BlueGreen_Phase_name = map[int32]string{
+	0: "STAGED",
+	1: "SWITCHED",
+	2: "PROMOTED",
+}
+var BlueGreen_Phase_value = map[string]int32{
+	"STAGED":   0,
+	"SWITCHED": 1,
+	"PROMOTED": 2,
+}
+
+func (x BlueGreen_Phase) String() string {
+	return proto.EnumName(BlueGreen_Phase_name, int32(x))
+}
+func (BlueGreen_Phase) EnumDescriptor() ([]byte, []int) { return fileDescriptor1, []int{2, 0} }
+
 // Info describes release information.
 type Info struct {
 	Status        *Status                    `protobuf:"bytes,1,opt,name=status" json:"status,omitempty"`
@@ -21,6 +84,15 @@ type Info struct {
 	LastDeployed  *google_protobuf.Timestamp `protobuf:"bytes,3,opt,name=last_deployed,json=lastDeployed" json:"last_deployed,omitempty"`
 	// Deleted tracks when this object was deleted.
 	Deleted *google_protobuf.Timestamp `protobuf:"bytes,4,opt,name=deleted" json:"deleted,omitempty"`
+	// Metadata is information the client recorded about this revision, such
+	// as the commit or the CI build it was deployed from.
+	Metadata map[string]string `protobuf:"bytes,5,rep,name=metadata" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Canary tracks the staged rollout of this revision, if it was upgraded
+	// to with a canary.
+	Canary *Canary `protobuf:"bytes,6,opt,name=canary" json:"canary,omitempty"`
+	// BlueGreen tracks the blue/green rollout of this revision, if it was
+	// upgraded to with one.
+	BlueGreen *BlueGreen `protobuf:"bytes,7,opt,name=blue_green,json=blueGreen" json:"blue_green,omitempty"`
 }
 
 func (m *Info) Reset()                    { *m = Info{} }
@@ -56,26 +128,108 @@ func (m *Info) GetDeleted() *google_protobuf.Timestamp {
 	return nil
 }
 
+func (m *Info) GetMetadata() map[string]string {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+func (m *Info) GetCanary() *Canary {
+	if m != nil {
+		return m.Canary
+	}
+	return nil
+}
+
+func (m *Info) GetBlueGreen() *BlueGreen {
+	if m != nil {
+		return m.BlueGreen
+	}
+	return nil
+}
+
+// Canary describes a staged rollout, in which a share of the replicas of each
+// Deployment of a release is run with the new revision before all of them
+// are.
+type Canary struct {
+	Phase Canary_Phase `protobuf:"varint,1,opt,name=phase,enum=hapi.release.Canary_Phase" json:"phase,omitempty"`
+	// Percent is the share of the replicas of each Deployment run as canary.
+	Percent int32 `protobuf:"varint,2,opt,name=percent" json:"percent,omitempty"`
+	// Deployments are the names of the canary Deployments.
+	Deployments []string `protobuf:"bytes,3,rep,name=deployments" json:"deployments,omitempty"`
+	// Message says why the canary was rolled back.
+	Message string `protobuf:"bytes,4,opt,name=message" json:"message,omitempty"`
+}
+
+func (m *Canary) Reset()                    { *m = Canary{} }
+func (m *Canary) String() string            { return proto.CompactTextString(m) }
+func (*Canary) ProtoMessage()               {}
+func (*Canary) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{1} }
+
+// BlueGreen describes a blue/green rollout, in which the Deployments of a new
+// revision run next to those of the previous one, and the Services of the
+// release are only switched over to them when the revision is promoted.
+type BlueGreen struct {
+	Phase BlueGreen_Phase `protobuf:"varint,1,opt,name=phase,enum=hapi.release.BlueGreen_Phase" json:"phase,omitempty"`
+	// Slot is the value of the helm.sh/slot label of the Deployments of the
+	// revision, "blue" or "green".
+	Slot string `protobuf:"bytes,2,opt,name=slot" json:"slot,omitempty"`
+	// Deployments are the names of the Deployments of the revision.
+	Deployments []string `protobuf:"bytes,3,rep,name=deployments" json:"deployments,omitempty"`
+	// Services is the manifest of the Services of the revision before it is
+	// switched over.
+	Services string `protobuf:"bytes,4,opt,name=services" json:"services,omitempty"`
+}
+
+func (m *BlueGreen) Reset()                    { *m = BlueGreen{} }
+func (m *BlueGreen) String() string            { return proto.CompactTextString(m) }
+func (*BlueGreen) ProtoMessage()               {}
+func (*BlueGreen) Descriptor() ([]byte, []int) { return fileDescriptor1, []int{2} }
+
 func init() {
 	proto.RegisterType((*Info)(nil), "hapi.release.Info")
+	proto.RegisterType((*Canary)(nil), "hapi.release.Canary")
+	proto.RegisterType((*BlueGreen)(nil), "hapi.release.BlueGreen")
+	proto.RegisterEnum("hapi.release.Canary_Phase", Canary_Phase_name, Canary_Phase_value)
+	proto.RegisterEnum("hapi.release.BlueGreen_Phase", BlueGreen_Phase_name, BlueGreen_Phase_value)
 }
 
 func init() { proto.RegisterFile("hapi/release/info.proto", fileDescriptor1) }
 
 var fileDescriptor1 = []byte{
-	// 212 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0xe2, 0x12, 0xcf, 0x48, 0x2c, 0xc8,
-	0xd4, 0x2f, 0x4a, 0xcd, 0x49, 0x4d, 0x2c, 0x4e, 0xd5, 0xcf, 0xcc, 0x4b, 0xcb, 0xd7, 0x2b, 0x28,
-	0xca, 0x2f, 0xc9, 0x17, 0xe2, 0x01, 0x49, 0xe8, 0x41, 0x25, 0xa4, 0xe4, 0xd3, 0xf3, 0xf3, 0xd3,
-	0x73, 0x52, 0xf5, 0xc1, 0x72, 0x49, 0xa5, 0x69, 0xfa, 0x25, 0x99, 0xb9, 0xa9, 0xc5, 0x25, 0x89,
-	0xb9, 0x05, 0x10, 0xe5, 0x52, 0x92, 0x28, 0xe6, 0x14, 0x97, 0x24, 0x96, 0x94, 0x16, 0x43, 0xa4,
-	0x94, 0xde, 0x31, 0x72, 0xb1, 0x78, 0xe6, 0xa5, 0xe5, 0x0b, 0xe9, 0x70, 0xb1, 0x41, 0x24, 0x24,
-	0x18, 0x15, 0x18, 0x35, 0xb8, 0x8d, 0x44, 0xf4, 0x90, 0xed, 0xd0, 0x0b, 0x06, 0xcb, 0x05, 0x41,
-	0xd5, 0x08, 0x39, 0x72, 0xf1, 0xa5, 0x65, 0x16, 0x15, 0x97, 0xc4, 0xa7, 0xa4, 0x16, 0xe4, 0xe4,
-	0x57, 0xa6, 0xa6, 0x48, 0x30, 0x81, 0x75, 0x49, 0xe9, 0x41, 0xdc, 0xa2, 0x07, 0x73, 0x8b, 0x5e,
-	0x08, 0xcc, 0x2d, 0x41, 0xbc, 0x60, 0x1d, 0x2e, 0x50, 0x0d, 0x42, 0xf6, 0x5c, 0xbc, 0x39, 0x89,
-	0xc8, 0x26, 0x30, 0x13, 0x34, 0x81, 0x07, 0xa4, 0x01, 0x6e, 0x80, 0x09, 0x17, 0x7b, 0x4a, 0x6a,
-	0x4e, 0x6a, 0x49, 0x6a, 0x8a, 0x04, 0x0b, 0x41, 0xad, 0x30, 0xa5, 0x4e, 0x9c, 0x51, 0xec, 0x50,
-	0x3f, 0x25, 0xb1, 0x81, 0xd5, 0x19, 0x03, 0x02, 0x00, 0x00, 0xff, 0xff, 0xeb, 0x9d, 0xa1, 0xf8,
-	0x67, 0x01, 0x00, 0x00,
+	// 516 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x52, 0x5d, 0x8b, 0xda, 0x4c,
+	0x18, 0xdd, 0x18, 0xbf, 0xf2, 0xa8, 0xfb, 0xca, 0xb0, 0xb0, 0x79, 0x03, 0xa5, 0xe2, 0x95, 0x17,
+	0x25, 0x16, 0xb7, 0x94, 0xd2, 0x16, 0x8a, 0x1f, 0xa9, 0x95, 0xee, 0xea, 0x32, 0xda, 0x96, 0xf6,
+	0x46, 0x46, 0x7d, 0x74, 0xa5, 0x93, 0x0f, 0x32, 0x93, 0x05, 0x7f, 0x5f, 0x7f, 0x40, 0x6f, 0xfa,
+	0x83, 0x4a, 0x26, 0x89, 0x98, 0xb2, 0x65, 0x7b, 0x37, 0x4f, 0xce, 0x39, 0xcf, 0x9c, 0x73, 0x26,
+	0x70, 0x79, 0xc7, 0x82, 0x7d, 0x37, 0x44, 0x8e, 0x4c, 0x60, 0x77, 0xef, 0x6d, 0x7d, 0x3b, 0x08,
+	0x7d, 0xe9, 0x93, 0x7a, 0x0c, 0xd8, 0x29, 0x60, 0x3d, 0xdd, 0xf9, 0xfe, 0x8e, 0x63, 0x57, 0x61,
+	0xab, 0x68, 0xdb, 0x95, 0x7b, 0x17, 0x85, 0x64, 0x6e, 0x90, 0xd0, 0xad, 0xff, 0x73, 0x7b, 0x84,
+	0x64, 0x32, 0x12, 0x09, 0xd4, 0xfe, 0xa9, 0x43, 0x71, 0xe2, 0x6d, 0x7d, 0xf2, 0x0c, 0xca, 0x09,
+	0x60, 0x6a, 0x2d, 0xad, 0x53, 0xeb, 0x5d, 0xd8, 0xa7, 0x77, 0xd8, 0x73, 0x85, 0xd1, 0x94, 0x43,
+	0xfa, 0x70, 0xbe, 0xdd, 0x87, 0x42, 0x2e, 0x37, 0x18, 0x70, 0xff, 0x80, 0x1b, 0xb3, 0xa0, 0x54,
+	0x96, 0x9d, 0x78, 0xb1, 0x33, 0x2f, 0xf6, 0x22, 0xf3, 0x42, 0x1b, 0x4a, 0x31, 0x4a, 0x05, 0xe4,
+	0x1d, 0x34, 0x38, 0x3b, 0xdd, 0xa0, 0x3f, 0xba, 0xa1, 0xce, 0xd9, 0xc9, 0x82, 0x17, 0x50, 0xd9,
+	0x20, 0x47, 0x89, 0x1b, 0xb3, 0xf8, 0xa8, 0x34, 0xa3, 0x92, 0xb7, 0x50, 0x75, 0x51, 0xb2, 0x0d,
+	0x93, 0xcc, 0x2c, 0xb5, 0xf4, 0x4e, 0xad, 0xd7, 0xca, 0x27, 0x8d, 0xdb, 0xb0, 0x6f, 0x52, 0x8a,
+	0xe3, 0xc9, 0xf0, 0x40, 0x8f, 0x8a, 0xb8, 0xa5, 0x35, 0xf3, 0x58, 0x78, 0x30, 0xcb, 0x0f, 0xb5,
+	0x34, 0x54, 0x18, 0x4d, 0x39, 0xe4, 0x25, 0xc0, 0x8a, 0x47, 0xb8, 0xdc, 0x85, 0x88, 0x9e, 0x59,
+	0x51, 0x8a, 0xcb, 0xbc, 0x62, 0xc0, 0x23, 0x1c, 0xc7, 0x30, 0x35, 0x56, 0xd9, 0xd1, 0x7a, 0x03,
+	0x8d, 0x9c, 0x01, 0xd2, 0x04, 0xfd, 0x3b, 0x1e, 0xd4, 0xcb, 0x18, 0x34, 0x3e, 0x92, 0x0b, 0x28,
+	0xdd, 0x33, 0x1e, 0xa1, 0xea, 0xdd, 0xa0, 0xc9, 0xf0, 0xba, 0xf0, 0x4a, 0x6b, 0xff, 0xd2, 0xa0,
+	0x9c, 0xf8, 0x20, 0xcf, 0xa1, 0x14, 0xdc, 0x31, 0x81, 0x4a, 0x78, 0xde, 0xb3, 0x1e, 0x32, 0x6b,
+	0xdf, 0xc6, 0x0c, 0x9a, 0x10, 0x89, 0x09, 0x95, 0x00, 0xc3, 0x35, 0x7a, 0x52, 0x2d, 0x2e, 0xd1,
+	0x6c, 0x24, 0x2d, 0xa8, 0x25, 0x2f, 0xe5, 0xa2, 0x27, 0x85, 0xa9, 0xb7, 0xf4, 0x8e, 0x41, 0x4f,
+	0x3f, 0xc5, 0x5a, 0x17, 0x85, 0x60, 0x3b, 0x54, 0xef, 0x61, 0xd0, 0x6c, 0x6c, 0x0f, 0xa0, 0xa4,
+	0x6e, 0x21, 0x35, 0xa8, 0xd0, 0x4f, 0xd3, 0xe9, 0x64, 0x3a, 0x6e, 0x9e, 0x91, 0x06, 0x18, 0x9f,
+	0x1d, 0x3a, 0x79, 0xff, 0x35, 0x1e, 0x35, 0x52, 0x87, 0xea, 0x2d, 0x9d, 0xdd, 0xcc, 0x16, 0xce,
+	0xa8, 0x59, 0x20, 0xff, 0x41, 0x8d, 0xce, 0xae, 0xaf, 0x9d, 0xd1, 0x72, 0xd0, 0x1f, 0x7e, 0x6c,
+	0xea, 0xed, 0x1f, 0x1a, 0x18, 0xc7, 0xb2, 0xc8, 0x55, 0x3e, 0xd9, 0x93, 0xbf, 0x94, 0x9a, 0x0f,
+	0x47, 0xa0, 0x28, 0xb8, 0x2f, 0xd3, 0xca, 0xd4, 0xf9, 0x1f, 0x62, 0x59, 0x50, 0x15, 0x18, 0xde,
+	0xef, 0xd7, 0x28, 0xd2, 0x5c, 0xc7, 0xb9, 0xdd, 0xcd, 0x82, 0x01, 0x94, 0xe7, 0x8b, 0xfe, 0xd8,
+	0x19, 0x35, 0xcf, 0xe2, 0x20, 0xf3, 0x2f, 0x93, 0xc5, 0xf0, 0x83, 0x33, 0xfa, 0x33, 0xd6, 0xc0,
+	0xf8, 0x56, 0x49, 0x4d, 0xae, 0xca, 0xea, 0x2f, 0xbd, 0xfa, 0x3d, 0x00, 0xe7, 0xc6, 0xbb, 0xd1,
+	0xe5, 0x03, 0x00, 0x00,
 }
@@ -9,6 +9,7 @@ import fmt "fmt"
 import math "math"
 import hapi_chart "k8s.io/helm/pkg/proto/hapi/chart"
 import hapi_chart3 "k8s.io/helm/pkg/proto/hapi/chart"
+import google_protobuf "github.com/golang/protobuf/ptypes/timestamp"
 
 // Reference imports to suppress errors if they are not otherwise used.
 var _ = proto.Marshal
@@ -35,6 +36,19 @@ type Release struct {
 	Version int32 `protobuf:"varint,7,opt,name=version" json:"version,omitempty"`
 	// Namespace is the kubernetes namespace of the release.
 	Namespace string `protobuf:"bytes,8,opt,name=namespace" json:"namespace,omitempty"`
+	// Seed is random data generated when the release is first installed and
+	// kept across upgrades. Stable random template functions derive their
+	// values from it.
+	Seed []byte `protobuf:"bytes,9,opt,name=seed,proto3" json:"seed,omitempty"`
+	// Signature, if set, proves that Tiller stored this record.
+	Signature *Signature `protobuf:"bytes,10,opt,name=signature" json:"signature,omitempty"`
+	// Overrides are the values set with 'helm values set'. They are merged
+	// over the values of every upgrade, and carried over to each revision.
+	Overrides *hapi_chart.Config `protobuf:"bytes,11,opt,name=overrides" json:"overrides,omitempty"`
+	// Lock, if set, makes Tiller refuse to upgrade, roll back or delete the
+	// release unless the request overrides the lock. It is carried over to
+	// each revision.
+	Lock *Lock `protobuf:"bytes,12,opt,name=lock" json:"lock,omitempty"`
 }
 
 func (m *Release) Reset()                    { *m = Release{} }
@@ -70,28 +84,97 @@ func (m *Release) GetHooks() []*Hook {
 	return nil
 }
 
+func (m *Release) GetSignature() *Signature {
+	if m != nil {
+		return m.Signature
+	}
+	return nil
+}
+
+func (m *Release) GetOverrides() *hapi_chart.Config {
+	if m != nil {
+		return m.Overrides
+	}
+	return nil
+}
+
+func (m *Release) GetLock() *Lock {
+	if m != nil {
+		return m.Lock
+	}
+	return nil
+}
+
+// Lock protects a release from changes, e.g. during a change freeze.
+type Lock struct {
+	// Reason is why the release was locked.
+	Reason string `protobuf:"bytes,1,opt,name=reason" json:"reason,omitempty"`
+	// Locked is when the release was locked.
+	Locked *google_protobuf.Timestamp `protobuf:"bytes,2,opt,name=locked" json:"locked,omitempty"`
+}
+
+func (m *Lock) Reset()                    { *m = Lock{} }
+func (m *Lock) String() string            { return proto.CompactTextString(m) }
+func (*Lock) ProtoMessage()               {}
+func (*Lock) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{1} }
+
+func (m *Lock) GetLocked() *google_protobuf.Timestamp {
+	if m != nil {
+		return m.Locked
+	}
+	return nil
+}
+
+// Signature signs a release record and links it to the record of the
+// previous revision, so that changed or missing records can be detected.
+type Signature struct {
+	// Previous is the digest of the previous revision's record, if there was
+	// one when this record was signed.
+	Previous string `protobuf:"bytes,1,opt,name=previous" json:"previous,omitempty"`
+	// Signature is an ASCII-armored detached PGP signature of the record's
+	// name, revision and digest, and of previous.
+	Signature string `protobuf:"bytes,2,opt,name=signature" json:"signature,omitempty"`
+}
+
+func (m *Signature) Reset()                    { *m = Signature{} }
+func (m *Signature) String() string            { return proto.CompactTextString(m) }
+func (*Signature) ProtoMessage()               {}
+func (*Signature) Descriptor() ([]byte, []int) { return fileDescriptor2, []int{2} }
+
 func init() {
 	proto.RegisterType((*Release)(nil), "hapi.release.Release")
+	proto.RegisterType((*Lock)(nil), "hapi.release.Lock")
+	proto.RegisterType((*Signature)(nil), "hapi.release.Signature")
 }
 
 func init() { proto.RegisterFile("hapi/release/release.proto", fileDescriptor2) }
 
 var fileDescriptor2 = []byte{
-	// 256 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x64, 0x90, 0xbf, 0x4e, 0xc3, 0x40,
-	0x0c, 0xc6, 0x95, 0x36, 0x7f, 0x1a, 0xc3, 0x82, 0x07, 0xb0, 0x22, 0x86, 0x88, 0x01, 0x22, 0x86,
-	0x54, 0x82, 0x37, 0x80, 0x05, 0xd6, 0x1b, 0xd9, 0x8e, 0xe8, 0x42, 0x4e, 0xa5, 0xe7, 0x28, 0x17,
-	0xf1, 0x2c, 0x3c, 0x2e, 0xba, 0x3f, 0x85, 0x94, 0x2e, 0x4e, 0xec, 0xdf, 0xa7, 0xcf, 0xdf, 0x19,
-	0xaa, 0x41, 0x8e, 0x7a, 0x3b, 0xa9, 0x4f, 0x25, 0xad, 0x3a, 0x7c, 0xdb, 0x71, 0xe2, 0x99, 0xf1,
-	0xdc, 0xb1, 0x36, 0xce, 0xaa, 0xab, 0x23, 0xe5, 0xc0, 0xbc, 0x0b, 0xb2, 0x7f, 0x40, 0x9b, 0x9e,
-	0x8f, 0x40, 0x37, 0xc8, 0x69, 0xde, 0x76, 0x6c, 0x7a, 0xfd, 0x11, 0xc1, 0xe5, 0x12, 0xb8, 0x1a,
-	0xe6, 0x37, 0xdf, 0x2b, 0x28, 0x44, 0xf0, 0x41, 0x84, 0xd4, 0xc8, 0xbd, 0xa2, 0xa4, 0x4e, 0x9a,
-	0x52, 0xf8, 0x7f, 0xbc, 0x85, 0xd4, 0xd9, 0xd3, 0xaa, 0x4e, 0x9a, 0xb3, 0x07, 0x6c, 0x97, 0xf9,
-	0xda, 0x57, 0xd3, 0xb3, 0xf0, 0x1c, 0xef, 0x20, 0xf3, 0xb6, 0xb4, 0xf6, 0xc2, 0x8b, 0x20, 0x0c,
-	0x9b, 0x9e, 0x5d, 0x15, 0x81, 0xe3, 0x3d, 0xe4, 0x21, 0x18, 0xa5, 0x4b, 0xcb, 0xa8, 0xf4, 0x44,
-	0x44, 0x05, 0x56, 0xb0, 0xd9, 0x4b, 0xa3, 0x7b, 0x65, 0x67, 0xca, 0x7c, 0xa8, 0xdf, 0x1e, 0x1b,
-	0xc8, 0xdc, 0x41, 0x2c, 0xe5, 0xf5, 0xfa, 0x34, 0xd9, 0x0b, 0xf3, 0x4e, 0x04, 0x01, 0x12, 0x14,
-	0x5f, 0x6a, 0xb2, 0x9a, 0x0d, 0x15, 0x75, 0xd2, 0x64, 0xe2, 0xd0, 0xe2, 0x35, 0x94, 0xee, 0x91,
-	0x76, 0x94, 0x9d, 0xa2, 0x8d, 0x5f, 0xf0, 0x37, 0x78, 0x2a, 0xdf, 0x8a, 0x68, 0xf7, 0x9e, 0xfb,
-	0x63, 0x3d, 0xfe, 0x04, 0x00, 0x00, 0xff, 0xff, 0xc8, 0x8f, 0xec, 0x97, 0xbb, 0x01, 0x00, 0x00,
+	// 403 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x92, 0xcd, 0x8e, 0xd3, 0x30,
+	0x10, 0xc7, 0x95, 0x6d, 0x9b, 0x6e, 0x66, 0x7b, 0xc1, 0x87, 0x5d, 0xab, 0x42, 0x22, 0xea, 0x01,
+	0x22, 0x0e, 0x29, 0x5a, 0xc4, 0x0b, 0x80, 0x90, 0x40, 0xe2, 0x64, 0x38, 0x71, 0x73, 0xd3, 0x49,
+	0x6b, 0xa5, 0xf1, 0x44, 0x76, 0xda, 0xb7, 0xe3, 0xdd, 0x90, 0x3f, 0x92, 0xb4, 0x7c, 0x5c, 0x12,
+	0xcf, 0xfc, 0x7f, 0xf2, 0xcc, 0xfc, 0x3d, 0xb0, 0x3e, 0xca, 0x4e, 0x6d, 0x0d, 0x9e, 0x50, 0x5a,
+	0x1c, 0xfe, 0x65, 0x67, 0xa8, 0x27, 0xb6, 0x72, 0x5a, 0x19, 0x73, 0xeb, 0xa7, 0x1b, 0xf2, 0x48,
+	0xd4, 0x04, 0xec, 0x0f, 0x41, 0xe9, 0x9a, 0x6e, 0x84, 0xea, 0x28, 0x4d, 0xbf, 0xad, 0x48, 0xd7,
+	0xea, 0x10, 0x85, 0xc7, 0x6b, 0xc1, 0x7d, 0x63, 0xfe, 0xd5, 0x81, 0xe8, 0x70, 0xc2, 0xad, 0x8f,
+	0x76, 0xe7, 0x7a, 0xdb, 0xab, 0x16, 0x6d, 0x2f, 0xdb, 0x2e, 0x00, 0x9b, 0x5f, 0x33, 0x58, 0x8a,
+	0x50, 0x88, 0x31, 0x98, 0x6b, 0xd9, 0x22, 0x4f, 0xf2, 0xa4, 0xc8, 0x84, 0x3f, 0xb3, 0xd7, 0x30,
+	0x77, 0xf5, 0xf9, 0x5d, 0x9e, 0x14, 0x0f, 0xcf, 0xac, 0xbc, 0x1e, 0xa0, 0xfc, 0xaa, 0x6b, 0x12,
+	0x5e, 0x67, 0x6f, 0x60, 0xe1, 0xeb, 0xf2, 0x99, 0x07, 0x5f, 0x04, 0x30, 0xb4, 0xf2, 0xc9, 0x7d,
+	0x45, 0xd0, 0xd9, 0x5b, 0x48, 0x43, 0xe7, 0x7c, 0x7e, 0x7d, 0x65, 0x24, 0xbd, 0x22, 0x22, 0xc1,
+	0xd6, 0x70, 0xdf, 0x4a, 0xad, 0x6a, 0xb4, 0x3d, 0x5f, 0xf8, 0xa6, 0xc6, 0x98, 0x15, 0xb0, 0x70,
+	0x8e, 0x59, 0x9e, 0xe6, 0xb3, 0xbf, 0x3b, 0xfb, 0x42, 0xd4, 0x88, 0x00, 0x30, 0x0e, 0xcb, 0x0b,
+	0x1a, 0xab, 0x48, 0xf3, 0x65, 0x9e, 0x14, 0x0b, 0x31, 0x84, 0xec, 0x25, 0x64, 0x6e, 0x48, 0xdb,
+	0xc9, 0x0a, 0xf9, 0xbd, 0x2f, 0x30, 0x25, 0x9c, 0x1d, 0x16, 0x71, 0xcf, 0xb3, 0x3c, 0x29, 0x56,
+	0xc2, 0x9f, 0xd9, 0x07, 0xc8, 0xac, 0x3a, 0x68, 0xd9, 0x9f, 0x0d, 0x72, 0xf0, 0x03, 0x3c, 0xdd,
+	0x56, 0xfe, 0x3e, 0xc8, 0x62, 0x22, 0xd9, 0x3b, 0xc8, 0xe8, 0x82, 0xc6, 0xa8, 0x3d, 0x5a, 0xfe,
+	0xf0, 0xdf, 0xb9, 0x27, 0xc8, 0xf9, 0x7e, 0xa2, 0xaa, 0xe1, 0xab, 0x7f, 0xf9, 0xfe, 0x8d, 0xaa,
+	0x46, 0x78, 0x7d, 0x23, 0x60, 0xee, 0x22, 0xf6, 0x08, 0xa9, 0x41, 0x69, 0x49, 0xc7, 0xd7, 0x8b,
+	0x11, 0x7b, 0x86, 0xd4, 0x71, 0xb8, 0x8f, 0x2f, 0xb8, 0x2e, 0xc3, 0x46, 0x94, 0xc3, 0x46, 0x94,
+	0x3f, 0x86, 0x8d, 0x10, 0x91, 0xdc, 0x7c, 0x86, 0x6c, 0x9c, 0xc2, 0xbd, 0x41, 0x67, 0xf0, 0xa2,
+	0xe8, 0x6c, 0xe3, 0xd5, 0x63, 0xec, 0xfc, 0x9b, 0xdc, 0xb8, 0x0b, 0xfe, 0x8d, 0x89, 0x8f, 0xd9,
+	0xcf, 0x65, 0x6c, 0x78, 0x97, 0xfa, 0x6a, 0xef, 0x7f, 0x0f, 0x00, 0xba, 0x44, 0xec, 0x9b, 0x1c,
+	0x03, 0x00, 0x00,
 }
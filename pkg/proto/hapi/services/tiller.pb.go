@@ -21,6 +21,7 @@ It has these top-level messages:
 	RollbackReleaseRequest
 	RollbackReleaseResponse
 	InstallReleaseRequest
+	ChartSource
 	InstallReleaseResponse
 	UninstallReleaseRequest
 	UninstallReleaseResponse
@@ -28,6 +29,25 @@ It has these top-level messages:
 	GetVersionResponse
 	GetHistoryRequest
 	GetHistoryResponse
+	ImportReleaseRequest
+	ImportReleaseResponse
+	ResourceEvent
+	ReleaseProgressResponse
+	GetReleaseDriftRequest
+	GetReleaseDriftResponse
+	ResourceDrift
+	FieldDrift
+	SyncReleaseRequest
+	SyncReleaseResponse
+	SetReleaseValuesRequest
+	SetReleaseValuesResponse
+	MaintainStorageRequest
+	StorageUsage
+	MaintainStorageResponse
+	SetReleaseLockRequest
+	SetReleaseLockResponse
+	PromoteReleaseRequest
+	PromoteReleaseResponse
 */
 package services
 
@@ -246,6 +266,62 @@ type UpdateReleaseRequest struct {
 	DryRun bool `protobuf:"varint,4,opt,name=dry_run,json=dryRun" json:"dry_run,omitempty"`
 	// DisableHooks causes the server to skip running any hooks for the upgrade.
 	DisableHooks bool `protobuf:"varint,5,opt,name=disable_hooks,json=disableHooks" json:"disable_hooks,omitempty"`
+	// ServerSide, if true, updates resources using server-side apply.
+	ServerSide bool `protobuf:"varint,6,opt,name=server_side,json=serverSide" json:"server_side,omitempty"`
+	// IncludeTemplates limits the upgrade to templates matching one of these
+	// path globs or Kind/name selectors.
+	IncludeTemplates []string `protobuf:"bytes,7,rep,name=include_templates,json=includeTemplates" json:"include_templates,omitempty"`
+	// ExcludeTemplates skips templates matching one of these path globs or
+	// Kind/name selectors.
+	ExcludeTemplates []string `protobuf:"bytes,8,rep,name=exclude_templates,json=excludeTemplates" json:"exclude_templates,omitempty"`
+	// DisableVersionCheck skips the chart's kubeVersion and tillerVersion checks.
+	DisableVersionCheck bool `protobuf:"varint,9,opt,name=disable_version_check,json=disableVersionCheck" json:"disable_version_check,omitempty"`
+	// SubchartNotes, if true, appends the notes of subcharts to those of the chart.
+	SubchartNotes bool `protobuf:"varint,10,opt,name=subchart_notes,json=subchartNotes" json:"subchart_notes,omitempty"`
+	// ChartSource describes where the chart came from.
+	ChartSource *ChartSource `protobuf:"bytes,11,opt,name=chart_source,json=chartSource" json:"chart_source,omitempty"`
+	// Wait, if true, waits until the resources of the release are ready
+	// before marking the release as successful.
+	Wait bool `protobuf:"varint,12,opt,name=wait" json:"wait,omitempty"`
+	// WaitForJobs, if true, also waits for the Jobs of the release to complete.
+	WaitForJobs bool `protobuf:"varint,13,opt,name=wait_for_jobs,json=waitForJobs" json:"wait_for_jobs,omitempty"`
+	// Timeout is how long to wait, in seconds. Zero means five minutes.
+	Timeout int64 `protobuf:"varint,14,opt,name=timeout" json:"timeout,omitempty"`
+	// Metadata is recorded on the new revision.
+	Metadata map[string]string `protobuf:"bytes,15,rep,name=metadata" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// OnlySubcharts limits the upgrade to the templates of these subcharts,
+	// named by their path from the chart, e.g. "redis" or "backend/redis".
+	OnlySubcharts []string `protobuf:"bytes,16,rep,name=only_subcharts,json=onlySubcharts" json:"only_subcharts,omitempty"`
+	// ServerDryRun, if true along with dry_run, also sends the resources of
+	// the upgrade to the Kubernetes API as a dry run, so that its validation
+	// and admission webhooks check them without anything being persisted.
+	ServerDryRun bool `protobuf:"varint,17,opt,name=server_dry_run,json=serverDryRun" json:"server_dry_run,omitempty"`
+	// OverrideLock, if the release is locked, is the reason for upgrading it
+	// anyway. It is recorded in the metadata of the new revision.
+	OverrideLock string `protobuf:"bytes,18,opt,name=override_lock,json=overrideLock" json:"override_lock,omitempty"`
+	// OverrideGates, if the deployment windows or gates of Tiller do not
+	// allow the upgrade now, is the reason for upgrading anyway. It is
+	// recorded in the metadata of the new revision.
+	OverrideGates string `protobuf:"bytes,19,opt,name=override_gates,json=overrideGates" json:"override_gates,omitempty"`
+	// CanaryPercent, if set, first runs this share of the replicas of each
+	// Deployment of the release with the new revision, in a separate
+	// Deployment. The upgrade is only rolled out in full if the canary
+	// becomes ready and passes its checks; otherwise it is removed, and the
+	// release is left as it was.
+	CanaryPercent int32 `protobuf:"varint,20,opt,name=canary_percent,json=canaryPercent" json:"canary_percent,omitempty"`
+	// CanaryDuration is how long to watch the ready canary, in seconds,
+	// before it is checked again and promoted.
+	CanaryDuration int64 `protobuf:"varint,21,opt,name=canary_duration,json=canaryDuration" json:"canary_duration,omitempty"`
+	// CanaryCheckURL, if set, is posted the canary once it has been watched,
+	// and must answer 200 OK for it to be promoted.
+	CanaryCheckUrl string `protobuf:"bytes,22,opt,name=canary_check_url,json=canaryCheckUrl" json:"canary_check_url,omitempty"`
+	// BlueGreen, if true, runs the Deployments of the new revision next to
+	// those of the current one, and leaves the Services of the release as
+	// they are until the revision is promoted with PromoteRelease.
+	BlueGreen bool `protobuf:"varint,23,opt,name=blue_green,json=blueGreen" json:"blue_green,omitempty"`
+	// SkipPreflight, if true, does not evaluate the pre-flight checks of the
+	// chart.
+	SkipPreflight bool `protobuf:"varint,24,opt,name=skip_preflight,json=skipPreflight" json:"skip_preflight,omitempty"`
 }
 
 func (m *UpdateReleaseRequest) Reset()                    { *m = UpdateReleaseRequest{} }
@@ -267,6 +343,20 @@ func (m *UpdateReleaseRequest) GetValues() *hapi_chart.Config {
 	return nil
 }
 
+func (m *UpdateReleaseRequest) GetChartSource() *ChartSource {
+	if m != nil {
+		return m.ChartSource
+	}
+	return nil
+}
+
+func (m *UpdateReleaseRequest) GetMetadata() map[string]string {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
 // UpdateReleaseResponse is the response to an update request.
 type UpdateReleaseResponse struct {
 	Release *hapi_release3.Release `protobuf:"bytes,1,opt,name=release" json:"release,omitempty"`
@@ -293,6 +383,15 @@ type RollbackReleaseRequest struct {
 	DisableHooks bool `protobuf:"varint,3,opt,name=disable_hooks,json=disableHooks" json:"disable_hooks,omitempty"`
 	// Version is the version of the release to deploy.
 	Version int32 `protobuf:"varint,4,opt,name=version" json:"version,omitempty"`
+	// Metadata is recorded on the new revision.
+	Metadata map[string]string `protobuf:"bytes,5,rep,name=metadata" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// OverrideLock, if the release is locked, is the reason for rolling it
+	// back anyway. It is recorded in the metadata of the new revision.
+	OverrideLock string `protobuf:"bytes,6,opt,name=override_lock,json=overrideLock" json:"override_lock,omitempty"`
+	// OverrideGates, if the deployment windows or gates of Tiller do not
+	// allow the rollback now, is the reason for rolling back anyway. It is
+	// recorded in the metadata of the new revision.
+	OverrideGates string `protobuf:"bytes,7,opt,name=override_gates,json=overrideGates" json:"override_gates,omitempty"`
 }
 
 func (m *RollbackReleaseRequest) Reset()                    { *m = RollbackReleaseRequest{} }
@@ -300,6 +399,13 @@ func (m *RollbackReleaseRequest) String() string            { return proto.Compa
 func (*RollbackReleaseRequest) ProtoMessage()               {}
 func (*RollbackReleaseRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
 
+func (m *RollbackReleaseRequest) GetMetadata() map[string]string {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
 // RollbackReleaseResponse is the response to an update request.
 type RollbackReleaseResponse struct {
 	Release *hapi_release3.Release `protobuf:"bytes,1,opt,name=release" json:"release,omitempty"`
@@ -337,6 +443,40 @@ type InstallReleaseRequest struct {
 	Namespace string `protobuf:"bytes,6,opt,name=namespace" json:"namespace,omitempty"`
 	// ReuseName requests that Tiller re-uses a name, instead of erroring out.
 	ReuseName bool `protobuf:"varint,7,opt,name=reuse_name,json=reuseName" json:"reuse_name,omitempty"`
+	// ServerSide, if true, creates resources using server-side apply.
+	ServerSide bool `protobuf:"varint,8,opt,name=server_side,json=serverSide" json:"server_side,omitempty"`
+	// IncludeTemplates limits the install to templates matching one of these
+	// path globs or Kind/name selectors.
+	IncludeTemplates []string `protobuf:"bytes,9,rep,name=include_templates,json=includeTemplates" json:"include_templates,omitempty"`
+	// ExcludeTemplates skips templates matching one of these path globs or
+	// Kind/name selectors.
+	ExcludeTemplates []string `protobuf:"bytes,10,rep,name=exclude_templates,json=excludeTemplates" json:"exclude_templates,omitempty"`
+	// DisableVersionCheck skips the chart's kubeVersion and tillerVersion checks.
+	DisableVersionCheck bool `protobuf:"varint,11,opt,name=disable_version_check,json=disableVersionCheck" json:"disable_version_check,omitempty"`
+	// SubchartNotes, if true, appends the notes of subcharts to those of the chart.
+	SubchartNotes bool `protobuf:"varint,12,opt,name=subchart_notes,json=subchartNotes" json:"subchart_notes,omitempty"`
+	// ChartSource describes where the chart came from.
+	ChartSource *ChartSource `protobuf:"bytes,13,opt,name=chart_source,json=chartSource" json:"chart_source,omitempty"`
+	// Wait, if true, waits until the resources of the release are ready
+	// before marking the release as successful.
+	Wait bool `protobuf:"varint,14,opt,name=wait" json:"wait,omitempty"`
+	// WaitForJobs, if true, also waits for the Jobs of the release to complete.
+	WaitForJobs bool `protobuf:"varint,15,opt,name=wait_for_jobs,json=waitForJobs" json:"wait_for_jobs,omitempty"`
+	// Timeout is how long to wait, in seconds. Zero means five minutes.
+	Timeout int64 `protobuf:"varint,16,opt,name=timeout" json:"timeout,omitempty"`
+	// Metadata is recorded on the release.
+	Metadata map[string]string `protobuf:"bytes,17,rep,name=metadata" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// ServerDryRun, if true along with dry_run, also sends the resources of
+	// the release to the Kubernetes API as a dry run, so that its validation
+	// and admission webhooks check them without anything being persisted.
+	ServerDryRun bool `protobuf:"varint,18,opt,name=server_dry_run,json=serverDryRun" json:"server_dry_run,omitempty"`
+	// OverrideGates, if the deployment windows or gates of Tiller do not
+	// allow the install now, is the reason for installing anyway. It is
+	// recorded in the metadata of the release.
+	OverrideGates string `protobuf:"bytes,19,opt,name=override_gates,json=overrideGates" json:"override_gates,omitempty"`
+	// SkipPreflight, if true, does not evaluate the pre-flight checks of the
+	// chart.
+	SkipPreflight bool `protobuf:"varint,20,opt,name=skip_preflight,json=skipPreflight" json:"skip_preflight,omitempty"`
 }
 
 func (m *InstallReleaseRequest) Reset()                    { *m = InstallReleaseRequest{} }
@@ -358,6 +498,38 @@ func (m *InstallReleaseRequest) GetValues() *hapi_chart.Config {
 	return nil
 }
 
+func (m *InstallReleaseRequest) GetChartSource() *ChartSource {
+	if m != nil {
+		return m.ChartSource
+	}
+	return nil
+}
+
+func (m *InstallReleaseRequest) GetMetadata() map[string]string {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+// ChartSource describes where a chart came from, so that Tiller can enforce
+// rules on the charts it installs.
+type ChartSource struct {
+	// URL is the URL the chart archive was downloaded from, if any.
+	Url string `protobuf:"bytes,1,opt,name=url" json:"url,omitempty"`
+	// ArchiveName is the file name of the chart archive.
+	ArchiveName string `protobuf:"bytes,2,opt,name=archive_name,json=archiveName" json:"archive_name,omitempty"`
+	// Archive is the chart archive the chart was loaded from.
+	Archive []byte `protobuf:"bytes,3,opt,name=archive,proto3" json:"archive,omitempty"`
+	// Provenance is the content of the provenance file of the archive, if any.
+	Provenance []byte `protobuf:"bytes,4,opt,name=provenance,proto3" json:"provenance,omitempty"`
+}
+
+func (m *ChartSource) Reset()                    { *m = ChartSource{} }
+func (m *ChartSource) String() string            { return proto.CompactTextString(m) }
+func (*ChartSource) ProtoMessage()               {}
+func (*ChartSource) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
 // InstallReleaseResponse is the response from a release installation.
 type InstallReleaseResponse struct {
 	Release *hapi_release3.Release `protobuf:"bytes,1,opt,name=release" json:"release,omitempty"`
@@ -366,7 +538,7 @@ type InstallReleaseResponse struct {
 func (m *InstallReleaseResponse) Reset()                    { *m = InstallReleaseResponse{} }
 func (m *InstallReleaseResponse) String() string            { return proto.CompactTextString(m) }
 func (*InstallReleaseResponse) ProtoMessage()               {}
-func (*InstallReleaseResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+func (*InstallReleaseResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
 
 func (m *InstallReleaseResponse) GetRelease() *hapi_release3.Release {
 	if m != nil {
@@ -383,23 +555,46 @@ type UninstallReleaseRequest struct {
 	DisableHooks bool `protobuf:"varint,2,opt,name=disable_hooks,json=disableHooks" json:"disable_hooks,omitempty"`
 	// Purge removes the release from the store and make its name free for later use.
 	Purge bool `protobuf:"varint,3,opt,name=purge" json:"purge,omitempty"`
+	// OverrideLock, if the release is locked, is the reason for deleting it
+	// anyway. It is recorded in the metadata of the deleted revision.
+	OverrideLock string `protobuf:"bytes,4,opt,name=override_lock,json=overrideLock" json:"override_lock,omitempty"`
+	// OverrideGates, if the deployment windows or gates of Tiller do not
+	// allow the delete now, is the reason for deleting anyway. It is
+	// recorded in the metadata of the deleted revision.
+	OverrideGates string `protobuf:"bytes,5,opt,name=override_gates,json=overrideGates" json:"override_gates,omitempty"`
+	// Cascade is the propagation policy of the deletion: "background",
+	// "foreground" or "orphan". If empty, resources are deleted the way
+	// kubectl deletes them.
+	Cascade string `protobuf:"bytes,6,opt,name=cascade" json:"cascade,omitempty"`
+	// Wait, if true, deletes the resources kind by kind in the reverse of the
+	// order they are installed in, and waits for each kind to be gone before
+	// deleting the next.
+	Wait bool `protobuf:"varint,7,opt,name=wait" json:"wait,omitempty"`
+	// Timeout is how long, in seconds, to wait for the resources to be gone.
+	Timeout int64 `protobuf:"varint,8,opt,name=timeout" json:"timeout,omitempty"`
+	// IncludeKept, if true, also deletes the resources annotated with
+	// "helm.sh/resource-policy: keep".
+	IncludeKept bool `protobuf:"varint,9,opt,name=include_kept,json=includeKept" json:"include_kept,omitempty"`
 }
 
 func (m *UninstallReleaseRequest) Reset()                    { *m = UninstallReleaseRequest{} }
 func (m *UninstallReleaseRequest) String() string            { return proto.CompactTextString(m) }
 func (*UninstallReleaseRequest) ProtoMessage()               {}
-func (*UninstallReleaseRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+func (*UninstallReleaseRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
 
 // UninstallReleaseResponse represents a successful response to an uninstall request.
 type UninstallReleaseResponse struct {
 	// Release is the release that was marked deleted.
 	Release *hapi_release3.Release `protobuf:"bytes,1,opt,name=release" json:"release,omitempty"`
+	// Kept lists the resources that were not deleted because of their
+	// resource policy, as "Kind name".
+	Kept []string `protobuf:"bytes,2,rep,name=kept" json:"kept,omitempty"`
 }
 
 func (m *UninstallReleaseResponse) Reset()                    { *m = UninstallReleaseResponse{} }
 func (m *UninstallReleaseResponse) String() string            { return proto.CompactTextString(m) }
 func (*UninstallReleaseResponse) ProtoMessage()               {}
-func (*UninstallReleaseResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+func (*UninstallReleaseResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
 
 func (m *UninstallReleaseResponse) GetRelease() *hapi_release3.Release {
 	if m != nil {
@@ -415,7 +610,7 @@ type GetVersionRequest struct {
 func (m *GetVersionRequest) Reset()                    { *m = GetVersionRequest{} }
 func (m *GetVersionRequest) String() string            { return proto.CompactTextString(m) }
 func (*GetVersionRequest) ProtoMessage()               {}
-func (*GetVersionRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+func (*GetVersionRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
 
 type GetVersionResponse struct {
 	Version *hapi_version.Version `protobuf:"bytes,1,opt,name=Version" json:"Version,omitempty"`
@@ -424,7 +619,7 @@ type GetVersionResponse struct {
 func (m *GetVersionResponse) Reset()                    { *m = GetVersionResponse{} }
 func (m *GetVersionResponse) String() string            { return proto.CompactTextString(m) }
 func (*GetVersionResponse) ProtoMessage()               {}
-func (*GetVersionResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+func (*GetVersionResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
 
 func (m *GetVersionResponse) GetVersion() *hapi_version.Version {
 	if m != nil {
@@ -444,7 +639,7 @@ type GetHistoryRequest struct {
 func (m *GetHistoryRequest) Reset()                    { *m = GetHistoryRequest{} }
 func (m *GetHistoryRequest) String() string            { return proto.CompactTextString(m) }
 func (*GetHistoryRequest) ProtoMessage()               {}
-func (*GetHistoryRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+func (*GetHistoryRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
 
 // GetHistoryResponse is received in response to a GetHistory rpc.
 type GetHistoryResponse struct {
@@ -454,7 +649,7 @@ type GetHistoryResponse struct {
 func (m *GetHistoryResponse) Reset()                    { *m = GetHistoryResponse{} }
 func (m *GetHistoryResponse) String() string            { return proto.CompactTextString(m) }
 func (*GetHistoryResponse) ProtoMessage()               {}
-func (*GetHistoryResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
+func (*GetHistoryResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
 
 func (m *GetHistoryResponse) GetReleases() []*hapi_release3.Release {
 	if m != nil {
@@ -463,6 +658,383 @@ func (m *GetHistoryResponse) GetReleases() []*hapi_release3.Release {
 	return nil
 }
 
+// ImportReleaseRequest requests that a release be imported.
+type ImportReleaseRequest struct {
+	// Releases are the revisions of the release, in any order.
+	Releases []*hapi_release3.Release `protobuf:"bytes,1,rep,name=releases" json:"releases,omitempty"`
+	// Namespace, if set, replaces the namespace of the release.
+	Namespace string `protobuf:"bytes,2,opt,name=namespace" json:"namespace,omitempty"`
+	// Apply creates the resources of the latest revision, if it is deployed.
+	Apply bool `protobuf:"varint,3,opt,name=apply" json:"apply,omitempty"`
+	// OverrideGates, if the deployment windows or gates of Tiller do not
+	// allow applying the release now, is the reason for applying it anyway.
+	// It is recorded in the metadata of the latest revision.
+	OverrideGates string `protobuf:"bytes,4,opt,name=override_gates,json=overrideGates" json:"override_gates,omitempty"`
+}
+
+func (m *ImportReleaseRequest) Reset()                    { *m = ImportReleaseRequest{} }
+func (m *ImportReleaseRequest) String() string            { return proto.CompactTextString(m) }
+func (*ImportReleaseRequest) ProtoMessage()               {}
+func (*ImportReleaseRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+
+func (m *ImportReleaseRequest) GetReleases() []*hapi_release3.Release {
+	if m != nil {
+		return m.Releases
+	}
+	return nil
+}
+
+// ImportReleaseResponse is received in response to an ImportRelease rpc.
+type ImportReleaseResponse struct {
+	// Release is the latest revision of the imported release.
+	Release *hapi_release3.Release `protobuf:"bytes,1,opt,name=release" json:"release,omitempty"`
+}
+
+func (m *ImportReleaseResponse) Reset()                    { *m = ImportReleaseResponse{} }
+func (m *ImportReleaseResponse) String() string            { return proto.CompactTextString(m) }
+func (*ImportReleaseResponse) ProtoMessage()               {}
+func (*ImportReleaseResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
+
+func (m *ImportReleaseResponse) GetRelease() *hapi_release3.Release {
+	if m != nil {
+		return m.Release
+	}
+	return nil
+}
+
+// ResourceEvent describes a change that Tiller made to a single resource.
+type ResourceEvent struct {
+	// Action is what happened to the resource: "created", "patched",
+	// "unchanged", "applied", "deleted" or "ready".
+	Action string `protobuf:"bytes,1,opt,name=action" json:"action,omitempty"`
+	// Kind is the kind of the resource, e.g. "Deployment".
+	Kind string `protobuf:"bytes,2,opt,name=kind" json:"kind,omitempty"`
+	// Name is the name of the resource.
+	Name string `protobuf:"bytes,3,opt,name=name" json:"name,omitempty"`
+	// Namespace is the namespace of the resource.
+	Namespace string `protobuf:"bytes,4,opt,name=namespace" json:"namespace,omitempty"`
+}
+
+func (m *ResourceEvent) Reset()                    { *m = ResourceEvent{} }
+func (m *ResourceEvent) String() string            { return proto.CompactTextString(m) }
+func (*ResourceEvent) ProtoMessage()               {}
+func (*ResourceEvent) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
+
+// ReleaseProgressResponse is streamed by the progress variants of the
+// install and update calls.
+type ReleaseProgressResponse struct {
+	// Event is set on progress messages.
+	Event *ResourceEvent `protobuf:"bytes,1,opt,name=event" json:"event,omitempty"`
+	// Release is set on the final message.
+	Release *hapi_release3.Release `protobuf:"bytes,2,opt,name=release" json:"release,omitempty"`
+}
+
+func (m *ReleaseProgressResponse) Reset()                    { *m = ReleaseProgressResponse{} }
+func (m *ReleaseProgressResponse) String() string            { return proto.CompactTextString(m) }
+func (*ReleaseProgressResponse) ProtoMessage()               {}
+func (*ReleaseProgressResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
+
+func (m *ReleaseProgressResponse) GetEvent() *ResourceEvent {
+	if m != nil {
+		return m.Event
+	}
+	return nil
+}
+
+func (m *ReleaseProgressResponse) GetRelease() *hapi_release3.Release {
+	if m != nil {
+		return m.Release
+	}
+	return nil
+}
+
+// GetReleaseDriftRequest requests the drift of a release from the cluster.
+type GetReleaseDriftRequest struct {
+	// The name of the release.
+	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	// Version of the release, or 0 for the latest one.
+	Version int32 `protobuf:"varint,2,opt,name=version" json:"version,omitempty"`
+	// IgnoreFields are the paths of fields that never count as drift, each
+	// optionally prefixed with "KIND:" or "KIND/NAME:".
+	IgnoreFields []string `protobuf:"bytes,3,rep,name=ignore_fields,json=ignoreFields" json:"ignore_fields,omitempty"`
+}
+
+func (m *GetReleaseDriftRequest) Reset()                    { *m = GetReleaseDriftRequest{} }
+func (m *GetReleaseDriftRequest) String() string            { return proto.CompactTextString(m) }
+func (*GetReleaseDriftRequest) ProtoMessage()               {}
+func (*GetReleaseDriftRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
+
+// GetReleaseDriftResponse is received in response to a GetReleaseDrift rpc.
+type GetReleaseDriftResponse struct {
+	// Resources has an entry for each resource of the release, in the order
+	// of the release manifest.
+	Resources []*ResourceDrift `protobuf:"bytes,1,rep,name=resources" json:"resources,omitempty"`
+}
+
+func (m *GetReleaseDriftResponse) Reset()                    { *m = GetReleaseDriftResponse{} }
+func (m *GetReleaseDriftResponse) String() string            { return proto.CompactTextString(m) }
+func (*GetReleaseDriftResponse) ProtoMessage()               {}
+func (*GetReleaseDriftResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
+
+func (m *GetReleaseDriftResponse) GetResources() []*ResourceDrift {
+	if m != nil {
+		return m.Resources
+	}
+	return nil
+}
+
+// ResourceDrift describes how a live object differs from the resource
+// stored with a release.
+type ResourceDrift struct {
+	ApiVersion string `protobuf:"bytes,1,opt,name=api_version,json=apiVersion" json:"api_version,omitempty"`
+	Kind       string `protobuf:"bytes,2,opt,name=kind" json:"kind,omitempty"`
+	Name       string `protobuf:"bytes,3,opt,name=name" json:"name,omitempty"`
+	Namespace  string `protobuf:"bytes,4,opt,name=namespace" json:"namespace,omitempty"`
+	// Status is "in sync", "changed" or "missing".
+	Status string `protobuf:"bytes,5,opt,name=status" json:"status,omitempty"`
+	// Fields are the fields that differ, if the status is "changed".
+	Fields []*FieldDrift `protobuf:"bytes,6,rep,name=fields" json:"fields,omitempty"`
+}
+
+func (m *ResourceDrift) Reset()                    { *m = ResourceDrift{} }
+func (m *ResourceDrift) String() string            { return proto.CompactTextString(m) }
+func (*ResourceDrift) ProtoMessage()               {}
+func (*ResourceDrift) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{26} }
+
+func (m *ResourceDrift) GetFields() []*FieldDrift {
+	if m != nil {
+		return m.Fields
+	}
+	return nil
+}
+
+// FieldDrift describes a field that differs between a stored resource and
+// its live object.
+type FieldDrift struct {
+	// Path is the path of the field, e.g. "spec.template.spec.containers[0].image".
+	Path string `protobuf:"bytes,1,opt,name=path" json:"path,omitempty"`
+	// Change is "added" for fields only set on the live object, "removed"
+	// for fields only set in the release, and "changed" otherwise.
+	Change string `protobuf:"bytes,2,opt,name=change" json:"change,omitempty"`
+	// Stored is the value in the release, as JSON, if set.
+	Stored string `protobuf:"bytes,3,opt,name=stored" json:"stored,omitempty"`
+	// Live is the value of the live object, as JSON, if set.
+	Live string `protobuf:"bytes,4,opt,name=live" json:"live,omitempty"`
+}
+
+func (m *FieldDrift) Reset()                    { *m = FieldDrift{} }
+func (m *FieldDrift) String() string            { return proto.CompactTextString(m) }
+func (*FieldDrift) ProtoMessage()               {}
+func (*FieldDrift) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{27} }
+
+// SyncReleaseRequest requests that a release's live objects be reverted to
+// the latest revision of the release.
+type SyncReleaseRequest struct {
+	// The name of the release.
+	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	// IgnoreFields are the paths of fields to leave as they are, in the same
+	// form as for GetReleaseDriftRequest.
+	IgnoreFields []string `protobuf:"bytes,2,rep,name=ignore_fields,json=ignoreFields" json:"ignore_fields,omitempty"`
+	// OverrideGates, if the deployment windows or gates of Tiller do not
+	// allow the sync now, is the reason for syncing anyway. As a sync records
+	// no revision, the reason is only logged.
+	OverrideGates string `protobuf:"bytes,3,opt,name=override_gates,json=overrideGates" json:"override_gates,omitempty"`
+}
+
+func (m *SyncReleaseRequest) Reset()                    { *m = SyncReleaseRequest{} }
+func (m *SyncReleaseRequest) String() string            { return proto.CompactTextString(m) }
+func (*SyncReleaseRequest) ProtoMessage()               {}
+func (*SyncReleaseRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{28} }
+
+// SyncReleaseResponse is received in response to a SyncRelease rpc.
+type SyncReleaseResponse struct {
+	// Resources are the resources that had drifted, with the changes that
+	// were reverted.
+	Resources []*ResourceDrift `protobuf:"bytes,1,rep,name=resources" json:"resources,omitempty"`
+}
+
+func (m *SyncReleaseResponse) Reset()                    { *m = SyncReleaseResponse{} }
+func (m *SyncReleaseResponse) String() string            { return proto.CompactTextString(m) }
+func (*SyncReleaseResponse) ProtoMessage()               {}
+func (*SyncReleaseResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{29} }
+
+func (m *SyncReleaseResponse) GetResources() []*ResourceDrift {
+	if m != nil {
+		return m.Resources
+	}
+	return nil
+}
+
+// SetReleaseValuesRequest requests that values be stored on a release, to be
+// merged over the values of its upgrades.
+type SetReleaseValuesRequest struct {
+	// The name of the release.
+	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	// Values are merged over the overrides the release already has.
+	Values *hapi_chart.Config `protobuf:"bytes,2,opt,name=values" json:"values,omitempty"`
+	// Replace, if true, replaces the overrides of the release with values.
+	Replace bool `protobuf:"varint,3,opt,name=replace" json:"replace,omitempty"`
+	// Apply, if true, upgrades the release to a new revision of the same
+	// chart with the new overrides.
+	Apply bool `protobuf:"varint,4,opt,name=apply" json:"apply,omitempty"`
+}
+
+func (m *SetReleaseValuesRequest) Reset()                    { *m = SetReleaseValuesRequest{} }
+func (m *SetReleaseValuesRequest) String() string            { return proto.CompactTextString(m) }
+func (*SetReleaseValuesRequest) ProtoMessage()               {}
+func (*SetReleaseValuesRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{30} }
+
+func (m *SetReleaseValuesRequest) GetValues() *hapi_chart.Config {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+// SetReleaseValuesResponse is received in response to a SetReleaseValues rpc.
+type SetReleaseValuesResponse struct {
+	// Release is the latest revision of the release, which is the new
+	// revision if the overrides were applied.
+	Release *hapi_release3.Release `protobuf:"bytes,1,opt,name=release" json:"release,omitempty"`
+}
+
+func (m *SetReleaseValuesResponse) Reset()                    { *m = SetReleaseValuesResponse{} }
+func (m *SetReleaseValuesResponse) String() string            { return proto.CompactTextString(m) }
+func (*SetReleaseValuesResponse) ProtoMessage()               {}
+func (*SetReleaseValuesResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{31} }
+
+func (m *SetReleaseValuesResponse) GetRelease() *hapi_release3.Release {
+	if m != nil {
+		return m.Release
+	}
+	return nil
+}
+
+// MaintainStorageRequest requests the maintenance of the release storage.
+type MaintainStorageRequest struct {
+	// MaxHistory is the most revisions of a release to keep. The oldest
+	// revisions beyond it are removed, except the deployed revision and
+	// those after it. Zero keeps every revision.
+	MaxHistory int32 `protobuf:"varint,1,opt,name=max_history,json=maxHistory" json:"max_history,omitempty"`
+	// DryRun, if true, reports what would be removed without removing it.
+	DryRun bool `protobuf:"varint,2,opt,name=dry_run,json=dryRun" json:"dry_run,omitempty"`
+}
+
+func (m *MaintainStorageRequest) Reset()                    { *m = MaintainStorageRequest{} }
+func (m *MaintainStorageRequest) String() string            { return proto.CompactTextString(m) }
+func (*MaintainStorageRequest) ProtoMessage()               {}
+func (*MaintainStorageRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{32} }
+
+// StorageUsage describes the records stored for one release.
+type StorageUsage struct {
+	// The name of the release.
+	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	// Revisions is the number of revisions stored, before any were removed.
+	Revisions int32 `protobuf:"varint,2,opt,name=revisions" json:"revisions,omitempty"`
+	// Bytes is the size of the stored revisions, before any were removed.
+	Bytes int64 `protobuf:"varint,3,opt,name=bytes" json:"bytes,omitempty"`
+	// Removed is the number of revisions that were removed.
+	Removed int32 `protobuf:"varint,4,opt,name=removed" json:"removed,omitempty"`
+	// Orphaned is true if the release was purged, and its remaining
+	// records were removed.
+	Orphaned bool `protobuf:"varint,5,opt,name=orphaned" json:"orphaned,omitempty"`
+}
+
+func (m *StorageUsage) Reset()                    { *m = StorageUsage{} }
+func (m *StorageUsage) String() string            { return proto.CompactTextString(m) }
+func (*StorageUsage) ProtoMessage()               {}
+func (*StorageUsage) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{33} }
+
+// MaintainStorageResponse is received in response to a MaintainStorage rpc.
+type MaintainStorageResponse struct {
+	// Releases are the releases in the storage, sorted by name.
+	Releases []*StorageUsage `protobuf:"bytes,1,rep,name=releases" json:"releases,omitempty"`
+	// Problems describe the records that failed the integrity checks.
+	Problems []string `protobuf:"bytes,2,rep,name=problems" json:"problems,omitempty"`
+}
+
+func (m *MaintainStorageResponse) Reset()                    { *m = MaintainStorageResponse{} }
+func (m *MaintainStorageResponse) String() string            { return proto.CompactTextString(m) }
+func (*MaintainStorageResponse) ProtoMessage()               {}
+func (*MaintainStorageResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{34} }
+
+func (m *MaintainStorageResponse) GetReleases() []*StorageUsage {
+	if m != nil {
+		return m.Releases
+	}
+	return nil
+}
+
+// SetReleaseLockRequest requests that a release be locked or unlocked.
+type SetReleaseLockRequest struct {
+	// The name of the release.
+	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	// Reason is why the release is locked. It is required to lock a release.
+	Reason string `protobuf:"bytes,2,opt,name=reason" json:"reason,omitempty"`
+	// Unlock, if true, removes the lock of the release.
+	Unlock bool `protobuf:"varint,3,opt,name=unlock" json:"unlock,omitempty"`
+}
+
+func (m *SetReleaseLockRequest) Reset()                    { *m = SetReleaseLockRequest{} }
+func (m *SetReleaseLockRequest) String() string            { return proto.CompactTextString(m) }
+func (*SetReleaseLockRequest) ProtoMessage()               {}
+func (*SetReleaseLockRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{35} }
+
+// SetReleaseLockResponse is received in response to a SetReleaseLock rpc.
+type SetReleaseLockResponse struct {
+	// Release is the latest revision of the release.
+	Release *hapi_release3.Release `protobuf:"bytes,1,opt,name=release" json:"release,omitempty"`
+}
+
+func (m *SetReleaseLockResponse) Reset()                    { *m = SetReleaseLockResponse{} }
+func (m *SetReleaseLockResponse) String() string            { return proto.CompactTextString(m) }
+func (*SetReleaseLockResponse) ProtoMessage()               {}
+func (*SetReleaseLockResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{36} }
+
+func (m *SetReleaseLockResponse) GetRelease() *hapi_release3.Release {
+	if m != nil {
+		return m.Release
+	}
+	return nil
+}
+
+// PromoteReleaseRequest requests that the staged blue/green revision of a
+// release be promoted.
+type PromoteReleaseRequest struct {
+	// The name of the release.
+	Name string `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	// GracePeriod is how long to keep the Deployments of the previous
+	// revision after the Services are switched, in seconds.
+	GracePeriod int64 `protobuf:"varint,2,opt,name=grace_period,json=gracePeriod" json:"grace_period,omitempty"`
+	// Timeout is how long to wait for the Deployments of the revision to be
+	// ready before the Services are switched, in seconds. Zero means five
+	// minutes.
+	Timeout int64 `protobuf:"varint,3,opt,name=timeout" json:"timeout,omitempty"`
+}
+
+func (m *PromoteReleaseRequest) Reset()                    { *m = PromoteReleaseRequest{} }
+func (m *PromoteReleaseRequest) String() string            { return proto.CompactTextString(m) }
+func (*PromoteReleaseRequest) ProtoMessage()               {}
+func (*PromoteReleaseRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{37} }
+
+// PromoteReleaseResponse is received in response to a PromoteRelease rpc.
+type PromoteReleaseResponse struct {
+	// Release is the promoted revision.
+	Release *hapi_release3.Release `protobuf:"bytes,1,opt,name=release" json:"release,omitempty"`
+}
+
+func (m *PromoteReleaseResponse) Reset()                    { *m = PromoteReleaseResponse{} }
+func (m *PromoteReleaseResponse) String() string            { return proto.CompactTextString(m) }
+func (*PromoteReleaseResponse) ProtoMessage()               {}
+func (*PromoteReleaseResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{38} }
+
+func (m *PromoteReleaseResponse) GetRelease() *hapi_release3.Release {
+	if m != nil {
+		return m.Release
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*ListReleasesRequest)(nil), "hapi.services.tiller.ListReleasesRequest")
 	proto.RegisterType((*ListSort)(nil), "hapi.services.tiller.ListSort")
@@ -476,6 +1048,7 @@ func init() {
 	proto.RegisterType((*RollbackReleaseRequest)(nil), "hapi.services.tiller.RollbackReleaseRequest")
 	proto.RegisterType((*RollbackReleaseResponse)(nil), "hapi.services.tiller.RollbackReleaseResponse")
 	proto.RegisterType((*InstallReleaseRequest)(nil), "hapi.services.tiller.InstallReleaseRequest")
+	proto.RegisterType((*ChartSource)(nil), "hapi.services.tiller.ChartSource")
 	proto.RegisterType((*InstallReleaseResponse)(nil), "hapi.services.tiller.InstallReleaseResponse")
 	proto.RegisterType((*UninstallReleaseRequest)(nil), "hapi.services.tiller.UninstallReleaseRequest")
 	proto.RegisterType((*UninstallReleaseResponse)(nil), "hapi.services.tiller.UninstallReleaseResponse")
@@ -483,6 +1056,25 @@ func init() {
 	proto.RegisterType((*GetVersionResponse)(nil), "hapi.services.tiller.GetVersionResponse")
 	proto.RegisterType((*GetHistoryRequest)(nil), "hapi.services.tiller.GetHistoryRequest")
 	proto.RegisterType((*GetHistoryResponse)(nil), "hapi.services.tiller.GetHistoryResponse")
+	proto.RegisterType((*ImportReleaseRequest)(nil), "hapi.services.tiller.ImportReleaseRequest")
+	proto.RegisterType((*ImportReleaseResponse)(nil), "hapi.services.tiller.ImportReleaseResponse")
+	proto.RegisterType((*ResourceEvent)(nil), "hapi.services.tiller.ResourceEvent")
+	proto.RegisterType((*ReleaseProgressResponse)(nil), "hapi.services.tiller.ReleaseProgressResponse")
+	proto.RegisterType((*GetReleaseDriftRequest)(nil), "hapi.services.tiller.GetReleaseDriftRequest")
+	proto.RegisterType((*GetReleaseDriftResponse)(nil), "hapi.services.tiller.GetReleaseDriftResponse")
+	proto.RegisterType((*ResourceDrift)(nil), "hapi.services.tiller.ResourceDrift")
+	proto.RegisterType((*FieldDrift)(nil), "hapi.services.tiller.FieldDrift")
+	proto.RegisterType((*SyncReleaseRequest)(nil), "hapi.services.tiller.SyncReleaseRequest")
+	proto.RegisterType((*SyncReleaseResponse)(nil), "hapi.services.tiller.SyncReleaseResponse")
+	proto.RegisterType((*SetReleaseValuesRequest)(nil), "hapi.services.tiller.SetReleaseValuesRequest")
+	proto.RegisterType((*SetReleaseValuesResponse)(nil), "hapi.services.tiller.SetReleaseValuesResponse")
+	proto.RegisterType((*MaintainStorageRequest)(nil), "hapi.services.tiller.MaintainStorageRequest")
+	proto.RegisterType((*StorageUsage)(nil), "hapi.services.tiller.StorageUsage")
+	proto.RegisterType((*MaintainStorageResponse)(nil), "hapi.services.tiller.MaintainStorageResponse")
+	proto.RegisterType((*SetReleaseLockRequest)(nil), "hapi.services.tiller.SetReleaseLockRequest")
+	proto.RegisterType((*SetReleaseLockResponse)(nil), "hapi.services.tiller.SetReleaseLockResponse")
+	proto.RegisterType((*PromoteReleaseRequest)(nil), "hapi.services.tiller.PromoteReleaseRequest")
+	proto.RegisterType((*PromoteReleaseResponse)(nil), "hapi.services.tiller.PromoteReleaseResponse")
 	proto.RegisterEnum("hapi.services.tiller.ListSort_SortBy", ListSort_SortBy_name, ListSort_SortBy_value)
 	proto.RegisterEnum("hapi.services.tiller.ListSort_SortOrder", ListSort_SortOrder_name, ListSort_SortOrder_value)
 }
@@ -519,6 +1111,34 @@ type ReleaseServiceClient interface {
 	RollbackRelease(ctx context.Context, in *RollbackReleaseRequest, opts ...grpc.CallOption) (*RollbackReleaseResponse, error)
 	// ReleaseHistory retrieves a releasse's history.
 	GetHistory(ctx context.Context, in *GetHistoryRequest, opts ...grpc.CallOption) (*GetHistoryResponse, error)
+	// InstallReleaseProgress installs a release like InstallRelease, streaming
+	// a ResourceEvent for each resource change as it is made. The last
+	// response carries the release.
+	InstallReleaseProgress(ctx context.Context, in *InstallReleaseRequest, opts ...grpc.CallOption) (ReleaseService_InstallReleaseProgressClient, error)
+	// UpdateReleaseProgress updates a release like UpdateRelease, streaming
+	// a ResourceEvent for each resource change as it is made. The last
+	// response carries the release.
+	UpdateReleaseProgress(ctx context.Context, in *UpdateReleaseRequest, opts ...grpc.CallOption) (ReleaseService_UpdateReleaseProgressClient, error)
+	// ImportRelease stores the revisions of a release exported from another
+	// Tiller, optionally creating its resources.
+	ImportRelease(ctx context.Context, in *ImportReleaseRequest, opts ...grpc.CallOption) (*ImportReleaseResponse, error)
+	// GetReleaseDrift compares the resources of a release with the live
+	// objects in the cluster.
+	GetReleaseDrift(ctx context.Context, in *GetReleaseDriftRequest, opts ...grpc.CallOption) (*GetReleaseDriftResponse, error)
+	// SyncRelease reverts the changes made to the live objects of a release
+	// since it was installed or upgraded.
+	SyncRelease(ctx context.Context, in *SyncReleaseRequest, opts ...grpc.CallOption) (*SyncReleaseResponse, error)
+	// SetReleaseValues stores override values on a release, and optionally
+	// upgrades it with them.
+	SetReleaseValues(ctx context.Context, in *SetReleaseValuesRequest, opts ...grpc.CallOption) (*SetReleaseValuesResponse, error)
+	// MaintainStorage compacts the release history Tiller stores, removes the
+	// records left behind by purges, and reports on the storage.
+	MaintainStorage(ctx context.Context, in *MaintainStorageRequest, opts ...grpc.CallOption) (*MaintainStorageResponse, error)
+	// SetReleaseLock locks or unlocks a release.
+	SetReleaseLock(ctx context.Context, in *SetReleaseLockRequest, opts ...grpc.CallOption) (*SetReleaseLockResponse, error)
+	// PromoteRelease switches the Services of a release over to its
+	// blue/green revision.
+	PromoteRelease(ctx context.Context, in *PromoteReleaseRequest, opts ...grpc.CallOption) (*PromoteReleaseResponse, error)
 }
 
 type releaseServiceClient struct {
@@ -633,6 +1253,133 @@ func (c *releaseServiceClient) GetHistory(ctx context.Context, in *GetHistoryReq
 	return out, nil
 }
 
+func (c *releaseServiceClient) InstallReleaseProgress(ctx context.Context, in *InstallReleaseRequest, opts ...grpc.CallOption) (ReleaseService_InstallReleaseProgressClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_ReleaseService_serviceDesc.Streams[1], c.cc, "/hapi.services.tiller.ReleaseService/InstallReleaseProgress", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &releaseServiceInstallReleaseProgressClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ReleaseService_InstallReleaseProgressClient interface {
+	Recv() (*ReleaseProgressResponse, error)
+	grpc.ClientStream
+}
+
+type releaseServiceInstallReleaseProgressClient struct {
+	grpc.ClientStream
+}
+
+func (x *releaseServiceInstallReleaseProgressClient) Recv() (*ReleaseProgressResponse, error) {
+	m := new(ReleaseProgressResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *releaseServiceClient) UpdateReleaseProgress(ctx context.Context, in *UpdateReleaseRequest, opts ...grpc.CallOption) (ReleaseService_UpdateReleaseProgressClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_ReleaseService_serviceDesc.Streams[2], c.cc, "/hapi.services.tiller.ReleaseService/UpdateReleaseProgress", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &releaseServiceUpdateReleaseProgressClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ReleaseService_UpdateReleaseProgressClient interface {
+	Recv() (*ReleaseProgressResponse, error)
+	grpc.ClientStream
+}
+
+type releaseServiceUpdateReleaseProgressClient struct {
+	grpc.ClientStream
+}
+
+func (x *releaseServiceUpdateReleaseProgressClient) Recv() (*ReleaseProgressResponse, error) {
+	m := new(ReleaseProgressResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *releaseServiceClient) ImportRelease(ctx context.Context, in *ImportReleaseRequest, opts ...grpc.CallOption) (*ImportReleaseResponse, error) {
+	out := new(ImportReleaseResponse)
+	err := grpc.Invoke(ctx, "/hapi.services.tiller.ReleaseService/ImportRelease", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *releaseServiceClient) GetReleaseDrift(ctx context.Context, in *GetReleaseDriftRequest, opts ...grpc.CallOption) (*GetReleaseDriftResponse, error) {
+	out := new(GetReleaseDriftResponse)
+	err := grpc.Invoke(ctx, "/hapi.services.tiller.ReleaseService/GetReleaseDrift", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *releaseServiceClient) SyncRelease(ctx context.Context, in *SyncReleaseRequest, opts ...grpc.CallOption) (*SyncReleaseResponse, error) {
+	out := new(SyncReleaseResponse)
+	err := grpc.Invoke(ctx, "/hapi.services.tiller.ReleaseService/SyncRelease", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *releaseServiceClient) SetReleaseValues(ctx context.Context, in *SetReleaseValuesRequest, opts ...grpc.CallOption) (*SetReleaseValuesResponse, error) {
+	out := new(SetReleaseValuesResponse)
+	err := grpc.Invoke(ctx, "/hapi.services.tiller.ReleaseService/SetReleaseValues", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *releaseServiceClient) MaintainStorage(ctx context.Context, in *MaintainStorageRequest, opts ...grpc.CallOption) (*MaintainStorageResponse, error) {
+	out := new(MaintainStorageResponse)
+	err := grpc.Invoke(ctx, "/hapi.services.tiller.ReleaseService/MaintainStorage", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *releaseServiceClient) SetReleaseLock(ctx context.Context, in *SetReleaseLockRequest, opts ...grpc.CallOption) (*SetReleaseLockResponse, error) {
+	out := new(SetReleaseLockResponse)
+	err := grpc.Invoke(ctx, "/hapi.services.tiller.ReleaseService/SetReleaseLock", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *releaseServiceClient) PromoteRelease(ctx context.Context, in *PromoteReleaseRequest, opts ...grpc.CallOption) (*PromoteReleaseResponse, error) {
+	out := new(PromoteReleaseResponse)
+	err := grpc.Invoke(ctx, "/hapi.services.tiller.ReleaseService/PromoteRelease", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for ReleaseService service
 
 type ReleaseServiceServer interface {
@@ -657,6 +1404,34 @@ type ReleaseServiceServer interface {
 	RollbackRelease(context.Context, *RollbackReleaseRequest) (*RollbackReleaseResponse, error)
 	// ReleaseHistory retrieves a releasse's history.
 	GetHistory(context.Context, *GetHistoryRequest) (*GetHistoryResponse, error)
+	// InstallReleaseProgress installs a release like InstallRelease, streaming
+	// a ResourceEvent for each resource change as it is made. The last
+	// response carries the release.
+	InstallReleaseProgress(*InstallReleaseRequest, ReleaseService_InstallReleaseProgressServer) error
+	// UpdateReleaseProgress updates a release like UpdateRelease, streaming
+	// a ResourceEvent for each resource change as it is made. The last
+	// response carries the release.
+	UpdateReleaseProgress(*UpdateReleaseRequest, ReleaseService_UpdateReleaseProgressServer) error
+	// ImportRelease stores the revisions of a release exported from another
+	// Tiller, optionally creating its resources.
+	ImportRelease(context.Context, *ImportReleaseRequest) (*ImportReleaseResponse, error)
+	// GetReleaseDrift compares the resources of a release with the live
+	// objects in the cluster.
+	GetReleaseDrift(context.Context, *GetReleaseDriftRequest) (*GetReleaseDriftResponse, error)
+	// SyncRelease reverts the changes made to the live objects of a release
+	// since it was installed or upgraded.
+	SyncRelease(context.Context, *SyncReleaseRequest) (*SyncReleaseResponse, error)
+	// SetReleaseValues stores override values on a release, and optionally
+	// upgrades it with them.
+	SetReleaseValues(context.Context, *SetReleaseValuesRequest) (*SetReleaseValuesResponse, error)
+	// MaintainStorage compacts the release history Tiller stores, removes the
+	// records left behind by purges, and reports on the storage.
+	MaintainStorage(context.Context, *MaintainStorageRequest) (*MaintainStorageResponse, error)
+	// SetReleaseLock locks or unlocks a release.
+	SetReleaseLock(context.Context, *SetReleaseLockRequest) (*SetReleaseLockResponse, error)
+	// PromoteRelease switches the Services of a release over to its
+	// blue/green revision.
+	PromoteRelease(context.Context, *PromoteReleaseRequest) (*PromoteReleaseResponse, error)
 }
 
 func RegisterReleaseServiceServer(s *grpc.Server, srv ReleaseServiceServer) {
@@ -828,6 +1603,174 @@ func _ReleaseService_GetHistory_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ReleaseService_InstallReleaseProgress_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(InstallReleaseRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ReleaseServiceServer).InstallReleaseProgress(m, &releaseServiceInstallReleaseProgressServer{stream})
+}
+
+type ReleaseService_InstallReleaseProgressServer interface {
+	Send(*ReleaseProgressResponse) error
+	grpc.ServerStream
+}
+
+type releaseServiceInstallReleaseProgressServer struct {
+	grpc.ServerStream
+}
+
+func (x *releaseServiceInstallReleaseProgressServer) Send(m *ReleaseProgressResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ReleaseService_UpdateReleaseProgress_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(UpdateReleaseRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ReleaseServiceServer).UpdateReleaseProgress(m, &releaseServiceUpdateReleaseProgressServer{stream})
+}
+
+type ReleaseService_UpdateReleaseProgressServer interface {
+	Send(*ReleaseProgressResponse) error
+	grpc.ServerStream
+}
+
+type releaseServiceUpdateReleaseProgressServer struct {
+	grpc.ServerStream
+}
+
+func (x *releaseServiceUpdateReleaseProgressServer) Send(m *ReleaseProgressResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _ReleaseService_ImportRelease_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportReleaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReleaseServiceServer).ImportRelease(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/hapi.services.tiller.ReleaseService/ImportRelease",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReleaseServiceServer).ImportRelease(ctx, req.(*ImportReleaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReleaseService_GetReleaseDrift_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetReleaseDriftRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReleaseServiceServer).GetReleaseDrift(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/hapi.services.tiller.ReleaseService/GetReleaseDrift",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReleaseServiceServer).GetReleaseDrift(ctx, req.(*GetReleaseDriftRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReleaseService_SyncRelease_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SyncReleaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReleaseServiceServer).SyncRelease(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/hapi.services.tiller.ReleaseService/SyncRelease",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReleaseServiceServer).SyncRelease(ctx, req.(*SyncReleaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReleaseService_SetReleaseValues_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetReleaseValuesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReleaseServiceServer).SetReleaseValues(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/hapi.services.tiller.ReleaseService/SetReleaseValues",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReleaseServiceServer).SetReleaseValues(ctx, req.(*SetReleaseValuesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReleaseService_MaintainStorage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MaintainStorageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReleaseServiceServer).MaintainStorage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/hapi.services.tiller.ReleaseService/MaintainStorage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReleaseServiceServer).MaintainStorage(ctx, req.(*MaintainStorageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReleaseService_SetReleaseLock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetReleaseLockRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReleaseServiceServer).SetReleaseLock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/hapi.services.tiller.ReleaseService/SetReleaseLock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReleaseServiceServer).SetReleaseLock(ctx, req.(*SetReleaseLockRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReleaseService_PromoteRelease_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PromoteReleaseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReleaseServiceServer).PromoteRelease(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/hapi.services.tiller.ReleaseService/PromoteRelease",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReleaseServiceServer).PromoteRelease(ctx, req.(*PromoteReleaseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _ReleaseService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "hapi.services.tiller.ReleaseService",
 	HandlerType: (*ReleaseServiceServer)(nil),
@@ -864,6 +1807,34 @@ var _ReleaseService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetHistory",
 			Handler:    _ReleaseService_GetHistory_Handler,
 		},
+		{
+			MethodName: "ImportRelease",
+			Handler:    _ReleaseService_ImportRelease_Handler,
+		},
+		{
+			MethodName: "GetReleaseDrift",
+			Handler:    _ReleaseService_GetReleaseDrift_Handler,
+		},
+		{
+			MethodName: "SyncRelease",
+			Handler:    _ReleaseService_SyncRelease_Handler,
+		},
+		{
+			MethodName: "SetReleaseValues",
+			Handler:    _ReleaseService_SetReleaseValues_Handler,
+		},
+		{
+			MethodName: "MaintainStorage",
+			Handler:    _ReleaseService_MaintainStorage_Handler,
+		},
+		{
+			MethodName: "SetReleaseLock",
+			Handler:    _ReleaseService_SetReleaseLock_Handler,
+		},
+		{
+			MethodName: "PromoteRelease",
+			Handler:    _ReleaseService_PromoteRelease_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -871,6 +1842,16 @@ var _ReleaseService_serviceDesc = grpc.ServiceDesc{
 			Handler:       _ReleaseService_ListReleases_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "InstallReleaseProgress",
+			Handler:       _ReleaseService_InstallReleaseProgress_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "UpdateReleaseProgress",
+			Handler:       _ReleaseService_UpdateReleaseProgress_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: fileDescriptor0,
 }
@@ -878,68 +1859,147 @@ var _ReleaseService_serviceDesc = grpc.ServiceDesc{
 func init() { proto.RegisterFile("hapi/services/tiller.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 1004 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x09, 0x6e, 0x88, 0x02, 0xff, 0x9c, 0x57, 0xdf, 0x6f, 0xe3, 0xc4,
-	0x13, 0xaf, 0x93, 0x34, 0x3f, 0xa6, 0x3f, 0xbe, 0xe9, 0x5e, 0xda, 0xb8, 0xd6, 0x17, 0x14, 0x19,
-	0xc1, 0x85, 0x83, 0x4b, 0x21, 0x3c, 0x21, 0x21, 0xa4, 0x5e, 0x2e, 0x4a, 0xcb, 0x95, 0x9c, 0xb4,
-	0xa1, 0x20, 0xf1, 0x40, 0xe4, 0x26, 0x9b, 0xab, 0x39, 0xc7, 0x1b, 0xbc, 0x9b, 0xea, 0xf2, 0xce,
-	0x0b, 0xff, 0x06, 0xff, 0x07, 0xff, 0x13, 0xef, 0xbc, 0x20, 0xef, 0x0f, 0x37, 0x76, 0xec, 0x9c,
-	0xc9, 0x8b, 0xed, 0xdd, 0x99, 0xfd, 0xcc, 0xcc, 0x67, 0x76, 0x66, 0x12, 0xb0, 0xee, 0x9d, 0x85,
-	0x7b, 0xc1, 0x48, 0xf0, 0xe0, 0x4e, 0x08, 0xbb, 0xe0, 0xae, 0xe7, 0x91, 0xa0, 0xb3, 0x08, 0x28,
-	0xa7, 0xa8, 0x11, 0xca, 0x3a, 0x5a, 0xd6, 0x91, 0x32, 0xeb, 0x4c, 0x9c, 0x98, 0xdc, 0x3b, 0x01,
-	0x97, 0x4f, 0xa9, 0x6d, 0x35, 0xd7, 0xf7, 0xa9, 0x3f, 0x73, 0xdf, 0x28, 0x81, 0x34, 0x11, 0x10,
-	0x8f, 0x38, 0x8c, 0xe8, 0x77, 0xec, 0x90, 0x96, 0xb9, 0xfe, 0x8c, 0x2a, 0xc1, 0x79, 0x4c, 0xc0,
-	0xb8, 0xc3, 0x97, 0x2c, 0x86, 0xf7, 0x40, 0x02, 0xe6, 0x52, 0x5f, 0xbf, 0xa5, 0xcc, 0xfe, 0xb3,
-	0x00, 0x4f, 0x6e, 0x5c, 0xc6, 0xb1, 0x3c, 0xc8, 0x30, 0xf9, 0x6d, 0x49, 0x18, 0x47, 0x0d, 0xd8,
-	0xf7, 0xdc, 0xb9, 0xcb, 0x4d, 0xa3, 0x65, 0xb4, 0x8b, 0x58, 0x2e, 0xd0, 0x19, 0x94, 0xe9, 0x6c,
-	0xc6, 0x08, 0x37, 0x0b, 0x2d, 0xa3, 0x5d, 0xc3, 0x6a, 0x85, 0xbe, 0x85, 0x0a, 0xa3, 0x01, 0x1f,
-	0xdf, 0xad, 0xcc, 0x62, 0xcb, 0x68, 0x1f, 0x77, 0x3f, 0xee, 0xa4, 0x51, 0xd1, 0x09, 0x2d, 0x8d,
-	0x68, 0xc0, 0x3b, 0xe1, 0xe3, 0xc5, 0x0a, 0x97, 0x99, 0x78, 0x87, 0xb8, 0x33, 0xd7, 0xe3, 0x24,
-	0x30, 0x4b, 0x12, 0x57, 0xae, 0xd0, 0x00, 0x40, 0xe0, 0xd2, 0x60, 0x4a, 0x02, 0x73, 0x5f, 0x40,
-	0xb7, 0x73, 0x40, 0xbf, 0x0e, 0xf5, 0x71, 0x8d, 0xe9, 0x4f, 0xf4, 0x0d, 0x1c, 0x4a, 0x4a, 0xc6,
-	0x13, 0x3a, 0x25, 0xcc, 0x2c, 0xb7, 0x8a, 0xed, 0xe3, 0xee, 0xb9, 0x84, 0xd2, 0x0c, 0x8f, 0x24,
-	0x69, 0x3d, 0x3a, 0x25, 0xf8, 0x40, 0xaa, 0x87, 0xdf, 0xcc, 0xfe, 0x05, 0xaa, 0x1a, 0xde, 0xee,
-	0x42, 0x59, 0x3a, 0x8f, 0x0e, 0xa0, 0x72, 0x3b, 0x7c, 0x35, 0x7c, 0xfd, 0xd3, 0xb0, 0xbe, 0x87,
-	0xaa, 0x50, 0x1a, 0x5e, 0x7e, 0xdf, 0xaf, 0x1b, 0xe8, 0x04, 0x8e, 0x6e, 0x2e, 0x47, 0x3f, 0x8c,
-	0x71, 0xff, 0xa6, 0x7f, 0x39, 0xea, 0xbf, 0xac, 0x17, 0xec, 0x0f, 0xa1, 0x16, 0x79, 0x85, 0x2a,
-	0x50, 0xbc, 0x1c, 0xf5, 0xe4, 0x91, 0x97, 0xfd, 0x51, 0xaf, 0x6e, 0xd8, 0x7f, 0x18, 0xd0, 0x88,
-	0x27, 0x81, 0x2d, 0xa8, 0xcf, 0x48, 0x98, 0x85, 0x09, 0x5d, 0xfa, 0x51, 0x16, 0xc4, 0x02, 0x21,
-	0x28, 0xf9, 0xe4, 0x9d, 0xce, 0x81, 0xf8, 0x0e, 0x35, 0x39, 0xe5, 0x8e, 0x27, 0xf8, 0x2f, 0x62,
-	0xb9, 0x40, 0x5f, 0x42, 0x55, 0x05, 0xc7, 0xcc, 0x52, 0xab, 0xd8, 0x3e, 0xe8, 0x9e, 0xc6, 0x43,
-	0x56, 0x16, 0x71, 0xa4, 0x66, 0x0f, 0xa0, 0x39, 0x20, 0xda, 0x13, 0xc9, 0x88, 0xbe, 0x13, 0xa1,
-	0x5d, 0x67, 0x4e, 0x84, 0x33, 0xa1, 0x5d, 0x67, 0x4e, 0x90, 0x09, 0x15, 0x75, 0xa1, 0x84, 0x3b,
-	0xfb, 0x58, 0x2f, 0x6d, 0x0e, 0xe6, 0x26, 0x90, 0x8a, 0x2b, 0x0d, 0xe9, 0x13, 0x28, 0x85, 0xd7,
-	0x59, 0xc0, 0x1c, 0x74, 0x51, 0xdc, 0xcf, 0x6b, 0x7f, 0x46, 0xb1, 0x90, 0xa3, 0xff, 0x43, 0x2d,
-	0xd4, 0x67, 0x0b, 0x67, 0x42, 0x44, 0xb4, 0x35, 0xfc, 0xb8, 0x61, 0x5f, 0xad, 0x5b, 0xed, 0x51,
-	0x9f, 0x13, 0x9f, 0xef, 0xe6, 0xff, 0x0d, 0x9c, 0xa7, 0x20, 0xa9, 0x00, 0x2e, 0xa0, 0xa2, 0x5c,
-	0x13, 0x68, 0x99, 0xbc, 0x6a, 0x2d, 0xfb, 0x2f, 0x03, 0x1a, 0xb7, 0x8b, 0xa9, 0xc3, 0x89, 0x16,
-	0x6d, 0x71, 0xea, 0x29, 0xec, 0x8b, 0xb6, 0xa0, 0xb8, 0x38, 0x91, 0xd8, 0xb2, 0x77, 0xf4, 0xc2,
-	0x27, 0x96, 0x72, 0xf4, 0x0c, 0xca, 0x0f, 0x8e, 0xb7, 0x24, 0x4c, 0x10, 0x11, 0xb1, 0xa6, 0x34,
-	0x45, 0x4f, 0xc1, 0x4a, 0x03, 0x35, 0xa1, 0x32, 0x0d, 0x56, 0xe3, 0x60, 0xe9, 0x8b, 0x22, 0xab,
-	0xe2, 0xf2, 0x34, 0x58, 0xe1, 0xa5, 0x8f, 0x3e, 0x82, 0xa3, 0xa9, 0xcb, 0x9c, 0x3b, 0x8f, 0x8c,
-	0xef, 0x29, 0x7d, 0xcb, 0x44, 0x9d, 0x55, 0xf1, 0xa1, 0xda, 0xbc, 0x0a, 0xf7, 0xec, 0x2b, 0x38,
-	0x4d, 0xb8, 0xbf, 0x2b, 0x13, 0xbf, 0x1b, 0x70, 0x86, 0xa9, 0xe7, 0xdd, 0x39, 0x93, 0xb7, 0x39,
-	0xb8, 0x58, 0x73, 0xbb, 0xb0, 0xdd, 0xed, 0xe2, 0xa6, 0xdb, 0xeb, 0xe9, 0x2d, 0xc5, 0xd3, 0xfb,
-	0x1d, 0x34, 0x37, 0xbc, 0xd8, 0x35, 0xa4, 0x7f, 0x0c, 0x38, 0xbd, 0xf6, 0x19, 0x77, 0x3c, 0x2f,
-	0x11, 0x51, 0x94, 0x49, 0x23, 0x77, 0x26, 0x0b, 0xff, 0x25, 0x93, 0xc5, 0x18, 0x25, 0x9a, 0xbf,
-	0xd2, 0x1a, 0x7f, 0x79, 0xb2, 0x1b, 0xaf, 0xa9, 0x72, 0xa2, 0xa6, 0xd0, 0x07, 0x00, 0x01, 0x59,
-	0x32, 0x32, 0x16, 0xe0, 0x15, 0x71, 0xbe, 0x26, 0x76, 0x86, 0xce, 0x9c, 0xd8, 0xd7, 0x70, 0x96,
-	0x0c, 0x7e, 0x57, 0x22, 0xef, 0xa1, 0x79, 0xeb, 0xbb, 0xa9, 0x4c, 0xa6, 0xdd, 0x8d, 0x8d, 0xd8,
-	0x0a, 0x29, 0xb1, 0x35, 0x60, 0x7f, 0xb1, 0x0c, 0xde, 0x10, 0xc5, 0x95, 0x5c, 0xd8, 0xaf, 0xc0,
-	0xdc, 0xb4, 0xb4, 0xab, 0xdb, 0x4f, 0xe0, 0x64, 0x40, 0xf8, 0x8f, 0xf2, 0x66, 0x29, 0x87, 0xed,
-	0x3e, 0xa0, 0xf5, 0xcd, 0x47, 0x6c, 0xb5, 0x15, 0xc7, 0xd6, 0x53, 0x59, 0xeb, 0x6b, 0x2d, 0xfb,
-	0x6b, 0x81, 0x7d, 0xe5, 0x32, 0x4e, 0x83, 0xd5, 0x36, 0x32, 0xea, 0x50, 0x9c, 0x3b, 0xef, 0x54,
-	0x17, 0x0b, 0x3f, 0xed, 0x81, 0xf0, 0x20, 0x3a, 0xaa, 0x3c, 0x58, 0x9f, 0x09, 0x46, 0xae, 0x99,
-	0xd0, 0xfd, 0xbb, 0x02, 0xc7, 0xba, 0x91, 0xcb, 0xb1, 0x8b, 0x5c, 0x38, 0x5c, 0x9f, 0x58, 0xe8,
-	0xd3, 0xec, 0xa9, 0x9c, 0xf8, 0x69, 0x61, 0x3d, 0xcb, 0xa3, 0x2a, 0x9d, 0xb5, 0xf7, 0xbe, 0x30,
-	0x10, 0x83, 0x7a, 0x72, 0x90, 0xa0, 0xe7, 0xe9, 0x18, 0x19, 0x93, 0xcb, 0xea, 0xe4, 0x55, 0xd7,
-	0x66, 0xd1, 0x83, 0xa0, 0x3d, 0xde, 0xfd, 0xd1, 0x7b, 0x61, 0xe2, 0x03, 0xc7, 0xba, 0xc8, 0xad,
-	0x1f, 0xd9, 0xfd, 0x15, 0x8e, 0x62, 0x7d, 0x16, 0x65, 0xb0, 0x95, 0x36, 0x4b, 0xac, 0xcf, 0x72,
-	0xe9, 0x46, 0xb6, 0xe6, 0x70, 0x1c, 0x2f, 0x5c, 0x94, 0x01, 0x90, 0xda, 0xdb, 0xac, 0xcf, 0xf3,
-	0x29, 0x47, 0xe6, 0x18, 0xd4, 0x93, 0x25, 0x97, 0x95, 0xc7, 0x8c, 0x26, 0x90, 0x95, 0xc7, 0xac,
-	0x4a, 0xb6, 0xf7, 0x90, 0x03, 0xf0, 0x58, 0x85, 0xe8, 0x69, 0x66, 0x42, 0xe2, 0xc5, 0x6b, 0xb5,
-	0xdf, 0xaf, 0x18, 0x99, 0x58, 0xc0, 0xff, 0x12, 0x93, 0x04, 0x65, 0x50, 0x93, 0x3e, 0xf6, 0xac,
-	0xe7, 0x39, 0xb5, 0x13, 0x41, 0xa9, 0xc2, 0xde, 0x12, 0x54, 0xbc, 0x6b, 0x6c, 0x09, 0x2a, 0xd1,
-	0x23, 0xec, 0xbd, 0x17, 0xf0, 0x73, 0x55, 0xeb, 0xdd, 0x95, 0xc5, 0x5f, 0x85, 0xaf, 0xfe, 0x0d,
-	0x00, 0x00, 0xff, 0xff, 0x9a, 0xb0, 0xe9, 0x29, 0xfb, 0x0c, 0x00, 0x00,
+	// 2270 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x1a, 0x4d, 0x73, 0xdb, 0xc6,
+	0x35, 0x24, 0x25, 0x7e, 0x3c, 0x92, 0x32, 0xb5, 0xd6, 0x07, 0x82, 0x49, 0x13, 0x19, 0x69, 0x6a,
+	0xc5, 0x1f, 0x74, 0xab, 0x5e, 0xec, 0xb4, 0x93, 0x19, 0x47, 0x52, 0x64, 0xc7, 0xb6, 0xe2, 0x01,
+	0x2d, 0xb7, 0xd3, 0x76, 0xca, 0x59, 0x81, 0x2b, 0x12, 0x16, 0x88, 0x45, 0x17, 0x20, 0x2b, 0xce,
+	0xb4, 0xc7, 0x1e, 0x32, 0xd3, 0x7b, 0x8f, 0x9d, 0xe9, 0xaf, 0xe9, 0x1f, 0xe9, 0xa9, 0xa7, 0xfe,
+	0x83, 0xce, 0x7e, 0x81, 0x00, 0x08, 0xca, 0x10, 0xe3, 0x8b, 0x88, 0xf7, 0xf6, 0xed, 0x7b, 0x6f,
+	0xdf, 0xf7, 0xae, 0x0d, 0xe6, 0x08, 0x07, 0xee, 0xa3, 0x90, 0xb0, 0xa9, 0xeb, 0x90, 0xf0, 0x51,
+	0xe4, 0x7a, 0x1e, 0x61, 0xdd, 0x80, 0xd1, 0x88, 0xa2, 0x2d, 0xbe, 0xd6, 0xd5, 0x6b, 0x5d, 0xb9,
+	0x66, 0xee, 0x88, 0x1d, 0xce, 0x08, 0xb3, 0x48, 0xfe, 0x95, 0xd4, 0xe6, 0x6e, 0x12, 0x4f, 0xfd,
+	0x0b, 0x77, 0xa8, 0x16, 0xa4, 0x08, 0x46, 0x3c, 0x82, 0x43, 0xa2, 0x7f, 0x53, 0x9b, 0xf4, 0x9a,
+	0xeb, 0x5f, 0x50, 0xb5, 0xf0, 0x71, 0x6a, 0x21, 0x8c, 0x70, 0x34, 0x09, 0x53, 0xfc, 0xa6, 0x84,
+	0x85, 0x2e, 0xf5, 0xf5, 0xaf, 0x5c, 0xb3, 0xfe, 0x55, 0x86, 0xdb, 0x2f, 0xdd, 0x30, 0xb2, 0xe5,
+	0xc6, 0xd0, 0x26, 0x7f, 0x9a, 0x90, 0x30, 0x42, 0x5b, 0xb0, 0xee, 0xb9, 0x63, 0x37, 0x32, 0x4a,
+	0x7b, 0xa5, 0xfd, 0x8a, 0x2d, 0x01, 0xb4, 0x03, 0x55, 0x7a, 0x71, 0x11, 0x92, 0xc8, 0x28, 0xef,
+	0x95, 0xf6, 0x1b, 0xb6, 0x82, 0xd0, 0xd7, 0x50, 0x0b, 0x29, 0x8b, 0xfa, 0xe7, 0x33, 0xa3, 0xb2,
+	0x57, 0xda, 0xdf, 0x38, 0xf8, 0xa2, 0x9b, 0x67, 0x8a, 0x2e, 0x97, 0xd4, 0xa3, 0x2c, 0xea, 0xf2,
+	0x3f, 0xdf, 0xcc, 0xec, 0x6a, 0x28, 0x7e, 0x39, 0xdf, 0x0b, 0xd7, 0x8b, 0x08, 0x33, 0xd6, 0x24,
+	0x5f, 0x09, 0xa1, 0x13, 0x00, 0xc1, 0x97, 0xb2, 0x01, 0x61, 0xc6, 0xba, 0x60, 0xbd, 0x5f, 0x80,
+	0xf5, 0xf7, 0x9c, 0xde, 0x6e, 0x84, 0xfa, 0x13, 0xfd, 0x1a, 0x5a, 0xd2, 0x24, 0x7d, 0x87, 0x0e,
+	0x48, 0x68, 0x54, 0xf7, 0x2a, 0xfb, 0x1b, 0x07, 0x1f, 0x4b, 0x56, 0xda, 0xc2, 0x3d, 0x69, 0xb4,
+	0x43, 0x3a, 0x20, 0x76, 0x53, 0x92, 0xf3, 0xef, 0xd0, 0xfa, 0x23, 0xd4, 0x35, 0x7b, 0xeb, 0x00,
+	0xaa, 0x52, 0x79, 0xd4, 0x84, 0xda, 0xd9, 0xe9, 0x8b, 0xd3, 0xef, 0x7f, 0x73, 0xda, 0xf9, 0x08,
+	0xd5, 0x61, 0xed, 0xf4, 0xe9, 0xab, 0xe3, 0x4e, 0x09, 0x6d, 0x42, 0xfb, 0xe5, 0xd3, 0xde, 0x9b,
+	0xbe, 0x7d, 0xfc, 0xf2, 0xf8, 0x69, 0xef, 0xf8, 0xa8, 0x53, 0xb6, 0x3e, 0x85, 0x46, 0xac, 0x15,
+	0xaa, 0x41, 0xe5, 0x69, 0xef, 0x50, 0x6e, 0x39, 0x3a, 0xee, 0x1d, 0x76, 0x4a, 0xd6, 0x0f, 0x25,
+	0xd8, 0x4a, 0x3b, 0x21, 0x0c, 0xa8, 0x1f, 0x12, 0xee, 0x05, 0x87, 0x4e, 0xfc, 0xd8, 0x0b, 0x02,
+	0x40, 0x08, 0xd6, 0x7c, 0x72, 0xa5, 0x7d, 0x20, 0xbe, 0x39, 0x65, 0x44, 0x23, 0xec, 0x09, 0xfb,
+	0x57, 0x6c, 0x09, 0xa0, 0x5f, 0x40, 0x5d, 0x1d, 0x2e, 0x34, 0xd6, 0xf6, 0x2a, 0xfb, 0xcd, 0x83,
+	0xed, 0xf4, 0x91, 0x95, 0x44, 0x3b, 0x26, 0xb3, 0x4e, 0x60, 0xf7, 0x84, 0x68, 0x4d, 0xa4, 0x45,
+	0x74, 0x4c, 0x70, 0xb9, 0x78, 0x4c, 0x8c, 0x92, 0x92, 0x8b, 0xc7, 0x04, 0x19, 0x50, 0x53, 0x01,
+	0x25, 0xd4, 0x59, 0xb7, 0x35, 0x68, 0x45, 0x60, 0x2c, 0x32, 0x52, 0xe7, 0xca, 0xe3, 0xf4, 0x33,
+	0x58, 0xe3, 0xe1, 0x2c, 0xd8, 0x34, 0x0f, 0x50, 0x5a, 0xcf, 0xe7, 0xfe, 0x05, 0xb5, 0xc5, 0x3a,
+	0xfa, 0x04, 0x1a, 0x9c, 0x3e, 0x0c, 0xb0, 0x43, 0xc4, 0x69, 0x1b, 0xf6, 0x1c, 0x61, 0x3d, 0x4b,
+	0x4a, 0x3d, 0xa4, 0x7e, 0x44, 0xfc, 0x68, 0x35, 0xfd, 0x5f, 0xc2, 0xc7, 0x39, 0x9c, 0xd4, 0x01,
+	0x1e, 0x41, 0x4d, 0xa9, 0x26, 0xb8, 0x2d, 0xb5, 0xab, 0xa6, 0xb2, 0xfe, 0x51, 0x87, 0xad, 0xb3,
+	0x60, 0x80, 0x23, 0xa2, 0x97, 0xae, 0x51, 0xea, 0x2e, 0xac, 0x8b, 0xb2, 0xa0, 0x6c, 0xb1, 0x29,
+	0x79, 0x0b, 0x54, 0xf7, 0x90, 0xff, 0xb5, 0xe5, 0x3a, 0xba, 0x07, 0xd5, 0x29, 0xf6, 0x26, 0x24,
+	0x34, 0x2a, 0x49, 0xab, 0x29, 0x4a, 0x51, 0x53, 0x6c, 0x45, 0x81, 0x76, 0xa1, 0x36, 0x60, 0xb3,
+	0x3e, 0x9b, 0xf8, 0x22, 0xc9, 0xea, 0x76, 0x75, 0xc0, 0x66, 0xf6, 0xc4, 0x47, 0x9f, 0x43, 0x7b,
+	0xe0, 0x86, 0xf8, 0xdc, 0x23, 0xfd, 0x11, 0xa5, 0x97, 0xa1, 0xc8, 0xb3, 0xba, 0xdd, 0x52, 0xc8,
+	0x67, 0x1c, 0x87, 0x3e, 0x83, 0x26, 0xcf, 0x38, 0xc2, 0xfa, 0xa1, 0x3b, 0x20, 0x46, 0x55, 0x90,
+	0x80, 0x44, 0xf5, 0xdc, 0x01, 0x41, 0xf7, 0x61, 0xd3, 0xf5, 0x1d, 0x6f, 0x32, 0x20, 0xfd, 0x88,
+	0x8c, 0x03, 0x0f, 0x47, 0x24, 0x34, 0x6a, 0x7b, 0x95, 0xfd, 0x86, 0xdd, 0x51, 0x0b, 0x6f, 0x34,
+	0x9e, 0x13, 0x93, 0xab, 0x2c, 0x71, 0x5d, 0x12, 0x93, 0xab, 0x0c, 0xf1, 0x01, 0x6c, 0x6b, 0xfd,
+	0x94, 0x6f, 0xfa, 0xce, 0x88, 0x38, 0x97, 0x46, 0x43, 0x28, 0x71, 0x5b, 0x2d, 0xbe, 0x95, 0x6b,
+	0x87, 0x7c, 0x09, 0x7d, 0x01, 0x1b, 0xe1, 0xe4, 0x5c, 0xd8, 0xa1, 0xef, 0x53, 0xce, 0x1d, 0x04,
+	0x71, 0x5b, 0x63, 0x4f, 0x39, 0x12, 0x1d, 0x41, 0x4b, 0xd2, 0x84, 0x74, 0xc2, 0x1c, 0x62, 0x34,
+	0x85, 0x15, 0xef, 0xe4, 0x57, 0x18, 0x61, 0xf9, 0x9e, 0x20, 0xb4, 0x9b, 0xce, 0x1c, 0xe0, 0x2e,
+	0xfc, 0x33, 0x76, 0x23, 0xa3, 0x25, 0x44, 0x88, 0x6f, 0x64, 0x41, 0x9b, 0xff, 0xf6, 0x2f, 0x28,
+	0xeb, 0xbf, 0xa3, 0xe7, 0xa1, 0xd1, 0x16, 0x8b, 0x4d, 0x8e, 0xfc, 0x96, 0xb2, 0xef, 0xe8, 0x79,
+	0xc8, 0x63, 0x2f, 0x72, 0xc7, 0x84, 0x4e, 0x22, 0x63, 0x43, 0x64, 0xad, 0x06, 0xd1, 0x1b, 0xa8,
+	0x8f, 0x49, 0x84, 0x07, 0x38, 0xc2, 0xc6, 0x2d, 0x91, 0xb7, 0x8f, 0xf3, 0x75, 0xca, 0x0b, 0xa9,
+	0xee, 0x2b, 0xb5, 0xf5, 0xd8, 0x8f, 0xd8, 0xcc, 0x8e, 0x39, 0x71, 0xa3, 0x50, 0xdf, 0x9b, 0xf5,
+	0xb5, 0x0d, 0x42, 0xa3, 0x23, 0x4c, 0xde, 0xe6, 0xd8, 0x9e, 0x46, 0xa2, 0x9f, 0xc2, 0x86, 0x72,
+	0xb5, 0x8e, 0x97, 0x4d, 0x19, 0x10, 0x12, 0x7b, 0x14, 0x47, 0x0d, 0x9d, 0x12, 0xc6, 0xdc, 0x01,
+	0xe9, 0x7b, 0xd4, 0xb9, 0x34, 0x90, 0x08, 0xe0, 0x96, 0x46, 0xbe, 0xa4, 0xd2, 0x0d, 0x31, 0xd1,
+	0x50, 0x38, 0xf9, 0xb6, 0xa0, 0x8a, 0xb7, 0x9e, 0x08, 0x0f, 0x7f, 0x01, 0x1b, 0x0e, 0xf6, 0x31,
+	0x9b, 0xf5, 0x03, 0xc2, 0x1c, 0xe2, 0x47, 0xc6, 0x96, 0xc8, 0xc5, 0xb6, 0xc4, 0xbe, 0x96, 0x48,
+	0x74, 0x17, 0x6e, 0x29, 0xb2, 0xc1, 0x84, 0xe1, 0x88, 0xe7, 0xec, 0xb6, 0xb0, 0x9b, 0xda, 0x7d,
+	0xa4, 0xb0, 0x68, 0x1f, 0x3a, 0x8a, 0x50, 0x04, 0x4a, 0x7f, 0xc2, 0x3c, 0x63, 0x47, 0x08, 0x56,
+	0x94, 0x22, 0x48, 0xce, 0x98, 0x87, 0x7e, 0x02, 0x70, 0xee, 0x4d, 0x48, 0x7f, 0xc8, 0x08, 0xf1,
+	0x8d, 0x5d, 0x71, 0xce, 0x06, 0xc7, 0x9c, 0x70, 0x84, 0x08, 0xa3, 0x4b, 0x37, 0xe8, 0x07, 0x8c,
+	0x5c, 0x78, 0xee, 0x70, 0x14, 0x19, 0x86, 0x0a, 0xa3, 0x4b, 0x37, 0x78, 0xad, 0x91, 0xe6, 0xaf,
+	0xa0, 0x9d, 0xb2, 0x39, 0xea, 0x40, 0xe5, 0x92, 0xcc, 0x54, 0x4e, 0xf3, 0x4f, 0x5e, 0x9f, 0x45,
+	0x1e, 0xaa, 0xa2, 0x2d, 0x81, 0xaf, 0xca, 0x8f, 0x4b, 0xd6, 0x33, 0xd8, 0xce, 0x78, 0x71, 0xd5,
+	0x1a, 0xf3, 0x9f, 0x32, 0xec, 0xd8, 0xd4, 0xf3, 0xce, 0xb1, 0x73, 0x59, 0xa0, 0xca, 0x24, 0x0a,
+	0x42, 0xf9, 0xfa, 0x82, 0x50, 0xc9, 0x29, 0x08, 0x89, 0xc2, 0xb9, 0x96, 0x2a, 0x9c, 0xe8, 0x6d,
+	0x22, 0x78, 0xd7, 0x45, 0xf0, 0x7e, 0x95, 0x1f, 0xbc, 0xf9, 0xba, 0x2e, 0x0d, 0xdf, 0x85, 0x88,
+	0xab, 0x16, 0x8a, 0xb8, 0x5a, 0x4e, 0xc4, 0xfd, 0x38, 0x8f, 0x7d, 0x07, 0xbb, 0x0b, 0xaa, 0xaf,
+	0xea, 0xb3, 0xff, 0x55, 0x61, 0xfb, 0xb9, 0x1f, 0x46, 0xd8, 0xf3, 0x32, 0x2e, 0x8b, 0x9b, 0x40,
+	0xa9, 0x70, 0x13, 0x28, 0xdf, 0xa4, 0x09, 0x54, 0x52, 0x3e, 0xd7, 0x01, 0xb2, 0x96, 0x08, 0x90,
+	0x42, 0x8d, 0x21, 0xd5, 0x8e, 0xab, 0x99, 0x76, 0xcc, 0xf3, 0x8b, 0x91, 0x49, 0x48, 0xfa, 0x82,
+	0x79, 0x4d, 0xe6, 0x97, 0xc0, 0x9c, 0x72, 0x09, 0x99, 0xae, 0x52, 0x2f, 0xd6, 0x55, 0x1a, 0x37,
+	0xe9, 0x2a, 0x70, 0xd3, 0xae, 0xd2, 0xbc, 0x49, 0x57, 0x69, 0x15, 0xe9, 0x2a, 0xed, 0x1f, 0xd5,
+	0x55, 0x36, 0xae, 0xeb, 0x2a, 0xb7, 0xae, 0xed, 0x2a, 0x9d, 0x74, 0x57, 0x39, 0x4b, 0x24, 0xe6,
+	0xa6, 0x48, 0xcc, 0x27, 0xf9, 0x3a, 0xe5, 0x06, 0xe4, 0xd2, 0xbc, 0x5c, 0xec, 0x17, 0x28, 0xa7,
+	0x5f, 0x14, 0x6f, 0x05, 0x99, 0x8a, 0xbb, 0xf5, 0xc1, 0x2b, 0xee, 0x5f, 0xa0, 0x99, 0xb0, 0x3a,
+	0xdf, 0xca, 0x1b, 0x84, 0xda, 0x3a, 0x61, 0x1e, 0xba, 0x03, 0x2d, 0xcc, 0x9c, 0x91, 0x3b, 0x55,
+	0x71, 0x2b, 0x39, 0x34, 0x15, 0xee, 0x54, 0xcd, 0x8d, 0x0a, 0x14, 0x89, 0xd4, 0xb2, 0x35, 0x88,
+	0x3e, 0x05, 0x08, 0x18, 0x9d, 0x12, 0x1f, 0xfb, 0x8e, 0xcc, 0xa7, 0x96, 0x9d, 0xc0, 0x58, 0xcf,
+	0x61, 0x27, 0x6b, 0xdf, 0x55, 0x8b, 0xc7, 0x3f, 0xcb, 0xb0, 0x7b, 0xe6, 0xbb, 0xb9, 0xe5, 0x23,
+	0xaf, 0xe2, 0x2f, 0x24, 0x74, 0x39, 0x27, 0xa1, 0xb7, 0x60, 0x3d, 0x98, 0xb0, 0x21, 0x51, 0x05,
+	0x42, 0x02, 0x8b, 0xc5, 0x77, 0xad, 0x50, 0xf1, 0x5d, 0xcf, 0xf3, 0xb1, 0x01, 0x35, 0x07, 0x87,
+	0x0e, 0x1e, 0xe8, 0x82, 0xa1, 0xc1, 0x38, 0xe6, 0x6b, 0x89, 0x98, 0x4f, 0xc4, 0x73, 0x3d, 0x1d,
+	0xcf, 0x77, 0xa0, 0xa5, 0x8b, 0xc3, 0x25, 0x09, 0x22, 0x35, 0x0f, 0x36, 0x15, 0xee, 0x05, 0x09,
+	0x22, 0xab, 0x0f, 0xc6, 0xa2, 0x81, 0x56, 0x34, 0x37, 0xd7, 0x4e, 0xc8, 0x29, 0x8b, 0x92, 0x22,
+	0xbe, 0xad, 0xdb, 0xb0, 0x79, 0x42, 0x22, 0x55, 0x25, 0x94, 0xed, 0xad, 0x63, 0x40, 0x49, 0xe4,
+	0x5c, 0x9e, 0x42, 0xa5, 0xe5, 0xe9, 0x0b, 0xb9, 0xa6, 0xd7, 0x54, 0xd6, 0x13, 0xc1, 0xfb, 0x99,
+	0x1b, 0x46, 0x94, 0xcd, 0xae, 0xf3, 0x6b, 0x07, 0x2a, 0x63, 0x7c, 0xa5, 0x2e, 0x30, 0xfc, 0xd3,
+	0x3a, 0x01, 0x94, 0xdc, 0xaa, 0x34, 0x48, 0x5e, 0x07, 0x4b, 0xc5, 0xae, 0x83, 0x7f, 0x85, 0xad,
+	0xe7, 0xe3, 0x80, 0xb2, 0x28, 0x13, 0x5e, 0x37, 0x67, 0x95, 0xee, 0x14, 0xe5, 0x6c, 0xa7, 0xd8,
+	0x82, 0x75, 0x1c, 0x04, 0xde, 0x4c, 0x87, 0x9d, 0x00, 0xf8, 0x70, 0x94, 0x11, 0xbf, 0x6a, 0xae,
+	0x8c, 0xa1, 0x6d, 0x13, 0x59, 0x90, 0x8f, 0xa7, 0xc4, 0x17, 0x6f, 0x19, 0xd8, 0x89, 0xb4, 0x37,
+	0x1a, 0xb6, 0x82, 0x84, 0x97, 0x5d, 0x7f, 0xa0, 0x6f, 0xd7, 0xfc, 0x3b, 0x36, 0x7a, 0x25, 0x61,
+	0xf4, 0xd4, 0x71, 0xd6, 0xb2, 0xf7, 0xd0, 0xbf, 0x95, 0x60, 0x57, 0xe9, 0xf0, 0x9a, 0xd1, 0x21,
+	0x23, 0xe1, 0xfc, 0xf6, 0xfb, 0x04, 0xd6, 0x09, 0x57, 0x41, 0x69, 0xfe, 0xf9, 0x92, 0xe9, 0x28,
+	0xa9, 0xad, 0x2d, 0x77, 0x24, 0x8f, 0x5d, 0x2e, 0x74, 0xec, 0x4b, 0xd8, 0x99, 0xdf, 0x62, 0x8f,
+	0x98, 0x7b, 0xb1, 0xda, 0x6d, 0x98, 0xe7, 0xbf, 0x3b, 0xf4, 0x29, 0x23, 0xfd, 0x0b, 0x97, 0x78,
+	0x03, 0x3e, 0x13, 0xf2, 0x24, 0x68, 0x49, 0xe4, 0xb7, 0x02, 0x67, 0xfd, 0x01, 0x76, 0x17, 0x84,
+	0xa9, 0x33, 0x3f, 0x85, 0x06, 0x53, 0x07, 0xd2, 0x01, 0xf3, 0x9e, 0x73, 0xcb, 0xfd, 0xf3, 0x5d,
+	0xd6, 0xbf, 0x4b, 0xd0, 0x4e, 0x2d, 0xf2, 0xf1, 0x01, 0x07, 0xae, 0xee, 0xdf, 0xea, 0x24, 0x80,
+	0x03, 0x57, 0x65, 0xd0, 0x87, 0xf1, 0x25, 0x8f, 0x14, 0xf9, 0x1a, 0xa4, 0xca, 0x99, 0x82, 0xd0,
+	0x63, 0xfe, 0x6a, 0x25, 0x8c, 0x51, 0x15, 0x07, 0xda, 0xcb, 0x3f, 0x90, 0x30, 0x8e, 0x3c, 0x8d,
+	0xa2, 0xb7, 0x06, 0x00, 0x73, 0x2c, 0xd7, 0x28, 0xc0, 0xd1, 0x48, 0x7b, 0x82, 0x7f, 0x73, 0x99,
+	0xce, 0x08, 0xfb, 0x43, 0x9d, 0x29, 0x0a, 0x92, 0xba, 0x50, 0x46, 0x06, 0x4a, 0x7f, 0x05, 0x71,
+	0x1e, 0x9e, 0x3b, 0xd5, 0xca, 0x8b, 0x6f, 0xeb, 0x15, 0xa0, 0xde, 0xcc, 0x77, 0x8a, 0x35, 0x86,
+	0xb4, 0x77, 0xcb, 0x39, 0xde, 0xfd, 0x2d, 0xdc, 0x4e, 0xb1, 0xfb, 0x70, 0x9e, 0xfd, 0xa1, 0x04,
+	0xbb, 0xbd, 0x38, 0x70, 0xde, 0x8a, 0x51, 0xf5, 0x3a, 0x75, 0x6f, 0x32, 0xf1, 0x1a, 0x3c, 0x63,
+	0x02, 0x4f, 0x3f, 0x16, 0xd5, 0x6d, 0x0d, 0xce, 0x2b, 0xce, 0x5a, 0xb2, 0xe2, 0xbc, 0x00, 0x63,
+	0x51, 0x95, 0x55, 0x8b, 0x8e, 0x0d, 0x3b, 0xaf, 0xb0, 0xeb, 0x47, 0xd8, 0xf5, 0x7b, 0x11, 0x65,
+	0x78, 0x18, 0x7b, 0xe1, 0x33, 0x68, 0x8e, 0xf1, 0x55, 0x7f, 0x24, 0x2b, 0xb4, 0x60, 0xb7, 0x6e,
+	0xc3, 0x18, 0x5f, 0xa9, 0x9a, 0xbd, 0xf4, 0x76, 0x66, 0xfd, 0xbd, 0x04, 0x2d, 0xc5, 0xec, 0x2c,
+	0xc4, 0xc3, 0xfc, 0xc7, 0xb4, 0x4f, 0xb8, 0x53, 0xa6, 0x2e, 0x4f, 0x82, 0x50, 0xa5, 0xf2, 0x1c,
+	0xc1, 0x4f, 0x7e, 0x3e, 0x8b, 0xd4, 0xab, 0x51, 0xc5, 0x96, 0x80, 0xb4, 0xd4, 0x98, 0x4e, 0xc9,
+	0x40, 0xdf, 0xe8, 0x14, 0x88, 0x4c, 0xa8, 0x53, 0x16, 0x8c, 0xb0, 0x4f, 0x06, 0xea, 0x0e, 0x10,
+	0xc3, 0xd6, 0x04, 0x76, 0x17, 0x8e, 0xa8, 0xcc, 0xf5, 0xf5, 0x42, 0x8f, 0xb0, 0xf2, 0x03, 0x23,
+	0x79, 0x9c, 0x44, 0xc3, 0x30, 0xa1, 0x1e, 0x30, 0x7a, 0xee, 0x91, 0xb1, 0x0e, 0xc8, 0x18, 0xb6,
+	0x7e, 0x0f, 0xdb, 0x73, 0x37, 0xf1, 0xe1, 0xe3, 0xba, 0x78, 0xd9, 0x81, 0x2a, 0x23, 0x38, 0x54,
+	0x55, 0xad, 0x61, 0x2b, 0x88, 0xe3, 0x27, 0xbe, 0x98, 0x66, 0xd4, 0x65, 0x48, 0x42, 0x7c, 0x44,
+	0xcb, 0x32, 0x5f, 0x35, 0x02, 0x46, 0xb0, 0xfd, 0x9a, 0xd1, 0x31, 0x2d, 0xf4, 0xee, 0x77, 0x07,
+	0x5a, 0x43, 0x86, 0x1d, 0xc2, 0x9f, 0x41, 0x5c, 0x2a, 0xcb, 0x56, 0xc5, 0x6e, 0x0a, 0xdc, 0x6b,
+	0x81, 0x4a, 0x4e, 0x43, 0x95, 0xd4, 0x34, 0xc4, 0x95, 0xce, 0x4a, 0x5a, 0x51, 0xe9, 0x83, 0xff,
+	0xde, 0x82, 0x0d, 0x85, 0xec, 0x49, 0x57, 0x21, 0x17, 0x5a, 0xc9, 0x17, 0x6a, 0xf4, 0xe5, 0xf2,
+	0x57, 0xf8, 0xcc, 0x3f, 0x25, 0x98, 0xf7, 0x8a, 0x90, 0x4a, 0x55, 0xad, 0x8f, 0x7e, 0x5e, 0x42,
+	0x21, 0x74, 0xb2, 0x0f, 0xc7, 0xe8, 0x61, 0x3e, 0x8f, 0x25, 0x2f, 0xd5, 0x66, 0xb7, 0x28, 0xb9,
+	0x16, 0x8b, 0xa6, 0xb0, 0x39, 0x5f, 0x55, 0xaf, 0xbd, 0xe8, 0xbd, 0x6c, 0xd2, 0x0f, 0xcc, 0xe6,
+	0xa3, 0xc2, 0xf4, 0xb1, 0xdc, 0x77, 0xd0, 0x4e, 0xbd, 0xfe, 0xa0, 0x7b, 0xc5, 0x1f, 0xfa, 0xcc,
+	0xfb, 0x85, 0x68, 0x63, 0x59, 0x63, 0xd8, 0x48, 0xdf, 0x3c, 0xd0, 0xfd, 0x1b, 0xdc, 0xff, 0xcc,
+	0x07, 0xc5, 0x88, 0x63, 0x71, 0x21, 0x74, 0xb2, 0xb3, 0xf7, 0x32, 0x3f, 0x2e, 0xb9, 0xc4, 0x98,
+	0xdd, 0xa2, 0xe4, 0xb1, 0x50, 0x0c, 0x30, 0x1f, 0xbd, 0xd1, 0xdd, 0xa5, 0x0e, 0x49, 0x4f, 0xec,
+	0xe6, 0xfe, 0xfb, 0x09, 0x63, 0x11, 0x01, 0xdc, 0xca, 0x3c, 0xff, 0xa0, 0x07, 0x37, 0x79, 0xe0,
+	0x32, 0x1f, 0x16, 0xa4, 0xce, 0x1c, 0x4a, 0x77, 0x86, 0xe5, 0x87, 0x4a, 0x5f, 0x15, 0xcc, 0xfd,
+	0xf7, 0x13, 0xc6, 0x22, 0xae, 0xb2, 0xb7, 0x52, 0x3d, 0xb5, 0xde, 0x2c, 0x46, 0x96, 0x1d, 0x2d,
+	0x7f, 0x12, 0x16, 0xe9, 0x3e, 0xcd, 0xbc, 0x7f, 0xc6, 0x82, 0x6f, 0x92, 0x09, 0x2b, 0xc8, 0x7d,
+	0x07, 0xed, 0xd4, 0xd5, 0x62, 0x99, 0xbc, 0xbc, 0xeb, 0x8f, 0x79, 0xbf, 0x10, 0x6d, 0x32, 0x64,
+	0x32, 0x83, 0xf1, 0xb2, 0x90, 0xc9, 0x1f, 0xd6, 0xcd, 0x87, 0x05, 0xa9, 0x63, 0x89, 0x03, 0x68,
+	0x26, 0x86, 0x35, 0xb4, 0x24, 0x14, 0x16, 0xc7, 0x43, 0xf3, 0xcb, 0x02, 0x94, 0xc9, 0x14, 0xcf,
+	0x0e, 0x4b, 0xcb, 0x52, 0x7c, 0xc9, 0x7c, 0x67, 0x76, 0x8b, 0x92, 0x27, 0x8d, 0x99, 0x99, 0x38,
+	0x96, 0x19, 0x33, 0x7f, 0xf6, 0x32, 0x1f, 0x16, 0xa4, 0x4e, 0x16, 0xce, 0xf4, 0x3c, 0xb0, 0x2c,
+	0x29, 0x72, 0x47, 0x12, 0xf3, 0x41, 0x31, 0xe2, 0xa4, 0xb8, 0x74, 0x27, 0x5f, 0x26, 0x2e, 0x77,
+	0xb2, 0x30, 0x1f, 0x14, 0x23, 0xd6, 0xe2, 0xbe, 0x81, 0xdf, 0xd5, 0x35, 0xed, 0x79, 0x55, 0xfc,
+	0xaf, 0x80, 0x5f, 0xfe, 0x7f, 0x00, 0x5d, 0xed, 0x24, 0x6c, 0xe6, 0x20, 0x00, 0x00,
 }
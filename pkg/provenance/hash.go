@@ -0,0 +1,127 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/minio/blake2b-simd"
+)
+
+// DefaultHash is the algorithm that chart sums are recorded with unless a
+// Signatory says otherwise.
+const DefaultHash = "sha256"
+
+// MinHashBits is the smallest digest, in bits, that a provenance file may
+// record the sum of a chart with. Charts whose sums were made with a weaker
+// algorithm fail verification.
+var MinHashBits = 256
+
+// Hash is an algorithm that provenance files can record chart sums with.
+type Hash struct {
+	// Name prefixes the sums made with the algorithm, e.g. "sha256".
+	Name string
+	// Bits is the size of the digest.
+	Bits int
+	// New returns a new instance of the algorithm.
+	New func() hash.Hash
+}
+
+var (
+	hashesMu sync.RWMutex
+	hashes   = map[string]Hash{}
+)
+
+func init() {
+	RegisterHash(Hash{Name: "sha256", Bits: 256, New: sha256.New})
+	RegisterHash(Hash{Name: "sha512", Bits: 512, New: sha512.New})
+	RegisterHash(Hash{Name: "blake2b", Bits: 512, New: blake2b.New512})
+}
+
+// RegisterHash makes an algorithm available for signing and verifying
+// charts. It replaces any algorithm registered with the same name.
+func RegisterHash(h Hash) {
+	hashesMu.Lock()
+	defer hashesMu.Unlock()
+	hashes[h.Name] = h
+}
+
+// LookupHash returns the algorithm registered as name.
+func LookupHash(name string) (Hash, error) {
+	hashesMu.RLock()
+	defer hashesMu.RUnlock()
+	h, ok := hashes[name]
+	if !ok {
+		return h, fmt.Errorf("unsupported hash algorithm %q: must be one of %s", name, strings.Join(hashNames(), ", "))
+	}
+	return h, nil
+}
+
+// HashNames returns the names of the registered algorithms, sorted.
+func HashNames() []string {
+	hashesMu.RLock()
+	defer hashesMu.RUnlock()
+	return hashNames()
+}
+
+func hashNames() []string {
+	names := make([]string, 0, len(hashes))
+	for n := range hashes {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DigestWith hashes a reader with the algorithm registered as name, and
+// returns the sum prefixed with the name, e.g. "sha512:HEX".
+func DigestWith(name string, in io.Reader) (string, error) {
+	h, err := LookupHash(name)
+	if err != nil {
+		return "", err
+	}
+	d := h.New()
+	if _, err := io.Copy(d, in); err != nil {
+		return "", err
+	}
+	return name + ":" + hex.EncodeToString(d.Sum(nil)), nil
+}
+
+// acceptedHash returns the algorithm a sum of the form "NAME:HEX" was made
+// with, if it is registered and at least MinHashBits strong.
+func acceptedHash(sum string) (Hash, error) {
+	parts := strings.SplitN(sum, ":", 2)
+	if len(parts) != 2 {
+		return Hash{}, fmt.Errorf("sum %q has no hash algorithm", sum)
+	}
+	h, err := LookupHash(parts[0])
+	if err != nil {
+		return h, err
+	}
+	if h.Bits < MinHashBits {
+		return h, fmt.Errorf("hash algorithm %s is too weak: its %d-bit digest is shorter than the %d bits required", h.Name, h.Bits, MinHashBits)
+	}
+	return h, nil
+}
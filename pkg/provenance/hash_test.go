@@ -0,0 +1,96 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHashNames(t *testing.T) {
+	expect := []string{"blake2b", "sha256", "sha512"}
+	if got := HashNames(); !reflect.DeepEqual(got, expect) {
+		t.Errorf("expected %v, got %v", expect, got)
+	}
+	if _, err := LookupHash("md5"); err == nil {
+		t.Error("expected an unregistered algorithm not to be found")
+	}
+}
+
+func TestSignWithHash(t *testing.T) {
+	archive, err := ioutil.ReadFile(testChartfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, alg := range HashNames() {
+		signer, err := NewFromFiles(testKeyfile, testPubfile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signer.Hash = alg
+		sig, err := signer.ClearSign(testChartfile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(sig, "hashtest-1.2.3.tgz: "+alg+":") {
+			t.Errorf("%s: expected the sum to be recorded with %s:\n%s", alg, alg, sig)
+		}
+
+		ver, err := signer.VerifyArchive("hashtest-1.2.3.tgz", archive, []byte(sig))
+		if err != nil {
+			t.Errorf("%s: %s", alg, err)
+		} else if !strings.HasPrefix(ver.FileHash, alg+":") {
+			t.Errorf("%s: unexpected hash %q", alg, ver.FileHash)
+		}
+		if _, err := signer.VerifyArchive("hashtest-1.2.3.tgz", append(archive, 0), []byte(sig)); err == nil {
+			t.Errorf("%s: expected a modified archive to fail", alg)
+		}
+	}
+
+	signer, err := NewFromFiles(testKeyfile, testPubfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer.Hash = "md5"
+	if _, err := signer.ClearSign(testChartfile); err == nil {
+		t.Error("expected signing with an unregistered algorithm to fail")
+	}
+}
+
+func TestMinHashBits(t *testing.T) {
+	defer func(old int) { MinHashBits = old }(MinHashBits)
+	MinHashBits = 512
+
+	signer, err := NewFromFiles(testKeyfile, testPubfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive, err := ioutil.ReadFile(testChartfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := ioutil.ReadFile(testSigBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := signer.VerifyArchive("hashtest-1.2.3.tgz", archive, sig); err == nil || !strings.Contains(err.Error(), "too weak") {
+		t.Errorf("expected a sha256 sum to be rejected, got %v", err)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"io/ioutil"
 	"os"
@@ -48,7 +49,8 @@ var defaultPGPConfig = packet.Config{
 // Images are of the form:
 //	"IMAGE:TAG": "sha256:SUM"
 // Docker optionally supports sha512, and if this is the case, the hash marker
-// will be 'sha512' instead of 'sha256'.
+// will be 'sha512' instead of 'sha256'. Chart sums may be made with any
+// algorithm registered with RegisterHash.
 type SumCollection struct {
 	Files  map[string]string `json:"files"`
 	Images map[string]string `json:"images,omitempty"`
@@ -76,6 +78,9 @@ type Signatory struct {
 	Entity *openpgp.Entity
 	// The keyring for this instance of Helm. This is used for verification.
 	KeyRing openpgp.EntityList
+	// Hash is the algorithm that ClearSign records the sum of a chart with,
+	// DefaultHash if empty.
+	Hash string
 }
 
 // NewFromFiles constructs a new Signatory from the PGP key in the given filename.
@@ -216,9 +221,13 @@ func (s *Signatory) ClearSign(chartpath string) (string, error) {
 
 	out := bytes.NewBuffer(nil)
 
-	b, err := messageBlock(chartpath)
+	alg := s.Hash
+	if alg == "" {
+		alg = DefaultHash
+	}
+	b, err := messageBlock(chartpath, alg)
 	if err != nil {
-		return "", nil
+		return "", err
 	}
 
 	// Sign the buffer
@@ -242,41 +251,135 @@ func (s *Signatory) Verify(chartpath, sigpath string) (*Verification, error) {
 		}
 	}
 
-	// First verify the signature
-	sig, err := s.decodeSignature(sigpath)
+	archive, err := ioutil.ReadFile(chartpath)
 	if err != nil {
-		return ver, fmt.Errorf("failed to decode signature: %s", err)
+		return ver, err
 	}
-
-	by, err := s.verifySignature(sig)
+	sig, err := ioutil.ReadFile(sigpath)
 	if err != nil {
 		return ver, err
 	}
-	ver.SignedBy = by
+	return s.VerifyArchive(filepath.Base(chartpath), archive, sig)
+}
 
-	// Second, verify the hash of the tarball.
-	sum, err := DigestFile(chartpath)
+// VerifyArchive checks a signature and verifies that it is legit for the chart
+// archive named name, both held in memory.
+func (s *Signatory) VerifyArchive(name string, archive, signature []byte) (*Verification, error) {
+	v, err := s.NewVerifier(name, signature)
 	if err != nil {
-		return ver, err
+		return v.Verification(), err
+	}
+	v.Write(archive)
+	return v.Verify()
+}
+
+// Verifier verifies a chart archive against its provenance file as the
+// archive is written to it, so that a chart can be verified while it
+// downloads without being saved first.
+type Verifier struct {
+	ver  Verification
+	alg  string
+	sum  string
+	hash hash.Hash
+}
+
+// NewVerifier checks the signature of a provenance file and returns a Verifier
+// for the chart archive named name that it covers.
+//
+// The signature is checked right away, so that a bad provenance file fails
+// before any of the archive has been read. So is the algorithm the sum of the
+// archive was made with, which must be registered and at least MinHashBits
+// strong.
+func (s *Signatory) NewVerifier(name string, signature []byte) (*Verifier, error) {
+	v := &Verifier{}
+
+	// First verify the signature
+	sig, _ := clearsign.Decode(signature)
+	if sig == nil {
+		// There was no sig in the file.
+		return v, errors.New("failed to decode signature: signature block not found")
 	}
+
+	by, err := s.verifySignature(sig)
+	if err != nil {
+		return v, err
+	}
+	v.ver.SignedBy = by
+
 	_, sums, err := parseMessageBlock(sig.Plaintext)
 	if err != nil {
-		return ver, err
+		return v, err
+	}
+	sum, ok := sums.Files[name]
+	if !ok {
+		return v, fmt.Errorf("provenance does not contain a SHA for a file named %q", name)
+	}
+	h, err := acceptedHash(sum)
+	if err != nil {
+		return v, fmt.Errorf("cannot verify %s: %s", name, err)
 	}
+	v.alg, v.sum, v.hash = h.Name, sum, h.New()
+	v.ver.FileName = name
+	return v, nil
+}
 
-	sum = "sha256:" + sum
-	basename := filepath.Base(chartpath)
-	if sha, ok := sums.Files[basename]; !ok {
-		return ver, fmt.Errorf("provenance does not contain a SHA for a file named %q", basename)
-	} else if sha != sum {
-		return ver, fmt.Errorf("sha256 sum does not match for %s: %q != %q", basename, sha, sum)
+// Write adds p to the archive being verified. It never fails.
+func (v *Verifier) Write(p []byte) (int, error) {
+	if v.hash == nil {
+		return len(p), nil
 	}
-	ver.FileHash = sum
-	ver.FileName = basename
+	return v.hash.Write(p)
+}
 
+// Verify checks that the archive written so far is the one the provenance
+// file covers.
+func (v *Verifier) Verify() (*Verification, error) {
 	// TODO: when image signing is added, verify that here.
+	if v.hash == nil {
+		return v.Verification(), errors.New("no provenance to verify against")
+	}
+	sum := v.alg + ":" + hex.EncodeToString(v.hash.Sum(nil))
+	if sum != v.sum {
+		return v.Verification(), fmt.Errorf("%s sum does not match for %s: %q != %q", v.alg, v.ver.FileName, v.sum, sum)
+	}
+	v.ver.FileHash = sum
+	return v.Verification(), nil
+}
 
-	return ver, nil
+// Verification returns what has been verified so far.
+func (v *Verifier) Verification() *Verification {
+	ver := v.ver
+	return &ver
+}
+
+// DetachSign returns an ASCII-armored detached signature of data.
+//
+// Unlike ClearSign, the signed data is not embedded in the signature, so it
+// can be published unchanged next to the signature. Repository indexes are
+// signed this way.
+//
+// The Signatory must have a valid Entity.PrivateKey for this to work.
+func (s *Signatory) DetachSign(data []byte) (string, error) {
+	if s.Entity == nil {
+		return "", errors.New("private key not found")
+	} else if s.Entity.PrivateKey == nil {
+		return "", errors.New("provided key is not a private key")
+	}
+
+	out := bytes.NewBuffer(nil)
+	if err := openpgp.ArmoredDetachSign(out, s.Entity, bytes.NewReader(data), &defaultPGPConfig); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// VerifyDetached checks an ASCII-armored detached signature of data against
+// the keyring, and returns the signer.
+func (s *Signatory) VerifyDetached(data, signature []byte) (*openpgp.Entity, error) {
+	if len(s.KeyRing) == 0 {
+		return nil, errors.New("no keyring to verify against")
+	}
+	return openpgp.CheckArmoredDetachedSignature(s.KeyRing, bytes.NewReader(data), bytes.NewReader(signature))
 }
 
 func (s *Signatory) decodeSignature(filename string) (*clearsign.Block, error) {
@@ -303,10 +406,15 @@ func (s *Signatory) verifySignature(block *clearsign.Block) (*openpgp.Entity, er
 	)
 }
 
-func messageBlock(chartpath string) (*bytes.Buffer, error) {
+func messageBlock(chartpath, alg string) (*bytes.Buffer, error) {
 	var b *bytes.Buffer
 	// Checksum the archive
-	chash, err := DigestFile(chartpath)
+	f, err := os.Open(chartpath)
+	if err != nil {
+		return b, err
+	}
+	defer f.Close()
+	chash, err := DigestWith(alg, f)
 	if err != nil {
 		return b, err
 	}
@@ -314,18 +422,19 @@ func messageBlock(chartpath string) (*bytes.Buffer, error) {
 	base := filepath.Base(chartpath)
 	sums := &SumCollection{
 		Files: map[string]string{
-			base: "sha256:" + chash,
+			base: chash,
 		},
 	}
 
-	// Load the archive into memory.
-	chart, err := chartutil.LoadFile(chartpath)
+	// Only the metadata is signed, so there is no need to load the whole
+	// archive into memory.
+	md, err := chartutil.LoadMetadata(chartpath)
 	if err != nil {
 		return b, err
 	}
 
 	// Buffer a hash + checksums YAML file
-	data, err := yaml.Marshal(chart.Metadata)
+	data, err := yaml.Marshal(md)
 	if err != nil {
 		return b, err
 	}
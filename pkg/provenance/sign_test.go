@@ -73,7 +73,7 @@ files:
 `
 
 func TestMessageBlock(t *testing.T) {
-	out, err := messageBlock(testChartfile)
+	out, err := messageBlock(testChartfile, DefaultHash)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -298,6 +298,74 @@ func TestVerify(t *testing.T) {
 	}
 }
 
+func TestVerifyArchive(t *testing.T) {
+	signer, err := NewFromFiles(testKeyfile, testPubfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive, err := ioutil.ReadFile(testChartfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := ioutil.ReadFile(testSigBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ver, err := signer.VerifyArchive("hashtest-1.2.3.tgz", archive, sig); err != nil {
+		t.Errorf("Failed to pass verify. Err: %s", err)
+	} else if ver.SignedBy == nil {
+		t.Error("No SignedBy field")
+	}
+
+	if _, err := signer.VerifyArchive("hashtest-1.2.4.tgz", archive, sig); err == nil {
+		t.Error("Expected an archive missing from the provenance to fail")
+	}
+	if _, err := signer.VerifyArchive("hashtest-1.2.3.tgz", append(archive, 0), sig); err == nil {
+		t.Error("Expected a modified archive to fail")
+	}
+	if _, err := signer.VerifyArchive("hashtest-1.2.3.tgz", archive, []byte("not signed")); err == nil {
+		t.Error("Expected a missing signature to fail")
+	}
+}
+
+func TestDetachSign(t *testing.T) {
+	signer, err := NewFromFiles(testKeyfile, testPubfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []byte("apiVersion: v1\nentries: {}\n")
+	sig, err := signer.DetachSign(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(sig, "-----BEGIN PGP SIGNATURE-----") {
+		t.Errorf("expected an armored signature, got %s", sig)
+	}
+
+	if by, err := signer.VerifyDetached(data, []byte(sig)); err != nil {
+		t.Errorf("Failed to verify. Err: %s", err)
+	} else if _, ok := by.Identities[testKeyName]; !ok {
+		t.Errorf("Expected signature by %q", testKeyName)
+	}
+
+	if _, err := signer.VerifyDetached(append(data, '#'), []byte(sig)); err == nil {
+		t.Error("Expected modified data to fail")
+	}
+	if _, err := signer.VerifyDetached(data, []byte("not signed")); err == nil {
+		t.Error("Expected a missing signature to fail")
+	}
+
+	pub, err := NewFromKeyring(testPubfile, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pub.DetachSign(data); err == nil {
+		t.Error("Expected signing with a public key to fail")
+	}
+}
+
 // readSumFile reads a file containing a sum generated by the UNIX shasum tool.
 func readSumFile(sumfile string) (string, error) {
 	data, err := ioutil.ReadFile(sumfile)
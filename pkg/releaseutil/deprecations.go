@@ -0,0 +1,212 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package releaseutil // import "k8s.io/helm/pkg/releaseutil"
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// The statuses of a resource that uses a deprecated API version.
+const (
+	// APIDeprecated means the API version is still served, but will be removed.
+	APIDeprecated = "deprecated"
+	// APIRemoved means the API version is no longer served.
+	APIRemoved = "removed"
+)
+
+// APIDeprecation records that Kubernetes deprecated an API version for a kind,
+// and the minor release of Kubernetes 1 that stopped serving it.
+type APIDeprecation struct {
+	Kind       string
+	APIVersion string
+	// ReplacedBy is the API version to use instead, if there is one.
+	ReplacedBy   string
+	DeprecatedIn int
+	RemovedIn    int
+}
+
+// APIDeprecations lists the API versions that Kubernetes deprecated and removed.
+var APIDeprecations = []APIDeprecation{
+	{"DaemonSet", "extensions/v1beta1", "apps/v1", 9, 16},
+	{"Deployment", "extensions/v1beta1", "apps/v1", 9, 16},
+	{"ReplicaSet", "extensions/v1beta1", "apps/v1", 9, 16},
+	{"NetworkPolicy", "extensions/v1beta1", "networking.k8s.io/v1", 9, 16},
+	{"PodSecurityPolicy", "extensions/v1beta1", "policy/v1beta1", 10, 16},
+	{"Deployment", "apps/v1beta1", "apps/v1", 9, 16},
+	{"StatefulSet", "apps/v1beta1", "apps/v1", 9, 16},
+	{"DaemonSet", "apps/v1beta2", "apps/v1", 9, 16},
+	{"Deployment", "apps/v1beta2", "apps/v1", 9, 16},
+	{"ReplicaSet", "apps/v1beta2", "apps/v1", 9, 16},
+	{"StatefulSet", "apps/v1beta2", "apps/v1", 9, 16},
+	{"Ingress", "extensions/v1beta1", "networking.k8s.io/v1", 14, 22},
+	{"Ingress", "networking.k8s.io/v1beta1", "networking.k8s.io/v1", 19, 22},
+	{"IngressClass", "networking.k8s.io/v1beta1", "networking.k8s.io/v1", 19, 22},
+	{"CustomResourceDefinition", "apiextensions.k8s.io/v1beta1", "apiextensions.k8s.io/v1", 16, 22},
+	{"MutatingWebhookConfiguration", "admissionregistration.k8s.io/v1beta1", "admissionregistration.k8s.io/v1", 16, 22},
+	{"ValidatingWebhookConfiguration", "admissionregistration.k8s.io/v1beta1", "admissionregistration.k8s.io/v1", 16, 22},
+	{"APIService", "apiregistration.k8s.io/v1beta1", "apiregistration.k8s.io/v1", 19, 22},
+	{"CertificateSigningRequest", "certificates.k8s.io/v1beta1", "certificates.k8s.io/v1", 19, 22},
+	{"Lease", "coordination.k8s.io/v1beta1", "coordination.k8s.io/v1", 19, 22},
+	{"PriorityClass", "scheduling.k8s.io/v1beta1", "scheduling.k8s.io/v1", 14, 22},
+	{"ClusterRole", "rbac.authorization.k8s.io/v1alpha1", "rbac.authorization.k8s.io/v1", 8, 22},
+	{"ClusterRoleBinding", "rbac.authorization.k8s.io/v1alpha1", "rbac.authorization.k8s.io/v1", 8, 22},
+	{"Role", "rbac.authorization.k8s.io/v1alpha1", "rbac.authorization.k8s.io/v1", 8, 22},
+	{"RoleBinding", "rbac.authorization.k8s.io/v1alpha1", "rbac.authorization.k8s.io/v1", 8, 22},
+	{"ClusterRole", "rbac.authorization.k8s.io/v1beta1", "rbac.authorization.k8s.io/v1", 17, 22},
+	{"ClusterRoleBinding", "rbac.authorization.k8s.io/v1beta1", "rbac.authorization.k8s.io/v1", 17, 22},
+	{"Role", "rbac.authorization.k8s.io/v1beta1", "rbac.authorization.k8s.io/v1", 17, 22},
+	{"RoleBinding", "rbac.authorization.k8s.io/v1beta1", "rbac.authorization.k8s.io/v1", 17, 22},
+	{"CronJob", "batch/v1beta1", "batch/v1", 21, 25},
+	{"PodDisruptionBudget", "policy/v1beta1", "policy/v1", 21, 25},
+	{"PodSecurityPolicy", "policy/v1beta1", "", 21, 25},
+	{"HorizontalPodAutoscaler", "autoscaling/v2beta1", "autoscaling/v2", 22, 25},
+	{"HorizontalPodAutoscaler", "autoscaling/v2beta2", "autoscaling/v2", 23, 26},
+}
+
+// FindAPIDeprecation returns the deprecation of the API version of a kind, or
+// nil if it is not deprecated.
+func FindAPIDeprecation(apiVersion, kind string) *APIDeprecation {
+	for i, d := range APIDeprecations {
+		if d.APIVersion == apiVersion && d.Kind == kind {
+			return &APIDeprecations[i]
+		}
+	}
+	return nil
+}
+
+// Status returns the status of the API version in the given minor release of
+// Kubernetes 1: APIRemoved, APIDeprecated, or the empty string if the API
+// version is not deprecated yet.
+func (d *APIDeprecation) Status(minor int) string {
+	switch {
+	case minor >= d.RemovedIn:
+		return APIRemoved
+	case minor >= d.DeprecatedIn:
+		return APIDeprecated
+	}
+	return ""
+}
+
+// DeprecatedResource is a resource of a manifest that uses a deprecated API version.
+type DeprecatedResource struct {
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	APIVersion string `json:"apiVersion"`
+	// Source is the template the resource was rendered from, if known.
+	Source string `json:"source,omitempty"`
+	// Status is APIDeprecated or APIRemoved.
+	Status       string `json:"status"`
+	DeprecatedIn string `json:"deprecatedIn"`
+	RemovedIn    string `json:"removedIn"`
+	ReplacedBy   string `json:"replacedBy,omitempty"`
+}
+
+type resourceHead struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Metadata   struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+}
+
+// ManifestDeprecations returns the resources of a YAML stream of manifests
+// that use API versions deprecated or removed in the given minor release of
+// Kubernetes 1, in the order they appear in the stream.
+//
+// Documents annotated with a "# Source:" comment, as Tiller stores them, are
+// reported with the template they were rendered from.
+func ManifestDeprecations(manifest string, minor int) ([]DeprecatedResource, error) {
+	docs, err := SplitManifest(manifest)
+	if err != nil {
+		return nil, err
+	}
+	res := []DeprecatedResource{}
+	for _, doc := range docs {
+		d := FindAPIDeprecation(doc.APIVersion, doc.Kind)
+		if d == nil || d.Status(minor) == "" {
+			continue
+		}
+		res = append(res, DeprecatedResource{
+			Kind:         doc.Kind,
+			Name:         doc.Name,
+			APIVersion:   doc.APIVersion,
+			Source:       doc.Source,
+			Status:       d.Status(minor),
+			DeprecatedIn: fmt.Sprintf("1.%d", d.DeprecatedIn),
+			RemovedIn:    fmt.Sprintf("1.%d", d.RemovedIn),
+			ReplacedBy:   d.ReplacedBy,
+		})
+	}
+	return res, nil
+}
+
+// manifestSource returns the template named by the "# Source:" comment of a
+// manifest document, or the empty string if there is none.
+func manifestSource(doc string) string {
+	for _, line := range strings.Split(doc, "\n") {
+		if strings.HasPrefix(line, "# Source: ") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "# Source: "))
+		}
+		if line != "" && line != "---" && !strings.HasPrefix(line, "#") {
+			break
+		}
+	}
+	return ""
+}
+
+// MigrateManifest rewrites the resources of a YAML stream of manifests that use
+// API versions the cluster no longer serves to the API versions that replaced
+// them, the way they would be rendered today. served reports whether the
+// cluster serves an API version.
+//
+// Only the apiVersion of a resource is changed, and only if its replacement is
+// served. The rewritten manifest is returned, along with the resources that
+// were migrated. If no resource is migrated, the manifest is returned as is.
+func MigrateManifest(manifest string, served func(apiVersion string) bool) (string, []DeprecatedResource, error) {
+	docs, err := SplitManifest(manifest)
+	if err != nil {
+		return "", nil, err
+	}
+	migrated := []DeprecatedResource{}
+	var b bytes.Buffer
+	for _, doc := range docs {
+		content := doc.Content
+		d := FindAPIDeprecation(doc.APIVersion, doc.Kind)
+		if d != nil && d.ReplacedBy != "" && !served(d.APIVersion) && served(d.ReplacedBy) {
+			line := regexp.MustCompile(`(?m)^apiVersion:[ \t]*["']?` + regexp.QuoteMeta(d.APIVersion) + `["']?[ \t]*$`)
+			content = line.ReplaceAllLiteralString(content, "apiVersion: "+d.ReplacedBy)
+			migrated = append(migrated, DeprecatedResource{
+				Kind:         doc.Kind,
+				Name:         doc.Name,
+				APIVersion:   doc.APIVersion,
+				Source:       doc.Source,
+				Status:       APIRemoved,
+				DeprecatedIn: fmt.Sprintf("1.%d", d.DeprecatedIn),
+				RemovedIn:    fmt.Sprintf("1.%d", d.RemovedIn),
+				ReplacedBy:   d.ReplacedBy,
+			})
+		}
+		b.WriteString("---\n" + content)
+	}
+	if len(migrated) == 0 {
+		return manifest, migrated, nil
+	}
+	return b.String(), migrated, nil
+}
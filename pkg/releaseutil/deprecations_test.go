@@ -0,0 +1,139 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package releaseutil // import "k8s.io/helm/pkg/releaseutil"
+
+import (
+	"reflect"
+	"testing"
+)
+
+const deprecationsManifest = `---
+# Source: mychart/templates/deployment.yaml
+apiVersion: extensions/v1beta1
+kind: Deployment
+metadata:
+  name: web
+---
+# Source: mychart/templates/ingress.yaml
+apiVersion: extensions/v1beta1
+kind: Ingress
+metadata:
+  name: web
+---
+# Source: mychart/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`
+
+func TestManifestDeprecations(t *testing.T) {
+	tests := []struct {
+		minor    int
+		expected []DeprecatedResource
+	}{
+		{8, []DeprecatedResource{}},
+		{9, []DeprecatedResource{
+			{Kind: "Deployment", Name: "web", APIVersion: "extensions/v1beta1", Source: "mychart/templates/deployment.yaml", Status: APIDeprecated, DeprecatedIn: "1.9", RemovedIn: "1.16", ReplacedBy: "apps/v1"},
+		}},
+		{16, []DeprecatedResource{
+			{Kind: "Deployment", Name: "web", APIVersion: "extensions/v1beta1", Source: "mychart/templates/deployment.yaml", Status: APIRemoved, DeprecatedIn: "1.9", RemovedIn: "1.16", ReplacedBy: "apps/v1"},
+			{Kind: "Ingress", Name: "web", APIVersion: "extensions/v1beta1", Source: "mychart/templates/ingress.yaml", Status: APIDeprecated, DeprecatedIn: "1.14", RemovedIn: "1.22", ReplacedBy: "networking.k8s.io/v1"},
+		}},
+	}
+	for _, tt := range tests {
+		res, err := ManifestDeprecations(deprecationsManifest, tt.minor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(res, tt.expected) {
+			t.Errorf("1.%d: expected %+v, got %+v", tt.minor, tt.expected, res)
+		}
+	}
+
+	if _, err := ManifestDeprecations("kind: [", 16); err == nil {
+		t.Error("expected an error for an invalid manifest")
+	}
+}
+
+func TestFindAPIDeprecation(t *testing.T) {
+	if d := FindAPIDeprecation("apps/v1", "Deployment"); d != nil {
+		t.Errorf("expected apps/v1 not to be deprecated, got %+v", d)
+	}
+	d := FindAPIDeprecation("policy/v1beta1", "PodSecurityPolicy")
+	if d == nil || d.ReplacedBy != "" || d.Status(25) != APIRemoved {
+		t.Errorf("unexpected deprecation %+v", d)
+	}
+}
+
+func TestMigrateManifest(t *testing.T) {
+	served := func(apiVersion string) bool {
+		return apiVersion == "v1" || apiVersion == "apps/v1" || apiVersion == "extensions/v1beta1"
+	}
+	manifest := `---
+# Source: mychart/templates/deployment.yaml
+apiVersion: "apps/v1beta2"
+kind: Deployment
+metadata:
+  name: web
+  annotations:
+    example.com/apiVersion: apps/v1beta2
+---
+# Source: mychart/templates/cronjob.yaml
+apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: backup
+---
+# Source: mychart/templates/ingress.yaml
+apiVersion: extensions/v1beta1
+kind: Ingress
+metadata:
+  name: web
+`
+	expected := `---
+# Source: mychart/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  annotations:
+    example.com/apiVersion: apps/v1beta2
+---
+# Source: mychart/templates/cronjob.yaml
+apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: backup
+---
+# Source: mychart/templates/ingress.yaml
+apiVersion: extensions/v1beta1
+kind: Ingress
+metadata:
+  name: web
+`
+	out, migrated, err := MigrateManifest(manifest, served)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, out)
+	}
+	if len(migrated) != 1 || migrated[0].Kind != "Deployment" || migrated[0].ReplacedBy != "apps/v1" || migrated[0].Source != "mychart/templates/deployment.yaml" {
+		t.Errorf("unexpected migrated resources %+v", migrated)
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package releaseutil
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// hookAnnotation marks a resource as a hook, which Tiller creates on its own
+// rather than as part of the release.
+const hookAnnotation = "helm.sh/hook"
+
+// DuplicateResource is a resource that more than one document of a manifest
+// defines. Whichever is applied last would silently win.
+type DuplicateResource struct {
+	// Group is the API group of the resource, empty for the core group.
+	Group     string
+	Kind      string
+	Namespace string
+	Name      string
+	// Sources are the templates of the documents that define the resource,
+	// in the order they appear in the manifest.
+	Sources []string
+}
+
+func (d DuplicateResource) String() string {
+	kind := d.Kind
+	if d.Group != "" {
+		kind += "." + d.Group
+	}
+	name := d.Name
+	if d.Namespace != "" {
+		name = d.Namespace + "/" + name
+	}
+	return fmt.Sprintf("%s %s", kind, name)
+}
+
+type duplicateHead struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Metadata   struct {
+		Name        string            `json:"name"`
+		Namespace   string            `json:"namespace"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"metadata"`
+}
+
+// FindDuplicates returns the resources that more than one document of a YAML
+// stream of manifests defines, identified by their API group, kind, namespace
+// and name, in the order they first appear. Resources without a namespace are
+// taken to be in namespace. Hooks are not considered, as chart authors often
+// give the hooks of different events the same name.
+//
+// A document without a "# Source:" comment is taken to come from the template
+// of the document before it, as helm template only annotates the first
+// document of each template.
+func FindDuplicates(manifest, namespace string) ([]DuplicateResource, error) {
+	var (
+		order  []string
+		found  = map[string]*DuplicateResource{}
+		source string
+	)
+	docs, err := SplitManifest(manifest)
+	if err != nil {
+		return nil, err
+	}
+	for _, doc := range docs {
+		if doc.Source != "" {
+			source = doc.Source
+		}
+		var h duplicateHead
+		if err := yaml.Unmarshal([]byte(doc.Content), &h); err != nil {
+			return nil, fmt.Errorf("could not parse manifest: %s", err)
+		}
+		if h.Kind == "" || h.Metadata.Name == "" {
+			continue
+		}
+		if _, ok := h.Metadata.Annotations[hookAnnotation]; ok {
+			continue
+		}
+
+		d := DuplicateResource{Kind: h.Kind, Namespace: h.Metadata.Namespace, Name: h.Metadata.Name}
+		if i := strings.Index(h.APIVersion, "/"); i >= 0 {
+			d.Group = h.APIVersion[:i]
+		}
+		if d.Namespace == "" {
+			d.Namespace = namespace
+		}
+		key := d.Group + "/" + d.Kind + "/" + d.Namespace + "/" + d.Name
+		if found[key] == nil {
+			found[key] = &d
+			order = append(order, key)
+		}
+		found[key].Sources = append(found[key].Sources, source)
+	}
+
+	res := []DuplicateResource{}
+	for _, key := range order {
+		if d := found[key]; len(d.Sources) > 1 {
+			res = append(res, *d)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package releaseutil // import "k8s.io/helm/pkg/releaseutil"
+
+import (
+	"reflect"
+	"testing"
+)
+
+const duplicatesManifest = `---
+# Source: mychart/templates/web.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+# Source: mychart/templates/legacy.yaml
+apiVersion: extensions/v1beta1
+kind: Deployment
+metadata:
+  name: web
+---
+apiVersion: apps/v1beta2
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+---
+# Source: mychart/templates/other.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: other
+---
+# Source: mychart/templates/hooks.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  annotations:
+    "helm.sh/hook": pre-install
+`
+
+func TestFindDuplicates(t *testing.T) {
+	dups, err := FindDuplicates(duplicatesManifest, "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []DuplicateResource{{
+		Group:     "apps",
+		Kind:      "Deployment",
+		Namespace: "default",
+		Name:      "web",
+		Sources:   []string{"mychart/templates/web.yaml", "mychart/templates/legacy.yaml"},
+	}}
+	if !reflect.DeepEqual(dups, expected) {
+		t.Errorf("expected %+v, got %+v", expected, dups)
+	}
+	if s := dups[0].String(); s != "Deployment.apps default/web" {
+		t.Errorf("unexpected string %q", s)
+	}
+
+	dups, err = FindDuplicates(duplicatesManifest, "other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dups) != 1 || dups[0].Kind != "Service" || dups[0].Namespace != "other" {
+		t.Errorf("expected the services to be duplicates in namespace other, got %+v", dups)
+	}
+
+	if _, err := FindDuplicates("kind: [Secret", ""); err == nil {
+		t.Error("expected an error for invalid YAML")
+	}
+}
@@ -16,7 +16,11 @@ limitations under the License.
 
 package releaseutil // import "k8s.io/helm/pkg/releaseutil"
 
-import rspb "k8s.io/helm/pkg/proto/hapi/release"
+import (
+	"strings"
+
+	rspb "k8s.io/helm/pkg/proto/hapi/release"
+)
 
 // FilterFunc returns true if the release object satisfies
 // the predicate of the underlying filter func.
@@ -76,3 +80,24 @@ func StatusFilter(status rspb.Status_Code) FilterFunc {
 		return rls.GetInfo().GetStatus().Code == status
 	})
 }
+
+// ResourceFilter filters a set of releases by whether their manifest or
+// hooks contain a resource of the given kind and name. Kinds are compared
+// case-insensitively.
+func ResourceFilter(kind, name string) FilterFunc {
+	return FilterFunc(func(rls *rspb.Release) bool {
+		for _, h := range rls.Hooks {
+			if strings.EqualFold(h.Kind, kind) && h.Name == name {
+				return true
+			}
+		}
+		// A manifest that cannot be parsed names no resources.
+		docs, _ := SplitManifest(rls.Manifest)
+		for _, doc := range docs {
+			if strings.EqualFold(doc.Kind, kind) && doc.Name == name {
+				return true
+			}
+		}
+		return false
+	})
+}
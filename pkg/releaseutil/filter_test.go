@@ -17,8 +17,10 @@ limitations under the License.
 package releaseutil // import "k8s.io/helm/pkg/releaseutil"
 
 import (
-	rspb "k8s.io/helm/pkg/proto/hapi/release"
+	"strings"
 	"testing"
+
+	rspb "k8s.io/helm/pkg/proto/hapi/release"
 )
 
 func TestFilterAny(t *testing.T) {
@@ -56,3 +58,29 @@ func TestFilterAll(t *testing.T) {
 		t.Fatal("got release with status DELTED")
 	}
 }
+
+func TestResourceFilter(t *testing.T) {
+	rels := []*rspb.Release{
+		{Name: "web", Manifest: "---\n# Source: web/templates/svc.yaml\nkind: Service\nmetadata:\n  name: web\n---\nkind: Deployment\nmetadata:\n  name: web\n"},
+		{Name: "db", Manifest: "kind: Service\nmetadata:\n  name: db\n", Hooks: []*rspb.Hook{{Kind: "Job", Name: "db-migrate"}}},
+	}
+
+	tests := []struct {
+		kind, name string
+		expect     []string
+	}{
+		{"Deployment", "web", []string{"web"}},
+		{"service", "db", []string{"db"}},
+		{"Job", "db-migrate", []string{"db"}},
+		{"Service", "cache", nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, r := range ResourceFilter(tt.kind, tt.name).Filter(rels) {
+			got = append(got, r.Name)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.expect, ",") {
+			t.Errorf("%s/%s: expected %v, got %v", tt.kind, tt.name, tt.expect, got)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package releaseutil // import "k8s.io/helm/pkg/releaseutil"
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ghodss/yaml"
+)
+
+// initContainersAnnotation holds the init containers of a pod template
+// before they became part of the pod spec.
+const initContainersAnnotation = "pod.beta.kubernetes.io/init-containers"
+
+// ImageRef is a container image referenced by a manifest.
+type ImageRef struct {
+	// Image is the image reference, e.g. "nginx:1.11".
+	Image string `json:"image"`
+	// Kind and Name identify the resource that references the image.
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// Container is the name of the container that runs the image.
+	Container string `json:"container"`
+}
+
+type container struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
+}
+
+type podSpec struct {
+	Containers     []container `json:"containers"`
+	InitContainers []container `json:"initContainers"`
+}
+
+type podTemplate struct {
+	Metadata struct {
+		Annotations map[string]string `json:"annotations"`
+	} `json:"metadata"`
+	Spec podSpec `json:"spec"`
+}
+
+type workload struct {
+	Kind     string `json:"kind"`
+	Metadata struct {
+		Name        string            `json:"name"`
+		Annotations map[string]string `json:"annotations"`
+	} `json:"metadata"`
+	Spec struct {
+		podSpec
+		Template    podTemplate `json:"template"`
+		JobTemplate struct {
+			Spec struct {
+				Template podTemplate `json:"template"`
+			} `json:"spec"`
+		} `json:"jobTemplate"`
+	} `json:"spec"`
+}
+
+// ManifestImages returns the container images referenced by the pods and pod
+// templates in a YAML stream of manifests, including init containers.
+//
+// Pods, workloads with a pod template (Deployments, DaemonSets, StatefulSets,
+// Jobs, ...) and CronJobs are searched. Other kinds are ignored.
+func ManifestImages(manifest string) ([]ImageRef, error) {
+	docs, err := SplitManifest(manifest)
+	if err != nil {
+		return nil, err
+	}
+	refs := []ImageRef{}
+	for _, doc := range docs {
+		var w workload
+		if err := yaml.Unmarshal([]byte(doc.Content), &w); err != nil {
+			return nil, fmt.Errorf("could not parse manifest: %s", err)
+		}
+
+		add := func(cs []container) {
+			for _, c := range cs {
+				if c.Image != "" {
+					refs = append(refs, ImageRef{Image: c.Image, Kind: w.Kind, Name: w.Metadata.Name, Container: c.Name})
+				}
+			}
+		}
+		addPod := func(annotations map[string]string, spec podSpec) {
+			add(spec.InitContainers)
+			if a, ok := annotations[initContainersAnnotation]; ok {
+				var cs []container
+				if err := json.Unmarshal([]byte(a), &cs); err == nil {
+					add(cs)
+				}
+			}
+			add(spec.Containers)
+		}
+
+		switch w.Kind {
+		case "Pod":
+			addPod(w.Metadata.Annotations, w.Spec.podSpec)
+		case "CronJob", "ScheduledJob":
+			t := w.Spec.JobTemplate.Spec.Template
+			addPod(t.Metadata.Annotations, t.Spec)
+		default:
+			t := w.Spec.Template
+			addPod(t.Metadata.Annotations, t.Spec)
+		}
+	}
+	return refs, nil
+}
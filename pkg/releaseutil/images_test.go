@@ -0,0 +1,93 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package releaseutil // import "k8s.io/helm/pkg/releaseutil"
+
+import (
+	"reflect"
+	"testing"
+)
+
+const imagesManifest = `---
+# Source: mychart/templates/pod.yaml
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+spec:
+  containers:
+  - name: nginx
+    image: nginx:1.11
+---
+# Source: mychart/templates/deployment.yaml
+apiVersion: extensions/v1beta1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  template:
+    metadata:
+      annotations:
+        pod.beta.kubernetes.io/init-containers: '[{"name":"migrate","image":"migrator:2"}]'
+    spec:
+      initContainers:
+      - name: setup
+        image: busybox
+      containers:
+      - name: api
+        image: example/api:0.3
+---
+# Source: mychart/templates/cronjob.yaml
+apiVersion: batch/v2alpha1
+kind: CronJob
+metadata:
+  name: backup
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: backup
+            image: example/backup@sha256:abc
+---
+# Source: mychart/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`
+
+func TestManifestImages(t *testing.T) {
+	refs, err := ManifestImages(imagesManifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := []ImageRef{
+		{Image: "nginx:1.11", Kind: "Pod", Name: "web", Container: "nginx"},
+		{Image: "busybox", Kind: "Deployment", Name: "api", Container: "setup"},
+		{Image: "migrator:2", Kind: "Deployment", Name: "api", Container: "migrate"},
+		{Image: "example/api:0.3", Kind: "Deployment", Name: "api", Container: "api"},
+		{Image: "example/backup@sha256:abc", Kind: "CronJob", Name: "backup", Container: "backup"},
+	}
+	if !reflect.DeepEqual(refs, expect) {
+		t.Errorf("expected %v, got %v", expect, refs)
+	}
+
+	if _, err := ManifestImages("kind: [\n"); err == nil {
+		t.Error("expected an error for an invalid manifest")
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package releaseutil
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// Document is one resource of a YAML stream of manifests.
+type Document struct {
+	// Source is the template the resource was rendered from, if known.
+	Source     string
+	APIVersion string
+	Kind       string
+	Name       string
+	// Content is the YAML of the resource, including its "# Source:"
+	// comment, without the "---" separator.
+	Content string
+}
+
+// SplitManifest splits a YAML stream of manifests into its resources, in the
+// order they appear in the stream. Empty documents are skipped.
+func SplitManifest(manifest string) ([]Document, error) {
+	docs := []Document{}
+	for _, doc := range strings.Split(manifest, "\n---") {
+		doc = strings.TrimPrefix(doc, "---")
+		if strings.TrimSpace(doc) == "" {
+			continue
+		}
+		var h resourceHead
+		if err := yaml.Unmarshal([]byte(doc), &h); err != nil {
+			return nil, fmt.Errorf("could not parse manifest: %s", err)
+		}
+		docs = append(docs, Document{
+			Source:     manifestSource(doc),
+			APIVersion: h.APIVersion,
+			Kind:       h.Kind,
+			Name:       h.Metadata.Name,
+			Content:    strings.Trim(doc, "\n") + "\n",
+		})
+	}
+	return docs, nil
+}
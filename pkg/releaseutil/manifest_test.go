@@ -0,0 +1,43 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package releaseutil // import "k8s.io/helm/pkg/releaseutil"
+
+import (
+	"testing"
+)
+
+func TestSplitManifest(t *testing.T) {
+	docs, err := SplitManifest(deprecationsManifest + "---\n\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 3 {
+		t.Fatalf("expected 3 documents, got %d", len(docs))
+	}
+	d := docs[1]
+	if d.Source != "mychart/templates/ingress.yaml" || d.APIVersion != "extensions/v1beta1" || d.Kind != "Ingress" || d.Name != "web" {
+		t.Errorf("unexpected document %+v", d)
+	}
+	expected := "# Source: mychart/templates/ingress.yaml\napiVersion: extensions/v1beta1\nkind: Ingress\nmetadata:\n  name: web\n"
+	if d.Content != expected {
+		t.Errorf("expected content %q, got %q", expected, d.Content)
+	}
+
+	if _, err := SplitManifest("kind: [Secret"); err == nil {
+		t.Error("expected an error for invalid YAML")
+	}
+}
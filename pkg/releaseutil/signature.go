@@ -0,0 +1,150 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package releaseutil // import "k8s.io/helm/pkg/releaseutil"
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/golang/protobuf/proto"
+
+	rspb "k8s.io/helm/pkg/proto/hapi/release"
+	"k8s.io/helm/pkg/provenance"
+)
+
+// ErrUnsigned indicates that a release record carries no signature.
+var ErrUnsigned = errors.New("record is not signed")
+
+// Digest returns the SHA-256 digest of a release record, as "sha256:HEX".
+//
+// The digest covers everything but the signature, the status and the time
+// of deletion, which Tiller changes as later revisions are deployed.
+func Digest(rls *rspb.Release) (string, error) {
+	cp := *rls
+	cp.Signature = nil
+	if rls.Info != nil {
+		cp.Info = &rspb.Info{
+			FirstDeployed: rls.Info.FirstDeployed,
+			LastDeployed:  rls.Info.LastDeployed,
+			Metadata:      rls.Info.Metadata,
+		}
+	}
+	b, err := proto.Marshal(&cp)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// statement is the message the signature of a record signs.
+func statement(rls *rspb.Release, digest, previous string) []byte {
+	s := fmt.Sprintf("release: %s\nrevision: %d\ndigest: %s\n", rls.Name, rls.Version, digest)
+	if previous != "" {
+		s += fmt.Sprintf("previous: %s\n", previous)
+	}
+	return []byte(s)
+}
+
+// Sign signs rls with the private key of signer, linking it to prev, the
+// record of the previous revision. prev may be nil.
+func Sign(signer *provenance.Signatory, rls, prev *rspb.Release) error {
+	digest, err := Digest(rls)
+	if err != nil {
+		return err
+	}
+	previous := ""
+	if prev != nil {
+		if previous, err = Digest(prev); err != nil {
+			return err
+		}
+	}
+	sig, err := signer.DetachSign(statement(rls, digest, previous))
+	if err != nil {
+		return fmt.Errorf("cannot sign release %s (v%d): %s", rls.Name, rls.Version, err)
+	}
+	rls.Signature = &rspb.Signature{Previous: previous, Signature: sig}
+	return nil
+}
+
+// SignatureCheck is the result of verifying the signature of one revision.
+type SignatureCheck struct {
+	Revision int32
+	// SignedBy is the identity of the key that signed the record.
+	SignedBy string
+	// Err is why the record could not be verified, if it could not.
+	Err error
+}
+
+// VerifyHistory verifies the signatures of the records of a release against
+// the keyring of signer, and checks that each record links to the one of the
+// previous revision. The checks are returned oldest revision first.
+//
+// The link of the oldest record in rls cannot be checked, since the record
+// it links to is not known.
+func VerifyHistory(signer *provenance.Signatory, rls []*rspb.Release) []SignatureCheck {
+	sorted := make([]*rspb.Release, len(rls))
+	copy(sorted, rls)
+	SortByRevision(sorted)
+
+	checks := make([]SignatureCheck, len(sorted))
+	for i, r := range sorted {
+		checks[i] = SignatureCheck{Revision: r.Version}
+		var prev *rspb.Release
+		if i > 0 {
+			prev = sorted[i-1]
+		}
+		checks[i].SignedBy, checks[i].Err = verifyRecord(signer, r, prev, i == 0)
+	}
+	return checks
+}
+
+func verifyRecord(signer *provenance.Signatory, r, prev *rspb.Release, oldest bool) (string, error) {
+	if r.Signature == nil || r.Signature.Signature == "" {
+		return "", ErrUnsigned
+	}
+	digest, err := Digest(r)
+	if err != nil {
+		return "", err
+	}
+	by, err := signer.VerifyDetached(statement(r, digest, r.Signature.Previous), []byte(r.Signature.Signature))
+	if err != nil {
+		return "", fmt.Errorf("signature does not match the record: %s", err)
+	}
+	name := ""
+	for n := range by.Identities {
+		name = n
+		break
+	}
+
+	if oldest || r.Version <= 1 {
+		return name, nil
+	}
+	if prev.Version != r.Version-1 {
+		return name, fmt.Errorf("the record of revision %d is missing", r.Version-1)
+	}
+	previous, err := Digest(prev)
+	if err != nil {
+		return name, err
+	}
+	if previous != r.Signature.Previous {
+		return name, fmt.Errorf("the record of revision %d is not the one this revision followed", prev.Version)
+	}
+	return name, nil
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package releaseutil // import "k8s.io/helm/pkg/releaseutil"
+
+import (
+	"strings"
+	"testing"
+
+	rspb "k8s.io/helm/pkg/proto/hapi/release"
+	"k8s.io/helm/pkg/provenance"
+)
+
+func signedHistory(t *testing.T, n int) (*provenance.Signatory, []*rspb.Release) {
+	signer, err := provenance.NewFromFiles("../provenance/testdata/helm-test-key.secret", "../provenance/testdata/helm-test-key.pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rls []*rspb.Release
+	var prev *rspb.Release
+	for i := 1; i <= n; i++ {
+		r := &rspb.Release{
+			Name:     "angry-panda",
+			Version:  int32(i),
+			Manifest: "kind: ConfigMap",
+			Info:     &rspb.Info{Status: &rspb.Status{Code: rspb.Status_DEPLOYED}},
+		}
+		if err := Sign(signer, r, prev); err != nil {
+			t.Fatal(err)
+		}
+		rls = append(rls, r)
+		prev = r
+	}
+	return signer, rls
+}
+
+func checkErrors(checks []SignatureCheck) []string {
+	res := []string{}
+	for _, c := range checks {
+		if c.Err != nil {
+			res = append(res, c.Err.Error())
+		} else {
+			res = append(res, "")
+		}
+	}
+	return res
+}
+
+func TestVerifyHistory(t *testing.T) {
+	signer, rls := signedHistory(t, 3)
+
+	// Status changes do not invalidate a record.
+	rls[0].Info.Status.Code = rspb.Status_SUPERSEDED
+
+	// The order of the records does not matter.
+	checks := VerifyHistory(signer, []*rspb.Release{rls[2], rls[0], rls[1]})
+	if len(checks) != 3 {
+		t.Fatalf("expected 3 checks, got %d", len(checks))
+	}
+	for i, c := range checks {
+		if c.Revision != int32(i+1) {
+			t.Errorf("expected revision %d, got %d", i+1, c.Revision)
+		}
+		if c.Err != nil {
+			t.Errorf("revision %d: %s", c.Revision, c.Err)
+		}
+		if !strings.Contains(c.SignedBy, "helm-test") {
+			t.Errorf("expected the record to be signed by the test key, got %q", c.SignedBy)
+		}
+	}
+}
+
+func TestVerifyHistoryTampered(t *testing.T) {
+	signer, rls := signedHistory(t, 3)
+	rls[1].Manifest = "kind: Secret"
+
+	errs := checkErrors(VerifyHistory(signer, rls))
+	if errs[0] != "" {
+		t.Errorf("expected revision 1 to verify, got %q", errs[0])
+	}
+	if !strings.Contains(errs[1], "signature does not match the record") {
+		t.Errorf("expected revision 2 to be rejected, got %q", errs[1])
+	}
+	if !strings.Contains(errs[2], "revision 2 is not the one this revision followed") {
+		t.Errorf("expected the link of revision 3 to be broken, got %q", errs[2])
+	}
+}
+
+func TestVerifyHistoryMissingRecord(t *testing.T) {
+	signer, rls := signedHistory(t, 3)
+
+	errs := checkErrors(VerifyHistory(signer, []*rspb.Release{rls[0], rls[2]}))
+	if errs[0] != "" {
+		t.Errorf("expected revision 1 to verify, got %q", errs[0])
+	}
+	if errs[1] != "the record of revision 2 is missing" {
+		t.Errorf("expected revision 2 to be reported missing, got %q", errs[1])
+	}
+
+	// Only the records that are there can be checked.
+	if errs := checkErrors(VerifyHistory(signer, rls[1:])); errs[0] != "" || errs[1] != "" {
+		t.Errorf("expected revisions 2 and 3 to verify, got %q", errs)
+	}
+}
+
+func TestVerifyHistoryUnsigned(t *testing.T) {
+	signer, rls := signedHistory(t, 2)
+	rls[1].Signature = nil
+
+	checks := VerifyHistory(signer, rls)
+	if checks[1].Err != ErrUnsigned {
+		t.Errorf("expected %q, got %v", ErrUnsigned, checks[1].Err)
+	}
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ClientOptions tune the HTTP client that downloads indexes, charts and
+// other repository content.
+type ClientOptions struct {
+	// MaxConnsPerHost limits the connections open to one host at a time, 0
+	// for no limit.
+	MaxConnsPerHost int
+	// MaxIdleConnsPerHost is the number of idle connections to a host kept
+	// open for reuse. It is capped by MaxConnsPerHost.
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout closes connections that have been idle this long.
+	IdleConnTimeout time.Duration
+	// DNSCacheTTL, if positive, is how long the addresses that a host name
+	// resolves to are remembered.
+	DNSCacheTTL time.Duration
+}
+
+// DefaultClientOptions are the options the shared client is created with.
+// They have no effect once Client has been called.
+var DefaultClientOptions = ClientOptions{
+	MaxIdleConnsPerHost: 16,
+	IdleConnTimeout:     90 * time.Second,
+	DNSCacheTTL:         time.Minute,
+}
+
+var (
+	clientOnce   sync.Once
+	sharedClient *http.Client
+)
+
+// Client returns the HTTP client shared by Get, registries and the chart
+// downloader, so that they all draw on one pool of connections. It is created
+// with DefaultClientOptions the first time it is called.
+func Client() *http.Client {
+	clientOnce.Do(func() {
+		sharedClient = NewClient(DefaultClientOptions)
+	})
+	return sharedClient
+}
+
+// NewClient returns an HTTP client that uses HTTP/2 with the servers that
+// support it, keeps connections alive for reuse as opts say, and caches DNS
+// lookups. Proxies are taken from the environment, as for
+// http.DefaultClient.
+func NewClient(opts ClientOptions) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	dial := dialer.DialContext
+	if opts.DNSCacheTTL > 0 {
+		c := &dnsCache{ttl: opts.DNSCacheTTL, lookup: net.DefaultResolver.LookupHost, entries: map[string]dnsEntry{}}
+		dial = c.dialer(dialer)
+	}
+
+	idle := opts.MaxIdleConnsPerHost
+	if opts.MaxConnsPerHost > 0 && idle > opts.MaxConnsPerHost {
+		idle = opts.MaxConnsPerHost
+	}
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:                 http.ProxyFromEnvironment,
+			DialContext:           dial,
+			ForceAttemptHTTP2:     true,
+			MaxIdleConns:          100,
+			MaxIdleConnsPerHost:   idle,
+			MaxConnsPerHost:       opts.MaxConnsPerHost,
+			IdleConnTimeout:       opts.IdleConnTimeout,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ExpectContinueTimeout: time.Second,
+		},
+	}
+}
+
+// dnsCache remembers the addresses host names resolve to, so that fetching
+// many files from one host does not look it up again for every connection.
+type dnsCache struct {
+	ttl    time.Duration
+	lookup func(ctx context.Context, host string) ([]string, error)
+
+	mu      sync.Mutex
+	entries map[string]dnsEntry
+}
+
+type dnsEntry struct {
+	addrs   []string
+	expires time.Time
+}
+
+func (c *dnsCache) addrs(ctx context.Context, host string) ([]string, error) {
+	c.mu.Lock()
+	e, ok := c.entries[host]
+	c.mu.Unlock()
+	if ok && time.Now().Before(e.expires) {
+		return e.addrs, nil
+	}
+
+	addrs, err := c.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.entries[host] = dnsEntry{addrs: addrs, expires: time.Now().Add(c.ttl)}
+	c.mu.Unlock()
+	return addrs, nil
+}
+
+func (c *dnsCache) forget(host string) {
+	c.mu.Lock()
+	delete(c.entries, host)
+	c.mu.Unlock()
+}
+
+// dialer returns a dial function that connects to the cached addresses of a
+// host with d, trying each in turn.
+func (c *dnsCache) dialer(d *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil || net.ParseIP(host) != nil {
+			return d.DialContext(ctx, network, addr)
+		}
+		addrs, err := c.addrs(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range addrs {
+			var conn net.Conn
+			if conn, err = d.DialContext(ctx, network, net.JoinHostPort(a, port)); err == nil {
+				return conn, nil
+			}
+		}
+		// The host may have moved, so look it up again next time.
+		c.forget(host)
+		return nil, err
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestClientIsShared(t *testing.T) {
+	if Client() != Client() {
+		t.Error("expected Client to return the same client every time")
+	}
+}
+
+func TestNewClientReusesConnections(t *testing.T) {
+	var mu sync.Mutex
+	conns := 0
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	srv.Config.ConnState = func(c net.Conn, s http.ConnState) {
+		if s == http.StateNew {
+			mu.Lock()
+			conns++
+			mu.Unlock()
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	c := NewClient(DefaultClientOptions)
+	for i := 0; i < 5; i++ {
+		resp, err := c.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if conns != 1 {
+		t.Errorf("expected 1 connection for 5 requests, got %d", conns)
+	}
+}
+
+func TestDNSCache(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	_, port, _ := net.SplitHostPort(srv.Listener.Addr().String())
+
+	lookups := 0
+	addrs := []string{"127.0.0.1"}
+	c := &dnsCache{
+		ttl: time.Hour,
+		lookup: func(ctx context.Context, host string) ([]string, error) {
+			lookups++
+			if host != "charts.example.com" {
+				return nil, errors.New("no such host")
+			}
+			return addrs, nil
+		},
+		entries: map[string]dnsEntry{},
+	}
+	dial := c.dialer(&net.Dialer{})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		conn, err := dial(ctx, "tcp", net.JoinHostPort("charts.example.com", port))
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn.Close()
+	}
+	if lookups != 1 {
+		t.Errorf("expected 1 lookup, got %d", lookups)
+	}
+
+	// An address given as an IP is dialed without a lookup.
+	conn, err := dial(ctx, "tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	if lookups != 1 {
+		t.Errorf("expected IP addresses not to be looked up, got %d lookups", lookups)
+	}
+
+	// When no cached address answers, the host is looked up again next time.
+	srv.Close()
+	if _, err := dial(ctx, "tcp", net.JoinHostPort("charts.example.com", port)); err == nil {
+		t.Fatal("expected a closed server to fail")
+	}
+	if _, ok := c.entries["charts.example.com"]; ok {
+		t.Error("expected a host that could not be reached to be forgotten")
+	}
+
+	if _, err := dial(ctx, "tcp", "other.example.com:80"); err == nil {
+		t.Error("expected a failed lookup to fail the dial")
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo
+
+import (
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"k8s.io/helm/pkg/provenance"
+)
+
+// Health is what Entry.Check found out about a repository.
+type Health struct {
+	// Err is what is wrong with the repository, or nil if nothing is.
+	Err error
+	// Generated is when the index of the repository was generated. It is
+	// zero if the index could not be fetched, or does not say.
+	Generated time.Time
+}
+
+// Check probes the repository: that its index can be fetched, over a TLS
+// connection with a valid certificate and with credentials the server
+// accepts, that it parses, that it has not expired and, if the repository
+// was added with VerifyIndex, that its signature verifies. If maxAge is
+// positive, the index must also have been generated less than maxAge ago.
+func (e *Entry) Check(maxAge time.Duration) Health {
+	h := Health{}
+	resp, err := Get(indexURL(e.URL))
+	if err != nil {
+		if isCertificateError(err) {
+			h.Err = fmt.Errorf("TLS verification failed: %s", err)
+		} else {
+			h.Err = fmt.Errorf("index is unreachable: %s", err)
+		}
+		return h
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		h.Err = fmt.Errorf("authentication failed: %s", resp.Status)
+		return h
+	case resp.StatusCode != http.StatusOK:
+		h.Err = fmt.Errorf("index is unavailable: %s", resp.Status)
+		return h
+	}
+
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		h.Err = fmt.Errorf("index is unreachable: %s", err)
+		return h
+	}
+	i, err := LoadIndex(b)
+	if err != nil {
+		h.Err = fmt.Errorf("index is invalid: %s", err)
+		return h
+	}
+	h.Generated = i.Generated
+
+	if !i.Expires.IsZero() && time.Now().After(i.Expires) {
+		h.Err = fmt.Errorf("index expired at %s", i.Expires.Format(time.RFC3339))
+		return h
+	}
+	if maxAge > 0 {
+		if i.Generated.IsZero() {
+			h.Err = fmt.Errorf("index does not say when it was generated")
+			return h
+		}
+		if age := time.Since(i.Generated); age > maxAge {
+			h.Err = fmt.Errorf("index was generated %s ago, more than %s", age-age%time.Second, maxAge)
+			return h
+		}
+	}
+	if e.VerifyIndex {
+		signer, err := provenance.NewFromKeyring(e.Keyring, "")
+		if err != nil {
+			h.Err = fmt.Errorf("failed to load keyring %s: %s", e.Keyring, err)
+			return h
+		}
+		h.Err = checkIndexSignature(signer, e.Name, e.URL, b)
+	}
+	return h
+}
+
+// isCertificateError reports whether err is caused by a TLS certificate that
+// could not be verified.
+func isCertificateError(err error) bool {
+	for err != nil {
+		switch e := err.(type) {
+		case x509.UnknownAuthorityError, x509.HostnameError, x509.CertificateInvalidError:
+			return true
+		case *url.Error:
+			err = e.Err
+		case interface {
+			Unwrap() error
+		}:
+			err = e.Unwrap()
+		default:
+			return false
+		}
+	}
+	return false
+}
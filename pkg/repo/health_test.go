@@ -0,0 +1,107 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEntryCheck(t *testing.T) {
+	_, restore := recordSleeps()
+	defer restore()
+
+	now := time.Now().UTC()
+	index := func(generated, expires time.Time) string {
+		s := fmt.Sprintf("apiVersion: v1\nentries: {}\ngenerated: %s\n", generated.Format(time.RFC3339))
+		if !expires.IsZero() {
+			s += fmt.Sprintf("expires: %s\n", expires.Format(time.RFC3339))
+		}
+		return s
+	}
+
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		maxAge time.Duration
+		expect string
+	}{
+		{name: "healthy", body: index(now.Add(-time.Hour), time.Time{})},
+		{name: "young enough", body: index(now.Add(-time.Hour), time.Time{}), maxAge: 2 * time.Hour},
+		{name: "too old", body: index(now.Add(-3*time.Hour), time.Time{}), maxAge: 2 * time.Hour, expect: "more than 2h0m0s"},
+		{name: "expired", body: index(now.Add(-time.Hour), now.Add(-time.Minute)), expect: "index expired"},
+		{name: "unauthorized", status: http.StatusUnauthorized, expect: "authentication failed: 401"},
+		{name: "forbidden", status: http.StatusForbidden, expect: "authentication failed: 403"},
+		{name: "missing", status: http.StatusNotFound, expect: "index is unavailable: 404"},
+		{name: "invalid", body: "not: [an index", expect: "index is invalid"},
+	}
+	for _, tt := range tests {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if tt.status != 0 {
+				w.WriteHeader(tt.status)
+				return
+			}
+			fmt.Fprint(w, tt.body)
+		}))
+		h := (&Entry{Name: "test", URL: srv.URL}).Check(tt.maxAge)
+		srv.Close()
+
+		if tt.expect == "" {
+			if h.Err != nil {
+				t.Errorf("%s: %s", tt.name, h.Err)
+			}
+			if h.Generated.IsZero() {
+				t.Errorf("%s: expected the generation time of the index", tt.name)
+			}
+			continue
+		}
+		if h.Err == nil || !strings.Contains(h.Err.Error(), tt.expect) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.expect, h.Err)
+		}
+	}
+}
+
+func TestEntryCheckTLS(t *testing.T) {
+	_, restore := recordSleeps()
+	defer restore()
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "apiVersion: v1\nentries: {}\n")
+	}))
+	// The failed handshakes are expected.
+	srv.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	srv.StartTLS()
+	defer srv.Close()
+
+	h := (&Entry{Name: "test", URL: srv.URL}).Check(0)
+	if h.Err == nil || !strings.HasPrefix(h.Err.Error(), "TLS verification failed") {
+		t.Errorf("expected a certificate error, got %v", h.Err)
+	}
+
+	srv.Close()
+	h = (&Entry{Name: "test", URL: srv.URL}).Check(0)
+	if h.Err == nil || !strings.HasPrefix(h.Err.Error(), "index is unreachable") {
+		t.Errorf("expected a closed server to be unreachable, got %v", h.Err)
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package repo
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -82,6 +83,11 @@ type IndexFile struct {
 	Generated  time.Time                `json:"generated"`
 	Entries    map[string]ChartVersions `json:"entries"`
 	PublicKeys []string                 `json:"publicKeys,omitempty"`
+	// Version increases every time the repository regenerates its index.
+	// Clients refuse an index older than the one they last saw.
+	Version int64 `json:"version,omitempty"`
+	// Expires, if set, is the time after which clients refuse the index.
+	Expires time.Time `json:"expires,omitempty"`
 }
 
 // NewIndexFile initializes an index.
@@ -168,7 +174,7 @@ func (i IndexFile) WriteFile(dest string, mode os.FileMode) error {
 	if err != nil {
 		return err
 	}
-	return ioutil.WriteFile(dest, b, mode)
+	return writeFileAtomic(dest, b, mode)
 }
 
 // Merge merges the given index file into this index.
@@ -203,18 +209,21 @@ type ChartVersion struct {
 
 // IndexDirectory reads a (flat) directory and generates an index.
 //
-// It indexes only charts that have been packaged (*.tgz).
+// It indexes only charts that have been packaged (*.tgz or *.tzst).
 //
 // The index returned will be in an unsorted state
 func IndexDirectory(dir, baseURL string) (*IndexFile, error) {
-	archives, err := filepath.Glob(filepath.Join(dir, "*.tgz"))
+	archives, err := filepath.Glob(filepath.Join(dir, "*"))
 	if err != nil {
 		return nil, err
 	}
 	index := NewIndexFile()
 	for _, arch := range archives {
 		fname := filepath.Base(arch)
-		c, err := chartutil.Load(arch)
+		if !chartutil.IsArchive(fname) {
+			continue
+		}
+		md, err := chartutil.LoadMetadata(arch)
 		if err != nil {
 			// Assume this is not a chart.
 			continue
@@ -223,40 +232,170 @@ func IndexDirectory(dir, baseURL string) (*IndexFile, error) {
 		if err != nil {
 			return index, err
 		}
-		index.Add(c.Metadata, fname, baseURL, hash)
+		index.Add(md, fname, baseURL, hash)
 	}
 	return index, nil
 }
 
+// IndexSignatureSuffix is appended to the URL of a repository's index.yaml to
+// find the detached signature of the index.
+const IndexSignatureSuffix = ".sig"
+
 // DownloadIndexFile fetches the index from a repository.
+//
+// The index is refused if it has expired, or if its version is lower than
+// that of the index previously saved at indexFilePath.
 func DownloadIndexFile(repoName, url, indexFilePath string) error {
-	var indexURL string
+	b, i, err := fetchIndex(url)
+	if err != nil {
+		return err
+	}
+	if err := checkFreshness(repoName, indexFilePath, i); err != nil {
+		return err
+	}
+	return saveIndex(indexFilePath, b)
+}
+
+// DownloadVerifiedIndexFile fetches the index from a repository, like
+// DownloadIndexFile, but only saves it if the detached signature published
+// next to it (index.yaml.sig) was made by a key in the given keyring.
+func DownloadVerifiedIndexFile(repoName, url, indexFilePath, keyring string) error {
+	signer, err := provenance.NewFromKeyring(keyring, "")
+	if err != nil {
+		return fmt.Errorf("failed to load keyring %s: %s", keyring, err)
+	}
+
+	b, i, err := fetchIndex(url)
+	if err != nil {
+		return err
+	}
+	if err := checkIndexSignature(signer, repoName, url, b); err != nil {
+		return err
+	}
+	if err := checkFreshness(repoName, indexFilePath, i); err != nil {
+		return err
+	}
+	return saveIndex(indexFilePath, b)
+}
 
-	indexURL = strings.TrimSuffix(url, "/") + "/index.yaml"
-	resp, err := http.Get(indexURL)
+// checkIndexSignature checks the index b of a repository against the detached
+// signature published next to it.
+func checkIndexSignature(signer *provenance.Signatory, repoName, url string, b []byte) error {
+	sigURL := indexURL(url) + IndexSignatureSuffix
+	resp, err := Get(sigURL)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch index signature %s: %s", sigURL, resp.Status)
+	}
+	sig, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if _, err := signer.VerifyDetached(b, sig); err != nil {
+		return fmt.Errorf("index of repository %q failed verification: %s", repoName, err)
+	}
+	return nil
+}
+
+// DownloadIndex fetches the index of the repository, verifying its signature
+// first if the repository was added with VerifyIndex set.
+func (e *Entry) DownloadIndex(indexFilePath string) error {
+	if e.VerifyIndex {
+		return DownloadVerifiedIndexFile(e.Name, e.URL, indexFilePath, e.Keyring)
+	}
+	return DownloadIndexFile(e.Name, e.URL, indexFilePath)
+}
+
+func indexURL(url string) string {
+	return strings.TrimSuffix(url, "/") + "/index.yaml"
+}
+
+// fetchIndex downloads an index and checks that it parses.
+func fetchIndex(url string) ([]byte, *IndexFile, error) {
+	resp, err := Get(indexURL(url))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
 
 	b, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
-	if _, err := LoadIndex(b); err != nil {
+	i, err := LoadIndex(b)
+	if err != nil {
+		return nil, nil, err
+	}
+	return b, i, nil
+}
+
+// checkFreshness refuses an expired index, and an index older than the one
+// last saved at indexFilePath, so that a stale copy cannot be passed off as
+// current to hide newer chart versions.
+func checkFreshness(repoName, indexFilePath string, i *IndexFile) error {
+	if err := checkExpiry(fmt.Sprintf("index of repository %q", repoName), i); err != nil {
 		return err
 	}
+	prev, err := LoadIndexFile(indexFilePath)
+	if err != nil {
+		// Nothing was seen before, so there is nothing to roll back from.
+		return nil
+	}
+	if i.Version < prev.Version {
+		return fmt.Errorf("index of repository %q has version %d, older than the last seen version %d", repoName, i.Version, prev.Version)
+	}
+	return nil
+}
 
-	return ioutil.WriteFile(indexFilePath, b, 0644)
+// checkExpiry refuses an index whose expiry has passed. what names the index
+// in the error.
+func checkExpiry(what string, i *IndexFile) error {
+	if !i.Expires.IsZero() && time.Now().After(i.Expires) {
+		return fmt.Errorf("%s expired at %s", what, i.Expires.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func saveIndex(indexFilePath string, b []byte) error {
+	if err := writeFileAtomic(indexFilePath, b, 0644); err != nil {
+		return err
+	}
+	// The cache would be noticed to be stale anyway, but there is no point
+	// in keeping it around.
+	os.Remove(indexFilePath + IndexCacheSuffix)
+	return nil
 }
 
 // LoadIndex loads an index file and does minimal validity checking.
 //
 // This will fail if API Version is not set (ErrNoAPIVersion) or if the unmarshal fails.
+//
+// JSON is valid YAML, so an index may also be served as JSON. That is decoded
+// directly, which is much faster than going through the YAML parser. YAML
+// indexes are decoded one chart version at a time when they are laid out as
+// Helm writes them; see decodeIndex.
 func LoadIndex(data []byte) (*IndexFile, error) {
+	if i, err := decodeIndex(bytes.NewReader(data)); err == nil {
+		return i, nil
+	}
+	return parseIndex(data)
+}
+
+// parseIndex parses a whole index at once.
+func parseIndex(data []byte) (*IndexFile, error) {
 	i := &IndexFile{}
-	if err := yaml.Unmarshal(data, i); err != nil {
+	var err error
+	if isJSON(data) {
+		err = json.Unmarshal(data, i)
+	} else {
+		err = yaml.Unmarshal(data, i)
+	}
+	if err != nil {
 		return i, err
 	}
 	if i.APIVersion == "" {
@@ -265,9 +404,16 @@ func LoadIndex(data []byte) (*IndexFile, error) {
 		//return i, ErrNoAPIVersion
 		return loadUnversionedIndex(data)
 	}
+	i.intern()
 	return i, nil
 }
 
+// isJSON reports whether data looks like a JSON object rather than YAML.
+func isJSON(data []byte) bool {
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	return len(trimmed) > 0 && trimmed[0] == '{'
+}
+
 // unversionedEntry represents a deprecated pre-Alpha.5 format.
 //
 // This will be removed prior to v2.0.0
@@ -314,12 +460,24 @@ func loadUnversionedIndex(data []byte) (*IndexFile, error) {
 }
 
 // LoadIndexFile takes a file at the given path and returns an IndexFile object
+//
+// The file is streamed, rather than read into memory first, unless it cannot
+// be decoded one chart version at a time.
 func LoadIndexFile(path string) (*IndexFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	i, err := decodeIndex(f)
+	f.Close()
+	if err == nil {
+		return i, nil
+	}
 	b, err := ioutil.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
-	return LoadIndex(b)
+	return parseIndex(b)
 }
 
 // urlJoin joins a base URL to one or more path components.
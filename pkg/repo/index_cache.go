@@ -0,0 +1,161 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// IndexCacheSuffix is appended to the path of an index file to name its
+// binary cache.
+const IndexCacheSuffix = ".cache"
+
+// indexCacheVersion must be bumped whenever IndexFile or ChartVersion change,
+// so that caches written by other versions of Helm are not misread.
+const indexCacheVersion = 2
+
+var errStaleIndexCache = errors.New("index cache is out of date")
+
+// indexCacheHeader identifies the index file a cache was made from.
+type indexCacheHeader struct {
+	Version int
+	Size    int64
+	ModTime time.Time
+}
+
+// LoadCachedIndexFile is like LoadIndexFile, but keeps a binary copy of the
+// parsed index next to the file, and loads that instead for as long as the
+// file does not change.
+//
+// Parsing the YAML of a large repository index takes far longer than decoding
+// the cache, and commands like 'helm search' load every index they know of.
+// The cache is only an optimization: when it cannot be read or written, the
+// index is parsed as usual.
+//
+// As it loads the indexes that charts are looked up in, LoadCachedIndexFile
+// also refuses an index whose expiry has passed since it was downloaded.
+func LoadCachedIndexFile(path string) (*IndexFile, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	cache := path + IndexCacheSuffix
+	i, err := readIndexCache(cache, fi)
+	if err != nil {
+		if i, err = LoadIndexFile(path); err != nil {
+			return nil, err
+		}
+		writeIndexCache(cache, fi, i)
+	}
+	if err := checkExpiry(fmt.Sprintf("index %s", path), i); err != nil {
+		return nil, err
+	}
+	return i, nil
+}
+
+func readIndexCache(cache string, fi os.FileInfo) (*IndexFile, error) {
+	f, err := os.Open(cache)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	dec := gob.NewDecoder(bufio.NewReader(f))
+	var h indexCacheHeader
+	if err := dec.Decode(&h); err != nil {
+		return nil, err
+	}
+	if h.Version != indexCacheVersion || h.Size != fi.Size() || !h.ModTime.Equal(fi.ModTime()) {
+		return nil, errStaleIndexCache
+	}
+	i := &IndexFile{}
+	if err := dec.Decode(i); err != nil {
+		return nil, err
+	}
+	if i.Entries == nil {
+		i.Entries = map[string]ChartVersions{}
+	}
+	i.intern()
+	return i, nil
+}
+
+func writeIndexCache(cache string, fi os.FileInfo, i *IndexFile) error {
+	var b bytes.Buffer
+	enc := gob.NewEncoder(&b)
+	h := indexCacheHeader{Version: indexCacheVersion, Size: fi.Size(), ModTime: fi.ModTime()}
+	if err := enc.Encode(h); err != nil {
+		return err
+	}
+	if err := enc.Encode(i); err != nil {
+		return err
+	}
+	return writeFileAtomic(cache, b.Bytes(), 0644)
+}
+
+// intern makes the strings that an index repeats share a single copy.
+//
+// Every version of a chart repeats its name, description, home, sources,
+// keywords and maintainers, so for repositories with many versions of each
+// chart this shrinks the parsed index considerably.
+func (i *IndexFile) intern() {
+	in := interner{}
+	for _, versions := range i.Entries {
+		for _, cv := range versions {
+			in.chartVersion(cv)
+		}
+	}
+}
+
+// interner maps strings to the copy of them that is kept.
+type interner map[string]string
+
+func (in interner) intern(s *string) {
+	if v, ok := in[*s]; ok {
+		*s = v
+	} else {
+		in[*s] = *s
+	}
+}
+
+// chartVersion interns the repeated strings of cv.
+func (in interner) chartVersion(cv *ChartVersion) {
+	if cv == nil || cv.Metadata == nil {
+		return
+	}
+	md := cv.Metadata
+	for _, s := range []*string{&md.Name, &md.Home, &md.Description, &md.Engine, &md.Icon, &md.ApiVersion, &md.TillerVersion, &md.KubeVersion, &md.ReplacedBy} {
+		in.intern(s)
+	}
+	for _, ss := range [][]string{md.Sources, md.Keywords} {
+		for j := range ss {
+			in.intern(&ss[j])
+		}
+	}
+	for _, m := range md.Maintainers {
+		if m != nil {
+			in.intern(&m.Name)
+			in.intern(&m.Email)
+			in.intern(&m.Url)
+		}
+	}
+}
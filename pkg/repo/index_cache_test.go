@@ -0,0 +1,160 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadCachedIndexFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-index-cache-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	b, err := ioutil.ReadFile(testfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "test-index.yaml")
+	if err := ioutil.WriteFile(path, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := LoadIndexFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i, err := LoadCachedIndexFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path + IndexCacheSuffix); err != nil {
+		t.Fatalf("expected a cache to be written: %s", err)
+	}
+	cached, err := LoadCachedIndexFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, got := range []*IndexFile{i, cached} {
+		if len(got.Entries) != len(want.Entries) {
+			t.Fatalf("expected %d entries, got %d", len(want.Entries), len(got.Entries))
+		}
+		for name, versions := range want.Entries {
+			for n, cv := range versions {
+				if !reflect.DeepEqual(cv.Metadata, got.Entries[name][n].Metadata) || cv.Digest != got.Entries[name][n].Digest {
+					t.Errorf("%s: expected %v, got %v", name, cv, got.Entries[name][n])
+				}
+			}
+		}
+	}
+
+	// Changing the index makes the cache stale.
+	empty := NewIndexFile()
+	if err := empty.WriteFile(path, 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	os.Chtimes(path, later, later)
+	i, err = LoadCachedIndexFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(i.Entries) != 0 {
+		t.Errorf("expected the changed index to be loaded, got %d entries", len(i.Entries))
+	}
+
+	// A corrupt cache is ignored.
+	if err := ioutil.WriteFile(path+IndexCacheSuffix, []byte("garbage"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCachedIndexFile(path); err != nil {
+		t.Errorf("expected a corrupt cache to be ignored, got %s", err)
+	}
+}
+
+func TestLoadIndexJSON(t *testing.T) {
+	want, err := LoadIndexFile(testfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := LoadIndex(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Entries) != len(want.Entries) {
+		t.Fatalf("expected %d entries, got %d", len(want.Entries), len(got.Entries))
+	}
+	for name, versions := range want.Entries {
+		if !reflect.DeepEqual(versions[0].Metadata, got.Entries[name][0].Metadata) {
+			t.Errorf("%s: expected %v, got %v", name, versions[0].Metadata, got.Entries[name][0].Metadata)
+		}
+	}
+}
+
+func TestLoadCachedIndexFileExpired(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-index-cache-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test-index.yaml")
+	i := NewIndexFile()
+	i.Expires = time.Now().Add(time.Hour)
+	if err := i.WriteFile(path, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCachedIndexFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// The index expires after it was downloaded and cached.
+	i.Expires = time.Now().Add(-time.Minute)
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeIndexCache(path+IndexCacheSuffix, fi, i); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCachedIndexFile(path); err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Errorf("expected the expired cached index to be refused, got %v", err)
+	}
+
+	if err := os.Remove(path + IndexCacheSuffix); err != nil {
+		t.Fatal(err)
+	}
+	if err := i.WriteFile(path, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCachedIndexFile(path); err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Errorf("expected the expired index to be refused, got %v", err)
+	}
+}
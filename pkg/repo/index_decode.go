@@ -0,0 +1,259 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// errIndexLayout is returned by decodeIndex for an index it cannot stream.
+var errIndexLayout = errors.New("index is not laid out to be streamed")
+
+// decodeIndex decodes an index from r one chart version at a time, interning
+// the strings of each version as it is decoded.
+//
+// Parsing a whole YAML index holds it in memory as YAML, as JSON and as Go
+// values at once, which for large repositories takes hundreds of megabytes.
+// decodeIndex instead splits the block layout that Helm and other index
+// generators write into chart versions, so that only the decoded index and a
+// single version are held. JSON is decoded with a streaming JSON decoder.
+//
+// For any other layout, and for indexes without an API version, it returns
+// an error, and the index must be parsed as a whole.
+func decodeIndex(r io.Reader) (*IndexFile, error) {
+	br := bufio.NewReader(r)
+	for {
+		c, err := br.ReadByte()
+		if err != nil {
+			return nil, errIndexLayout
+		}
+		if !strings.ContainsRune(" \t\r\n", rune(c)) {
+			br.UnreadByte()
+			if c == '{' {
+				return decodeJSONIndex(br)
+			}
+			break
+		}
+	}
+
+	d := &indexDecoder{
+		entries:     map[string]ChartVersions{},
+		in:          interner{},
+		chartIndent: -1,
+	}
+	for {
+		line, err := br.ReadString('\n')
+		if line != "" {
+			if err := d.line(line); err != nil {
+				return nil, err
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := d.flush(); err != nil {
+		return nil, err
+	}
+
+	i := &IndexFile{}
+	if err := yaml.Unmarshal(d.rest.Bytes(), i); err != nil {
+		return nil, err
+	}
+	if i.APIVersion == "" || (len(i.Entries) > 0 && d.streamed) {
+		return nil, errIndexLayout
+	}
+	if d.streamed || i.Entries == nil {
+		i.Entries = d.entries
+	} else {
+		i.intern()
+	}
+	return i, nil
+}
+
+func decodeJSONIndex(r io.Reader) (*IndexFile, error) {
+	i := &IndexFile{}
+	if err := json.NewDecoder(r).Decode(i); err != nil {
+		return nil, err
+	}
+	if i.APIVersion == "" {
+		return nil, errIndexLayout
+	}
+	if i.Entries == nil {
+		i.Entries = map[string]ChartVersions{}
+	}
+	i.intern()
+	return i, nil
+}
+
+// indexDecoder splits the lines of a YAML index into the chart versions of
+// its entries and the lines of its other fields.
+type indexDecoder struct {
+	// rest holds the lines of the fields other than the entries.
+	rest bytes.Buffer
+	// entries holds the versions decoded so far.
+	entries map[string]ChartVersions
+	in      interner
+	// streamed is set once an "entries:" block has been seen.
+	streamed  bool
+	inEntries bool
+	started   bool
+
+	// chartIndent is the indentation of the chart names in the entries, and
+	// chart the name of the chart whose versions follow.
+	chartIndent int
+	chart       string
+	// itemIndent is the indentation of the "-" of the versions of the chart,
+	// and keyIndent that of the keys of the version being read into item.
+	itemIndent int
+	keyIndent  int
+	item       bytes.Buffer
+	hasItem    bool
+}
+
+func (d *indexDecoder) line(line string) error {
+	trimmed := strings.TrimSpace(line)
+	indent := len(line) - len(strings.TrimLeft(line, " "))
+	if strings.HasPrefix(line[indent:], "\t") {
+		return errIndexLayout
+	}
+
+	switch {
+	case trimmed == "":
+		// Blank lines may be part of a block scalar.
+		if d.hasItem {
+			d.item.WriteString("\n")
+		} else if !d.inEntries {
+			d.rest.WriteString(line)
+		}
+		return nil
+
+	case indent == 0:
+		if err := d.flush(); err != nil {
+			return err
+		}
+		if trimmed[0] == '#' {
+			return nil
+		}
+		if trimmed == "---" && !d.started {
+			return nil
+		}
+		if trimmed == "---" || trimmed == "..." || trimmed[0] == '%' {
+			return errIndexLayout
+		}
+		d.started = true
+		d.inEntries = trimmed == "entries:"
+		if d.inEntries {
+			if d.streamed {
+				return errIndexLayout
+			}
+			d.streamed = true
+			return nil
+		}
+		d.rest.WriteString(line)
+		return nil
+
+	case !d.inEntries:
+		// A field other than the entries continues.
+		d.rest.WriteString(line)
+		return nil
+
+	case trimmed[0] == '#' && (!d.hasItem || indent <= d.itemIndent):
+		return nil
+	}
+
+	if d.chartIndent < 0 {
+		d.chartIndent = indent
+	}
+	if indent < d.chartIndent {
+		return errIndexLayout
+	}
+
+	if indent == d.chartIndent && trimmed[0] != '-' {
+		// The name of a chart, such as "nginx:".
+		if err := d.flush(); err != nil {
+			return err
+		}
+		var entry map[string]ChartVersions
+		if err := yaml.Unmarshal([]byte(trimmed), &entry); err != nil || len(entry) != 1 {
+			return errIndexLayout
+		}
+		for name, versions := range entry {
+			if _, ok := d.entries[name]; ok {
+				return errIndexLayout
+			}
+			for _, cv := range versions {
+				d.in.chartVersion(cv)
+			}
+			d.chart = name
+			d.entries[name] = versions
+		}
+		d.itemIndent = -1
+		return nil
+	}
+	if d.chart == "" {
+		return errIndexLayout
+	}
+
+	if trimmed[0] == '-' && (d.itemIndent < 0 || indent == d.itemIndent) {
+		// A version of the chart starts.
+		if err := d.flush(); err != nil {
+			return err
+		}
+		rest := strings.TrimLeft(line[indent+1:], " ")
+		if rest == "" || rest[0] == '\r' || rest[0] == '\n' {
+			return errIndexLayout
+		}
+		d.itemIndent = indent
+		d.keyIndent = len(line) - len(rest)
+		d.item.WriteString(rest)
+		d.hasItem = true
+		return nil
+	}
+
+	if !d.hasItem || indent < d.keyIndent {
+		return errIndexLayout
+	}
+	d.item.WriteString(line[d.keyIndent:])
+	return nil
+}
+
+// flush decodes the version being read, if there is one.
+func (d *indexDecoder) flush() error {
+	if !d.hasItem {
+		return nil
+	}
+	cv := &ChartVersion{}
+	if err := yaml.Unmarshal(d.item.Bytes(), cv); err != nil {
+		return err
+	}
+	d.in.chartVersion(cv)
+	d.entries[d.chart] = append(d.entries[d.chart], cv)
+	d.item.Reset()
+	d.hasItem = false
+	return nil
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/ghodss/yaml"
+)
+
+func TestDecodeIndex(t *testing.T) {
+	local, err := ioutil.ReadFile(testfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i, err := parseIndex(local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	written, err := yaml.Marshal(i)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"indented sequences", local},
+		{"as written by helm", written},
+		{"document start and comments", []byte(`---
+# The index of the charts.
+apiVersion: v1
+entries:
+  # No versions yet.
+  empty: []
+  nginx:
+  # The latest version.
+  - name: nginx
+    version: 0.2.0
+    description: |
+      A web server.
+
+      # Not a comment.
+    urls:
+    - https://charts.example.com/nginx-0.2.0.tgz
+
+  - name: nginx
+    version: 0.1.0
+generated: 2016-10-06T16:23:20.499029981-06:00
+`)},
+		{"no entries", []byte("apiVersion: v1\nentries: {}\n")},
+		{"flow entries", []byte("apiVersion: v1\nentries: {nginx: [{name: nginx, version: 0.1.0}]}\n")},
+		{"windows line endings", bytes.Replace(local, []byte("\n"), []byte("\r\n"), -1)},
+	}
+	for _, tt := range tests {
+		want, err := parseIndex(tt.data)
+		if err != nil {
+			t.Fatalf("%s: %s", tt.name, err)
+		}
+		got, err := decodeIndex(bytes.NewReader(tt.data))
+		if err != nil {
+			t.Errorf("%s: expected the index to be decoded, got %s", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: expected %#v, got %#v", tt.name, want, got)
+		}
+	}
+}
+
+func TestDecodeIndexLayouts(t *testing.T) {
+	// Indexes that cannot be split into versions are left to parseIndex.
+	for _, data := range []string{
+		"apiVersion: v1\nentries:\n  nginx:\n  - &v {name: nginx, version: 0.1.0}\n  - *v\n",
+		"apiVersion: v1\nentries:\n  nginx: [\n    {name: nginx}]\n",
+		"apiVersion: v1\nentries:\n\tnginx: []\n",
+		"apiVersion: v1\nentries:\n  nginx: []\n---\napiVersion: v1\n",
+		"entries:\n  nginx: []\n",
+	} {
+		if _, err := decodeIndex(bytes.NewReader([]byte(data))); err == nil {
+			t.Errorf("expected %q not to be streamed", data)
+		}
+	}
+
+	// They still load.
+	i, err := LoadIndex([]byte("apiVersion: v1\nentries:\n  nginx:\n  - &v {name: nginx, version: 0.1.0}\n  - *v\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(i.Entries["nginx"]) != 2 {
+		t.Errorf("expected two versions, got %d", len(i.Entries["nginx"]))
+	}
+}
@@ -22,9 +22,14 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/ghodss/yaml"
 
 	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/provenance"
 )
 
 const (
@@ -155,6 +160,119 @@ func TestDownloadIndexFile(t *testing.T) {
 	verifyLocalIndex(t, i)
 }
 
+func TestDownloadIndexFileFreshness(t *testing.T) {
+	var served *IndexFile
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := yaml.Marshal(served)
+		w.Write(b)
+	}))
+	defer srv.Close()
+
+	dirName, err := ioutil.TempDir("", "tmp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirName)
+	path := filepath.Join(dirName, testRepo+"-index.yaml")
+
+	served = NewIndexFile()
+	served.Version = 2
+	if err := DownloadIndexFile(testRepo, srv.URL, path); err != nil {
+		t.Fatal(err)
+	}
+
+	served = NewIndexFile()
+	served.Version = 1
+	if err := DownloadIndexFile(testRepo, srv.URL, path); err == nil || !strings.Contains(err.Error(), "older than the last seen version 2") {
+		t.Errorf("expected an older index to be refused, got %v", err)
+	}
+	if i, err := LoadIndexFile(path); err != nil || i.Version != 2 {
+		t.Errorf("expected the saved index to be kept, got %v (%v)", i, err)
+	}
+
+	served = NewIndexFile()
+	served.Version = 3
+	served.Expires = time.Now().Add(-time.Minute)
+	if err := DownloadIndexFile(testRepo, srv.URL, path); err == nil || !strings.Contains(err.Error(), "expired") {
+		t.Errorf("expected an expired index to be refused, got %v", err)
+	}
+
+	served.Expires = time.Now().Add(time.Hour)
+	if err := DownloadIndexFile(testRepo, srv.URL, path); err != nil {
+		t.Errorf("expected a newer index to be accepted, got %s", err)
+	}
+}
+
+func TestDownloadVerifiedIndexFile(t *testing.T) {
+	fileBytes, err := ioutil.ReadFile("testdata/local-index.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := provenance.NewFromFiles("../provenance/testdata/helm-test-key.secret", "../provenance/testdata/helm-test-key.pub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := signer.DetachSign(fileBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	index, signature := fileBytes, []byte(sig)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/index.yaml":
+			w.Write(index)
+		case "/index.yaml" + IndexSignatureSuffix:
+			if signature == nil {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write(signature)
+		}
+	}))
+	defer srv.Close()
+
+	dirName, err := ioutil.TempDir("", "tmp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirName)
+
+	keyring := "../provenance/testdata/helm-test-key.pub"
+	path := filepath.Join(dirName, testRepo+"-index.yaml")
+	if err := DownloadVerifiedIndexFile(testRepo, srv.URL, path, keyring); err != nil {
+		t.Fatal(err)
+	}
+	i, err := LoadIndexFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifyLocalIndex(t, i)
+
+	os.Remove(path)
+	index = append(append([]byte{}, fileBytes...), []byte("\n# tampered\n")...)
+	if err := DownloadVerifiedIndexFile(testRepo, srv.URL, path, keyring); err == nil || !strings.Contains(err.Error(), "failed verification") {
+		t.Errorf("expected a tampered index to fail verification, got %v", err)
+	}
+	if _, err := os.Stat(path); err == nil {
+		t.Error("expected a tampered index not to be saved")
+	}
+
+	index, signature = fileBytes, nil
+	if err := DownloadVerifiedIndexFile(testRepo, srv.URL, path, keyring); err == nil {
+		t.Error("expected an unsigned index to fail verification")
+	}
+
+	e := &Entry{Name: testRepo, URL: srv.URL}
+	if err := e.DownloadIndex(path); err != nil {
+		t.Errorf("expected an unsigned index to be accepted without VerifyIndex, got %s", err)
+	}
+	e.VerifyIndex, e.Keyring = true, keyring
+	if err := e.DownloadIndex(path); err == nil {
+		t.Error("expected an unsigned index to fail with VerifyIndex")
+	}
+}
+
 func verifyLocalIndex(t *testing.T, i *IndexFile) {
 	numEntries := len(i.Entries)
 	if numEntries != 2 {
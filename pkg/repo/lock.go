@@ -0,0 +1,99 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo // import "k8s.io/helm/pkg/repo"
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LockTimeout is how long to wait for another process to release a lock.
+var LockTimeout = 30 * time.Second
+
+// staleLockAge is the age past which a lock is assumed to be left over from a
+// process that died while holding it. Locks are only held while a file is
+// rewritten, so no live process holds one for that long.
+const staleLockAge = 2 * time.Minute
+
+// FileLock is an exclusive, advisory lock on a file, held by creating a lock
+// file next to it.
+type FileLock struct {
+	path string
+}
+
+// Lock takes the lock on the file at path, waiting for up to timeout for
+// other processes to release it.
+func Lock(path string, timeout time.Duration) (*FileLock, error) {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(timeout)
+	for {
+		f, err := os.OpenFile(lockPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			return &FileLock{path: lockPath}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+
+		if fi, err := os.Stat(lockPath); err == nil && time.Since(fi.ModTime()) > staleLockAge {
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for the lock on %s. If no other helm is running, remove %s", path, lockPath)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// Unlock releases the lock.
+func (l *FileLock) Unlock() error {
+	return os.Remove(l.path)
+}
+
+// writeFileAtomic writes data to the file at path, like ioutil.WriteFile, but
+// by renaming a temporary file over it, so that readers never see a partly
+// written file. A symbolic link at path is followed, not replaced.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		path = real
+	}
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Chmod(perm)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
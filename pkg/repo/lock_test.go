@@ -0,0 +1,178 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-lock-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "repositories.yaml")
+
+	l, err := Lock(path, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Lock(path, 100*time.Millisecond); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected a timeout while the lock is held, got %v", err)
+	}
+	if err := l.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err = Lock(path, time.Second)
+	if err != nil {
+		t.Fatalf("expected the lock to be free after Unlock: %s", err)
+	}
+	l.Unlock()
+}
+
+func TestLockStale(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-lock-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "repositories.yaml")
+
+	if err := ioutil.WriteFile(path+".lock", []byte("1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * staleLockAge)
+	if err := os.Chtimes(path+".lock", old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := Lock(path, 100*time.Millisecond)
+	if err != nil {
+		t.Fatalf("expected a stale lock to be taken over: %s", err)
+	}
+	l.Unlock()
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-lock-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	target := filepath.Join(dir, "target.yaml")
+	link := filepath.Join(dir, "link.yaml")
+	if err := ioutil.WriteFile(target, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("symlinks not supported: %s", err)
+	}
+
+	if err := writeFileAtomic(link, []byte("new"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if fi, err := os.Lstat(link); err != nil || fi.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("expected %s to still be a symlink", link)
+	}
+	b, err := ioutil.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "new" {
+		t.Errorf("expected the link target to be rewritten, got %q", b)
+	}
+	if fi, _ := os.Stat(target); fi.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600, got %v", fi.Mode().Perm())
+	}
+
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 2 {
+		t.Errorf("expected no temporary files to be left behind, got %d files", len(files))
+	}
+}
+
+func TestUpdateRepositoriesFileConcurrent(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-lock-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "repositories.yaml")
+	if err := NewRepoFile().WriteFile(path, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	var wg sync.WaitGroup
+	for _, name := range names {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			err := UpdateRepositoriesFile(path, func(f *RepoFile) error {
+				f.Add(&Entry{Name: name, URL: "http://example.com/" + name})
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}(name)
+	}
+	wg.Wait()
+
+	f, err := LoadRepositoriesFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		if !f.Has(name) {
+			t.Errorf("expected repository %q to have been added", name)
+		}
+	}
+}
+
+func TestUpdateRepositoriesFileMissing(t *testing.T) {
+	dir, err := ioutil.TempDir("", "helm-lock-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "repositories.yaml")
+
+	err = UpdateRepositoriesFile(path, func(f *RepoFile) error {
+		f.Add(&Entry{Name: "stable", URL: "http://example.com/stable"})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := LoadRepositoriesFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.APIVersion != APIVersionV1 || !f.Has("stable") {
+		t.Errorf("expected a new file with the stable repository, got %+v", f)
+	}
+}
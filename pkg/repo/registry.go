@@ -0,0 +1,352 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"k8s.io/helm/pkg/proto/hapi/chart"
+)
+
+// Media types of the parts of a chart stored in an OCI registry.
+const (
+	OCIManifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+	ChartConfigMediaType = "application/vnd.cncf.helm.config.v1+json"
+	ChartLayerMediaType  = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+)
+
+// ociCreatedAnnotation is the manifest annotation holding the creation time.
+const ociCreatedAnnotation = "org.opencontainers.image.created"
+
+// Registry lists the charts stored in a namespace of an OCI registry.
+//
+// Registries that require authentication are supported with basic
+// authentication and with the bearer token flow of the distribution spec.
+type Registry struct {
+	// Host is the registry's host, with an optional port.
+	Host string
+	// Namespace is the path under which charts are listed, e.g. "project".
+	// If empty, every repository of the registry is listed.
+	Namespace string
+
+	Username string
+	Password string
+	// PlainHTTP makes the registry be reached over HTTP rather than HTTPS.
+	PlainHTTP bool
+
+	Client *http.Client
+
+	// tokens caches bearer tokens by scope.
+	tokens map[string]string
+}
+
+// NewRegistry parses a reference of the form oci://HOST[/NAMESPACE].
+func NewRegistry(ref string) (*Registry, error) {
+	if !strings.HasPrefix(ref, "oci://") {
+		return nil, fmt.Errorf("invalid registry reference %q: must start with oci://", ref)
+	}
+	parts := strings.SplitN(strings.TrimSuffix(strings.TrimPrefix(ref, "oci://"), "/"), "/", 2)
+	if parts[0] == "" {
+		return nil, fmt.Errorf("invalid registry reference %q: no host", ref)
+	}
+	r := &Registry{Host: parts[0], Client: Client()}
+	if len(parts) == 2 {
+		r.Namespace = parts[1]
+	}
+	return r, nil
+}
+
+// ociManifest is the part of an OCI image manifest that describes a chart.
+type ociManifest struct {
+	Config      ociDescriptor     `json:"config"`
+	Layers      []ociDescriptor   `json:"layers"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+type ociDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+}
+
+// Index generates an index of the charts in the registry's namespace.
+//
+// Each chart version points at the chart archive's blob URL, so that clients
+// of classic chart repositories can download it. Artifacts that are not
+// charts are skipped.
+func (r *Registry) Index() (*IndexFile, error) {
+	repos, err := r.repositories()
+	if err != nil {
+		return nil, err
+	}
+	index := NewIndexFile()
+	for _, name := range repos {
+		tags, err := r.tags(name)
+		if err != nil {
+			return nil, err
+		}
+		for _, tag := range tags {
+			cv, err := r.chartVersion(name, tag)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%s: %s", name, tag, err)
+			}
+			if cv == nil || index.Has(cv.Name, cv.Version) {
+				continue
+			}
+			index.Entries[cv.Name] = append(index.Entries[cv.Name], cv)
+		}
+	}
+	return index, nil
+}
+
+// repositories lists the repositories under the namespace.
+func (r *Registry) repositories() ([]string, error) {
+	var catalog struct {
+		Repositories []string `json:"repositories"`
+	}
+	names := []string{}
+	err := r.list("/v2/_catalog", "registry:catalog:*", func(body io.Reader) error {
+		catalog.Repositories = nil
+		if err := json.NewDecoder(body).Decode(&catalog); err != nil {
+			return fmt.Errorf("malformed catalog: %s", err)
+		}
+		for _, n := range catalog.Repositories {
+			if r.Namespace == "" || strings.HasPrefix(n, r.Namespace+"/") {
+				names = append(names, n)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot list the repositories of %s: %s", r.Host, err)
+	}
+	return names, nil
+}
+
+// tags lists the tags of a repository.
+func (r *Registry) tags(name string) ([]string, error) {
+	var list struct {
+		Tags []string `json:"tags"`
+	}
+	tags := []string{}
+	err := r.list("/v2/"+name+"/tags/list", pullScope(name), func(body io.Reader) error {
+		list.Tags = nil
+		if err := json.NewDecoder(body).Decode(&list); err != nil {
+			return fmt.Errorf("malformed tag list: %s", err)
+		}
+		tags = append(tags, list.Tags...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot list the tags of %s: %s", name, err)
+	}
+	return tags, nil
+}
+
+// chartVersion returns the index entry for a tag, or nil if the tag is not
+// a chart.
+func (r *Registry) chartVersion(name, tag string) (*ChartVersion, error) {
+	var m ociManifest
+	if err := r.getJSON("/v2/"+name+"/manifests/"+tag, pullScope(name), OCIManifestMediaType, &m); err != nil {
+		return nil, err
+	}
+	if m.Config.MediaType != ChartConfigMediaType {
+		return nil, nil
+	}
+	layer := ""
+	for _, l := range m.Layers {
+		if l.MediaType == ChartLayerMediaType {
+			layer = l.Digest
+		}
+	}
+	if !strings.HasPrefix(layer, "sha256:") {
+		return nil, errors.New("chart has no sha256 chart content layer")
+	}
+
+	md := &chart.Metadata{}
+	if err := r.getJSON("/v2/"+name+"/blobs/"+m.Config.Digest, pullScope(name), ChartConfigMediaType, md); err != nil {
+		return nil, err
+	}
+	if md.Name == "" || md.Version == "" {
+		return nil, errors.New("chart config has no name or version")
+	}
+
+	created := time.Now()
+	if t, err := time.Parse(time.RFC3339, m.Annotations[ociCreatedAnnotation]); err == nil {
+		created = t
+	}
+	return &ChartVersion{
+		Metadata: md,
+		URLs:     []string{r.url("/v2/" + name + "/blobs/" + layer)},
+		Created:  created,
+		Digest:   strings.TrimPrefix(layer, "sha256:"),
+	}, nil
+}
+
+func pullScope(name string) string {
+	return "repository:" + name + ":pull"
+}
+
+func (r *Registry) url(path string) string {
+	scheme := "https"
+	if r.PlainHTTP {
+		scheme = "http"
+	}
+	return scheme + "://" + r.Host + path
+}
+
+// nextLink matches the Link header of a paginated response.
+var nextLink = regexp.MustCompile(`<([^>]+)>;\s*rel="?next"?`)
+
+// list fetches every page of a paginated listing and passes each to fn.
+func (r *Registry) list(path, scope string, fn func(io.Reader) error) error {
+	u := r.url(path)
+	for u != "" {
+		resp, err := r.get(u, scope, "application/json")
+		if err != nil {
+			return err
+		}
+		err = fn(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		u = ""
+		if m := nextLink.FindStringSubmatch(resp.Header.Get("Link")); m != nil {
+			next, err := resp.Request.URL.Parse(m[1])
+			if err != nil {
+				return err
+			}
+			u = next.String()
+		}
+	}
+	return nil
+}
+
+func (r *Registry) getJSON(path, scope, accept string, v interface{}) error {
+	resp, err := r.get(r.url(path), scope, accept)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// get sends a GET request, authenticating if the registry asks for it.
+func (r *Registry) get(u, scope, accept string) (*http.Response, error) {
+	resp, err := r.do(u, scope, accept)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		if err := r.authenticate(challenge, scope); err != nil {
+			return nil, err
+		}
+		if resp, err = r.do(u, scope, accept); err != nil {
+			return nil, err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("GET %s: %s", u, resp.Status)
+	}
+	return resp, nil
+}
+
+func (r *Registry) do(u, scope, accept string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	if token, ok := r.tokens[scope]; ok {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if r.Username != "" {
+		req.SetBasicAuth(r.Username, r.Password)
+	}
+	return DefaultRetryPolicy.Do(r.Client, req)
+}
+
+// challengeParam matches the parameters of a WWW-Authenticate challenge.
+var challengeParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// authenticate fetches a bearer token for scope, as asked for by challenge.
+func (r *Registry) authenticate(challenge, scope string) error {
+	if !strings.HasPrefix(challenge, "Bearer ") {
+		if r.Username == "" {
+			return errors.New("the registry requires credentials")
+		}
+		return errors.New("the registry refused the credentials")
+	}
+	params := map[string]string{}
+	for _, m := range challengeParam.FindAllStringSubmatch(challenge, -1) {
+		params[m[1]] = m[2]
+	}
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return fmt.Errorf("invalid authentication challenge %q", challenge)
+	}
+	q := realm.Query()
+	if s := params["service"]; s != "" {
+		q.Set("service", s)
+	}
+	q.Set("scope", scope)
+	realm.RawQuery = q.Encode()
+
+	req, err := http.NewRequest("GET", realm.String(), nil)
+	if err != nil {
+		return err
+	}
+	if r.Username != "" {
+		req.SetBasicAuth(r.Username, r.Password)
+	}
+	resp, err := DefaultRetryPolicy.Do(r.Client, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		b, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("cannot get a registry token: %s %s", resp.Status, strings.TrimSpace(string(b)))
+	}
+	var t struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&t); err != nil {
+		return fmt.Errorf("malformed registry token: %s", err)
+	}
+	if t.Token == "" {
+		t.Token = t.AccessToken
+	}
+	if r.tokens == nil {
+		r.tokens = map[string]string{}
+	}
+	r.tokens[scope] = t.Token
+	return nil
+}
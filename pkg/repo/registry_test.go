@@ -0,0 +1,154 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// testRegistry serves charts under "project", an image that is not a chart,
+// and a repository outside of the namespace. It requires bearer tokens,
+// which the user "helm" with the password "secret" can get.
+func testRegistry(t *testing.T) *httptest.Server {
+	charts := map[string]map[string]string{
+		"project/alpine": {"0.1.0": "0.1.0", "0.2.0": "0.2.0"},
+		"project/web":    {"1.0.0": "1.0.0"},
+	}
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if u, p, ok := r.BasicAuth(); !ok || u != "helm" || p != "secret" {
+				http.Error(w, "bad credentials", http.StatusUnauthorized)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{"token": "t/" + r.URL.Query().Get("scope")})
+			return
+		}
+
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/v2/"), "/")
+		scope := "registry:catalog:*"
+		if parts[0] != "_catalog" {
+			scope = "repository:" + parts[0] + "/" + parts[1] + ":pull"
+		}
+		if r.Header.Get("Authorization") != "Bearer t/"+scope {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test",scope="%s"`, srv.URL, scope))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch {
+		case parts[0] == "_catalog" && r.URL.Query().Get("last") == "":
+			w.Header().Set("Link", `</v2/_catalog?last=project%2Fweb&n=2>; rel="next"`)
+			json.NewEncoder(w).Encode(map[string][]string{"repositories": {"other/chart", "project/alpine"}})
+		case parts[0] == "_catalog":
+			json.NewEncoder(w).Encode(map[string][]string{"repositories": {"project/image", "project/web"}})
+		case parts[2] == "tags":
+			tags := []string{"latest"}
+			if c, ok := charts[parts[0]+"/"+parts[1]]; ok {
+				tags = []string{}
+				for tag := range c {
+					tags = append(tags, tag)
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"name": parts[0] + "/" + parts[1], "tags": tags})
+		case parts[2] == "manifests" && parts[1] == "image":
+			fmt.Fprint(w, `{"config":{"mediaType":"application/vnd.oci.image.config.v1+json","digest":"sha256:00"},"layers":[]}`)
+		case parts[2] == "manifests":
+			if r.Header.Get("Accept") != OCIManifestMediaType {
+				t.Errorf("expected an OCI manifest to be asked for, got %q", r.Header.Get("Accept"))
+			}
+			fmt.Fprintf(w, `{"config":{"mediaType":%q,"digest":"sha256:config-%s-%s"},"layers":[{"mediaType":%q,"digest":"sha256:layer-%s-%s"}],"annotations":{%q:"2016-10-03T10:15:13Z"}}`,
+				ChartConfigMediaType, parts[1], parts[3], ChartLayerMediaType, parts[1], parts[3], ociCreatedAnnotation)
+		case parts[2] == "blobs":
+			nv := strings.Split(strings.TrimPrefix(parts[3], "sha256:config-"), "-")
+			fmt.Fprintf(w, `{"name":%q,"version":%q,"description":"A chart","appVersion":"1.2"}`, nv[0], nv[1])
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	return srv
+}
+
+func TestNewRegistry(t *testing.T) {
+	r, err := NewRegistry("oci://registry.example.com:5000/team/charts/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Host != "registry.example.com:5000" || r.Namespace != "team/charts" {
+		t.Errorf("unexpected host %q and namespace %q", r.Host, r.Namespace)
+	}
+	for _, ref := range []string{"https://registry.example.com", "oci://", "oci:///project"} {
+		if _, err := NewRegistry(ref); err == nil {
+			t.Errorf("expected %q to be rejected", ref)
+		}
+	}
+}
+
+func TestRegistryIndex(t *testing.T) {
+	srv := testRegistry(t)
+	defer srv.Close()
+
+	r, err := NewRegistry("oci://" + strings.TrimPrefix(srv.URL, "http://") + "/project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.PlainHTTP = true
+	r.Username, r.Password = "helm", "secret"
+
+	i, err := r.Index()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(i.Entries) != 2 || len(i.Entries["alpine"]) != 2 || len(i.Entries["web"]) != 1 {
+		t.Fatalf("expected alpine 0.1.0 and 0.2.0 and web 1.0.0, got %v", i.Entries)
+	}
+	cv, err := i.Get("web", "1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := srv.URL + "/v2/project/web/blobs/sha256:layer-web-1.0.0"; len(cv.URLs) != 1 || cv.URLs[0] != expect {
+		t.Errorf("expected URLs [%s], got %v", expect, cv.URLs)
+	}
+	if cv.Digest != "layer-web-1.0.0" {
+		t.Errorf("expected the layer digest, got %q", cv.Digest)
+	}
+	if cv.Description != "A chart" {
+		t.Errorf("expected the chart metadata, got %v", cv.Metadata)
+	}
+	if cv.Created.Format("2006-01-02") != "2016-10-03" {
+		t.Errorf("expected the creation time of the manifest, got %s", cv.Created)
+	}
+}
+
+func TestRegistryIndexBadCredentials(t *testing.T) {
+	srv := testRegistry(t)
+	defer srv.Close()
+
+	r, _ := NewRegistry("oci://" + strings.TrimPrefix(srv.URL, "http://") + "/project")
+	r.PlainHTTP = true
+	r.Username, r.Password = "helm", "wrong"
+
+	_, err := r.Index()
+	if err == nil || !strings.Contains(err.Error(), "cannot get a registry token: 401 Unauthorized bad credentials") {
+		t.Errorf("expected the token request to fail, got %v", err)
+	}
+}
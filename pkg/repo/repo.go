@@ -48,8 +48,29 @@ type Entry struct {
 	Name  string `json:"name"`
 	Cache string `json:"cache"`
 	URL   string `json:"url"`
+	// VerifyIndex requires the index to be signed by a key in Keyring.
+	VerifyIndex bool `json:"verifyIndex,omitempty"`
+	// Keyring, if set, is the keyring that the index and the charts of the
+	// repository are verified with.
+	Keyring string `json:"keyring,omitempty"`
+	// Verify is the verification policy for the charts of the repository:
+	// VerifyAlways, VerifyIfPresent or VerifyNever. If empty, charts are
+	// verified as each command is told to.
+	Verify string `json:"verify,omitempty"`
 }
 
+// Verification policies of a repository.
+const (
+	// VerifyAlways refuses charts that do not verify, or have no provenance
+	// file.
+	VerifyAlways = "always"
+	// VerifyIfPresent verifies the charts that have a provenance file, and
+	// refuses those that do not verify.
+	VerifyIfPresent = "ifpresent"
+	// VerifyNever only verifies charts when a command is explicitly told to.
+	VerifyNever = "never"
+)
+
 // RepoFile represents the repositories.yaml file in $HELM_HOME
 type RepoFile struct {
 	APIVersion   string    `json:"apiVersion"`
@@ -158,7 +179,32 @@ func (r *RepoFile) WriteFile(path string, perm os.FileMode) error {
 	if err != nil {
 		return err
 	}
-	return ioutil.WriteFile(path, data, perm)
+	return writeFileAtomic(path, data, perm)
+}
+
+// UpdateRepositoriesFile changes the repositories file at path with fn.
+//
+// The file is locked while it is loaded, changed and written back, so that
+// concurrent updates (from parallel CI jobs, say) do not overwrite each
+// other. If fn returns an error, the file is left alone. If there is no file
+// at path, fn changes an empty one, which is then written to path.
+func UpdateRepositoriesFile(path string, fn func(*RepoFile) error) error {
+	lock, err := Lock(path, LockTimeout)
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	r, err := LoadRepositoriesFile(path)
+	if os.IsNotExist(err) {
+		r = NewRepoFile()
+	} else if err != nil && err != ErrRepoOutOfDate {
+		return err
+	}
+	if err := fn(r); err != nil {
+		return err
+	}
+	return r.WriteFile(path, 0644)
 }
 
 // LoadChartRepository loads a directory of charts as if it were a repository.
@@ -190,7 +236,7 @@ func LoadChartRepository(dir, url string) (*ChartRepository, error) {
 					return nil
 				}
 				r.IndexFile = i
-			} else if strings.HasSuffix(f.Name(), ".tgz") {
+			} else if chartutil.IsArchive(f.Name()) {
 				r.ChartPaths = append(r.ChartPaths, path)
 			}
 		}
@@ -222,7 +268,7 @@ func (r *ChartRepository) generateIndex() error {
 	}
 
 	for _, path := range r.ChartPaths {
-		ch, err := chartutil.Load(path)
+		md, err := chartutil.LoadMetadata(path)
 		if err != nil {
 			return err
 		}
@@ -232,8 +278,8 @@ func (r *ChartRepository) generateIndex() error {
 			return err
 		}
 
-		if !r.IndexFile.Has(ch.Metadata.Name, ch.Metadata.Version) {
-			r.IndexFile.Add(ch.Metadata, path, r.URL, digest)
+		if !r.IndexFile.Has(md.Name, md.Version) {
+			r.IndexFile.Add(md, path, r.URL, digest)
 		}
 		// TODO: If a chart exists, but has a different Digest, should we error?
 	}
@@ -0,0 +1,99 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy governs how HTTP requests for indexes, charts and other
+// repository content are retried when they fail for a transient reason: a
+// network error, a timeout, a 5xx response or a 429 (Too Many Requests).
+// Other responses, such as a 404, are returned on the first attempt.
+type RetryPolicy struct {
+	// Retries is the number of attempts made after the first one fails.
+	Retries int
+	// Backoff is the wait before the first retry. It doubles for each retry
+	// after that.
+	Backoff time.Duration
+	// Timeout, if positive, limits each attempt, including reading the body.
+	Timeout time.Duration
+}
+
+// DefaultRetryPolicy is the policy used by Get, and by the repository,
+// registry and chart downloads built on it. The zero value makes a single
+// attempt.
+var DefaultRetryPolicy RetryPolicy
+
+// Get fetches url with the shared Client, retrying as DefaultRetryPolicy
+// says.
+func Get(url string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return DefaultRetryPolicy.Do(Client(), req)
+}
+
+// Do sends req with client, retrying as the policy says.
+//
+// The request must not have a body, since it may be sent more than once. The
+// response to the last attempt is returned, whatever its status, unless that
+// attempt failed with an error.
+func (p RetryPolicy) Do(client *http.Client, req *http.Request) (*http.Response, error) {
+	if p.Timeout > 0 {
+		c := *client
+		c.Timeout = p.Timeout
+		client = &c
+	}
+	wait := p.Backoff
+	for attempt := 0; ; attempt++ {
+		resp, err := client.Do(req)
+		if attempt >= p.Retries || !retryable(resp, err) {
+			if err != nil && attempt > 0 {
+				err = fmt.Errorf("%s (after %d attempts)", err, attempt+1)
+			}
+			return resp, err
+		}
+		if resp != nil {
+			drain(resp)
+		}
+		retrySleep(wait)
+		wait *= 2
+	}
+}
+
+// drain reads what is left of a small response body before closing it, so
+// that the connection can be reused.
+func drain(resp *http.Response) {
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+}
+
+// retrySleep waits between attempts. Tests replace it.
+var retrySleep = time.Sleep
+
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+}
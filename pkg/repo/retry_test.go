@@ -0,0 +1,151 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repo
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordSleeps replaces retrySleep with one that records the waits instead
+// of sleeping. Calling restore puts the original back.
+func recordSleeps() (waits *[]time.Duration, restore func()) {
+	waits = &[]time.Duration{}
+	old := retrySleep
+	retrySleep = func(d time.Duration) { *waits = append(*waits, d) }
+	return waits, func() { retrySleep = old }
+}
+
+func flakyServer(failures int, status int) (*httptest.Server, *int) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		fmt.Fprint(w, "ok")
+	}))
+	return srv, &calls
+}
+
+func TestRetryPolicyRetriesTransientFailures(t *testing.T) {
+	waits, restore := recordSleeps()
+	defer restore()
+	srv, calls := flakyServer(2, http.StatusServiceUnavailable)
+	defer srv.Close()
+
+	req, _ := http.NewRequest("GET", srv.URL, nil)
+	p := RetryPolicy{Retries: 3, Backoff: time.Second}
+	resp, err := p.Do(http.DefaultClient, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if b, _ := ioutil.ReadAll(resp.Body); string(b) != "ok" {
+		t.Errorf("expected the body of the successful attempt, got %q", b)
+	}
+	if *calls != 3 {
+		t.Errorf("expected 3 attempts, got %d", *calls)
+	}
+	if expect := []time.Duration{time.Second, 2 * time.Second}; !reflect.DeepEqual(*waits, expect) {
+		t.Errorf("expected waits %v, got %v", expect, *waits)
+	}
+}
+
+func TestRetryPolicyGivesUp(t *testing.T) {
+	_, restore := recordSleeps()
+	defer restore()
+	srv, calls := flakyServer(10, http.StatusTooManyRequests)
+	defer srv.Close()
+
+	req, _ := http.NewRequest("GET", srv.URL, nil)
+	resp, err := RetryPolicy{Retries: 2}.Do(http.DefaultClient, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("expected the last response, got %s", resp.Status)
+	}
+	if *calls != 3 {
+		t.Errorf("expected 3 attempts, got %d", *calls)
+	}
+}
+
+func TestRetryPolicyDoesNotRetryClientErrors(t *testing.T) {
+	waits, restore := recordSleeps()
+	defer restore()
+	srv, calls := flakyServer(10, http.StatusNotFound)
+	defer srv.Close()
+
+	req, _ := http.NewRequest("GET", srv.URL, nil)
+	resp, err := RetryPolicy{Retries: 3}.Do(http.DefaultClient, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if *calls != 1 || len(*waits) != 0 {
+		t.Errorf("expected a 404 not to be retried, got %d attempts", *calls)
+	}
+}
+
+func TestRetryPolicyTimeout(t *testing.T) {
+	_, restore := recordSleeps()
+	defer restore()
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	req, _ := http.NewRequest("GET", srv.URL, nil)
+	_, err := RetryPolicy{Retries: 1, Timeout: 50 * time.Millisecond}.Do(http.DefaultClient, req)
+	if err == nil {
+		t.Fatal("expected the attempts to time out")
+	}
+	if !strings.Contains(err.Error(), "after 2 attempts") {
+		t.Errorf("expected the error to count the attempts, got %q", err)
+	}
+}
+
+func TestGetUsesDefaultRetryPolicy(t *testing.T) {
+	_, restore := recordSleeps()
+	defer restore()
+	old := DefaultRetryPolicy
+	DefaultRetryPolicy = RetryPolicy{Retries: 1}
+	defer func() { DefaultRetryPolicy = old }()
+
+	srv, calls := flakyServer(1, http.StatusBadGateway)
+	defer srv.Close()
+
+	resp, err := Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || *calls != 2 {
+		t.Errorf("expected a successful second attempt, got %s after %d attempts", resp.Status, *calls)
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sbom
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+
+	"k8s.io/helm/pkg/proto/hapi/chart"
+)
+
+// templateImage matches literal image references in templates. References
+// built with template actions are skipped; they are found through values.
+var templateImage = regexp.MustCompile(`(?m)^[\s-]*image:\s*["']?([^"'\s{}]+)["']?\s*$`)
+
+// Images returns the container images a chart and its dependencies reference,
+// sorted and without duplicates.
+//
+// Images are found in values as either an "image" string, or an "image" table
+// with "repository" and optional "registry" and "tag" entries. Templates are
+// searched for literal "image:" fields.
+func Images(ch *chart.Chart) []string {
+	seen := map[string]bool{}
+	var walk func(*chart.Chart)
+	walk = func(c *chart.Chart) {
+		if c.Values != nil {
+			vals := map[string]interface{}{}
+			if err := yaml.Unmarshal([]byte(c.Values.Raw), &vals); err == nil {
+				valueImages(vals, seen)
+			}
+		}
+		for _, t := range c.Templates {
+			for _, m := range templateImage.FindAllSubmatch(t.Data, -1) {
+				seen[string(m[1])] = true
+			}
+		}
+		for _, dep := range c.Dependencies {
+			walk(dep)
+		}
+	}
+	walk(ch)
+
+	images := make([]string, 0, len(seen))
+	for img := range seen {
+		images = append(images, img)
+	}
+	sort.Strings(images)
+	return images
+}
+
+func valueImages(v interface{}, seen map[string]bool) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, val := range v {
+			if k == "image" {
+				if img := imageRef(val); img != "" {
+					seen[img] = true
+					continue
+				}
+			}
+			valueImages(val, seen)
+		}
+	case []interface{}:
+		for _, val := range v {
+			valueImages(val, seen)
+		}
+	}
+}
+
+// imageRef returns the image reference an "image" value describes, or "" if
+// it does not describe one.
+func imageRef(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		if strings.Contains(v, "{{") {
+			return ""
+		}
+		return v
+	case map[string]interface{}:
+		repo, ok := v["repository"].(string)
+		if !ok || repo == "" {
+			return ""
+		}
+		if reg, ok := v["registry"].(string); ok && reg != "" {
+			repo = reg + "/" + repo
+		}
+		switch tag := v["tag"].(type) {
+		case string:
+			if tag != "" {
+				repo += ":" + tag
+			}
+		case float64:
+			repo += ":" + strconv.FormatFloat(tag, 'f', -1, 64)
+		}
+		return repo
+	}
+	return ""
+}
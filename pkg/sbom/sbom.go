@@ -0,0 +1,144 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*Package sbom generates software bills of materials for charts.
+
+A bill of materials lists a chart, the charts it depends on, and the container
+images it references, in the CycloneDX JSON format
+(https://cyclonedx.org/specification/overview/).
+*/
+package sbom // import "k8s.io/helm/pkg/sbom"
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/version"
+)
+
+// BOM is a CycloneDX bill of materials.
+type BOM struct {
+	BOMFormat   string      `json:"bomFormat"`
+	SpecVersion string      `json:"specVersion"`
+	Version     int         `json:"version"`
+	Metadata    Metadata    `json:"metadata"`
+	Components  []Component `json:"components"`
+}
+
+// Metadata describes the BOM and the chart it was generated for.
+type Metadata struct {
+	Timestamp string    `json:"timestamp"`
+	Tools     []Tool    `json:"tools"`
+	Component Component `json:"component"`
+}
+
+// Tool is the tool that generated the BOM.
+type Tool struct {
+	Vendor  string `json:"vendor"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Component is a chart or a container image.
+type Component struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	// Hashes holds the digests of the component, if known.
+	Hashes []Hash `json:"hashes,omitempty"`
+}
+
+// Hash is a digest of a component.
+type Hash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+// New generates a BOM for a chart.
+//
+// digest is the SHA-256 digest of the chart archive. digests maps
+// "name-version" of dependencies to the SHA-256 digests of their archives;
+// dependencies missing from it are listed without a digest.
+func New(ch *chart.Chart, digest string, digests map[string]string) *BOM {
+	b := &BOM{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.4",
+		Version:     1,
+		Metadata: Metadata{
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Tools:     []Tool{{Vendor: "Helm", Name: "helm", Version: version.GetVersion()}},
+			Component: chartComponent(ch.Metadata, digest),
+		},
+		Components: []Component{},
+	}
+
+	var walk func(*chart.Chart)
+	walk = func(c *chart.Chart) {
+		for _, dep := range c.Dependencies {
+			md := dep.Metadata
+			b.Components = append(b.Components, chartComponent(md, digests[md.Name+"-"+md.Version]))
+			walk(dep)
+		}
+	}
+	walk(ch)
+
+	for _, img := range Images(ch) {
+		b.Components = append(b.Components, imageComponent(img))
+	}
+	return b
+}
+
+// Write writes the BOM as indented JSON.
+func (b *BOM) Write(w io.Writer) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+func chartComponent(md *chart.Metadata, digest string) Component {
+	c := Component{Type: "application", Name: md.Name, Version: md.Version}
+	if digest != "" {
+		c.Hashes = []Hash{{Alg: "SHA-256", Content: digest}}
+	}
+	return c
+}
+
+func imageComponent(ref string) Component {
+	c := Component{Type: "container", Name: ref}
+	if i := lastIndexTag(ref); i >= 0 {
+		c.Name, c.Version = ref[:i], ref[i+1:]
+	}
+	return c
+}
+
+// lastIndexTag returns the index of the '@' or ':' separating an image name
+// from its digest or tag, or -1 if there is none. A ':' before the last '/'
+// belongs to a registry port.
+func lastIndexTag(ref string) int {
+	if i := strings.Index(ref, "@"); i >= 0 {
+		return i
+	}
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		return i
+	}
+	return -1
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"k8s.io/helm/pkg/proto/hapi/chart"
+)
+
+func testChart() *chart.Chart {
+	return &chart.Chart{
+		Metadata: &chart.Metadata{Name: "frontend", Version: "1.2.3"},
+		Values: &chart.Config{Raw: `
+image: nginx:1.11
+sidecar:
+  image:
+    registry: quay.io
+    repository: example/proxy
+    tag: 2.1
+templated:
+  image: "{{ .Values.other }}"
+`},
+		Templates: []*chart.Template{
+			{Name: "templates/job.yaml", Data: []byte(`spec:
+  containers:
+  - name: migrate
+    image: "busybox:latest"
+  - name: app
+    image: {{ .Values.image }}
+`)},
+		},
+		Dependencies: []*chart.Chart{
+			{
+				Metadata: &chart.Metadata{Name: "redis", Version: "0.4.0"},
+				Values:   &chart.Config{Raw: "image:\n  repository: redis\n  tag: \"3.2\"\n"},
+			},
+		},
+	}
+}
+
+func TestImages(t *testing.T) {
+	expect := []string{"busybox:latest", "nginx:1.11", "quay.io/example/proxy:2.1", "redis:3.2"}
+	if got := Images(testChart()); !reflect.DeepEqual(got, expect) {
+		t.Errorf("expected %v, got %v", expect, got)
+	}
+}
+
+func TestNew(t *testing.T) {
+	b := New(testChart(), "abc", map[string]string{"redis-0.4.0": "def"})
+
+	if b.BOMFormat != "CycloneDX" {
+		t.Errorf("unexpected format %q", b.BOMFormat)
+	}
+	md := b.Metadata.Component
+	if md.Name != "frontend" || md.Version != "1.2.3" || len(md.Hashes) != 1 || md.Hashes[0].Content != "abc" {
+		t.Errorf("unexpected chart component %+v", md)
+	}
+
+	expect := []Component{
+		{Type: "application", Name: "redis", Version: "0.4.0", Hashes: []Hash{{Alg: "SHA-256", Content: "def"}}},
+		{Type: "container", Name: "busybox", Version: "latest"},
+		{Type: "container", Name: "nginx", Version: "1.11"},
+		{Type: "container", Name: "quay.io/example/proxy", Version: "2.1"},
+		{Type: "container", Name: "redis", Version: "3.2"},
+	}
+	if !reflect.DeepEqual(b.Components, expect) {
+		t.Errorf("expected components %+v, got %+v", expect, b.Components)
+	}
+
+	var buf bytes.Buffer
+	if err := b.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("BOM is not valid JSON: %s", err)
+	}
+}
+
+func TestImageComponent(t *testing.T) {
+	tests := map[string][2]string{
+		"nginx":                         {"nginx", ""},
+		"nginx:1.11":                    {"nginx", "1.11"},
+		"localhost:5000/nginx":          {"localhost:5000/nginx", ""},
+		"localhost:5000/nginx:1.11":     {"localhost:5000/nginx", "1.11"},
+		"nginx@sha256:0123456789abcdef": {"nginx", "sha256:0123456789abcdef"},
+	}
+	for ref, expect := range tests {
+		c := imageComponent(ref)
+		if c.Name != expect[0] || c.Version != expect[1] {
+			t.Errorf("%s: expected %v, got %q %q", ref, expect, c.Name, c.Version)
+		}
+	}
+}
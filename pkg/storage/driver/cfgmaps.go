@@ -37,6 +37,7 @@ import (
 )
 
 var _ Driver = (*ConfigMaps)(nil)
+var _ Checker = (*ConfigMaps)(nil)
 
 // ConfigMapsDriverName is the string name of the driver.
 const ConfigMapsDriverName = "ConfigMap"
@@ -49,6 +50,10 @@ var magicGzip = []byte{0x1f, 0x8b, 0x08}
 // ConfigMapsInterface.
 type ConfigMaps struct {
 	impl client.ConfigMapsInterface
+	// KeyProvider, if set, is used to encrypt the releases that are written,
+	// and to decrypt encrypted releases that are read. Releases stored in
+	// plaintext can be read either way.
+	KeyProvider KeyProvider
 }
 
 // NewConfigMaps initializes a new ConfigMaps wrapping an implmenetation of
@@ -76,7 +81,7 @@ func (cfgmaps *ConfigMaps) Get(key string) (*rspb.Release, error) {
 		return nil, err
 	}
 	// found the configmap, decode the base64 data string
-	r, err := decodeRelease(obj.Data["release"])
+	r, err := decodeRelease(obj.Data["release"], cfgmaps.KeyProvider)
 	if err != nil {
 		logerrf(err, "get: failed to decode data %q", key)
 		return nil, err
@@ -103,7 +108,7 @@ func (cfgmaps *ConfigMaps) List(filter func(*rspb.Release) bool) ([]*rspb.Releas
 	// iterate over the configmaps object list
 	// and decode each release
 	for _, item := range list.Items {
-		rls, err := decodeRelease(item.Data["release"])
+		rls, err := decodeRelease(item.Data["release"], cfgmaps.KeyProvider)
 		if err != nil {
 			logerrf(err, "list: failed to decode release: %v", item)
 			continue
@@ -137,7 +142,7 @@ func (cfgmaps *ConfigMaps) Query(labels map[string]string) ([]*rspb.Release, err
 
 	var results []*rspb.Release
 	for _, item := range list.Items {
-		rls, err := decodeRelease(item.Data["release"])
+		rls, err := decodeRelease(item.Data["release"], cfgmaps.KeyProvider)
 		if err != nil {
 			logerrf(err, "query: failed to decode release: %s", err)
 			continue
@@ -147,6 +152,31 @@ func (cfgmaps *ConfigMaps) Query(labels map[string]string) ([]*rspb.Release, err
 	return results, nil
 }
 
+// Check describes the configmaps owned by Tiller that cannot be decoded, or
+// whose labels do not match the release they hold.
+func (cfgmaps *ConfigMaps) Check() ([]string, error) {
+	lsel := kblabels.Set{"OWNER": "TILLER"}.AsSelector()
+	list, err := cfgmaps.impl.List(api.ListOptions{LabelSelector: lsel})
+	if err != nil {
+		logerrf(err, "check: failed to list")
+		return nil, err
+	}
+
+	var problems []string
+	for _, item := range list.Items {
+		rls, err := decodeRelease(item.Data["release"], cfgmaps.KeyProvider)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("configmap %s cannot be read: %s", item.Name, err))
+			continue
+		}
+		if item.Labels["NAME"] != rls.Name || item.Labels["VERSION"] != strconv.Itoa(int(rls.Version)) {
+			problems = append(problems, fmt.Sprintf("configmap %s is labeled %s v%s, but holds %s v%d",
+				item.Name, item.Labels["NAME"], item.Labels["VERSION"], rls.Name, rls.Version))
+		}
+	}
+	return problems, nil
+}
+
 // Create creates a new ConfigMap holding the release. If the
 // ConfigMap already exists, ErrReleaseExists is returned.
 func (cfgmaps *ConfigMaps) Create(key string, rls *rspb.Release) error {
@@ -157,7 +187,7 @@ func (cfgmaps *ConfigMaps) Create(key string, rls *rspb.Release) error {
 	lbs.set("CREATED_AT", strconv.Itoa(int(time.Now().Unix())))
 
 	// create a new configmap to hold the release
-	obj, err := newConfigMapsObject(key, rls, lbs)
+	obj, err := newConfigMapsObject(key, rls, lbs, cfgmaps.KeyProvider)
 	if err != nil {
 		logerrf(err, "create: failed to encode release %q", rls.Name)
 		return err
@@ -184,7 +214,7 @@ func (cfgmaps *ConfigMaps) Update(key string, rls *rspb.Release) error {
 	lbs.set("MODIFIED_AT", strconv.Itoa(int(time.Now().Unix())))
 
 	// create a new configmap object to hold the release
-	obj, err := newConfigMapsObject(key, rls, lbs)
+	obj, err := newConfigMapsObject(key, rls, lbs, cfgmaps.KeyProvider)
 	if err != nil {
 		logerrf(err, "update: failed to encode release %q", rls.Name)
 		return err
@@ -218,7 +248,8 @@ func (cfgmaps *ConfigMaps) Delete(key string) (rls *rspb.Release, err error) {
 
 // newConfigMapsObject constructs a kubernetes ConfigMap object
 // to store a release. Each configmap data entry is the base64
-// encoded string of a release's binary protobuf encoding.
+// encoded string of a release's binary protobuf encoding, encrypted
+// with kp unless it is nil.
 //
 // The following labels are used within each configmap:
 //
@@ -229,11 +260,11 @@ func (cfgmaps *ConfigMaps) Delete(key string) (rls *rspb.Release, err error) {
 //    "OWNER"          - owner of the configmap, currently "TILLER".
 //    "NAME"           - name of the release.
 //
-func newConfigMapsObject(key string, rls *rspb.Release, lbs labels) (*api.ConfigMap, error) {
+func newConfigMapsObject(key string, rls *rspb.Release, lbs labels, kp KeyProvider) (*api.ConfigMap, error) {
 	const owner = "TILLER"
 
 	// encode the release
-	s, err := encodeRelease(rls)
+	s, err := encodeRelease(rls, kp)
 	if err != nil {
 		return nil, err
 	}
@@ -260,7 +291,10 @@ func newConfigMapsObject(key string, rls *rspb.Release, lbs labels) (*api.Config
 
 // encodeRelease encodes a release returning a base64 encoded
 // gzipped binary protobuf encoding representation, or error.
-func encodeRelease(rls *rspb.Release) (string, error) {
+//
+// If kp is not nil, the gzipped data is encrypted before it is
+// base64 encoded.
+func encodeRelease(rls *rspb.Release, kp KeyProvider) (string, error) {
 	b, err := proto.Marshal(rls)
 	if err != nil {
 		return "", err
@@ -275,20 +309,32 @@ func encodeRelease(rls *rspb.Release) (string, error) {
 	}
 	w.Close()
 
-	return b64.EncodeToString(buf.Bytes()), nil
+	b = buf.Bytes()
+	if kp != nil {
+		if b, err = seal(kp, b); err != nil {
+			return "", err
+		}
+	}
+	return b64.EncodeToString(b), nil
 }
 
 // decodeRelease decodes the bytes in data into a release
 // type. Data must contain a base64 encoded string of a
 // valid protobuf encoding of a release, otherwise
-// an error is returned.
-func decodeRelease(data string) (*rspb.Release, error) {
+// an error is returned. Encrypted data is decrypted with kp.
+func decodeRelease(data string, kp KeyProvider) (*rspb.Release, error) {
 	// base64 decode string
 	b, err := b64.DecodeString(data)
 	if err != nil {
 		return nil, err
 	}
 
+	if isSealed(b) {
+		if b, err = unseal(kp, b); err != nil {
+			return nil, err
+		}
+	}
+
 	// For backwards compatibility with releases that were stored before
 	// compression was introduced we skip decompression if the
 	// gzip magic header is not found
@@ -16,6 +16,8 @@ package driver
 import (
 	"encoding/base64"
 	"reflect"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/gogo/protobuf/proto"
@@ -57,7 +59,7 @@ func TestUNcompressedConfigMapGet(t *testing.T) {
 	rel := releaseStub(name, vers, rspb.Status_DEPLOYED)
 
 	// Create a test fixture which contains an uncompressed release
-	cfgmap, err := newConfigMapsObject(key, rel, nil)
+	cfgmap, err := newConfigMapsObject(key, rel, nil, nil)
 	if err != nil {
 		t.Fatalf("Failed to create configmap: %s", err)
 	}
@@ -128,6 +130,35 @@ func TestConfigMapList(t *testing.T) {
 	}
 }
 
+func TestConfigMapCheck(t *testing.T) {
+	cfgmaps := newTestFixtureCfgMaps(t, []*rspb.Release{
+		releaseStub("key-1", 1, rspb.Status_DEPLOYED),
+		releaseStub("key-2", 1, rspb.Status_DEPLOYED),
+		releaseStub("key-3", 1, rspb.Status_DEPLOYED),
+	}...)
+	objects := cfgmaps.impl.(*MockConfigMapsInterface).objects
+	objects["key-2.v1"].Data["release"] = "not a release"
+	objects["key-3.v1"].Labels["VERSION"] = "2"
+
+	problems, err := cfgmaps.Check()
+	if err != nil {
+		t.Fatalf("Failed to check: %s", err)
+	}
+	sort.Strings(problems)
+	expect := []string{
+		"configmap key-2.v1 cannot be read: ",
+		"configmap key-3.v1 is labeled key-3 v2, but holds key-3 v1",
+	}
+	if len(problems) != len(expect) {
+		t.Fatalf("Expected %d problems, got %q", len(expect), problems)
+	}
+	for i, p := range problems {
+		if !strings.HasPrefix(p, expect[i]) {
+			t.Errorf("Expected %q, got %q", expect[i], p)
+		}
+	}
+}
+
 func TestConfigMapCreate(t *testing.T) {
 	cfgmaps := newTestFixtureCfgMaps(t)
 
@@ -69,6 +69,14 @@ type Queryor interface {
 	Query(labels map[string]string) ([]*rspb.Release, error)
 }
 
+// Checker is implemented by drivers that can check the records they store.
+//
+// Check describes each record that cannot be read, or that is labeled for
+// another release than the one it holds. List and Query skip such records.
+type Checker interface {
+	Check() ([]string, error)
+}
+
 // Driver is the interface composed of Creator, Updator, Deletor, Queryor
 // interfaces. It defines the behavior for storing, updating, deleted,
 // and retrieving tiller releases from some underlying storage mechanism,
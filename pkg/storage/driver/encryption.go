@@ -0,0 +1,195 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver // import "k8s.io/helm/pkg/storage/driver"
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// magicEnvelope marks release payloads sealed with envelope encryption.
+var magicEnvelope = []byte("HELMENC1")
+
+// ErrNoKeyProvider indicates that an encrypted release was read by a driver
+// that has no KeyProvider configured.
+var ErrNoKeyProvider = errors.New("release is encrypted, but no key provider is configured")
+
+// KeyProvider wraps and unwraps the data keys used to encrypt release
+// payloads.
+//
+// Each release record is encrypted with its own freshly generated data key,
+// which is then wrapped by the KeyProvider and stored next to the ciphertext.
+// A KeyProvider may keep the key encryption key locally or delegate to an
+// external key management service.
+type KeyProvider interface {
+	// Name identifies the provider. It is stored with each record.
+	Name() string
+	// WrapKey encrypts a data key.
+	WrapKey(dataKey []byte) (wrapped []byte, keyID string, err error)
+	// UnwrapKey decrypts a data key previously wrapped with keyID.
+	UnwrapKey(wrapped []byte, keyID string) ([]byte, error)
+}
+
+// envelope is the stored form of an encrypted release payload.
+type envelope struct {
+	Provider string `json:"provider"`
+	KeyID    string `json:"keyID"`
+	Key      []byte `json:"key"`
+	Nonce    []byte `json:"nonce"`
+	Data     []byte `json:"data"`
+}
+
+// seal encrypts plaintext with a new data key wrapped by kp.
+func seal(kp KeyProvider, plaintext []byte) ([]byte, error) {
+	dataKey := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, err
+	}
+	nonce, data, err := aesgcmSeal(dataKey, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, keyID, err := kp.WrapKey(dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not wrap data key with %s: %s", kp.Name(), err)
+	}
+	b, err := json.Marshal(envelope{Provider: kp.Name(), KeyID: keyID, Key: wrapped, Nonce: nonce, Data: data})
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte{}, magicEnvelope...), b...), nil
+}
+
+// isSealed reports whether b was produced by seal.
+func isSealed(b []byte) bool {
+	return bytes.HasPrefix(b, magicEnvelope)
+}
+
+// unseal decrypts a payload produced by seal.
+func unseal(kp KeyProvider, b []byte) ([]byte, error) {
+	if kp == nil {
+		return nil, ErrNoKeyProvider
+	}
+	var env envelope
+	if err := json.Unmarshal(b[len(magicEnvelope):], &env); err != nil {
+		return nil, fmt.Errorf("malformed encrypted release: %s", err)
+	}
+	if env.Provider != kp.Name() {
+		return nil, fmt.Errorf("release was encrypted by key provider %q, but %q is configured", env.Provider, kp.Name())
+	}
+	dataKey, err := kp.UnwrapKey(env.Key, env.KeyID)
+	if err != nil {
+		return nil, fmt.Errorf("could not unwrap data key: %s", err)
+	}
+	return aesgcmOpen(dataKey, env.Nonce, env.Data)
+}
+
+func aesgcmSeal(key, plaintext []byte) (nonce, ciphertext []byte, err error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce = make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, nil, err
+	}
+	return nonce, gcm.Seal(nil, nonce, plaintext, nil), nil
+}
+
+func aesgcmOpen(key, nonce, ciphertext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, errors.New("malformed encrypted release: bad nonce")
+	}
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+// AESKeyProvider is a KeyProvider that wraps data keys locally with AES-GCM,
+// using 256-bit keys that are typically read from a Kubernetes Secret.
+//
+// The first key is used to wrap new data keys. The others are only used to
+// unwrap data keys of existing records, which allows keys to be rotated.
+type AESKeyProvider struct {
+	active string
+	keys   map[string][]byte
+}
+
+// NewAESKeyProvider creates an AESKeyProvider. Each key must be 32 bytes long.
+func NewAESKeyProvider(keys ...[]byte) (*AESKeyProvider, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("at least one key is required")
+	}
+	p := &AESKeyProvider{keys: map[string][]byte{}}
+	for i, k := range keys {
+		if len(k) != 32 {
+			return nil, fmt.Errorf("key %d is %d bytes long, want 32", i, len(k))
+		}
+		sum := sha256.Sum256(k)
+		id := hex.EncodeToString(sum[:8])
+		if i == 0 {
+			p.active = id
+		}
+		p.keys[id] = k
+	}
+	return p, nil
+}
+
+// Name returns the name of the provider.
+func (p *AESKeyProvider) Name() string {
+	return "aes"
+}
+
+// WrapKey encrypts a data key with the active key.
+func (p *AESKeyProvider) WrapKey(dataKey []byte) ([]byte, string, error) {
+	nonce, ciphertext, err := aesgcmSeal(p.keys[p.active], dataKey)
+	if err != nil {
+		return nil, "", err
+	}
+	return append(nonce, ciphertext...), p.active, nil
+}
+
+// UnwrapKey decrypts a data key with the key identified by keyID.
+func (p *AESKeyProvider) UnwrapKey(wrapped []byte, keyID string) ([]byte, error) {
+	k, ok := p.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", keyID)
+	}
+	// AES-GCM nonces are 12 bytes.
+	if len(wrapped) < 12 {
+		return nil, errors.New("wrapped key is too short")
+	}
+	return aesgcmOpen(k, wrapped[:12], wrapped[12:])
+}
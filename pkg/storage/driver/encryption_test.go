@@ -0,0 +1,118 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package driver
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	rspb "k8s.io/helm/pkg/proto/hapi/release"
+)
+
+func testKeyProvider(t *testing.T, keys ...string) *AESKeyProvider {
+	bs := [][]byte{}
+	for _, k := range keys {
+		bs = append(bs, []byte(k))
+	}
+	kp, err := NewAESKeyProvider(bs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kp
+}
+
+func TestEncryptedConfigMapRoundTrip(t *testing.T) {
+	var mock MockConfigMapsInterface
+	mock.Init(t)
+	cfgmaps := NewConfigMaps(&mock)
+	cfgmaps.KeyProvider = testKeyProvider(t, "0123456789abcdef0123456789abcdef")
+
+	name := "smug-pigeon"
+	key := testKey(name, 1)
+	rel := releaseStub(name, 1, rspb.Status_DEPLOYED)
+	rel.Manifest = "kind: Secret\ndata:\n  password: aHVudGVyMg==\n"
+	if err := cfgmaps.Create(key, rel); err != nil {
+		t.Fatalf("Failed to create release: %s", err)
+	}
+
+	// The stored payload must not be readable without the key.
+	raw, err := b64.DecodeString(mock.objects[key].Data["release"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isSealed(raw) || bytes.Contains(raw, []byte("aHVudGVyMg")) {
+		t.Error("Expected the stored release to be encrypted")
+	}
+	if _, err := decodeRelease(mock.objects[key].Data["release"], nil); err != ErrNoKeyProvider {
+		t.Errorf("Expected ErrNoKeyProvider, got %v", err)
+	}
+
+	got, err := cfgmaps.Get(key)
+	if err != nil {
+		t.Fatalf("Failed to get release: %s", err)
+	}
+	if !reflect.DeepEqual(rel, got) {
+		t.Errorf("Expected {%q}, got {%q}", rel, got)
+	}
+}
+
+func TestEncryptedConfigMapReadsPlaintext(t *testing.T) {
+	name := "smug-pigeon"
+	rel := releaseStub(name, 1, rspb.Status_DEPLOYED)
+	cfgmaps := newTestFixtureCfgMaps(t, rel)
+	cfgmaps.KeyProvider = testKeyProvider(t, "0123456789abcdef0123456789abcdef")
+
+	got, err := cfgmaps.Get(testKey(name, 1))
+	if err != nil {
+		t.Fatalf("Failed to get release: %s", err)
+	}
+	if !reflect.DeepEqual(rel, got) {
+		t.Errorf("Expected {%q}, got {%q}", rel, got)
+	}
+}
+
+func TestAESKeyProviderRotation(t *testing.T) {
+	oldKey, newKey := "0123456789abcdef0123456789abcdef", "fedcba9876543210fedcba9876543210"
+	rel := releaseStub("smug-pigeon", 1, rspb.Status_DEPLOYED)
+
+	data, err := encodeRelease(rel, testKeyProvider(t, oldKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := decodeRelease(data, testKeyProvider(t, newKey)); err == nil || !strings.Contains(err.Error(), "unknown key") {
+		t.Errorf("Expected an unknown key error, got %v", err)
+	}
+	got, err := decodeRelease(data, testKeyProvider(t, newKey, oldKey))
+	if err != nil {
+		t.Fatalf("Failed to decode with a rotated key: %s", err)
+	}
+	if !reflect.DeepEqual(rel, got) {
+		t.Errorf("Expected {%q}, got {%q}", rel, got)
+	}
+}
+
+func TestNewAESKeyProvider(t *testing.T) {
+	if _, err := NewAESKeyProvider(); err == nil {
+		t.Error("Expected an error without keys")
+	}
+	if _, err := NewAESKeyProvider([]byte("short")); err == nil {
+		t.Error("Expected an error for a short key")
+	}
+}
@@ -144,6 +144,11 @@ func (mem *Memory) Delete(key string) (*rspb.Release, error) {
 		}
 		if recs, ok := mem.cache[name]; ok {
 			if r := recs.Remove(key); r != nil {
+				if len(recs) == 0 {
+					delete(mem.cache, name)
+				} else {
+					mem.cache[name] = recs
+				}
 				return r.rls, nil
 			}
 		}
@@ -165,4 +165,16 @@ func TestMemoryDelete(t *testing.T) {
 			}
 		}
 	}
+	ls, err := ts.Query(map[string]string{"NAME": "rls-a"})
+	if err != nil {
+		t.Fatalf("Failed to query: %s", err)
+	}
+	if len(ls) != 3 {
+		t.Errorf("Expected 3 revisions of rls-a to be left, got %d", len(ls))
+	}
+	for _, r := range ls {
+		if r.Version == 1 {
+			t.Errorf("Expected rls-a.v1 to be deleted")
+		}
+	}
 }
@@ -0,0 +1,177 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage // import "k8s.io/helm/pkg/storage"
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+
+	rspb "k8s.io/helm/pkg/proto/hapi/release"
+	relutil "k8s.io/helm/pkg/releaseutil"
+	"k8s.io/helm/pkg/storage/driver"
+)
+
+// MaintainOptions configure Maintain.
+type MaintainOptions struct {
+	// MaxHistory is the most revisions of a release to keep. Zero keeps
+	// every revision.
+	MaxHistory int
+	// DryRun reports what would be removed without removing it.
+	DryRun bool
+}
+
+// Usage describes the records stored for one release.
+type Usage struct {
+	Name string
+	// Revisions and Bytes count the records before any were removed.
+	Revisions int
+	Bytes     int
+	Removed   int
+	// Orphaned is set if the release was purged, but some of its records
+	// were left behind.
+	Orphaned bool
+}
+
+// Report is the result of Maintain.
+type Report struct {
+	// Releases are sorted by name.
+	Releases []Usage
+	Problems []string
+}
+
+// Maintain compacts the history of every release to opts.MaxHistory
+// revisions, removes the records of purged releases, and checks the
+// integrity of the records that are stored.
+//
+// Compaction removes the oldest revisions of a release, but never its
+// deployed revision or the revisions after it, so that it can still be
+// rolled back. A release whose revisions are all superseded was purged by a
+// delete that did not finish, and its records are removed.
+func (s *Storage) Maintain(opts MaintainOptions) (*Report, error) {
+	s.logf("Maintaining storage (max history %d, dry run %t)", opts.MaxHistory, opts.DryRun)
+
+	report := &Report{}
+	if c, ok := s.Driver.(driver.Checker); ok {
+		problems, err := c.Check()
+		if err != nil {
+			return nil, err
+		}
+		report.Problems = append(report.Problems, problems...)
+	}
+
+	all, err := s.ListReleases()
+	if err != nil {
+		return nil, err
+	}
+	histories := map[string][]*rspb.Release{}
+	for _, r := range all {
+		histories[r.Name] = append(histories[r.Name], r)
+	}
+	names := make([]string, 0, len(histories))
+	for name := range histories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		h := histories[name]
+		relutil.SortByRevision(h)
+
+		u := Usage{Name: name, Revisions: len(h)}
+		for _, r := range h {
+			u.Bytes += proto.Size(r)
+		}
+		report.Problems = append(report.Problems, s.checkHistory(name, h)...)
+
+		remove := compact(h, opts.MaxHistory)
+		if orphaned(h) {
+			u.Orphaned = true
+			remove = h
+		}
+		u.Removed = len(remove)
+		if !opts.DryRun {
+			for _, r := range remove {
+				if _, err := s.Delete(r.Name, r.Version); err != nil {
+					return nil, err
+				}
+			}
+		}
+		report.Releases = append(report.Releases, u)
+	}
+	return report, nil
+}
+
+// checkHistory describes what is wrong with the records of a release, sorted
+// by revision.
+func (s *Storage) checkHistory(name string, h []*rspb.Release) []string {
+	var problems, deployed []string
+	for _, r := range h {
+		if r.Info != nil && r.Info.Status != nil && r.Info.Status.Code == rspb.Status_DEPLOYED {
+			deployed = append(deployed, fmt.Sprintf("v%d", r.Version))
+		}
+	}
+	if len(deployed) > 1 {
+		problems = append(problems, fmt.Sprintf("release %s has %d deployed revisions: %s", name, len(deployed), strings.Join(deployed, ", ")))
+	}
+
+	if s.Signer == nil {
+		return problems
+	}
+	// Records stored before signing was turned on are not signed; any
+	// unsigned record after a signed one is a problem.
+	signed := false
+	for _, c := range relutil.VerifyHistory(s.Signer, h) {
+		if c.Err == relutil.ErrUnsigned && !signed {
+			continue
+		}
+		signed = true
+		if c.Err != nil {
+			problems = append(problems, fmt.Sprintf("release %s v%d: %s", name, c.Revision, c.Err))
+		}
+	}
+	return problems
+}
+
+// compact returns the oldest revisions of h, sorted by revision, that are
+// beyond max, stopping at the deployed revision.
+func compact(h []*rspb.Release, max int) []*rspb.Release {
+	if max <= 0 || len(h) <= max {
+		return nil
+	}
+	n := len(h) - max
+	for i, r := range h[:n] {
+		if r.Info != nil && r.Info.Status != nil && r.Info.Status.Code == rspb.Status_DEPLOYED {
+			n = i
+			break
+		}
+	}
+	return h[:n]
+}
+
+// orphaned reports whether every revision of h is superseded, which is what
+// a purge that did not finish leaves behind.
+func orphaned(h []*rspb.Release) bool {
+	for _, r := range h {
+		if r.Info == nil || r.Info.Status == nil || r.Info.Status.Code != rspb.Status_SUPERSEDED {
+			return false
+		}
+	}
+	return len(h) > 0
+}
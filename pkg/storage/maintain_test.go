@@ -0,0 +1,97 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage // import "k8s.io/helm/pkg/storage"
+
+import (
+	"strings"
+	"testing"
+
+	rspb "k8s.io/helm/pkg/proto/hapi/release"
+	"k8s.io/helm/pkg/storage/driver"
+)
+
+func TestStorageMaintain(t *testing.T) {
+	storage := Init(driver.NewMemory())
+	for _, r := range []ReleaseTestData{
+		// Compacted to its last two revisions.
+		{Name: "angry-beaver", Version: 1, Status: rspb.Status_SUPERSEDED},
+		{Name: "angry-beaver", Version: 2, Status: rspb.Status_SUPERSEDED},
+		{Name: "angry-beaver", Version: 3, Status: rspb.Status_SUPERSEDED},
+		{Name: "angry-beaver", Version: 4, Status: rspb.Status_DEPLOYED},
+		// The deployed revision is kept after failed upgrades.
+		{Name: "bold-eagle", Version: 1, Status: rspb.Status_SUPERSEDED},
+		{Name: "bold-eagle", Version: 2, Status: rspb.Status_DEPLOYED},
+		{Name: "bold-eagle", Version: 3, Status: rspb.Status_FAILED},
+		{Name: "bold-eagle", Version: 4, Status: rspb.Status_FAILED},
+		// Left behind by a purge.
+		{Name: "calm-otter", Version: 1, Status: rspb.Status_SUPERSEDED},
+		{Name: "calm-otter", Version: 2, Status: rspb.Status_SUPERSEDED},
+		// Two deployed revisions.
+		{Name: "dizzy-hen", Version: 1, Status: rspb.Status_DEPLOYED},
+		{Name: "dizzy-hen", Version: 2, Status: rspb.Status_DEPLOYED},
+	} {
+		assertErrNil(t.Fatal, storage.Create(r.ToRelease()), "Storing release")
+	}
+
+	report, err := storage.Maintain(MaintainOptions{MaxHistory: 2, DryRun: true})
+	assertErrNil(t.Fatal, err, "Maintaining storage")
+	if all, _ := storage.ListReleases(); len(all) != 12 {
+		t.Errorf("expected a dry run to remove nothing, %d records are left", len(all))
+	}
+
+	report, err = storage.Maintain(MaintainOptions{MaxHistory: 2})
+	assertErrNil(t.Fatal, err, "Maintaining storage")
+
+	expect := []Usage{
+		{Name: "angry-beaver", Revisions: 4, Removed: 2},
+		{Name: "bold-eagle", Revisions: 4, Removed: 1},
+		{Name: "calm-otter", Revisions: 2, Removed: 2, Orphaned: true},
+		{Name: "dizzy-hen", Revisions: 2},
+	}
+	if len(report.Releases) != len(expect) {
+		t.Fatalf("expected %d releases, got %v", len(expect), report.Releases)
+	}
+	for i, u := range report.Releases {
+		if u.Bytes == 0 {
+			t.Errorf("expected the size of %s to be reported", u.Name)
+		}
+		u.Bytes = 0
+		if u != expect[i] {
+			t.Errorf("expected %+v, got %+v", expect[i], u)
+		}
+	}
+	if len(report.Problems) != 1 || !strings.Contains(report.Problems[0], "dizzy-hen has 2 deployed revisions: v1, v2") {
+		t.Errorf("expected the deployed revisions of dizzy-hen to be reported, got %q", report.Problems)
+	}
+
+	for name, revisions := range map[string][]int32{
+		"angry-beaver": {3, 4},
+		"bold-eagle":   {2, 3, 4},
+		"calm-otter":   nil,
+	} {
+		h, _ := storage.History(name)
+		if len(h) != len(revisions) {
+			t.Errorf("expected %s to keep revisions %v, got %d", name, revisions, len(h))
+			continue
+		}
+		for _, v := range revisions {
+			if _, err := storage.Get(name, v); err != nil {
+				t.Errorf("expected %s to keep revision %d: %s", name, v, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"time"
+
+	"k8s.io/helm/pkg/metrics"
+	rspb "k8s.io/helm/pkg/proto/hapi/release"
+	"k8s.io/helm/pkg/storage/driver"
+)
+
+var storageDuration = metrics.NewHistogram("tiller_storage_operation_duration_seconds",
+	"Time taken by operations of the release storage driver.", metrics.DefaultBuckets, "driver", "operation")
+
+// timedDriver measures the operations of a driver.
+type timedDriver struct {
+	driver.Driver
+}
+
+func (d timedDriver) observe(operation string, start time.Time) {
+	storageDuration.Observe(time.Since(start).Seconds(), d.Name(), operation)
+}
+
+func (d timedDriver) Create(key string, rls *rspb.Release) error {
+	defer d.observe("create", time.Now())
+	return d.Driver.Create(key, rls)
+}
+
+func (d timedDriver) Update(key string, rls *rspb.Release) error {
+	defer d.observe("update", time.Now())
+	return d.Driver.Update(key, rls)
+}
+
+func (d timedDriver) Delete(key string) (*rspb.Release, error) {
+	defer d.observe("delete", time.Now())
+	return d.Driver.Delete(key)
+}
+
+func (d timedDriver) Get(key string) (*rspb.Release, error) {
+	defer d.observe("get", time.Now())
+	return d.Driver.Get(key)
+}
+
+func (d timedDriver) List(filter func(*rspb.Release) bool) ([]*rspb.Release, error) {
+	defer d.observe("list", time.Now())
+	return d.Driver.List(filter)
+}
+
+func (d timedDriver) Query(labels map[string]string) ([]*rspb.Release, error) {
+	defer d.observe("query", time.Now())
+	return d.Driver.Query(labels)
+}
+
+// Check checks the records of the driver, if it can.
+func (d timedDriver) Check() ([]string, error) {
+	c, ok := d.Driver.(driver.Checker)
+	if !ok {
+		return nil, nil
+	}
+	defer d.observe("check", time.Now())
+	return c.Check()
+}
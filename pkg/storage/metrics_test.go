@@ -0,0 +1,49 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"k8s.io/helm/pkg/metrics"
+	"k8s.io/helm/pkg/storage/driver"
+)
+
+func TestStorageMetrics(t *testing.T) {
+	storage := Init(driver.NewMemory())
+	rls := ReleaseTestData{Name: "timed-beaver", Version: 1}.ToRelease()
+	assertErrNil(t.Fatal, storage.Create(rls), "StoreRelease")
+	_, err := storage.Get(rls.Name, rls.Version)
+	assertErrNil(t.Fatal, err, "QueryRelease")
+
+	if name := storage.Name(); name != "Memory" {
+		t.Errorf("expected the name of the driver, got %q", name)
+	}
+
+	b := bytes.NewBuffer(nil)
+	if err := metrics.Write(b); err != nil {
+		t.Fatal(err)
+	}
+	for _, op := range []string{"create", "get"} {
+		series := `tiller_storage_operation_duration_seconds_count{driver="Memory",operation="` + op + `"}`
+		if !strings.Contains(b.String(), series) {
+			t.Errorf("expected %s in\n%s", series, b.String())
+		}
+	}
+}
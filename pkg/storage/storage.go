@@ -21,6 +21,7 @@ import (
 	"log"
 
 	rspb "k8s.io/helm/pkg/proto/hapi/release"
+	"k8s.io/helm/pkg/provenance"
 	relutil "k8s.io/helm/pkg/releaseutil"
 	"k8s.io/helm/pkg/storage/driver"
 )
@@ -28,13 +29,29 @@ import (
 // Storage represents a storage engine for a Release.
 type Storage struct {
 	driver.Driver
+
+	// Signer, if set, signs every record stored, linking it to the record
+	// of the previous revision.
+	Signer *provenance.Signatory
+
+	// Log, if set, writes the log messages of the storage instead of
+	// log.Output, e.g. to tag them with the request they are made for.
+	Log func(calldepth int, s string) error
+}
+
+func (s *Storage) logf(format string, v ...interface{}) {
+	output := log.Output
+	if s.Log != nil {
+		output = s.Log
+	}
+	output(2, fmt.Sprintf(format, v...))
 }
 
 // Get retrieves the release from storage. An error is returned
 // if the storage driver failed to fetch the release, or the
 // release identified by the key, version pair does not exist.
 func (s *Storage) Get(name string, version int32) (*rspb.Release, error) {
-	log.Printf("Getting release %q (v%d) from storage\n", name, version)
+	s.logf("Getting release %q (v%d) from storage\n", name, version)
 	return s.Driver.Get(makeKey(name, version))
 }
 
@@ -42,7 +59,10 @@ func (s *Storage) Get(name string, version int32) (*rspb.Release, error) {
 // error is returned if the storage driver failed to store the
 // release, or a release with identical an key already exists.
 func (s *Storage) Create(rls *rspb.Release) error {
-	log.Printf("Create release %q (v%d) in storage\n", rls.Name, rls.Version)
+	s.logf("Create release %q (v%d) in storage\n", rls.Name, rls.Version)
+	if err := s.sign(rls); err != nil {
+		return err
+	}
 	return s.Driver.Create(makeKey(rls.Name, rls.Version), rls)
 }
 
@@ -50,29 +70,50 @@ func (s *Storage) Create(rls *rspb.Release) error {
 // storage backend fails to update the release or if the release
 // does not exist.
 func (s *Storage) Update(rls *rspb.Release) error {
-	log.Printf("Updating %q (v%d) in storage\n", rls.Name, rls.Version)
+	s.logf("Updating %q (v%d) in storage\n", rls.Name, rls.Version)
+	if err := s.sign(rls); err != nil {
+		return err
+	}
 	return s.Driver.Update(makeKey(rls.Name, rls.Version), rls)
 }
 
+// sign signs rls, if the storage has a Signer.
+func (s *Storage) sign(rls *rspb.Release) error {
+	if s.Signer == nil {
+		return nil
+	}
+	var prev *rspb.Release
+	if rls.Version > 1 {
+		p, err := s.Driver.Get(makeKey(rls.Name, rls.Version-1))
+		switch {
+		case err == nil:
+			prev = p
+		case err != driver.ErrReleaseNotFound:
+			return err
+		}
+	}
+	return relutil.Sign(s.Signer, rls, prev)
+}
+
 // Delete deletes the release from storage. An error is returned if
 // the storage backend fails to delete the release or if the release
 // does not exist.
 func (s *Storage) Delete(name string, version int32) (*rspb.Release, error) {
-	log.Printf("Deleting release %q (v%d) from storage\n", name, version)
+	s.logf("Deleting release %q (v%d) from storage\n", name, version)
 	return s.Driver.Delete(makeKey(name, version))
 }
 
 // ListReleases returns all releases from storage. An error is returned if the
 // storage backend fails to retrieve the releases.
 func (s *Storage) ListReleases() ([]*rspb.Release, error) {
-	log.Println("Listing all releases in storage")
+	s.logf("Listing all releases in storage")
 	return s.Driver.List(func(_ *rspb.Release) bool { return true })
 }
 
 // ListDeleted returns all releases with Status == DELETED. An error is returned
 // if the storage backend fails to retrieve the releases.
 func (s *Storage) ListDeleted() ([]*rspb.Release, error) {
-	log.Println("List deleted releases in storage")
+	s.logf("List deleted releases in storage")
 	return s.Driver.List(func(rls *rspb.Release) bool {
 		return relutil.StatusFilter(rspb.Status_DELETED).Check(rls)
 	})
@@ -81,7 +122,7 @@ func (s *Storage) ListDeleted() ([]*rspb.Release, error) {
 // ListDeployed returns all releases with Status == DEPLOYED. An error is returned
 // if the storage backend fails to retrieve the releases.
 func (s *Storage) ListDeployed() ([]*rspb.Release, error) {
-	log.Println("Listing all deployed releases in storage")
+	s.logf("Listing all deployed releases in storage")
 	return s.Driver.List(func(rls *rspb.Release) bool {
 		return relutil.StatusFilter(rspb.Status_DEPLOYED).Check(rls)
 	})
@@ -91,7 +132,7 @@ func (s *Storage) ListDeployed() ([]*rspb.Release, error) {
 // (filter0 && filter1 && ... && filterN), i.e. a Release is included in the results
 // if and only if all filters return true.
 func (s *Storage) ListFilterAll(fns ...relutil.FilterFunc) ([]*rspb.Release, error) {
-	log.Println("Listing all releases with filter")
+	s.logf("Listing all releases with filter")
 	return s.Driver.List(func(rls *rspb.Release) bool {
 		return relutil.All(fns...).Check(rls)
 	})
@@ -101,7 +142,7 @@ func (s *Storage) ListFilterAll(fns ...relutil.FilterFunc) ([]*rspb.Release, err
 // (filter0 || filter1 || ... || filterN), i.e. a Release is included in the results
 // if at least one of the filters returns true.
 func (s *Storage) ListFilterAny(fns ...relutil.FilterFunc) ([]*rspb.Release, error) {
-	log.Println("Listing any releases with filter")
+	s.logf("Listing any releases with filter")
 	return s.Driver.List(func(rls *rspb.Release) bool {
 		return relutil.Any(fns...).Check(rls)
 	})
@@ -110,7 +151,7 @@ func (s *Storage) ListFilterAny(fns ...relutil.FilterFunc) ([]*rspb.Release, err
 // Deployed returns the deployed release with the provided release name, or
 // returns ErrReleaseNotFound if not found.
 func (s *Storage) Deployed(name string) (*rspb.Release, error) {
-	log.Printf("Getting deployed release from '%s' history\n", name)
+	s.logf("Getting deployed release from '%s' history\n", name)
 
 	ls, err := s.Driver.Query(map[string]string{
 		"NAME":   name,
@@ -130,7 +171,7 @@ func (s *Storage) Deployed(name string) (*rspb.Release, error) {
 // History returns the revision history for the release with the provided name, or
 // returns ErrReleaseNotFound if no such release name exists.
 func (s *Storage) History(name string) ([]*rspb.Release, error) {
-	log.Printf("Getting release history for '%s'\n", name)
+	s.logf("Getting release history for '%s'\n", name)
 
 	l, err := s.Driver.Query(map[string]string{"NAME": name, "OWNER": "TILLER"})
 	if err != nil {
@@ -160,11 +201,12 @@ func makeKey(rlsname string, version int32) string {
 }
 
 // Init initializes a new storage backend with the driver d.
-// If d is nil, the default in-memory driver is used.
+// If d is nil, the default in-memory driver is used. The time taken by
+// the operations of d is exported as a metric.
 func Init(d driver.Driver) *Storage {
 	// default driver is in memory
 	if d == nil {
 		d = driver.NewMemory()
 	}
-	return &Storage{Driver: d}
+	return &Storage{Driver: timedDriver{d}}
 }
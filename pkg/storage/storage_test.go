@@ -22,6 +22,8 @@ import (
 	"testing"
 
 	rspb "k8s.io/helm/pkg/proto/hapi/release"
+	"k8s.io/helm/pkg/provenance"
+	relutil "k8s.io/helm/pkg/releaseutil"
 	"k8s.io/helm/pkg/storage/driver"
 )
 
@@ -249,6 +251,35 @@ func TestStorageLast(t *testing.T) {
 	}
 }
 
+func TestStorageSigned(t *testing.T) {
+	signer, err := provenance.NewFromFiles("../provenance/testdata/helm-test-key.secret", "../provenance/testdata/helm-test-key.pub")
+	assertErrNil(t.Fatal, err, "Loading signing key")
+	storage := Init(driver.NewMemory())
+	storage.Signer = signer
+
+	const name = "angry-bird"
+	rls0 := ReleaseTestData{Name: name, Version: 1, Status: rspb.Status_DEPLOYED}.ToRelease()
+	rls1 := ReleaseTestData{Name: name, Version: 2, Status: rspb.Status_DEPLOYED}.ToRelease()
+	assertErrNil(t.Fatal, storage.Create(rls0), "Storing release 'angry-bird' (v1)")
+
+	// Superseding a record and changing its manifest re-signs it.
+	rls0.Info.Status.Code = rspb.Status_SUPERSEDED
+	rls0.Manifest = "kind: ConfigMap"
+	assertErrNil(t.Fatal, storage.Update(rls0), "Updating release 'angry-bird' (v1)")
+	assertErrNil(t.Fatal, storage.Create(rls1), "Storing release 'angry-bird' (v2)")
+
+	h, err := storage.History(name)
+	assertErrNil(t.Fatal, err, "Getting release history")
+	for _, c := range relutil.VerifyHistory(signer, h) {
+		if c.Err != nil {
+			t.Errorf("revision %d: %s", c.Revision, c.Err)
+		}
+	}
+	if rls1.Signature == nil || rls1.Signature.Previous == "" {
+		t.Errorf("expected revision 2 to be linked to revision 1, got %v", rls1.Signature)
+	}
+}
+
 type ReleaseTestData struct {
 	Name      string
 	Version   int32
@@ -0,0 +1,237 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tiller
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/ghodss/yaml"
+	ctx "golang.org/x/net/context"
+
+	"k8s.io/helm/pkg/kube"
+	"k8s.io/helm/pkg/proto/hapi/release"
+	"k8s.io/helm/pkg/proto/hapi/services"
+	"k8s.io/helm/pkg/releaseutil"
+)
+
+// slotLabel is set on the Deployments of blue/green revisions, their
+// selectors and their pods. It is added to the selectors of the Services of
+// the release when they are switched over.
+const slotLabel = "helm.sh/slot"
+
+// blueGreenSleep waits out the grace period of a promotion.
+var blueGreenSleep = time.Sleep
+
+// stageBlueGreen turns updated, an upgrade of current, into a blue/green
+// revision. Its Deployments are renamed and labelled with the next slot, and
+// run next to the Deployments of current, while the Services of current are
+// kept as they are. The Services of updated are kept aside for the promotion.
+func stageBlueGreen(current, updated *release.Release) error {
+	if bg := current.Info.BlueGreen; bg != nil && bg.Phase != release.BlueGreen_PROMOTED {
+		return fmt.Errorf("release %s has a blue/green revision (v%d) that is not promoted yet", current.Name, current.Version)
+	}
+	slot := "green"
+	if bg := current.Info.BlueGreen; bg != nil && bg.Slot == "green" {
+		slot = "blue"
+	}
+
+	docs, err := releaseutil.SplitManifest(updated.Manifest)
+	if err != nil {
+		return err
+	}
+	staged := bytes.NewBuffer(nil)
+	svcs := bytes.NewBuffer(nil)
+	names := []string{}
+	for _, d := range docs {
+		if d.Kind != "Deployment" && d.Kind != "Service" {
+			staged.WriteString("\n---\n" + d.Content)
+			continue
+		}
+		var obj map[string]interface{}
+		if err := yaml.Unmarshal([]byte(d.Content), &obj); err != nil {
+			return fmt.Errorf("could not parse %s %s: %s", d.Kind, d.Name, err)
+		}
+		if d.Kind == "Service" {
+			// Services without a selector have their endpoints managed
+			// elsewhere.
+			if spec, ok := obj["spec"].(map[string]interface{}); ok {
+				if sel, ok := spec["selector"].(map[string]interface{}); ok && len(sel) > 0 {
+					sel[slotLabel] = slot
+				}
+			}
+			if err := writeDocument(svcs, d.Source, obj); err != nil {
+				return err
+			}
+			continue
+		}
+		name := d.Name + "-" + slot
+		slotted(obj, name, slot)
+		if err := writeDocument(staged, d.Source, obj); err != nil {
+			return err
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("a blue/green upgrade needs a Deployment, and the release has none")
+	}
+
+	cur, err := releaseutil.SplitManifest(current.Manifest)
+	if err != nil {
+		return err
+	}
+	for _, d := range cur {
+		if d.Kind == "Deployment" || d.Kind == "Service" {
+			staged.WriteString("\n---\n" + d.Content)
+		}
+	}
+
+	updated.Manifest = staged.String()
+	updated.Info.BlueGreen = &release.BlueGreen{
+		Phase:       release.BlueGreen_STAGED,
+		Slot:        slot,
+		Deployments: names,
+		Services:    svcs.String(),
+	}
+	return nil
+}
+
+// slotted renames the Deployment obj to name, and labels it, its selector and
+// its pods with slot.
+func slotted(obj map[string]interface{}, name, slot string) {
+	delete(obj, "status")
+	md := childMap(obj, "metadata")
+	md["name"] = name
+	childMap(md, "labels")[slotLabel] = slot
+
+	spec := childMap(obj, "spec")
+	// Without a selector, the template labels are selected, slotLabel
+	// included.
+	if sel, ok := spec["selector"].(map[string]interface{}); ok {
+		childMap(sel, "matchLabels")[slotLabel] = slot
+	}
+	tmd := childMap(childMap(spec, "template"), "metadata")
+	childMap(tmd, "labels")[slotLabel] = slot
+}
+
+// PromoteRelease switches the Services of a release over to the Deployments
+// of its blue/green revision, once they are ready, and deletes the
+// Deployments of the previous revision after the grace period.
+//
+// A promotion that was interrupted during the grace period can be run again.
+func (s *ReleaseServer) PromoteRelease(c ctx.Context, req *services.PromoteReleaseRequest) (*services.PromoteReleaseResponse, error) {
+	if !checkClientVersion(c) {
+		return nil, errIncompatibleVersion
+	}
+
+	s, err := s.forRequest(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ValidName.MatchString(req.Name) {
+		return nil, errMissingRelease
+	}
+
+	rel, err := s.env.Releases.Last(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	bg := rel.Info.BlueGreen
+	if bg == nil || bg.Phase == release.BlueGreen_PROMOTED {
+		return nil, fmt.Errorf("release %s has no blue/green revision to promote", rel.Name)
+	}
+	if code := rel.Info.Status.Code; code != release.Status_DEPLOYED {
+		return nil, fmt.Errorf("the blue/green revision of %s (v%d) is %s, and cannot be promoted", rel.Name, rel.Version, code)
+	}
+	if _, err := checkLock(rel, "promote", "", nil); err != nil {
+		return nil, err
+	}
+	if err := s.checkGates(rel, "promote", ""); err != nil {
+		return nil, err
+	}
+
+	docs, err := releaseutil.SplitManifest(rel.Manifest)
+	if err != nil {
+		return nil, err
+	}
+	inSlot := map[string]bool{}
+	for _, n := range bg.Deployments {
+		inSlot[n] = true
+	}
+	ready := bytes.NewBuffer(nil)
+	switched := bytes.NewBuffer(nil)
+	promoted := bytes.NewBuffer(nil)
+	for _, d := range docs {
+		switch {
+		case d.Kind == "Service":
+			// Replaced by the Services of the revision.
+		case d.Kind == "Deployment" && !inSlot[d.Name]:
+			switched.WriteString("\n---\n" + d.Content)
+		default:
+			switched.WriteString("\n---\n" + d.Content)
+			promoted.WriteString("\n---\n" + d.Content)
+			if d.Kind == "Deployment" {
+				ready.WriteString("\n---\n" + d.Content)
+			}
+		}
+	}
+	switched.WriteString(bg.Services)
+	promoted.WriteString(bg.Services)
+
+	// The Services of the revision are only applied now, so the policy
+	// reviews the release as it is switched over.
+	review := *rel
+	review.Manifest = switched.String()
+	if err := s.checkPolicy(&review, "promote"); err != nil {
+		return nil, err
+	}
+
+	opts := kube.WaitOptions{Timeout: time.Duration(req.Timeout) * time.Second}
+	if err := s.env.KubeClient.Wait(rel.Namespace, ready, opts); err != nil {
+		return nil, fmt.Errorf("the Deployments of %s (v%d) are not ready: %s", rel.Name, rel.Version, err)
+	}
+
+	kubeCli := s.kubeClientFor(rel)
+	if bg.Phase == release.BlueGreen_STAGED {
+		s.logf("Switching the Services of %s over to slot %s", rel.Name, bg.Slot)
+		if err := kubeCli.Update(rel.Namespace, bytes.NewBufferString(rel.Manifest), bytes.NewBufferString(switched.String())); err != nil {
+			return nil, err
+		}
+		rel.Manifest = switched.String()
+		bg.Phase = release.BlueGreen_SWITCHED
+		if err := s.env.Releases.Update(rel); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.GracePeriod > 0 {
+		s.logf("Keeping the previous Deployments of %s for %ds", rel.Name, req.GracePeriod)
+		blueGreenSleep(time.Duration(req.GracePeriod) * time.Second)
+	}
+	if err := kubeCli.Update(rel.Namespace, bytes.NewBufferString(rel.Manifest), bytes.NewBufferString(promoted.String())); err != nil {
+		return nil, err
+	}
+	rel.Manifest = promoted.String()
+	bg.Phase = release.BlueGreen_PROMOTED
+	bg.Services = ""
+	if err := s.env.Releases.Update(rel); err != nil {
+		return nil, err
+	}
+	return &services.PromoteReleaseResponse{Release: rel}, nil
+}
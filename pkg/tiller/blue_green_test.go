@@ -0,0 +1,188 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tiller
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/release"
+	"k8s.io/helm/pkg/proto/hapi/services"
+	"k8s.io/helm/pkg/tiller/environment"
+)
+
+var blueGreenService = `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  selector:
+    app: web
+`
+
+// updateRecordingKubeClient records the target manifest of each update.
+type updateRecordingKubeClient struct {
+	environment.PrintingKubeClient
+	targets []string
+}
+
+func (u *updateRecordingKubeClient) Update(ns string, currentReader, modifiedReader io.Reader) error {
+	b, err := ioutil.ReadAll(modifiedReader)
+	u.targets = append(u.targets, string(b))
+	return err
+}
+
+func TestStageBlueGreen(t *testing.T) {
+	current := releaseStub()
+	current.Manifest = "---\n# Source: hello/templates/deployment.yaml\n" + canaryDeployment + "\n---\n# Source: hello/templates/service.yaml\n" + blueGreenService
+	updated := releaseStub()
+	updated.Version = 2
+	updated.Manifest = "---\n# Source: hello/templates/deployment.yaml\n" + strings.Replace(canaryDeployment, "web:2.0", "web:3.0", 1) +
+		"\n---\n# Source: hello/templates/service.yaml\n" + blueGreenService +
+		"\n---\n# Source: hello/templates/cm.yaml\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: web\n"
+
+	if err := stageBlueGreen(current, updated); err != nil {
+		t.Fatal(err)
+	}
+	bg := updated.Info.BlueGreen
+	if bg.Phase != release.BlueGreen_STAGED || bg.Slot != "green" || len(bg.Deployments) != 1 || bg.Deployments[0] != "web-green" {
+		t.Errorf("unexpected blue/green rollout %v", bg)
+	}
+	for _, want := range []string{
+		// The new Deployment, in its slot,
+		"  name: web-green\n",
+		"      helm.sh/slot: green\n",
+		"image: web:3.0\n",
+		// next to the current one,
+		"  name: web\n  labels:\n    app: web\n",
+		"image: web:2.0\n",
+		// the Service as it is,
+		blueGreenService,
+		// and the other resources of the upgrade.
+		"kind: ConfigMap\n",
+	} {
+		if !strings.Contains(updated.Manifest, want) {
+			t.Errorf("expected the staged manifest to contain %q, got:\n%s", want, updated.Manifest)
+		}
+	}
+	if !strings.Contains(bg.Services, "    app: web\n    helm.sh/slot: green\n") {
+		t.Errorf("expected the Service to select the slot once promoted, got:\n%s", bg.Services)
+	}
+
+	// The next rollout takes the other slot,
+	bg.Phase = release.BlueGreen_PROMOTED
+	next := releaseStub()
+	next.Manifest = updated.Manifest
+	if err := stageBlueGreen(updated, next); err != nil {
+		t.Fatal(err)
+	}
+	if next.Info.BlueGreen.Slot != "blue" {
+		t.Errorf("expected the blue slot, got %q", next.Info.BlueGreen.Slot)
+	}
+	// but not before the last one was promoted.
+	if err := stageBlueGreen(next, releaseStub()); err == nil || !strings.Contains(err.Error(), "not promoted yet") {
+		t.Errorf("expected an unpromoted revision to be refused, got %v", err)
+	}
+}
+
+func TestPromoteRelease(t *testing.T) {
+	var slept time.Duration
+	blueGreenSleep = func(d time.Duration) { slept = d }
+	defer func() { blueGreenSleep = time.Sleep }()
+
+	rs := rsFixture()
+	kc := &updateRecordingKubeClient{PrintingKubeClient: environment.PrintingKubeClient{Out: ioutil.Discard}}
+	rs.env.KubeClient = kc
+	rel := releaseStub()
+	rel.Manifest = "---\n# Source: hello/templates/deployment.yaml\n" + strings.Replace(canaryDeployment, "extensions/v1beta1", "v1", 1)
+	rs.env.Releases.Create(rel)
+
+	req := &services.UpdateReleaseRequest{Name: rel.Name, Chart: canaryChart(), BlueGreen: true}
+	if _, err := rs.UpdateRelease(helm.NewContext(), req); err != nil {
+		t.Fatalf("Failed upgrade: %s", err)
+	}
+	staged, _ := rs.env.Releases.Get(rel.Name, 2)
+	if staged.Info.Status.Code != release.Status_DEPLOYED || staged.Info.BlueGreen.Phase != release.BlueGreen_STAGED {
+		t.Fatalf("expected a staged revision, got %s with %v", staged.Info.Status.Code, staged.Info.BlueGreen)
+	}
+	if strings.Contains(staged.Manifest, "kind: Service") {
+		t.Errorf("expected the new Service to wait for the promotion, got:\n%s", staged.Manifest)
+	}
+
+	res, err := rs.PromoteRelease(helm.NewContext(), &services.PromoteReleaseRequest{Name: rel.Name, GracePeriod: 30})
+	if err != nil {
+		t.Fatalf("Failed promotion: %s", err)
+	}
+	if slept != 30*time.Second {
+		t.Errorf("expected a grace period of 30s, got %s", slept)
+	}
+	if len(kc.targets) != 3 {
+		t.Fatalf("expected the upgrade, the switch and the teardown, got %d updates", len(kc.targets))
+	}
+	// The previous Deployment is the only one as it was written in the chart.
+	previous := "  name: web\n  labels:\n"
+	switched, promoted := kc.targets[1], kc.targets[2]
+	if !strings.Contains(switched, "helm.sh/slot: green") || !strings.Contains(switched, "kind: Service") || !strings.Contains(switched, previous) {
+		t.Errorf("expected the Service to be switched with both Deployments running, got:\n%s", switched)
+	}
+	if strings.Contains(promoted, previous) || !strings.Contains(promoted, "  name: web-green\n") {
+		t.Errorf("expected the previous Deployment to be torn down, got:\n%s", promoted)
+	}
+
+	if res.Release.Info.BlueGreen.Phase != release.BlueGreen_PROMOTED || res.Release.Manifest != promoted {
+		t.Errorf("expected a promoted revision, got %v", res.Release.Info.BlueGreen)
+	}
+	if _, err := rs.PromoteRelease(helm.NewContext(), &services.PromoteReleaseRequest{Name: rel.Name}); err == nil {
+		t.Error("expected a promoted release not to be promoted again")
+	}
+}
+
+func TestPromoteReleasePolicyViolation(t *testing.T) {
+	rs := rsFixture()
+	kc := &updateRecordingKubeClient{PrintingKubeClient: environment.PrintingKubeClient{Out: ioutil.Discard}}
+	rs.env.KubeClient = kc
+	rel := releaseStub()
+	rel.Manifest = "---\n# Source: hello/templates/deployment.yaml\n" + strings.Replace(canaryDeployment, "extensions/v1beta1", "v1", 1)
+	rs.env.Releases.Create(rel)
+
+	req := &services.UpdateReleaseRequest{Name: rel.Name, Chart: canaryChart(), BlueGreen: true}
+	if _, err := rs.UpdateRelease(helm.NewContext(), req); err != nil {
+		t.Fatalf("Failed upgrade: %s", err)
+	}
+
+	// The Service is held back until the promotion, which must have it reviewed.
+	deny := &denyKind{kind: "Service"}
+	rs.env.Policy = deny
+	_, err := rs.PromoteRelease(helm.NewContext(), &services.PromoteReleaseRequest{Name: rel.Name})
+	if err == nil || !strings.Contains(err.Error(), "violates policy") {
+		t.Fatalf("expected the promotion to be rejected, got %v", err)
+	}
+	if len(deny.reviews) != 1 || deny.reviews[0].Release.Operation != "promote" {
+		t.Errorf("expected one review of the promotion, got %v", deny.reviews)
+	}
+	if len(kc.targets) != 1 {
+		t.Errorf("expected only the upgrade to be applied, got %d updates", len(kc.targets))
+	}
+	staged, _ := rs.env.Releases.Get(rel.Name, 2)
+	if staged.Info.BlueGreen.Phase != release.BlueGreen_STAGED {
+		t.Errorf("expected the revision to stay staged, got %v", staged.Info.BlueGreen.Phase)
+	}
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tiller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/ghodss/yaml"
+
+	"k8s.io/helm/pkg/kube"
+	"k8s.io/helm/pkg/proto/hapi/release"
+	"k8s.io/helm/pkg/proto/hapi/services"
+	"k8s.io/helm/pkg/releaseutil"
+)
+
+// canaryLabel is set on the canary Deployments, their selectors and their
+// pods. The pods keep the labels of the pods of the Deployment they are a
+// canary of, so that Services send them their share of the traffic.
+const canaryLabel = "helm.sh/canary"
+
+// canarySuffix is appended to the names of Deployments to name their
+// canaries.
+const canarySuffix = "-canary"
+
+// canaryClient is the HTTP client used to check canaries. Upgrades wait on
+// it, so it must not hang.
+var canaryClient = &http.Client{Timeout: 30 * time.Second}
+
+// canarySleep waits while a canary is watched.
+var canarySleep = time.Sleep
+
+// canaryCheck is posted to the check URL of a canary.
+type canaryCheck struct {
+	Release     string   `json:"release"`
+	Namespace   string   `json:"namespace"`
+	Revision    int32    `json:"revision"`
+	Percent     int32    `json:"percent"`
+	Deployments []string `json:"deployments"`
+}
+
+// canaryManifest returns the manifest of a canary Deployment for each
+// Deployment in manifest, running percent of its replicas (at least one), and
+// the names of the canaries.
+func canaryManifest(manifest string, percent int32) (string, []string, error) {
+	if percent < 1 || percent > 100 {
+		return "", nil, fmt.Errorf("canary percentage must be between 1 and 100, not %d", percent)
+	}
+	docs, err := releaseutil.SplitManifest(manifest)
+	if err != nil {
+		return "", nil, err
+	}
+
+	b := bytes.NewBuffer(nil)
+	names := []string{}
+	for _, d := range docs {
+		if d.Kind != "Deployment" {
+			continue
+		}
+		var obj map[string]interface{}
+		if err := yaml.Unmarshal([]byte(d.Content), &obj); err != nil {
+			return "", nil, fmt.Errorf("could not parse Deployment %s: %s", d.Name, err)
+		}
+		name := d.Name + canarySuffix
+		canaryOf(obj, name, percent)
+		if err := writeDocument(b, d.Source, obj); err != nil {
+			return "", nil, err
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return "", nil, fmt.Errorf("a canary needs a Deployment, and the release has none")
+	}
+	return b.String(), names, nil
+}
+
+// canaryOf turns the Deployment obj into its canary, named name.
+func canaryOf(obj map[string]interface{}, name string, percent int32) {
+	delete(obj, "status")
+	md := childMap(obj, "metadata")
+	md["name"] = name
+	childMap(md, "labels")[canaryLabel] = "true"
+
+	spec := childMap(obj, "spec")
+	replicas := 1.0
+	if r, ok := spec["replicas"].(float64); ok && r > 0 {
+		replicas = r
+	}
+	spec["replicas"] = math.Max(1, math.Ceil(replicas*float64(percent)/100))
+
+	// Without a selector, the template labels are selected, canaryLabel
+	// included.
+	if sel, ok := spec["selector"].(map[string]interface{}); ok {
+		childMap(sel, "matchLabels")[canaryLabel] = "true"
+	}
+	tmd := childMap(childMap(spec, "template"), "metadata")
+	childMap(tmd, "labels")[canaryLabel] = "true"
+}
+
+// writeDocument appends obj to the manifest in b, rendered from source.
+func writeDocument(b *bytes.Buffer, source string, obj map[string]interface{}) error {
+	out, err := yaml.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	b.WriteString("\n---\n")
+	if source != "" {
+		b.WriteString(sourcePrefix + source + "\n")
+	}
+	b.Write(out)
+	return nil
+}
+
+// childMap returns the map under key of m, adding an empty one if there is
+// none.
+func childMap(m map[string]interface{}, key string) map[string]interface{} {
+	c, ok := m[key].(map[string]interface{})
+	if !ok {
+		c = map[string]interface{}{}
+		m[key] = c
+	}
+	return c
+}
+
+// runCanary rolls out the canary Deployments in manifest for the upgrade of
+// r, and returns why they did not pass, if they did not. The revision is
+// recorded as each phase of the canary starts.
+func (s *ReleaseServer) runCanary(r *release.Release, manifest string, names []string, req *services.UpdateReleaseRequest) error {
+	r.Info.Status.Code = release.Status_UNKNOWN
+	r.Info.Canary = &release.Canary{Percent: req.CanaryPercent, Deployments: names}
+	s.recordRelease(r, false)
+
+	s.logf("Rolling out canary of %s: %v", r.Name, names)
+	if err := s.kubeClientFor(r).Create(r.Namespace, bytes.NewBufferString(manifest)); err != nil {
+		return err
+	}
+	opts := kube.WaitOptions{Timeout: time.Duration(req.Timeout) * time.Second}
+	if err := s.env.KubeClient.Wait(r.Namespace, bytes.NewBufferString(manifest), opts); err != nil {
+		return fmt.Errorf("the canary did not become ready: %s", err)
+	}
+
+	r.Info.Canary.Phase = release.Canary_VERIFYING
+	s.recordRelease(r, true)
+	if req.CanaryDuration > 0 {
+		s.logf("Watching canary of %s for %ds", r.Name, req.CanaryDuration)
+		canarySleep(time.Duration(req.CanaryDuration) * time.Second)
+		if err := s.env.KubeClient.Wait(r.Namespace, bytes.NewBufferString(manifest), opts); err != nil {
+			return fmt.Errorf("the canary failed while it was watched: %s", err)
+		}
+	}
+	if req.CanaryCheckUrl != "" {
+		return checkCanary(req.CanaryCheckUrl, &canaryCheck{
+			Release:     r.Name,
+			Namespace:   r.Namespace,
+			Revision:    r.Version,
+			Percent:     req.CanaryPercent,
+			Deployments: names,
+		})
+	}
+	return nil
+}
+
+// checkCanary posts c to url, which passes the canary by answering 200 OK.
+func checkCanary(url string, c *canaryCheck) error {
+	body, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	resp, err := canaryClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("the canary could not be checked: %s", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	b, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	if msg := bytes.TrimSpace(b); len(msg) > 0 {
+		return fmt.Errorf("the canary check answered %s: %s", resp.Status, msg)
+	}
+	return fmt.Errorf("the canary check answered %s", resp.Status)
+}
+
+// removeCanary deletes the canary Deployments in manifest.
+func (s *ReleaseServer) removeCanary(r *release.Release, manifest string) {
+	if err := s.kubeClientFor(r).Delete(r.Namespace, bytes.NewBufferString(manifest)); err != nil {
+		s.logf("warning: Failed to delete the canary of %s: %s", r.Name, err)
+	}
+}
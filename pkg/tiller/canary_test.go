@@ -0,0 +1,221 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tiller
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/kube"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/proto/hapi/release"
+	"k8s.io/helm/pkg/proto/hapi/services"
+	"k8s.io/helm/pkg/tiller/environment"
+)
+
+var canaryDeployment = `apiVersion: extensions/v1beta1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app: web
+spec:
+  replicas: 10
+  selector:
+    matchLabels:
+      app: web
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: web
+        image: web:2.0
+`
+
+// canaryKubeClient records the resources created and deleted, and how often
+// resources are updated.
+type canaryKubeClient struct {
+	environment.PrintingKubeClient
+	created, deleted string
+	updates          int
+	waitErr          error
+}
+
+func (c *canaryKubeClient) Create(ns string, r io.Reader) error {
+	b, err := ioutil.ReadAll(r)
+	c.created += string(b)
+	return err
+}
+
+func (c *canaryKubeClient) Delete(ns string, r io.Reader) error {
+	b, err := ioutil.ReadAll(r)
+	c.deleted += string(b)
+	return err
+}
+
+func (c *canaryKubeClient) Update(ns string, currentReader, modifiedReader io.Reader) error {
+	c.updates++
+	return nil
+}
+
+func (c *canaryKubeClient) Wait(ns string, r io.Reader, opts kube.WaitOptions) error {
+	return c.waitErr
+}
+
+func canaryChart() *chart.Chart {
+	// Only v1 is available without a cluster.
+	deployment := strings.Replace(canaryDeployment, "extensions/v1beta1", "v1", 1)
+	return &chart.Chart{
+		Metadata: &chart.Metadata{Name: "hello"},
+		Templates: []*chart.Template{
+			{Name: "templates/deployment.yaml", Data: []byte(deployment)},
+			{Name: "templates/service.yaml", Data: []byte("apiVersion: v1\nkind: Service\nmetadata:\n  name: web\nspec:\n  selector:\n    app: web\n")},
+		},
+	}
+}
+
+func TestCanaryManifest(t *testing.T) {
+	m, names, err := canaryManifest("# Source: hello/templates/deployment.yaml\n"+canaryDeployment+"\n---\napiVersion: v1\nkind: Service\nmetadata:\n  name: web\n", 25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 1 || names[0] != "web-canary" {
+		t.Errorf("unexpected canaries %v", names)
+	}
+	for _, want := range []string{
+		"# Source: hello/templates/deployment.yaml\n",
+		"  name: web-canary\n",
+		"  replicas: 3\n",
+		"    matchLabels:\n      app: web\n      helm.sh/canary: \"true\"\n",
+		"image: web:2.0\n",
+	} {
+		if !strings.Contains(m, want) {
+			t.Errorf("expected the canary to contain %q, got:\n%s", want, m)
+		}
+	}
+	if strings.Contains(m, "kind: Service") {
+		t.Errorf("expected only Deployments to get a canary, got:\n%s", m)
+	}
+
+	if _, _, err := canaryManifest("apiVersion: v1\nkind: Service\nmetadata:\n  name: web\n", 25); err == nil {
+		t.Error("expected a release without Deployments to fail")
+	}
+	if _, _, err := canaryManifest(canaryDeployment, 120); err == nil {
+		t.Error("expected a percentage over 100 to fail")
+	}
+}
+
+func TestUpdateReleaseCanaryPromoted(t *testing.T) {
+	var checked canaryCheck
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&checked)
+	}))
+	defer srv.Close()
+
+	rs := rsFixture()
+	kc := &canaryKubeClient{PrintingKubeClient: environment.PrintingKubeClient{Out: ioutil.Discard}}
+	rs.env.KubeClient = kc
+	rel := releaseStub()
+	rs.env.Releases.Create(rel)
+
+	req := &services.UpdateReleaseRequest{
+		Name:           rel.Name,
+		Chart:          canaryChart(),
+		CanaryPercent:  20,
+		CanaryCheckUrl: srv.URL,
+	}
+	res, err := rs.UpdateRelease(helm.NewContext(), req)
+	if err != nil {
+		t.Fatalf("Failed upgrade: %s", err)
+	}
+
+	if !strings.Contains(kc.created, "name: web-canary") || !strings.Contains(kc.created, "replicas: 2") {
+		t.Errorf("expected the canary to be created, got:\n%s", kc.created)
+	}
+	if kc.deleted != kc.created {
+		t.Errorf("expected the canary to be deleted, got:\n%s", kc.deleted)
+	}
+	if kc.updates != 1 {
+		t.Errorf("expected the release to be updated once, got %d", kc.updates)
+	}
+	if checked.Release != rel.Name || checked.Revision != 2 || checked.Percent != 20 || len(checked.Deployments) != 1 {
+		t.Errorf("unexpected canary check %+v", checked)
+	}
+
+	stored, err := rs.env.Releases.Get(rel.Name, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Info.Status.Code != release.Status_DEPLOYED || stored.Info.Canary.Phase != release.Canary_PROMOTED {
+		t.Errorf("expected a promoted, deployed revision, got %s with %v", stored.Info.Status.Code, stored.Info.Canary)
+	}
+	if res.Release.Info.Canary.Percent != 20 {
+		t.Errorf("expected the canary to be returned, got %v", res.Release.Info.Canary)
+	}
+}
+
+func TestUpdateReleaseCanaryRolledBack(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "error rate 12%", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	rs := rsFixture()
+	kc := &canaryKubeClient{PrintingKubeClient: environment.PrintingKubeClient{Out: ioutil.Discard}}
+	rs.env.KubeClient = kc
+	rel := releaseStub()
+	rs.env.Releases.Create(rel)
+
+	req := &services.UpdateReleaseRequest{
+		Name:           rel.Name,
+		Chart:          canaryChart(),
+		CanaryPercent:  20,
+		CanaryCheckUrl: srv.URL,
+	}
+	_, err := rs.UpdateRelease(helm.NewContext(), req)
+	if err == nil || !strings.Contains(err.Error(), "error rate 12%") {
+		t.Fatalf("expected the canary to fail, got %v", err)
+	}
+	if kc.updates != 0 {
+		t.Errorf("expected the release not to be updated, got %d updates", kc.updates)
+	}
+	if kc.deleted == "" || kc.deleted != kc.created {
+		t.Errorf("expected the canary to be deleted, got:\n%s", kc.deleted)
+	}
+
+	stored, err := rs.env.Releases.Get(rel.Name, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Info.Status.Code != release.Status_FAILED || stored.Info.Canary.Phase != release.Canary_ROLLED_BACK {
+		t.Errorf("expected a rolled back, failed revision, got %s with %v", stored.Info.Status.Code, stored.Info.Canary)
+	}
+	if !strings.Contains(stored.Info.Canary.Message, "error rate 12%") {
+		t.Errorf("expected the reason to be recorded, got %q", stored.Info.Canary.Message)
+	}
+	if current, _ := rs.env.Releases.Get(rel.Name, 1); current.Info.Status.Code != release.Status_DEPLOYED {
+		t.Errorf("expected the current revision to stay deployed, got %s", current.Info.Status.Code)
+	}
+}
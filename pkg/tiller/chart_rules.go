@@ -0,0 +1,282 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tiller
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/ghodss/yaml"
+	"github.com/golang/protobuf/proto"
+
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/proto/hapi/services"
+	"k8s.io/helm/pkg/provenance"
+)
+
+// ChartRules restrict the charts Tiller installs, upgrades and rolls back to,
+// whatever the client asks for.
+//
+// A chart that matches a deny rule is rejected. If there are allow rules, a
+// chart must also match one of them. If any rule matches repositories, charts
+// whose source the client does not report are rejected.
+type ChartRules struct {
+	// Keyring is the keyring file that chart signatures are verified with.
+	Keyring string      `json:"keyring"`
+	Allow   []ChartRule `json:"allow"`
+	Deny    []ChartRule `json:"deny"`
+
+	signatory *provenance.Signatory
+	// matchesRepositories is set if any rule matches repositories.
+	matchesRepositories bool
+}
+
+// ChartRule matches charts. A rule matches the charts that match all of its
+// fields; fields left empty match any chart.
+type ChartRule struct {
+	// Repository is a glob matched against the URL the chart archive was
+	// downloaded from, e.g. "https://charts.example.com/**". As in Chart, "*"
+	// does not match "/", but "**" does. The URL is the one reported by the
+	// client, so it is advisory: only SignedBy proves where a chart is from.
+	Repository string `json:"repository"`
+	// Chart is a glob matched against the chart name.
+	Chart string `json:"chart"`
+	// Version is a SemVer constraint on the chart version, e.g. ">= 1.2.0".
+	Version string `json:"version"`
+	// SignedBy matches charts with a valid signature by a key of the keyring
+	// whose identity or fingerprint contains one of these strings, ignoring
+	// case, e.g. an email address or a key ID.
+	SignedBy []string `json:"signedBy"`
+
+	repository *regexp.Regexp
+	version    *semver.Constraints
+}
+
+// LoadChartRules reads chart rules from a YAML file.
+func LoadChartRules(filename string) (*ChartRules, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	r := &ChartRules{}
+	if err := yaml.Unmarshal(b, r); err != nil {
+		return nil, fmt.Errorf("cannot parse chart rules %s: %s", filename, err)
+	}
+
+	needsKeyring := false
+	for _, rules := range [][]ChartRule{r.Allow, r.Deny} {
+		for i := range rules {
+			rule := &rules[i]
+			if rule.Repository != "" {
+				re, err := globRegexp(rule.Repository)
+				if err != nil {
+					return nil, fmt.Errorf("invalid repository pattern %q: %s", rule.Repository, err)
+				}
+				rule.repository = re
+				r.matchesRepositories = true
+			}
+			if rule.Chart != "" {
+				if _, err := path.Match(rule.Chart, ""); err != nil {
+					return nil, fmt.Errorf("invalid chart pattern %q: %s", rule.Chart, err)
+				}
+			}
+			if rule.Version != "" {
+				c, err := semver.NewConstraint(rule.Version)
+				if err != nil {
+					return nil, fmt.Errorf("invalid version constraint %q: %s", rule.Version, err)
+				}
+				rule.version = c
+			}
+			needsKeyring = needsKeyring || len(rule.SignedBy) > 0
+		}
+	}
+
+	if r.Keyring != "" {
+		if r.signatory, err = provenance.NewFromKeyring(r.Keyring, ""); err != nil {
+			return nil, fmt.Errorf("cannot load keyring %s: %s", r.Keyring, err)
+		}
+	} else if needsKeyring {
+		return nil, fmt.Errorf("chart rules %s match signers, but have no keyring", filename)
+	}
+	return r, nil
+}
+
+// chartCandidate is what chart rules are matched against.
+type chartCandidate struct {
+	name    string
+	version string
+	url     string
+	// signers holds the identities and fingerprint of the key that signed
+	// the chart, if its signature was verified.
+	signers []string
+}
+
+// check returns an error if the rules do not allow the chart, which came from
+// src.
+func (r *ChartRules) check(ch *chart.Chart, src *services.ChartSource) error {
+	if ch == nil || ch.Metadata == nil {
+		// Missing charts are rejected by the release logic.
+		return nil
+	}
+	c, err := r.candidate(ch, src)
+	if err != nil {
+		return err
+	}
+	// A deny rule on a repository must not be escaped by not reporting one.
+	if r.matchesRepositories && c.url == "" {
+		return fmt.Errorf("chart %s-%s has no known source, which Tiller's chart rules require", c.name, c.version)
+	}
+
+	for _, rule := range r.Deny {
+		if rule.matches(c) {
+			return fmt.Errorf("chart %s-%s is denied by Tiller's chart rules", c.name, c.version)
+		}
+	}
+	if len(r.Allow) == 0 {
+		return nil
+	}
+	for _, rule := range r.Allow {
+		if rule.matches(c) {
+			return nil
+		}
+	}
+	return fmt.Errorf("chart %s-%s is not allowed by Tiller's chart rules", c.name, c.version)
+}
+
+// candidate describes a chart, verifying its signature, if it has one.
+func (r *ChartRules) candidate(ch *chart.Chart, src *services.ChartSource) (*chartCandidate, error) {
+	c := &chartCandidate{name: ch.Metadata.Name, version: ch.Metadata.Version}
+	if src == nil {
+		return c, nil
+	}
+	c.url = src.Url
+	if len(src.Provenance) == 0 || r.signatory == nil {
+		return c, nil
+	}
+
+	ver, err := r.signatory.VerifyArchive(src.ArchiveName, src.Archive, src.Provenance)
+	if err != nil {
+		return nil, fmt.Errorf("cannot verify chart %s-%s: %s", c.name, c.version, err)
+	}
+	// The signature covers the archive, so the chart must be the one in it.
+	signed, err := chartutil.LoadArchive(bytes.NewReader(src.Archive))
+	if err != nil {
+		return nil, fmt.Errorf("cannot load the archive of chart %s-%s: %s", c.name, c.version, err)
+	}
+	if !proto.Equal(signed, ch) {
+		return nil, fmt.Errorf("chart %s-%s differs from its signed archive", c.name, c.version)
+	}
+
+	for id := range ver.SignedBy.Identities {
+		c.signers = append(c.signers, id)
+	}
+	c.signers = append(c.signers, fmt.Sprintf("%X", ver.SignedBy.PrimaryKey.Fingerprint))
+	return c, nil
+}
+
+func (rule *ChartRule) matches(c *chartCandidate) bool {
+	if rule.repository != nil && !rule.repository.MatchString(c.url) {
+		return false
+	}
+	if rule.Chart != "" {
+		if ok, _ := path.Match(rule.Chart, c.name); !ok {
+			return false
+		}
+	}
+	if rule.version != nil {
+		v, err := semver.NewVersion(c.version)
+		if err != nil || !rule.version.Check(v) {
+			return false
+		}
+	}
+	if len(rule.SignedBy) > 0 && !signedBy(c.signers, rule.SignedBy) {
+		return false
+	}
+	return true
+}
+
+// globRegexp compiles a glob to a regular expression. Globs are those of
+// path.Match, except that "**" also matches "/" and that character classes
+// are negated with "!" as well as "^".
+func globRegexp(glob string) (*regexp.Regexp, error) {
+	var b bytes.Buffer
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if strings.HasPrefix(glob[i:], "**") {
+				b.WriteString(".*")
+				i++
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				return nil, errors.New("unterminated character class")
+			}
+			class := glob[i+1 : i+end+1]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			b.WriteString("[" + class + "]")
+			i += end + 1
+		case '\\':
+			if i+1 == len(glob) {
+				return nil, errors.New("trailing escape")
+			}
+			i++
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		default:
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+func signedBy(signers, wanted []string) bool {
+	for _, w := range wanted {
+		for _, s := range signers {
+			if strings.Contains(strings.ToLower(s), strings.ToLower(w)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkChart rejects charts that the chart rules of the server do not allow.
+func (s *ReleaseServer) checkChart(ch *chart.Chart, src *services.ChartSource) error {
+	if s.ChartRules == nil {
+		return nil
+	}
+	if err := s.ChartRules.check(ch, src); err != nil {
+		s.logf("Chart rejected: %s", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tiller
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/proto/hapi/release"
+	"k8s.io/helm/pkg/proto/hapi/services"
+)
+
+func writeChartRules(t *testing.T, rules string) *ChartRules {
+	dir, err := ioutil.TempDir("", "tiller-rules-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "rules.yaml")
+	if err := ioutil.WriteFile(name, []byte(rules), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := LoadChartRules(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// signedChart returns the signed test chart and where it came from.
+func signedChart(t *testing.T) (*chart.Chart, *services.ChartSource) {
+	archive, err := ioutil.ReadFile("testdata/signtest-0.1.0.tgz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prov, err := ioutil.ReadFile("testdata/signtest-0.1.0.tgz.prov")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ch, err := chartutil.LoadFile("testdata/signtest-0.1.0.tgz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ch, &services.ChartSource{
+		Url:         "https://charts.example.com/signtest-0.1.0.tgz",
+		ArchiveName: "signtest-0.1.0.tgz",
+		Archive:     archive,
+		Provenance:  prov,
+	}
+}
+
+func TestChartRulesSignedBy(t *testing.T) {
+	r := writeChartRules(t, `
+keyring: testdata/helm-test-key.pub
+allow:
+- signedBy: ["helm-testing@helm.sh"]
+`)
+	ch, src := signedChart(t)
+	if err := r.check(ch, src); err != nil {
+		t.Errorf("expected the signed chart to be allowed, got %s", err)
+	}
+
+	if err := r.check(ch, &services.ChartSource{Url: src.Url}); err == nil {
+		t.Error("expected the chart to be rejected without its provenance")
+	}
+
+	// A chart that is not the one that was signed.
+	ch.Metadata.Description = "tampered"
+	err := r.check(ch, src)
+	if err == nil || !strings.Contains(err.Error(), "differs from its signed archive") {
+		t.Errorf("expected a modified chart to be rejected, got %v", err)
+	}
+}
+
+func TestChartRulesFingerprint(t *testing.T) {
+	r := writeChartRules(t, `
+keyring: testdata/helm-test-key.pub
+allow:
+- signedBy: ["843bbf981fc18762"]
+`)
+	ch, src := signedChart(t)
+	if err := r.check(ch, src); err != nil {
+		t.Errorf("expected the chart to match the key ID, got %s", err)
+	}
+}
+
+func TestChartRulesRepositoryAndName(t *testing.T) {
+	r := writeChartRules(t, `
+allow:
+- repository: "https://charts.example.com/**"
+  version: ">= 0.1.0"
+deny:
+- chart: "sign*"
+  version: "< 0.1.0"
+`)
+	ch, src := signedChart(t)
+	if err := r.check(ch, &services.ChartSource{Url: src.Url}); err != nil {
+		t.Errorf("expected the chart to be allowed, got %s", err)
+	}
+	if err := r.check(ch, &services.ChartSource{Url: "https://charts.example.com/stable/signtest-0.1.0.tgz"}); err != nil {
+		t.Errorf("expected a chart from a nested path to be allowed, got %s", err)
+	}
+	if err := r.check(ch, &services.ChartSource{Url: "https://evil.example.com/signtest-0.1.0.tgz"}); err == nil {
+		t.Error("expected a chart from another repository to be rejected")
+	}
+	if err := r.check(ch, nil); err == nil {
+		t.Error("expected a chart from an unknown repository to be rejected")
+	}
+
+	ch.Metadata.Version = "0.0.9"
+	err := r.check(ch, &services.ChartSource{Url: src.Url})
+	if err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Errorf("expected the old chart to be denied, got %v", err)
+	}
+}
+
+func TestChartRulesDenyRepository(t *testing.T) {
+	r := writeChartRules(t, `
+deny:
+- repository: "https://evil.example.com/*"
+`)
+	ch, src := signedChart(t)
+	if err := r.check(ch, &services.ChartSource{Url: src.Url}); err != nil {
+		t.Errorf("expected the chart to be allowed, got %s", err)
+	}
+	if err := r.check(ch, &services.ChartSource{Url: "https://evil.example.com/signtest-0.1.0.tgz"}); err == nil {
+		t.Error("expected a chart from the denied repository to be rejected")
+	}
+	// A client that does not say where the chart came from cannot escape the
+	// deny rule.
+	for _, src := range []*services.ChartSource{nil, {}} {
+		err := r.check(ch, src)
+		if err == nil || !strings.Contains(err.Error(), "no known source") {
+			t.Errorf("expected a chart without a source to be rejected, got %v", err)
+		}
+	}
+}
+
+func TestGlobRegexp(t *testing.T) {
+	tests := []struct {
+		glob, s string
+		match   bool
+	}{
+		{"https://charts.example.com/*", "https://charts.example.com/a.tgz", true},
+		{"https://charts.example.com/*", "https://charts.example.com/stable/a.tgz", false},
+		{"https://charts.example.com/**", "https://charts.example.com/stable/a.tgz", true},
+		{"https://charts.example.com/**", "https://charts.example.com.evil.com/a.tgz", false},
+		{"https://charts.example.com/a-?.tgz", "https://charts.example.com/a-1.tgz", true},
+		{"https://charts.example.com/a-[0-9].tgz", "https://charts.example.com/a-x.tgz", false},
+		{"https://charts.example.com/a-[!0-9].tgz", "https://charts.example.com/a-x.tgz", true},
+		{"https://charts.example.com/a-[!0-9].tgz", "https://charts.example.com/a-1.tgz", false},
+		{"https://charts.example.com/a-[!x].tgz", "https://charts.example.com/a-!.tgz", true},
+		{"https://charts.example.com/a-[!x].tgz", "https://charts.example.com/a-x.tgz", false},
+		{"https://charts.example.com/a-[^0-9].tgz", "https://charts.example.com/a-x.tgz", true},
+		{"https://charts.example.com/a\\*.tgz", "https://charts.example.com/a*.tgz", true},
+		{"https://charts.example.com/a\\*.tgz", "https://charts.example.com/ab.tgz", false},
+	}
+	for _, tt := range tests {
+		re, err := globRegexp(tt.glob)
+		if err != nil {
+			t.Errorf("%s: %s", tt.glob, err)
+			continue
+		}
+		if got := re.MatchString(tt.s); got != tt.match {
+			t.Errorf("%s matching %s: expected %t, got %t", tt.glob, tt.s, tt.match, got)
+		}
+	}
+}
+
+func TestLoadChartRulesErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tiller-rules-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, rules := range []string{
+		"allow:\n- signedBy: [someone]\n",
+		"allow:\n- version: \"not a version\"\n",
+		"deny:\n- chart: \"[\"\n",
+		"deny:\n- repository: \"https://charts.example.com/[\"\n",
+	} {
+		name := filepath.Join(dir, "rules.yaml")
+		if err := ioutil.WriteFile(name, []byte(rules), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadChartRules(name); err == nil {
+			t.Errorf("expected rules %q to be invalid", rules)
+		}
+	}
+}
+
+func TestImportReleaseChartRules(t *testing.T) {
+	rs := rsFixture()
+	rs.ChartRules = writeChartRules(t, "deny:\n- chart: hello\n")
+
+	rel := releaseStub()
+	rel.Chart = chartStub()
+	req := &services.ImportReleaseRequest{Releases: []*release.Release{rel}}
+	_, err := rs.ImportRelease(helm.NewContext(), req)
+	if err == nil || !strings.Contains(err.Error(), "denied by Tiller's chart rules") {
+		t.Errorf("expected the import to be denied, got %v", err)
+	}
+	if h, _ := rs.env.Releases.History(rel.Name); len(h) != 0 {
+		t.Errorf("expected no revisions to be stored, got %d", len(h))
+	}
+}
+
+func TestInstallReleaseChartRules(t *testing.T) {
+	rs := rsFixture()
+	rs.ChartRules = writeChartRules(t, "deny:\n- chart: hello\n")
+
+	req := &services.InstallReleaseRequest{Namespace: "spaced", Chart: chartStub()}
+	_, err := rs.InstallRelease(helm.NewContext(), req)
+	if err == nil || !strings.Contains(err.Error(), "denied by Tiller's chart rules") {
+		t.Errorf("expected the install to be denied, got %v", err)
+	}
+}
+
+func TestRollbackReleaseChartRules(t *testing.T) {
+	rs := rsFixture()
+	rel := releaseStub()
+	rs.env.Releases.Create(rel)
+	upgradedRel := upgradeReleaseVersion(rel)
+	rs.env.Releases.Update(rel)
+	rs.env.Releases.Create(upgradedRel)
+	rs.ChartRules = writeChartRules(t, "deny:\n- chart: hello\n")
+
+	req := &services.RollbackReleaseRequest{Name: rel.Name, Version: 1}
+	_, err := rs.RollbackRelease(helm.NewContext(), req)
+	if err == nil || !strings.Contains(err.Error(), "denied by Tiller's chart rules") {
+		t.Errorf("expected the rollback to be denied, got %v", err)
+	}
+	if h, _ := rs.env.Releases.History(rel.Name); len(h) != 2 {
+		t.Errorf("expected no revision to be added, got %d revisions", len(h))
+	}
+}
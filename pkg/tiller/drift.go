@@ -0,0 +1,561 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tiller
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	ctx "golang.org/x/net/context"
+
+	"k8s.io/helm/pkg/kube"
+	"k8s.io/helm/pkg/proto/hapi/release"
+	"k8s.io/helm/pkg/proto/hapi/services"
+	relutil "k8s.io/helm/pkg/releaseutil"
+)
+
+// Drift statuses of a resource.
+const (
+	driftInSync  = "in sync"
+	driftChanged = "changed"
+	driftMissing = "missing"
+)
+
+// serverFields are the fields of live objects that the API server,
+// controllers and Tiller itself maintain, and that never count as drift. The
+// ownership labels are added when resources are applied, so they are never in
+// the stored manifest.
+var serverFields = map[string]bool{
+	"status":                     true,
+	"metadata.creationTimestamp": true,
+	"metadata.generation":        true,
+	"metadata.resourceVersion":   true,
+	"metadata.selfLink":          true,
+	"metadata.uid":               true,
+	`metadata.annotations["deployment.kubernetes.io/revision"]`:                true,
+	`metadata.annotations["kubectl.kubernetes.io/last-applied-configuration"]`: true,
+	`metadata.annotations["` + kube.AnnotationSource + `"]`:                    true,
+	`metadata.labels["` + kube.LabelManagedBy + `"]`:                           true,
+	`metadata.labels["` + kube.LabelRelease + `"]`:                             true,
+	`metadata.labels["` + kube.LabelRevision + `"]`:                            true,
+	`metadata.labels["` + kube.LabelChart + `"]`:                               true,
+}
+
+// defaultedFields are fields that the API server fills in when a resource
+// leaves them out, with the values it fills in. A field that is only set on
+// the live object is not drift if its value is one of these, or if there are
+// none listed because the default is computed.
+var defaultedFields = map[string][]interface{}{
+	"backoffLimit":                  {6.0},
+	"clusterIP":                     nil,
+	"completions":                   {1.0},
+	"concurrencyPolicy":             {"Allow"},
+	"defaultMode":                   {420.0},
+	"dnsPolicy":                     {"ClusterFirst"},
+	"externalTrafficPolicy":         {"Cluster"},
+	"failedJobsHistoryLimit":        {1.0},
+	"failureThreshold":              {3.0},
+	"finalizers":                    nil,
+	"imagePullPolicy":               nil,
+	"namespace":                     nil,
+	"nodePort":                      nil,
+	"parallelism":                   {1.0},
+	"periodSeconds":                 {10.0},
+	"podManagementPolicy":           {"OrderedReady"},
+	"progressDeadlineSeconds":       nil,
+	"protocol":                      {"TCP"},
+	"replicas":                      {1.0},
+	"restartPolicy":                 {"Always"},
+	"revisionHistoryLimit":          nil,
+	"schedulerName":                 {"default-scheduler"},
+	"secrets":                       nil,
+	"selector":                      nil,
+	"serviceAccount":                nil,
+	"sessionAffinity":               {"None"},
+	"strategy":                      nil,
+	"successThreshold":              {1.0},
+	"successfulJobsHistoryLimit":    {3.0},
+	"suspend":                       {false},
+	"targetPort":                    nil,
+	"terminationGracePeriodSeconds": {30.0},
+	"terminationMessagePath":        {"/dev/termination-log"},
+	"terminationMessagePolicy":      {"File"},
+	"timeoutSeconds":                {1.0},
+	"type":                          {"ClusterIP", "Opaque", "RollingUpdate"},
+	"updateStrategy":                nil,
+}
+
+// IgnoreFieldsAnnotation lists the paths of the fields of a resource that are
+// managed outside of Helm, separated by commas, e.g. "spec.replicas". Changes
+// to these fields are not reported as drift, and sync leaves them alone.
+const IgnoreFieldsAnnotation = "helm.sh/ignore-fields"
+
+// GetReleaseDrift compares the resources of a release with the live objects
+// in the cluster.
+func (s *ReleaseServer) GetReleaseDrift(c ctx.Context, req *services.GetReleaseDriftRequest) (*services.GetReleaseDriftResponse, error) {
+	if !checkClientVersion(c) {
+		return nil, errIncompatibleVersion
+	}
+
+	s, err := s.forRequest(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ValidName.MatchString(req.Name) {
+		return nil, errMissingRelease
+	}
+
+	var rel *release.Release
+	if req.Version <= 0 {
+		if rel, err = s.env.Releases.Last(req.Name); err != nil {
+			return nil, fmt.Errorf("getting deployed release %q: %s", req.Name, err)
+		}
+	} else {
+		if rel, err = s.env.Releases.Get(req.Name, req.Version); err != nil {
+			return nil, fmt.Errorf("getting release '%s' (v%d): %s", req.Name, req.Version, err)
+		}
+	}
+
+	resources, err := s.releaseDrift(rel, req.IgnoreFields)
+	if err != nil {
+		return nil, err
+	}
+	res := &services.GetReleaseDriftResponse{}
+	for _, r := range resources {
+		res.Resources = append(res.Resources, r.drift)
+	}
+	return res, nil
+}
+
+// SyncRelease reverts the changes made to the live objects of the deployed
+// revision of a release: changed fields are set back, fields added outside of
+// Helm are removed and deleted objects are created again.
+func (s *ReleaseServer) SyncRelease(c ctx.Context, req *services.SyncReleaseRequest) (*services.SyncReleaseResponse, error) {
+	if !checkClientVersion(c) {
+		return nil, errIncompatibleVersion
+	}
+
+	s, err := s.forRequest(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ValidName.MatchString(req.Name) {
+		return nil, errMissingRelease
+	}
+
+	rel, err := s.env.Releases.Deployed(req.Name)
+	if err != nil {
+		return nil, fmt.Errorf("getting deployed release %q: %s", req.Name, err)
+	}
+	if err := s.checkPolicy(rel, "sync"); err != nil {
+		return nil, err
+	}
+	if err := s.checkGates(rel, "sync", req.OverrideGates); err != nil {
+		return nil, err
+	}
+
+	resources, err := s.releaseDrift(rel, req.IgnoreFields)
+	if err != nil {
+		return nil, err
+	}
+	res := &services.SyncReleaseResponse{}
+	missing := bytes.NewBuffer(nil)
+	current := bytes.NewBuffer(nil)
+	target := bytes.NewBuffer(nil)
+	for _, r := range resources {
+		switch r.drift.Status {
+		case driftMissing:
+			missing.WriteString("\n---\n" + r.doc.Content)
+		case driftChanged:
+			live, err := yaml.Marshal(r.live)
+			if err != nil {
+				return nil, err
+			}
+			reverted, err := yaml.Marshal(reconcile("", r.stored, r.live, r.ignored))
+			if err != nil {
+				return nil, err
+			}
+			current.WriteString("\n---\n" + string(live))
+			target.WriteString("\n---\n" + string(reverted))
+		default:
+			continue
+		}
+		res.Resources = append(res.Resources, r.drift)
+	}
+
+	kubeCli := s.kubeClientFor(rel)
+	if missing.Len() > 0 {
+		if err := kubeCli.Create(rel.Namespace, missing); err != nil {
+			return nil, fmt.Errorf("could not create the missing resources of %q: %s", rel.Name, err)
+		}
+	}
+	if current.Len() > 0 {
+		if err := kubeCli.Update(rel.Namespace, current, target); err != nil {
+			return nil, fmt.Errorf("could not revert the resources of %q: %s", rel.Name, err)
+		}
+	}
+	s.logf("Synced %d resources of %s (v%d)", len(res.Resources), rel.Name, rel.Version)
+	return res, nil
+}
+
+// liveResource is a resource of a release together with its live object.
+type liveResource struct {
+	doc     relutil.Document
+	stored  map[string]interface{}
+	live    map[string]interface{}
+	ignored func(path string) bool
+	drift   *services.ResourceDrift
+}
+
+// releaseDrift looks up the live objects of the resources of rel and compares
+// them with the release, leaving out the fields matched by ignore.
+func (s *ReleaseServer) releaseDrift(rel *release.Release, ignore []string) ([]liveResource, error) {
+	rules, err := parseIgnoreFields(ignore)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := relutil.SplitManifest(rel.Manifest)
+	if err != nil {
+		return nil, err
+	}
+	res := []liveResource{}
+	for _, d := range docs {
+		if d.Kind == "" {
+			continue
+		}
+		r, err := s.resourceDrift(rel.Namespace, d, rules)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, r)
+	}
+	return res, nil
+}
+
+func (s *ReleaseServer) resourceDrift(namespace string, d relutil.Document, rules []ignoreRule) (liveResource, error) {
+	r := liveResource{doc: d, stored: map[string]interface{}{}}
+	if err := yaml.Unmarshal([]byte(d.Content), &r.stored); err != nil {
+		return r, fmt.Errorf("could not parse %s %q: %s", d.Kind, d.Name, err)
+	}
+	if md, ok := r.stored["metadata"].(map[string]interface{}); ok {
+		if ns, ok := md["namespace"].(string); ok && ns != "" {
+			namespace = ns
+		}
+		if a, ok := md["annotations"].(map[string]interface{}); ok {
+			if v, ok := a[IgnoreFieldsAnnotation].(string); ok {
+				fields, err := parseIgnoreFields(strings.Split(v, ","))
+				if err != nil {
+					return r, fmt.Errorf("%s %q: %s", d.Kind, d.Name, err)
+				}
+				rules = append(append([]ignoreRule{}, rules...), fields...)
+			}
+		}
+	}
+	r.ignored = func(path string) bool {
+		for _, rule := range rules {
+			if rule.matches(d.Kind, d.Name, path) {
+				return true
+			}
+		}
+		return false
+	}
+
+	r.drift = &services.ResourceDrift{
+		ApiVersion: d.APIVersion,
+		Kind:       d.Kind,
+		Name:       d.Name,
+		Namespace:  namespace,
+		Status:     driftInSync,
+	}
+	live, err := s.env.KubeClient.Lookup(d.APIVersion, d.Kind, namespace, d.Name)
+	if err != nil {
+		return r, fmt.Errorf("could not look up %s %q: %s", d.Kind, d.Name, err)
+	}
+	r.live = live
+	if len(live) == 0 {
+		r.drift.Status = driftMissing
+		return r, nil
+	}
+
+	r.drift.Fields = diffFields("", prune("", r.stored), prune("", live), r.ignored)
+	if len(r.drift.Fields) > 0 {
+		r.drift.Status = driftChanged
+	}
+	return r, nil
+}
+
+// ignoreRule matches the paths of fields to ignore, optionally only for
+// resources of a kind, or of a kind and name.
+type ignoreRule struct {
+	kind string
+	name string
+	path *regexp.Regexp
+}
+
+// parseIgnoreFields parses field paths of the form [KIND[/NAME]:]PATH, where
+// "[*]" in PATH matches any list index. A path also matches all of the fields
+// below it.
+func parseIgnoreFields(fields []string) ([]ignoreRule, error) {
+	res := []ignoreRule{}
+	for _, f := range fields {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		rule := ignoreRule{}
+		p := f
+		if i := strings.Index(f, ":"); i >= 0 && !strings.ContainsAny(f[:i], ".[") {
+			rule.kind, p = f[:i], f[i+1:]
+			if j := strings.Index(rule.kind, "/"); j >= 0 {
+				rule.kind, rule.name = rule.kind[:j], rule.kind[j+1:]
+			}
+		}
+		if p == "" || rule.kind == "" && p != f {
+			return nil, fmt.Errorf("invalid field to ignore %q: expected [KIND[/NAME]:]PATH", f)
+		}
+		expr := strings.Replace(regexp.QuoteMeta(p), `\[\*\]`, `\[[0-9]+\]`, -1)
+		rule.path = regexp.MustCompile(`^` + expr + `($|\.|\[)`)
+		res = append(res, rule)
+	}
+	return res, nil
+}
+
+func (r ignoreRule) matches(kind, name, path string) bool {
+	if r.kind != "" && !strings.EqualFold(r.kind, kind) {
+		return false
+	}
+	if r.name != "" && r.name != name {
+		return false
+	}
+	return r.path.MatchString(path)
+}
+
+// identifier matches map keys that can be written in a path without quoting.
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+func fieldPath(parent, key string) string {
+	if !identifier.MatchString(key) {
+		return fmt.Sprintf("%s[%q]", parent, key)
+	}
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+// diffFields returns the fields that differ between a stored value and its
+// live counterpart, in the order of their paths, leaving out the ignored
+// ones. Maps are compared key by key and lists item by item.
+func diffFields(path string, stored, live interface{}, ignored func(string) bool) []*services.FieldDrift {
+	switch sv := stored.(type) {
+	case map[string]interface{}:
+		lv, ok := live.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := []string{}
+		for k := range sv {
+			keys = append(keys, k)
+		}
+		for k := range lv {
+			if _, ok := sv[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		res := []*services.FieldDrift{}
+		for _, k := range keys {
+			p := fieldPath(path, k)
+			s, inStored := sv[k]
+			l, inLive := lv[k]
+			switch {
+			case ignored(p):
+			case !inLive:
+				res = append(res, fieldDrift(p, "removed", s, nil))
+			case !inStored:
+				if !isDefault(k, l) {
+					res = append(res, fieldDrift(p, "added", nil, l))
+				}
+			default:
+				res = append(res, diffFields(p, s, l, ignored)...)
+			}
+		}
+		return res
+	case []interface{}:
+		lv, ok := live.([]interface{})
+		if !ok {
+			break
+		}
+		res := []*services.FieldDrift{}
+		for i := 0; i < len(sv) || i < len(lv); i++ {
+			p := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case ignored(p):
+			case i >= len(lv):
+				res = append(res, fieldDrift(p, "removed", sv[i], nil))
+			case i >= len(sv):
+				res = append(res, fieldDrift(p, "added", nil, lv[i]))
+			default:
+				res = append(res, diffFields(p, sv[i], lv[i], ignored)...)
+			}
+		}
+		return res
+	}
+	if reflect.DeepEqual(stored, live) {
+		return nil
+	}
+	return []*services.FieldDrift{fieldDrift(path, "changed", stored, live)}
+}
+
+// reconcile returns the live value with the changes that diffFields reports
+// reverted, keeping the fields that the API server maintains or defaults and
+// the ignored ones. The server fields of a map added outside of Helm, such as
+// the ownership labels of labels only set on the live object, are kept too.
+func reconcile(path string, stored, live interface{}, ignored func(string) bool) interface{} {
+	if serverFields[path] || ignored(path) {
+		return live
+	}
+	switch sv := stored.(type) {
+	case map[string]interface{}:
+		lv, ok := live.(map[string]interface{})
+		if !ok {
+			break
+		}
+		res := map[string]interface{}{}
+		for k, l := range lv {
+			p := fieldPath(path, k)
+			if s, ok := sv[k]; ok && prune(p, s) != nil {
+				res[k] = reconcile(p, s, l, ignored)
+			} else if pl := prune(p, l); pl == nil || serverFields[p] || ignored(p) || isDefault(k, pl) {
+				res[k] = l
+			} else if lm, ok := l.(map[string]interface{}); ok {
+				if kept := reconcile(p, map[string]interface{}{}, lm, ignored).(map[string]interface{}); len(kept) > 0 {
+					res[k] = kept
+				}
+			}
+		}
+		for k, s := range sv {
+			if _, ok := lv[k]; !ok && prune(fieldPath(path, k), s) != nil {
+				res[k] = s
+			}
+		}
+		return res
+	case []interface{}:
+		lv, ok := live.([]interface{})
+		if !ok {
+			break
+		}
+		res := []interface{}{}
+		for i, s := range sv {
+			p := fmt.Sprintf("%s[%d]", path, i)
+			if i < len(lv) {
+				res = append(res, reconcile(p, s, lv[i], ignored))
+			} else {
+				res = append(res, s)
+			}
+		}
+		for i := len(sv); i < len(lv); i++ {
+			if ignored(fmt.Sprintf("%s[%d]", path, i)) {
+				res = append(res, lv[i])
+			}
+		}
+		return res
+	}
+	if prune(path, stored) == nil {
+		return live
+	}
+	return stored
+}
+
+// isDefault reports whether a field only set on a live object holds the
+// value the API server defaults it to.
+func isDefault(key string, v interface{}) bool {
+	defaults, ok := defaultedFields[key]
+	if !ok {
+		return false
+	}
+	if len(defaults) == 0 {
+		return true
+	}
+	for _, d := range defaults {
+		if reflect.DeepEqual(d, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// prune removes the server fields of the value at path, as well as null
+// values, empty maps and empty lists, which the API server treats the same as
+// fields that are not set.
+func prune(path string, v interface{}) interface{} {
+	if serverFields[path] {
+		return nil
+	}
+	switch t := v.(type) {
+	case map[string]interface{}:
+		res := map[string]interface{}{}
+		for k, e := range t {
+			if e = prune(fieldPath(path, k), e); e != nil {
+				res[k] = e
+			}
+		}
+		if len(res) == 0 {
+			return nil
+		}
+		return res
+	case []interface{}:
+		if len(t) == 0 {
+			return nil
+		}
+		res := make([]interface{}, len(t))
+		for i, e := range t {
+			res[i] = prune(fmt.Sprintf("%s[%d]", path, i), e)
+		}
+		return res
+	}
+	return v
+}
+
+func fieldDrift(path, change string, stored, live interface{}) *services.FieldDrift {
+	return &services.FieldDrift{
+		Path:   path,
+		Change: change,
+		Stored: driftValue(stored),
+		Live:   driftValue(live),
+	}
+}
+
+func driftValue(v interface{}) string {
+	if v == nil {
+		return ""
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
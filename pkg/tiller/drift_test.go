@@ -0,0 +1,373 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tiller
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/release"
+	"k8s.io/helm/pkg/proto/hapi/services"
+	"k8s.io/helm/pkg/tiller/environment"
+)
+
+// driftKubeClient returns live objects by kind and name, and records the
+// resources created and updated.
+type driftKubeClient struct {
+	environment.PrintingKubeClient
+	objs    map[string]string
+	created string
+	current string
+	target  string
+}
+
+func (d *driftKubeClient) Create(ns string, r io.Reader) error {
+	b, err := ioutil.ReadAll(r)
+	d.created = string(b)
+	return err
+}
+
+func (d *driftKubeClient) Update(ns string, current, target io.Reader) error {
+	b, err := ioutil.ReadAll(current)
+	if err != nil {
+		return err
+	}
+	d.current = string(b)
+	b, err = ioutil.ReadAll(target)
+	d.target = string(b)
+	return err
+}
+
+func (d *driftKubeClient) Lookup(apiVersion, kind, ns, name string) (map[string]interface{}, error) {
+	res := map[string]interface{}{}
+	err := yaml.Unmarshal([]byte(d.objs[kind+"/"+name]), &res)
+	return res, err
+}
+
+const driftManifest = `---
+# Source: hello/templates/deployment.yaml
+apiVersion: extensions/v1beta1
+kind: Deployment
+metadata:
+  name: web
+  labels:
+    app: web
+spec:
+  replicas: 2
+  template:
+    metadata:
+      creationTimestamp: null
+      labels:
+        app: web
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.11
+        env:
+        - name: MODE
+          value: prod
+---
+# Source: hello/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - port: 80
+---
+# Source: hello/templates/secret.yaml
+apiVersion: v1
+kind: Secret
+metadata:
+  name: web
+`
+
+func driftFixture() (*ReleaseServer, *driftKubeClient) {
+	rs := rsFixture()
+	kc := &driftKubeClient{
+		PrintingKubeClient: environment.PrintingKubeClient{Out: os.Stdout},
+		objs: map[string]string{
+			"Deployment/web": `
+apiVersion: extensions/v1beta1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+  uid: 0b2bd3c1
+  resourceVersion: "1234"
+  generation: 3
+  annotations:
+    deployment.kubernetes.io/revision: "2"
+  labels:
+    app: web
+    hotfix: "true"
+    helm.sh/managed-by: Tiller
+    helm.sh/release: angry-panda
+spec:
+  replicas: 5
+  revisionHistoryLimit: 2
+  strategy:
+    type: RollingUpdate
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      dnsPolicy: ClusterFirst
+      restartPolicy: Always
+      securityContext: {}
+      containers:
+      - name: web
+        image: nginx:1.11
+        imagePullPolicy: IfNotPresent
+        terminationMessagePath: /dev/termination-log
+        resources: {}
+status:
+  replicas: 5
+`,
+			"Service/web": `
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: default
+  labels:
+    helm.sh/managed-by: Tiller
+    helm.sh/release: angry-panda
+    helm.sh/revision: "1"
+    helm.sh/chart: hello-0.1.0
+spec:
+  clusterIP: 10.0.0.12
+  sessionAffinity: None
+  type: ClusterIP
+  ports:
+  - port: 80
+    protocol: TCP
+    targetPort: 80
+`,
+		},
+	}
+	rs.env.KubeClient = kc
+
+	rel := releaseStub()
+	rel.Namespace = "default"
+	rel.Manifest = driftManifest
+	rs.env.Releases.Create(rel)
+	return rs, kc
+}
+
+func TestGetReleaseDrift(t *testing.T) {
+	c := helm.NewContext()
+	rs, _ := driftFixture()
+
+	res, err := rs.GetReleaseDrift(c, &services.GetReleaseDriftRequest{Name: "angry-panda"})
+	if err != nil {
+		t.Fatalf("Failed drift: %s", err)
+	}
+	if len(res.Resources) != 3 {
+		t.Fatalf("Expected 3 resources, got %d", len(res.Resources))
+	}
+
+	dep := res.Resources[0]
+	if dep.Kind != "Deployment" || dep.Namespace != "default" || dep.Status != driftChanged {
+		t.Errorf("Unexpected deployment drift %v", dep)
+	}
+	expected := []*services.FieldDrift{
+		{Path: "metadata.labels.hotfix", Change: "added", Live: `"true"`},
+		{Path: "spec.replicas", Change: "changed", Stored: "2", Live: "5"},
+		{Path: "spec.template.spec.containers[0].env", Change: "removed", Stored: `[{"name":"MODE","value":"prod"}]`},
+	}
+	if !reflect.DeepEqual(dep.Fields, expected) {
+		t.Errorf("Expected fields %v, got %v", expected, dep.Fields)
+	}
+
+	if svc := res.Resources[1]; svc.Status != driftInSync || len(svc.Fields) > 0 {
+		t.Errorf("Expected the service to be in sync, got %v", svc)
+	}
+	if sec := res.Resources[2]; sec.Status != driftMissing {
+		t.Errorf("Expected the secret to be missing, got %v", sec)
+	}
+}
+
+func TestGetReleaseDriftMissingRelease(t *testing.T) {
+	c := helm.NewContext()
+	rs := rsFixture()
+	rs.env.Releases.Create(namedReleaseStub("angry-panda", release.Status_DEPLOYED))
+
+	if _, err := rs.GetReleaseDrift(c, &services.GetReleaseDriftRequest{Name: "sad-panda"}); err == nil {
+		t.Error("Expected an error for a release that does not exist")
+	}
+}
+
+func TestGetReleaseDriftIgnoreFields(t *testing.T) {
+	c := helm.NewContext()
+	rs, _ := driftFixture()
+
+	req := &services.GetReleaseDriftRequest{
+		Name:         "angry-panda",
+		IgnoreFields: []string{"Deployment:spec.replicas", "metadata.labels", "spec.template.spec.containers[*].env"},
+	}
+	res, err := rs.GetReleaseDrift(c, req)
+	if err != nil {
+		t.Fatalf("Failed drift: %s", err)
+	}
+	if dep := res.Resources[0]; dep.Status != driftInSync {
+		t.Errorf("Expected the deployment to be in sync, got %v", dep)
+	}
+
+	req.IgnoreFields = []string{"Service/other:spec.replicas"}
+	if res, err = rs.GetReleaseDrift(c, req); err != nil {
+		t.Fatalf("Failed drift: %s", err)
+	}
+	if dep := res.Resources[0]; len(dep.Fields) != 3 {
+		t.Errorf("Expected 3 fields to have drifted, got %v", dep.Fields)
+	}
+
+	req.IgnoreFields = []string{":spec.replicas"}
+	if _, err := rs.GetReleaseDrift(c, req); err == nil {
+		t.Error("Expected an error for an invalid field")
+	}
+}
+
+func TestGetReleaseDriftIgnoreFieldsAnnotation(t *testing.T) {
+	c := helm.NewContext()
+	rs, _ := driftFixture()
+	rel, _ := rs.env.Releases.Get("angry-panda", 1)
+	rel.Manifest = strings.Replace(rel.Manifest, "  labels:\n    app: web\nspec:\n  replicas: 2", "  labels:\n    app: web\n  annotations:\n    helm.sh/ignore-fields: spec.replicas, metadata.labels.hotfix\nspec:\n  replicas: 2", 1)
+	rs.env.Releases.Update(rel)
+
+	res, err := rs.GetReleaseDrift(c, &services.GetReleaseDriftRequest{Name: "angry-panda"})
+	if err != nil {
+		t.Fatalf("Failed drift: %s", err)
+	}
+	fields := res.Resources[0].Fields
+	if len(fields) != 2 || fields[0].Path != "metadata.annotations" || fields[1].Path != "spec.template.spec.containers[0].env" {
+		t.Errorf("Unexpected fields %v", fields)
+	}
+}
+
+func TestSyncRelease(t *testing.T) {
+	c := helm.NewContext()
+	rs, kc := driftFixture()
+
+	res, err := rs.SyncRelease(c, &services.SyncReleaseRequest{Name: "angry-panda", IgnoreFields: []string{"spec.replicas"}})
+	if err != nil {
+		t.Fatalf("Failed sync: %s", err)
+	}
+	if len(res.Resources) != 2 || res.Resources[0].Kind != "Deployment" || res.Resources[1].Kind != "Secret" {
+		t.Fatalf("Expected the deployment and secret to be synced, got %v", res.Resources)
+	}
+
+	if !strings.Contains(kc.created, "kind: Secret") {
+		t.Errorf("Expected the secret to be created, got %q", kc.created)
+	}
+	if !strings.Contains(kc.current, "hotfix") {
+		t.Errorf("Expected the live deployment to be the current state, got %q", kc.current)
+	}
+
+	target := map[string]interface{}{}
+	if err := yaml.Unmarshal([]byte(strings.TrimPrefix(kc.target, "\n---\n")), &target); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{}
+	yaml.Unmarshal([]byte(`
+apiVersion: extensions/v1beta1
+kind: Deployment
+metadata:
+  name: web
+  namespace: default
+  uid: 0b2bd3c1
+  resourceVersion: "1234"
+  generation: 3
+  annotations:
+    deployment.kubernetes.io/revision: "2"
+  labels:
+    app: web
+    helm.sh/managed-by: Tiller
+    helm.sh/release: angry-panda
+spec:
+  replicas: 5
+  revisionHistoryLimit: 2
+  strategy:
+    type: RollingUpdate
+  template:
+    metadata:
+      labels:
+        app: web
+    spec:
+      dnsPolicy: ClusterFirst
+      restartPolicy: Always
+      securityContext: {}
+      containers:
+      - name: web
+        image: nginx:1.11
+        imagePullPolicy: IfNotPresent
+        terminationMessagePath: /dev/termination-log
+        resources: {}
+        env:
+        - name: MODE
+          value: prod
+status:
+  replicas: 5
+`), &expected)
+	if !reflect.DeepEqual(target, expected) {
+		t.Errorf("Expected the reverted deployment\n%v\ngot\n%v", expected, target)
+	}
+}
+
+func TestReconcileKeepsOwnershipLabels(t *testing.T) {
+	stored := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "web"},
+	}
+	live := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name": "web",
+			"labels": map[string]interface{}{
+				"hotfix":          "true",
+				"helm.sh/release": "angry-panda",
+			},
+		},
+	}
+	expected := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":   "web",
+			"labels": map[string]interface{}{"helm.sh/release": "angry-panda"},
+		},
+	}
+	never := func(string) bool { return false }
+	if got := reconcile("", stored, live, never); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestSyncReleaseNotDeployed(t *testing.T) {
+	c := helm.NewContext()
+	rs := rsFixture()
+	rs.env.Releases.Create(namedReleaseStub("angry-panda", release.Status_FAILED))
+
+	if _, err := rs.SyncRelease(c, &services.SyncReleaseRequest{Name: "angry-panda"}); err == nil {
+		t.Error("Expected an error for a release that is not deployed")
+	}
+}
@@ -27,10 +27,14 @@ import (
 
 	"k8s.io/helm/pkg/chartutil"
 	"k8s.io/helm/pkg/engine"
+	"k8s.io/helm/pkg/gate"
 	"k8s.io/helm/pkg/kube"
+	"k8s.io/helm/pkg/notify"
+	"k8s.io/helm/pkg/policy"
 	"k8s.io/helm/pkg/proto/hapi/chart"
 	"k8s.io/helm/pkg/storage"
 	"k8s.io/helm/pkg/storage/driver"
+	"k8s.io/helm/pkg/valueref"
 	"k8s.io/kubernetes/pkg/client/unversioned"
 	"k8s.io/kubernetes/pkg/client/unversioned/testclient"
 )
@@ -119,6 +123,15 @@ type KubeClient interface {
 	// by "\n---\n").
 	Delete(namespace string, reader io.Reader) error
 
+	// DeleteWithOptions destroys one or more resources with the propagation
+	// policy of opts, and waits until they are gone if opts asks to.
+	//
+	// namespace must contain a valid existing namespace.
+	//
+	// reader must contain a YAML stream (one or more YAML documents separated
+	// by "\n---\n").
+	DeleteWithOptions(namespace string, reader io.Reader, opts kube.DeleteOptions) error
+
 	// Watch the resource in reader until it is "ready".
 	//
 	// For Jobs, "ready" means the job ran to completion (excited without error).
@@ -126,6 +139,15 @@ type KubeClient interface {
 	// error.
 	WatchUntilReady(namespace string, reader io.Reader) error
 
+	// Wait waits until the resources in reader are ready, or the timeout of
+	// opts expires. See kube.Client.Wait for what "ready" means for each kind.
+	//
+	// namespace must contain a valid existing namespace.
+	//
+	// reader must contain a YAML stream (one or more YAML documents separated
+	// by "\n---\n").
+	Wait(namespace string, reader io.Reader, opts kube.WaitOptions) error
+
 	// Update updates one or more resources or creates the resource
 	// if it doesn't exist
 	//
@@ -135,6 +157,29 @@ type KubeClient interface {
 	// by "\n---\n").
 	Update(namespace string, originalReader, modifiedReader io.Reader) error
 
+	// Apply creates or updates one or more resources using server-side apply,
+	// and deletes resources present in originalReader but not in modifiedReader.
+	//
+	// namespace must contain a valid existing namespace
+	//
+	// originalReader may be nil. modifiedReader must contain a YAML stream
+	// (one or more YAML documents separated by "\n---\n").
+	Apply(namespace string, originalReader, modifiedReader io.Reader) error
+
+	// DryRun sends one or more resources to the API server as a server-side
+	// dry run, so that they are validated and go through admission webhooks
+	// without being persisted.
+	//
+	// reader must contain a YAML stream (one or more YAML documents separated
+	// by "\n---\n").
+	DryRun(namespace string, reader io.Reader) error
+
+	// Lookup fetches a live object by API version, kind, namespace and name.
+	//
+	// If name is empty, all objects of that kind in the namespace are returned
+	// as a list. A missing object yields an empty map rather than an error.
+	Lookup(apiVersion, kind, namespace, name string) (map[string]interface{}, error)
+
 	// APIClient gets a raw API client for Kubernetes.
 	APIClient() (unversioned.Interface, error)
 }
@@ -173,18 +218,54 @@ func (p *PrintingKubeClient) Delete(ns string, r io.Reader) error {
 	return err
 }
 
+// DeleteWithOptions implements KubeClient DeleteWithOptions.
+//
+// It only prints out the content to be deleted.
+func (p *PrintingKubeClient) DeleteWithOptions(ns string, r io.Reader, opts kube.DeleteOptions) error {
+	_, err := io.Copy(p.Out, r)
+	return err
+}
+
 // WatchUntilReady implements KubeClient WatchUntilReady.
 func (p *PrintingKubeClient) WatchUntilReady(ns string, r io.Reader) error {
 	_, err := io.Copy(p.Out, r)
 	return err
 }
 
+// Wait implements KubeClient Wait.
+//
+// The printing client has no cluster to wait for, so everything is ready.
+func (p *PrintingKubeClient) Wait(ns string, r io.Reader, opts kube.WaitOptions) error {
+	return nil
+}
+
 // Update implements KubeClient Update.
 func (p *PrintingKubeClient) Update(ns string, currentReader, modifiedReader io.Reader) error {
 	_, err := io.Copy(p.Out, modifiedReader)
 	return err
 }
 
+// Apply implements KubeClient Apply.
+func (p *PrintingKubeClient) Apply(ns string, currentReader, modifiedReader io.Reader) error {
+	_, err := io.Copy(p.Out, modifiedReader)
+	return err
+}
+
+// DryRun implements KubeClient DryRun.
+//
+// The printing client has no cluster to validate against, so everything passes.
+func (p *PrintingKubeClient) DryRun(ns string, r io.Reader) error {
+	_, err := io.Copy(p.Out, r)
+	return err
+}
+
+// Lookup implements KubeClient Lookup.
+//
+// The printing client has no cluster to query, so nothing is ever found.
+func (p *PrintingKubeClient) Lookup(apiVersion, kind, namespace, name string) (map[string]interface{}, error) {
+	return map[string]interface{}{}, nil
+}
+
 // Environment provides the context for executing a client request.
 //
 // All services in a context are concurrency safe.
@@ -195,6 +276,20 @@ type Environment struct {
 	Releases *storage.Storage
 	// KubeClient is a Kubernetes API client.
 	KubeClient KubeClient
+	// Policy, if set, must allow a release before it is applied.
+	Policy policy.Evaluator
+	// Notifier, if set, is told about every release that is installed,
+	// upgraded, rolled back or deleted.
+	Notifier notify.Notifier
+	// Gates, if set, decide whether releases may be installed, upgraded,
+	// rolled back or deleted at the time they are.
+	Gates *gate.Config
+	// ValueResolvers resolve the references to secrets in the values of
+	// releases when they are rendered.
+	ValueResolvers valueref.Resolvers
+	// ValuePolicy lists the secrets the releases of each namespace may refer
+	// to. References to any other secret fail.
+	ValuePolicy valueref.Policy
 }
 
 // New returns an environment initialized with the defaults.
@@ -22,6 +22,7 @@ import (
 	"testing"
 
 	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/kube"
 	"k8s.io/helm/pkg/proto/hapi/chart"
 	unversionedclient "k8s.io/kubernetes/pkg/client/unversioned"
 	"k8s.io/kubernetes/pkg/client/unversioned/testclient"
@@ -51,12 +52,27 @@ func (k *mockKubeClient) Get(ns string, r io.Reader) (string, error) {
 func (k *mockKubeClient) Delete(ns string, r io.Reader) error {
 	return nil
 }
+func (k *mockKubeClient) DeleteWithOptions(ns string, r io.Reader, opts kube.DeleteOptions) error {
+	return nil
+}
 func (k *mockKubeClient) Update(ns string, currentReader, modifiedReader io.Reader) error {
 	return nil
 }
 func (k *mockKubeClient) WatchUntilReady(ns string, r io.Reader) error {
 	return nil
 }
+func (k *mockKubeClient) Wait(ns string, r io.Reader, opts kube.WaitOptions) error {
+	return nil
+}
+func (k *mockKubeClient) Apply(ns string, currentReader, modifiedReader io.Reader) error {
+	return nil
+}
+func (k *mockKubeClient) DryRun(ns string, r io.Reader) error {
+	return nil
+}
+func (k *mockKubeClient) Lookup(apiVersion, kind, ns, name string) (map[string]interface{}, error) {
+	return map[string]interface{}{}, nil
+}
 
 var _ Engine = &mockEngine{}
 var _ KubeClient = &mockKubeClient{}
@@ -0,0 +1,64 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tiller
+
+import (
+	"k8s.io/helm/pkg/gate"
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+// gateOverrideKey is the metadata key a revision made outside of the
+// deployment windows, or against the gates, records the reason for the change
+// under.
+const gateOverrideKey = "gate-override"
+
+// checkGates rejects a change by operation to r that the windows and gates of
+// the environment do not allow now, unless override gives the reason for the
+// change. The reason is recorded in the metadata of r.
+func (s *ReleaseServer) checkGates(r *release.Release, operation, override string) error {
+	if s.env.Gates == nil {
+		return nil
+	}
+
+	req := &gate.Request{
+		Name:      r.Name,
+		Namespace: r.Namespace,
+		Revision:  r.Version,
+		Operation: operation,
+	}
+	if r.Chart != nil && r.Chart.Metadata != nil {
+		req.Chart = r.Chart.Metadata.Name
+		req.ChartVersion = r.Chart.Metadata.Version
+	}
+	err := s.env.Gates.Check(req)
+	if err == nil {
+		return nil
+	}
+	if override == "" {
+		s.logf("%s", err)
+		return err
+	}
+
+	s.logf("%s, overridden: %s", err, override)
+	md := map[string]string{}
+	for k, v := range r.Info.Metadata {
+		md[k] = v
+	}
+	md[gateOverrideKey] = override
+	r.Info.Metadata = md
+	return nil
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tiller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"k8s.io/helm/pkg/gate"
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/release"
+	"k8s.io/helm/pkg/proto/hapi/services"
+)
+
+// freezeGate rejects every change, and records the requests it is asked
+// about.
+func freezeGate(t *testing.T, asked *[]gate.Request) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req gate.Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("could not decode gate request: %s", err)
+		}
+		*asked = append(*asked, req)
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte("change freeze"))
+	}))
+}
+
+func TestInstallReleaseGateRejected(t *testing.T) {
+	var asked []gate.Request
+	srv := freezeGate(t, &asked)
+	defer srv.Close()
+
+	rs := rsFixture()
+	rs.env.Gates = &gate.Config{Gates: []*gate.Gate{{URL: srv.URL}}}
+
+	req := &services.InstallReleaseRequest{Name: "frozen", Namespace: "spaced", Chart: chartStub()}
+	_, err := rs.InstallRelease(helm.NewContext(), req)
+	if err == nil || !strings.Contains(err.Error(), "release frozen cannot be changed (install) now") || !strings.Contains(err.Error(), "change freeze") {
+		t.Fatalf("expected the install to be rejected, got %v", err)
+	}
+	if len(asked) != 1 || asked[0].Operation != "install" || asked[0].Namespace != "spaced" || asked[0].Chart != "hello" {
+		t.Errorf("unexpected gate requests: %+v", asked)
+	}
+	if rels, _ := rs.env.Releases.ListReleases(); len(rels) != 0 {
+		t.Errorf("expected nothing to be recorded, got %d releases", len(rels))
+	}
+
+	// Dry runs change nothing, so they are not checked.
+	req.DryRun = true
+	if _, err := rs.InstallRelease(helm.NewContext(), req); err != nil {
+		t.Errorf("Failed dry run install: %s", err)
+	}
+	if len(asked) != 1 {
+		t.Errorf("expected the dry run not to be checked, got %d gate requests", len(asked))
+	}
+}
+
+func TestUpdateReleaseGateOverridden(t *testing.T) {
+	var asked []gate.Request
+	srv := freezeGate(t, &asked)
+	defer srv.Close()
+
+	rs := rsFixture()
+	rel := releaseStub()
+	rs.env.Releases.Create(rel)
+	rs.env.Gates = &gate.Config{Gates: []*gate.Gate{{URL: srv.URL}}}
+
+	req := &services.UpdateReleaseRequest{Name: rel.Name, Chart: rel.Chart, OverrideGates: "security fix"}
+	res, err := rs.UpdateRelease(helm.NewContext(), req)
+	if err != nil {
+		t.Fatalf("Failed upgrade: %s", err)
+	}
+	if got := res.Release.Info.Metadata[gateOverrideKey]; got != "security fix" {
+		t.Errorf("expected the override to be recorded, got %q", got)
+	}
+	if len(asked) != 1 || asked[0].Operation != "upgrade" || asked[0].Revision != 2 {
+		t.Errorf("unexpected gate requests: %+v", asked)
+	}
+}
+
+func TestUninstallReleaseGateRejected(t *testing.T) {
+	var asked []gate.Request
+	srv := freezeGate(t, &asked)
+	defer srv.Close()
+
+	rs := rsFixture()
+	rel := releaseStub()
+	rs.env.Releases.Create(rel)
+	rs.env.Gates = &gate.Config{Gates: []*gate.Gate{{Scope: gate.Scope{Operations: []string{"delete"}}, URL: srv.URL}}}
+
+	_, err := rs.UninstallRelease(helm.NewContext(), &services.UninstallReleaseRequest{Name: rel.Name})
+	if err == nil || !strings.Contains(err.Error(), "cannot be changed (delete) now") {
+		t.Fatalf("expected the delete to be rejected, got %v", err)
+	}
+	if r, _ := rs.env.Releases.Get(rel.Name, rel.Version); r.Info.Status.Code != rel.Info.Status.Code {
+		t.Errorf("expected the release to be left alone, got %s", r.Info.Status.Code)
+	}
+}
+
+func TestSyncReleaseGateRejected(t *testing.T) {
+	var asked []gate.Request
+	srv := freezeGate(t, &asked)
+	defer srv.Close()
+
+	rs, kc := driftFixture()
+	rs.env.Gates = &gate.Config{Gates: []*gate.Gate{{URL: srv.URL}}}
+
+	req := &services.SyncReleaseRequest{Name: "angry-panda"}
+	if _, err := rs.SyncRelease(helm.NewContext(), req); err == nil || !strings.Contains(err.Error(), "(sync) now") {
+		t.Fatalf("expected the sync to be rejected, got %v", err)
+	}
+	if kc.created != "" || kc.target != "" {
+		t.Errorf("expected nothing to be changed, got created %q and target %q", kc.created, kc.target)
+	}
+
+	req.OverrideGates = "hotfix"
+	if _, err := rs.SyncRelease(helm.NewContext(), req); err != nil {
+		t.Errorf("expected the overridden sync to go ahead, got %v", err)
+	}
+	if len(asked) != 2 || asked[0].Operation != "sync" {
+		t.Errorf("unexpected gate requests: %+v", asked)
+	}
+}
+
+func TestImportReleaseGateRejected(t *testing.T) {
+	var asked []gate.Request
+	srv := freezeGate(t, &asked)
+	defer srv.Close()
+
+	rs := rsFixture()
+	rs.env.Gates = &gate.Config{Gates: []*gate.Gate{{URL: srv.URL}}}
+
+	req := &services.ImportReleaseRequest{Releases: []*release.Release{importStub(1, release.Status_DEPLOYED)}, Apply: true}
+	if _, err := rs.ImportRelease(helm.NewContext(), req); err == nil || !strings.Contains(err.Error(), "(import) now") {
+		t.Fatalf("expected the import to be rejected, got %v", err)
+	}
+	if h, _ := rs.env.Releases.History("angry-bird"); len(h) != 0 {
+		t.Errorf("expected nothing to be stored, got %d revisions", len(h))
+	}
+
+	req.OverrideGates = "restoring after INC-42"
+	res, err := rs.ImportRelease(helm.NewContext(), req)
+	if err != nil {
+		t.Fatalf("expected the overridden import to go ahead, got %v", err)
+	}
+	if res.Release.Info.Metadata[gateOverrideKey] != "restoring after INC-42" {
+		t.Errorf("expected the reason to be recorded, got %v", res.Release.Info.Metadata)
+	}
+	if len(asked) != 2 || asked[0].Operation != "import" {
+		t.Errorf("unexpected gate requests: %+v", asked)
+	}
+}
@@ -0,0 +1,336 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*Package gateway serves the release operations of Tiller's gRPC API as JSON
+over HTTP.
+
+The gateway is a client of Tiller like helm is: every HTTP request becomes a
+gRPC call carrying the caller's bearer token, which Tiller validates when it
+impersonates users, so it applies the same checks to both. Requests without a
+token are rejected, and the identity a request asks to impersonate is never
+passed on, so a caller can act only as itself. Responses are the
+gRPC responses encoded with encoding/json, so fields have the names of the
+protobuf definitions.
+*/
+package gateway // import "k8s.io/helm/pkg/tiller/gateway"
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+// Prefix is the path that the gateway serves the API under.
+const Prefix = "/v1/"
+
+// Gateway is an http.Handler that calls Tiller for every request.
+type Gateway struct {
+	// Client returns the client that calls Tiller on behalf of a request,
+	// configured with opts.
+	Client func(opts ...helm.Option) helm.Interface
+	// Authenticate is called before every request is passed on. If it
+	// returns an error, the request is rejected as unauthorized. If it is
+	// nil, RequireToken is used.
+	Authenticate func(*http.Request) error
+}
+
+// New returns a gateway to the Tiller listening on host.
+func New(host string) *Gateway {
+	return &Gateway{
+		Client: func(opts ...helm.Option) helm.Interface {
+			return helm.NewClient(append([]helm.Option{helm.Host(host)}, opts...)...)
+		},
+	}
+}
+
+// RequireToken is an Authenticate function that rejects requests without a
+// bearer token.
+func RequireToken(r *http.Request) error {
+	if bearerToken(r) == "" {
+		return errors.New("a bearer token is required")
+	}
+	return nil
+}
+
+// requestIDHeader is the header that identifies a request in Tiller's log.
+const requestIDHeader = "X-Request-Id"
+
+// errNotFound is returned for paths that the gateway does not serve.
+var errNotFound = errors.New("not found")
+
+// errMethod is returned for methods that a path does not support.
+var errMethod = errors.New("method not allowed")
+
+// ServeHTTP implements http.Handler.
+//
+// The gateway serves:
+//
+//	GET    /v1/version
+//	GET    /v1/releases?filter=&limit=&offset=&sort_by=&sort_order=&status=
+//	GET    /v1/releases/NAME?version=
+//	DELETE /v1/releases/NAME?purge=&dry_run=&disable_hooks=
+//	GET    /v1/releases/NAME/content?version=
+//	GET    /v1/releases/NAME/history?max=
+//	GET    /v1/releases/NAME/drift?version=
+//	POST   /v1/releases/NAME/rollback?version=&dry_run=&disable_hooks=
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	authenticate := g.Authenticate
+	if authenticate == nil {
+		authenticate = RequireToken
+	}
+	if err := authenticate(r); err != nil {
+		writeError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	// The request ID of the caller, if it sent one, is passed on to Tiller
+	// and returned, so that the request can be found in Tiller's log.
+	id := r.Header.Get(requestIDHeader)
+	if id == "" {
+		id = helm.NewRequestID()
+	}
+	w.Header().Set(requestIDHeader, id)
+
+	res, err := g.call(r, id)
+	switch {
+	case err == errNotFound:
+		writeError(w, http.StatusNotFound, err)
+	case err == errMethod:
+		writeError(w, http.StatusMethodNotAllowed, err)
+	case err != nil:
+		writeError(w, statusCode(err), errors.New(grpc.ErrorDesc(err)))
+	default:
+		writeJSON(w, http.StatusOK, res)
+	}
+}
+
+// call makes the gRPC call that r asks for.
+func (g *Gateway) call(r *http.Request, requestID string) (interface{}, error) {
+	if !strings.HasPrefix(r.URL.Path, Prefix) {
+		return nil, errNotFound
+	}
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, Prefix), "/"), "/")
+	q := r.URL.Query()
+	client := g.Client(helm.UserToken(bearerToken(r)), helm.RequestID(requestID))
+
+	switch {
+	case len(parts) == 1 && parts[0] == "version":
+		if r.Method != "GET" {
+			return nil, errMethod
+		}
+		return client.GetVersion()
+
+	case len(parts) == 1 && parts[0] == "releases":
+		if r.Method != "GET" {
+			return nil, errMethod
+		}
+		opts, err := listOptions(q)
+		if err != nil {
+			return nil, err
+		}
+		return client.ListReleases(opts...)
+
+	case len(parts) == 2 && parts[0] == "releases":
+		name := parts[1]
+		switch r.Method {
+		case "GET":
+			v, err := int32Param(q, "version")
+			if err != nil {
+				return nil, err
+			}
+			return client.ReleaseStatus(name, helm.StatusReleaseVersion(v))
+		case "DELETE":
+			b, err := boolParams(q, "purge", "dry_run", "disable_hooks")
+			if err != nil {
+				return nil, err
+			}
+			return client.DeleteRelease(name, helm.DeletePurge(b["purge"]), helm.DeleteDryRun(b["dry_run"]), helm.DeleteDisableHooks(b["disable_hooks"]))
+		}
+		return nil, errMethod
+
+	case len(parts) == 3 && parts[0] == "releases":
+		name := parts[1]
+		switch parts[2] {
+		case "content", "history", "drift":
+			if r.Method != "GET" {
+				return nil, errMethod
+			}
+		case "rollback":
+			if r.Method != "POST" {
+				return nil, errMethod
+			}
+		default:
+			return nil, errNotFound
+		}
+
+		param := "version"
+		if parts[2] == "history" {
+			param = "max"
+		}
+		n, err := int32Param(q, param)
+		if err != nil {
+			return nil, err
+		}
+		switch parts[2] {
+		case "content":
+			return client.ReleaseContent(name, helm.ContentReleaseVersion(n))
+		case "history":
+			if n == 0 {
+				n = 256
+			}
+			return client.ReleaseHistory(name, helm.WithMaxHistory(n))
+		case "drift":
+			return client.ReleaseDrift(name, helm.DriftReleaseVersion(n))
+		default:
+			b, err := boolParams(q, "dry_run", "disable_hooks")
+			if err != nil {
+				return nil, err
+			}
+			return client.RollbackRelease(name, helm.RollbackVersion(n), helm.RollbackDryRun(b["dry_run"]), helm.RollbackDisableHooks(b["disable_hooks"]))
+		}
+	}
+	return nil, errNotFound
+}
+
+func listOptions(q map[string][]string) ([]helm.ReleaseListOption, error) {
+	opts := []helm.ReleaseListOption{
+		helm.ReleaseListFilter(first(q, "filter")),
+		helm.ReleaseListOffset(first(q, "offset")),
+	}
+	for _, p := range []struct {
+		name string
+		opt  func(int32) helm.ReleaseListOption
+	}{
+		{"limit", func(n int32) helm.ReleaseListOption { return helm.ReleaseListLimit(int(n)) }},
+		{"sort_by", helm.ReleaseListSort},
+		{"sort_order", helm.ReleaseListOrder},
+	} {
+		n, err := int32Param(q, p.name)
+		if err != nil {
+			return nil, err
+		}
+		if n != 0 {
+			opts = append(opts, p.opt(n))
+		}
+	}
+
+	codes := []release.Status_Code{}
+	for _, s := range q["status"] {
+		code, ok := release.Status_Code_value[strings.ToUpper(s)]
+		if !ok {
+			return nil, badRequest(fmt.Errorf("unknown status %q", s))
+		}
+		codes = append(codes, release.Status_Code(code))
+	}
+	if len(codes) > 0 {
+		opts = append(opts, helm.ReleaseListStatuses(codes))
+	}
+	return opts, nil
+}
+
+// paramError is an invalid query parameter.
+type paramError struct{ error }
+
+func badRequest(err error) error { return paramError{err} }
+
+func first(q map[string][]string, name string) string {
+	if v := q[name]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func int32Param(q map[string][]string, name string) (int32, error) {
+	v := first(q, name)
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(v, 10, 32)
+	if err != nil {
+		return 0, badRequest(fmt.Errorf("invalid %s %q: must be a number", name, v))
+	}
+	return int32(n), nil
+}
+
+// boolParams parses the named boolean parameters. Missing ones are false.
+func boolParams(q map[string][]string, names ...string) (map[string]bool, error) {
+	res := map[string]bool{}
+	for _, name := range names {
+		v := first(q, name)
+		if v == "" {
+			continue
+		}
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, badRequest(fmt.Errorf("invalid %s %q: must be true or false", name, v))
+		}
+		res[name] = b
+	}
+	return res, nil
+}
+
+func bearerToken(r *http.Request) string {
+	const prefix = "Bearer "
+	if h := r.Header.Get("Authorization"); strings.HasPrefix(h, prefix) {
+		return strings.TrimSpace(h[len(prefix):])
+	}
+	return ""
+}
+
+// statusCode maps the error of a gRPC call to an HTTP status code.
+func statusCode(err error) int {
+	if _, ok := err.(paramError); ok {
+		return http.StatusBadRequest
+	}
+	switch grpc.Code(err) {
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	}
+	// Tiller reports most errors without a code, missing releases among them.
+	if strings.Contains(grpc.ErrorDesc(err), "not found") {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
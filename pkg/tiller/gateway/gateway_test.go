@@ -0,0 +1,196 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gateway
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/release"
+	rls "k8s.io/helm/pkg/proto/hapi/services"
+)
+
+// fakeClient answers the calls the tests make. Anything else panics.
+type fakeClient struct {
+	helm.Interface
+	rels []*release.Release
+}
+
+func (c *fakeClient) ListReleases(opts ...helm.ReleaseListOption) (*rls.ListReleasesResponse, error) {
+	return &rls.ListReleasesResponse{Count: int64(len(c.rels)), Releases: c.rels}, nil
+}
+
+func (c *fakeClient) ReleaseStatus(name string, opts ...helm.StatusOption) (*rls.GetReleaseStatusResponse, error) {
+	for _, r := range c.rels {
+		if r.Name == name {
+			return &rls.GetReleaseStatusResponse{Name: r.Name, Namespace: r.Namespace, Info: r.Info}, nil
+		}
+	}
+	return nil, errors.New("getting deployed release \"" + name + "\": release: not found")
+}
+
+func serve(g *Gateway, method, url string, header http.Header) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(method, url, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	w := httptest.NewRecorder()
+	g.ServeHTTP(w, req)
+	return w
+}
+
+func TestGatewayResponses(t *testing.T) {
+	fake := &fakeClient{rels: []*release.Release{{
+		Name:      "web",
+		Namespace: "default",
+		Info:      &release.Info{Status: &release.Status{Code: release.Status_DEPLOYED}},
+	}}}
+	g := &Gateway{Client: func(...helm.Option) helm.Interface { return fake }}
+
+	tests := []struct {
+		method, url string
+		code        int
+		body        string
+	}{
+		{"GET", "/v1/releases", 200, `"releases":[{"name":"web"`},
+		{"GET", "/v1/releases/web", 200, `"namespace":"default"`},
+		{"GET", "/v1/releases/db", 404, `"error":"getting deployed release \"db\": release: not found"`},
+		{"GET", "/v1/releases/web/nothing", 404, `"error":"not found"`},
+		{"PUT", "/v1/releases", 405, `"error":"method not allowed"`},
+		{"POST", "/v1/releases/web/content", 405, `"error":"method not allowed"`},
+		{"GET", "/v1/releases?limit=ten", 400, `invalid limit \"ten\": must be a number`},
+		{"GET", "/v1/releases?status=broken", 400, `unknown status \"broken\"`},
+		{"GET", "/healthz", 404, `"error":"not found"`},
+	}
+	header := http.Header{"Authorization": {"Bearer s3cr3t"}}
+	for _, tt := range tests {
+		w := serve(g, tt.method, tt.url, header)
+		if w.Code != tt.code {
+			t.Errorf("%s %s: expected status %d, got %d", tt.method, tt.url, tt.code, w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("%s %s: expected JSON, got %q", tt.method, tt.url, ct)
+		}
+		if !strings.Contains(w.Body.String(), tt.body) {
+			t.Errorf("%s %s: expected the body to contain %s, got %s", tt.method, tt.url, tt.body, w.Body.String())
+		}
+	}
+}
+
+// errSkip keeps the helm client from dialing Tiller.
+var errSkip = errors.New("test: skip")
+
+func TestGatewayRequests(t *testing.T) {
+	var msg proto.Message
+	var md metadata.MD
+	g := &Gateway{Client: func(opts ...helm.Option) helm.Interface {
+		return helm.NewClient(append(opts, helm.BeforeCall(func(c context.Context, m proto.Message) error {
+			msg = m
+			md, _ = metadata.FromContext(c)
+			return errSkip
+		}))...)
+	}}
+
+	tests := []struct {
+		method, url string
+		expect      proto.Message
+	}{
+		{"GET", "/v1/version", &rls.GetVersionRequest{}},
+		{"GET", "/v1/releases?filter=^w&limit=5&sort_by=2&status=deployed&status=FAILED", &rls.ListReleasesRequest{
+			Filter:      "^w",
+			Limit:       5,
+			SortBy:      rls.ListSort_SortBy(2),
+			StatusCodes: []release.Status_Code{release.Status_DEPLOYED, release.Status_FAILED},
+		}},
+		{"GET", "/v1/releases/web?version=2", &rls.GetReleaseStatusRequest{Name: "web", Version: 2}},
+		{"GET", "/v1/releases/web/content?version=3", &rls.GetReleaseContentRequest{Name: "web", Version: 3}},
+		{"GET", "/v1/releases/web/history", &rls.GetHistoryRequest{Name: "web", Max: 256}},
+		{"GET", "/v1/releases/web/drift", &rls.GetReleaseDriftRequest{Name: "web"}},
+		{"POST", "/v1/releases/web/rollback?version=1&dry_run=true", &rls.RollbackReleaseRequest{Name: "web", Version: 1, DryRun: true}},
+		{"DELETE", "/v1/releases/web?purge=true", &rls.UninstallReleaseRequest{Name: "web", Purge: true}},
+	}
+	header := http.Header{
+		"Authorization":     {"Bearer s3cr3t"},
+		"Impersonate-User":  {"ci"},
+		"Impersonate-Group": {"dev", "ops"},
+		"X-Request-Id":      {"0123456789abcdef"},
+	}
+	for _, tt := range tests {
+		msg, md = nil, nil
+		w := serve(g, tt.method, tt.url, header)
+		if w.Code != http.StatusInternalServerError || !strings.Contains(w.Body.String(), errSkip.Error()) {
+			t.Errorf("%s %s: expected the call to be made, got %d %s", tt.method, tt.url, w.Code, w.Body.String())
+		}
+		if !reflect.DeepEqual(msg, tt.expect) {
+			t.Errorf("%s %s: expected request %#v, got %#v", tt.method, tt.url, tt.expect, msg)
+		}
+		if !reflect.DeepEqual(md["x-helm-user-token"], []string{"s3cr3t"}) {
+			t.Errorf("%s %s: expected the caller's token to be sent, got %v", tt.method, tt.url, md)
+		}
+		if _, ok := md["x-helm-as-user"]; ok {
+			t.Errorf("%s %s: expected the identity the caller asks for not to be sent, got %v", tt.method, tt.url, md)
+		}
+		if _, ok := md["x-helm-as-group"]; ok {
+			t.Errorf("%s %s: expected the groups the caller asks for not to be sent, got %v", tt.method, tt.url, md)
+		}
+		if !reflect.DeepEqual(md["x-helm-request-id"], []string{"0123456789abcdef"}) || w.Header().Get("X-Request-Id") != "0123456789abcdef" {
+			t.Errorf("%s %s: expected the request ID to be passed on and returned, got %v and %q", tt.method, tt.url, md, w.Header().Get("X-Request-Id"))
+		}
+	}
+
+	w := serve(g, "GET", "/v1/version", http.Header{"Authorization": {"Bearer s3cr3t"}})
+	if id := w.Header().Get("X-Request-Id"); id == "" || !reflect.DeepEqual(md["x-helm-request-id"], []string{id}) {
+		t.Errorf("expected a request ID to be made up and returned, got %v and %q", md, id)
+	}
+}
+
+func TestGatewayAuthenticate(t *testing.T) {
+	g := &Gateway{Client: func(...helm.Option) helm.Interface { return &fakeClient{} }}
+
+	for _, h := range []http.Header{nil, {"Authorization": {"Bearer "}}, {"Authorization": {"Basic czNjcjN0"}}} {
+		if w := serve(g, "GET", "/v1/releases", h); w.Code != http.StatusUnauthorized {
+			t.Errorf("expected a request with %v to be rejected, got %d", h, w.Code)
+		}
+	}
+
+	w := serve(g, "GET", "/v1/releases", nil)
+	var body map[string]string
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body["error"] != "a bearer token is required" {
+		t.Errorf("unexpected body %s", w.Body.String())
+	}
+
+	w = serve(g, "GET", "/v1/releases", http.Header{"Authorization": {"Bearer s3cr3t"}})
+	if w.Code != http.StatusOK {
+		t.Errorf("expected a request with a token to be served, got %d", w.Code)
+	}
+
+	g.Authenticate = func(*http.Request) error { return errors.New("token was not accepted") }
+	w = serve(g, "GET", "/v1/releases", http.Header{"Authorization": {"Bearer s3cr3t"}})
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected a request that Authenticate rejects to be rejected, got %d", w.Code)
+	}
+}
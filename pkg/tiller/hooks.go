@@ -18,11 +18,12 @@ package tiller
 
 import (
 	"fmt"
-	"log"
 	"path"
 	"strings"
 
 	"github.com/ghodss/yaml"
+
+	"k8s.io/helm/pkg/chartutil"
 	"k8s.io/helm/pkg/proto/hapi/release"
 )
 
@@ -60,21 +61,6 @@ type simpleHead struct {
 	} `json:"metadata,omitempty"`
 }
 
-type versionSet map[string]struct{}
-
-func newVersionSet(apiVersions ...string) versionSet {
-	vs := versionSet{}
-	for _, v := range apiVersions {
-		vs[v] = struct{}{}
-	}
-	return vs
-}
-
-func (v versionSet) Has(apiVersion string) bool {
-	_, ok := v[apiVersion]
-	return ok
-}
-
 // manifest represents a manifest file, which has a name and some content.
 type manifest struct {
 	name    string
@@ -103,7 +89,7 @@ type manifest struct {
 //
 // Files that do not parse into the expected format are simply placed into a map and
 // returned.
-func sortManifests(files map[string]string, apis versionSet, sort SortOrder) ([]*release.Hook, []manifest, error) {
+func (s *ReleaseServer) sortManifests(files map[string]string, apis chartutil.VersionSet, sort SortOrder) ([]*release.Hook, []manifest, error) {
 	hs := []*release.Hook{}
 	generic := []manifest{}
 
@@ -115,7 +101,7 @@ func sortManifests(files map[string]string, apis versionSet, sort SortOrder) ([]
 		}
 		// Skip empty files, and log this.
 		if len(strings.TrimSpace(c)) == 0 {
-			log.Printf("info: manifest %q is empty. Skipping.", n)
+			s.logf("info: manifest %q is empty. Skipping.", n)
 			continue
 		}
 
@@ -160,7 +146,7 @@ func sortManifests(files map[string]string, apis versionSet, sort SortOrder) ([]
 		}
 
 		if !isHook {
-			log.Printf("info: skipping unknown hook: %q", hookTypes)
+			s.logf("info: skipping unknown hook: %q", hookTypes)
 			continue
 		}
 		hs = append(hs, h)
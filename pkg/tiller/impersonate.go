@@ -0,0 +1,239 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tiller
+
+import (
+	"errors"
+	"fmt"
+
+	ctx "golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+	"k8s.io/kubernetes/pkg/apis/authentication"
+	"k8s.io/kubernetes/pkg/apis/authorization"
+
+	"k8s.io/helm/pkg/kube"
+	"k8s.io/helm/pkg/tiller/environment"
+)
+
+// userTokenKey is the metadata key the client sends its Kubernetes bearer
+// token under.
+const userTokenKey = "x-helm-user-token"
+
+// asUserKey and asGroupKey are the metadata keys the client sends the
+// identity it asks Tiller to impersonate under.
+const (
+	asUserKey  = "x-helm-as-user"
+	asGroupKey = "x-helm-as-group"
+)
+
+var errMissingUserToken = errors.New("tiller acts as the calling user, but the client sent no Kubernetes bearer token")
+
+var errGroupWithoutUser = errors.New("impersonating a group requires a user to impersonate")
+
+func getUserToken(c ctx.Context) string {
+	if md, ok := metadata.FromContext(c); ok {
+		if v, ok := md[userTokenKey]; ok {
+			return v[0]
+		}
+	}
+	return ""
+}
+
+// getImpersonation returns the user and groups the client asked for, if any.
+func getImpersonation(c ctx.Context) (string, []string) {
+	md, ok := metadata.FromContext(c)
+	if !ok {
+		return "", nil
+	}
+	user := ""
+	if v, ok := md[asUserKey]; ok {
+		user = v[0]
+	}
+	return user, md[asGroupKey]
+}
+
+// impersonating returns a release server that acts on the cluster as the user
+// who sent the request, if the server impersonates users, and s otherwise.
+//
+// The user is the one the Kubernetes API server authenticates the client's
+// bearer token as, so RBAC decides what the request may do. Releases are still
+// stored as Tiller.
+//
+// A client may also ask for another user and groups to be impersonated. When
+// the server impersonates users, the caller must be allowed to impersonate
+// them; otherwise Tiller's own service account needs the permission.
+func (s *ReleaseServer) impersonating(c ctx.Context) (*ReleaseServer, error) {
+	user, groups := getImpersonation(c)
+	if user == "" && len(groups) > 0 {
+		return nil, errGroupWithoutUser
+	}
+	if !s.ImpersonateUsers && user == "" {
+		return s, nil
+	}
+
+	if s.ImpersonateUsers {
+		caller, err := s.authenticate(getUserToken(c))
+		if err != nil {
+			return nil, err
+		}
+		if user == "" {
+			user, groups = caller.Username, caller.Groups
+		} else if err := s.authorizeImpersonation(caller, user, groups); err != nil {
+			return nil, err
+		}
+	}
+	s.logf("Acting as user %q", user)
+
+	cp := *s
+	cp.actingAs = &authentication.UserInfo{Username: user, Groups: groups}
+	// Other KubeClients, such as the printing one, have no identity to change.
+	if kc, ok := s.env.KubeClient.(*kube.Client); ok {
+		env := *s.env
+		env.KubeClient = kc.Impersonate(user, groups)
+		cp.env = &env
+	}
+	return &cp, nil
+}
+
+// authenticate returns the user the Kubernetes API server authenticates token
+// as.
+func (s *ReleaseServer) authenticate(token string) (authentication.UserInfo, error) {
+	if token == "" {
+		return authentication.UserInfo{}, errMissingUserToken
+	}
+
+	cli, err := s.env.KubeClient.APIClient()
+	if err != nil {
+		return authentication.UserInfo{}, err
+	}
+	review, err := cli.Authentication().TokenReviews().Create(&authentication.TokenReview{
+		Spec: authentication.TokenReviewSpec{Token: token},
+	})
+	if err != nil {
+		return authentication.UserInfo{}, fmt.Errorf("cannot authenticate user: %s", err)
+	}
+	if !review.Status.Authenticated {
+		if review.Status.Error != "" {
+			return authentication.UserInfo{}, fmt.Errorf("user token was not accepted: %s", review.Status.Error)
+		}
+		return authentication.UserInfo{}, errors.New("user token was not accepted")
+	}
+	return review.Status.User, nil
+}
+
+// authorizeImpersonation checks that caller may impersonate user and each of
+// groups, the same check the API server makes for impersonating requests.
+func (s *ReleaseServer) authorizeImpersonation(caller authentication.UserInfo, user string, groups []string) error {
+	check := func(resource, name string) error {
+		allowed, err := s.allowed(caller, &authorization.ResourceAttributes{
+			Verb:     "impersonate",
+			Resource: resource,
+			Name:     name,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot authorize impersonation: %s", err)
+		}
+		if !allowed {
+			return fmt.Errorf("user %q may not impersonate %s %q", caller.Username, resource[:len(resource)-1], name)
+		}
+		return nil
+	}
+
+	if err := check("users", user); err != nil {
+		return err
+	}
+	for _, g := range groups {
+		if err := check("groups", g); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// authorizeRead checks, when the server impersonates users, that the user the
+// request acts as may perform verb ("get" or "list") on the config maps that
+// Tiller stores releases in: on the record called name, or on all of them if
+// name is empty. Reading a release does not touch the cluster, so this is the
+// only check RBAC gets to make. The check is made whatever storage driver
+// Tiller uses.
+func (s *ReleaseServer) authorizeRead(verb, name string) error {
+	if !s.ImpersonateUsers || s.actingAs == nil {
+		return nil
+	}
+	allowed, err := s.allowed(*s.actingAs, &authorization.ResourceAttributes{
+		Namespace: environment.TillerNamespace,
+		Verb:      verb,
+		Resource:  "configmaps",
+		Name:      name,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot authorize reading releases: %s", err)
+	}
+	if !allowed {
+		return fmt.Errorf("user %q may not read the releases stored in %q", s.actingAs.Username, environment.TillerNamespace)
+	}
+	return nil
+}
+
+// authorizeWrite is the counterpart of authorizeRead for requests that change
+// the release records themselves, rather than the cluster: it checks that the
+// user the request acts as may perform verb ("create", "update" or "delete")
+// on the record called name, or on all of them if name is empty.
+func (s *ReleaseServer) authorizeWrite(verb, name string) error {
+	if !s.ImpersonateUsers || s.actingAs == nil {
+		return nil
+	}
+	allowed, err := s.allowed(*s.actingAs, &authorization.ResourceAttributes{
+		Namespace: environment.TillerNamespace,
+		Verb:      verb,
+		Resource:  "configmaps",
+		Name:      name,
+	})
+	if err != nil {
+		return fmt.Errorf("cannot authorize changing releases: %s", err)
+	}
+	if !allowed {
+		return fmt.Errorf("user %q may not change the releases stored in %q", s.actingAs.Username, environment.TillerNamespace)
+	}
+	return nil
+}
+
+// allowed asks the API server whether user may act on the resource described
+// by attrs.
+func (s *ReleaseServer) allowed(user authentication.UserInfo, attrs *authorization.ResourceAttributes) (bool, error) {
+	cli, err := s.env.KubeClient.APIClient()
+	if err != nil {
+		return false, err
+	}
+	review, err := cli.Authorization().SubjectAccessReviews().Create(&authorization.SubjectAccessReview{
+		Spec: authorization.SubjectAccessReviewSpec{
+			ResourceAttributes: attrs,
+			User:               user.Username,
+			Groups:             user.Groups,
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	return review.Status.Allowed, nil
+}
+
+// recordName returns the name the storage gives the record of version of the
+// release called name.
+func recordName(name string, version int32) string {
+	return fmt.Sprintf("%s.v%d", name, version)
+}
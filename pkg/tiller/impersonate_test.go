@@ -0,0 +1,292 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tiller
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+	"k8s.io/kubernetes/pkg/apis/authentication"
+	"k8s.io/kubernetes/pkg/apis/authorization"
+	"k8s.io/kubernetes/pkg/client/unversioned"
+	"k8s.io/kubernetes/pkg/client/unversioned/testclient"
+	"k8s.io/kubernetes/pkg/runtime"
+
+	"k8s.io/helm/pkg/proto/hapi/release"
+	"k8s.io/helm/pkg/proto/hapi/services"
+	"k8s.io/helm/pkg/tiller/environment"
+	"k8s.io/helm/pkg/version"
+)
+
+// tokenReviewKubeClient authenticates the token "good" as jane, and lets
+// anyone impersonate the user "ci" and the group "dev", read the release
+// records if canRead is set and change them if canWrite is.
+type tokenReviewKubeClient struct {
+	environment.PrintingKubeClient
+	reviewed   []string
+	authorized []string
+	canRead    bool
+	canWrite   bool
+}
+
+func (k *tokenReviewKubeClient) APIClient() (unversioned.Interface, error) {
+	fake := testclient.NewSimpleFake()
+	fake.PrependReactor("create", "tokenreviews", func(action testclient.Action) (bool, runtime.Object, error) {
+		review := action.(testclient.CreateAction).GetObject().(*authentication.TokenReview)
+		k.reviewed = append(k.reviewed, review.Spec.Token)
+		if review.Spec.Token == "good" {
+			review.Status.Authenticated = true
+			review.Status.User = authentication.UserInfo{Username: "jane", Groups: []string{"dev"}}
+		} else {
+			review.Status.Error = "invalid bearer token"
+		}
+		return true, review, nil
+	})
+	fake.PrependReactor("create", "subjectaccessreviews", func(action testclient.Action) (bool, runtime.Object, error) {
+		review := action.(testclient.CreateAction).GetObject().(*authorization.SubjectAccessReview)
+		attrs := review.Spec.ResourceAttributes
+		k.authorized = append(k.authorized, review.Spec.User+" "+attrs.Verb+" "+attrs.Resource+"/"+attrs.Name)
+		if attrs.Resource == "configmaps" {
+			can := k.canWrite
+			if attrs.Verb == "get" || attrs.Verb == "list" {
+				can = k.canRead
+			}
+			review.Status.Allowed = can && attrs.Namespace == environment.TillerNamespace
+		} else {
+			review.Status.Allowed = attrs.Name == "ci" || attrs.Name == "dev"
+		}
+		return true, review, nil
+	})
+	return fake, nil
+}
+
+func userContext(token string) context.Context {
+	md := metadata.Pairs("x-helm-api-client", version.Version, userTokenKey, token)
+	return metadata.NewContext(context.TODO(), md)
+}
+
+func impersonationContext(token, user string, groups ...string) context.Context {
+	md := metadata.Pairs("x-helm-api-client", version.Version, userTokenKey, token, asUserKey, user)
+	if len(groups) > 0 {
+		md[asGroupKey] = groups
+	}
+	return metadata.NewContext(context.TODO(), md)
+}
+
+func TestImpersonatingDisabled(t *testing.T) {
+	rs := rsFixture()
+	got, err := rs.impersonating(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != rs {
+		t.Error("expected the server itself when not impersonating")
+	}
+}
+
+func TestImpersonatingWithoutToken(t *testing.T) {
+	rs := rsFixture()
+	rs.ImpersonateUsers = true
+	if _, err := rs.impersonating(userContext("")); err != errMissingUserToken {
+		t.Errorf("expected %q, got %v", errMissingUserToken, err)
+	}
+}
+
+func TestImpersonatingRejectedToken(t *testing.T) {
+	rs := rsFixture()
+	rs.ImpersonateUsers = true
+	kc := &tokenReviewKubeClient{PrintingKubeClient: environment.PrintingKubeClient{Out: os.Stdout}}
+	rs.env.KubeClient = kc
+
+	_, err := rs.impersonating(userContext("bad"))
+	if err == nil || !strings.Contains(err.Error(), "invalid bearer token") {
+		t.Errorf("expected the token to be rejected, got %v", err)
+	}
+	if len(kc.reviewed) != 1 || kc.reviewed[0] != "bad" {
+		t.Errorf("expected the token to be reviewed, got %v", kc.reviewed)
+	}
+}
+
+func TestInstallReleaseImpersonating(t *testing.T) {
+	rs := rsFixture()
+	rs.ImpersonateUsers = true
+	kc := &tokenReviewKubeClient{PrintingKubeClient: environment.PrintingKubeClient{Out: os.Stdout}}
+	rs.env.KubeClient = kc
+
+	req := &services.InstallReleaseRequest{Namespace: "spaced", Chart: chartStub()}
+	if _, err := rs.InstallRelease(userContext("good"), req); err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+	if len(kc.reviewed) != 1 || kc.reviewed[0] != "good" {
+		t.Errorf("expected the token to be reviewed, got %v", kc.reviewed)
+	}
+
+	if _, err := rs.InstallRelease(userContext(""), req); err != errMissingUserToken {
+		t.Errorf("expected anonymous installs to be refused, got %v", err)
+	}
+}
+
+func TestImpersonatingGroupWithoutUser(t *testing.T) {
+	rs := rsFixture()
+	if _, err := rs.impersonating(impersonationContext("", "", "dev")); err != errGroupWithoutUser {
+		t.Errorf("expected %q, got %v", errGroupWithoutUser, err)
+	}
+}
+
+func TestImpersonatingRequestedUser(t *testing.T) {
+	rs := rsFixture()
+	rs.ImpersonateUsers = true
+	kc := &tokenReviewKubeClient{PrintingKubeClient: environment.PrintingKubeClient{Out: os.Stdout}}
+	rs.env.KubeClient = kc
+
+	if _, err := rs.impersonating(impersonationContext("good", "ci", "dev")); err != nil {
+		t.Fatal(err)
+	}
+	expect := []string{"jane impersonate users/ci", "jane impersonate groups/dev"}
+	if !reflect.DeepEqual(kc.authorized, expect) {
+		t.Errorf("expected access reviews %v, got %v", expect, kc.authorized)
+	}
+}
+
+func TestImpersonatingForbiddenUser(t *testing.T) {
+	rs := rsFixture()
+	rs.ImpersonateUsers = true
+	kc := &tokenReviewKubeClient{PrintingKubeClient: environment.PrintingKubeClient{Out: os.Stdout}}
+	rs.env.KubeClient = kc
+
+	_, err := rs.impersonating(impersonationContext("good", "ci", "admins"))
+	if err == nil || !strings.Contains(err.Error(), `user "jane" may not impersonate group "admins"`) {
+		t.Errorf("expected the impersonation to be refused, got %v", err)
+	}
+
+	// Without a token the caller cannot be checked.
+	if _, err := rs.impersonating(impersonationContext("", "ci")); err != errMissingUserToken {
+		t.Errorf("expected %q, got %v", errMissingUserToken, err)
+	}
+}
+
+func TestReadsImpersonating(t *testing.T) {
+	rs := rsFixture()
+	rs.ImpersonateUsers = true
+	kc := &tokenReviewKubeClient{PrintingKubeClient: environment.PrintingKubeClient{Out: os.Stdout}}
+	rs.env.KubeClient = kc
+	rel := releaseStub()
+	rs.env.Releases.Create(rel)
+
+	c := userContext("good")
+	read := func() []error {
+		_, contentErr := rs.GetReleaseContent(c, &services.GetReleaseContentRequest{Name: rel.Name})
+		_, historyErr := rs.GetHistory(c, &services.GetHistoryRequest{Name: rel.Name, Max: 10})
+		listErr := rs.ListReleases(&services.ListReleasesRequest{}, &mockListServer{ctx: c})
+		return []error{contentErr, historyErr, listErr}
+	}
+
+	for i, err := range read() {
+		if err == nil || !strings.Contains(err.Error(), `user "jane" may not read the releases`) {
+			t.Errorf("read %d: expected the read to be refused, got %v", i, err)
+		}
+	}
+	expect := []string{"jane get configmaps/angry-panda.v1", "jane list configmaps/", "jane list configmaps/"}
+	if !reflect.DeepEqual(kc.authorized, expect) {
+		t.Errorf("expected access reviews %v, got %v", expect, kc.authorized)
+	}
+
+	kc.canRead = true
+	for i, err := range read() {
+		if err != nil {
+			t.Errorf("read %d: expected the read to be allowed, got %v", i, err)
+		}
+	}
+
+	// Without a token the reader cannot be checked.
+	if _, err := rs.GetReleaseContent(userContext(""), &services.GetReleaseContentRequest{Name: rel.Name}); err != errMissingUserToken {
+		t.Errorf("expected %q, got %v", errMissingUserToken, err)
+	}
+}
+
+func TestImportReleaseImpersonating(t *testing.T) {
+	rs := rsFixture()
+	rs.ImpersonateUsers = true
+	kc := &tokenReviewKubeClient{PrintingKubeClient: environment.PrintingKubeClient{Out: os.Stdout}}
+	rs.env.KubeClient = kc
+
+	req := &services.ImportReleaseRequest{Releases: []*release.Release{importStub(1, release.Status_DEPLOYED)}}
+	if _, err := rs.ImportRelease(userContext("good"), req); err == nil || !strings.Contains(err.Error(), `user "jane" may not change the releases`) {
+		t.Errorf("expected the import to be refused, got %v", err)
+	}
+	if h, _ := rs.env.Releases.History("angry-bird"); len(h) != 0 {
+		t.Errorf("expected nothing to be stored, got %d revisions", len(h))
+	}
+	if expect := []string{"jane create configmaps/"}; !reflect.DeepEqual(kc.authorized, expect) {
+		t.Errorf("expected access reviews %v, got %v", expect, kc.authorized)
+	}
+
+	kc.canWrite = true
+	if _, err := rs.ImportRelease(userContext("good"), req); err != nil {
+		t.Errorf("expected the import to be allowed, got %v", err)
+	}
+}
+
+func TestMaintainStorageImpersonating(t *testing.T) {
+	rs := rsFixture()
+	rs.ImpersonateUsers = true
+	kc := &tokenReviewKubeClient{PrintingKubeClient: environment.PrintingKubeClient{Out: os.Stdout}, canRead: true}
+	rs.env.KubeClient = kc
+	rs.env.Releases.Create(releaseStub())
+
+	c := userContext("good")
+	if _, err := rs.MaintainStorage(c, &services.MaintainStorageRequest{MaxHistory: 1}); err == nil || !strings.Contains(err.Error(), `user "jane" may not change the releases`) {
+		t.Errorf("expected maintenance to be refused, got %v", err)
+	}
+	if _, err := rs.MaintainStorage(c, &services.MaintainStorageRequest{MaxHistory: 1, DryRun: true}); err != nil {
+		t.Errorf("expected a dry run to be allowed to readers, got %v", err)
+	}
+	if expect := []string{"jane delete configmaps/", "jane list configmaps/"}; !reflect.DeepEqual(kc.authorized, expect) {
+		t.Errorf("expected access reviews %v, got %v", expect, kc.authorized)
+	}
+
+	kc.canWrite = true
+	if _, err := rs.MaintainStorage(c, &services.MaintainStorageRequest{MaxHistory: 1}); err != nil {
+		t.Errorf("expected maintenance to be allowed, got %v", err)
+	}
+}
+
+func TestSetReleaseLockImpersonating(t *testing.T) {
+	rs := rsFixture()
+	rs.ImpersonateUsers = true
+	kc := &tokenReviewKubeClient{PrintingKubeClient: environment.PrintingKubeClient{Out: os.Stdout}}
+	rs.env.KubeClient = kc
+	rel := releaseStub()
+	rs.env.Releases.Create(rel)
+
+	req := &services.SetReleaseLockRequest{Name: rel.Name, Unlock: true, Reason: "hotfix"}
+	if _, err := rs.SetReleaseLock(userContext("good"), req); err == nil || !strings.Contains(err.Error(), `user "jane" may not change the releases`) {
+		t.Errorf("expected the unlock to be refused, got %v", err)
+	}
+	if expect := []string{"jane update configmaps/angry-panda.v1"}; !reflect.DeepEqual(kc.authorized, expect) {
+		t.Errorf("expected access reviews %v, got %v", expect, kc.authorized)
+	}
+
+	kc.canWrite = true
+	if _, err := rs.SetReleaseLock(userContext("good"), req); err != nil {
+		t.Errorf("expected the unlock to be allowed, got %v", err)
+	}
+}
@@ -38,6 +38,22 @@ func sortByKind(manifests []manifest, ordering SortOrder) []manifest {
 	return ks.manifests
 }
 
+// teardownBatches groups manifests, sorted by InstallOrder, by kind, in the
+// reverse of that order. Kinds that InstallOrder does not know of are
+// installed last, so they come first.
+func teardownBatches(manifests []manifest) [][]manifest {
+	var batches [][]manifest
+	for i := len(manifests) - 1; i >= 0; i-- {
+		m := manifests[i]
+		if n := len(batches); n > 0 && batches[n-1][0].head.Kind == m.head.Kind {
+			batches[n-1] = append(batches[n-1], m)
+			continue
+		}
+		batches = append(batches, []manifest{m})
+	}
+	return batches
+}
+
 type kindSorter struct {
 	ordering  map[string]int
 	manifests []manifest
@@ -70,3 +70,24 @@ func TestKindSorter(t *testing.T) {
 	}
 
 }
+
+func TestTeardownBatches(t *testing.T) {
+	manifests := []manifest{
+		{name: "a", head: &simpleHead{Kind: "ConfigMap"}},
+		{name: "b", head: &simpleHead{Kind: "Service"}},
+		{name: "c", head: &simpleHead{Kind: "Deployment"}},
+		{name: "d", head: &simpleHead{Kind: "Deployment"}},
+		{name: "e", head: &simpleHead{Kind: "Widget"}},
+	}
+
+	got := ""
+	for _, batch := range teardownBatches(manifests) {
+		for _, m := range batch {
+			got += m.name
+		}
+		got += " "
+	}
+	if expect := "e dc b a "; got != expect {
+		t.Errorf("Expected %q, got %q", expect, got)
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tiller
+
+import (
+	"path"
+	"time"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	"k8s.io/helm/pkg/metrics"
+)
+
+// releaseBuckets are histogram buckets, in seconds, for release operations,
+// which may wait for resources to become ready.
+var releaseBuckets = []float64{.1, .5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600}
+
+var (
+	releaseOperations = metrics.NewCounter("tiller_release_operations_total",
+		"Release operations by result, success or failure.", "operation", "result")
+	releaseDuration = metrics.NewHistogram("tiller_release_operation_duration_seconds",
+		"Time taken by release operations.", releaseBuckets, "operation")
+	releasesInFlight = metrics.NewGauge("tiller_release_operations_in_flight",
+		"Release operations in progress.", "operation")
+	grpcDuration = metrics.NewHistogram("tiller_grpc_request_duration_seconds",
+		"Time taken by gRPC requests, by method and status code.", releaseBuckets, "method", "code")
+)
+
+// releaseOperationsByMethod are the operations of the gRPC methods that
+// change releases.
+var releaseOperationsByMethod = map[string]string{
+	"InstallRelease":         "install",
+	"InstallReleaseProgress": "install",
+	"UpdateRelease":          "upgrade",
+	"UpdateReleaseProgress":  "upgrade",
+	"RollbackRelease":        "rollback",
+	"UninstallRelease":       "delete",
+	"ImportRelease":          "import",
+	"SyncRelease":            "sync",
+	"SetReleaseValues":       "set-values",
+	"MaintainStorage":        "maintain",
+	"SetReleaseLock":         "lock",
+	"PromoteRelease":         "promote",
+}
+
+// unaryMetrics is a gRPC interceptor that measures unary requests.
+func unaryMetrics(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	done := measure(info.FullMethod)
+	res, err := handler(ctx, req)
+	done(err)
+	return res, err
+}
+
+// streamMetrics is a gRPC interceptor that measures streaming requests.
+func streamMetrics(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	done := measure(info.FullMethod)
+	err := handler(srv, ss)
+	done(err)
+	return err
+}
+
+// measure starts measuring a request of a gRPC method, given as
+// "/package.Service/Method". The returned func ends the measurement with the
+// error of the request.
+func measure(fullMethod string) func(error) {
+	start := time.Now()
+	method := path.Base(fullMethod)
+	op, changesRelease := releaseOperationsByMethod[method]
+	if changesRelease {
+		releasesInFlight.Inc(op)
+	}
+	return func(err error) {
+		elapsed := time.Since(start).Seconds()
+		grpcDuration.Observe(elapsed, method, grpc.Code(err).String())
+		if !changesRelease {
+			return
+		}
+		releasesInFlight.Dec(op)
+		result := "success"
+		if err != nil {
+			result = "failure"
+		}
+		releaseOperations.Inc(op, result)
+		releaseDuration.Observe(elapsed, op)
+	}
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tiller
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"k8s.io/helm/pkg/metrics"
+)
+
+func metricsOutput(t *testing.T) string {
+	b := bytes.NewBuffer(nil)
+	if err := metrics.Write(b); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+func TestMeasure(t *testing.T) {
+	done := measure("/hapi.services.tiller.ReleaseService/RollbackRelease")
+	if out := metricsOutput(t); !strings.Contains(out, "\ntiller_release_operations_in_flight{operation=\"rollback\"} 1\n") {
+		t.Errorf("expected a rollback in flight, got\n%s", out)
+	}
+	done(errors.New("boom"))
+	measure("/hapi.services.tiller.ReleaseService/GetHistory")(nil)
+
+	out := metricsOutput(t)
+	for _, expect := range []string{
+		"\ntiller_release_operations_in_flight{operation=\"rollback\"} 0\n",
+		"\ntiller_release_operations_total{operation=\"rollback\",result=\"failure\"} 1\n",
+		"\ntiller_release_operation_duration_seconds_count{operation=\"rollback\"} 1\n",
+		"\ntiller_grpc_request_duration_seconds_count{method=\"RollbackRelease\",code=\"Unknown\"} 1\n",
+		"\ntiller_grpc_request_duration_seconds_count{method=\"GetHistory\",code=\"OK\"} 1\n",
+	} {
+		if !strings.Contains(out, expect) {
+			t.Errorf("expected %q in\n%s", strings.TrimSpace(expect), out)
+		}
+	}
+	if strings.Contains(out, "operation=\"GetHistory\"") {
+		t.Errorf("expected GetHistory not to be counted as a release operation, got\n%s", out)
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tiller
+
+import (
+	"sort"
+	"time"
+
+	"k8s.io/helm/pkg/notify"
+	"k8s.io/helm/pkg/proto/hapi/release"
+	"k8s.io/helm/pkg/releaseutil"
+)
+
+// notify tells the notifier of the environment that operation was performed
+// on r, replacing the resources of previous, the manifest of the revision it
+// replaced. Deleted releases have no resources left.
+func (s *ReleaseServer) notify(operation string, r *release.Release, previous string, err error) {
+	if s.env.Notifier == nil || r == nil {
+		return
+	}
+
+	current := r.Manifest
+	if operation == notify.Delete {
+		current = ""
+	}
+	e := &notify.Event{
+		Operation: operation,
+		Succeeded: err == nil,
+		Release: notify.Release{
+			Name:      r.Name,
+			Namespace: r.Namespace,
+			Revision:  r.Version,
+		},
+		Diff: diffResources(previous, current),
+		Time: time.Now(),
+	}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	if r.Info != nil && r.Info.Status != nil {
+		e.Release.Status = r.Info.Status.Code.String()
+	}
+	if r.Chart != nil && r.Chart.Metadata != nil {
+		e.Chart = notify.Chart{Name: r.Chart.Metadata.Name, Version: r.Chart.Metadata.Version}
+	}
+	s.env.Notifier.Notify(e)
+}
+
+// diffResources summarizes the changes from the resources of one manifest to
+// those of another, sorted by Kind/name.
+func diffResources(previous, current string) notify.Diff {
+	before := resourceContents(previous)
+	after := resourceContents(current)
+
+	d := notify.Diff{Added: []string{}, Changed: []string{}, Removed: []string{}}
+	for id, content := range after {
+		old, ok := before[id]
+		switch {
+		case !ok:
+			d.Added = append(d.Added, id)
+		case old != content:
+			d.Changed = append(d.Changed, id)
+		}
+	}
+	for id := range before {
+		if _, ok := after[id]; !ok {
+			d.Removed = append(d.Removed, id)
+		}
+	}
+	sort.Strings(d.Added)
+	sort.Strings(d.Changed)
+	sort.Strings(d.Removed)
+	return d
+}
+
+// resourceContents maps the resources of a manifest by Kind/name to their
+// YAML.
+func resourceContents(manifest string) map[string]string {
+	res := map[string]string{}
+	// A manifest that cannot be split was never applied, so it has no
+	// resources to compare.
+	docs, _ := releaseutil.SplitManifest(manifest)
+	for _, d := range docs {
+		res[d.Kind+"/"+d.Name] = d.Content
+	}
+	return res
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tiller
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/notify"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/proto/hapi/release"
+	"k8s.io/helm/pkg/proto/hapi/services"
+	"k8s.io/helm/pkg/tiller/environment"
+)
+
+type recordingNotifier struct {
+	events []*notify.Event
+}
+
+func (r *recordingNotifier) Notify(e *notify.Event) {
+	r.events = append(r.events, e)
+}
+
+func configMapChart(version string, data map[string]string) *chart.Chart {
+	ch := &chart.Chart{Metadata: &chart.Metadata{Name: "hello", Version: version}}
+	for name, value := range data {
+		ch.Templates = append(ch.Templates, &chart.Template{
+			Name: "templates/" + name,
+			Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: " + name + "\ndata:\n  value: " + value + "\n"),
+		})
+	}
+	return ch
+}
+
+func TestNotifyReleaseLifecycle(t *testing.T) {
+	c := helm.NewContext()
+	rs := rsFixture()
+	n := &recordingNotifier{}
+	rs.env.Notifier = n
+
+	install := &services.InstallReleaseRequest{
+		Name:      "notified",
+		Namespace: "spaced",
+		Chart:     configMapChart("0.1.0", map[string]string{"a": "1", "b": "1"}),
+	}
+	if _, err := rs.InstallRelease(c, install); err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+	update := &services.UpdateReleaseRequest{
+		Name:  "notified",
+		Chart: configMapChart("0.2.0", map[string]string{"a": "2", "c": "1"}),
+	}
+	if _, err := rs.UpdateRelease(c, update); err != nil {
+		t.Fatalf("Failed upgrade: %s", err)
+	}
+	if _, err := rs.UninstallRelease(c, &services.UninstallReleaseRequest{Name: "notified"}); err != nil {
+		t.Fatalf("Failed uninstall: %s", err)
+	}
+
+	if len(n.events) != 3 {
+		t.Fatalf("expected 3 events, got %d", len(n.events))
+	}
+	tests := []struct {
+		operation string
+		revision  int32
+		version   string
+		status    string
+		diff      notify.Diff
+	}{
+		{notify.Install, 1, "0.1.0", "DEPLOYED", notify.Diff{Added: []string{"ConfigMap/a", "ConfigMap/b"}, Changed: []string{}, Removed: []string{}}},
+		{notify.Upgrade, 2, "0.2.0", "DEPLOYED", notify.Diff{Added: []string{"ConfigMap/c"}, Changed: []string{"ConfigMap/a"}, Removed: []string{"ConfigMap/b"}}},
+		{notify.Delete, 2, "0.2.0", "DELETED", notify.Diff{Added: []string{}, Changed: []string{}, Removed: []string{"ConfigMap/a", "ConfigMap/c"}}},
+	}
+	for i, tt := range tests {
+		e := n.events[i]
+		if e.Operation != tt.operation || !e.Succeeded || e.Error != "" {
+			t.Errorf("event %d: expected successful %s, got %+v", i, tt.operation, e)
+		}
+		want := notify.Release{Name: "notified", Namespace: "spaced", Revision: tt.revision, Status: tt.status}
+		if e.Release != want {
+			t.Errorf("event %d: expected release %+v, got %+v", i, want, e.Release)
+		}
+		if e.Chart.Name != "hello" || e.Chart.Version != tt.version {
+			t.Errorf("event %d: expected chart hello %s, got %+v", i, tt.version, e.Chart)
+		}
+		if !reflect.DeepEqual(e.Diff, tt.diff) {
+			t.Errorf("event %d: expected diff %#v, got %#v", i, tt.diff, e.Diff)
+		}
+		if e.Time.IsZero() {
+			t.Errorf("event %d: expected a time", i)
+		}
+	}
+}
+
+func TestNotifyFailedUpgrade(t *testing.T) {
+	c := helm.NewContext()
+	rs := rsFixture()
+	n := &recordingNotifier{}
+	rs.env.Notifier = n
+	rs.env.KubeClient = &updateFailingKubeClient{PrintingKubeClient: environment.PrintingKubeClient{Out: os.Stdout}}
+	rel := releaseStub()
+	rs.env.Releases.Create(rel)
+
+	req := &services.UpdateReleaseRequest{Name: rel.Name, Chart: configMapChart("0.2.0", map[string]string{"a": "1"})}
+	if _, err := rs.UpdateRelease(c, req); err == nil {
+		t.Fatal("expected the upgrade to fail")
+	}
+	if len(n.events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(n.events))
+	}
+	e := n.events[0]
+	if e.Operation != notify.Upgrade || e.Succeeded || e.Error == "" {
+		t.Errorf("expected a failed upgrade, got %+v", e)
+	}
+	if e.Release.Status != release.Status_FAILED.String() {
+		t.Errorf("expected status FAILED, got %q", e.Release.Status)
+	}
+}
+
+func TestNotifySkipsDryRun(t *testing.T) {
+	c := helm.NewContext()
+	rs := rsFixture()
+	n := &recordingNotifier{}
+	rs.env.Notifier = n
+
+	req := &services.InstallReleaseRequest{Chart: chartStub(), DryRun: true}
+	if _, err := rs.InstallRelease(c, req); err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+	if len(n.events) != 0 {
+		t.Errorf("expected no events for a dry run, got %d", len(n.events))
+	}
+}
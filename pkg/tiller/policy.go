@@ -0,0 +1,65 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tiller
+
+import (
+	"fmt"
+
+	"k8s.io/helm/pkg/policy"
+	"k8s.io/helm/pkg/proto/hapi/release"
+)
+
+// checkPolicy rejects a release, about to be applied by operation, that the
+// policy of the environment does not allow.
+func (s *ReleaseServer) checkPolicy(r *release.Release, operation string) error {
+	if s.env.Policy == nil {
+		return nil
+	}
+
+	manifest := r.Manifest
+	for _, h := range r.Hooks {
+		manifest += "\n---\n" + h.Manifest
+	}
+	resources, err := policy.Resources(manifest)
+	if err != nil {
+		return err
+	}
+	review := &policy.Review{
+		Release: policy.Release{
+			Name:      r.Name,
+			Namespace: r.Namespace,
+			Revision:  r.Version,
+			Operation: operation,
+		},
+		Resources: resources,
+	}
+	if r.Chart != nil && r.Chart.Metadata != nil {
+		review.Release.Chart = r.Chart.Metadata.Name
+		review.Release.ChartVersion = r.Chart.Metadata.Version
+	}
+
+	vs, err := s.env.Policy.Evaluate(review)
+	if err != nil {
+		// Fail closed: a release that could not be checked is not applied.
+		return fmt.Errorf("could not evaluate policy for release %s: %s", r.Name, err)
+	}
+	if len(vs) > 0 {
+		s.logf("Release %q violates policy: %v", r.Name, vs)
+		return &policy.Error{Release: r.Name, Violations: vs}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tiller
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/policy"
+	"k8s.io/helm/pkg/proto/hapi/services"
+)
+
+// denyKind denies every resource of a kind.
+type denyKind struct {
+	kind    string
+	reviews []*policy.Review
+}
+
+func (d *denyKind) Evaluate(r *policy.Review) ([]policy.Violation, error) {
+	d.reviews = append(d.reviews, r)
+	vs := []policy.Violation{}
+	for _, res := range r.Resources {
+		if res["kind"] == d.kind {
+			vs = append(vs, policy.Violation{Resource: d.kind, Message: "not allowed"})
+		}
+	}
+	return vs, nil
+}
+
+type brokenPolicy struct{}
+
+func (brokenPolicy) Evaluate(*policy.Review) ([]policy.Violation, error) {
+	return nil, errors.New("policy server unreachable")
+}
+
+func TestInstallReleasePolicyAllowed(t *testing.T) {
+	rs := rsFixture()
+	deny := &denyKind{kind: "Secret"}
+	rs.env.Policy = deny
+
+	req := &services.InstallReleaseRequest{Namespace: "spaced", Chart: chartStub()}
+	if _, err := rs.InstallRelease(helm.NewContext(), req); err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+	if len(deny.reviews) != 1 {
+		t.Fatalf("expected one review, got %d", len(deny.reviews))
+	}
+	r := deny.reviews[0]
+	if r.Release.Operation != "install" || r.Release.Namespace != "spaced" || r.Release.Chart != "hello" {
+		t.Errorf("unexpected release under review: %+v", r.Release)
+	}
+	// Three templates and the hook.
+	if len(r.Resources) != 4 {
+		t.Errorf("expected 4 resources, got %d", len(r.Resources))
+	}
+}
+
+func TestInstallReleasePolicyViolation(t *testing.T) {
+	rs := rsFixture()
+	rs.env.Policy = &denyKind{kind: "ConfigMap"}
+
+	req := &services.InstallReleaseRequest{Namespace: "spaced", Chart: chartStub()}
+	_, err := rs.InstallRelease(helm.NewContext(), req)
+	if err == nil {
+		t.Fatal("expected the release to be rejected")
+	}
+	if !strings.Contains(err.Error(), "violates policy:\n  ConfigMap: not allowed") {
+		t.Errorf("unexpected error %q", err)
+	}
+	if rels, _ := rs.env.Releases.ListReleases(); len(rels) != 0 {
+		t.Errorf("expected nothing to be recorded, got %d releases", len(rels))
+	}
+}
+
+func TestUpdateReleasePolicyUnavailable(t *testing.T) {
+	rs := rsFixture()
+	rel := releaseStub()
+	rs.env.Releases.Create(rel)
+	rs.env.Policy = brokenPolicy{}
+
+	req := &services.UpdateReleaseRequest{Name: rel.Name, Chart: rel.Chart}
+	_, err := rs.UpdateRelease(helm.NewContext(), req)
+	if err == nil || !strings.Contains(err.Error(), "policy server unreachable") {
+		t.Errorf("expected the upgrade to fail closed, got %v", err)
+	}
+}
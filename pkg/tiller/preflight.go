@@ -0,0 +1,140 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tiller
+
+import (
+	"bytes"
+	"fmt"
+
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+)
+
+// crdAPIVersion is the API version CustomResourceDefinitions are looked up
+// with.
+const crdAPIVersion = "apiextensions.k8s.io/v1beta1"
+
+// preflightError is returned for charts that fail their pre-flight checks.
+type preflightError struct {
+	chart    string
+	failures []string
+}
+
+func (e *preflightError) Error() string {
+	b := bytes.NewBufferString(fmt.Sprintf("pre-flight checks of chart %s failed:", e.chart))
+	for _, f := range e.failures {
+		b.WriteString("\n  " + f)
+	}
+	return b.String()
+}
+
+// preflight evaluates pre-flight checks, asking the cluster for each fact
+// at most once.
+type preflight struct {
+	s         *ReleaseServer
+	namespace string
+	versions  chartutil.VersionSet
+	nodes     int // -1 until the nodes have been counted
+}
+
+// checkPreflight rejects a chart, about to be applied in namespace, whose
+// pre-flight checks or those of its subcharts fail.
+func (s *ReleaseServer) checkPreflight(ch *chart.Chart, namespace string) error {
+	checks, err := chartutil.LoadPreflightChecks(ch)
+	if err != nil || len(checks) == 0 {
+		return err
+	}
+
+	p := &preflight{s: s, namespace: namespace, nodes: -1}
+	var failures []string
+	for _, c := range checks {
+		reasons, err := p.run(c)
+		if err != nil {
+			// Fail closed: a chart that could not be checked is not applied.
+			return fmt.Errorf("could not run pre-flight check %q of %s: %s", c.Name, c.File, err)
+		}
+		for _, r := range reasons {
+			f := c.Name + ": " + r
+			if c.Message != "" {
+				f += ". " + c.Message
+			}
+			failures = append(failures, f)
+		}
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	name := ch.Metadata.Name + "-" + ch.Metadata.Version
+	s.logf("Chart %s failed its pre-flight checks: %v", name, failures)
+	return &preflightError{chart: name, failures: failures}
+}
+
+// run returns why the check fails, if it does.
+func (p *preflight) run(c *chartutil.PreflightCheck) ([]string, error) {
+	var reasons []string
+	if len(c.APIVersions) > 0 && p.versions == nil {
+		vs, err := p.s.getVersionSet()
+		if err != nil {
+			return nil, fmt.Errorf("could not get apiVersions from Kubernetes: %s", err)
+		}
+		p.versions = vs
+	}
+	for _, v := range c.APIVersions {
+		if !p.versions.Has(v) {
+			reasons = append(reasons, fmt.Sprintf("the cluster does not serve %s", v))
+		}
+	}
+
+	for _, name := range c.CRDs {
+		obj, err := p.s.env.KubeClient.Lookup(crdAPIVersion, "CustomResourceDefinition", "", name)
+		if err != nil {
+			return nil, err
+		}
+		if len(obj) == 0 {
+			reasons = append(reasons, fmt.Sprintf("the cluster has no CustomResourceDefinition %s", name))
+		}
+	}
+
+	if c.MinNodes > 0 {
+		if p.nodes < 0 {
+			list, err := p.s.env.KubeClient.Lookup("v1", "Node", "", "")
+			if err != nil {
+				return nil, err
+			}
+			items, _ := list["items"].([]interface{})
+			p.nodes = len(items)
+		}
+		if p.nodes < c.MinNodes {
+			reasons = append(reasons, fmt.Sprintf("the cluster has %d nodes, but %d are required", p.nodes, c.MinNodes))
+		}
+	}
+
+	for _, r := range c.Resources {
+		namespace := r.Namespace
+		if namespace == "" {
+			namespace = p.namespace
+		}
+		obj, err := p.s.env.KubeClient.Lookup(r.APIVersion, r.Kind, namespace, r.Name)
+		if err != nil {
+			return nil, err
+		}
+		if len(obj) == 0 {
+			reasons = append(reasons, fmt.Sprintf("%s does not exist", r))
+		}
+	}
+	return reasons, nil
+}
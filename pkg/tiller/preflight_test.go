@@ -0,0 +1,162 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tiller
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/any"
+
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/proto/hapi/services"
+	"k8s.io/helm/pkg/tiller/environment"
+)
+
+// preflightKubeClient serves the objects it holds, keyed by
+// "Kind namespace/name", and a number of nodes.
+type preflightKubeClient struct {
+	environment.PrintingKubeClient
+	objects map[string]bool
+	nodes   int
+	lookups int
+}
+
+func (p *preflightKubeClient) Lookup(apiVersion, kind, ns, name string) (map[string]interface{}, error) {
+	p.lookups++
+	if kind == "Node" {
+		items := make([]interface{}, p.nodes)
+		return map[string]interface{}{"items": items}, nil
+	}
+	if p.objects[kind+" "+ns+"/"+name] {
+		return map[string]interface{}{"kind": kind}, nil
+	}
+	return map[string]interface{}{}, nil
+}
+
+const preflightChecks = `checks:
+- name: operator
+  crds:
+  - widgets.example.com
+  message: Install the widget operator first.
+- name: size
+  minNodes: 3
+- name: secret
+  resources:
+  - apiVersion: v1
+    kind: Secret
+    name: credentials
+`
+
+func preflightChart() *chart.Chart {
+	ch := chartStub()
+	ch.Metadata.Version = "0.1.0"
+	ch.Files = []*any.Any{{TypeUrl: "checks/cluster.yaml", Value: []byte(preflightChecks)}}
+	return ch
+}
+
+func TestInstallReleasePreflightFailed(t *testing.T) {
+	rs := rsFixture()
+	rs.env.KubeClient = &preflightKubeClient{
+		PrintingKubeClient: environment.PrintingKubeClient{Out: os.Stdout},
+		nodes:              1,
+	}
+
+	req := &services.InstallReleaseRequest{Namespace: "spaced", Chart: preflightChart()}
+	_, err := rs.InstallRelease(helm.NewContext(), req)
+	if err == nil {
+		t.Fatal("expected the pre-flight checks to fail")
+	}
+	expect := `pre-flight checks of chart hello-0.1.0 failed:
+  operator: the cluster has no CustomResourceDefinition widgets.example.com. Install the widget operator first.
+  size: the cluster has 1 nodes, but 3 are required
+  secret: Secret credentials does not exist`
+	if err.Error() != expect {
+		t.Errorf("expected %q, got %q", expect, err)
+	}
+	if rels, _ := rs.env.Releases.ListReleases(); len(rels) != 0 {
+		t.Errorf("expected nothing to be recorded, got %d releases", len(rels))
+	}
+
+	// Dry runs are checked too, so that failures show up early.
+	req.DryRun = true
+	if _, err := rs.InstallRelease(helm.NewContext(), req); err == nil {
+		t.Error("expected the pre-flight checks of the dry run to fail")
+	}
+
+	req.SkipPreflight = true
+	if _, err := rs.InstallRelease(helm.NewContext(), req); err != nil {
+		t.Errorf("expected the pre-flight checks to be skipped, got %s", err)
+	}
+}
+
+func TestInstallReleasePreflightPassed(t *testing.T) {
+	rs := rsFixture()
+	kc := &preflightKubeClient{
+		PrintingKubeClient: environment.PrintingKubeClient{Out: os.Stdout},
+		objects: map[string]bool{
+			"CustomResourceDefinition /widgets.example.com": true,
+			"Secret spaced/credentials":                     true,
+		},
+		nodes: 3,
+	}
+	rs.env.KubeClient = kc
+
+	req := &services.InstallReleaseRequest{Namespace: "spaced", Chart: preflightChart()}
+	if _, err := rs.InstallRelease(helm.NewContext(), req); err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+	if kc.lookups != 3 {
+		t.Errorf("expected 3 lookups, got %d", kc.lookups)
+	}
+}
+
+func TestUpdateReleasePreflight(t *testing.T) {
+	rs := rsFixture()
+	rel := releaseStub()
+	rel.Namespace = "spaced"
+	rs.env.Releases.Create(rel)
+
+	ch := chartStub()
+	ch.Metadata.Version = "0.2.0"
+	sub := chartStub()
+	sub.Metadata.Name = "sub"
+	sub.Files = []*any.Any{{TypeUrl: "checks/api.yaml", Value: []byte("checks:\n- name: batch\n  apiVersions: [batch/v2alpha1]\n")}}
+	ch.Dependencies = []*chart.Chart{sub}
+
+	req := &services.UpdateReleaseRequest{Name: rel.Name, Chart: ch}
+	_, err := rs.UpdateRelease(helm.NewContext(), req)
+	if err == nil || !strings.Contains(err.Error(), "batch: the cluster does not serve batch/v2alpha1") {
+		t.Fatalf("expected the pre-flight check of the subchart to fail, got %v", err)
+	}
+	if h, _ := rs.env.Releases.History(rel.Name); len(h) != 1 {
+		t.Errorf("expected no new revision, got %d revisions", len(h))
+	}
+}
+
+func TestInstallReleasePreflightInvalid(t *testing.T) {
+	rs := rsFixture()
+	ch := chartStub()
+	ch.Files = []*any.Any{{TypeUrl: "checks/bad.yaml", Value: []byte("checks:\n- name: nothing\n")}}
+
+	_, err := rs.InstallRelease(helm.NewContext(), &services.InstallReleaseRequest{Chart: ch})
+	if err == nil || !strings.Contains(err.Error(), `cannot parse checks/bad.yaml of chart hello: check "nothing": no requirements`) {
+		t.Errorf("expected the checks not to parse, got %v", err)
+	}
+}
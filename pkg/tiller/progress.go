@@ -0,0 +1,73 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tiller
+
+import (
+	"k8s.io/helm/pkg/kube"
+	"k8s.io/helm/pkg/proto/hapi/services"
+)
+
+// progressSender is the part of a progress stream that events are sent on.
+type progressSender interface {
+	Send(*services.ReleaseProgressResponse) error
+}
+
+// streamReporter sends each resource change to a progress stream.
+type streamReporter struct {
+	stream progressSender
+}
+
+// Report implements kube.ProgressReporter. It fails if the client went away;
+// the release carries on regardless.
+func (r streamReporter) Report(action, kind, name, namespace string) error {
+	ev := &services.ResourceEvent{Action: action, Kind: kind, Name: name, Namespace: namespace}
+	return r.stream.Send(&services.ReleaseProgressResponse{Event: ev})
+}
+
+// reporting returns a release server whose Kubernetes client reports the
+// changes it makes to r. KubeClients that cannot report are used as they are.
+func (s *ReleaseServer) reporting(r kube.ProgressReporter) *ReleaseServer {
+	kc, ok := s.env.KubeClient.(*kube.Client)
+	if !ok {
+		return s
+	}
+	env := *s.env
+	env.KubeClient = kc.WithReporter(r)
+	cp := *s
+	cp.env = &env
+	return &cp
+}
+
+// InstallReleaseProgress installs a release, streaming the changes made to
+// its resources.
+func (s *ReleaseServer) InstallReleaseProgress(req *services.InstallReleaseRequest, stream services.ReleaseService_InstallReleaseProgressServer) error {
+	res, err := s.reporting(streamReporter{stream}).InstallRelease(stream.Context(), req)
+	if err != nil {
+		return err
+	}
+	return stream.Send(&services.ReleaseProgressResponse{Release: res.Release})
+}
+
+// UpdateReleaseProgress updates a release, streaming the changes made to its
+// resources.
+func (s *ReleaseServer) UpdateReleaseProgress(req *services.UpdateReleaseRequest, stream services.ReleaseService_UpdateReleaseProgressServer) error {
+	res, err := s.reporting(streamReporter{stream}).UpdateRelease(stream.Context(), req)
+	if err != nil {
+		return err
+	}
+	return stream.Send(&services.ReleaseProgressResponse{Release: res.Release})
+}
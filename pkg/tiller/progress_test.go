@@ -0,0 +1,121 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tiller
+
+import (
+	"testing"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/kube"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/proto/hapi/services"
+)
+
+type mockProgressServer struct {
+	sent []*services.ReleaseProgressResponse
+}
+
+func (p *mockProgressServer) Send(res *services.ReleaseProgressResponse) error {
+	p.sent = append(p.sent, res)
+	return nil
+}
+
+func (p *mockProgressServer) Context() context.Context       { return helm.NewContext() }
+func (p *mockProgressServer) SendMsg(v interface{}) error    { return nil }
+func (p *mockProgressServer) RecvMsg(v interface{}) error    { return nil }
+func (p *mockProgressServer) SendHeader(m metadata.MD) error { return nil }
+func (p *mockProgressServer) SetTrailer(m metadata.MD)       {}
+func (p *mockProgressServer) SetHeader(m metadata.MD) error  { return nil }
+
+func TestInstallReleaseProgress(t *testing.T) {
+	rs := rsFixture()
+	req := &services.InstallReleaseRequest{
+		Namespace: "spaced",
+		Chart: &chart.Chart{
+			Metadata:  &chart.Metadata{Name: "hello"},
+			Templates: []*chart.Template{{Name: "templates/hello", Data: []byte("hello: world")}},
+		},
+	}
+
+	stream := &mockProgressServer{}
+	if err := rs.InstallReleaseProgress(req, stream); err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+	if len(stream.sent) == 0 {
+		t.Fatal("expected the release to be sent")
+	}
+	last := stream.sent[len(stream.sent)-1]
+	if last.Release == nil || last.Release.Namespace != "spaced" {
+		t.Errorf("expected the release as the last message, got %v", last)
+	}
+}
+
+func TestUpdateReleaseProgress(t *testing.T) {
+	rs := rsFixture()
+	rel := releaseStub()
+	rs.env.Releases.Create(rel)
+
+	req := &services.UpdateReleaseRequest{
+		Name: rel.Name,
+		Chart: &chart.Chart{
+			Metadata:  &chart.Metadata{Name: "hello"},
+			Templates: []*chart.Template{{Name: "templates/hello", Data: []byte("hello: world")}},
+		},
+	}
+
+	stream := &mockProgressServer{}
+	if err := rs.UpdateReleaseProgress(req, stream); err != nil {
+		t.Fatalf("Failed update: %s", err)
+	}
+	last := stream.sent[len(stream.sent)-1]
+	if last.Release == nil || last.Release.Version != 2 {
+		t.Errorf("expected revision 2 as the last message, got %v", last)
+	}
+}
+
+func TestStreamReporter(t *testing.T) {
+	stream := &mockProgressServer{}
+	if err := (streamReporter{stream}).Report("patched", "Service", "web", "default"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stream.sent) != 1 {
+		t.Fatalf("expected 1 message, got %d", len(stream.sent))
+	}
+	ev := stream.sent[0].Event
+	if ev == nil || ev.Action != "patched" || ev.Kind != "Service" || ev.Name != "web" || ev.Namespace != "default" {
+		t.Errorf("unexpected event %v", ev)
+	}
+}
+
+func TestReportingKubeClient(t *testing.T) {
+	rs := rsFixture()
+	kc := kube.New(nil)
+	rs.env.KubeClient = kc
+
+	r := streamReporter{&mockProgressServer{}}
+	got, ok := rs.reporting(r).env.KubeClient.(*kube.Client)
+	if !ok || got.Reporter == nil {
+		t.Fatalf("expected a reporting client, got %#v", got)
+	}
+	if kc.Reporter != nil {
+		t.Error("the shared client must not be changed")
+	}
+}
@@ -28,6 +28,15 @@ func (s *ReleaseServer) GetHistory(ctx context.Context, req *tpb.GetHistoryReque
 		return nil, errIncompatibleVersion
 	}
 
+	s, err := s.forRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// A history is read with a list of the release's records.
+	if err := s.authorizeRead("list", ""); err != nil {
+		return nil, err
+	}
+
 	h, err := s.env.Releases.History(req.Name)
 	if err != nil {
 		return nil, err
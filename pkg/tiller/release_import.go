@@ -0,0 +1,123 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tiller
+
+import (
+	"fmt"
+
+	ctx "golang.org/x/net/context"
+
+	"k8s.io/helm/pkg/proto/hapi/release"
+	"k8s.io/helm/pkg/proto/hapi/services"
+	relutil "k8s.io/helm/pkg/releaseutil"
+)
+
+// ImportRelease stores the revisions of a release exported from another
+// Tiller, and creates the resources of its latest revision if asked to.
+//
+// The release must not exist yet. If its resources cannot be created, the
+// revisions are kept and the latest one is marked as failed, as for a failed
+// install.
+func (s *ReleaseServer) ImportRelease(c ctx.Context, req *services.ImportReleaseRequest) (*services.ImportReleaseResponse, error) {
+	if !checkClientVersion(c) {
+		return nil, errIncompatibleVersion
+	}
+
+	s, err := s.forRequest(c)
+	if err != nil {
+		return nil, err
+	}
+
+	rels, err := importedRevisions(req.Releases)
+	if err != nil {
+		return nil, err
+	}
+	name := rels[0].Name
+	// The revisions are written as Tiller: only applying them acts as the
+	// caller on the cluster.
+	if err := s.authorizeWrite("create", ""); err != nil {
+		return nil, err
+	}
+	if h, err := s.env.Releases.History(name); err == nil && len(h) > 0 {
+		return nil, fmt.Errorf("release %q already exists", name)
+	}
+	if req.Namespace != "" {
+		for _, r := range rels {
+			r.Namespace = req.Namespace
+		}
+	}
+
+	// Every revision is checked, as any of them can be rolled back to. An
+	// imported chart has no source.
+	for _, r := range rels {
+		if err := s.checkChart(r.Chart, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	latest := rels[len(rels)-1]
+	apply := req.Apply && latest.Info.Status.Code == release.Status_DEPLOYED
+	if apply {
+		if err := s.checkPolicy(latest, "import"); err != nil {
+			return nil, err
+		}
+		if err := s.checkGates(latest, "import", req.OverrideGates); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, r := range rels {
+		if err := s.env.Releases.Create(r); err != nil {
+			return nil, fmt.Errorf("could not store revision %d of %q: %s", r.Version, name, err)
+		}
+	}
+	s.logf("Imported %d revisions of %s", len(rels), name)
+
+	res := &services.ImportReleaseResponse{Release: latest}
+	if apply {
+		if err := s.createResources(latest, false); err != nil {
+			s.logf("warning: Release %q failed: %s", name, err)
+			latest.Info.Status.Code = release.Status_FAILED
+			s.recordRelease(latest, true)
+			return res, fmt.Errorf("release %s failed: %s", name, err)
+		}
+	}
+	return res, nil
+}
+
+// importedRevisions checks that revs are distinct revisions of one release,
+// and returns them sorted by revision.
+func importedRevisions(revs []*release.Release) ([]*release.Release, error) {
+	if len(revs) == 0 {
+		return nil, errMissingRelease
+	}
+	seen := map[int32]bool{}
+	for _, r := range revs {
+		switch {
+		case r == nil || r.Name == "" || r.Info == nil || r.Info.Status == nil:
+			return nil, errMissingRelease
+		case r.Name != revs[0].Name:
+			return nil, fmt.Errorf("cannot import revisions of both %q and %q", revs[0].Name, r.Name)
+		case r.Version < 1 || seen[r.Version]:
+			return nil, fmt.Errorf("%s: %s %d", r.Name, errInvalidRevision, r.Version)
+		}
+		seen[r.Version] = true
+	}
+	rels := append([]*release.Release{}, revs...)
+	relutil.SortByRevision(rels)
+	return rels, nil
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tiller
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/release"
+	"k8s.io/helm/pkg/proto/hapi/services"
+	"k8s.io/helm/pkg/tiller/environment"
+)
+
+func importStub(version int32, status release.Status_Code) *release.Release {
+	r := namedReleaseStub("angry-bird", status)
+	r.Version = version
+	r.Namespace = "default"
+	r.Manifest = fmt.Sprintf("kind: ConfigMap\nmetadata:\n  name: angry-bird-v%d\n", version)
+	return r
+}
+
+func TestImportRelease(t *testing.T) {
+	c := helm.NewContext()
+	rs := rsFixture()
+	var created bytes.Buffer
+	rs.env.KubeClient = &environment.PrintingKubeClient{Out: &created}
+
+	req := &services.ImportReleaseRequest{
+		Releases: []*release.Release{
+			importStub(2, release.Status_DEPLOYED),
+			importStub(1, release.Status_SUPERSEDED),
+		},
+		Namespace: "moved",
+		Apply:     true,
+	}
+	res, err := rs.ImportRelease(c, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Release.Version != 2 || res.Release.Namespace != "moved" {
+		t.Errorf("expected revision 2 in moved, got %d in %s", res.Release.Version, res.Release.Namespace)
+	}
+	for _, v := range []int32{1, 2} {
+		r, err := rs.env.Releases.Get("angry-bird", v)
+		if err != nil {
+			t.Fatalf("revision %d was not stored: %s", v, err)
+		}
+		if r.Namespace != "moved" {
+			t.Errorf("expected revision %d in moved, got %s", v, r.Namespace)
+		}
+	}
+	if s := created.String(); !strings.Contains(s, "angry-bird-v2") || strings.Contains(s, "angry-bird-v1") {
+		t.Errorf("expected only the latest revision to be created, got %q", s)
+	}
+
+	if _, err := rs.ImportRelease(c, req); err == nil || err.Error() != `release "angry-bird" already exists` {
+		t.Errorf("expected an existing release to be refused, got %v", err)
+	}
+}
+
+func TestImportReleaseWithoutApply(t *testing.T) {
+	rs := rsFixture()
+	var created bytes.Buffer
+	rs.env.KubeClient = &environment.PrintingKubeClient{Out: &created}
+
+	req := &services.ImportReleaseRequest{Releases: []*release.Release{importStub(1, release.Status_DEPLOYED)}}
+	if _, err := rs.ImportRelease(helm.NewContext(), req); err != nil {
+		t.Fatal(err)
+	}
+	if created.Len() != 0 {
+		t.Errorf("expected no resources to be created, got %q", created.String())
+	}
+}
+
+func TestImportReleaseErrors(t *testing.T) {
+	other := importStub(2, release.Status_DEPLOYED)
+	other.Name = "calm-bird"
+
+	tests := []struct {
+		name   string
+		rels   []*release.Release
+		expect string
+	}{
+		{"no revisions", nil, errMissingRelease.Error()},
+		{"two releases", []*release.Release{importStub(1, release.Status_SUPERSEDED), other}, `cannot import revisions of both "angry-bird" and "calm-bird"`},
+		{"duplicate revision", []*release.Release{importStub(1, release.Status_SUPERSEDED), importStub(1, release.Status_DEPLOYED)}, "angry-bird: invalid release revision 1"},
+		{"revision zero", []*release.Release{importStub(0, release.Status_DEPLOYED)}, "angry-bird: invalid release revision 0"},
+	}
+	for _, tt := range tests {
+		rs := rsFixture()
+		_, err := rs.ImportRelease(helm.NewContext(), &services.ImportReleaseRequest{Releases: tt.rels})
+		if err == nil || err.Error() != tt.expect {
+			t.Errorf("%s: expected %q, got %v", tt.name, tt.expect, err)
+		}
+	}
+}
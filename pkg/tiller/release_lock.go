@@ -0,0 +1,107 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tiller
+
+import (
+	"errors"
+	"fmt"
+
+	ctx "golang.org/x/net/context"
+
+	"k8s.io/helm/pkg/proto/hapi/release"
+	"k8s.io/helm/pkg/proto/hapi/services"
+	"k8s.io/helm/pkg/timeconv"
+)
+
+// lockOverrideKey is the metadata key a revision made despite the lock of the
+// release records the reason for the change under.
+const lockOverrideKey = "lock-override"
+
+// unlockReasonKey is the metadata key the revision a lock was removed from
+// records the reason for removing it under.
+const unlockReasonKey = "unlock-reason"
+
+var errMissingLockReason = errors.New("a reason is required to lock or unlock a release")
+
+// SetReleaseLock locks or unlocks the latest revision of a release. The lock
+// is carried over to each later revision, and Tiller refuses to upgrade, roll
+// back or delete a locked release unless the request overrides the lock.
+//
+// Either way the request needs a reason. Only the record of the release
+// changes, so, when Tiller impersonates users, the caller must be allowed to
+// update it.
+func (s *ReleaseServer) SetReleaseLock(c ctx.Context, req *services.SetReleaseLockRequest) (*services.SetReleaseLockResponse, error) {
+	if !checkClientVersion(c) {
+		return nil, errIncompatibleVersion
+	}
+
+	s, err := s.forRequest(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ValidName.MatchString(req.Name) {
+		return nil, errMissingRelease
+	}
+	if req.Reason == "" {
+		return nil, errMissingLockReason
+	}
+
+	rel, err := s.env.Releases.Last(req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorizeWrite("update", recordName(rel.Name, rel.Version)); err != nil {
+		return nil, err
+	}
+
+	if req.Unlock {
+		s.logf("unlocking %s (v%d): %s", rel.Name, rel.Version, req.Reason)
+		rel.Lock = nil
+		md := map[string]string{}
+		for k, v := range rel.Info.Metadata {
+			md[k] = v
+		}
+		md[unlockReasonKey] = req.Reason
+		rel.Info.Metadata = md
+	} else {
+		s.logf("locking %s (v%d): %s", rel.Name, rel.Version, req.Reason)
+		rel.Lock = &release.Lock{Reason: req.Reason, Locked: timeconv.Now()}
+	}
+	if err := s.env.Releases.Update(rel); err != nil {
+		return nil, err
+	}
+	return &services.SetReleaseLockResponse{Release: rel}, nil
+}
+
+// checkLock refuses to change a locked release, unless override gives the
+// reason for the change. The reason is recorded in md, which is returned.
+func checkLock(r *release.Release, operation, override string, md map[string]string) (map[string]string, error) {
+	if r.Lock == nil {
+		return md, nil
+	}
+	if override == "" {
+		return nil, fmt.Errorf("release %s is locked (%s), override the lock with a reason to %s it", r.Name, r.Lock.Reason, operation)
+	}
+
+	res := map[string]string{}
+	for k, v := range md {
+		res[k] = v
+	}
+	res[lockOverrideKey] = override
+	return res, nil
+}
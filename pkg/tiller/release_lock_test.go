@@ -0,0 +1,104 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tiller
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/proto/hapi/release"
+	"k8s.io/helm/pkg/proto/hapi/services"
+)
+
+func TestSetReleaseLock(t *testing.T) {
+	c := helm.NewContext()
+	rs := rsFixture()
+	rel := releaseStub()
+	rs.env.Releases.Create(rel)
+
+	if _, err := rs.SetReleaseLock(c, &services.SetReleaseLockRequest{Name: rel.Name}); err != errMissingLockReason {
+		t.Errorf("expected a lock without a reason to be refused, got %v", err)
+	}
+
+	res, err := rs.SetReleaseLock(c, &services.SetReleaseLockRequest{Name: rel.Name, Reason: "change freeze"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Release.Lock == nil || res.Release.Lock.Reason != "change freeze" || res.Release.Lock.Locked == nil {
+		t.Fatalf("expected the release to be locked, got %v", res.Release.Lock)
+	}
+
+	upgrade := &services.UpdateReleaseRequest{
+		Name: rel.Name,
+		Chart: &chart.Chart{
+			Metadata:  &chart.Metadata{Name: "hello"},
+			Templates: []*chart.Template{{Name: "templates/hello", Data: []byte("hello: world")}},
+		},
+	}
+	_, err = rs.UpdateRelease(c, upgrade)
+	if err == nil || !strings.Contains(err.Error(), "angry-panda is locked (change freeze)") {
+		t.Errorf("expected the upgrade to be refused, got %v", err)
+	}
+	if _, err := rs.RollbackRelease(c, &services.RollbackReleaseRequest{Name: rel.Name, Version: 1}); err == nil {
+		t.Error("expected the rollback to be refused")
+	}
+	if _, err := rs.UninstallRelease(c, &services.UninstallReleaseRequest{Name: rel.Name}); err == nil {
+		t.Error("expected the delete to be refused")
+	}
+
+	// Dry runs are not refused.
+	dry := *upgrade
+	dry.DryRun = true
+	if _, err := rs.UpdateRelease(c, &dry); err != nil {
+		t.Errorf("expected a dry run to go ahead, got %s", err)
+	}
+
+	upgrade.OverrideLock = "hotfix for INC-42"
+	up, err := rs.UpdateRelease(c, upgrade)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if up.Release.Info.Metadata[lockOverrideKey] != "hotfix for INC-42" {
+		t.Errorf("expected the reason for the override to be recorded, got %v", up.Release.Info.Metadata)
+	}
+	if up.Release.Lock == nil {
+		t.Error("expected the lock to be carried over to the new revision")
+	}
+
+	if _, err := rs.SetReleaseLock(c, &services.SetReleaseLockRequest{Name: rel.Name, Unlock: true}); err != errMissingLockReason {
+		t.Errorf("expected an unlock without a reason to be refused, got %v", err)
+	}
+	res, err = rs.SetReleaseLock(c, &services.SetReleaseLockRequest{Name: rel.Name, Unlock: true, Reason: "freeze is over"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Release.Lock != nil {
+		t.Errorf("expected the release to be unlocked, got %v", res.Release.Lock)
+	}
+	if res.Release.Info.Metadata[unlockReasonKey] != "freeze is over" {
+		t.Errorf("expected the reason for the unlock to be recorded, got %v", res.Release.Info.Metadata)
+	}
+	del, err := rs.UninstallRelease(c, &services.UninstallReleaseRequest{Name: rel.Name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if del.Release.Info.Status.Code != release.Status_DELETED {
+		t.Errorf("expected the unlocked release to be deleted, got %s", del.Release.Info.Status.Code)
+	}
+}
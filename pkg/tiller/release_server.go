@@ -18,22 +18,28 @@ package tiller
 
 import (
 	"bytes"
+	"crypto/rand"
 	"errors"
 	"fmt"
-	"log"
 	"path"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 
+	"github.com/Masterminds/semver"
 	"github.com/technosophos/moniker"
 	ctx "golang.org/x/net/context"
 	"k8s.io/kubernetes/pkg/api/unversioned"
+	"k8s.io/kubernetes/pkg/apis/authentication"
 
 	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/engine"
 	"k8s.io/helm/pkg/kube"
+	"k8s.io/helm/pkg/notify"
 	"k8s.io/helm/pkg/proto/hapi/chart"
 	"k8s.io/helm/pkg/proto/hapi/release"
 	"k8s.io/helm/pkg/proto/hapi/services"
@@ -41,6 +47,7 @@ import (
 	"k8s.io/helm/pkg/storage/driver"
 	"k8s.io/helm/pkg/tiller/environment"
 	"k8s.io/helm/pkg/timeconv"
+	"k8s.io/helm/pkg/valueref"
 	"k8s.io/helm/pkg/version"
 )
 
@@ -90,12 +97,29 @@ var maxMsgSize = 1024 * 1024 * 10
 func NewServer() *grpc.Server {
 	return grpc.NewServer(
 		grpc.MaxMsgSize(maxMsgSize),
+		grpc.UnaryInterceptor(unaryMetrics),
+		grpc.StreamInterceptor(streamMetrics),
 	)
 }
 
 // ReleaseServer implements the server-side gRPC endpoint for the HAPI services.
 type ReleaseServer struct {
 	env *environment.Environment
+
+	// ImpersonateUsers makes the server act on the cluster as the user that
+	// sent the request, rather than as itself.
+	ImpersonateUsers bool
+
+	// ChartRules, if set, restrict the charts that may be installed.
+	ChartRules *ChartRules
+
+	// requestID identifies the request the server is serving, if any. See
+	// forRequest.
+	requestID string
+
+	// actingAs is the user the server acts on the cluster as, if it
+	// impersonates one. See impersonating.
+	actingAs *authentication.UserInfo
 }
 
 // NewReleaseServer creates a new release server.
@@ -120,6 +144,14 @@ func (s *ReleaseServer) ListReleases(req *services.ListReleasesRequest, stream s
 		return errIncompatibleVersion
 	}
 
+	s, err := s.forRequest(stream.Context())
+	if err != nil {
+		return err
+	}
+	if err := s.authorizeRead("list", ""); err != nil {
+		return err
+	}
+
 	if len(req.StatusCodes) == 0 {
 		req.StatusCodes = []release.Status_Code{release.Status_DEPLOYED}
 	}
@@ -223,7 +255,24 @@ func (s *ReleaseServer) GetVersion(c ctx.Context, req *services.GetVersionReques
 	return &services.GetVersionResponse{Version: v}, nil
 }
 
+// getAPIVersion returns the API version the client speaks, or an empty
+// string for clients that predate API versions.
+func getAPIVersion(c ctx.Context) string {
+	if md, ok := metadata.FromContext(c); ok {
+		if v, ok := md["x-helm-api-version"]; ok {
+			return v[0]
+		}
+	}
+	return ""
+}
+
+// checkClientVersion reports whether the client can be served. Clients that
+// send their API version are served if Tiller still speaks it; older clients
+// must have a compatible release version.
 func checkClientVersion(c ctx.Context) bool {
+	if api := getAPIVersion(c); api != "" {
+		return version.IsCompatibleAPI(api)
+	}
 	v := getVersion(c)
 	return version.IsCompatible(v, version.Version)
 }
@@ -234,6 +283,11 @@ func (s *ReleaseServer) GetReleaseStatus(c ctx.Context, req *services.GetRelease
 		return nil, errIncompatibleVersion
 	}
 
+	s, err := s.forRequest(c)
+	if err != nil {
+		return nil, err
+	}
+
 	if !ValidName.MatchString(req.Name) {
 		return nil, errMissingRelease
 	}
@@ -271,7 +325,7 @@ func (s *ReleaseServer) GetReleaseStatus(c ctx.Context, req *services.GetRelease
 		// Skip errors if this is already deleted or failed.
 		return statusResp, nil
 	} else if err != nil {
-		log.Printf("warning: Get for %s failed: %v", rel.Name, err)
+		s.logf("warning: Get for %s failed: %v", rel.Name, err)
 		return nil, err
 	}
 	rel.Info.Status.Resources = resp
@@ -284,17 +338,28 @@ func (s *ReleaseServer) GetReleaseContent(c ctx.Context, req *services.GetReleas
 		return nil, errIncompatibleVersion
 	}
 
+	s, err := s.forRequest(c)
+	if err != nil {
+		return nil, err
+	}
+
 	if !ValidName.MatchString(req.Name) {
 		return nil, errMissingRelease
 	}
 
+	var rel *release.Release
 	if req.Version <= 0 {
-		rel, err := s.env.Releases.Deployed(req.Name)
+		rel, err = s.env.Releases.Deployed(req.Name)
+	} else {
+		rel, err = s.env.Releases.Get(req.Name, req.Version)
+	}
+	if err != nil {
 		return &services.GetReleaseContentResponse{Release: rel}, err
 	}
-
-	rel, err := s.env.Releases.Get(req.Name, req.Version)
-	return &services.GetReleaseContentResponse{Release: rel}, err
+	if err := s.authorizeRead("get", recordName(rel.Name, rel.Version)); err != nil {
+		return nil, err
+	}
+	return &services.GetReleaseContentResponse{Release: rel}, nil
 }
 
 // UpdateRelease takes an existing release and new information, and upgrades the release.
@@ -303,52 +368,127 @@ func (s *ReleaseServer) UpdateRelease(c ctx.Context, req *services.UpdateRelease
 		return nil, errIncompatibleVersion
 	}
 
-	currentRelease, updatedRelease, err := s.prepareUpdate(req)
+	s, err := s.forRequest(c)
 	if err != nil {
 		return nil, err
 	}
 
-	res, err := s.performUpdate(currentRelease, updatedRelease, req)
-	if err != nil {
-		return res, err
+	if err := s.checkChart(req.Chart, req.ChartSource); err != nil {
+		return nil, err
 	}
+	return s.upgrade(req)
+}
 
+// upgrade upgrades a release to the chart and values of req, and records the
+// new revision.
+func (s *ReleaseServer) upgrade(req *services.UpdateReleaseRequest) (*services.UpdateReleaseResponse, error) {
+	currentRelease, updatedRelease, err := s.prepareUpdate(req)
+	if err != nil {
+		return nil, err
+	}
+	if req.BlueGreen {
+		if req.CanaryPercent > 0 {
+			return nil, errors.New("an upgrade cannot have both a canary and a blue/green rollout")
+		}
+		if err := stageBlueGreen(currentRelease, updatedRelease); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.checkPolicy(updatedRelease, "upgrade"); err != nil {
+		return nil, err
+	}
 	if !req.DryRun {
-		if err := s.env.Releases.Create(updatedRelease); err != nil {
-			return res, err
+		if err := s.checkGates(updatedRelease, "upgrade", req.OverrideGates); err != nil {
+			return nil, err
 		}
 	}
 
-	return res, nil
+	res, err := s.performUpdate(currentRelease, updatedRelease, req)
+	if err == nil && !req.DryRun {
+		// A canary has recorded the revision already.
+		if updatedRelease.Info.Canary != nil {
+			err = s.env.Releases.Update(updatedRelease)
+		} else {
+			err = s.env.Releases.Create(updatedRelease)
+		}
+	}
+	if !req.DryRun {
+		s.notify(notify.Upgrade, updatedRelease, currentRelease.Manifest, err)
+	}
+	return res, err
 }
 
 func (s *ReleaseServer) performUpdate(originalRelease, updatedRelease *release.Release, req *services.UpdateReleaseRequest) (*services.UpdateReleaseResponse, error) {
 	res := &services.UpdateReleaseResponse{Release: updatedRelease}
 
+	var canary string
+	var canaries []string
+	if req.CanaryPercent > 0 {
+		var err error
+		if canary, canaries, err = canaryManifest(updatedRelease.Manifest, req.CanaryPercent); err != nil {
+			return res, err
+		}
+	}
+
 	if req.DryRun {
-		log.Printf("Dry run for %s", updatedRelease.Name)
+		s.logf("Dry run for %s", updatedRelease.Name)
+		if req.ServerDryRun {
+			return res, s.serverDryRun(updatedRelease, req.DisableHooks)
+		}
 		return res, nil
 	}
 
 	// pre-ugrade hooks
 	if !req.DisableHooks {
-		if err := s.execHook(updatedRelease.Hooks, updatedRelease.Name, updatedRelease.Namespace, preUpgrade); err != nil {
+		if err := s.execHook(updatedRelease, preUpgrade); err != nil {
+			// Keep the failed revision, so that the hook report can be read.
+			updatedRelease.Info.Status.Code = release.Status_FAILED
+			s.recordRelease(updatedRelease, false)
 			return res, err
 		}
 	}
 
-	if err := s.performKubeUpdate(originalRelease, updatedRelease); err != nil {
-		log.Printf("warning: Release Upgrade %q failed: %s", updatedRelease.Name, err)
+	// A canary records the revision as it starts.
+	recorded := canary != ""
+	if recorded {
+		defer s.removeCanary(updatedRelease, canary)
+		if err := s.runCanary(updatedRelease, canary, canaries, req); err != nil {
+			s.logf("warning: Canary of release %q failed: %s", updatedRelease.Name, err)
+			updatedRelease.Info.Status.Code = release.Status_FAILED
+			updatedRelease.Info.Canary.Phase = release.Canary_ROLLED_BACK
+			updatedRelease.Info.Canary.Message = err.Error()
+			s.recordRelease(updatedRelease, true)
+			return res, fmt.Errorf("canary failed, so %s was not upgraded: %s", updatedRelease.Name, err)
+		}
+		updatedRelease.Info.Canary.Phase = release.Canary_PROMOTED
+	}
+
+	if err := s.performKubeUpdate(originalRelease, updatedRelease, req.ServerSide); err != nil {
+		s.logf("warning: Release Upgrade %q failed: %s", updatedRelease.Name, err)
 		originalRelease.Info.Status.Code = release.Status_SUPERSEDED
 		updatedRelease.Info.Status.Code = release.Status_FAILED
 		s.recordRelease(originalRelease, true)
-		s.recordRelease(updatedRelease, false)
+		s.recordRelease(updatedRelease, recorded)
+		return res, err
+	}
+
+	if err := s.waitForResources(updatedRelease, req.Wait, req.WaitForJobs, req.Timeout); err != nil {
+		s.logf("warning: Release %q failed waiting for resources: %s", updatedRelease.Name, err)
+		originalRelease.Info.Status.Code = release.Status_SUPERSEDED
+		updatedRelease.Info.Status.Code = release.Status_FAILED
+		s.recordRelease(originalRelease, true)
+		s.recordRelease(updatedRelease, recorded)
 		return res, err
 	}
 
 	// post-upgrade hooks
 	if !req.DisableHooks {
-		if err := s.execHook(updatedRelease.Hooks, updatedRelease.Name, updatedRelease.Namespace, postUpgrade); err != nil {
+		if err := s.execHook(updatedRelease, postUpgrade); err != nil {
+			s.logf("warning: Release %q failed post-upgrade: %s", updatedRelease.Name, err)
+			originalRelease.Info.Status.Code = release.Status_SUPERSEDED
+			updatedRelease.Info.Status.Code = release.Status_FAILED
+			s.recordRelease(originalRelease, true)
+			s.recordRelease(updatedRelease, recorded)
 			return res, err
 		}
 	}
@@ -366,7 +506,7 @@ func (s *ReleaseServer) performUpdate(originalRelease, updatedRelease *release.R
 // If the request already has values, or if there are no values in the current release, this does nothing.
 func (s *ReleaseServer) reuseValues(req *services.UpdateReleaseRequest, current *release.Release) {
 	if (req.Values == nil || req.Values.Raw == "") && current.Config != nil && current.Config.Raw != "" {
-		log.Printf("Copying values from %s (v%d) to new release.", current.Name, current.Version)
+		s.logf("Copying values from %s (v%d) to new release.", current.Name, current.Version)
 		req.Values = current.Config
 	}
 }
@@ -387,9 +527,34 @@ func (s *ReleaseServer) prepareUpdate(req *services.UpdateReleaseRequest) (*rele
 		return nil, nil, err
 	}
 
+	// Dry runs change nothing, so a lock does not stop them.
+	metadata := req.Metadata
+	if !req.DryRun {
+		if metadata, err = checkLock(currentRelease, "upgrade", req.OverrideLock, req.Metadata); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if !req.DisableVersionCheck {
+		if err := s.checkVersionConstraints(req.Chart); err != nil {
+			return nil, nil, err
+		}
+	}
+	if !req.SkipPreflight {
+		if err := s.checkPreflight(req.Chart, currentRelease.Namespace); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	// If new values were not supplied in the upgrade, re-use the existing values.
 	s.reuseValues(req, currentRelease)
 
+	// Values set with 'helm values set' take precedence over the others.
+	values, err := withOverrides(req.Values, currentRelease.Overrides)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	ts := timeconv.Now()
 	options := chartutil.ReleaseOptions{
 		Name:      req.Name,
@@ -397,30 +562,67 @@ func (s *ReleaseServer) prepareUpdate(req *services.UpdateReleaseRequest) (*rele
 		Namespace: currentRelease.Namespace,
 	}
 
-	valuesToRender, err := chartutil.ToRenderValues(req.Chart, req.Values, options)
+	caps, err := s.capabilities()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.migrateAPIVersions(currentRelease, caps.APIVersions); err != nil {
+		return nil, nil, err
+	}
+	valuesToRender, err := chartutil.ToRenderValues(req.Chart, values, options, caps)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	hooks, manifestDoc, notesTxt, err := s.renderResources(req.Chart, valuesToRender)
+	sel, err := newTemplateSelector(req.IncludeTemplates, req.ExcludeTemplates)
 	if err != nil {
 		return nil, nil, err
 	}
+	if err := sel.onlySubcharts(req.Chart, req.OnlySubcharts); err != nil {
+		return nil, nil, err
+	}
+
+	// Keep the seed of the release, so that stable random values stay the same.
+	seed := currentRelease.Seed
+	if len(seed) == 0 {
+		if seed, err = newSeed(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	opts := renderOptions{apiVersions: caps.APIVersions, selector: sel, live: !req.DryRun || req.ServerDryRun, seed: seed, subchartNotes: req.SubchartNotes}
+	hooks, manifestDoc, notesTxt, err := s.renderResources(req.Chart, valuesToRender, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Resources outside of the selected templates keep their current state.
+	if !sel.empty() {
+		unselected, err := sel.unselected(currentRelease.Manifest)
+		if err != nil {
+			return nil, nil, err
+		}
+		manifestDoc.WriteString(unselected)
+	}
 
 	// Store an updated release.
 	updatedRelease := &release.Release{
 		Name:      req.Name,
 		Namespace: currentRelease.Namespace,
 		Chart:     req.Chart,
-		Config:    req.Values,
+		Config:    values,
+		Overrides: currentRelease.Overrides,
+		Lock:      currentRelease.Lock,
 		Info: &release.Info{
 			FirstDeployed: currentRelease.Info.FirstDeployed,
 			LastDeployed:  ts,
 			Status:        &release.Status{Code: release.Status_UNKNOWN},
+			Metadata:      metadata,
 		},
 		Version:  currentRelease.Version + 1,
 		Manifest: manifestDoc.String(),
 		Hooks:    hooks,
+		Seed:     seed,
 	}
 
 	if len(notesTxt) > 0 {
@@ -429,48 +631,83 @@ func (s *ReleaseServer) prepareUpdate(req *services.UpdateReleaseRequest) (*rele
 	return currentRelease, updatedRelease, nil
 }
 
+// migrateAPIVersions rewrites the resources of a release's manifest that use
+// API versions the cluster no longer serves to the versions that replaced them.
+//
+// Kubernetes converts stored objects to the API versions it still serves, so
+// the resources exist, but an update computed from the old manifest would fail
+// to find them. The migrated manifest is stored when the release is superseded.
+func (s *ReleaseServer) migrateAPIVersions(r *release.Release, vs chartutil.VersionSet) error {
+	manifest, migrated, err := relutil.MigrateManifest(r.Manifest, vs.Has)
+	if err != nil {
+		return fmt.Errorf("could not migrate the manifest of %s (v%d): %s", r.Name, r.Version, err)
+	}
+	for _, m := range migrated {
+		s.logf("Migrating %s %q of %s (v%d) from %s to %s", m.Kind, m.Name, r.Name, r.Version, m.APIVersion, m.ReplacedBy)
+	}
+	r.Manifest = manifest
+	return nil
+}
+
 // RollbackRelease rolls back to a previous version of the given release.
 func (s *ReleaseServer) RollbackRelease(c ctx.Context, req *services.RollbackReleaseRequest) (*services.RollbackReleaseResponse, error) {
 	if !checkClientVersion(c) {
 		return nil, errIncompatibleVersion
 	}
 
-	currentRelease, targetRelease, err := s.prepareRollback(req)
+	s, err := s.forRequest(c)
 	if err != nil {
 		return nil, err
 	}
 
-	res, err := s.performRollback(currentRelease, targetRelease, req)
+	currentRelease, targetRelease, err := s.prepareRollback(req)
 	if err != nil {
-		return res, err
+		return nil, err
+	}
+	if err := s.checkPolicy(targetRelease, "rollback"); err != nil {
+		return nil, err
+	}
+	// The chart was allowed when the revision was made; the rules may have
+	// changed since. Stored releases do not record where their chart came from.
+	if err := s.checkChart(targetRelease.Chart, nil); err != nil {
+		return nil, err
 	}
-
 	if !req.DryRun {
-		if err := s.env.Releases.Create(targetRelease); err != nil {
-			return res, err
+		if err := s.checkGates(targetRelease, "rollback", req.OverrideGates); err != nil {
+			return nil, err
 		}
 	}
 
-	return res, nil
+	res, err := s.performRollback(currentRelease, targetRelease, req)
+	if err == nil && !req.DryRun {
+		err = s.env.Releases.Create(targetRelease)
+	}
+	if !req.DryRun {
+		s.notify(notify.Rollback, targetRelease, currentRelease.Manifest, err)
+	}
+	return res, err
 }
 
 func (s *ReleaseServer) performRollback(currentRelease, targetRelease *release.Release, req *services.RollbackReleaseRequest) (*services.RollbackReleaseResponse, error) {
 	res := &services.RollbackReleaseResponse{Release: targetRelease}
 
 	if req.DryRun {
-		log.Printf("Dry run for %s", targetRelease.Name)
+		s.logf("Dry run for %s", targetRelease.Name)
 		return res, nil
 	}
 
 	// pre-rollback hooks
 	if !req.DisableHooks {
-		if err := s.execHook(targetRelease.Hooks, targetRelease.Name, targetRelease.Namespace, preRollback); err != nil {
+		if err := s.execHook(targetRelease, preRollback); err != nil {
+			// Keep the failed revision, so that the hook report can be read.
+			targetRelease.Info.Status.Code = release.Status_FAILED
+			s.recordRelease(targetRelease, false)
 			return res, err
 		}
 	}
 
-	if err := s.performKubeUpdate(currentRelease, targetRelease); err != nil {
-		log.Printf("warning: Release Rollback %q failed: %s", targetRelease.Name, err)
+	if err := s.performKubeUpdate(currentRelease, targetRelease, false); err != nil {
+		s.logf("warning: Release Rollback %q failed: %s", targetRelease.Name, err)
 		currentRelease.Info.Status.Code = release.Status_SUPERSEDED
 		targetRelease.Info.Status.Code = release.Status_FAILED
 		s.recordRelease(currentRelease, true)
@@ -480,7 +717,12 @@ func (s *ReleaseServer) performRollback(currentRelease, targetRelease *release.R
 
 	// post-rollback hooks
 	if !req.DisableHooks {
-		if err := s.execHook(targetRelease.Hooks, targetRelease.Name, targetRelease.Namespace, postRollback); err != nil {
+		if err := s.execHook(targetRelease, postRollback); err != nil {
+			s.logf("warning: Release %q failed post-rollback: %s", targetRelease.Name, err)
+			currentRelease.Info.Status.Code = release.Status_SUPERSEDED
+			targetRelease.Info.Status.Code = release.Status_FAILED
+			s.recordRelease(currentRelease, true)
+			s.recordRelease(targetRelease, false)
 			return res, err
 		}
 	}
@@ -493,10 +735,15 @@ func (s *ReleaseServer) performRollback(currentRelease, targetRelease *release.R
 	return res, nil
 }
 
-func (s *ReleaseServer) performKubeUpdate(currentRelease, targetRelease *release.Release) error {
-	kubeCli := s.env.KubeClient
+// performKubeUpdate moves the resources in the cluster from currentRelease's
+// manifest to targetRelease's, using server-side apply if serverSide is true.
+func (s *ReleaseServer) performKubeUpdate(currentRelease, targetRelease *release.Release, serverSide bool) error {
+	kubeCli := s.kubeClientFor(targetRelease)
 	current := bytes.NewBufferString(currentRelease.Manifest)
 	target := bytes.NewBufferString(targetRelease.Manifest)
+	if serverSide {
+		return kubeCli.Apply(targetRelease.Namespace, current, target)
+	}
 	return kubeCli.Update(targetRelease.Namespace, current, target)
 }
 
@@ -515,12 +762,19 @@ func (s *ReleaseServer) prepareRollback(req *services.RollbackReleaseRequest) (*
 		return nil, nil, err
 	}
 
+	metadata := req.Metadata
+	if !req.DryRun {
+		if metadata, err = checkLock(crls, "roll back", req.OverrideLock, req.Metadata); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	rbv := req.Version
 	if req.Version == 0 {
 		rbv = crls.Version - 1
 	}
 
-	log.Printf("rolling back %s (current: v%d, target: v%d)", req.Name, crls.Version, rbv)
+	s.logf("rolling back %s (current: v%d, target: v%d)", req.Name, crls.Version, rbv)
 
 	prls, err := s.env.Releases.Get(req.Name, rbv)
 	if err != nil {
@@ -533,6 +787,8 @@ func (s *ReleaseServer) prepareRollback(req *services.RollbackReleaseRequest) (*
 		Namespace: crls.Namespace,
 		Chart:     prls.Chart,
 		Config:    prls.Config,
+		Overrides: prls.Overrides,
+		Lock:      crls.Lock,
 		Info: &release.Info{
 			FirstDeployed: crls.Info.FirstDeployed,
 			LastDeployed:  timeconv.Now(),
@@ -540,10 +796,15 @@ func (s *ReleaseServer) prepareRollback(req *services.RollbackReleaseRequest) (*
 				Code:  release.Status_UNKNOWN,
 				Notes: prls.Info.Status.Notes,
 			},
+			Metadata: metadata,
 		},
 		Version:  crls.Version + 1,
 		Manifest: prls.Manifest,
 		Hooks:    prls.Hooks,
+		Seed:     prls.Seed,
+	}
+	if len(target.Seed) == 0 {
+		target.Seed = crls.Seed
 	}
 
 	return crls, target, nil
@@ -569,7 +830,7 @@ func (s *ReleaseServer) uniqName(start string, reuse bool) (string, error) {
 
 		if st := rel.Info.Status.Code; reuse && (st == release.Status_DELETED || st == release.Status_FAILED) {
 			// Allowe re-use of names if the previous release is marked deleted.
-			log.Printf("reusing name %q", start)
+			s.logf("reusing name %q", start)
 			return start, nil
 		} else if reuse {
 			return "", errors.New("cannot re-use a name that is still in use")
@@ -588,9 +849,9 @@ func (s *ReleaseServer) uniqName(start string, reuse bool) (string, error) {
 		if _, err := s.env.Releases.Get(name, 1); err == driver.ErrReleaseNotFound {
 			return name, nil
 		}
-		log.Printf("info: Name %q is taken. Searching again.", name)
+		s.logf("info: Name %q is taken. Searching again.", name)
 	}
-	log.Printf("warning: No available release names found after %d tries", maxTries)
+	s.logf("warning: No available release names found after %d tries", maxTries)
 	return "ERROR", errors.New("no available release name found")
 }
 
@@ -600,7 +861,7 @@ func (s *ReleaseServer) engine(ch *chart.Chart) environment.Engine {
 		if r, ok := s.env.EngineYard.Get(ch.Metadata.Engine); ok {
 			renderer = r
 		} else {
-			log.Printf("warning: %s requested non-existent template engine %s", ch.Metadata.Name, ch.Metadata.Engine)
+			s.logf("warning: %s requested non-existent template engine %s", ch.Metadata.Name, ch.Metadata.Engine)
 		}
 	}
 	return renderer
@@ -612,9 +873,24 @@ func (s *ReleaseServer) InstallRelease(c ctx.Context, req *services.InstallRelea
 		return nil, errIncompatibleVersion
 	}
 
+	s, err := s.forRequest(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkChart(req.Chart, req.ChartSource); err != nil {
+		return nil, err
+	}
+
 	rel, err := s.prepareRelease(req)
+	if err == nil {
+		err = s.checkPolicy(rel, "install")
+	}
+	if err == nil && !req.DryRun {
+		err = s.checkGates(rel, "install", req.OverrideGates)
+	}
 	if err != nil {
-		log.Printf("Failed install prepare step: %s", err)
+		s.logf("Failed install prepare step: %s", err)
 		res := &services.InstallReleaseResponse{Release: rel}
 
 		// On dry run, append the manifest contents to a failed release. This is
@@ -627,7 +903,10 @@ func (s *ReleaseServer) InstallRelease(c ctx.Context, req *services.InstallRelea
 
 	res, err := s.performRelease(rel, req)
 	if err != nil {
-		log.Printf("Failed install perform step: %s", err)
+		s.logf("Failed install perform step: %s", err)
+	}
+	if !req.DryRun {
+		s.notify(notify.Install, rel, "", err)
 	}
 	return res, err
 }
@@ -638,6 +917,17 @@ func (s *ReleaseServer) prepareRelease(req *services.InstallReleaseRequest) (*re
 		return nil, errMissingChart
 	}
 
+	if !req.DisableVersionCheck {
+		if err := s.checkVersionConstraints(req.Chart); err != nil {
+			return nil, err
+		}
+	}
+	if !req.SkipPreflight {
+		if err := s.checkPreflight(req.Chart, req.Namespace); err != nil {
+			return nil, err
+		}
+	}
+
 	name, err := s.uniqName(req.Name, req.ReuseName)
 	if err != nil {
 		return nil, err
@@ -645,12 +935,27 @@ func (s *ReleaseServer) prepareRelease(req *services.InstallReleaseRequest) (*re
 
 	ts := timeconv.Now()
 	options := chartutil.ReleaseOptions{Name: name, Time: ts, Namespace: req.Namespace}
-	valuesToRender, err := chartutil.ToRenderValues(req.Chart, req.Values, options)
+	caps, err := s.capabilities()
+	if err != nil {
+		return nil, err
+	}
+	valuesToRender, err := chartutil.ToRenderValues(req.Chart, req.Values, options, caps)
 	if err != nil {
 		return nil, err
 	}
 
-	hooks, manifestDoc, notesTxt, err := s.renderResources(req.Chart, valuesToRender)
+	sel, err := newTemplateSelector(req.IncludeTemplates, req.ExcludeTemplates)
+	if err != nil {
+		return nil, err
+	}
+
+	seed, err := newSeed()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := renderOptions{apiVersions: caps.APIVersions, selector: sel, live: !req.DryRun || req.ServerDryRun, seed: seed, subchartNotes: req.SubchartNotes}
+	hooks, manifestDoc, notesTxt, err := s.renderResources(req.Chart, valuesToRender, opts)
 	if err != nil {
 		// Return a release with partial data so that client can show debugging
 		// information.
@@ -682,10 +987,12 @@ func (s *ReleaseServer) prepareRelease(req *services.InstallReleaseRequest) (*re
 			FirstDeployed: ts,
 			LastDeployed:  ts,
 			Status:        &release.Status{Code: release.Status_UNKNOWN},
+			Metadata:      req.Metadata,
 		},
 		Manifest: manifestDoc.String(),
 		Hooks:    hooks,
 		Version:  1,
+		Seed:     seed,
 	}
 	if len(notesTxt) > 0 {
 		rel.Info.Status.Notes = notesTxt
@@ -693,11 +1000,35 @@ func (s *ReleaseServer) prepareRelease(req *services.InstallReleaseRequest) (*re
 	return rel, nil
 }
 
-func (s *ReleaseServer) getVersionSet() (versionSet, error) {
-	defVersions := newVersionSet("v1")
+// capabilities describes the cluster Tiller is attached to, and Tiller itself,
+// for use as .Capabilities in templates.
+func (s *ReleaseServer) capabilities() (*chartutil.Capabilities, error) {
+	vs, err := s.getVersionSet()
+	if err != nil {
+		return nil, fmt.Errorf("Could not get apiVersions from Kubernetes: %s", err)
+	}
 	cli, err := s.env.KubeClient.APIClient()
 	if err != nil {
-		log.Printf("API Client for Kubernetes is missing: %s.", err)
+		return nil, err
+	}
+	kv, err := cli.Discovery().ServerVersion()
+	if err != nil {
+		return nil, fmt.Errorf("Could not get server version from Kubernetes: %s", err)
+	}
+	return &chartutil.Capabilities{
+		APIVersions:   vs,
+		KubeVersion:   kv,
+		TillerVersion: version.GetVersionProto(),
+	}, nil
+}
+
+// getVersionSet returns the API group versions available in the cluster, as
+// well as each of their resource kinds in the form "group/version/Kind".
+func (s *ReleaseServer) getVersionSet() (chartutil.VersionSet, error) {
+	defVersions := chartutil.DefaultVersionSet
+	cli, err := s.env.KubeClient.APIClient()
+	if err != nil {
+		s.logf("API Client for Kubernetes is missing: %s.", err)
 		return defVersions, err
 	}
 
@@ -715,13 +1046,158 @@ func (s *ReleaseServer) getVersionSet() (versionSet, error) {
 	}
 
 	versions := unversioned.ExtractGroupVersions(groups)
-	return newVersionSet(versions...), nil
+
+	resources, err := cli.Discovery().ServerResources()
+	if err != nil {
+		s.logf("warning: could not list API resources: %s", err)
+	}
+	for gv, list := range resources {
+		if list == nil {
+			continue
+		}
+		for _, r := range list.APIResources {
+			// Skip subresources such as "deployments/status".
+			if strings.Contains(r.Name, "/") {
+				continue
+			}
+			versions = append(versions, path.Join(gv, r.Kind))
+		}
+	}
+	return chartutil.NewVersionSet(versions...), nil
+}
+
+// checkVersionConstraints verifies that the running Tiller and the target
+// cluster satisfy the tillerVersion and kubeVersion ranges declared by a chart
+// and all of its dependencies.
+func (s *ReleaseServer) checkVersionConstraints(ch *chart.Chart) error {
+	kubeVersion := ""
+	return walkCharts(ch, func(c *chart.Chart) error {
+		md := c.Metadata
+		if md == nil {
+			return nil
+		}
+		if md.TillerVersion != "" && !version.IsCompatibleRange(md.TillerVersion, version.Version) {
+			return fmt.Errorf("chart %q requires Tiller %s, but Tiller is %s", md.Name, md.TillerVersion, version.Version)
+		}
+		if md.KubeVersion == "" {
+			return nil
+		}
+		if kubeVersion == "" {
+			v, err := s.getKubeVersion()
+			if err != nil {
+				return fmt.Errorf("could not check kubeVersion of chart %q: %s", md.Name, err)
+			}
+			kubeVersion = v
+		}
+		if !version.IsCompatibleRange(md.KubeVersion, kubeVersion) {
+			return fmt.Errorf("chart %q requires Kubernetes %s, but the cluster is running %s", md.Name, md.KubeVersion, kubeVersion)
+		}
+		return nil
+	})
 }
 
-func (s *ReleaseServer) renderResources(ch *chart.Chart, values chartutil.Values) ([]*release.Hook, *bytes.Buffer, string, error) {
+// getKubeVersion returns the Kubernetes version of the cluster, with any
+// pre-release or build information (e.g. "-gke.0") removed.
+func (s *ReleaseServer) getKubeVersion() (string, error) {
+	cli, err := s.env.KubeClient.APIClient()
+	if err != nil {
+		return "", err
+	}
+	info, err := cli.Discovery().ServerVersion()
+	if err != nil {
+		return "", err
+	}
+	v, err := semver.NewVersion(info.GitVersion)
+	if err != nil {
+		return "", fmt.Errorf("unparseable Kubernetes version %q: %s", info.GitVersion, err)
+	}
+	return fmt.Sprintf("%d.%d.%d", v.Major(), v.Minor(), v.Patch()), nil
+}
+
+// newSeed generates the seed for a new release.
+func newSeed() ([]byte, error) {
+	seed := make([]byte, 32)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, fmt.Errorf("could not generate release seed: %s", err)
+	}
+	return seed, nil
+}
+
+// walkCharts calls fn on a chart and then, recursively, on its dependencies.
+func walkCharts(ch *chart.Chart, fn func(*chart.Chart) error) error {
+	if err := fn(ch); err != nil {
+		return err
+	}
+	for _, dep := range ch.Dependencies {
+		if err := walkCharts(dep, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderOptions controls how the chart of a release is rendered.
+type renderOptions struct {
+	// apiVersions are the API versions available in the cluster.
+	apiVersions chartutil.VersionSet
+	// selector limits the templates that make it into the release.
+	selector templateSelector
+	// live binds the "lookup" template function to the cluster. It is off
+	// for client-side dry runs, in which case lookup finds nothing.
+	live bool
+	// seed is the release's source of stable random values.
+	seed []byte
+	// subchartNotes appends the notes of subcharts to those of the chart.
+	subchartNotes bool
+}
+
+// resolveValues replaces the references to secrets in the chart values of
+// values by the secrets. The values of the request are left unchanged, so
+// that only the references are stored with the release. Only the references
+// the value policy allows for the namespace of the release are resolved.
+func (s *ReleaseServer) resolveValues(values chartutil.Values) (chartutil.Values, error) {
+	vals, ok := values["Values"].(chartutil.Values)
+	if !ok {
+		return values, nil
+	}
+	namespace := ""
+	if r, ok := values["Release"].(map[string]interface{}); ok {
+		namespace, _ = r["Namespace"].(string)
+	}
+	allowed := func(ref *valueref.Ref) bool {
+		return s.env.ValuePolicy.Allows(namespace, ref)
+	}
+	resolved, err := s.env.ValueResolvers.Resolve(vals, allowed)
+	if err != nil {
+		return nil, err
+	}
+	res := chartutil.Values{}
+	for k, v := range values {
+		res[k] = v
+	}
+	res["Values"] = chartutil.Values(resolved)
+	return res, nil
+}
+
+// renderResources renders a chart into its hooks, manifest and notes.
+func (s *ReleaseServer) renderResources(ch *chart.Chart, values chartutil.Values, opts renderOptions) ([]*release.Hook, *bytes.Buffer, string, error) {
 	renderer := s.engine(ch)
+	if e, ok := renderer.(*engine.Engine); ok {
+		e = e.WithSeed(opts.seed)
+		if opts.live {
+			e = e.WithLookup(s.env.KubeClient.Lookup)
+		}
+		renderer = e
+	}
+	values, err := s.resolveValues(values)
+	if err != nil {
+		return nil, nil, "", err
+	}
 	files, err := renderer.Render(ch, values)
 	if err != nil {
+		if re, ok := err.(*engine.RenderError); ok && re.Source != "" {
+			s.logf("%s\n%s:\n%s", err, re.File, re.Source)
+		}
 		return nil, nil, "", err
 	}
 
@@ -731,25 +1207,28 @@ func (s *ReleaseServer) renderResources(ch *chart.Chart, values chartutil.Values
 	// look for terminating NOTES.txt. We also remove it from the files so that we don't have to skip
 	// it in the sortHooks.
 	notes := ""
+	subNotes := map[string]string{}
 	for k, v := range files {
 		if strings.HasSuffix(k, notesFileSuffix) {
-			// Only apply the notes if it belongs to the parent chart
+			// Only apply the notes if it belongs to the parent chart, unless
+			// subchart notes were asked for.
 			// Note: Do not use filePath.Join since it creates a path with \ which is not expected
 			if k == path.Join(ch.Metadata.Name, "templates", notesFileSuffix) {
 				notes = v
+			} else if opts.subchartNotes && strings.TrimSpace(v) != "" {
+				subNotes[k] = v
 			}
 			delete(files, k)
 		}
 	}
+	if len(subNotes) > 0 {
+		notes = appendSubchartNotes(notes, subNotes)
+	}
 
 	// Sort hooks, manifests, and partials. Only hooks and manifests are returned,
 	// as partials are not used after renderer.Render. Empty manifests are also
 	// removed here.
-	vs, err := s.getVersionSet()
-	if err != nil {
-		return nil, nil, "", fmt.Errorf("Could not get apiVersions from Kubernetes: %s", err)
-	}
-	hooks, manifests, err := sortManifests(files, vs, InstallOrder)
+	hooks, manifests, err := s.sortManifests(files, opts.apiVersions, InstallOrder)
 	if err != nil {
 		// By catching parse errors here, we can prevent bogus releases from going
 		// to Kubernetes.
@@ -766,6 +1245,7 @@ func (s *ReleaseServer) renderResources(ch *chart.Chart, values chartutil.Values
 		}
 		return nil, b, "", err
 	}
+	hooks, manifests = opts.selector.filterHooks(hooks), opts.selector.filterManifests(manifests)
 
 	// Aggregate all valid manifests into one big doc.
 	b := bytes.NewBuffer(nil)
@@ -777,13 +1257,33 @@ func (s *ReleaseServer) renderResources(ch *chart.Chart, values chartutil.Values
 	return hooks, b, notes, nil
 }
 
+// appendSubchartNotes appends the notes of subcharts, keyed by the path of
+// their NOTES.txt, to the notes of the chart, in a stable order.
+func appendSubchartNotes(notes string, subNotes map[string]string) string {
+	names := make([]string, 0, len(subNotes))
+	for k := range subNotes {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	b := bytes.NewBufferString(strings.TrimRight(notes, "\n"))
+	for _, k := range names {
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString(strings.TrimRight(subNotes[k], "\n"))
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
 func (s *ReleaseServer) recordRelease(r *release.Release, reuse bool) {
 	if reuse {
 		if err := s.env.Releases.Update(r); err != nil {
-			log.Printf("warning: Failed to update release %q: %s", r.Name, err)
+			s.logf("warning: Failed to update release %q: %s", r.Name, err)
 		}
 	} else if err := s.env.Releases.Create(r); err != nil {
-		log.Printf("warning: Failed to record release %q: %s", r.Name, err)
+		s.logf("warning: Failed to record release %q: %s", r.Name, err)
 	}
 }
 
@@ -792,13 +1292,22 @@ func (s *ReleaseServer) performRelease(r *release.Release, req *services.Install
 	res := &services.InstallReleaseResponse{Release: r}
 
 	if req.DryRun {
-		log.Printf("Dry run for %s", r.Name)
+		s.logf("Dry run for %s", r.Name)
+		if req.ServerDryRun {
+			return res, s.serverDryRun(r, req.DisableHooks)
+		}
 		return res, nil
 	}
 
 	// pre-install hooks
 	if !req.DisableHooks {
-		if err := s.execHook(r.Hooks, r.Name, r.Namespace, preInstall); err != nil {
+		if err := s.execHook(r, preInstall); err != nil {
+			// Keep the failed release, so that the hook report can be read.
+			// A replaced release has no revision of its own yet.
+			r.Info.Status.Code = release.Status_FAILED
+			if !req.ReuseName {
+				s.recordRelease(r, false)
+			}
 			return res, err
 		}
 	}
@@ -820,8 +1329,8 @@ func (s *ReleaseServer) performRelease(r *release.Release, req *services.Install
 		// so as to append to the old release's history
 		r.Version = old.Version + 1
 
-		if err := s.performKubeUpdate(old, r); err != nil {
-			log.Printf("warning: Release replace %q failed: %s", r.Name, err)
+		if err := s.performKubeUpdate(old, r, req.ServerSide); err != nil {
+			s.logf("warning: Release replace %q failed: %s", r.Name, err)
 			old.Info.Status.Code = release.Status_SUPERSEDED
 			r.Info.Status.Code = release.Status_FAILED
 			s.recordRelease(old, true)
@@ -832,19 +1341,25 @@ func (s *ReleaseServer) performRelease(r *release.Release, req *services.Install
 	default:
 		// nothing to replace, create as normal
 		// regular manifests
-		b := bytes.NewBufferString(r.Manifest)
-		if err := s.env.KubeClient.Create(r.Namespace, b); err != nil {
-			log.Printf("warning: Release %q failed: %s", r.Name, err)
+		if err := s.createResources(r, req.ServerSide); err != nil {
+			s.logf("warning: Release %q failed: %s", r.Name, err)
 			r.Info.Status.Code = release.Status_FAILED
 			s.recordRelease(r, false)
 			return res, fmt.Errorf("release %s failed: %s", r.Name, err)
 		}
 	}
 
+	if err := s.waitForResources(r, req.Wait, req.WaitForJobs, req.Timeout); err != nil {
+		s.logf("warning: Release %q failed waiting for resources: %s", r.Name, err)
+		r.Info.Status.Code = release.Status_FAILED
+		s.recordRelease(r, false)
+		return res, fmt.Errorf("release %s failed: %s", r.Name, err)
+	}
+
 	// post-install hooks
 	if !req.DisableHooks {
-		if err := s.execHook(r.Hooks, r.Name, r.Namespace, postInstall); err != nil {
-			log.Printf("warning: Release %q failed post-install: %s", r.Name, err)
+		if err := s.execHook(r, postInstall); err != nil {
+			s.logf("warning: Release %q failed post-install: %s", r.Name, err)
 			r.Info.Status.Code = release.Status_FAILED
 			s.recordRelease(r, false)
 			return res, err
@@ -864,15 +1379,68 @@ func (s *ReleaseServer) performRelease(r *release.Release, req *services.Install
 	return res, nil
 }
 
-func (s *ReleaseServer) execHook(hs []*release.Hook, name, namespace, hook string) error {
-	kubeCli := s.env.KubeClient
+// serverDryRun has the Kubernetes API validate the resources of r, and of its
+// hooks unless disableHooks is set, without creating or changing any of them.
+func (s *ReleaseServer) serverDryRun(r *release.Release, disableHooks bool) error {
+	hooks := r.Hooks
+	if disableHooks {
+		hooks = nil
+	}
+	if strings.TrimSpace(r.Manifest) == "" && len(hooks) == 0 {
+		return nil
+	}
+	b := bytes.NewBufferString(r.Manifest)
+	for _, h := range hooks {
+		b.WriteString("\n---\n" + h.Manifest)
+	}
+	return s.kubeClientFor(r).DryRun(r.Namespace, b)
+}
+
+// createResources creates the resources in the manifest of r, using
+// server-side apply if serverSide is true.
+func (s *ReleaseServer) createResources(r *release.Release, serverSide bool) error {
+	kubeCli := s.kubeClientFor(r)
+	b := bytes.NewBufferString(r.Manifest)
+	if serverSide {
+		return kubeCli.Apply(r.Namespace, nil, b)
+	}
+	return kubeCli.Create(r.Namespace, b)
+}
+
+// waitForResources waits until the resources in the manifest of r are ready,
+// if wait or jobs is set. Waiting for Jobs implies waiting for everything else.
+func (s *ReleaseServer) waitForResources(r *release.Release, wait, jobs bool, timeout int64) error {
+	if !wait && !jobs {
+		return nil
+	}
+	opts := kube.WaitOptions{Timeout: time.Duration(timeout) * time.Second, Jobs: jobs}
+	return s.env.KubeClient.Wait(r.Namespace, bytes.NewBufferString(r.Manifest), opts)
+}
+
+// kubeClientFor returns the KubeClient to change the resources of r with. It
+// labels the resources with the release, revision and chart of r.
+func (s *ReleaseServer) kubeClientFor(r *release.Release) environment.KubeClient {
+	kc, ok := s.env.KubeClient.(*kube.Client)
+	if !ok {
+		return s.env.KubeClient
+	}
+	owner := kube.Owner{Release: r.Name, Revision: r.Version}
+	if md := r.GetChart().GetMetadata(); md != nil {
+		owner.Chart = md.Name + "-" + md.Version
+	}
+	return kc.OwnedBy(owner)
+}
+
+// execHook runs the hooks of r for the given hook event.
+func (s *ReleaseServer) execHook(r *release.Release, hook string) error {
+	kubeCli := s.kubeClientFor(r)
 	code, ok := events[hook]
 	if !ok {
 		return fmt.Errorf("unknown hook %q", hook)
 	}
 
-	log.Printf("Executing %s hooks for %s", hook, name)
-	for _, h := range hs {
+	s.logf("Executing %s hooks for %s", hook, r.Name)
+	for _, h := range r.Hooks {
 		found := false
 		for _, e := range h.Events {
 			if e == code {
@@ -884,24 +1452,53 @@ func (s *ReleaseServer) execHook(hs []*release.Hook, name, namespace, hook strin
 			continue
 		}
 
+		exec := &release.HookExecution{
+			Event:     code,
+			StartedAt: timeconv.Now(),
+			Phase:     release.HookExecution_RUNNING,
+			Logs:      hookLogs(h, r.Namespace),
+		}
+		h.LastExecution = exec
+
 		b := bytes.NewBufferString(h.Manifest)
-		if err := kubeCli.Create(namespace, b); err != nil {
-			log.Printf("warning: Release %q pre-install %s failed: %s", name, h.Path, err)
+		if err := kubeCli.Create(r.Namespace, b); err != nil {
+			s.logf("warning: Release %q pre-install %s failed: %s", r.Name, h.Path, err)
+			failHook(exec, err)
 			return err
 		}
 		// No way to rewind a bytes.Buffer()?
 		b.Reset()
 		b.WriteString(h.Manifest)
-		if err := kubeCli.WatchUntilReady(namespace, b); err != nil {
-			log.Printf("warning: Release %q pre-install %s could not complete: %s", name, h.Path, err)
+		if err := kubeCli.WatchUntilReady(r.Namespace, b); err != nil {
+			s.logf("warning: Release %q pre-install %s could not complete: %s", r.Name, h.Path, err)
+			failHook(exec, err)
 			return err
 		}
 		h.LastRun = timeconv.Now()
+		exec.CompletedAt = h.LastRun
+		exec.Phase = release.HookExecution_SUCCEEDED
 	}
-	log.Printf("Hooks complete for %s %s", hook, name)
+	s.logf("Hooks complete for %s %s", hook, r.Name)
 	return nil
 }
 
+// failHook records the failure of a hook run.
+func failHook(exec *release.HookExecution, err error) {
+	exec.CompletedAt = timeconv.Now()
+	exec.Phase = release.HookExecution_FAILED
+	exec.Message = err.Error()
+}
+
+// hookLogs tells where the output of a hook can be found. Only the pods that
+// hooks run in have logs.
+func hookLogs(h *release.Hook, namespace string) string {
+	switch h.Kind {
+	case "Job", "Pod":
+		return fmt.Sprintf("kubectl logs --namespace %s %s/%s", namespace, strings.ToLower(h.Kind), h.Name)
+	}
+	return ""
+}
+
 func (s *ReleaseServer) purgeReleases(rels ...*release.Release) error {
 	for _, rel := range rels {
 		if _, err := s.env.Releases.Delete(rel.Name, rel.Version); err != nil {
@@ -917,14 +1514,19 @@ func (s *ReleaseServer) UninstallRelease(c ctx.Context, req *services.UninstallR
 		return nil, errIncompatibleVersion
 	}
 
+	s, err := s.forRequest(c)
+	if err != nil {
+		return nil, err
+	}
+
 	if !ValidName.MatchString(req.Name) {
-		log.Printf("uninstall: Release not found: %s", req.Name)
+		s.logf("uninstall: Release not found: %s", req.Name)
 		return nil, errMissingRelease
 	}
 
 	rels, err := s.env.Releases.History(req.Name)
 	if err != nil {
-		log.Printf("uninstall: Release not loaded: %s", req.Name)
+		s.logf("uninstall: Release not loaded: %s", req.Name)
 		return nil, err
 	}
 	if len(rels) < 1 {
@@ -934,12 +1536,27 @@ func (s *ReleaseServer) UninstallRelease(c ctx.Context, req *services.UninstallR
 	relutil.SortByRevision(rels)
 	rel := rels[len(rels)-1]
 
+	switch req.Cascade {
+	case "", kube.PropagationBackground, kube.PropagationForeground, kube.PropagationOrphan:
+	default:
+		return nil, fmt.Errorf("invalid cascade %q, must be %s, %s or %s", req.Cascade, kube.PropagationBackground, kube.PropagationForeground, kube.PropagationOrphan)
+	}
+
+	md, err := checkLock(rel, "delete", req.OverrideLock, rel.Info.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	rel.Info.Metadata = md
+	if err := s.checkGates(rel, "delete", req.OverrideGates); err != nil {
+		return nil, err
+	}
+
 	// TODO: Are there any cases where we want to force a delete even if it's
 	// already marked deleted?
 	if rel.Info.Status.Code == release.Status_DELETED {
 		if req.Purge {
 			if err := s.purgeReleases(rels...); err != nil {
-				log.Printf("uninstall: Failed to purge the release: %s", err)
+				s.logf("uninstall: Failed to purge the release: %s", err)
 				return nil, err
 			}
 			return &services.UninstallReleaseResponse{Release: rel}, nil
@@ -947,13 +1564,14 @@ func (s *ReleaseServer) UninstallRelease(c ctx.Context, req *services.UninstallR
 		return nil, fmt.Errorf("the release named %q is already deleted", req.Name)
 	}
 
-	log.Printf("uninstall: Deleting %s", req.Name)
+	s.logf("uninstall: Deleting %s", req.Name)
 	rel.Info.Status.Code = release.Status_DELETED
 	rel.Info.Deleted = timeconv.Now()
 	res := &services.UninstallReleaseResponse{Release: rel}
 
 	if !req.DisableHooks {
-		if err := s.execHook(rel.Hooks, rel.Name, rel.Namespace, preDelete); err != nil {
+		if err := s.execHook(rel, preDelete); err != nil {
+			s.notify(notify.Delete, rel, rel.Manifest, err)
 			return res, err
 		}
 	}
@@ -967,11 +1585,17 @@ func (s *ReleaseServer) UninstallRelease(c ctx.Context, req *services.UninstallR
 	// state. See https://github.com/kubernetes/helm/issues/1511 for a better way
 	// to do this.
 	if err := s.env.Releases.Update(rel); err != nil {
-		log.Printf("uninstall: Failed to store updated release: %s", err)
+		s.logf("uninstall: Failed to store updated release: %s", err)
 	}
 
+	// Waiting tears the release down kind by kind, the reverse of how it
+	// was installed.
+	order := UninstallOrder
+	if req.Wait {
+		order = InstallOrder
+	}
 	manifests := splitManifests(rel.Manifest)
-	_, files, err := sortManifests(manifests, vs, UninstallOrder)
+	_, files, err := s.sortManifests(manifests, vs, order)
 	if err != nil {
 		// We could instead just delete everything in no particular order.
 		// FIXME: One way to delete at this point would be to try a label-based
@@ -979,13 +1603,41 @@ func (s *ReleaseServer) UninstallRelease(c ctx.Context, req *services.UninstallR
 		// and delete something that was not legitimately part of this release.
 		return nil, fmt.Errorf("corrupted release record. You must manually delete the resources: %s", err)
 	}
+	if !req.IncludeKept {
+		files, res.Kept = filterKept(files)
+		if len(res.Kept) > 0 {
+			s.logf("uninstall: Keeping resources of %q because of their resource policy: %s", req.Name, strings.Join(res.Kept, ", "))
+		}
+	}
+	batches := [][]manifest{}
+	for _, file := range files {
+		batches = append(batches, []manifest{file})
+	}
+	if req.Wait {
+		batches = teardownBatches(files)
+	}
 
 	// Collect the errors, and return them later.
 	es := []string{}
-	for _, file := range files {
-		b := bytes.NewBufferString(file.content)
-		if err := s.env.KubeClient.Delete(rel.Namespace, b); err != nil {
-			log.Printf("uninstall: Failed deletion of %q: %s", req.Name, err)
+	deadline := time.Now().Add(time.Duration(req.Timeout) * time.Second)
+	for _, batch := range batches {
+		docs := make([]string, len(batch))
+		for i, file := range batch {
+			docs[i] = file.content
+		}
+		b := bytes.NewBufferString(strings.Join(docs, "\n---\n"))
+		opts := kube.DeleteOptions{Propagation: req.Cascade, Wait: req.Wait}
+		if req.Wait && req.Timeout > 0 {
+			// The timeout is for the whole release, not for each kind. As a
+			// zero timeout means the default one, an expired deadline still
+			// leaves a moment to find the resources gone.
+			opts.Timeout = deadline.Sub(time.Now())
+			if opts.Timeout <= 0 {
+				opts.Timeout = time.Nanosecond
+			}
+		}
+		if err := s.env.KubeClient.DeleteWithOptions(rel.Namespace, b, opts); err != nil {
+			s.logf("uninstall: Failed deletion of %q: %s", req.Name, err)
 			if err == kube.ErrNoObjectsVisited {
 				// Rewrite the message from "no objects visited"
 				err = errors.New("object not found, skipping delete")
@@ -995,14 +1647,14 @@ func (s *ReleaseServer) UninstallRelease(c ctx.Context, req *services.UninstallR
 	}
 
 	if !req.DisableHooks {
-		if err := s.execHook(rel.Hooks, rel.Name, rel.Namespace, postDelete); err != nil {
+		if err := s.execHook(rel, postDelete); err != nil {
 			es = append(es, err.Error())
 		}
 	}
 
 	if req.Purge {
 		if err := s.purgeReleases(rels...); err != nil {
-			log.Printf("uninstall: Failed to purge the release: %s", err)
+			s.logf("uninstall: Failed to purge the release: %s", err)
 		}
 	}
 
@@ -1011,6 +1663,7 @@ func (s *ReleaseServer) UninstallRelease(c ctx.Context, req *services.UninstallR
 		errs = fmt.Errorf("deletion completed with %d error(s): %s", len(es), strings.Join(es, "; "))
 	}
 
+	s.notify(notify.Delete, rel, rel.Manifest, errs)
 	return res, errs
 }
 
@@ -20,22 +20,31 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/ghodss/yaml"
 	"github.com/golang/protobuf/ptypes/timestamp"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc/metadata"
 
+	"k8s.io/helm/pkg/chartutil"
 	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/kube"
 	"k8s.io/helm/pkg/proto/hapi/chart"
 	"k8s.io/helm/pkg/proto/hapi/release"
 	"k8s.io/helm/pkg/proto/hapi/services"
 	"k8s.io/helm/pkg/storage"
 	"k8s.io/helm/pkg/storage/driver"
 	"k8s.io/helm/pkg/tiller/environment"
+	"k8s.io/helm/pkg/valueref"
+	"k8s.io/helm/pkg/version"
 )
 
 const notesText = "my notes here"
@@ -166,6 +175,25 @@ func TestValidName(t *testing.T) {
 	}
 }
 
+func TestCheckClientVersion(t *testing.T) {
+	tests := []struct {
+		md       metadata.MD
+		expected bool
+	}{
+		{metadata.Pairs("x-helm-api-client", version.Version), true},
+		{metadata.Pairs("x-helm-api-client", "v0.1.0"), false},
+		// The API version takes precedence over the release version.
+		{metadata.Pairs("x-helm-api-client", "v0.1.0", "x-helm-api-version", strconv.Itoa(version.APIVersion)), true},
+		{metadata.Pairs("x-helm-api-client", version.Version, "x-helm-api-version", strconv.Itoa(version.APIVersion+1)), false},
+		{metadata.MD{}, false},
+	}
+	for _, tt := range tests {
+		if got := checkClientVersion(metadata.NewContext(context.TODO(), tt.md)); got != tt.expected {
+			t.Errorf("expected client with %v to be compatible: %v", tt.md, tt.expected)
+		}
+	}
+}
+
 func TestGetVersionSet(t *testing.T) {
 	rs := rsFixture()
 	vs, err := rs.getVersionSet()
@@ -180,6 +208,23 @@ func TestGetVersionSet(t *testing.T) {
 	}
 }
 
+func TestCapabilities(t *testing.T) {
+	rs := rsFixture()
+	caps, err := rs.capabilities()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !caps.APIVersions.Has("v1") {
+		t.Error("Expected capabilities to include v1.")
+	}
+	if caps.KubeVersion == nil || caps.KubeVersion.GitVersion == "" {
+		t.Error("Expected capabilities to include a Kubernetes version.")
+	}
+	if caps.TillerVersion.SemVer != version.GetVersion() {
+		t.Errorf("Expected Tiller version %q, got %q", version.GetVersion(), caps.TillerVersion.SemVer)
+	}
+}
+
 func TestUniqName(t *testing.T) {
 	rs := rsFixture()
 
@@ -349,6 +394,57 @@ func TestInstallReleaseWithNotes(t *testing.T) {
 	}
 }
 
+type secretResolver map[string]string
+
+func (r secretResolver) Resolve(ref *valueref.Ref) (string, error) {
+	v, ok := r[ref.Path]
+	if !ok {
+		return "", fmt.Errorf("no secret %s", ref.Path)
+	}
+	return v, nil
+}
+
+func TestInstallReleaseValueRefs(t *testing.T) {
+	c := helm.NewContext()
+	rs := rsFixture()
+	rs.env.ValueResolvers = valueref.Resolvers{"test": secretResolver{"db": "s3cr3t", "other": "0th3r"}}
+	rs.env.ValuePolicy = valueref.Policy{"spaced": {"test://db", "test://nope"}}
+
+	req := &services.InstallReleaseRequest{
+		Namespace: "spaced",
+		Chart: &chart.Chart{
+			Metadata:  &chart.Metadata{Name: "hello"},
+			Templates: []*chart.Template{{Name: "templates/secret", Data: []byte("password: {{ .Values.db.password }}")}},
+		},
+		Values: &chart.Config{Raw: "db:\n  password: ref+test://db\n"},
+	}
+	res, err := rs.InstallRelease(c, req)
+	if err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+	if !strings.Contains(res.Release.Manifest, "password: s3cr3t") {
+		t.Errorf("Expected the secret in the manifest, got %q", res.Release.Manifest)
+	}
+	if res.Release.Config.Raw != req.Values.Raw {
+		t.Errorf("Expected the reference to be stored, got %q", res.Release.Config.Raw)
+	}
+
+	req.Values.Raw = "db:\n  password: ref+test://nope\n"
+	if _, err := rs.InstallRelease(c, req); err == nil || !strings.Contains(err.Error(), "cannot resolve ref+test://nope (value db.password): no secret nope") {
+		t.Errorf("Expected an error resolving the reference, got %v", err)
+	}
+
+	req.Values.Raw = "db:\n  password: ref+test://other\n"
+	if _, err := rs.InstallRelease(c, req); err == nil || !strings.Contains(err.Error(), "may not refer to") {
+		t.Errorf("Expected a reference outside of the policy to fail, got %v", err)
+	}
+	req.Values.Raw = "db:\n  password: ref+test://db\n"
+	req.Namespace = "other"
+	if _, err := rs.InstallRelease(c, req); err == nil || !strings.Contains(err.Error(), "may not refer to") {
+		t.Errorf("Expected a reference from another namespace to fail, got %v", err)
+	}
+}
+
 func TestInstallReleaseWithNotesRendered(t *testing.T) {
 	c := helm.NewContext()
 	rs := rsFixture()
@@ -462,6 +558,47 @@ func TestInstallReleaseWithChartAndDependencyNotes(t *testing.T) {
 	}
 }
 
+func TestInstallReleaseWithSubchartNotes(t *testing.T) {
+	c := helm.NewContext()
+	rs := rsFixture()
+
+	req := &services.InstallReleaseRequest{
+		Namespace:     "spaced",
+		SubchartNotes: true,
+		Chart: &chart.Chart{
+			Metadata: &chart.Metadata{Name: "hello"},
+			Templates: []*chart.Template{
+				{Name: "templates/hello", Data: []byte("hello: world")},
+				{Name: "templates/NOTES.txt", Data: []byte(notesText)},
+			},
+			Dependencies: []*chart.Chart{
+				{
+					Metadata: &chart.Metadata{Name: "goodbye"},
+					Templates: []*chart.Template{
+						{Name: "templates/NOTES.txt", Data: []byte(notesText + " goodbye")},
+					},
+				},
+				{
+					Metadata: &chart.Metadata{Name: "empty"},
+					Templates: []*chart.Template{
+						{Name: "templates/NOTES.txt", Data: []byte("")},
+					},
+				},
+			},
+		},
+	}
+
+	res, err := rs.InstallRelease(c, req)
+	if err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+
+	expect := notesText + "\n\n" + notesText + " goodbye\n"
+	if res.Release.Info.Status.Notes != expect {
+		t.Errorf("Expected %q, got %q", expect, res.Release.Info.Status.Notes)
+	}
+}
+
 func TestInstallReleaseDryRun(t *testing.T) {
 	c := helm.NewContext()
 	rs := rsFixture()
@@ -511,6 +648,38 @@ func TestInstallReleaseDryRun(t *testing.T) {
 	}
 }
 
+func TestInstallReleaseServerDryRun(t *testing.T) {
+	c := helm.NewContext()
+	rs := rsFixture()
+	kc := &dryRunKubeClient{PrintingKubeClient: environment.PrintingKubeClient{Out: ioutil.Discard}}
+	rs.env.KubeClient = kc
+
+	req := &services.InstallReleaseRequest{
+		Chart:        chartStub(),
+		DryRun:       true,
+		ServerDryRun: true,
+	}
+	res, err := rs.InstallRelease(c, req)
+	if err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+	if !strings.Contains(kc.sent, "hello: world") || !strings.Contains(kc.sent, "name: test-cm") {
+		t.Errorf("Expected the manifest and hooks to be dry run, got %q", kc.sent)
+	}
+	if _, err := rs.env.Releases.Get(res.Release.Name, res.Release.Version); err == nil {
+		t.Errorf("Expected no stored release.")
+	}
+
+	kc.sent, kc.err = "", errors.New("admission webhook denied the request")
+	req.DisableHooks = true
+	if _, err := rs.InstallRelease(c, req); err == nil || !strings.Contains(err.Error(), "admission webhook") {
+		t.Errorf("Expected the dry run error, got %v", err)
+	}
+	if strings.Contains(kc.sent, "name: test-cm") {
+		t.Errorf("Expected hooks to be left out with DisableHooks, got %q", kc.sent)
+	}
+}
+
 func TestInstallReleaseNoHooks(t *testing.T) {
 	c := helm.NewContext()
 	rs := rsFixture()
@@ -547,6 +716,131 @@ func TestInstallReleaseFailedHooks(t *testing.T) {
 	if hl := res.Release.Info.Status.Code; hl != release.Status_FAILED {
 		t.Errorf("Expected FAILED release. Got %d", hl)
 	}
+
+	exec := res.Release.Hooks[0].LastExecution
+	if exec == nil {
+		t.Fatal("Expected the hook run to be recorded")
+	}
+	if exec.Event != release.Hook_POST_INSTALL || exec.Phase != release.HookExecution_FAILED {
+		t.Errorf("Expected a failed post-install run, got %s %s", exec.Event, exec.Phase)
+	}
+	if exec.Message != "Failed watch" {
+		t.Errorf("Unexpected hook message %q", exec.Message)
+	}
+	if exec.StartedAt == nil || exec.CompletedAt == nil {
+		t.Errorf("Expected start and completion times, got %v", exec)
+	}
+}
+
+func TestInstallReleaseServerSide(t *testing.T) {
+	c := helm.NewContext()
+	rs := rsFixture()
+	kc := newApplyRecordingKubeClient()
+	rs.env.KubeClient = kc
+
+	req := &services.InstallReleaseRequest{
+		Chart:      chartStub(),
+		ServerSide: true,
+	}
+	if _, err := rs.InstallRelease(c, req); err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+
+	if kc.applied != 1 {
+		t.Errorf("Expected resources to be applied once, got %d", kc.applied)
+	}
+}
+
+func TestInstallReleaseIncludeTemplates(t *testing.T) {
+	c := helm.NewContext()
+	rs := rsFixture()
+
+	req := &services.InstallReleaseRequest{
+		Chart:            chartStub(),
+		IncludeTemplates: []string{"templates/hello", "ConfigMap/test-*"},
+		ExcludeTemplates: []string{"hello/templates/goodbye"},
+	}
+	res, err := rs.InstallRelease(c, req)
+	if err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+
+	if !strings.Contains(res.Release.Manifest, "hello: world") {
+		t.Errorf("Expected included template in manifest, got %q", res.Release.Manifest)
+	}
+	if strings.Contains(res.Release.Manifest, "goodbye") || strings.Contains(res.Release.Manifest, "Earth") {
+		t.Errorf("Expected other templates to be left out, got %q", res.Release.Manifest)
+	}
+	if len(res.Release.Hooks) != 1 {
+		t.Errorf("Expected the selected hook, got %d hooks", len(res.Release.Hooks))
+	}
+}
+
+func TestInstallReleaseBadTemplatePattern(t *testing.T) {
+	c := helm.NewContext()
+	rs := rsFixture()
+
+	req := &services.InstallReleaseRequest{
+		Chart:            chartStub(),
+		IncludeTemplates: []string{"templates/[hello"},
+	}
+	if _, err := rs.InstallRelease(c, req); err == nil {
+		t.Fatal("Expected an error for a malformed template pattern")
+	}
+}
+
+func TestInstallReleaseTillerVersionConstraint(t *testing.T) {
+	c := helm.NewContext()
+	rs := rsFixture()
+
+	ch := chartStub()
+	ch.Dependencies = []*chart.Chart{
+		{Metadata: &chart.Metadata{Name: "sub", TillerVersion: ">=100.0.0"}},
+	}
+	req := &services.InstallReleaseRequest{Chart: ch}
+	_, err := rs.InstallRelease(c, req)
+	if err == nil {
+		t.Fatal("Expected install to fail on an unsatisfied tillerVersion constraint")
+	}
+	if !strings.Contains(err.Error(), `chart "sub" requires Tiller >=100.0.0`) {
+		t.Errorf("Unexpected error: %s", err)
+	}
+
+	req.DisableVersionCheck = true
+	if _, err := rs.InstallRelease(c, req); err != nil {
+		t.Errorf("Expected version check to be skipped, got %s", err)
+	}
+}
+
+func TestInstallReleaseLookup(t *testing.T) {
+	c := helm.NewContext()
+	rs := rsFixture()
+	rs.env.KubeClient = &lookupKubeClient{
+		PrintingKubeClient: environment.PrintingKubeClient{Out: os.Stdout},
+		obj:                map[string]interface{}{"data": map[string]interface{}{"password": "hunter2"}},
+	}
+
+	ch := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "hello"},
+		Templates: []*chart.Template{
+			{Name: "templates/secret", Data: []byte(`{{$s := lookup "v1" "Secret" .Release.Namespace "pw"}}password: {{if $s}}{{$s.data.password}}{{else}}new{{end}}`)},
+		},
+	}
+	res, err := rs.InstallRelease(c, &services.InstallReleaseRequest{Chart: ch, Namespace: "spaced"})
+	if err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+	if !strings.Contains(res.Release.Manifest, "password: hunter2") {
+		t.Errorf("Expected looked up value in manifest, got %q", res.Release.Manifest)
+	}
+
+	res, err = rs.InstallRelease(c, &services.InstallReleaseRequest{Chart: ch, DryRun: true})
+	if err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+	if !strings.Contains(res.Release.Manifest, "password: new") {
+		t.Errorf("Expected lookup to be stubbed for dry runs, got %q", res.Release.Manifest)
+	}
 }
 
 func TestInstallReleaseReuseName(t *testing.T) {
@@ -629,6 +923,12 @@ func TestUpdateRelease(t *testing.T) {
 		t.Errorf("Expected event 0 to be post upgrade")
 	}
 
+	if exec := updated.Hooks[0].LastExecution; exec == nil || exec.Phase != release.HookExecution_SUCCEEDED {
+		t.Errorf("Expected a succeeded hook run, got %v", exec)
+	} else if exec.Event != release.Hook_POST_UPGRADE {
+		t.Errorf("Expected the last run to be post upgrade, got %s", exec.Event)
+	}
+
 	if updated.Hooks[0].Events[1] != release.Hook_PRE_UPGRADE {
 		t.Errorf("Expected event 0 to be pre upgrade")
 	}
@@ -656,6 +956,235 @@ func TestUpdateRelease(t *testing.T) {
 	}
 }
 
+func TestInstallReleaseWait(t *testing.T) {
+	c := helm.NewContext()
+	rs := rsFixture()
+	kc := &waitKubeClient{PrintingKubeClient: environment.PrintingKubeClient{Out: os.Stdout}, err: errors.New("timed out")}
+	rs.env.KubeClient = kc
+
+	res, err := rs.InstallRelease(c, &services.InstallReleaseRequest{Chart: chartStub()})
+	if err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+	if kc.opts != nil {
+		t.Errorf("Expected no wait without Wait, got %+v", kc.opts)
+	}
+
+	req := &services.InstallReleaseRequest{Chart: chartStub(), Wait: true, Timeout: 60}
+	res, err = rs.InstallRelease(c, req)
+	if err == nil {
+		t.Fatal("Expected failed install")
+	}
+	if !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if hl := res.Release.Info.Status.Code; hl != release.Status_FAILED {
+		t.Errorf("Expected FAILED release. Got %s", hl)
+	}
+	if kc.opts == nil || kc.opts.Timeout != time.Minute || kc.opts.Jobs {
+		t.Errorf("Unexpected wait options %+v", kc.opts)
+	}
+}
+
+func TestUpdateReleaseWaitForJobs(t *testing.T) {
+	c := helm.NewContext()
+	rs := rsFixture()
+	rel := releaseStub()
+	rs.env.Releases.Create(rel)
+	kc := &waitKubeClient{PrintingKubeClient: environment.PrintingKubeClient{Out: os.Stdout}}
+	rs.env.KubeClient = kc
+
+	req := &services.UpdateReleaseRequest{
+		Name:        rel.Name,
+		Chart:       chartStub(),
+		WaitForJobs: true,
+	}
+	res, err := rs.UpdateRelease(c, req)
+	if err != nil {
+		t.Fatalf("Failed update: %s", err)
+	}
+	if res.Release.Info.Status.Code != release.Status_DEPLOYED {
+		t.Errorf("Expected DEPLOYED release. Got %s", res.Release.Info.Status.Code)
+	}
+	if kc.opts == nil || !kc.opts.Jobs || kc.opts.Timeout != 0 {
+		t.Errorf("Unexpected wait options %+v", kc.opts)
+	}
+}
+
+func TestMigrateAPIVersions(t *testing.T) {
+	rs := rsFixture()
+	rel := releaseStub()
+	rel.Manifest = `---
+# Source: hello/templates/deployment.yaml
+apiVersion: extensions/v1beta1
+kind: Deployment
+metadata:
+  name: hello
+---
+# Source: hello/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: hello
+`
+	if err := rs.migrateAPIVersions(rel, chartutil.NewVersionSet("v1", "extensions/v1beta1", "apps/v1")); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(rel.Manifest, "apiVersion: extensions/v1beta1\nkind: Deployment") {
+		t.Errorf("expected a served API version to be kept, got %s", rel.Manifest)
+	}
+
+	if err := rs.migrateAPIVersions(rel, chartutil.NewVersionSet("v1", "apps/v1")); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(rel.Manifest, "apiVersion: apps/v1\nkind: Deployment") {
+		t.Errorf("expected the Deployment to be migrated to apps/v1, got %s", rel.Manifest)
+	}
+	if !strings.Contains(rel.Manifest, "apiVersion: v1\nkind: Service") {
+		t.Errorf("expected the Service to be kept, got %s", rel.Manifest)
+	}
+}
+
+func TestUpdateReleaseServerSide(t *testing.T) {
+	c := helm.NewContext()
+	rs := rsFixture()
+	kc := newApplyRecordingKubeClient()
+	rs.env.KubeClient = kc
+	rel := releaseStub()
+	rs.env.Releases.Create(rel)
+
+	req := &services.UpdateReleaseRequest{
+		Name:       rel.Name,
+		Chart:      chartStub(),
+		ServerSide: true,
+	}
+	if _, err := rs.UpdateRelease(c, req); err != nil {
+		t.Fatalf("Failed updated: %s", err)
+	}
+
+	if kc.applied != 1 {
+		t.Errorf("Expected resources to be applied once, got %d", kc.applied)
+	}
+}
+
+func TestUpdateReleaseServerDryRun(t *testing.T) {
+	c := helm.NewContext()
+	rs := rsFixture()
+	kc := &dryRunKubeClient{PrintingKubeClient: environment.PrintingKubeClient{Out: ioutil.Discard}}
+	rs.env.KubeClient = kc
+	rel := releaseStub()
+	rs.env.Releases.Create(rel)
+
+	req := &services.UpdateReleaseRequest{
+		Name:         rel.Name,
+		Chart:        chartStub(),
+		DryRun:       true,
+		ServerDryRun: true,
+	}
+	res, err := rs.UpdateRelease(c, req)
+	if err != nil {
+		t.Fatalf("Failed updated: %s", err)
+	}
+	if !strings.Contains(kc.sent, "goodbye: world") {
+		t.Errorf("Expected the manifest to be dry run, got %q", kc.sent)
+	}
+	if _, err := rs.env.Releases.Get(rel.Name, res.Release.Version); err == nil {
+		t.Errorf("Expected no stored release.")
+	}
+}
+
+func TestUpdateReleaseIncludeTemplates(t *testing.T) {
+	c := helm.NewContext()
+	rs := rsFixture()
+	rel := releaseStub()
+	rel.Manifest = "\n---\n# Source: hello/templates/hello\nhello: mars\n---\n# Source: hello/templates/goodbye\ngoodbye: mars"
+	rs.env.Releases.Create(rel)
+
+	req := &services.UpdateReleaseRequest{
+		Name:             rel.Name,
+		Chart:            chartStub(),
+		IncludeTemplates: []string{"templates/hello"},
+	}
+	res, err := rs.UpdateRelease(c, req)
+	if err != nil {
+		t.Fatalf("Failed updated: %s", err)
+	}
+
+	if !strings.Contains(res.Release.Manifest, "hello: world") {
+		t.Errorf("Expected selected template to be updated, got %q", res.Release.Manifest)
+	}
+	if !strings.Contains(res.Release.Manifest, "goodbye: mars") {
+		t.Errorf("Expected unselected template to be carried over, got %q", res.Release.Manifest)
+	}
+	if strings.Contains(res.Release.Manifest, "hello: mars") || strings.Contains(res.Release.Manifest, "goodbye: world") {
+		t.Errorf("Unexpected manifest content %q", res.Release.Manifest)
+	}
+}
+
+func TestUpdateReleaseOnlySubcharts(t *testing.T) {
+	c := helm.NewContext()
+	rs := rsFixture()
+	rel := releaseStub()
+	rel.Manifest = "\n---\n# Source: hello/templates/hello\nhello: mars\n---\n# Source: hello/charts/redis/templates/redis\nredis: mars"
+	rs.env.Releases.Create(rel)
+
+	ch := chartStub()
+	ch.Dependencies = []*chart.Chart{{
+		Metadata:  &chart.Metadata{Name: "redis"},
+		Templates: []*chart.Template{{Name: "templates/redis", Data: []byte("redis: world")}},
+	}}
+	req := &services.UpdateReleaseRequest{
+		Name:          rel.Name,
+		Chart:         ch,
+		OnlySubcharts: []string{"redis"},
+	}
+	res, err := rs.UpdateRelease(c, req)
+	if err != nil {
+		t.Fatalf("Failed updated: %s", err)
+	}
+	if !strings.Contains(res.Release.Manifest, "redis: world") {
+		t.Errorf("Expected the subchart to be updated, got %q", res.Release.Manifest)
+	}
+	if !strings.Contains(res.Release.Manifest, "hello: mars") || strings.Contains(res.Release.Manifest, "hello: world") {
+		t.Errorf("Expected the parent chart to be carried over, got %q", res.Release.Manifest)
+	}
+
+	req.OnlySubcharts = []string{"mysql"}
+	if _, err := rs.UpdateRelease(c, req); err == nil || !strings.Contains(err.Error(), `has no subchart "mysql"`) {
+		t.Errorf("Expected an error for a missing subchart, got %v", err)
+	}
+}
+
+func TestUpdateReleaseStableRand(t *testing.T) {
+	c := helm.NewContext()
+	rs := rsFixture()
+
+	ch := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "hello"},
+		Templates: []*chart.Template{
+			{Name: "templates/secret", Data: []byte(`password: {{stableRandAlphaNum "pw" 16}}`)},
+		},
+	}
+	res, err := rs.InstallRelease(c, &services.InstallReleaseRequest{Chart: ch})
+	if err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+	if len(res.Release.Seed) == 0 {
+		t.Fatal("Expected the release to have a seed")
+	}
+
+	upres, err := rs.UpdateRelease(c, &services.UpdateReleaseRequest{Name: res.Release.Name, Chart: ch})
+	if err != nil {
+		t.Fatalf("Failed upgrade: %s", err)
+	}
+	if upres.Release.Manifest != res.Release.Manifest {
+		t.Errorf("Expected stable values across upgrades, got %q and %q", res.Release.Manifest, upres.Release.Manifest)
+	}
+	if string(upres.Release.Seed) != string(res.Release.Seed) {
+		t.Error("Expected the seed to be kept across upgrades")
+	}
+}
+
 func TestUpdateReleaseFailure(t *testing.T) {
 	c := helm.NewContext()
 	rs := rsFixture()
@@ -725,6 +1254,61 @@ func TestRollbackReleaseFailure(t *testing.T) {
 	}
 }
 
+func TestUpdateReleaseFailedHooks(t *testing.T) {
+	c := helm.NewContext()
+	rs := rsFixture()
+	rel := releaseStub()
+	rs.env.Releases.Create(rel)
+	rs.env.KubeClient = newHookFailingKubeClient()
+
+	req := &services.UpdateReleaseRequest{
+		Name: rel.Name,
+		Chart: &chart.Chart{
+			Metadata: &chart.Metadata{Name: "hello"},
+			Templates: []*chart.Template{
+				{Name: "templates/hello", Data: []byte("hello: world")},
+				{Name: "templates/hooks", Data: []byte(manifestWithUpgradeHooks)},
+			},
+		},
+	}
+	if _, err := rs.UpdateRelease(c, req); err == nil {
+		t.Fatal("Expected failed update")
+	}
+
+	failed, err := rs.env.Releases.Get(rel.Name, 2)
+	if err != nil {
+		t.Fatalf("Expected the failed revision to be recorded: %s", err)
+	}
+	if failed.Info.Status.Code != release.Status_FAILED {
+		t.Errorf("Expected FAILED release. Got %s", failed.Info.Status.Code)
+	}
+	exec := failed.Hooks[0].LastExecution
+	if exec == nil {
+		t.Fatal("Expected the hook run to be recorded")
+	}
+	if exec.Event != release.Hook_PRE_UPGRADE || exec.Phase != release.HookExecution_FAILED {
+		t.Errorf("Expected a failed pre-upgrade run, got %s %s", exec.Event, exec.Phase)
+	}
+	if exec.Logs != "" {
+		t.Errorf("Expected no logs for a ConfigMap hook, got %q", exec.Logs)
+	}
+
+	old, err := rs.env.Releases.Get(rel.Name, rel.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if old.Info.Status.Code != release.Status_DEPLOYED {
+		t.Errorf("Expected the previous release to stay DEPLOYED. Got %s", old.Info.Status.Code)
+	}
+}
+
+func TestHookLogs(t *testing.T) {
+	h := &release.Hook{Name: "migrate", Kind: "Job"}
+	if got := hookLogs(h, "prod"); got != "kubectl logs --namespace prod job/migrate" {
+		t.Errorf("Unexpected logs pointer %q", got)
+	}
+}
+
 func TestUpdateReleaseNoHooks(t *testing.T) {
 	c := helm.NewContext()
 	rs := rsFixture()
@@ -1053,6 +1637,87 @@ func TestUninstallReleaseNoHooks(t *testing.T) {
 	}
 }
 
+func TestUninstallReleaseWait(t *testing.T) {
+	c := helm.NewContext()
+	rs := rsFixture()
+	kc := &deleteKubeClient{PrintingKubeClient: environment.PrintingKubeClient{Out: ioutil.Discard}}
+	rs.env.KubeClient = kc
+	rel := releaseStub()
+	rel.Manifest = strings.Join([]string{
+		"apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config",
+		"apiVersion: v1\nkind: Service\nmetadata:\n  name: web",
+		"apiVersion: v1\nkind: Deployment\nmetadata:\n  name: web",
+		"apiVersion: v1\nkind: Deployment\nmetadata:\n  name: worker",
+	}, "\n---\n")
+	rs.env.Releases.Create(rel)
+
+	req := &services.UninstallReleaseRequest{Name: rel.Name, DisableHooks: true, Cascade: "foreground", Wait: true, Timeout: 60}
+	if _, err := rs.UninstallRelease(c, req); err != nil {
+		t.Fatalf("Failed uninstall: %s", err)
+	}
+
+	expect := []string{"Deployment Deployment", "Service", "ConfigMap"}
+	if strings.Join(kc.batches, ", ") != strings.Join(expect, ", ") {
+		t.Errorf("Expected deletions %v, got %v", expect, kc.batches)
+	}
+	for _, opts := range kc.opts {
+		if opts.Propagation != "foreground" || !opts.Wait || opts.Timeout <= 0 || opts.Timeout > time.Minute {
+			t.Errorf("Unexpected delete options: %+v", opts)
+		}
+	}
+}
+
+func TestUninstallReleaseKeep(t *testing.T) {
+	rs := rsFixture()
+	kc := &deleteKubeClient{PrintingKubeClient: environment.PrintingKubeClient{Out: ioutil.Discard}}
+	rs.env.KubeClient = kc
+	rel := releaseStub()
+	rel.Manifest = strings.Join([]string{
+		"apiVersion: v1\nkind: Service\nmetadata:\n  name: web",
+		"apiVersion: v1\nkind: PersistentVolumeClaim\nmetadata:\n  name: data\n  annotations:\n    helm.sh/resource-policy: keep",
+	}, "\n---\n")
+	rs.env.Releases.Create(rel)
+
+	req := &services.UninstallReleaseRequest{Name: rel.Name, DisableHooks: true}
+	res, err := rs.UninstallRelease(helm.NewContext(), req)
+	if err != nil {
+		t.Fatalf("Failed uninstall: %s", err)
+	}
+	if len(kc.batches) != 1 || kc.batches[0] != "Service" {
+		t.Errorf("Expected only the Service to be deleted, got %v", kc.batches)
+	}
+	if len(res.Kept) != 1 || res.Kept[0] != "PersistentVolumeClaim data" {
+		t.Errorf("Expected the claim to be reported as kept, got %v", res.Kept)
+	}
+
+	// IncludeKept deletes kept resources too.
+	rel = namedReleaseStub("kept-anyway", release.Status_DEPLOYED)
+	rel.Manifest = "apiVersion: v1\nkind: PersistentVolumeClaim\nmetadata:\n  name: data\n  annotations:\n    helm.sh/resource-policy: keep"
+	rs.env.Releases.Create(rel)
+	kc.batches = nil
+
+	req = &services.UninstallReleaseRequest{Name: rel.Name, DisableHooks: true, IncludeKept: true}
+	if res, err = rs.UninstallRelease(helm.NewContext(), req); err != nil {
+		t.Fatalf("Failed uninstall: %s", err)
+	}
+	if len(kc.batches) != 1 || kc.batches[0] != "PersistentVolumeClaim" || len(res.Kept) != 0 {
+		t.Errorf("Expected the claim to be deleted, got %v, kept %v", kc.batches, res.Kept)
+	}
+}
+
+func TestUninstallReleaseInvalidCascade(t *testing.T) {
+	rs := rsFixture()
+	rs.env.Releases.Create(releaseStub())
+
+	req := &services.UninstallReleaseRequest{Name: "angry-panda", Cascade: "sideways"}
+	if _, err := rs.UninstallRelease(helm.NewContext(), req); err == nil || !strings.Contains(err.Error(), `invalid cascade "sideways"`) {
+		t.Errorf("Expected the cascade to be rejected, got %v", err)
+	}
+	if rel, _ := rs.env.Releases.Get("angry-panda", 1); rel.Info.Status.Code != release.Status_DEPLOYED {
+		t.Errorf("Expected the release to be left alone, got %s", rel.Info.Status.Code)
+	}
+}
+
 func TestGetReleaseContent(t *testing.T) {
 	c := helm.NewContext()
 	rs := rsFixture()
@@ -1310,8 +1975,92 @@ func (h *hookFailingKubeClient) WatchUntilReady(ns string, r io.Reader) error {
 	return errors.New("Failed watch")
 }
 
+// deleteKubeClient records the kinds of each DeleteWithOptions call, and its
+// options.
+type deleteKubeClient struct {
+	environment.PrintingKubeClient
+	batches []string
+	opts    []kube.DeleteOptions
+}
+
+func (d *deleteKubeClient) DeleteWithOptions(ns string, r io.Reader, opts kube.DeleteOptions) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	var kinds []string
+	for _, doc := range strings.Split(string(data), "\n---\n") {
+		var sh simpleHead
+		if err := yaml.Unmarshal([]byte(doc), &sh); err != nil {
+			return err
+		}
+		kinds = append(kinds, sh.Kind)
+	}
+	d.batches = append(d.batches, strings.Join(kinds, " "))
+	d.opts = append(d.opts, opts)
+	return nil
+}
+
+type lookupKubeClient struct {
+	environment.PrintingKubeClient
+	obj map[string]interface{}
+}
+
+func (l *lookupKubeClient) Lookup(apiVersion, kind, ns, name string) (map[string]interface{}, error) {
+	return l.obj, nil
+}
+
+type waitKubeClient struct {
+	environment.PrintingKubeClient
+	err  error
+	opts *kube.WaitOptions
+}
+
+func (w *waitKubeClient) Wait(ns string, r io.Reader, opts kube.WaitOptions) error {
+	w.opts = &opts
+	return w.err
+}
+
+func newApplyRecordingKubeClient() *applyRecordingKubeClient {
+	return &applyRecordingKubeClient{
+		PrintingKubeClient: environment.PrintingKubeClient{Out: os.Stdout},
+	}
+}
+
+type applyRecordingKubeClient struct {
+	environment.PrintingKubeClient
+	applied int
+}
+
+func (a *applyRecordingKubeClient) Update(ns string, currentReader, modifiedReader io.Reader) error {
+	return errors.New("Update called with server-side apply enabled")
+}
+
+func (a *applyRecordingKubeClient) Apply(ns string, currentReader, modifiedReader io.Reader) error {
+	a.applied++
+	return nil
+}
+
+// dryRunKubeClient records what is sent to DryRun, and fails it with err.
+type dryRunKubeClient struct {
+	environment.PrintingKubeClient
+	sent string
+	err  error
+}
+
+func (d *dryRunKubeClient) DryRun(ns string, r io.Reader) error {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	d.sent += string(b)
+	return d.err
+}
+
 type mockListServer struct {
 	val *services.ListReleasesResponse
+	// ctx is the context of the call, if not the default.
+	ctx context.Context
 }
 
 func (l *mockListServer) Send(res *services.ListReleasesResponse) error {
@@ -1319,9 +2068,75 @@ func (l *mockListServer) Send(res *services.ListReleasesResponse) error {
 	return nil
 }
 
-func (l *mockListServer) Context() context.Context       { return helm.NewContext() }
+func (l *mockListServer) Context() context.Context {
+	if l.ctx != nil {
+		return l.ctx
+	}
+	return helm.NewContext()
+}
+
 func (l *mockListServer) SendMsg(v interface{}) error    { return nil }
 func (l *mockListServer) RecvMsg(v interface{}) error    { return nil }
 func (l *mockListServer) SendHeader(m metadata.MD) error { return nil }
 func (l *mockListServer) SetTrailer(m metadata.MD)       {}
 func (l *mockListServer) SetHeader(m metadata.MD) error  { return nil }
+
+func TestKubeClientForLabelsResources(t *testing.T) {
+	rs := rsFixture()
+	kc := kube.New(nil)
+	rs.env.KubeClient = kc
+
+	rel := releaseStub()
+	rel.Version = 4
+	rel.Chart.Metadata.Version = "0.1.0"
+	got, ok := rs.kubeClientFor(rel).(*kube.Client)
+	if !ok || got.Owner == nil {
+		t.Fatalf("expected an owned client, got %#v", got)
+	}
+	if expect := (kube.Owner{Release: rel.Name, Revision: 4, Chart: "hello-0.1.0"}); *got.Owner != expect {
+		t.Errorf("expected owner %v, got %v", expect, *got.Owner)
+	}
+	if kc.Owner != nil {
+		t.Error("the shared client must not be changed")
+	}
+}
+
+func TestReleaseMetadata(t *testing.T) {
+	c := helm.NewContext()
+	rs := rsFixture()
+
+	ires, err := rs.InstallRelease(c, &services.InstallReleaseRequest{
+		Namespace: "spaced",
+		Chart:     chartStub(),
+		Metadata:  map[string]string{"commit": "abc123", "build": "https://ci.example.com/1"},
+	})
+	if err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+	name := ires.Release.Name
+	if _, err := rs.UpdateRelease(c, &services.UpdateReleaseRequest{
+		Name:     name,
+		Chart:    chartStub(),
+		Metadata: map[string]string{"commit": "def456"},
+	}); err != nil {
+		t.Fatalf("Failed upgrade: %s", err)
+	}
+	if _, err := rs.RollbackRelease(c, &services.RollbackReleaseRequest{Name: name, Version: 1}); err != nil {
+		t.Fatalf("Failed rollback: %s", err)
+	}
+
+	expect := []map[string]string{
+		{"commit": "abc123", "build": "https://ci.example.com/1"},
+		{"commit": "def456"},
+		nil,
+	}
+	for i, md := range expect {
+		rel, err := rs.env.Releases.Get(name, int32(i+1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(rel.Info.Metadata, md) {
+			t.Errorf("expected revision %d to have metadata %v, got %v", i+1, md, rel.Info.Metadata)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tiller
+
+import (
+	"fmt"
+
+	ctx "golang.org/x/net/context"
+
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/proto/hapi/services"
+)
+
+// SetReleaseValues stores override values on the latest revision of a
+// release. Overrides are merged over the values of every later upgrade, so
+// they do not have to be given again each time.
+//
+// The overrides take effect with the next upgrade, or, if the request asks
+// for it, with an upgrade to the same chart and values right away.
+func (s *ReleaseServer) SetReleaseValues(c ctx.Context, req *services.SetReleaseValuesRequest) (*services.SetReleaseValuesResponse, error) {
+	if !checkClientVersion(c) {
+		return nil, errIncompatibleVersion
+	}
+
+	s, err := s.forRequest(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ValidName.MatchString(req.Name) {
+		return nil, errMissingRelease
+	}
+
+	rel, err := s.env.Releases.Last(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	base := rel.Overrides
+	if req.Replace {
+		base = nil
+	}
+	if rel.Overrides, err = withOverrides(base, req.Values); err != nil {
+		return nil, err
+	}
+	s.logf("setting the overrides of %s (v%d)", rel.Name, rel.Version)
+	if err := s.env.Releases.Update(rel); err != nil {
+		return nil, err
+	}
+	if !req.Apply {
+		return &services.SetReleaseValuesResponse{Release: rel}, nil
+	}
+
+	res, err := s.upgrade(&services.UpdateReleaseRequest{
+		Name:   rel.Name,
+		Chart:  rel.Chart,
+		Values: rel.Config,
+	})
+	if res == nil {
+		return nil, err
+	}
+	return &services.SetReleaseValuesResponse{Release: res.Release}, err
+}
+
+// withOverrides returns values with overrides merged over them. Tables are
+// merged recursively, and anything else in overrides replaces what values
+// has, including null, which removes a default of the chart.
+func withOverrides(values, overrides *chart.Config) (*chart.Config, error) {
+	if overrides == nil || overrides.Raw == "" {
+		return values, nil
+	}
+	if values == nil || values.Raw == "" {
+		return overrides, nil
+	}
+
+	dst, err := chartutil.ReadValues([]byte(values.Raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid values: %s", err)
+	}
+	src, err := chartutil.ReadValues([]byte(overrides.Raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid override values: %s", err)
+	}
+	chartutil.MergeValues(dst, src)
+	raw, err := dst.YAML()
+	if err != nil {
+		return nil, err
+	}
+	return &chart.Config{Raw: raw}, nil
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tiller
+
+import (
+	"strings"
+	"testing"
+
+	"k8s.io/helm/pkg/chartutil"
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/proto/hapi/services"
+)
+
+func TestSetReleaseValues(t *testing.T) {
+	c := helm.NewContext()
+	rs := rsFixture()
+	rel := releaseStub()
+	rel.Chart.Templates = []*chart.Template{{Name: "templates/hello", Data: []byte("name: {{ .Values.name }}\nsize: {{ .Values.size }}")}}
+	rs.env.Releases.Create(rel)
+
+	res, err := rs.SetReleaseValues(c, &services.SetReleaseValuesRequest{
+		Name:   rel.Name,
+		Values: &chart.Config{Raw: "name: override\nextra:\n  a: 1\n"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Release.Version != 1 {
+		t.Errorf("expected the overrides to be stored on revision 1, got revision %d", res.Release.Version)
+	}
+
+	// Overrides are merged into the ones already stored.
+	if _, err := rs.SetReleaseValues(c, &services.SetReleaseValuesRequest{
+		Name:   rel.Name,
+		Values: &chart.Config{Raw: "extra:\n  b: 2\n"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	last, err := rs.env.Releases.Last(rel.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last.Version != 1 {
+		t.Fatalf("expected no new revision, got revision %d", last.Version)
+	}
+	expect := "extra:\n  a: 1\n  b: 2\nname: override\n"
+	if last.Overrides.Raw != expect {
+		t.Errorf("expected overrides %q, got %q", expect, last.Overrides.Raw)
+	}
+
+	// An upgrade merges the overrides over its values, and keeps them.
+	up, err := rs.UpdateRelease(c, &services.UpdateReleaseRequest{
+		Name:   rel.Name,
+		Chart:  rel.Chart,
+		Values: &chart.Config{Raw: "name: given\nsize: 2\n"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(up.Release.Manifest, "name: override\nsize: 2") {
+		t.Errorf("expected the overrides to be rendered, got\n%s", up.Release.Manifest)
+	}
+	if up.Release.Overrides.Raw != expect {
+		t.Errorf("expected the overrides to be kept, got %q", up.Release.Overrides.Raw)
+	}
+	vals, err := chartutil.ReadValues([]byte(up.Release.Config.Raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vals["name"] != "override" || vals["size"] != float64(2) {
+		t.Errorf("expected the values of the revision to include the overrides, got %v", vals)
+	}
+
+	// --replace --apply replaces the overrides and upgrades at once.
+	res, err = rs.SetReleaseValues(c, &services.SetReleaseValuesRequest{
+		Name:    rel.Name,
+		Values:  &chart.Config{Raw: "name: applied\n"},
+		Replace: true,
+		Apply:   true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Release.Version != 3 {
+		t.Errorf("expected revision 3, got %d", res.Release.Version)
+	}
+	if !strings.Contains(res.Release.Manifest, "name: applied\nsize: 2") {
+		t.Errorf("expected the new overrides to be rendered, got\n%s", res.Release.Manifest)
+	}
+	if res.Release.Overrides.Raw != "name: applied\n" {
+		t.Errorf("expected the overrides to be replaced, got %q", res.Release.Overrides.Raw)
+	}
+
+	if _, err := rs.SetReleaseValues(c, &services.SetReleaseValuesRequest{Name: "no-such-release"}); err == nil {
+		t.Error("expected a missing release to fail")
+	}
+}
+
+func TestWithOverrides(t *testing.T) {
+	tests := []struct {
+		values, overrides, expect string
+	}{
+		{values: "", overrides: "", expect: ""},
+		{values: "a: 1\n", overrides: "", expect: "a: 1\n"},
+		{values: "", overrides: "a: 1\n", expect: "a: 1\n"},
+		{values: "a: 1\nt:\n  b: 1\n  c: 1\n", overrides: "t:\n  c: 2\n", expect: "a: 1\nt:\n  b: 1\n  c: 2\n"},
+		{values: "t:\n  b: 1\n", overrides: "t: flat\n", expect: "t: flat\n"},
+		{values: "a: 1\n", overrides: "a: null\n", expect: "a: null\n"},
+	}
+	for _, tt := range tests {
+		cfg, err := withOverrides(&chart.Config{Raw: tt.values}, &chart.Config{Raw: tt.overrides})
+		if err != nil {
+			t.Errorf("%q over %q: %s", tt.overrides, tt.values, err)
+			continue
+		}
+		if cfg.Raw != tt.expect {
+			t.Errorf("%q over %q: expected %q, got %q", tt.overrides, tt.values, tt.expect, cfg.Raw)
+		}
+	}
+
+	if _, err := withOverrides(&chart.Config{Raw: "a: 1\n"}, &chart.Config{Raw: "- not a table"}); err == nil {
+		t.Error("expected invalid overrides to fail")
+	}
+}
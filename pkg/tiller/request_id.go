@@ -0,0 +1,88 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tiller
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+
+	ctx "golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+
+	"k8s.io/helm/pkg/kube"
+)
+
+// requestIDKey is the metadata key the client sends the ID of its request
+// under. The ID correlates the log messages of the client and Tiller.
+const requestIDKey = "x-helm-request-id"
+
+func getRequestID(c ctx.Context) string {
+	if md, ok := metadata.FromContext(c); ok {
+		if v, ok := md[requestIDKey]; ok {
+			return v[0]
+		}
+	}
+	return ""
+}
+
+// newRequestID makes up an ID for a request from a client that sent none.
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// forRequest returns a release server that serves the request of c: its log
+// messages, and those of its storage and Kubernetes client, are tagged with
+// the ID of the request, and it acts on the cluster as the user who sent it.
+// See impersonating.
+func (s *ReleaseServer) forRequest(c ctx.Context) (*ReleaseServer, error) {
+	id := getRequestID(c)
+	if id == "" {
+		id = newRequestID()
+	}
+	output := func(calldepth int, msg string) error {
+		return log.Output(calldepth+1, fmt.Sprintf("[%s] %s", id, msg))
+	}
+
+	env := *s.env
+	if env.Releases != nil {
+		releases := *env.Releases
+		releases.Log = output
+		env.Releases = &releases
+	}
+	if kc, ok := env.KubeClient.(*kube.Client); ok {
+		env.KubeClient = kc.WithLog(output)
+	}
+	cp := *s
+	cp.env = &env
+	cp.requestID = id
+	return cp.impersonating(c)
+}
+
+// logf logs a message, tagged with the ID of the request being served.
+func (s *ReleaseServer) logf(format string, v ...interface{}) {
+	msg := fmt.Sprintf(format, v...)
+	if s.requestID != "" {
+		msg = fmt.Sprintf("[%s] %s", s.requestID, msg)
+	}
+	log.Output(2, msg)
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tiller
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/services"
+)
+
+func TestRequestIDInLog(t *testing.T) {
+	b := bytes.NewBuffer(nil)
+	log.SetOutput(b)
+	defer log.SetOutput(os.Stderr)
+
+	rs := rsFixture()
+	md, _ := metadata.FromContext(helm.NewContext())
+	md[requestIDKey] = []string{"0123456789abcdef"}
+	c := metadata.NewContext(helm.NewContext(), md)
+
+	req := &services.InstallReleaseRequest{Name: "traced", Chart: chartStub()}
+	if _, err := rs.InstallRelease(c, req); err != nil {
+		t.Fatalf("Failed install: %s", err)
+	}
+
+	var storageLines int
+	for _, l := range strings.Split(strings.TrimSpace(b.String()), "\n") {
+		if !strings.Contains(l, "[0123456789abcdef] ") {
+			t.Errorf("expected the request ID in %q", l)
+		}
+		if strings.Contains(l, "in storage") {
+			storageLines++
+		}
+	}
+	if storageLines == 0 {
+		t.Errorf("expected the storage to log with the request ID, got\n%s", b)
+	}
+	if rs.env.Releases.Log != nil {
+		t.Error("expected the environment of the server to be left alone")
+	}
+}
+
+func TestRequestIDMadeUp(t *testing.T) {
+	rs, err := rsFixture().forRequest(helm.NewContext())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rs.requestID) != 16 {
+		t.Errorf("expected a request ID to be made up, got %q", rs.requestID)
+	}
+}
@@ -0,0 +1,40 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tiller
+
+// ResourcePolicyAnnotation sets what Tiller does with a resource when its
+// release is deleted. With the value "keep", the resource is left in the
+// cluster, e.g. to keep the data of a PersistentVolumeClaim.
+const ResourcePolicyAnnotation = "helm.sh/resource-policy"
+
+// keepPolicy is the resource policy of resources that outlive their release.
+const keepPolicy = "keep"
+
+// filterKept splits manifests into those to delete and those kept by their
+// resource policy, which are returned as "Kind name".
+func filterKept(manifests []manifest) ([]manifest, []string) {
+	remaining := []manifest{}
+	kept := []string{}
+	for _, m := range manifests {
+		if m.head == nil || m.head.Metadata == nil || m.head.Metadata.Annotations[ResourcePolicyAnnotation] != keepPolicy {
+			remaining = append(remaining, m)
+			continue
+		}
+		kept = append(kept, m.head.Kind+" "+m.head.Metadata.Name)
+	}
+	return remaining, kept
+}
@@ -0,0 +1,212 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tiller
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"k8s.io/helm/pkg/proto/hapi/chart"
+	"k8s.io/helm/pkg/proto/hapi/release"
+	relutil "k8s.io/helm/pkg/releaseutil"
+)
+
+// sourcePrefix marks the template a manifest document was rendered from.
+const sourcePrefix = "# Source: "
+
+// kindSelector matches patterns of the form Kind/name, e.g. "Deployment/web".
+// Anything else is treated as a template path glob.
+var kindSelector = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*/[^/]+$`)
+
+// templateSelector restricts a release to the templates that match at least
+// one include pattern (or all templates, if there are none) and no exclude
+// pattern.
+//
+// A pattern is either a path glob matched against the template path (with or
+// without the leading chart name, so both "mychart/templates/*.yaml" and
+// "templates/*.yaml" work), or a Kind/name selector such as "Service/web*".
+//
+// If subcharts is set, only the templates of those subcharts (including
+// their own subcharts) are selected as well.
+type templateSelector struct {
+	include   []string
+	exclude   []string
+	subcharts []string // template path prefixes, e.g. "umbrella/charts/redis/"
+}
+
+func newTemplateSelector(include, exclude []string) (templateSelector, error) {
+	for _, p := range append(append([]string{}, include...), exclude...) {
+		if _, err := path.Match(p, ""); err != nil {
+			return templateSelector{}, fmt.Errorf("invalid template pattern %q: %s", p, err)
+		}
+	}
+	return templateSelector{include: include, exclude: exclude}, nil
+}
+
+// onlySubcharts limits the selector to the templates of the named subcharts
+// of ch. A name is the path of the subchart from ch, e.g. "redis" or
+// "backend/redis".
+func (t *templateSelector) onlySubcharts(ch *chart.Chart, names []string) error {
+	for _, n := range names {
+		prefix := ch.Metadata.Name + "/"
+		c := ch
+		for _, part := range strings.Split(n, "/") {
+			var sub *chart.Chart
+			for _, d := range c.Dependencies {
+				if d.Metadata != nil && d.Metadata.Name == part {
+					sub = d
+					break
+				}
+			}
+			if sub == nil {
+				return fmt.Errorf("chart %q has no subchart %q", ch.Metadata.Name, n)
+			}
+			prefix += "charts/" + part + "/"
+			c = sub
+		}
+		t.subcharts = append(t.subcharts, prefix)
+	}
+	return nil
+}
+
+// empty reports whether the selector lets every template through.
+func (t templateSelector) empty() bool {
+	return len(t.include) == 0 && len(t.exclude) == 0 && len(t.subcharts) == 0
+}
+
+// selects reports whether the template at name, rendering a resource of the
+// given kind and resource name, is selected.
+func (t templateSelector) selects(name, kind, resource string) bool {
+	if len(t.subcharts) > 0 && !hasAnyPrefix(name, t.subcharts) {
+		return false
+	}
+	if len(t.include) > 0 && !matchAny(t.include, name, kind, resource) {
+		return false
+	}
+	return !matchAny(t.exclude, name, kind, resource)
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAny(patterns []string, name, kind, resource string) bool {
+	for _, p := range patterns {
+		if matchPattern(p, name, kind, resource) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchPattern(pattern, name, kind, resource string) bool {
+	if kindSelector.MatchString(pattern) {
+		parts := strings.SplitN(pattern, "/", 2)
+		if !strings.EqualFold(parts[0], kind) {
+			return false
+		}
+		ok, _ := path.Match(parts[1], resource)
+		return ok
+	}
+	if ok, _ := path.Match(pattern, name); ok {
+		return true
+	}
+	// Allow the chart name to be left off of the pattern.
+	if i := strings.Index(name, "/"); i >= 0 {
+		ok, _ := path.Match(pattern, name[i+1:])
+		return ok
+	}
+	return false
+}
+
+// filterManifests returns the manifests the selector selects.
+func (t templateSelector) filterManifests(ms []manifest) []manifest {
+	if t.empty() {
+		return ms
+	}
+	res := []manifest{}
+	for _, m := range ms {
+		kind, resource := headKindName(m.head)
+		if t.selects(m.name, kind, resource) {
+			res = append(res, m)
+		}
+	}
+	return res
+}
+
+// filterHooks returns the hooks the selector selects.
+func (t templateSelector) filterHooks(hs []*release.Hook) []*release.Hook {
+	if t.empty() {
+		return hs
+	}
+	res := []*release.Hook{}
+	for _, h := range hs {
+		if t.selects(h.Path, h.Kind, h.Name) {
+			res = append(res, h)
+		}
+	}
+	return res
+}
+
+// unselected returns the documents of a previously rendered release manifest
+// that the selector does not select, in the same "# Source:" annotated format.
+//
+// Upgrades limited to a subset of templates carry these documents over, so
+// that resources outside of the selection are left untouched rather than
+// deleted. Only the first document of a template carries its "# Source:"
+// line; the documents after it belong to the same template, and are given
+// the line when they are carried over.
+func (t templateSelector) unselected(bigfile string) (string, error) {
+	docs, err := relutil.SplitManifest(bigfile)
+	if err != nil {
+		return "", err
+	}
+	b := bytes.NewBuffer(nil)
+	source := ""
+	for _, doc := range docs {
+		if doc.Source != "" {
+			source = doc.Source
+		}
+		if source == "" || t.selects(source, doc.Kind, doc.Name) {
+			continue
+		}
+		b.WriteString("\n---\n")
+		if doc.Source == "" {
+			b.WriteString(sourcePrefix + source + "\n")
+		}
+		b.WriteString(doc.Content)
+	}
+	return b.String(), nil
+}
+
+func headKindName(sh *simpleHead) (string, string) {
+	if sh == nil {
+		return "", ""
+	}
+	if sh.Metadata == nil {
+		return sh.Kind, ""
+	}
+	return sh.Kind, sh.Metadata.Name
+}
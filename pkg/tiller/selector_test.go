@@ -0,0 +1,130 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tiller
+
+import (
+	"testing"
+
+	"k8s.io/helm/pkg/proto/hapi/chart"
+)
+
+func TestTemplateSelector(t *testing.T) {
+	tests := []struct {
+		include, exclude []string
+		name, kind, res  string
+		expect           bool
+	}{
+		{nil, nil, "mychart/templates/svc.yaml", "Service", "web", true},
+		{[]string{"templates/*.yaml"}, nil, "mychart/templates/svc.yaml", "Service", "web", true},
+		{[]string{"mychart/templates/svc.yaml"}, nil, "mychart/templates/svc.yaml", "Service", "web", true},
+		{[]string{"templates/deploy.yaml"}, nil, "mychart/templates/svc.yaml", "Service", "web", false},
+		{[]string{"Service/web"}, nil, "mychart/templates/svc.yaml", "Service", "web", true},
+		{[]string{"Service/w*"}, nil, "mychart/templates/svc.yaml", "Service", "web", true},
+		{[]string{"Deployment/web"}, nil, "mychart/templates/svc.yaml", "Service", "web", false},
+		{nil, []string{"Service/*"}, "mychart/templates/svc.yaml", "Service", "web", false},
+		{[]string{"templates/*"}, []string{"templates/svc.yaml"}, "mychart/templates/svc.yaml", "Service", "web", false},
+	}
+
+	for i, tt := range tests {
+		sel, err := newTemplateSelector(tt.include, tt.exclude)
+		if err != nil {
+			t.Fatalf("%d: %s", i, err)
+		}
+		if got := sel.selects(tt.name, tt.kind, tt.res); got != tt.expect {
+			t.Errorf("%d: expected %t, got %t", i, tt.expect, got)
+		}
+	}
+}
+
+func TestTemplateSelectorUnselected(t *testing.T) {
+	sel, _ := newTemplateSelector([]string{"ConfigMap/a"}, nil)
+	in := "\n---\n# Source: c/templates/a.yaml\nkind: ConfigMap\nmetadata:\n  name: a\n" +
+		"\n---\n# Source: c/templates/b.yaml\nkind: ConfigMap\nmetadata:\n  name: b\n"
+	expect := "\n---\n# Source: c/templates/b.yaml\nkind: ConfigMap\nmetadata:\n  name: b\n"
+	if got, err := sel.unselected(in); err != nil || got != expect {
+		t.Errorf("expected %q, got %q", expect, got)
+	}
+}
+
+func TestTemplateSelectorUnselectedMultiDocument(t *testing.T) {
+	sel, _ := newTemplateSelector([]string{"templates/a.yaml"}, nil)
+	in := "\n---\n# Source: c/templates/a.yaml\nkind: ConfigMap\nmetadata:\n  name: a\n" +
+		"\n---\n# Source: c/templates/b.yaml\nkind: ConfigMap\nmetadata:\n  name: b1\n" +
+		"---\nkind: ConfigMap\nmetadata:\n  name: b2\n"
+	expect := "\n---\n# Source: c/templates/b.yaml\nkind: ConfigMap\nmetadata:\n  name: b1\n" +
+		"\n---\n# Source: c/templates/b.yaml\nkind: ConfigMap\nmetadata:\n  name: b2\n"
+	if got, err := sel.unselected(in); err != nil || got != expect {
+		t.Errorf("expected %q, got %q", expect, got)
+	}
+
+	// A document following a selected one belongs to the selected template.
+	sel, _ = newTemplateSelector([]string{"templates/b.yaml"}, nil)
+	expect = "\n---\n# Source: c/templates/a.yaml\nkind: ConfigMap\nmetadata:\n  name: a\n"
+	if got, err := sel.unselected(in); err != nil || got != expect {
+		t.Errorf("expected %q, got %q", expect, got)
+	}
+}
+
+func TestTemplateSelectorOnlySubcharts(t *testing.T) {
+	ch := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "umbrella"},
+		Dependencies: []*chart.Chart{
+			{Metadata: &chart.Metadata{Name: "redis"}},
+			{
+				Metadata: &chart.Metadata{Name: "backend"},
+				Dependencies: []*chart.Chart{
+					{Metadata: &chart.Metadata{Name: "redis"}},
+				},
+			},
+		},
+	}
+
+	sel, _ := newTemplateSelector(nil, []string{"ConfigMap/*"})
+	if err := sel.onlySubcharts(ch, []string{"redis"}); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name, kind string
+		expect     bool
+	}{
+		{"umbrella/charts/redis/templates/svc.yaml", "Service", true},
+		{"umbrella/charts/redis/templates/cm.yaml", "ConfigMap", false},
+		{"umbrella/templates/svc.yaml", "Service", false},
+		{"umbrella/charts/redis-ha/templates/svc.yaml", "Service", false},
+		{"umbrella/charts/backend/charts/redis/templates/svc.yaml", "Service", false},
+	}
+	for _, tt := range tests {
+		if got := sel.selects(tt.name, tt.kind, "x"); got != tt.expect {
+			t.Errorf("%s: expected %t, got %t", tt.name, tt.expect, got)
+		}
+	}
+
+	sel, _ = newTemplateSelector(nil, nil)
+	if err := sel.onlySubcharts(ch, []string{"backend"}); err != nil {
+		t.Fatal(err)
+	}
+	if !sel.selects("umbrella/charts/backend/charts/redis/templates/svc.yaml", "Service", "x") {
+		t.Error("expected the subcharts of a subchart to be selected")
+	}
+
+	if err := sel.onlySubcharts(ch, []string{"backend/redis"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sel.onlySubcharts(ch, []string{"backend/mysql"}); err == nil {
+		t.Error("expected an error for a missing subchart")
+	}
+}
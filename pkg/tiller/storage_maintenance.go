@@ -0,0 +1,70 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tiller
+
+import (
+	ctx "golang.org/x/net/context"
+
+	"k8s.io/helm/pkg/proto/hapi/services"
+	"k8s.io/helm/pkg/storage"
+)
+
+// MaintainStorage compacts the history of the releases in storage, removes
+// the records that purges left behind, and reports the records stored for
+// each release, along with any that fail the integrity checks.
+//
+// The records of every release are affected, locked ones included, so the
+// caller must be allowed to delete all of them, or, for a dry run, to list
+// them.
+func (s *ReleaseServer) MaintainStorage(c ctx.Context, req *services.MaintainStorageRequest) (*services.MaintainStorageResponse, error) {
+	if !checkClientVersion(c) {
+		return nil, errIncompatibleVersion
+	}
+
+	s, err := s.forRequest(c)
+	if err != nil {
+		return nil, err
+	}
+	if req.DryRun {
+		err = s.authorizeRead("list", "")
+	} else {
+		err = s.authorizeWrite("delete", "")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := s.env.Releases.Maintain(storage.MaintainOptions{
+		MaxHistory: int(req.MaxHistory),
+		DryRun:     req.DryRun,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := &services.MaintainStorageResponse{Problems: report.Problems}
+	for _, u := range report.Releases {
+		res.Releases = append(res.Releases, &services.StorageUsage{
+			Name:      u.Name,
+			Revisions: int32(u.Revisions),
+			Bytes:     int64(u.Bytes),
+			Removed:   int32(u.Removed),
+			Orphaned:  u.Orphaned,
+		})
+	}
+	return res, nil
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tiller
+
+import (
+	"testing"
+
+	"k8s.io/helm/pkg/helm"
+	"k8s.io/helm/pkg/proto/hapi/release"
+	"k8s.io/helm/pkg/proto/hapi/services"
+)
+
+func TestMaintainStorage(t *testing.T) {
+	c := helm.NewContext()
+	rs := rsFixture()
+
+	rel := releaseStub()
+	rs.env.Releases.Create(rel)
+	for i := 0; i < 3; i++ {
+		next := upgradeReleaseVersion(rel)
+		rs.env.Releases.Update(rel)
+		rs.env.Releases.Create(next)
+		rel = next
+	}
+	purged := namedReleaseStub("purged-panda", release.Status_SUPERSEDED)
+	rs.env.Releases.Create(purged)
+
+	res, err := rs.MaintainStorage(c, &services.MaintainStorageRequest{MaxHistory: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Releases) != 2 {
+		t.Fatalf("expected 2 releases, got %v", res.Releases)
+	}
+	if u := res.Releases[0]; u.Name != rel.Name || u.Revisions != 4 || u.Removed != 2 || u.Bytes == 0 {
+		t.Errorf("expected 2 of 4 revisions of %s to be removed, got %v", rel.Name, u)
+	}
+	if u := res.Releases[1]; u.Name != purged.Name || !u.Orphaned || u.Removed != 1 {
+		t.Errorf("expected %s to be removed as orphaned, got %v", purged.Name, u)
+	}
+	if len(res.Problems) != 0 {
+		t.Errorf("expected no problems, got %q", res.Problems)
+	}
+
+	h, err := rs.env.Releases.History(rel.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h) != 2 {
+		t.Errorf("expected 2 revisions to be left, got %d", len(h))
+	}
+	if h, _ := rs.env.Releases.History(purged.Name); len(h) != 0 {
+		t.Errorf("expected the records of %s to be removed, got %d", purged.Name, len(h))
+	}
+}
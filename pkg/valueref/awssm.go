@@ -0,0 +1,209 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package valueref
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// SecretsManager resolves references to secrets in AWS Secrets Manager. The
+// path of a reference is the name or ARN of the secret. Secrets holding a
+// JSON object are referred to by key, e.g. "ref+awssm://prod/db#password";
+// other secrets are used as they are, and must be referred to without a key.
+type SecretsManager struct {
+	// Region is the AWS region of the secrets. It defaults to $AWS_REGION.
+	Region string `json:"region"`
+	// Endpoint is the URL of the Secrets Manager API. It defaults to the
+	// endpoint of Region.
+	Endpoint string `json:"endpoint"`
+
+	// AccessKeyID, SecretAccessKey and SessionToken are the credentials the
+	// requests are signed with. They default to $AWS_ACCESS_KEY_ID,
+	// $AWS_SECRET_ACCESS_KEY and $AWS_SESSION_TOKEN.
+	AccessKeyID     string `json:"-"`
+	SecretAccessKey string `json:"-"`
+	SessionToken    string `json:"-"`
+
+	// Client is the HTTP client used. If nil, http.DefaultClient is used.
+	Client *http.Client `json:"-"`
+
+	now func() time.Time
+}
+
+func (s *SecretsManager) init() error {
+	if s.Region == "" {
+		s.Region = os.Getenv("AWS_REGION")
+	}
+	if s.Region == "" {
+		return fmt.Errorf("awssm has no region, and $AWS_REGION is not set")
+	}
+	if s.Endpoint == "" {
+		s.Endpoint = "https://secretsmanager." + s.Region + ".amazonaws.com"
+	}
+	if s.AccessKeyID == "" {
+		s.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+		s.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		s.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	if s.AccessKeyID == "" || s.SecretAccessKey == "" {
+		return fmt.Errorf("awssm has no credentials: set $AWS_ACCESS_KEY_ID and $AWS_SECRET_ACCESS_KEY")
+	}
+	return nil
+}
+
+type getSecretValueResponse struct {
+	SecretString *string `json:"SecretString"`
+}
+
+type awsError struct {
+	Type    string `json:"__type"`
+	Message string `json:"message"`
+}
+
+// Resolve implements Resolver.
+func (s *SecretsManager) Resolve(ref *Ref) (string, error) {
+	body, err := json.Marshal(map[string]string{"SecretId": ref.Path})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequest("POST", s.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	s.sign(req, body)
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var e awsError
+		if json.Unmarshal(b, &e) == nil && e.Message != "" {
+			// The type is e.g. "com.amazonaws.secretsmanager#ResourceNotFoundException".
+			return "", fmt.Errorf("secrets manager returned %s: %s: %s", resp.Status, e.Type[strings.LastIndex(e.Type, "#")+1:], e.Message)
+		}
+		return "", fmt.Errorf("secrets manager returned %s", resp.Status)
+	}
+
+	var res getSecretValueResponse
+	if err := json.Unmarshal(b, &res); err != nil {
+		return "", fmt.Errorf("cannot decode the answer of secrets manager: %s", err)
+	}
+	if res.SecretString == nil {
+		return "", fmt.Errorf("the secret is binary, which is not supported")
+	}
+	if ref.Key == "" {
+		return *res.SecretString, nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(*res.SecretString), &fields); err != nil {
+		return "", fmt.Errorf("the secret is not a JSON object, so it cannot be referred to by key")
+	}
+	return field(fields, ref.Key)
+}
+
+// sign signs req with AWS Signature Version 4.
+func (s *SecretsManager) sign(req *http.Request, body []byte) {
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	t := now().UTC()
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if s.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.SessionToken)
+	}
+
+	// The signed headers, in the sorted order the signature requires.
+	headers := []string{"content-type", "host", "x-amz-date"}
+	if s.SessionToken != "" {
+		headers = append(headers, "x-amz-security-token")
+	}
+	headers = append(headers, "x-amz-target")
+	var canonical bytes.Buffer
+	for _, h := range headers {
+		v := req.Header.Get(h)
+		if h == "host" {
+			v = req.URL.Host
+		}
+		fmt.Fprintf(&canonical, "%s:%s\n", h, strings.TrimSpace(v))
+	}
+	signed := strings.Join(headers, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	creq := strings.Join([]string{
+		req.Method,
+		path,
+		req.URL.Query().Encode(),
+		canonical.String(),
+		signed,
+		hexSHA256(body),
+	}, "\n")
+
+	scope := date + "/" + s.Region + "/secretsmanager/aws4_request"
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hexSHA256([]byte(creq))
+	sig := hex.EncodeToString(hmacSHA256(signingKey(s.SecretAccessKey, date, s.Region, "secretsmanager"), toSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", s.AccessKeyID, scope, signed, sig))
+}
+
+// signingKey derives the key of Signature Version 4 for a day, region and
+// service from a secret access key.
+func signingKey(secret, date, region, service string) []byte {
+	k := hmacSHA256([]byte("AWS4"+secret), date)
+	k = hmacSHA256(k, region)
+	k = hmacSHA256(k, service)
+	return hmacSHA256(k, "aws4_request")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package valueref
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSigningKey(t *testing.T) {
+	// The example of the AWS Signature Version 4 documentation.
+	k := signingKey("wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "20120215", "us-east-1", "iam")
+	expect := "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d"
+	if s := hex.EncodeToString(k); s != expect {
+		t.Errorf("expected %s, got %s", expect, s)
+	}
+}
+
+func TestSecretsManagerResolve(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/20170301/eu-west-1/secretsmanager/aws4_request, SignedHeaders=content-type;host;x-amz-date;x-amz-security-token;x-amz-target, Signature=") {
+			t.Errorf("unexpected authorization %q", auth)
+		}
+		if d := r.Header.Get("X-Amz-Date"); d != "20170301T120000Z" {
+			t.Errorf("unexpected date %q", d)
+		}
+		if tok := r.Header.Get("X-Amz-Security-Token"); tok != "session" {
+			t.Errorf("unexpected session token %q", tok)
+		}
+		if target := r.Header.Get("X-Amz-Target"); target != "secretsmanager.GetSecretValue" {
+			t.Errorf("unexpected target %q", target)
+		}
+		var req struct{ SecretId string }
+		json.NewDecoder(r.Body).Decode(&req)
+		switch req.SecretId {
+		case "prod/db":
+			w.Write([]byte(`{"Name": "prod/db", "SecretString": "{\"user\": \"web\", \"password\": \"s3cr3t\"}"}`))
+		case "prod/key":
+			w.Write([]byte(`{"Name": "prod/key", "SecretString": "abc"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"__type": "com.amazonaws.secretsmanager#ResourceNotFoundException", "message": "Secrets Manager can't find the specified secret."}`))
+		}
+	}))
+	defer srv.Close()
+
+	s := &SecretsManager{
+		Region:          "eu-west-1",
+		Endpoint:        srv.URL,
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+		SessionToken:    "session",
+		now:             func() time.Time { return time.Date(2017, 3, 1, 12, 0, 0, 0, time.UTC) },
+	}
+	if err := s.init(); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		ref    string
+		expect string
+		err    string
+	}{
+		{ref: "ref+awssm://prod/db#password", expect: "s3cr3t"},
+		{ref: "ref+awssm://prod/key", expect: "abc"},
+		{ref: "ref+awssm://prod/key#a", err: "not a JSON object"},
+		{ref: "ref+awssm://prod/nope", err: "ResourceNotFoundException: Secrets Manager can't find the specified secret."},
+	}
+	for _, tt := range tests {
+		ref, _ := Parse(tt.ref)
+		v, err := s.Resolve(ref)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: expected error %q, got %v", tt.ref, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", tt.ref, err)
+		} else if v != tt.expect {
+			t.Errorf("%s: expected %q, got %q", tt.ref, tt.expect, v)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package valueref
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// commandTimeout is how long a Command may take to resolve a reference.
+const commandTimeout = 30 * time.Second
+
+// Command resolves references by running an external program, which makes
+// it possible to support stores that are not built in.
+//
+// The program is run with its arguments followed by the reference, e.g.
+// "ref+gcpsm://projects/p/secrets/db#password", and with the parts of the
+// reference in $HELM_REF_SCHEME, $HELM_REF_PATH and $HELM_REF_KEY. It
+// writes the value to its standard output; a single trailing newline is
+// removed. If it fails, its standard error is reported.
+type Command struct {
+	Scheme string   `json:"scheme"`
+	Path   string   `json:"command"`
+	Args   []string `json:"args"`
+}
+
+// Resolve implements Resolver.
+func (c *Command) Resolve(ref *Ref) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(c.Path, append(append([]string{}, c.Args...), ref.String())...)
+	cmd.Env = append(os.Environ(),
+		"HELM_REF_SCHEME="+ref.Scheme,
+		"HELM_REF_PATH="+ref.Path,
+		"HELM_REF_KEY="+ref.Key,
+	)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		return "", err
+	}
+	timer := time.AfterFunc(commandTimeout, func() { cmd.Process.Kill() })
+	err := cmd.Wait()
+	if !timer.Stop() {
+		return "", fmt.Errorf("%s did not finish within %s", c.Path, commandTimeout)
+	}
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("%s failed: %s", c.Path, msg)
+	}
+	return strings.TrimSuffix(stdout.String(), "\n"), nil
+}
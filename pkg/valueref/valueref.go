@@ -0,0 +1,313 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+/*Package valueref resolves references to secrets held outside of values.
+
+A string value of the form
+
+	ref+SCHEME://PATH#KEY
+
+refers to the field KEY of the secret at PATH in the secret store SCHEME, e.g.
+"ref+vault://secret/data/db#password" or "ref+awssm://prod/db#password". The
+key may be left off for secrets that have a single value. Tiller replaces
+references with the values they refer to when it renders a chart, so that
+secrets never have to be written to values files, and only the references are
+stored with the values of releases. Whatever a template renders a secret into,
+such as the manifest, hooks or notes, holds the secret though, and is stored
+with the release and returned to the client.
+
+Secrets are read with the credentials of Tiller, whoever installs the release,
+so a Policy lists the secrets the releases of each namespace may refer to.
+Nothing else is resolved.
+
+Stores are reached through Resolvers. Vault and AWS Secrets Manager are
+built in, and any other store can be added with a Command that runs an
+external program.
+*/
+package valueref // import "k8s.io/helm/pkg/valueref"
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+)
+
+// Prefix starts every reference.
+const Prefix = "ref+"
+
+var refPattern = regexp.MustCompile(`^ref\+([a-z][a-z0-9-]*)://([^#]+)(?:#(.*))?$`)
+
+// Ref is a reference to a secret.
+type Ref struct {
+	// Scheme names the store, e.g. "vault".
+	Scheme string
+	// Path locates the secret in the store.
+	Path string
+	// Key is the field of the secret, if any.
+	Key string
+}
+
+// Parse parses a reference. It returns false if s is not a reference.
+func Parse(s string) (*Ref, bool) {
+	m := refPattern.FindStringSubmatch(s)
+	if m == nil {
+		return nil, false
+	}
+	return &Ref{Scheme: m[1], Path: m[2], Key: m[3]}, true
+}
+
+// String returns the reference in the ref+SCHEME://PATH#KEY form.
+func (r *Ref) String() string {
+	s := Prefix + r.Scheme + "://" + r.Path
+	if r.Key != "" {
+		s += "#" + r.Key
+	}
+	return s
+}
+
+// Resolver looks up the values of references in one store.
+type Resolver interface {
+	Resolve(ref *Ref) (string, error)
+}
+
+// Resolvers are the resolvers of the known stores, by scheme.
+type Resolvers map[string]Resolver
+
+// Resolve returns a copy of vals in which every reference has been replaced
+// by the value it refers to. vals itself is left unchanged, so that it can be
+// stored with the release as it was given. A reference that allowed does not
+// allow fails.
+//
+// Each reference is only resolved once, however often it appears.
+func (r Resolvers) Resolve(vals map[string]interface{}, allowed func(*Ref) bool) (map[string]interface{}, error) {
+	w := &walker{resolvers: r, allowed: allowed, cache: map[string]string{}}
+	v, err := w.walk(vals, "")
+	if err != nil {
+		return nil, err
+	}
+	return v.(map[string]interface{}), nil
+}
+
+// Schemes returns the schemes there are resolvers for, sorted.
+func (r Resolvers) Schemes() []string {
+	s := make([]string, 0, len(r))
+	for k := range r {
+		s = append(s, k)
+	}
+	sort.Strings(s)
+	return s
+}
+
+type walker struct {
+	resolvers Resolvers
+	allowed   func(*Ref) bool
+	cache     map[string]string
+}
+
+func (w *walker) walk(v interface{}, at string) (interface{}, error) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			p := k
+			if at != "" {
+				p = at + "." + k
+			}
+			r, err := w.walk(e, p)
+			if err != nil {
+				return nil, err
+			}
+			res[k] = r
+		}
+		return res, nil
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i, e := range v {
+			r, err := w.walk(e, fmt.Sprintf("%s[%d]", at, i))
+			if err != nil {
+				return nil, err
+			}
+			res[i] = r
+		}
+		return res, nil
+	case string:
+		ref, ok := Parse(v)
+		if !ok {
+			return v, nil
+		}
+		return w.resolve(ref, at)
+	}
+	return v, nil
+}
+
+func (w *walker) resolve(ref *Ref, at string) (string, error) {
+	s := ref.String()
+	if v, ok := w.cache[s]; ok {
+		return v, nil
+	}
+	r, ok := w.resolvers[ref.Scheme]
+	if !ok {
+		return "", fmt.Errorf("value %s refers to %s, but there is no resolver for %q", at, s, ref.Scheme)
+	}
+	if !w.allowed(ref) {
+		return "", fmt.Errorf("value %s refers to %s, which releases in this namespace may not refer to", at, s)
+	}
+	v, err := r.Resolve(ref)
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve %s (value %s): %s", s, at, err)
+	}
+	w.cache[s] = v
+	return v, nil
+}
+
+// Policy lists, by namespace, the secrets that releases in the namespace may
+// refer to, as patterns of SCHEME://PATH in which "*" matches any run of
+// characters, e.g. "vault://secret/data/prod/*". The patterns listed for the
+// namespace "*" apply to every namespace.
+type Policy map[string][]string
+
+// Allows reports whether releases in namespace may refer to ref.
+func (p Policy) Allows(namespace string, ref *Ref) bool {
+	target := ref.Scheme + "://" + ref.Path
+	for _, ns := range []string{namespace, "*"} {
+		for _, pattern := range p[ns] {
+			if globRegexp(pattern).MatchString(target) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// globRegexp translates a pattern in which "*" matches any run of characters
+// to a regular expression that matches the whole of a string.
+func globRegexp(pattern string) *regexp.Regexp {
+	return regexp.MustCompile("^" + strings.Replace(regexp.QuoteMeta(pattern), `\*`, ".*", -1) + "$")
+}
+
+// Config configures the resolvers of Tiller.
+type Config struct {
+	Vault   *Vault          `json:"vault"`
+	AWSSM   *SecretsManager `json:"awssm"`
+	Plugins []*Command      `json:"plugins"`
+	// Allow lists the secrets the releases of each namespace may refer to.
+	// Without it, no reference is resolved.
+	Allow Policy `json:"allow"`
+	// Client is the HTTP client of Vault and Secrets Manager, unless they
+	// have one of their own. If nil, http.DefaultClient is used.
+	Client *http.Client `json:"-"`
+}
+
+// LoadConfig reads the configuration of resolvers from a YAML file:
+//
+//	vault:
+//	  address: https://vault.example.com:8200
+//	  tokenFile: /var/run/secrets/vault/token
+//	awssm:
+//	  region: eu-west-1
+//	plugins:
+//	  - scheme: gcpsm
+//	    command: /usr/local/bin/gcpsm-ref
+//	allow:
+//	  prod:
+//	    - vault://secret/data/prod/*
+//	  "*":
+//	    - awssm://shared/*
+//
+// Vault is reached at $VAULT_ADDR with $VAULT_TOKEN, unless an address and a
+// token file are given. AWS credentials are read from $AWS_ACCESS_KEY_ID,
+// $AWS_SECRET_ACCESS_KEY and $AWS_SESSION_TOKEN, and the region defaults to
+// $AWS_REGION.
+func LoadConfig(filename string) (*Config, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	c := &Config{}
+	if err := yaml.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %s", filename, err)
+	}
+	return c, nil
+}
+
+// Resolvers returns the resolvers configured by c.
+func (c *Config) Resolvers() (Resolvers, error) {
+	rs := Resolvers{}
+	if c.Vault != nil {
+		if err := c.Vault.init(); err != nil {
+			return nil, err
+		}
+		if c.Vault.Client == nil {
+			c.Vault.Client = c.Client
+		}
+		rs["vault"] = c.Vault
+	}
+	if c.AWSSM != nil {
+		if err := c.AWSSM.init(); err != nil {
+			return nil, err
+		}
+		if c.AWSSM.Client == nil {
+			c.AWSSM.Client = c.Client
+		}
+		rs["awssm"] = c.AWSSM
+	}
+	for _, p := range c.Plugins {
+		switch {
+		case p.Scheme == "":
+			return nil, fmt.Errorf("plugin without a scheme")
+		case !refPattern.MatchString(Prefix + p.Scheme + "://x"):
+			return nil, fmt.Errorf("plugin has invalid scheme %q: schemes are lower case letters, digits and dashes", p.Scheme)
+		case p.Path == "":
+			return nil, fmt.Errorf("plugin %s without a command", p.Scheme)
+		}
+		if _, ok := rs[p.Scheme]; ok {
+			return nil, fmt.Errorf("more than one resolver for %q", p.Scheme)
+		}
+		rs[p.Scheme] = p
+	}
+	return rs, nil
+}
+
+// field returns the field key of the fields of a secret, or its only field
+// if key is empty.
+func field(fields map[string]interface{}, key string) (string, error) {
+	if key == "" {
+		if len(fields) != 1 {
+			return "", fmt.Errorf("the secret has %d fields, so the reference needs a #key", len(fields))
+		}
+		for k := range fields {
+			key = k
+		}
+	}
+	v, ok := fields[key]
+	if !ok {
+		return "", fmt.Errorf("the secret has no field %q", key)
+	}
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package valueref
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in  string
+		ref *Ref
+	}{
+		{"ref+vault://secret/data/db#password", &Ref{Scheme: "vault", Path: "secret/data/db", Key: "password"}},
+		{"ref+awssm://arn:aws:secretsmanager:eu-west-1:1:secret:db", &Ref{Scheme: "awssm", Path: "arn:aws:secretsmanager:eu-west-1:1:secret:db"}},
+		{"ref+my-store://a/b#", &Ref{Scheme: "my-store", Path: "a/b"}},
+		{"ref+vault://", nil},
+		{"ref+Vault://a", nil},
+		{"see ref+vault://a", nil},
+		{"vault://a", nil},
+	}
+	for _, tt := range tests {
+		ref, ok := Parse(tt.in)
+		if ok != (tt.ref != nil) || !reflect.DeepEqual(ref, tt.ref) {
+			t.Errorf("%q: expected %v, got %v", tt.in, tt.ref, ref)
+		}
+	}
+	if s := tests[0].ref.String(); s != tests[0].in {
+		t.Errorf("expected %q, got %q", tests[0].in, s)
+	}
+}
+
+type fakeResolver struct {
+	secrets map[string]string
+	calls   int
+}
+
+func (f *fakeResolver) Resolve(ref *Ref) (string, error) {
+	f.calls++
+	v, ok := f.secrets[ref.Path+"#"+ref.Key]
+	if !ok {
+		return "", fmt.Errorf("no secret %s", ref.Path)
+	}
+	return v, nil
+}
+
+func TestResolve(t *testing.T) {
+	f := &fakeResolver{secrets: map[string]string{"db#password": "s3cr3t"}}
+	rs := Resolvers{"fake": f}
+	vals := map[string]interface{}{
+		"name": "web",
+		"db": map[string]interface{}{
+			"password": "ref+fake://db#password",
+			"port":     5432,
+		},
+		"replicas": []interface{}{"ref+fake://db#password", "plain"},
+	}
+
+	res, err := rs.Resolve(vals, all)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := map[string]interface{}{
+		"name": "web",
+		"db": map[string]interface{}{
+			"password": "s3cr3t",
+			"port":     5432,
+		},
+		"replicas": []interface{}{"s3cr3t", "plain"},
+	}
+	if !reflect.DeepEqual(res, expect) {
+		t.Errorf("expected %v, got %v", expect, res)
+	}
+	if f.calls != 1 {
+		t.Errorf("expected the reference to be resolved once, got %d", f.calls)
+	}
+	if vals["db"].(map[string]interface{})["password"] != "ref+fake://db#password" {
+		t.Errorf("the values were changed")
+	}
+
+	_, err = rs.Resolve(map[string]interface{}{"a": map[string]interface{}{"b": "ref+fake://missing"}}, all)
+	if err == nil || !strings.Contains(err.Error(), "cannot resolve ref+fake://missing (value a.b): no secret missing") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	_, err = rs.Resolve(map[string]interface{}{"a": "ref+vault://secret/db"}, all)
+	if err == nil || !strings.Contains(err.Error(), `no resolver for "vault"`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+	_, err = rs.Resolve(vals, func(*Ref) bool { return false })
+	if err == nil || !strings.Contains(err.Error(), "value db.password refers to ref+fake://db#password, which releases in this namespace may not refer to") {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := Resolvers(nil).Resolve(map[string]interface{}{"a": "b"}, all); err != nil {
+		t.Errorf("unexpected error without resolvers: %s", err)
+	}
+}
+
+func all(*Ref) bool { return true }
+
+func TestPolicyAllows(t *testing.T) {
+	p := Policy{
+		"prod": {"vault://secret/data/prod/*"},
+		"*":    {"awssm://shared/db"},
+	}
+	tests := []struct {
+		namespace, ref string
+		expect         bool
+	}{
+		{"prod", "ref+vault://secret/data/prod/db#password", true},
+		{"prod", "ref+vault://secret/data/prod/a/b", true},
+		{"prod", "ref+vault://secret/data/staging/db", false},
+		{"prod", "ref+awssm://secret/data/prod/db", false},
+		{"staging", "ref+vault://secret/data/prod/db", false},
+		{"staging", "ref+awssm://shared/db#key", true},
+		{"staging", "ref+awssm://shared/db2", false},
+	}
+	for _, tt := range tests {
+		ref, _ := Parse(tt.ref)
+		if got := p.Allows(tt.namespace, ref); got != tt.expect {
+			t.Errorf("%s in %s: expected %t, got %t", tt.ref, tt.namespace, tt.expect, got)
+		}
+	}
+	ref, _ := Parse("ref+vault://secret/data/prod/db")
+	if Policy(nil).Allows("prod", ref) {
+		t.Error("expected an empty policy to allow nothing")
+	}
+}
+
+func TestConfigResolvers(t *testing.T) {
+	tests := []struct {
+		config  string
+		schemes []string
+		err     string
+	}{
+		{config: "vault:\n  address: http://vault:8200\nplugins:\n  - scheme: gcpsm\n    command: /bin/gcpsm\nallow:\n  prod: [\"vault://secret/*\"]\n", schemes: []string{"gcpsm", "vault"}},
+		{config: "awssm:\n  region: eu-west-1\n", err: "no credentials"},
+		{config: "plugins:\n  - command: /bin/x\n", err: "plugin without a scheme"},
+		{config: "plugins:\n  - scheme: GCP\n    command: /bin/x\n", err: "invalid scheme"},
+		{config: "plugins:\n  - scheme: gcp\n", err: "plugin gcp without a command"},
+		{config: "vault:\n  address: http://vault\nplugins:\n  - scheme: vault\n    command: /bin/x\n", err: "more than one resolver"},
+	}
+	for _, tt := range tests {
+		f, err := ioutil.TempFile("", "resolvers")
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.WriteString(tt.config)
+		f.Close()
+		c, err := LoadConfig(f.Name())
+		os.Remove(f.Name())
+		if err != nil {
+			t.Fatalf("%q: %s", tt.config, err)
+		}
+		os.Unsetenv("AWS_ACCESS_KEY_ID")
+		rs, err := c.Resolvers()
+		if tt.err == "" {
+			if err != nil {
+				t.Errorf("%q: %s", tt.config, err)
+			} else if !reflect.DeepEqual(rs.Schemes(), tt.schemes) {
+				t.Errorf("%q: expected resolvers for %v, got %v", tt.config, tt.schemes, rs.Schemes())
+			}
+		}
+		if tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)) {
+			t.Errorf("%q: expected error %q, got %v", tt.config, tt.err, err)
+		}
+	}
+}
+
+func TestCommand(t *testing.T) {
+	dir, err := ioutil.TempDir("", "valueref")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	script := filepath.Join(dir, "resolve")
+	body := "#!/bin/sh\nif [ \"$HELM_REF_KEY\" = bad ]; then echo \"no such key\" >&2; exit 1; fi\necho \"$1 $2 $HELM_REF_SCHEME $HELM_REF_PATH $HELM_REF_KEY\"\n"
+	if err := ioutil.WriteFile(script, []byte(body), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Command{Scheme: "test", Path: script, Args: []string{"--flag"}}
+	v, err := c.Resolve(&Ref{Scheme: "test", Path: "a/b", Key: "k"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := "--flag ref+test://a/b#k test a/b k"; v != expect {
+		t.Errorf("expected %q, got %q", expect, v)
+	}
+
+	_, err = c.Resolve(&Ref{Scheme: "test", Path: "a/b", Key: "bad"})
+	if err == nil || !strings.Contains(err.Error(), "failed: no such key") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
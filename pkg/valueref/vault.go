@@ -0,0 +1,117 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package valueref
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Vault resolves references to secrets in HashiCorp Vault. The path of a
+// reference is the API path of the secret, without the leading /v1/, e.g.
+// "secret/data/db" for the secret "db" of a KV version 2 engine mounted at
+// "secret".
+type Vault struct {
+	// Address is the URL of the Vault server. It defaults to $VAULT_ADDR.
+	Address string `json:"address"`
+	// TokenFile is a file holding the token to authenticate with. If it is
+	// empty, $VAULT_TOKEN is used.
+	TokenFile string `json:"tokenFile"`
+	// Namespace is the Vault Enterprise namespace, if any.
+	Namespace string `json:"namespace"`
+	// Token authenticates the requests if there is no TokenFile. TokenFile is
+	// read again for each reference, so that the token can be renewed.
+	Token string `json:"-"`
+	// Client is the HTTP client used. If nil, http.DefaultClient is used.
+	Client *http.Client `json:"-"`
+}
+
+func (v *Vault) init() error {
+	if v.Address == "" {
+		v.Address = os.Getenv("VAULT_ADDR")
+	}
+	if v.Address == "" {
+		return fmt.Errorf("vault has no address, and $VAULT_ADDR is not set")
+	}
+	if v.TokenFile == "" && v.Token == "" {
+		v.Token = os.Getenv("VAULT_TOKEN")
+	}
+	return nil
+}
+
+type vaultResponse struct {
+	Data   map[string]interface{} `json:"data"`
+	Errors []string               `json:"errors"`
+}
+
+// Resolve implements Resolver.
+func (v *Vault) Resolve(ref *Ref) (string, error) {
+	token := v.Token
+	if v.TokenFile != "" {
+		b, err := ioutil.ReadFile(v.TokenFile)
+		if err != nil {
+			return "", err
+		}
+		token = strings.TrimSpace(string(b))
+	}
+
+	url := strings.TrimSuffix(v.Address, "/") + "/v1/" + strings.TrimPrefix(ref.Path, "/")
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if v.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.Namespace)
+	}
+
+	client := v.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var res vaultResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil && resp.StatusCode == http.StatusOK {
+		return "", fmt.Errorf("cannot decode the answer of vault: %s", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if len(res.Errors) > 0 {
+			return "", fmt.Errorf("vault returned %s: %s", resp.Status, strings.Join(res.Errors, "; "))
+		}
+		return "", fmt.Errorf("vault returned %s", resp.Status)
+	}
+
+	fields := res.Data
+	// KV version 2 engines nest the secret in data.data, next to its metadata.
+	if d, ok := fields["data"].(map[string]interface{}); ok {
+		if _, ok := fields["metadata"]; ok {
+			fields = d
+		}
+	}
+	return field(fields, ref.Key)
+}
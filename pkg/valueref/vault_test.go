@@ -0,0 +1,94 @@
+/*
+Copyright 2016 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package valueref
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestVaultResolve(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "t0ken" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"errors": ["permission denied"]}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/db":
+			w.Write([]byte(`{"data": {"data": {"password": "s3cr3t", "port": 5432}, "metadata": {"version": 3}}}`))
+		case "/v1/kv/api":
+			w.Write([]byte(`{"data": {"key": "abc"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errors": []}`))
+		}
+	}))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "vault")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	tokenFile := dir + "/token"
+	if err := ioutil.WriteFile(tokenFile, []byte("t0ken\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	v := &Vault{Address: srv.URL, TokenFile: tokenFile}
+	if err := v.init(); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		ref    string
+		expect string
+		err    string
+	}{
+		{ref: "ref+vault://secret/data/db#password", expect: "s3cr3t"},
+		{ref: "ref+vault://secret/data/db#port", expect: "5432"},
+		{ref: "ref+vault://kv/api", expect: "abc"},
+		{ref: "ref+vault://secret/data/db", err: "the secret has 2 fields, so the reference needs a #key"},
+		{ref: "ref+vault://secret/data/db#user", err: `the secret has no field "user"`},
+		{ref: "ref+vault://secret/data/nope#a", err: "vault returned 404 Not Found"},
+	}
+	for _, tt := range tests {
+		ref, _ := Parse(tt.ref)
+		s, err := v.Resolve(ref)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: expected error %q, got %v", tt.ref, tt.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", tt.ref, err)
+		} else if s != tt.expect {
+			t.Errorf("%s: expected %q, got %q", tt.ref, tt.expect, s)
+		}
+	}
+
+	v = &Vault{Address: srv.URL, Token: "wrong"}
+	ref, _ := Parse("ref+vault://kv/api")
+	if _, err := v.Resolve(ref); err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -18,10 +18,32 @@ package version // import "k8s.io/helm/pkg/version"
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/Masterminds/semver"
 )
 
+// APIVersion is the version of the gRPC API between helm clients and Tiller.
+//
+// Within an API version, services, RPCs and fields are only ever added, never
+// removed or changed in meaning, so a client and a server that speak the same
+// API version work together whatever their release versions. Only a change
+// that breaks this promise increases it.
+const APIVersion = 1
+
+// MinAPIVersion is the oldest API version that Tiller still serves.
+const MinAPIVersion = 1
+
+// IsCompatibleAPI tests if a client speaking the API version api can be
+// served by this version of Tiller.
+func IsCompatibleAPI(api string) bool {
+	v, err := strconv.Atoi(api)
+	if err != nil {
+		return false
+	}
+	return v >= MinAPIVersion && v <= APIVersion
+}
+
 // IsCompatible tests if a client and server version are compatible.
 func IsCompatible(client, server string) bool {
 	cv, err := semver.NewVersion(client)
@@ -44,3 +66,18 @@ func IsCompatible(client, server string) bool {
 	}
 	return c.Check(sv)
 }
+
+// IsCompatibleRange compares a version to a constraint.
+// It returns true if the version matches the constraint, and false in all other cases.
+func IsCompatibleRange(constraint, ver string) bool {
+	sv, err := semver.NewVersion(ver)
+	if err != nil {
+		return false
+	}
+
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return false
+	}
+	return c.Check(sv)
+}
@@ -17,7 +17,10 @@ limitations under the License.
 // Package version represents the current version of the project.
 package version // import "k8s.io/helm/pkg/version"
 
-import "testing"
+import (
+	"strconv"
+	"testing"
+)
 
 func TestIsCompatible(t *testing.T) {
 	tests := []struct {
@@ -41,3 +44,45 @@ func TestIsCompatible(t *testing.T) {
 		}
 	}
 }
+
+func TestIsCompatibleRange(t *testing.T) {
+	tests := []struct {
+		constraint string
+		ver        string
+		expected   bool
+	}{
+		{"~2.0.0", "v2.0.1", true},
+		{"~2.0.0", "v2.1.1", false},
+		{">=2.0.0", "v2.1.1", true},
+		{">=1.4.0, <1.6.0", "v1.5.3", true},
+		{">=1.4.0, <1.6.0", "v1.6.0", false},
+		{"^1.4.0", "not-a-version", false},
+		{"!!1.4.0", "v1.4.0", false},
+	}
+
+	for _, tt := range tests {
+		if IsCompatibleRange(tt.constraint, tt.ver) != tt.expected {
+			t.Errorf("expected constraint %s to be %v for %s", tt.constraint, tt.expected, tt.ver)
+		}
+	}
+}
+
+func TestIsCompatibleAPI(t *testing.T) {
+	tests := []struct {
+		api      string
+		expected bool
+	}{
+		{strconv.Itoa(APIVersion), true},
+		{strconv.Itoa(MinAPIVersion), true},
+		{strconv.Itoa(MinAPIVersion - 1), false},
+		{strconv.Itoa(APIVersion + 1), false},
+		{"v1", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if IsCompatibleAPI(tt.api) != tt.expected {
+			t.Errorf("expected API version %q to be %v", tt.api, tt.expected)
+		}
+	}
+}
@@ -37,6 +37,11 @@ run_style_check() {
   make test-style
 }
 
+run_windows_check() {
+  echo "Running 'make test-windows'"
+  make test-windows
+}
+
 # Build to ensure packages are compiled
 echo "Running 'make build'"
 make build
@@ -44,4 +49,5 @@ make build
 case "${CIRCLE_NODE_INDEX-0}" in
   0) run_unit_test   ;;
   1) run_style_check ;;
+  2) run_windows_check ;;
 esac